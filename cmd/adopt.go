@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ildx/merlin/internal/cli"
+	"github.com/ildx/merlin/internal/config"
+	"github.com/ildx/merlin/internal/migrate"
+	"github.com/ildx/merlin/internal/protect"
+	"github.com/spf13/cobra"
+)
+
+var adoptCmd = &cobra.Command{
+	Use:   "adopt <path...>",
+	Short: "Bring existing dotfiles into the dotfiles repository",
+	Long: `Copy dotfiles or config directories from your home directory into the
+dotfiles repository as a tool, with a generated merlin.toml that links them
+back to where they are now.
+
+BEHAVIOR
+	Files are copied, never moved: nothing changes in $HOME until you run
+	'merlin link <tool>'. Directories are copied recursively, skipping
+	symlinks and .git directories. A tool that already exists in the
+	repository is left untouched. 'merlin scan' lists candidates with the
+	matching adopt command.
+
+FLAGS
+	--tool <name>   Tool to create (default: derived from each path, e.g.
+	                ~/.config/nvim -> nvim, ~/.wgetrc -> wget)
+	--dry-run       Show the generated merlin.toml without writing
+
+EXAMPLES
+	merlin adopt ~/.config/nvim
+	merlin adopt ~/.wgetrc --tool wget --dry-run
+	merlin adopt ~/.vimrc ~/.vim --tool vim`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runAdopt(cmd, args); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(adoptCmd)
+	adoptCmd.Flags().String("tool", "", "Tool to create (default: derived from each path)")
+}
+
+func runAdopt(cmd *cobra.Command, args []string) error {
+	tool, _ := cmd.Flags().GetString("tool")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	repo, err := config.FindDotfilesRepo()
+	if err != nil {
+		return fmt.Errorf("dotfiles repository not found: %w", err)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("get home directory: %w", err)
+	}
+
+	paths := make([]string, 0, len(args))
+	for _, arg := range args {
+		path := protect.Expand(arg, home)
+		if _, err := os.Lstat(path); err != nil {
+			return fmt.Errorf("cannot adopt %s: %w", arg, err)
+		}
+		paths = append(paths, path)
+	}
+
+	plan, err := migrate.AnalyzeHome(paths, home, tool)
+	if err != nil {
+		return err
+	}
+	if len(plan.Tools) == 0 {
+		migrate.PrintReport(os.Stdout, plan)
+		return fmt.Errorf("nothing to adopt")
+	}
+
+	if dryRun {
+		for _, t := range plan.Tools {
+			fmt.Printf("# %s\n%s\n", repo.Rel(repo.GetToolMerlinConfig(t.Name)), migrate.ToolTOML(t))
+		}
+		fmt.Printf("Would write %d tool(s) to %s:\n", len(plan.Tools), repo.ConfigDir)
+		migrate.PrintReport(os.Stdout, plan)
+		fmt.Println("\nThis was a dry run. No files were written.")
+		return nil
+	}
+
+	if err := migrate.WriteTools(plan, repo.Root, repo.ConfigDir); err != nil {
+		return err
+	}
+	if len(plan.Tools) == 0 {
+		migrate.PrintReport(os.Stdout, plan)
+		return fmt.Errorf("nothing adopted")
+	}
+
+	fmt.Printf("Adopted %d tool(s) into %s:\n", len(plan.Tools), repo.ConfigDir)
+	migrate.PrintReport(os.Stdout, plan)
+	fmt.Println("\nNext steps:")
+	for _, t := range plan.Tools {
+		fmt.Printf("  merlin link %s --strategy overwrite   # replace the originals with links to the copies\n", t.Name)
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ildx/merlin/internal/cli"
+	"github.com/ildx/merlin/internal/config"
+	"github.com/ildx/merlin/internal/installer"
+	"github.com/ildx/merlin/internal/models"
+	"github.com/ildx/merlin/internal/parser"
+	"github.com/ildx/merlin/internal/system"
+	"github.com/spf13/cobra"
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Check declared packages against what is installed",
+	Long: `Report declarations that no longer describe the machine well.
+
+SUBCOMMANDS
+	brew   Compare brew leaves with brew.toml
+
+See also: merlin diff (missing and extra packages)`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+var auditBrewCmd = &cobra.Command{
+	Use:   "brew",
+	Short: "Compare Homebrew leaf formulae with brew.toml",
+	Long: `Compare 'brew leaves' (formulae nothing else depends on) with brew.toml.
+
+BEHAVIOR
+	Two lists are printed; nothing is changed unless --capture is given:
+	• Leaves not in brew.toml: installed on purpose but never declared.
+	  Add them to brew.toml or uninstall them.
+	• Declared but only dependencies: formulae in brew.toml that other
+	  installed formulae already pull in, listed with what requires them.
+	  They can usually be dropped from brew.toml.
+	Declared formulae that aren't installed are reported by 'merlin diff'.
+
+	--capture appends the undeclared leaves to brew.toml as [[brew]]
+	entries with their description, added_by and today's added_date,
+	leaving the rest of the file as written. Give --reason to record why
+	they're installed.
+
+FLAGS
+	--json           Print the audit as JSON
+	--capture        Append undeclared leaves to brew.toml
+	--reason <text>  Reason recorded on captured entries
+	--dry-run        Show the entries --capture would append
+
+EXAMPLES
+	merlin audit brew
+	merlin audit brew --json
+	merlin audit brew --capture --reason "image tooling for work"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runAuditBrew(cmd); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+	auditCmd.AddCommand(auditBrewCmd)
+
+	auditBrewCmd.Flags().Bool("json", false, "Print the audit as JSON")
+	auditBrewCmd.Flags().Bool("capture", false, "Append undeclared leaves to brew.toml")
+	auditBrewCmd.Flags().String("reason", "", "Reason recorded on captured entries")
+}
+
+func runAuditBrew(cmd *cobra.Command) error {
+	asJSON, _ := cmd.Flags().GetBool("json")
+	capture, _ := cmd.Flags().GetBool("capture")
+
+	if brewCheck := system.CheckHomebrew(); !brewCheck.Exists {
+		return brewCheck.Error
+	}
+	repo, err := config.FindDotfilesRepo()
+	if err != nil {
+		return fmt.Errorf("dotfiles repository not found: %w", err)
+	}
+	declared, err := parser.ParseBrewConfig(repo.GetToolConfigDir("brew"))
+	if err != nil {
+		return err
+	}
+
+	audit, err := installer.AuditBrew(nil, declared)
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(audit); err != nil {
+			return err
+		}
+	} else {
+		printBrewAudit(cmd, audit)
+	}
+	if capture {
+		return captureLeaves(cmd, repo.GetToolConfigDir("brew"), audit.Undeclared)
+	}
+	return nil
+}
+
+func printBrewAudit(cmd *cobra.Command, audit *installer.BrewAudit) {
+
+	fmt.Printf("\n🍃 Leaves not in brew.toml (%d):\n", len(audit.Undeclared))
+	fmt.Print(cli.BulletList(audit.Undeclared))
+
+	fmt.Printf("\n🔗 Declared but only dependencies (%d):\n", len(audit.DependencyOnly))
+	if len(audit.DependencyOnly) > 0 {
+		table := newTable(cmd, "FORMULA", "REQUIRED BY")
+		for _, d := range audit.DependencyOnly {
+			table.AddRow(d.Name, strings.Join(d.RequiredBy, ", "))
+		}
+		table.Render(os.Stdout)
+	}
+
+	if len(audit.Undeclared) == 0 && len(audit.DependencyOnly) == 0 {
+		cli.Success("brew.toml matches the installed leaves")
+	}
+}
+
+// captureLeaves appends leaves to brew.toml in brewDir, recording where and
+// when they were captured
+func captureLeaves(cmd *cobra.Command, brewDir string, leaves []string) error {
+	if len(leaves) == 0 {
+		return nil
+	}
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	reason, _ := cmd.Flags().GetString("reason")
+
+	descs, err := installer.BrewDescriptions(nil, leaves)
+	if err != nil {
+		cli.Warning("Could not look up descriptions: %v", err)
+	}
+	packages := make([]models.BrewPackage, 0, len(leaves))
+	for _, name := range leaves {
+		packages = append(packages, models.BrewPackage{
+			Name:        name,
+			Description: descs[name],
+			Reason:      reason,
+			AddedBy:     "merlin audit brew",
+			AddedDate:   models.Today(),
+		})
+	}
+
+	path := filepath.Join(brewDir, "brew.toml")
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("read %s: %w", path, err)
+	}
+	if dryRun {
+		fmt.Printf("\n[DRY RUN] Would append to %s:\n", path)
+		fmt.Print(parser.AppendBrewEntries("", "brew", packages))
+		return nil
+	}
+	if err := os.MkdirAll(brewDir, 0755); err != nil {
+		return fmt.Errorf("create %s: %w", brewDir, err)
+	}
+	if err := os.WriteFile(path, []byte(parser.AppendBrewEntries(string(data), "brew", packages)), 0644); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	cli.Success("Captured %d leaves in %s", len(packages), path)
+	return nil
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ildx/merlin/internal/cli"
+	"github.com/ildx/merlin/internal/git"
+	"github.com/ildx/merlin/internal/models"
+)
+
+// maxBlockingPaths is how many unrelated changes a skipped auto-commit lists
+const maxBlockingPaths = 10
+
+// batchWindow returns [settings.git] batch_window; zero (unset or invalid,
+// which validate reports) commits every operation right away
+func batchWindow(settings models.GitSettings) time.Duration {
+	window, err := time.ParseDuration(settings.BatchWindow)
+	if err != nil || window < 0 {
+		return 0
+	}
+	return window
+}
+
+// queueAutoCommit stages paths and adds info to the repository's pending
+// batch. A batch that has already been quiet for the window is committed
+// first, so the new operation starts the next one.
+func queueAutoCommit(repoGit *git.Repo, settings models.GitSettings, info git.CommitInfo, paths []string) {
+	if due, err := repoGit.BatchDue(batchWindow(settings), time.Now()); err == nil && due {
+		flushAutoCommits(repoGit, settings)
+	}
+	if err := repoGit.QueueCommit(info, paths); err != nil {
+		cli.Warning("auto-commit not queued: %v", err)
+		return
+	}
+	cli.Success("Auto-commit queued (%s); 'merlin repo flush' commits now", info.Summary)
+}
+
+// flushAutoCommits commits the pending batch, reporting the outcome
+func flushAutoCommits(repoGit *git.Repo, settings models.GitSettings) {
+	msg, err := repoGit.FlushCommits(settings.CommitTemplate)
+	switch {
+	case err != nil:
+		cli.Warning("auto-commit batch failed: %v", err)
+	case msg != "":
+		cli.Success("Auto-commit created (%s)", msg)
+	}
+}
+
+// autoCommitBlocked reports whether changes outside paths block the
+// auto-commit described by info. A blocked commit is recorded for merlin repo
+// commit, and the blocking paths are listed with the ways forward.
+// commitAnyway (--commit-anyway) skips the check.
+func autoCommitBlocked(repoGit *git.Repo, info git.CommitInfo, paths []string, commitAnyway bool) bool {
+	if commitAnyway {
+		return false
+	}
+	unrelated, err := repoGit.FindUnrelatedChanges(paths)
+	if err != nil || unrelated.Empty() {
+		return false
+	}
+	if err := repoGit.RecordBlockedCommit(info, paths); err != nil {
+		cli.Warning("blocked auto-commit not recorded: %v", err)
+	}
+
+	blocking := len(unrelated.Paths())
+	cli.Warning("auto-commit (%s) skipped: %d unrelated change(s) in the repository", info.Summary, blocking)
+	listed := 0
+	for _, group := range []struct {
+		code  string
+		paths []string
+	}{{"UU", unrelated.Conflicted}, {" M", unrelated.Unstaged}, {"??", unrelated.Untracked}} {
+		for _, p := range group.paths {
+			if listed == maxBlockingPaths {
+				break
+			}
+			fmt.Printf("    %s %s\n", group.code, p)
+			listed++
+		}
+	}
+	if blocking > listed {
+		fmt.Printf("    … and %d more (git status)\n", blocking-listed)
+	}
+	fmt.Println("  Commit or stash them, then run 'merlin repo commit' to create the skipped commit.")
+	fmt.Println("  Changes that belong with it: 'merlin repo commit --include <path>'.")
+	fmt.Println("  To commit only this operation's files next time, pass --commit-anyway.")
+	return true
+}
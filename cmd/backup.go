@@ -6,8 +6,8 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
-	"text/tabwriter"
 	"time"
 
 	"github.com/ildx/merlin/internal/backup"
@@ -15,6 +15,9 @@ import (
 	"github.com/ildx/merlin/internal/config"
 	"github.com/ildx/merlin/internal/git"
 	"github.com/ildx/merlin/internal/parser"
+	"github.com/ildx/merlin/internal/protect"
+	"github.com/ildx/merlin/internal/symlink"
+	"github.com/ildx/merlin/internal/system"
 	"github.com/spf13/cobra"
 )
 
@@ -31,10 +34,15 @@ var backupCreateCmd = &cobra.Command{
 	Use:   "create [files...]",
 	Short: "Create a backup of specified files",
 	Long: `Create a new backup of one or more configuration files.
+
+Use --linked to snapshot every file currently present at a link target
+(all tools, or only those in --profile) without listing them by hand.
 	
 Examples:
   merlin backup create ~/.zshrc ~/.gitconfig --reason "Before major changes"
-  merlin backup create ~/covenant/config/zsh/config/*.zsh`,
+  merlin backup create ~/covenant/config/zsh/config/*.zsh
+  merlin backup create --linked --reason "Before experimenting"
+  merlin backup create --linked --profile work`,
 	RunE: runBackupCreate,
 }
 
@@ -42,8 +50,20 @@ var backupListCmd = &cobra.Command{
 	Use:     "list",
 	Aliases: []string{"ls"},
 	Short:   "List all available backups",
-	Long:    `Show all backups with their IDs, timestamps, reasons, and file counts.`,
-	RunE:    runBackupList,
+	Long: `Show all backups with their IDs, timestamps, reasons, and file counts.
+
+Every backup records the operation (one merlin invocation) that created it.
+Backups from the same operation, such as the one-per-conflict backups of
+"merlin link --all --strategy backup", are listed as a single batch under
+the operation ID, with the tools they were made for; restoring that ID
+restores the whole batch. Use --operation to list the backups of one batch,
+or --flat to list every backup on its own row.
+
+Examples:
+  merlin backup list
+  merlin backup list --operation op_20250108_143022_a1b2
+  merlin backup list --flat`,
+	RunE: runBackupList,
 }
 
 var backupShowCmd = &cobra.Command{
@@ -55,40 +75,108 @@ var backupShowCmd = &cobra.Command{
 }
 
 var backupRestoreCmd = &cobra.Command{
-	Use:   "restore <backup-id>",
+	Use:   "restore <backup-id|operation-id>",
 	Short: "Restore files from a backup",
 	Long: `Restore configuration files from a previous backup.
 	
 By default, all files in the backup are restored. Use --files to restore
 specific files only.
 
+Given an operation ID (op_..., as listed by 'merlin backup list'), every
+backup of that operation is restored, e.g. all the files a link run with
+--strategy backup replaced. A file backed up more than once in the
+operation gets its oldest version, from before the operation started.
+
+Before overwriting anything, the current versions of the files being
+restored are saved to a new "pre-restore of <id>" backup, so a mistaken
+restore can itself be undone. Use --no-safety-backup to skip this.
+
+Backups taken under a different home directory (another machine or a renamed
+user) are restored into the current home automatically. Use --map old=new
+(repeatable) to remap any other path prefix.
+
+Every file is verified and restored on its own, so a checksum failure
+doesn't stop the rest. A table lists each file as restored, skipped
+(already matches the backup) or failed, noting targets modified after the
+backup was taken. Use --dry-run to preview it without writing anything.
+
 Examples:
   merlin backup restore 20250108_143022
-  merlin backup restore 20250108_143022 --files ~/.zshrc,~/.gitconfig`,
+  merlin backup restore 20250108_143022 --files ~/.zshrc,~/.gitconfig
+  merlin backup restore 20250108_143022 --no-safety-backup
+  merlin backup restore 20250108_143022 --map /Volumes/old=/Volumes/new
+  merlin backup restore 20250108_143022 --dry-run
+  merlin backup restore op_20250108_143022_a1b2`,
 	Args: cobra.ExactArgs(1),
 	RunE: runBackupRestore,
 }
 
+var backupRestoreFileCmd = &cobra.Command{
+	Use:   "restore-file <path>",
+	Short: "Restore one file from the backup holding the version you want",
+	Long: `Find every backup containing a file and restore one of its versions.
+
+Without --version or --at, the versions are listed newest first (numbered
+from 1) and, in a terminal, you are asked which one to restore. --version N
+picks the Nth newest; --at picks the newest version taken at or before a
+date ("2025-01-08" means the end of that day, "2025-01-08 14:30" a minute).
+
+Like restore, the current file is first saved to a "pre-restore" backup
+unless --no-safety-backup is given, and --dry-run only previews.
+
+Examples:
+  merlin backup restore-file ~/.zshrc
+  merlin backup restore-file ~/.zshrc --version 2
+  merlin backup restore-file ~/.config/nvim/init.lua --at 2025-01-08
+  merlin backup restore-file ~/.gitconfig --at "2025-01-08 14:30" --dry-run`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBackupRestoreFile,
+}
+
 var backupCleanCmd = &cobra.Command{
 	Use:   "clean",
 	Short: "Delete old backups",
-	Long: `Remove backups older than a specified number of days.
-	
+	Long: `Remove old backups, listing each with its age and size on disk and the
+total space reclaimed.
+
 Use --keep to specify how many recent backups to preserve.
 Use --older-than to delete backups older than N days.
+With both, --keep wins: the N newest backups are always kept, and of the
+rest only those older than the given days are deleted.
+Use --dry-run to list what would be deleted without deleting anything.
 
 Examples:
   merlin backup clean --keep 5
-  merlin backup clean --older-than 30`,
+  merlin backup clean --older-than 30
+  merlin backup clean --keep 5 --older-than 30 --dry-run`,
 	RunE: runBackupClean,
 }
 
 var backupDeleteCmd = &cobra.Command{
 	Use:   "delete <backup-id>",
 	Short: "Delete a specific backup",
-	Long:  `Permanently remove a backup and all its files.`,
-	Args:  cobra.ExactArgs(1),
-	RunE:  runBackupDelete,
+	Long: `Permanently remove a backup and all its files. Use --dry-run to show
+the backup and its size without deleting it.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBackupDelete,
+}
+
+var backupMoveStoreCmd = &cobra.Command{
+	Use:   "move-store <path>",
+	Short: "Move all backups to a new directory",
+	Long: `Move every existing backup into a new directory, e.g. an external disk or a
+synced folder, and rewrite the manifests to match.
+
+Backups are read from the backup location (backup_dir in merlin.toml or
+MERLIN_BACKUP_DIR), every backup_roots entry and ~/.merlin/backups. After
+moving, set backup_dir or MERLIN_BACKUP_DIR to the new path so new backups
+are written there as well.
+
+Examples:
+  merlin backup move-store /Volumes/External/merlin-backups
+  merlin backup move-store ~/Dropbox/merlin-backups`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBackupMoveStore,
 }
 
 var (
@@ -98,6 +186,15 @@ var (
 	backupOlderThan    int
 	backupForce        bool
 	backupNoAutoCommit bool
+	backupCommitAnyway bool
+	backupLinked       bool
+	backupProfile      string
+	backupNoSafety     bool
+	backupPathMaps     []string
+	backupOperation    string
+	backupFlat         bool
+	backupVersion      int
+	backupAt           string
 )
 
 func init() {
@@ -107,16 +204,33 @@ func init() {
 	backupCmd.AddCommand(backupListCmd)
 	backupCmd.AddCommand(backupShowCmd)
 	backupCmd.AddCommand(backupRestoreCmd)
+	backupCmd.AddCommand(backupRestoreFileCmd)
 	backupCmd.AddCommand(backupCleanCmd)
 	backupCmd.AddCommand(backupDeleteCmd)
+	backupCmd.AddCommand(backupMoveStoreCmd)
 
 	// Create flags
 	backupCreateCmd.Flags().StringVarP(&backupReason, "reason", "r", "", "Reason for creating this backup")
 	backupCreateCmd.Flags().BoolVar(&backupNoAutoCommit, "no-auto-commit", false, "Disable auto-commit even if enabled in settings")
+	backupCreateCmd.Flags().BoolVar(&backupCommitAnyway, "commit-anyway", false, "Auto-commit the backup index even if other files changed")
+	backupCreateCmd.Flags().BoolVar(&backupLinked, "linked", false, "Back up every file currently present at a link target")
+	backupCreateCmd.Flags().StringVar(&backupProfile, "profile", "", "With --linked, only include tools from this profile")
+
+	// List flags
+	backupListCmd.Flags().StringVar(&backupOperation, "operation", "", "Only show backups created by this operation ID")
+	backupListCmd.Flags().BoolVar(&backupFlat, "flat", false, "List every backup instead of grouping them by operation")
 
 	// Restore flags
 	backupRestoreCmd.Flags().StringVar(&backupFiles, "files", "", "Comma-separated list of files to restore (default: all)")
 	backupRestoreCmd.Flags().BoolVar(&backupForce, "force", false, "Skip confirmation prompt")
+	backupRestoreCmd.Flags().StringArrayVar(&backupPathMaps, "map", nil, "Remap a path prefix as old=new (repeatable)")
+	backupRestoreCmd.Flags().BoolVar(&backupNoSafety, "no-safety-backup", false, "Don't back up current files before overwriting them")
+
+	// Restore-file flags
+	backupRestoreFileCmd.Flags().IntVar(&backupVersion, "version", 0, "Restore the Nth newest version (1 = newest)")
+	backupRestoreFileCmd.Flags().StringVar(&backupAt, "at", "", "Restore the newest version taken at or before this date (YYYY-MM-DD [HH:MM])")
+	backupRestoreFileCmd.Flags().BoolVar(&backupForce, "force", false, "Skip confirmation prompt")
+	backupRestoreFileCmd.Flags().BoolVar(&backupNoSafety, "no-safety-backup", false, "Don't back up the current file before overwriting it")
 
 	// Clean flags
 	backupCleanCmd.Flags().IntVar(&backupKeep, "keep", 0, "Number of recent backups to keep (default: keep all)")
@@ -125,16 +239,33 @@ func init() {
 }
 
 func runBackupCreate(cmd *cobra.Command, args []string) error {
-	if len(args) == 0 {
+	if backupProfile != "" && !backupLinked {
+		return fmt.Errorf("--profile requires --linked")
+	}
+	if len(args) == 0 && !backupLinked {
 		return fmt.Errorf("no files specified for backup")
 	}
 
 	if backupReason == "" {
-		backupReason = "Manual backup"
+		if backupLinked {
+			backupReason = "Snapshot of linked configs"
+		} else {
+			backupReason = "Manual backup"
+		}
 	}
 
 	// Expand globs in file arguments
 	var expandedFiles []string
+	if backupLinked {
+		linked, err := collectLinkedTargets(backupProfile)
+		if err != nil {
+			return err
+		}
+		if len(linked) == 0 {
+			return fmt.Errorf("no linked files found to back up")
+		}
+		expandedFiles = append(expandedFiles, linked...)
+	}
 	for _, pattern := range args {
 		matches, err := filepath.Glob(pattern)
 		if err != nil {
@@ -171,11 +302,14 @@ func runBackupCreate(cmd *cobra.Command, args []string) error {
 				if wErr != nil {
 					cli.Warning("backup index update failed: %v", wErr)
 				} else {
+					info := git.BackupCommit(manifest.ID, len(manifest.Files))
 					// Safety: ensure no unrelated changes outside index file
-					if unrelated, uErr := repoGit.HasUnrelatedChanges([]string{relPath}); uErr == nil && unrelated {
-						cli.Warning("auto-commit (backup) skipped: unrelated changes detected")
+					if autoCommitBlocked(repoGit, info, []string{relPath}, backupCommitAnyway) {
+						// reported with the blocking paths
+					} else if batchWindow(rootCfg.Settings.Git) > 0 {
+						queueAutoCommit(repoGit, rootCfg.Settings.Git, info, []string{relPath})
 					} else {
-						msg := buildBackupCommitMessage(manifest)
+						msg := git.CommitMessage(rootCfg.Settings.Git.CommitTemplate, info)
 						if cErr := repoGit.Commit(msg, []string{relPath}); cErr != nil {
 							if strings.Contains(cErr.Error(), "no staged changes") {
 								// Allow empty commit to preserve audit trail
@@ -200,39 +334,119 @@ func runBackupCreate(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// collectLinkedTargets lists regular files present at the link targets of all
+// discovered tools, optionally restricted to a profile.
+func collectLinkedTargets(profileName string) ([]string, error) {
+	repo, err := config.FindDotfilesRepo()
+	if err != nil {
+		return nil, err
+	}
+	rootConfig, err := parser.ParseRootMerlinTOML(repo.GetRootMerlinConfig())
+	if err != nil {
+		return nil, fmt.Errorf("parsing root config: %w", err)
+	}
+	vars, err := symlink.GetVariablesFromRoot(rootConfig)
+	if err != nil {
+		return nil, fmt.Errorf("getting variables: %w", err)
+	}
+	tools, err := symlink.DiscoverTools(repo, vars)
+	if err != nil {
+		return nil, fmt.Errorf("discovering tools: %w", err)
+	}
+	if profileName != "" {
+		if tools, err = filterToolsByProfile(tools, rootConfig, profileName); err != nil {
+			return nil, err
+		}
+	}
+
+	seen := make(map[string]bool)
+	var files []string
+	for _, tool := range tools {
+		targets, err := symlink.TargetFiles(tool)
+		if err != nil {
+			cli.Warning("%s: %v", tool.Name, err)
+			continue
+		}
+		for _, f := range targets {
+			if !seen[f] {
+				seen[f] = true
+				files = append(files, f)
+			}
+		}
+	}
+	return files, nil
+}
+
 func runBackupList(cmd *cobra.Command, args []string) error {
 	backups, err := backup.ListBackups()
 	if err != nil {
 		return fmt.Errorf("list backups: %w", err)
 	}
 
+	if backupOperation != "" {
+		backups = backup.FilterByOperation(backups, backupOperation)
+		if len(backups) == 0 {
+			fmt.Printf("No backups found for operation %s.\n", backupOperation)
+			return nil
+		}
+	}
+
 	if len(backups) == 0 {
 		fmt.Println("No backups found.")
 		fmt.Println("\nCreate a backup with: merlin backup create <files...>")
 		return nil
 	}
 
-	fmt.Printf("Found %d backup(s):\n\n", len(backups))
+	if backupOperation == "" && !backupFlat {
+		return printBackupBatches(cmd, backups)
+	}
 
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
-	fmt.Fprintln(w, "ID\tTIMESTAMP\tFILES\tREASON")
-	fmt.Fprintln(w, "--\t---------\t-----\t------")
+	fmt.Printf("Found %d backup(s):\n\n", len(backups))
 
+	table := newTable(cmd, "ID", "TIMESTAMP", "FILES", "TOOL", "OPERATION", "REASON").Fixed(0).Fixed(1).Fixed(2).Fixed(4)
 	for _, b := range backups {
 		timestamp := b.Timestamp.Format("2006-01-02 15:04:05")
-		reason := b.Reason
-		if len(reason) > 40 {
-			reason = reason[:37] + "..."
+		operation := b.Operation
+		if operation == "" {
+			operation = "-"
 		}
-		fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", b.ID, timestamp, len(b.Files), reason)
+		tool := b.Tool
+		if tool == "" {
+			tool = "-"
+		}
+		table.AddRow(b.ID, timestamp, fmt.Sprintf("%d", len(b.Files)), tool, operation, b.Reason)
 	}
-
-	w.Flush()
+	table.Render(os.Stdout)
 	fmt.Println("\nUse 'merlin backup show <id>' for detailed information")
 
 	return nil
 }
 
+// printBackupBatches lists backups grouped by the operation that created them
+func printBackupBatches(cmd *cobra.Command, backups []*backup.BackupManifest) error {
+	batches := backup.GroupByOperation(backups)
+	fmt.Printf("Found %d backup(s) in %d batch(es):\n\n", len(backups), len(batches))
+
+	table := newTable(cmd, "ID", "TIMESTAMP", "FILES", "TOOLS", "REASON").Fixed(0).Fixed(1).Fixed(2)
+	grouped := false
+	for _, b := range batches {
+		tools := strings.Join(b.Tools(), ", ")
+		if tools == "" {
+			tools = "-"
+		}
+		if len(b.Backups) > 1 {
+			grouped = true
+		}
+		table.AddRow(b.ID(), b.Timestamp().Format("2006-01-02 15:04:05"), fmt.Sprintf("%d", b.Files()), tools, b.Reason())
+	}
+	table.Render(os.Stdout)
+	fmt.Println("\nUse 'merlin backup show <id>' for detailed information")
+	if grouped {
+		fmt.Println(cli.Dim("op_ IDs group several backups: list them with --operation <id>, restore them all with 'merlin backup restore <id>'"))
+	}
+	return nil
+}
+
 func runBackupShow(cmd *cobra.Command, args []string) error {
 	backupID := args[0]
 
@@ -244,19 +458,19 @@ func runBackupShow(cmd *cobra.Command, args []string) error {
 	fmt.Printf("Backup: %s\n", manifest.ID)
 	fmt.Printf("Created: %s\n", manifest.Timestamp.Format("2006-01-02 15:04:05"))
 	fmt.Printf("Reason: %s\n", manifest.Reason)
+	if manifest.Operation != "" {
+		fmt.Printf("Operation: %s\n", manifest.Operation)
+	}
+	fmt.Printf("Location: %s\n", manifest.Dir())
 	fmt.Printf("Files: %d\n\n", len(manifest.Files))
 
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
-	fmt.Fprintln(w, "ORIGINAL PATH\tSIZE\tCHECKSUM")
-	fmt.Fprintln(w, "-------------\t----\t--------")
-
+	table := newTable(cmd, "ORIGINAL PATH", "SIZE", "CHECKSUM").TruncateMiddle(0).Fixed(1).Fixed(2)
 	for _, entry := range manifest.Files {
 		sizeKB := float64(entry.Size) / 1024
 		checksum := entry.Checksum[:12] + "..." // Show first 12 chars
-		fmt.Fprintf(w, "%s\t%.1f KB\t%s\n", entry.OriginalPath, sizeKB, checksum)
+		table.AddRow(entry.OriginalPath, fmt.Sprintf("%.1f KB", sizeKB), checksum)
 	}
-
-	w.Flush()
+	table.Render(os.Stdout)
 	fmt.Printf("\nRestore with: merlin backup restore %s\n", manifest.ID)
 
 	return nil
@@ -264,6 +478,9 @@ func runBackupShow(cmd *cobra.Command, args []string) error {
 
 func runBackupRestore(cmd *cobra.Command, args []string) error {
 	backupID := args[0]
+	if backup.IsOperationID(backupID) {
+		return runBackupRestoreOperation(cmd, backupID)
+	}
 
 	// Load backup info
 	manifest, err := backup.GetBackupInfo(backupID)
@@ -280,11 +497,24 @@ func runBackupRestore(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	mapping, err := backup.ParsePathMap(backupPathMaps)
+	if err != nil {
+		return err
+	}
+
 	// Show what will be restored
 	fmt.Printf("Backup: %s\n", manifest.ID)
 	fmt.Printf("Created: %s\n", manifest.Timestamp.Format("2006-01-02 15:04:05"))
 	fmt.Printf("Reason: %s\n\n", manifest.Reason)
 
+	if pairs := backup.RestorePathMap(manifest, mapping).Pairs(); len(pairs) > 0 {
+		fmt.Println("Remapping paths:")
+		for _, pair := range pairs {
+			fmt.Printf("  • %s\n", pair)
+		}
+		fmt.Println()
+	}
+
 	if len(selectiveFiles) > 0 {
 		fmt.Printf("Will restore %d file(s):\n", len(selectiveFiles))
 		for _, f := range selectiveFiles {
@@ -294,6 +524,17 @@ func runBackupRestore(cmd *cobra.Command, args []string) error {
 		fmt.Printf("Will restore all %d file(s) from backup\n", len(manifest.Files))
 	}
 
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	if dryRun {
+		results, err := backup.RestoreBackupResults(backupID, selectiveFiles, mapping, true)
+		if err != nil {
+			return fmt.Errorf("restore backup: %w", err)
+		}
+		fmt.Println()
+		printRestoreResults(cmd, results, true)
+		return nil
+	}
+
 	// Confirmation prompt (unless --force)
 	if !backupForce {
 		fmt.Print("\n⚠️  This will overwrite existing files. Continue? [y/N]: ")
@@ -306,64 +547,358 @@ func runBackupRestore(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	var safety *backup.BackupManifest
+	if !backupNoSafety {
+		safety, err = backup.CreateSafetyBackup(backupID, selectiveFiles, mapping)
+		if err != nil {
+			return fmt.Errorf("create safety backup: %w", err)
+		}
+		if safety != nil {
+			fmt.Printf("\n💾 Saved current files to backup %s\n", safety.ID)
+		}
+	}
+
 	fmt.Println("\nRestoring files...")
-	if err := backup.RestoreBackup(backupID, selectiveFiles); err != nil {
+	results, err := backup.RestoreBackupResults(backupID, selectiveFiles, mapping, false)
+	if err != nil {
 		return fmt.Errorf("restore backup: %w", err)
 	}
-
-	fmt.Println("✅ Backup restored successfully")
+	failed := printRestoreResults(cmd, results, false)
+	if safety != nil {
+		fmt.Printf("Undo with: merlin backup restore %s\n", safety.ID)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d file(s) could not be restored", failed)
+	}
 
 	return nil
 }
 
-func runBackupClean(cmd *cobra.Command, args []string) error {
-	backups, err := backup.ListBackups()
+// runBackupRestoreOperation restores every backup an operation created.
+// Backups are restored newest first, so a file backed up twice ends up with
+// its oldest version. The safety backups all belong to the current
+// operation, so restoring that operation undoes the restore.
+func runBackupRestoreOperation(cmd *cobra.Command, operation string) error {
+	all, err := backup.ListBackups()
 	if err != nil {
 		return fmt.Errorf("list backups: %w", err)
 	}
-
+	backups := backup.FilterByOperation(all, operation)
 	if len(backups) == 0 {
-		fmt.Println("No backups to clean.")
+		return fmt.Errorf("no backups found for operation %s", operation)
+	}
+	batch := backup.GroupByOperation(backups)[0]
+
+	var selectiveFiles []string
+	if backupFiles != "" {
+		selectiveFiles = strings.Split(backupFiles, ",")
+		for i := range selectiveFiles {
+			selectiveFiles[i] = strings.TrimSpace(selectiveFiles[i])
+		}
+	}
+	mapping, err := backup.ParsePathMap(backupPathMaps)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Operation: %s\n", operation)
+	fmt.Printf("Created: %s\n", batch.Timestamp().Format("2006-01-02 15:04:05"))
+	if tools := batch.Tools(); len(tools) > 0 {
+		fmt.Printf("Tools: %s\n", strings.Join(tools, ", "))
+	}
+	fmt.Printf("Backups: %d (%d file(s))\n\n", len(backups), batch.Files())
+
+	restore := func(dryRun bool) ([]backup.RestoreResult, error) {
+		var results []backup.RestoreResult
+		for _, m := range backups {
+			if len(selectiveFiles) > 0 && !backupHasAny(m, selectiveFiles, mapping) {
+				continue
+			}
+			r, err := backup.RestoreBackupResults(m.ID, selectiveFiles, mapping, dryRun)
+			if err != nil {
+				return results, fmt.Errorf("restore backup %s: %w", m.ID, err)
+			}
+			results = append(results, r...)
+		}
+		return results, nil
+	}
+
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	if dryRun {
+		results, err := restore(true)
+		if err != nil {
+			return err
+		}
+		printRestoreResults(cmd, results, true)
 		return nil
 	}
 
-	var toDelete []*backup.BackupManifest
+	if !backupForce {
+		fmt.Print("⚠️  This will overwrite existing files. Continue? [y/N]: ")
+		var response string
+		fmt.Scanln(&response)
+		response = strings.ToLower(strings.TrimSpace(response))
+		if response != "y" && response != "yes" {
+			fmt.Println("Restore cancelled.")
+			return nil
+		}
+	}
 
-	// Delete based on --keep flag
-	if backupKeep > 0 {
-		if len(backups) > backupKeep {
-			toDelete = backups[backupKeep:]
+	safetyBackups := 0
+	if !backupNoSafety {
+		for _, m := range backups {
+			safety, err := backup.CreateSafetyBackup(m.ID, selectiveFiles, mapping)
+			if err != nil {
+				return fmt.Errorf("create safety backup: %w", err)
+			}
+			if safety != nil {
+				safetyBackups++
+			}
+		}
+		if safetyBackups > 0 {
+			fmt.Printf("\n💾 Saved current files to %d backup(s)\n", safetyBackups)
 		}
 	}
 
-	// Delete based on --older-than flag
-	if backupOlderThan > 0 {
-		cutoff := time.Now().AddDate(0, 0, -backupOlderThan)
-		for _, b := range backups {
-			if b.Timestamp.Before(cutoff) {
-				// Check if not already in toDelete list
-				found := false
-				for _, d := range toDelete {
-					if d.ID == b.ID {
-						found = true
-						break
-					}
-				}
-				if !found {
-					toDelete = append(toDelete, b)
-				}
+	fmt.Println("\nRestoring files...")
+	results, err := restore(false)
+	if err != nil {
+		return err
+	}
+	failed := printRestoreResults(cmd, results, false)
+	if safetyBackups > 0 {
+		fmt.Printf("Undo with: merlin backup restore %s\n", backup.Operation())
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d file(s) could not be restored", failed)
+	}
+	return nil
+}
+
+// backupHasAny reports whether manifest holds any of files, by original or
+// remapped path
+func backupHasAny(manifest *backup.BackupManifest, files []string, mapping backup.PathMap) bool {
+	pathMap := backup.RestorePathMap(manifest, mapping)
+	for _, entry := range manifest.Files {
+		for _, f := range files {
+			if f == entry.OriginalPath || f == pathMap.Apply(entry.OriginalPath) {
+				return true
 			}
 		}
 	}
+	return false
+}
+
+// printRestoreResults prints a table of per-file restore outcomes followed by
+// a summary line, and returns how many files failed (or would fail)
+func printRestoreResults(cmd *cobra.Command, results []backup.RestoreResult, dryRun bool) int {
+	table := newTable(cmd, "FILE", "STATUS", "NOTE").TruncateMiddle(0)
+	counts := make(map[backup.RestoreStatus]int)
+	warnings := 0
+	for _, result := range results {
+		counts[result.Status]++
+		note := result.Warning
+		if result.Err != nil {
+			note = result.Err.Error()
+		}
+		if result.Warning != "" {
+			warnings++
+		}
+		table.AddRow(result.Path, string(result.Status), note)
+	}
+	table.Render(os.Stdout)
+	fmt.Println()
+
+	summary := fmt.Sprintf("%d skipped (already up to date), %d failed", counts[backup.RestoreSkipped], counts[backup.RestoreFailed])
+	if warnings > 0 {
+		summary += fmt.Sprintf(", %d newer than the backup", warnings)
+	}
+	switch {
+	case dryRun:
+		fmt.Printf("🔍 Would restore %d file(s); %s\n", counts[backup.RestoreWouldRestore], summary)
+	case counts[backup.RestoreFailed] > 0:
+		cli.Warning("Restored %d file(s); %s", counts[backup.RestoreRestored], summary)
+	default:
+		cli.Success("Restored %d file(s); %s", counts[backup.RestoreRestored], summary)
+	}
+	return counts[backup.RestoreFailed]
+}
 
+func runBackupRestoreFile(cmd *cobra.Command, args []string) error {
+	if backupVersion != 0 && backupAt != "" {
+		return fmt.Errorf("--version and --at can't be combined")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("get home directory: %w", err)
+	}
+	path, err := filepath.Abs(protect.Expand(args[0], home))
+	if err != nil {
+		return fmt.Errorf("resolve %s: %w", args[0], err)
+	}
+
+	versions, err := backup.FileVersions(path)
+	if err != nil {
+		return fmt.Errorf("list backups: %w", err)
+	}
+	if len(versions) == 0 {
+		return fmt.Errorf("no backup contains %s", path)
+	}
+
+	var chosen *backup.FileVersion
+	switch {
+	case backupVersion != 0:
+		if backupVersion < 1 || backupVersion > len(versions) {
+			return fmt.Errorf("--version must be between 1 and %d", len(versions))
+		}
+		chosen = &versions[backupVersion-1]
+	case backupAt != "":
+		at, err := parseBackupDate(backupAt)
+		if err != nil {
+			return err
+		}
+		if chosen = backup.VersionAt(versions, at); chosen == nil {
+			return fmt.Errorf("no version of %s was backed up by %s (oldest: %s)", path, backupAt, versions[len(versions)-1].Backup.Timestamp.Format("2006-01-02 15:04:05"))
+		}
+	}
+
+	if chosen == nil {
+		printFileVersions(cmd, path, versions)
+		if !stdinIsTerminal() {
+			fmt.Println("\nRestore one with --version N or --at <date>")
+			return nil
+		}
+		fmt.Printf("\nVersion to restore [1-%d, Enter to cancel]: ", len(versions))
+		var response string
+		fmt.Scanln(&response)
+		n, err := strconv.Atoi(strings.TrimSpace(response))
+		if err != nil || n < 1 || n > len(versions) {
+			fmt.Println("Restore cancelled.")
+			return nil
+		}
+		chosen = &versions[n-1]
+		backupForce = true // choosing a version is the confirmation
+	}
+
+	id, original := chosen.Backup.ID, chosen.Entry.OriginalPath
+	fmt.Printf("Restoring %s from backup %s (%s)\n\n", path, id, chosen.Backup.Timestamp.Format("2006-01-02 15:04:05"))
+
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	if dryRun {
+		results, err := backup.RestoreBackupResults(id, []string{original}, nil, true)
+		if err != nil {
+			return fmt.Errorf("restore backup: %w", err)
+		}
+		printRestoreResults(cmd, results, true)
+		return nil
+	}
+
+	if !backupForce {
+		fmt.Print("⚠️  This will overwrite the current file. Continue? [y/N]: ")
+		var response string
+		fmt.Scanln(&response)
+		response = strings.ToLower(strings.TrimSpace(response))
+		if response != "y" && response != "yes" {
+			fmt.Println("Restore cancelled.")
+			return nil
+		}
+		fmt.Println()
+	}
+
+	var safety *backup.BackupManifest
+	if !backupNoSafety {
+		safety, err = backup.CreateSafetyBackup(id, []string{original}, nil)
+		if err != nil {
+			return fmt.Errorf("create safety backup: %w", err)
+		}
+		if safety != nil {
+			fmt.Printf("💾 Saved current file to backup %s\n\n", safety.ID)
+		}
+	}
+
+	results, err := backup.RestoreBackupResults(id, []string{original}, nil, false)
+	if err != nil {
+		return fmt.Errorf("restore backup: %w", err)
+	}
+	failed := printRestoreResults(cmd, results, false)
+	if safety != nil {
+		fmt.Printf("Undo with: merlin backup restore %s\n", safety.ID)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%s could not be restored", path)
+	}
+	return nil
+}
+
+// printFileVersions lists the backed up versions of path, newest first
+func printFileVersions(cmd *cobra.Command, path string, versions []backup.FileVersion) {
+	fmt.Printf("%d version(s) of %s:\n\n", len(versions), path)
+	table := newTable(cmd, "#", "TIMESTAMP", "BACKUP", "SIZE", "CHECKSUM", "REASON").Fixed(0).Fixed(1).Fixed(2).Fixed(3).Fixed(4)
+	for i, v := range versions {
+		table.AddRow(
+			strconv.Itoa(i+1),
+			v.Backup.Timestamp.Format("2006-01-02 15:04:05"),
+			v.Backup.ID,
+			fmt.Sprintf("%.1f KB", float64(v.Entry.Size)/1024),
+			v.Entry.Checksum[:12]+"...",
+			v.Backup.Reason,
+		)
+	}
+	table.Render(os.Stdout)
+}
+
+// backupDateLayouts are the formats accepted by --at, most precise first
+var backupDateLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04", "20060102_150405", "2006-01-02"}
+
+// parseBackupDate parses an --at date in local time; a bare day means the
+// end of that day
+func parseBackupDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range backupDateLayouts {
+		t, err := time.ParseInLocation(layout, value, time.Local)
+		if err != nil {
+			continue
+		}
+		switch layout {
+		case "2006-01-02":
+			t = t.Add(24*time.Hour - time.Nanosecond)
+		case "2006-01-02 15:04", "2006-01-02T15:04":
+			t = t.Add(time.Minute - time.Nanosecond)
+		}
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q (use YYYY-MM-DD, YYYY-MM-DD HH:MM or a backup ID)", value)
+}
+
+func runBackupClean(cmd *cobra.Command, args []string) error {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	backups, err := backup.ListBackups()
+	if err != nil {
+		return fmt.Errorf("list backups: %w", err)
+	}
+
+	if len(backups) == 0 {
+		fmt.Println("No backups to clean.")
+		return nil
+	}
+
+	now := time.Now()
+	toDelete := backupsToPrune(backups, backupKeep, backupOlderThan, now)
 	if len(toDelete) == 0 {
 		fmt.Println("No backups match deletion criteria.")
 		return nil
 	}
 
-	fmt.Printf("Will delete %d backup(s):\n\n", len(toDelete))
-	for _, b := range toDelete {
-		fmt.Printf("  • %s - %s (%d files)\n", b.ID, b.Timestamp.Format("2006-01-02 15:04"), len(b.Files))
+	if dryRun {
+		fmt.Printf("[DRY RUN] Would delete %d of %d backup(s):\n\n", len(toDelete), len(backups))
+	} else {
+		fmt.Printf("Will delete %d of %d backup(s):\n\n", len(toDelete), len(backups))
+	}
+	total := printBackupSizes(cmd, toDelete, now)
+	fmt.Printf("\nReclaimable: %s\n", cli.FormatBytes(total))
+	if dryRun {
+		return nil
 	}
 
 	// Confirmation prompt
@@ -390,6 +925,45 @@ func runBackupClean(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// printBackupSizes lists backups with their age and size on disk and
+// returns their total size
+func printBackupSizes(cmd *cobra.Command, backups []*backup.BackupManifest, now time.Time) int64 {
+	var total int64
+	table := newTable(cmd, "ID", "TIMESTAMP", "AGE", "FILES", "SIZE", "REASON").Fixed(0).Fixed(1).Fixed(2).Fixed(3).Fixed(4)
+	for _, b := range backups {
+		size := "?"
+		if bytes, err := system.DirSize(b.Dir()); err == nil {
+			total += bytes
+			size = cli.FormatBytes(bytes)
+		}
+		age := fmt.Sprintf("%dd", int(now.Sub(b.Timestamp).Hours()/24))
+		table.AddRow(b.ID, b.Timestamp.Format("2006-01-02 15:04"), age, fmt.Sprintf("%d", len(b.Files)), size, b.Reason)
+	}
+	table.Render(os.Stdout)
+	return total
+}
+
+// backupsToPrune returns the backups (sorted newest first) to delete: those
+// beyond the keep newest, those older than olderThanDays, or, with both
+// given, only those that are both. The keep newest are therefore never
+// deleted, however old. Zero disables either rule; with both zero nothing
+// is deleted.
+func backupsToPrune(backups []*backup.BackupManifest, keep, olderThanDays int, now time.Time) []*backup.BackupManifest {
+	if keep <= 0 && olderThanDays <= 0 {
+		return nil
+	}
+	cutoff := now.AddDate(0, 0, -olderThanDays)
+	var toDelete []*backup.BackupManifest
+	for i, b := range backups {
+		beyondKeep := keep <= 0 || i >= keep
+		tooOld := olderThanDays <= 0 || b.Timestamp.Before(cutoff)
+		if beyondKeep && tooOld {
+			toDelete = append(toDelete, b)
+		}
+	}
+	return toDelete
+}
+
 func runBackupDelete(cmd *cobra.Command, args []string) error {
 	backupID := args[0]
 
@@ -401,7 +975,16 @@ func runBackupDelete(cmd *cobra.Command, args []string) error {
 
 	fmt.Printf("Backup: %s\n", manifest.ID)
 	fmt.Printf("Created: %s\n", manifest.Timestamp.Format("2006-01-02 15:04:05"))
-	fmt.Printf("Files: %d\n\n", len(manifest.Files))
+	fmt.Printf("Files: %d\n", len(manifest.Files))
+	if size, err := system.DirSize(manifest.Dir()); err == nil {
+		fmt.Printf("Size: %s\n", cli.FormatBytes(size))
+	}
+	fmt.Println()
+
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		fmt.Printf("[DRY RUN] Would delete backup %s\n", manifest.ID)
+		return nil
+	}
 
 	// Confirmation
 	if !backupForce {
@@ -423,8 +1006,40 @@ func runBackupDelete(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func runBackupMoveStore(cmd *cobra.Command, args []string) error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("get home directory: %w", err)
+	}
+	dest, err := filepath.Abs(protect.Expand(args[0], home))
+	if err != nil {
+		return fmt.Errorf("resolve %s: %w", args[0], err)
+	}
+
+	result, err := backup.MoveStore(dest)
+	if result != nil && len(result.Moved) > 0 {
+		fmt.Printf("Moved %d backup(s) to %s\n", len(result.Moved), dest)
+	}
+	if err != nil {
+		return err
+	}
+	if len(result.Moved) == 0 {
+		fmt.Printf("No backups to move; %s already holds them all.\n", dest)
+	}
+	for _, id := range result.Skipped {
+		cli.Warning("Skipped %s: %s already has a backup with that ID", id, dest)
+	}
+
+	if current, err := backup.BackupLocation(); err == nil && current != dest {
+		fmt.Printf("\nNew backups are still written to %s. Point them at the new store with\n", current)
+		fmt.Printf("  backup_dir = %q   (under [settings] in merlin.toml)\n", dest)
+		fmt.Printf("or export %s=%s\n", backup.EnvVarBackupDir, dest)
+	}
+	return nil
+}
+
 // backupIndex is the JSON schema stored in the repo tracking backups created while working in this repo.
-// A lightweight audit trail independent of actual backup storage under ~/.merlin/backups.
+// A lightweight audit trail independent of actual backup storage (see backup.BackupLocation).
 type backupIndex struct {
 	Entries []backupIndexEntry `json:"entries"`
 }
@@ -470,8 +1085,3 @@ func updateBackupIndex(repoRoot string, manifest *backup.BackupManifest) (string
 	}
 	return rel, nil
 }
-
-// buildBackupCommitMessage builds commit message for a backup auto-commit.
-func buildBackupCommitMessage(manifest *backup.BackupManifest) string {
-	return fmt.Sprintf("chore(backup): record %s (%d files)", manifest.ID, len(manifest.Files))
-}
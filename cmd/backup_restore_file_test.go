@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseBackupDate(t *testing.T) {
+	day := time.Date(2025, 1, 8, 0, 0, 0, 0, time.Local)
+	cases := []struct {
+		value string
+		want  time.Time
+	}{
+		{"2025-01-08", day.Add(24*time.Hour - time.Nanosecond)},
+		{"2025-01-08 14:30", day.Add(14*time.Hour + 31*time.Minute - time.Nanosecond)},
+		{"2025-01-08 14:30:05", day.Add(14*time.Hour + 30*time.Minute + 5*time.Second)},
+		{"20250108_143005", day.Add(14*time.Hour + 30*time.Minute + 5*time.Second)},
+	}
+	for _, c := range cases {
+		got, err := parseBackupDate(c.value)
+		if err != nil || !got.Equal(c.want) {
+			t.Errorf("parseBackupDate(%q) = %v, %v; want %v", c.value, got, err, c.want)
+		}
+	}
+	if _, err := parseBackupDate("last tuesday"); err == nil {
+		t.Error("expected an error for an invalid date")
+	}
+}
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ildx/merlin/internal/cli"
+	"github.com/ildx/merlin/internal/state"
+	"github.com/ildx/merlin/internal/symlink"
+	"github.com/spf13/cobra"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect or clear merlin's caches",
+	Long: `Show or clear the caches merlin keeps to speed up repeated runs.
+
+CACHES
+	hashes     File hashes used by the divergence check of diff and prompt
+	           (~/.merlin/state/hashes.json), keyed by path, size and mtime
+	tools      Discovered tool configs (~/.merlin/state/tools.json)
+	packages   Package state used by diff --offline (~/.merlin/cache/packages.json)
+	links      Link status per tool shown by list configs
+	           (~/.merlin/cache/link-state.json)
+
+	Every cache is rebuilt on demand, so clearing one only costs time on the
+	next run.
+
+SUBCOMMANDS
+	stats            Show each cache's size, entries and last update (default)
+	clear [cache]... Delete the named caches, or all of them (--dry-run lists them)
+
+EXAMPLES
+	merlin cache
+	merlin cache clear hashes
+	merlin cache clear`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runCacheStats(cmd); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show cache sizes and entries",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runCacheStats(cmd); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:       "clear [cache]...",
+	Short:     "Delete caches (all by default)",
+	ValidArgs: []string{"hashes", "tools", "packages", "links"},
+	Args:      cobra.OnlyValidArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runCacheClear(cmd, args); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheStatsCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+}
+
+// cacheFile is one of the caches listed by merlin cache
+type cacheFile struct {
+	name string
+	path func() (string, error)
+}
+
+var cacheFiles = []cacheFile{
+	{"hashes", state.HashCachePath},
+	{"tools", symlink.ToolIndexPath},
+	{"packages", state.CachePath},
+	{"links", state.LinkStateCachePath},
+}
+
+func runCacheStats(cmd *cobra.Command) error {
+	table := newTable(cmd, "CACHE", "ENTRIES", "SIZE", "UPDATED", "PATH").TruncateMiddle(4)
+	for _, c := range cacheFiles {
+		path, err := c.path()
+		if err != nil {
+			return err
+		}
+		info, err := os.Stat(path)
+		if os.IsNotExist(err) {
+			table.AddRow(c.name, "-", "-", "never", path)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		entries := "-"
+		switch c.name {
+		case "hashes":
+			entries = fmt.Sprint(len(state.LoadHashCache().Entries))
+		case "links":
+			entries = fmt.Sprint(len(state.LoadLinkStateCache().Tools))
+		}
+		table.AddRow(c.name, entries, cli.FormatBytes(info.Size()), info.ModTime().Format("2006-01-02 15:04"), path)
+	}
+
+	fmt.Println("\n🗃  Merlin caches")
+	fmt.Println()
+	table.Render(os.Stdout)
+	return nil
+}
+
+func runCacheClear(cmd *cobra.Command, names []string) error {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	selected := make(map[string]bool)
+	for _, name := range names {
+		selected[name] = true
+	}
+
+	var cleared []string
+	for _, c := range cacheFiles {
+		if len(selected) > 0 && !selected[c.name] {
+			continue
+		}
+		path, err := c.path()
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			continue
+		}
+		if !dryRun {
+			if err := os.Remove(path); err != nil {
+				return fmt.Errorf("failed to clear %s cache: %w", c.name, err)
+			}
+		}
+		cleared = append(cleared, c.name)
+	}
+
+	switch {
+	case len(cleared) == 0:
+		cli.Info("No caches to clear")
+	case dryRun:
+		fmt.Printf("🔍 Would clear %s\n", strings.Join(cleared, ", "))
+	default:
+		cli.Success("Cleared %s", strings.Join(cleared, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ildx/merlin/internal/cli"
+	"github.com/ildx/merlin/internal/config"
+	"github.com/ildx/merlin/internal/installer"
+	"github.com/ildx/merlin/internal/models"
+	"github.com/ildx/merlin/internal/parser"
+	"github.com/ildx/merlin/internal/symlink"
+	"github.com/ildx/merlin/internal/system"
+	"github.com/spf13/cobra"
+)
+
+var cleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Reclaim disk space and remove stale symlinks",
+	Long: `Remove caches, packages and symlinks that are no longer needed.
+
+SUBCOMMANDS
+	brew   Run brew autoremove and brew cleanup
+
+FLAGS
+	--broken    Remove stale symlinks: links into the repository whose source
+	            was deleted, found in the target directories of the tools and
+	            of the links merlin recorded (the ones 'merlin diff' lists as
+	            Stale). Dangling links pointing elsewhere are left alone.
+	--dry-run   With --broken, list the links without removing them
+
+EXAMPLES
+	merlin clean --broken --dry-run
+	merlin clean --broken
+
+See also: merlin backup clean (old backups)`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if broken, _ := cmd.Flags().GetBool("broken"); !broken {
+			cmd.Help()
+			return
+		}
+		if err := runCleanBroken(cmd); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var cleanBrewCmd = &cobra.Command{
+	Use:   "brew",
+	Short: "Remove unused Homebrew dependencies and caches",
+	Long: `Preview or run brew autoremove and brew cleanup.
+
+BEHAVIOR
+	Without --apply nothing is removed: merlin lists the unneeded dependencies
+	and the space brew cleanup would reclaim. With --apply both commands run
+	and the summary reports the space freed and the disk space now available.
+
+FLAGS
+	--apply        Actually remove packages and caches
+	--leaves       Also uninstall leaf formulae not declared in brew.toml
+	-vv            Show brew's own output
+
+EXAMPLES
+	merlin clean brew                   # Preview
+	merlin clean brew --apply           # Autoremove + cleanup
+	merlin clean brew --leaves          # Preview undeclared leaves too
+	merlin clean brew --leaves --apply  # Prune formulae missing from brew.toml`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runCleanBrew(cmd); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cleanCmd)
+	cleanCmd.AddCommand(cleanBrewCmd)
+
+	cleanCmd.Flags().Bool("broken", false, "Remove symlinks into the repository whose source was deleted")
+	cleanBrewCmd.Flags().Bool("apply", false, "Remove packages and caches (default is a preview)")
+	cleanBrewCmd.Flags().Bool("leaves", false, "Include leaf formulae not declared in brew.toml")
+}
+
+func runCleanBrew(cmd *cobra.Command) error {
+	apply, _ := cmd.Flags().GetBool("apply")
+	leaves, _ := cmd.Flags().GetBool("leaves")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	verbosity := verbosityLevel(cmd)
+	if dryRun {
+		apply = false
+	}
+
+	if brewCheck := system.CheckHomebrew(); !brewCheck.Exists {
+		return brewCheck.Error
+	}
+
+	var declared *models.BrewConfig
+	if leaves {
+		repo, err := config.FindDotfilesRepo()
+		if err != nil {
+			return fmt.Errorf("dotfiles repository not found (needed for --leaves): %w", err)
+		}
+		declared, err = parser.ParseBrewConfig(repo.GetToolConfigDir("brew"))
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Println("\n🔍 Checking what Homebrew can clean up...")
+	plan, err := installer.PlanBrewCleanup(declared, leaves)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n🧹 Unneeded dependencies (%d):\n", len(plan.Autoremove))
+	fmt.Print(cli.BulletList(plan.Autoremove))
+	if leaves {
+		fmt.Printf("\n🍃 Leaves not in brew.toml (%d):\n", len(plan.Undeclared))
+		fmt.Print(cli.BulletList(plan.Undeclared))
+	}
+	fmt.Printf("\n💾 Cache and old versions: %s reclaimable\n", cli.FormatBytes(plan.Reclaimable))
+
+	if !apply {
+		fmt.Println("\nThis was a preview. Run with --apply to clean up.")
+		return nil
+	}
+
+	home, _ := os.UserHomeDir()
+	freeBefore, diskErr := system.FreeDiskSpace(home)
+
+	fmt.Println()
+	freed, err := installer.ApplyBrewCleanup(plan, verbosity.Stream(), os.Stdout)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("\n📊 Summary:")
+	fmt.Printf("   Removed: %d dependencies", len(plan.Autoremove))
+	if leaves {
+		fmt.Printf(", %d undeclared leaves", len(plan.Undeclared))
+	}
+	fmt.Println()
+	fmt.Printf("   Cleanup freed: %s\n", cli.FormatBytes(freed))
+	if diskErr == nil {
+		if freeAfter, err := system.FreeDiskSpace(home); err == nil {
+			fmt.Printf("   Disk free: %s → %s\n", cli.FormatBytes(freeBefore), cli.FormatBytes(freeAfter))
+		}
+	}
+	return nil
+}
+
+func runCleanBroken(cmd *cobra.Command) error {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	repo, err := config.FindDotfilesRepo()
+	if err != nil {
+		return fmt.Errorf("dotfiles repository not found: %w", err)
+	}
+	rootConfig, err := parser.ParseRootMerlinTOML(repo.GetRootMerlinConfig())
+	if err != nil {
+		return fmt.Errorf("parse root config: %w", err)
+	}
+	vars, err := symlink.GetVariablesFromRoot(rootConfig)
+	if err != nil {
+		return fmt.Errorf("get variables: %w", err)
+	}
+	tools, err := symlink.DiscoverTools(repo, vars)
+	if err != nil {
+		return fmt.Errorf("discover tools: %w", err)
+	}
+	registry := loadLinkRegistry()
+
+	stale := symlink.StaleLinks(tools, registry.ToolsByTarget(), repo.Root)
+	if len(stale) == 0 {
+		cli.Success("No stale symlinks")
+		return nil
+	}
+
+	fmt.Printf("Stale symlinks (%d):\n\n", len(stale))
+	table := newTable(cmd, "TOOL", "LINK", "POINTS TO (DELETED)").Fixed(0).TruncateMiddle(1).TruncateMiddle(2)
+	for _, link := range stale {
+		tool := link.Tool
+		if tool == "" {
+			tool = "-"
+		}
+		table.AddRow(tool, link.Target, repo.Rel(link.Dest))
+	}
+	table.Render(os.Stdout)
+
+	if dryRun {
+		fmt.Printf("\n🔍 Would remove %d stale symlink(s)\n", len(stale))
+		return nil
+	}
+
+	fmt.Println()
+	var failed int
+	for _, link := range stale {
+		if err := symlink.RemoveStaleLink(link); err != nil {
+			cli.Warning("%v", err)
+			failed++
+			continue
+		}
+		registry.Forget(link.Target)
+	}
+	saveLinkRegistry(registry, false)
+	if failed > 0 {
+		return fmt.Errorf("removed %d stale symlink(s), %d could not be removed", len(stale)-failed, failed)
+	}
+	cli.Success("Removed %d stale symlink(s)", len(stale))
+	return nil
+}
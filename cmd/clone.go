@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/ildx/merlin/internal/cli"
+	"github.com/ildx/merlin/internal/config"
+	"github.com/ildx/merlin/internal/git"
+	"github.com/ildx/merlin/internal/parser"
+	"github.com/ildx/merlin/internal/protect"
+	"github.com/spf13/cobra"
+)
+
+var cloneCmd = &cobra.Command{
+	Use:   "clone <git-url>",
+	Short: "Clone a dotfiles repository and set this machine up from it",
+	Long: `Clone a dotfiles repository onto a fresh machine, check that it is a merlin
+repository, and remember it so every other command finds it from any
+directory.
+
+BEHAVIOR
+	The repository is cloned into --to, or ~/<repository name> by default.
+	It must contain a root merlin.toml and its tools directory; otherwise the
+	clone is left in place for 'merlin migrate' and nothing is recorded.
+	The path is recorded as "dotfiles" in ~/.merlin/config.toml, which merlin
+	uses when neither MERLIN_DOTFILES nor the current directory points at a
+	repository.
+
+	Afterwards merlin offers to bootstrap: install brew packages, install App
+	Store apps, then link every tool. --bootstrap runs it without asking;
+	outside a terminal the steps are only printed.
+
+FLAGS
+	--to <path>     Where to clone (default ~/<repository name>)
+	--bootstrap     Run the bootstrap steps without asking
+	--dry-run       Show what would happen without cloning
+
+EXAMPLES
+	merlin clone git@github.com:me/dotfiles.git
+	merlin clone https://github.com/me/dotfiles --to ~/.dotfiles
+	merlin clone git@github.com:me/dotfiles.git --bootstrap`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runClone(cmd, args[0]); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var (
+	cloneTo        string
+	cloneBootstrap bool
+)
+
+func init() {
+	rootCmd.AddCommand(cloneCmd)
+	cloneCmd.Flags().StringVar(&cloneTo, "to", "", "Where to clone (default ~/<repository name>)")
+	cloneCmd.Flags().BoolVar(&cloneBootstrap, "bootstrap", false, "Install packages and link every tool without asking")
+}
+
+// bootstrapSteps are the merlin commands that set a machine up from a
+// freshly cloned repository, in order
+var bootstrapSteps = [][]string{
+	{"install", "brew", "--all"},
+	{"install", "mas", "--all"},
+	{"link", "--all"},
+}
+
+func runClone(cmd *cobra.Command, url string) error {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	if !git.IsGitAvailable() {
+		return errors.New("git is not installed (install the Xcode command line tools: xcode-select --install)")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("get home directory: %w", err)
+	}
+	dest := cloneTo
+	if dest == "" {
+		name := cloneDirName(url)
+		if name == "" {
+			return fmt.Errorf("can't tell the repository name from %s; pass --to", url)
+		}
+		dest = filepath.Join(home, name)
+	}
+	if dest, err = filepath.Abs(protect.Expand(dest, home)); err != nil {
+		return fmt.Errorf("resolve %s: %w", cloneTo, err)
+	}
+	if entries, err := os.ReadDir(dest); err == nil && len(entries) > 0 {
+		return fmt.Errorf("%s already exists and is not empty; choose another path with --to", dest)
+	}
+
+	if dryRun {
+		fmt.Printf("Would clone %s into %s\n", url, dest)
+		fmt.Println("Would record it in ~/.merlin/config.toml and offer to bootstrap:")
+		for _, step := range bootstrapSteps {
+			fmt.Printf("  merlin %s\n", strings.Join(step, " "))
+		}
+		return nil
+	}
+
+	fmt.Printf("Cloning %s into %s\n", url, dest)
+	if _, err := git.Clone(url, dest, os.Stdout); err != nil {
+		return err
+	}
+
+	repo, err := config.LoadDotfilesRepo(dest)
+	if err == nil {
+		_, err = parser.ParseRootMerlinTOML(repo.GetRootMerlinConfig())
+	}
+	if err != nil {
+		return fmt.Errorf("cloned into %s, but it is not a merlin repository: %w\nConvert an existing setup with 'merlin migrate'", dest, err)
+	}
+
+	user, err := config.LoadUserConfig()
+	if err != nil {
+		cli.Warning("%v; overwriting it", err)
+	}
+	user.Dotfiles = repo.Root
+	if err := user.Save(); err != nil {
+		return fmt.Errorf("record dotfiles repository: %w", err)
+	}
+	cli.Success("Cloned and recorded %s as your dotfiles repository", repo.Root)
+	if env := os.Getenv(config.EnvVarDotfiles); env != "" && env != repo.Root {
+		cli.Warning("%s=%s is set and takes precedence; unset it or point it at %s", config.EnvVarDotfiles, env, repo.Root)
+	}
+
+	if !cloneBootstrap {
+		if !stdinIsTerminal() {
+			printBootstrapSteps()
+			return nil
+		}
+		fmt.Print("\nBootstrap now (install brew packages and App Store apps, link every tool)? [y/N]: ")
+		var response string
+		fmt.Scanln(&response)
+		response = strings.ToLower(strings.TrimSpace(response))
+		if response != "y" && response != "yes" {
+			printBootstrapSteps()
+			return nil
+		}
+	}
+	return runBootstrap(repo.Root)
+}
+
+// cloneDirName returns the directory a clone of url gets by default, like
+// git: the last path element without ".git"
+func cloneDirName(url string) string {
+	url = strings.TrimRight(url, "/")
+	if i := strings.LastIndexAny(url, "/:"); i >= 0 {
+		url = url[i+1:]
+	}
+	return strings.TrimSuffix(url, ".git")
+}
+
+func printBootstrapSteps() {
+	fmt.Println("\nSet this machine up with:")
+	for _, step := range bootstrapSteps {
+		fmt.Printf("  merlin %s\n", strings.Join(step, " "))
+	}
+}
+
+// runBootstrap runs each bootstrap step as its own merlin command against
+// root, carrying on past failed steps
+func runBootstrap(root string) error {
+	self, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("locate merlin: %w", err)
+	}
+	var failed []string
+	for _, step := range bootstrapSteps {
+		name := "merlin " + strings.Join(step, " ")
+		fmt.Printf("\n▶ %s\n", name)
+		c := exec.Command(self, step...)
+		c.Dir = root
+		c.Env = append(os.Environ(), config.EnvVarDotfiles+"="+root)
+		c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := c.Run(); err != nil {
+			cli.Warning("%s failed: %v", name, err)
+			failed = append(failed, name)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("bootstrap finished with %d failed step(s): %s", len(failed), strings.Join(failed, ", "))
+	}
+	cli.Success("Bootstrap complete")
+	return nil
+}
@@ -0,0 +1,18 @@
+package cmd
+
+import "testing"
+
+func TestCloneDirName(t *testing.T) {
+	cases := map[string]string{
+		"git@github.com:me/dotfiles.git":      "dotfiles",
+		"https://github.com/me/dotfiles":      "dotfiles",
+		"https://github.com/me/dotfiles.git/": "dotfiles",
+		"/srv/git/dots.git":                   "dots",
+		"host:dots":                           "dots",
+	}
+	for url, want := range cases {
+		if got := cloneDirName(url); got != want {
+			t.Errorf("cloneDirName(%q) = %q, want %q", url, got, want)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ildx/merlin/internal/cli"
+	"github.com/ildx/merlin/internal/config"
+	"github.com/ildx/merlin/internal/parser"
+	"github.com/ildx/merlin/internal/symlink"
+	"github.com/spf13/cobra"
+)
+
+var conflictsCmd = &cobra.Command{
+	Use:   "conflicts [tool...]",
+	Short: "List link targets that are in the way",
+	Long: `List every link target that is occupied by something other than the link
+merlin wants there, without linking anything.
+
+Each entry shows what exists at the target (file, directory, or where a
+symlink points), what merlin would link there, and a command that resolves
+it. This is the conflict part of 'merlin link --all --dry-run', minus
+everything that would link cleanly.
+
+BEHAVIOR
+	Without arguments, every enabled tool is checked; pass tool names to
+	limit the report. Identical files, broken symlinks and symlinks into the
+	dotfiles repository are safe to overwrite; anything else is suggested
+	with --strategy backup. Protected paths are flagged: linking refuses them
+	whatever the strategy.
+
+FLAGS
+	--profile <name>   Only check the tools of a profile
+	--json             Output JSON instead of text
+
+EXAMPLES
+	merlin conflicts
+	merlin conflicts nvim zsh
+	merlin conflicts --profile work --json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runConflicts(cmd, args); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(conflictsCmd)
+	conflictsCmd.Flags().String("profile", "", "Only check the tools of this profile")
+	conflictsCmd.Flags().Bool("json", false, "Output JSON instead of human-readable text")
+}
+
+func runConflicts(cmd *cobra.Command, args []string) error {
+	profile, _ := cmd.Flags().GetString("profile")
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	repo, err := config.FindDotfilesRepo()
+	if err != nil {
+		return fmt.Errorf("dotfiles repository not found: %w", err)
+	}
+	rootConfig, err := parser.ParseRootMerlinTOML(repo.GetRootMerlinConfig())
+	if err != nil {
+		return fmt.Errorf("failed to parse root config: %w", err)
+	}
+	vars, err := symlink.GetVariablesFromRoot(rootConfig)
+	if err != nil {
+		return fmt.Errorf("failed to get variables: %w", err)
+	}
+
+	tools, err := symlink.DiscoverTools(repo, vars)
+	if err != nil {
+		return fmt.Errorf("discovering tools: %w", err)
+	}
+	if profile != "" {
+		if tools, err = filterToolsByProfile(tools, rootConfig, profile); err != nil {
+			return err
+		}
+	}
+	if len(args) > 0 {
+		if tools, err = selectTools(tools, args); err != nil {
+			return err
+		}
+	}
+
+	conflicts := symlink.FindConflicts(tools)
+
+	if asJSON {
+		if conflicts == nil {
+			conflicts = []symlink.Conflict{}
+		}
+		data, err := json.MarshalIndent(conflicts, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(conflicts) == 0 {
+		cli.Success("No conflicts across %d tool(s)", len(tools))
+		return nil
+	}
+
+	notes := loadTargetNotes()
+	fmt.Printf("⚠️  %d conflicting target(s) across %d tool(s)\n\n", len(conflicts), len(tools))
+	for _, c := range conflicts {
+		fmt.Printf("%s  %s\n", c.Tool, c.Target)
+		fmt.Printf("    exists:  %s\n", describeExisting(c))
+		fmt.Printf("    wants:   → %s\n", c.Source)
+		fmt.Printf("    resolve: %s\n", conflictResolution(c, repo.Root))
+		printTargetNotes(notes, c.Target, "    ")
+		fmt.Println()
+	}
+	fmt.Println(cli.Dim("Backups made by --strategy backup are listed by: merlin backup list"))
+	return nil
+}
+
+// selectTools keeps the named tools, failing on names that aren't enabled tools
+func selectTools(tools []*symlink.ToolConfig, names []string) ([]*symlink.ToolConfig, error) {
+	byName := make(map[string]*symlink.ToolConfig, len(tools))
+	for _, tool := range tools {
+		byName[tool.Name] = tool
+	}
+	selected := make([]*symlink.ToolConfig, 0, len(names))
+	for _, name := range names {
+		tool, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("tool '%s' not found or disabled", name)
+		}
+		selected = append(selected, tool)
+	}
+	return selected, nil
+}
+
+// describeExisting summarizes what occupies a conflicting target
+func describeExisting(c symlink.Conflict) string {
+	var desc string
+	switch {
+	case c.PointsTo != "":
+		desc = fmt.Sprintf("%s → %s", c.Existing, c.PointsTo)
+	case c.Existing == "directory":
+		desc = fmt.Sprintf("directory (%d file(s))", c.Files)
+	case c.Identical:
+		desc = "file (same content as the source)"
+	default:
+		desc = c.Existing
+	}
+	if c.Protected {
+		desc += " [protected]"
+	}
+	return desc
+}
+
+// conflictResolution suggests the command that clears a conflict: overwrite
+// when nothing would be lost, backup otherwise
+func conflictResolution(c symlink.Conflict, repoRoot string) string {
+	if c.Protected {
+		return "protected_paths refuses this target; drop the link or the protected entry"
+	}
+	overwrite := fmt.Sprintf("merlin link %s --strategy overwrite", c.Tool)
+	switch {
+	case c.Existing == "broken symlink":
+		return overwrite + "  # dangling link, nothing to lose"
+	case c.Identical:
+		return overwrite + "  # identical content"
+	case c.PointsTo != "" && intoRepo(c.PointsTo, c.Target, repoRoot):
+		return overwrite + "  # stale link into the dotfiles repo"
+	default:
+		return fmt.Sprintf("merlin link %s --strategy backup  # keeps a copy in ~/.merlin/backups", c.Tool)
+	}
+}
+
+// intoRepo reports whether a symlink destination lies in the dotfiles repo
+func intoRepo(dest, target, repoRoot string) bool {
+	if !filepath.IsAbs(dest) {
+		dest = filepath.Join(filepath.Dir(target), dest)
+	}
+	rel, err := filepath.Rel(repoRoot, filepath.Clean(dest))
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
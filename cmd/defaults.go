@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ildx/merlin/internal/cli"
+	"github.com/ildx/merlin/internal/config"
+	"github.com/ildx/merlin/internal/parser"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// initCommandDefaults applies the root config's [defaults] to the command
+// about to run. Like initRootSettings it leaves unreadable configs for the
+// command to report.
+func initCommandDefaults(cmd *cobra.Command) {
+	repo, err := config.FindDotfilesRepo()
+	if err != nil {
+		return
+	}
+	rootConfig, err := parser.ParseRootMerlinTOML(repo.GetRootMerlinConfig())
+	if err != nil {
+		return
+	}
+	if err := applyCommandDefaults(cmd, rootConfig.Defaults); err != nil {
+		cli.Warning("[defaults]: %v", err)
+	}
+}
+
+// commandKey is how [defaults] names cmd: its path without the root command,
+// e.g. "backup restore"
+func commandKey(cmd *cobra.Command) string {
+	path := strings.Fields(cmd.CommandPath())
+	return strings.Join(path[1:], " ")
+}
+
+// applyCommandDefaults sets the flags of cmd that aren't given on the command
+// line to their [defaults] values. A parent command's table applies to its
+// subcommands that share the flag ([defaults.install] retries covers
+// "install brew"), with the command's own table taking precedence. Flags a
+// parent's table names but cmd lacks are skipped; unknown flags in cmd's own
+// table are reported.
+func applyCommandDefaults(cmd *cobra.Command, defaults map[string]map[string]any) error {
+	if len(defaults) == 0 {
+		return nil
+	}
+	key := commandKey(cmd)
+
+	values := make(map[string]any)
+	fields := strings.Fields(key)
+	for i := 1; i <= len(fields); i++ {
+		for flag, value := range defaults[strings.Join(fields[:i], " ")] {
+			values[flag] = value
+		}
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var problems []string
+	for _, name := range names {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil {
+			if _, own := defaults[key][name]; own {
+				problems = append(problems, fmt.Sprintf("'merlin %s' has no --%s flag", key, name))
+			}
+			continue
+		}
+		if flag.Changed {
+			continue
+		}
+		if err := setFlagDefault(cmd.Flags(), flag, values[name]); err != nil {
+			problems = append(problems, fmt.Sprintf("--%s: %v", name, err))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// setFlagDefault sets flag from a TOML value as if it had been passed, so
+// commands that check whether a flag was given (e.g. install --retries over
+// settings.install_retries) honor it. Arrays are only accepted by list flags,
+// each element passed in turn.
+func setFlagDefault(flags *pflag.FlagSet, flag *pflag.Flag, value any) error {
+	items, isList := value.([]any)
+	if !isList {
+		items = []any{value}
+	} else if !strings.HasSuffix(flag.Value.Type(), "Slice") && !strings.HasSuffix(flag.Value.Type(), "Array") {
+		return fmt.Errorf("takes a single value, not a list")
+	}
+	for _, item := range items {
+		switch item.(type) {
+		case string, bool, int64, float64:
+		default:
+			return fmt.Errorf("unsupported value %v", item)
+		}
+		if err := flags.Set(flag.Name, fmt.Sprint(item)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkCommandDefaults lists [defaults] entries that name no command, or a
+// flag neither the command nor any of its subcommands has
+func checkCommandDefaults(defaults map[string]map[string]any) []string {
+	var problems []string
+	keys := make([]string, 0, len(defaults))
+	for key := range defaults {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		cmd, _, err := rootCmd.Find(strings.Fields(key))
+		if err != nil || commandKey(cmd) != key {
+			problems = append(problems, fmt.Sprintf("[defaults] names unknown command '%s'", key))
+			continue
+		}
+		flags := make([]string, 0, len(defaults[key]))
+		for name := range defaults[key] {
+			flags = append(flags, name)
+		}
+		sort.Strings(flags)
+		for _, name := range flags {
+			if !commandTreeHasFlag(cmd, name) {
+				problems = append(problems, fmt.Sprintf("[defaults] '%s' has no --%s flag", key, name))
+			}
+		}
+	}
+	return problems
+}
+
+// commandTreeHasFlag reports whether cmd or one of its subcommands accepts
+// the flag, including persistent flags inherited from parents
+func commandTreeHasFlag(cmd *cobra.Command, name string) bool {
+	if cmd.Flags().Lookup(name) != nil || cmd.InheritedFlags().Lookup(name) != nil {
+		return true
+	}
+	for _, sub := range cmd.Commands() {
+		if commandTreeHasFlag(sub, name) {
+			return true
+		}
+	}
+	return false
+}
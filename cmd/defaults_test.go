@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestApplyCommandDefaults(t *testing.T) {
+	root := &cobra.Command{Use: "merlin"}
+	install := &cobra.Command{Use: "install"}
+	brew := &cobra.Command{Use: "brew", Run: func(*cobra.Command, []string) {}}
+	install.PersistentFlags().Int("retries", 0, "")
+	brew.Flags().Bool("all", false, "")
+	brew.Flags().String("tag", "", "")
+	brew.Flags().StringSlice("only", nil, "")
+	root.AddCommand(install)
+	install.AddCommand(brew)
+
+	defaults := map[string]map[string]any{
+		"install":      {"retries": int64(2), "editor": "code"},
+		"install brew": {"retries": int64(3), "all": true, "tag": "work", "only": []any{"a", "b"}},
+	}
+	var applied *cobra.Command
+	brew.Run = func(cmd *cobra.Command, args []string) {
+		applied = cmd
+		if err := applyCommandDefaults(cmd, defaults); err != nil {
+			t.Errorf("applyCommandDefaults() error = %v", err)
+		}
+	}
+	root.SetArgs([]string{"install", "brew", "--tag", "home"})
+	if err := root.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	flags := applied.Flags()
+	if retries, _ := flags.GetInt("retries"); retries != 3 {
+		t.Errorf("retries = %d, want the command's own default 3", retries)
+	}
+	if all, _ := flags.GetBool("all"); !all || !flags.Changed("all") {
+		t.Errorf("all = %v (changed %v), want true and treated as passed", all, flags.Changed("all"))
+	}
+	if tag, _ := flags.GetString("tag"); tag != "home" {
+		t.Errorf("tag = %q, want the command line to win", tag)
+	}
+	if only, _ := flags.GetStringSlice("only"); strings.Join(only, ",") != "a,b" {
+		t.Errorf("only = %v, want [a b]", only)
+	}
+
+	// A flag the command's own table names but it doesn't have is reported
+	defaults["install brew"]["jobs"] = int64(4)
+	if err := applyCommandDefaults(applied, defaults); err == nil || !strings.Contains(err.Error(), "--jobs") {
+		t.Errorf("err = %v, want unknown --jobs reported", err)
+	}
+}
@@ -1,12 +1,16 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/ildx/merlin/internal/cli"
 	"github.com/ildx/merlin/internal/config"
 	"github.com/ildx/merlin/internal/diff"
+	"github.com/ildx/merlin/internal/logger"
+	"github.com/ildx/merlin/internal/machine"
 	"github.com/ildx/merlin/internal/state"
 	"github.com/spf13/cobra"
 )
@@ -21,6 +25,9 @@ import (
 //	--configs    Include symlink/config differences
 //	--scripts    Include script differences (placeholder)
 //	--json       Output machine-readable JSON instead of text summary
+//	--machine    Compare with another machine's published snapshot instead
+//	--summary    Print only the drift counts
+//	--quiet      Print only errors
 //
 // When no category flags are provided, all categories are shown.
 //
@@ -30,6 +37,7 @@ import (
 //	merlin diff --packages          # Only package drift
 //	merlin diff --configs --json    # Symlink diff as JSON
 //	merlin diff --scripts           # (will show placeholder until implemented)
+//	merlin diff --machine desktop   # What desktop has that this machine lacks
 //
 // EXIT STATUS
 //
@@ -37,7 +45,7 @@ import (
 var diffCmd = &cobra.Command{
 	Use:   "diff",
 	Short: "Show differences between system state and repo configs",
-	Long:  "Compute and display drift between installed packages, symlinked configs, and declared repository state. Useful for auditing and reconciling machines.",
+	Long:  "Compute and display drift between installed packages, symlinked configs, and declared repository state. Useful for auditing and reconciling machines.\n\nWith --machine <name>, compare this machine's packages and links with the snapshot another machine published ('merlin machine publish'), listing what is installed there but not here and the reverse.\n\nWith --summary, only the drift counts are printed; with --quiet, only errors.",
 	Run: func(cmd *cobra.Command, args []string) {
 		runDiff(cmd)
 	},
@@ -49,6 +57,8 @@ func init() {
 	diffCmd.Flags().Bool("configs", false, "Include config/symlink differences")
 	diffCmd.Flags().Bool("scripts", false, "Include script differences")
 	diffCmd.Flags().Bool("json", false, "Output JSON instead of human-readable text")
+	diffCmd.Flags().String("machine", "", "Compare packages and links with another machine's published snapshot")
+	addOutputFlags(diffCmd.Flags())
 }
 
 func runDiff(cmd *cobra.Command) {
@@ -59,16 +69,46 @@ func runDiff(cmd *cobra.Command) {
 		os.Exit(1)
 	}
 
-	// Collect system snapshot (read-only operation)
-	snap := state.CollectSnapshot(repo.Root)
+	// Collect system snapshot (read-only operation). Offline runs reuse the
+	// package state cached by the last online diff.
+	var snap *state.SystemSnapshot
+	offlineNote := ""
+	if offlineMode(cmd) {
+		var cache *state.PackageCache
+		snap, cache = state.CollectOfflineSnapshot(repo.Root)
+		if cache == nil {
+			offlineNote = "Offline: no cached package state; run 'merlin diff' online once to populate it"
+		} else {
+			offlineNote = fmt.Sprintf("Offline: package state cached %s", cache.CollectedAt.Format("2006-01-02 15:04"))
+		}
+	} else {
+		snap = state.CollectSnapshot(repo.Root)
+		if err := state.SavePackageCache(snap); err != nil {
+			logger.Debug("package cache not saved", "error", err)
+		}
+	}
+
+	if other, _ := cmd.Flags().GetString("machine"); other != "" {
+		if err := runMachineDiff(cmd, repo, snap, other, offlineNote); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+		return
+	}
 
 	// Compute diff
-	result, err := diff.Compute(repo, snap)
+	result, err := diff.Compute(cmd.Context(), repo, snap)
 	if err != nil {
 		cli.Error("Failed to compute diff: %v", err)
 		os.Exit(1)
 	}
 
+	// Remember the outcome for 'merlin prompt'
+	drift := result.Drift(repo.Root)
+	if err := state.SaveDrift(drift); err != nil {
+		logger.Debug("drift summary not saved", "error", err)
+	}
+
 	// Resolve flags
 	includePackages, _ := cmd.Flags().GetBool("packages")
 	includeConfigs, _ := cmd.Flags().GetBool("configs")
@@ -88,7 +128,7 @@ func runDiff(cmd *cobra.Command) {
 			cli.Error("Failed to marshal diff to JSON: %v", jErr)
 			os.Exit(1)
 		}
-		fmt.Println(jsonStr)
+		fmt.Fprintln(cli.SummaryOutput(), jsonStr)
 		return
 	}
 
@@ -96,6 +136,9 @@ func runDiff(cmd *cobra.Command) {
 	fmt.Println("\n🧭 Merlin Diff Report")
 	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 	fmt.Printf("Repository: %s\n", repo.Root)
+	if offlineNote != "" {
+		cli.Warning("%s", offlineNote)
+	}
 	fmt.Println()
 
 	output := result.HumanReadable(includePackages, includeConfigs, includeScripts)
@@ -103,8 +146,71 @@ func runDiff(cmd *cobra.Command) {
 
 	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 	fmt.Println("Legend: Added=present but undeclared | Missing=declared but absent")
-	fmt.Println("Symlink categories: Missing=not created | Orphaned=points into repo but undeclared | Broken=target missing | Divergent=hash mismatch")
+	fmt.Println("Symlink categories: Missing=not created | Orphaned=points into repo but undeclared | Broken=target missing | Stale=repo file deleted (merlin clean --broken) | Divergent=hash mismatch")
 	fmt.Println("Scripts use Added/Missing semantics (namespaced as tool/script).")
 	fmt.Println()
+	cli.Summary("Drift: %d missing, %d broken, %d undeclared", drift.Missing, drift.Broken, drift.Extra)
 	cli.Success("Diff completed")
 }
+
+// machineDiffLabels names the kinds of state machine.Compare reports
+var machineDiffLabels = map[string]string{
+	"formulae":   "Formulae",
+	"casks":      "Casks",
+	"mas_apps":   "App Store apps",
+	"extensions": "Editor extensions",
+	"links":      "Links",
+}
+
+// runMachineDiff compares this machine's state (snap) with the snapshot the
+// named machine published in the repository
+func runMachineDiff(cmd *cobra.Command, repo *config.DotfilesRepo, snap *state.SystemSnapshot, name, offlineNote string) error {
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	there, err := machine.LoadSnapshot(repo.Root, name)
+	if err != nil {
+		return err
+	}
+	here, err := machineSnapshotFrom(repo, snap)
+	if err != nil {
+		return err
+	}
+	comparison := machine.Compare(here, there)
+
+	if asJSON {
+		enc := json.NewEncoder(cli.SummaryOutput())
+		enc.SetIndent("", "  ")
+		return enc.Encode(comparison)
+	}
+
+	fmt.Printf("\n🖥  %s compared with %s\n", here.Machine, name)
+	fmt.Printf("Snapshot of %s published %s (%s)\n", name, there.TakenAt.Format("2006-01-02 15:04"), formatSince(time.Since(there.TakenAt)))
+	if offlineNote != "" {
+		cli.Warning("%s", offlineNote)
+	}
+	fmt.Println()
+	if comparison.Empty() {
+		cli.Success("Same packages and links as %s", name)
+		cli.Summary("Summary: 0 only on %s, 0 only on this machine", name)
+		return nil
+	}
+	onlyThere, onlyHere := 0, 0
+	for _, set := range comparison.Sets {
+		onlyThere += len(set.OnlyThere)
+		onlyHere += len(set.OnlyHere)
+		if len(set.OnlyThere) == 0 && len(set.OnlyHere) == 0 {
+			continue
+		}
+		fmt.Printf("== %s ==\n", machineDiffLabels[set.Kind])
+		for _, item := range set.OnlyThere {
+			fmt.Printf("  + %s\n", item)
+		}
+		for _, item := range set.OnlyHere {
+			fmt.Printf("  %s\n", cli.Dim("- "+item))
+		}
+		fmt.Println()
+	}
+	fmt.Printf("Legend: + only on %s (missing here) | - only on this machine\n", name)
+	cli.Summary("Summary: %d only on %s, %d only on this machine", onlyThere, name, onlyHere)
+	return nil
+}
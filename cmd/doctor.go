@@ -2,8 +2,15 @@ package cmd
 
 import (
 	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
 
 	"github.com/ildx/merlin/internal/cli"
+	"github.com/ildx/merlin/internal/config"
+	"github.com/ildx/merlin/internal/models"
+	"github.com/ildx/merlin/internal/parser"
+	"github.com/ildx/merlin/internal/symlink"
 	"github.com/ildx/merlin/internal/system"
 	"github.com/spf13/cobra"
 )
@@ -11,7 +18,7 @@ import (
 var doctorCmd = &cobra.Command{
 	Use:   "doctor",
 	Short: "Check system prerequisites",
-	Long:  "Check if required system tools (Homebrew, mas-cli, optional utilities) are installed and report environment details.\n\nOUTPUT SECTIONS\n  • System information (OS, arch, hostname)\n  • macOS suitability\n  • Required package managers\n  • Optional helper tools (git, curl, jq, yq)\n\nEXIT STATUS\n  Always exits 0; missing prerequisites are reported with suggestions.\n\nEXAMPLES\n  merlin doctor          # Full system check\n  merlin doctor --verbose (global flag for more logging)\n\nTIPS\n  Run this first on a new machine to confirm prerequisites before installs.",
+	Long:  "Check if required system tools (Homebrew, mas-cli, optional utilities) are installed and report environment details.\n\nOUTPUT SECTIONS\n  • System information (OS, arch, hostname)\n  • macOS suitability\n  • Required package managers, with the Homebrew prefix merlin uses\n    and any other Homebrew installation (e.g. x86_64 under Rosetta)\n  • Optional helper tools (git, curl, jq, yq)\n  • macOS privacy permissions: Full Disk Access of this terminal, and the\n    permissions tools declare in [tool] permissions\n\nEXIT STATUS\n  Always exits 0; missing prerequisites are reported with suggestions.\n\nEXAMPLES\n  merlin doctor          # Full system check\n  merlin doctor -vvv      # With debug logging\n\nTIPS\n  Run this first on a new machine to confirm prerequisites before installs.",
 	Run: func(cmd *cobra.Command, args []string) {
 		runDoctor()
 	},
@@ -50,6 +57,9 @@ func runDoctor() {
 	fmt.Printf("\n📦 Package Managers:\n")
 	brewCheck := system.CheckHomebrew()
 	fmt.Printf("   %s\n", system.FormatCommandCheck(brewCheck))
+	if brewCheck.Exists {
+		printBrewPrefixes(brewCheck.Path)
+	}
 
 	// Check mas-cli
 	masCheck := system.CheckMAS()
@@ -66,6 +76,8 @@ func runDoctor() {
 		}
 	}
 
+	printPermissionChecks()
+
 	// Overall status
 	fmt.Println("\n━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 
@@ -80,3 +92,101 @@ func runDoctor() {
 	}
 	fmt.Println()
 }
+
+// printPermissionChecks reports whether the terminal has Full Disk Access and
+// which privacy permissions the repository's tools declare
+func printPermissionChecks() {
+	fmt.Printf("\n🔐 Permissions:\n")
+	byPermission := permissionsByTool()
+
+	who := "this terminal"
+	if tools := byPermission[models.PermissionFullDiskAccess]; len(tools) > 0 {
+		who += "; needed by " + strings.Join(tools, ", ")
+	}
+	fmt.Printf("   %s\n", formatPermissionCheck(system.CheckPermission(models.PermissionFullDiskAccess), who))
+
+	permissions := make([]string, 0, len(byPermission))
+	for p := range byPermission {
+		if p != models.PermissionFullDiskAccess {
+			permissions = append(permissions, p)
+		}
+	}
+	sort.Strings(permissions)
+	for _, p := range permissions {
+		who := "needed by " + strings.Join(byPermission[p], ", ")
+		fmt.Printf("   %s\n", formatPermissionCheck(system.CheckPermission(p), who))
+	}
+}
+
+// permissionsByTool maps each permission declared by an enabled tool to the
+// tools declaring it. Without a readable repository it is empty.
+func permissionsByTool() map[string][]string {
+	byPermission := make(map[string][]string)
+	repo, err := config.FindDotfilesRepo()
+	if err != nil {
+		return byPermission
+	}
+	rootConfig, err := parser.ParseRootMerlinTOML(repo.GetRootMerlinConfig())
+	if err != nil {
+		return byPermission
+	}
+	vars, err := symlink.GetVariablesFromRoot(rootConfig)
+	if err != nil {
+		return byPermission
+	}
+	tools, _ := symlink.DiscoverTools(repo, vars)
+	for _, tool := range tools {
+		for _, p := range tool.Permissions {
+			byPermission[p] = append(byPermission[p], tool.Name)
+		}
+	}
+	for _, names := range byPermission {
+		sort.Strings(names)
+	}
+	return byPermission
+}
+
+// warnMissingPermissions warns, before linking, about declared permissions
+// the terminal is known to lack (see merlin doctor)
+func warnMissingPermissions(tools []*symlink.ToolConfig) {
+	checked := make(map[string]*system.PermissionCheck)
+	for _, tool := range tools {
+		for _, p := range tool.Permissions {
+			if checked[p] == nil {
+				checked[p] = system.CheckPermission(p)
+			}
+			if check := checked[p]; check.Status == system.PermissionMissing {
+				cli.Warning("%s needs %s, which this terminal lacks (open \"%s\")", tool.Name, check.Label, check.Pane)
+			}
+		}
+	}
+}
+
+// printBrewPrefixes shows the prefix of the brew merlin runs and any other
+// Homebrew installation, e.g. an x86_64 one under Rosetta on Apple Silicon
+func printBrewPrefixes(active string) {
+	prefix := system.BrewPrefix(active)
+	fmt.Printf("     Prefix: %s\n", prefix)
+	for _, install := range system.DetectBrews() {
+		if install.Prefix == prefix {
+			continue
+		}
+		fmt.Printf("     Also installed: %s (%s); use arch = %q in brew.toml to install with it\n", install.Prefix, install.Arch, install.Arch)
+	}
+	if onPath, err := exec.LookPath("brew"); err == nil && system.BrewPrefix(onPath) != prefix {
+		fmt.Printf("     ⚠️  The brew first on PATH is %s; merlin uses %s (set brew_path under [settings.brew] to change)\n", onPath, active)
+	}
+}
+
+// formatPermissionCheck renders a permission and what needs it, with the
+// System Settings pane to open when it isn't known to be granted
+func formatPermissionCheck(check *system.PermissionCheck, who string) string {
+	switch check.Status {
+	case system.PermissionGranted:
+		return fmt.Sprintf("✓ %s (%s)", check.Label, who)
+	case system.PermissionMissing:
+		return fmt.Sprintf("✗ %s missing (%s)\n     Grant it in System Settings: open \"%s\"", check.Label, who, check.Pane)
+	default:
+		return fmt.Sprintf("? %s (%s): can't be checked, confirm in System Settings: open \"%s\"", check.Label, who, check.Pane)
+	}
+}
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/ildx/merlin/internal/backup"
+	"github.com/ildx/merlin/internal/cli"
+	"github.com/ildx/merlin/internal/config"
+	"github.com/ildx/merlin/internal/state"
+	"github.com/ildx/merlin/internal/system"
+	"github.com/spf13/cobra"
+)
+
+var duCmd = &cobra.Command{
+	Use:   "du",
+	Short: "Show disk usage of merlin-managed data",
+	Long: `Summarize how much space backups, cached snapshots, logs and the dotfiles
+repository take up, list the largest backups, and suggest how to reclaim space.
+
+LOCATIONS
+	~/.merlin/backups      Backups created by link, unlink and backup create
+	                       (moved by backup_dir or MERLIN_BACKUP_DIR)
+	~/.merlin/cache        Package snapshots used by diff --offline
+	~/.merlin/logs         Script output logs
+	~/.merlin/merlin.log   Merlin's own log
+	<dotfiles repo>        Including its .git directory
+
+FLAGS
+	--top <n>   Number of largest backups to list (default 5)
+
+EXAMPLES
+	merlin du
+	merlin du --top 10`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runDu(cmd); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(duCmd)
+	duCmd.Flags().Int("top", 5, "Number of largest backups to list")
+}
+
+// backupUsage is the on-disk size of one backup
+type backupUsage struct {
+	manifest *backup.BackupManifest
+	size     int64
+}
+
+func runDu(cmd *cobra.Command) error {
+	top, _ := cmd.Flags().GetInt("top")
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("get home directory: %w", err)
+	}
+	merlinDir := filepath.Join(home, ".merlin")
+
+	backupDir, err := backup.BackupLocation()
+	if err != nil {
+		return err
+	}
+	cachePath, err := state.CachePath()
+	if err != nil {
+		return err
+	}
+
+	backups, err := backup.ListBackups()
+	if err != nil {
+		return err
+	}
+	var usages []backupUsage
+	for _, b := range backups {
+		size, err := system.DirSize(b.Dir())
+		if err != nil {
+			return err
+		}
+		usages = append(usages, backupUsage{manifest: b, size: size})
+	}
+
+	table := newTable(cmd, "DATA", "SIZE", "PATH").Fixed(0).Fixed(1).TruncateMiddle(2)
+	addRow := func(label, path string) (int64, error) {
+		size, err := system.DirSize(path)
+		if err != nil {
+			return 0, err
+		}
+		table.AddRow(label, cli.FormatBytes(size), path)
+		return size, nil
+	}
+
+	backupsSize, err := addRow(fmt.Sprintf("Backups (%d)", len(backups)), backupDir)
+	if err != nil {
+		return err
+	}
+	if _, err := addRow("Package snapshots", filepath.Dir(cachePath)); err != nil {
+		return err
+	}
+	logsSize, err := addRow("Script logs", filepath.Join(merlinDir, "logs"))
+	if err != nil {
+		return err
+	}
+	if _, err := addRow("Merlin log", filepath.Join(merlinDir, "merlin.log")); err != nil {
+		return err
+	}
+
+	var gitSize int64
+	repo, repoErr := config.FindDotfilesRepo()
+	if repoErr == nil {
+		if _, err := addRow("Dotfiles repo", repo.Root); err != nil {
+			return err
+		}
+		if gitSize, err = addRow("  of which .git", filepath.Join(repo.Root, ".git")); err != nil {
+			return err
+		}
+	}
+
+	fmt.Println("\n💾 Merlin disk usage")
+	fmt.Println()
+	table.Render(os.Stdout)
+	if repoErr != nil {
+		fmt.Println()
+		fmt.Println(cli.Dim("(dotfiles repository not found; repo size omitted)"))
+	}
+
+	if len(usages) > 0 && top > 0 {
+		sort.SliceStable(usages, func(i, j int) bool { return usages[i].size > usages[j].size })
+		if len(usages) > top {
+			usages = usages[:top]
+		}
+
+		fmt.Printf("\n📦 Largest backups\n\n")
+		largest := newTable(cmd, "ID", "TIMESTAMP", "FILES", "SIZE", "REASON").Fixed(0).Fixed(1).Fixed(2).Fixed(3)
+		for _, u := range usages {
+			largest.AddRow(u.manifest.ID, u.manifest.Timestamp.Format("2006-01-02 15:04:05"),
+				fmt.Sprintf("%d", len(u.manifest.Files)), cli.FormatBytes(u.size), u.manifest.Reason)
+		}
+		largest.Render(os.Stdout)
+	}
+
+	var suggestions []string
+	if backupsSize > 0 {
+		suggestions = append(suggestions,
+			"merlin backup clean --keep 5  # Keep only the 5 newest backups",
+			"merlin backup clean --older-than 30  # Drop backups older than 30 days")
+	}
+	if logsSize > 0 {
+		suggestions = append(suggestions, "rm -r ~/.merlin/logs/scripts/<tool>  # Remove a tool's script logs")
+	}
+	if gitSize > 0 {
+		suggestions = append(suggestions, fmt.Sprintf("git -C %s gc  # Compact repository history", repo.Root))
+	}
+	if len(suggestions) > 0 {
+		fmt.Println("\n💡 Reclaim space with:")
+		fmt.Print(cli.BulletList(suggestions))
+	}
+
+	return nil
+}
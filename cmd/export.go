@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ildx/merlin/internal/cli"
+	"github.com/ildx/merlin/internal/config"
+	"github.com/ildx/merlin/internal/export"
+	"github.com/ildx/merlin/internal/parser"
+	"github.com/ildx/merlin/internal/symlink"
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the declared setup for machines without merlin",
+	Long: `Render the packages and links declared in the dotfiles repository in a form
+that runs without merlin.
+
+SUBCOMMANDS
+	script   Standalone bash script (brew, mas and ln -s commands)`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+var exportScriptCmd = &cobra.Command{
+	Use:   "script",
+	Short: "Emit a standalone bootstrap script",
+	Long: `Emit a bash script that installs brew.toml formulae and casks, mas.toml apps,
+and creates every declared symlink with ln -s. The script is idempotent:
+installed packages are skipped and existing files are moved aside before
+linking.
+
+Link sources are relative to $DOTFILES (default: the repository's current
+location) and targets relative to $HOME, so the script can be shared.
+
+FLAGS
+	--output,-o <file>  Write to a file instead of stdout (made executable)
+	--profile <name>    Only link the profile's tools
+	--no-packages       Skip brew and mas installs
+	--no-links          Skip symlinks
+
+EXAMPLES
+	merlin export script > bootstrap.sh
+	merlin export script --profile minimal --no-packages -o link.sh
+	DOTFILES=~/src/dotfiles bash bootstrap.sh`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runExportScript(cmd); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.AddCommand(exportScriptCmd)
+
+	exportScriptCmd.Flags().StringP("output", "o", "", "Write the script to a file")
+	exportScriptCmd.Flags().String("profile", "", "Only include links for tools in this profile")
+	exportScriptCmd.Flags().Bool("no-packages", false, "Skip Homebrew and Mac App Store installs")
+	exportScriptCmd.Flags().Bool("no-links", false, "Skip symlinks")
+}
+
+func runExportScript(cmd *cobra.Command) error {
+	output, _ := cmd.Flags().GetString("output")
+	profile, _ := cmd.Flags().GetString("profile")
+	noPackages, _ := cmd.Flags().GetBool("no-packages")
+	noLinks, _ := cmd.Flags().GetBool("no-links")
+
+	repo, err := config.FindDotfilesRepo()
+	if err != nil {
+		return fmt.Errorf("dotfiles repository not found: %w", err)
+	}
+	rootConfig, err := parser.ParseRootMerlinTOML(repo.GetRootMerlinConfig())
+	if err != nil {
+		return fmt.Errorf("failed to parse root config: %w", err)
+	}
+	vars, err := symlink.GetVariablesFromRoot(rootConfig)
+	if err != nil {
+		return fmt.Errorf("failed to get variables: %w", err)
+	}
+
+	spec := export.ScriptSpec{RepoRoot: repo.Root, HomeDir: vars.HomeDir}
+
+	if !noPackages {
+		if brewDir := repo.GetToolConfigDir("brew"); parser.HasBrewConfig(brewDir) {
+			brewConfig, err := parser.ParseBrewConfig(brewDir)
+			if err != nil {
+				return err
+			}
+			spec.Taps, spec.Formulae, spec.Casks = brewConfig.Taps, brewConfig.Formulae, brewConfig.Casks
+		}
+		masPath := filepath.Join(repo.GetToolConfigDir("mas"), "mas.toml")
+		if _, err := os.Stat(masPath); err == nil {
+			masConfig, err := parser.ParseMASTOML(masPath)
+			if err != nil {
+				return err
+			}
+			spec.Apps = masConfig.Apps
+		}
+	}
+
+	if !noLinks {
+		tools, err := symlink.DiscoverTools(repo, vars)
+		if err != nil {
+			return err
+		}
+		if profile != "" {
+			if tools, err = filterToolsByProfile(tools, rootConfig, profile); err != nil {
+				return err
+			}
+		}
+		for _, tool := range tools {
+			spec.Links = append(spec.Links, symlink.ExpandLinks(tool.Links)...)
+		}
+	}
+
+	if output == "" {
+		return export.WriteScript(os.Stdout, spec)
+	}
+
+	f, err := os.OpenFile(output, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0755)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", output, err)
+	}
+	defer f.Close()
+	if err := export.WriteScript(f, spec); err != nil {
+		return err
+	}
+	cli.Success("Wrote %s (%d packages, %d apps, %d links)", output,
+		len(spec.Formulae)+len(spec.Casks), len(spec.Apps), len(spec.Links))
+	return nil
+}
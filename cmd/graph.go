@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/ildx/merlin/internal/cli"
+	"github.com/ildx/merlin/internal/config"
+	"github.com/ildx/merlin/internal/graph"
+	"github.com/ildx/merlin/internal/installer"
+	"github.com/ildx/merlin/internal/parser"
+	"github.com/ildx/merlin/internal/symlink"
+	"github.com/ildx/merlin/internal/system"
+	"github.com/spf13/cobra"
+)
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Render dependency graphs as DOT or Mermaid",
+	Long: `Print a dependency graph for Graphviz (DOT) or Mermaid.
+
+SUBCOMMANDS
+	brew    Dependencies among the formulae in brew.toml
+	tools   Tool dependencies and profile membership`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+var graphBrewCmd = &cobra.Command{
+	Use:   "brew",
+	Short: "Graph the dependencies of the formulae in brew.toml",
+	Long: `Graph every formula declared in brew.toml and what it pulls in, as
+reported by 'brew info --json=v2 --installed'.
+
+BEHAVIOR
+	Formulae are drawn by kind:
+	• Declared leaves (green): declared, and nothing else in the graph
+	  needs them.
+	• Declared dependencies (yellow): declared, but another formula pulls
+	  them in anyway. They can usually be dropped from brew.toml.
+	• Dependencies (plain): pulled in, not declared.
+	• Not installed (dashed): declared, so their dependencies are unknown.
+	Casks have no formula dependencies and are left out.
+
+FLAGS
+	--format <dot|mermaid>   Output format (default dot)
+
+EXAMPLES
+	merlin graph brew | dot -Tsvg > brew.svg
+	merlin graph brew --format mermaid > brew.mmd
+
+See also: merlin audit brew`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runGraphBrew(cmd); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var graphToolsCmd = &cobra.Command{
+	Use:   "tools",
+	Short: "Graph tool dependencies and profile membership",
+	Long: `Graph the enabled tools, the tools each lists in [tool] dependencies
+of its merlin.toml, and the profiles of the root merlin.toml.
+
+BEHAVIOR
+	• Profiles (blue) point at their tools with dashed arrows.
+	• Tools point at the tools they depend on. Dependencies that aren't
+	  tools (usually packages) are left out.
+	• Dependency cycles are drawn in red; 'merlin link --all' refuses to
+	  order tools while one exists.
+	• Orphan tools (red), in no profile, are only linked by name or by
+	  --all. Names in a profile that aren't enabled tools are dashed.
+	Cycles and orphans are also reported as warnings on stderr.
+
+FLAGS
+	--format <dot|mermaid>   Output format (default dot)
+
+EXAMPLES
+	merlin graph tools | dot -Tsvg > tools.svg
+	merlin graph tools --format mermaid > tools.mmd`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runGraphTools(cmd); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(graphCmd)
+	graphCmd.AddCommand(graphBrewCmd)
+	graphCmd.AddCommand(graphToolsCmd)
+
+	graphCmd.PersistentFlags().String("format", "dot", "Output format: dot or mermaid")
+}
+
+func runGraphBrew(cmd *cobra.Command) error {
+	format, err := graphFormat(cmd)
+	if err != nil {
+		return err
+	}
+	if brewCheck := system.CheckHomebrew(); !brewCheck.Exists {
+		return brewCheck.Error
+	}
+	repo, err := config.FindDotfilesRepo()
+	if err != nil {
+		return fmt.Errorf("dotfiles repository not found: %w", err)
+	}
+	declared, err := parser.ParseBrewConfig(repo.GetToolConfigDir("brew"))
+	if err != nil {
+		return err
+	}
+
+	g, err := installer.BrewGraph(nil, declared)
+	if err != nil {
+		return err
+	}
+	out, err := g.Render(format)
+	if err != nil {
+		return err
+	}
+	fmt.Print(out)
+	return nil
+}
+
+func runGraphTools(cmd *cobra.Command) error {
+	format, err := graphFormat(cmd)
+	if err != nil {
+		return err
+	}
+	repo, err := config.FindDotfilesRepo()
+	if err != nil {
+		return fmt.Errorf("dotfiles repository not found: %w", err)
+	}
+	rootConfig, err := parser.ParseRootMerlinTOML(repo.GetRootMerlinConfig())
+	if err != nil {
+		return fmt.Errorf("parsing root config: %w", err)
+	}
+	vars, err := symlink.GetVariablesFromRoot(rootConfig)
+	if err != nil {
+		return fmt.Errorf("getting variables: %w", err)
+	}
+	tools, err := symlink.DiscoverTools(repo, vars)
+	if err != nil {
+		return fmt.Errorf("discovering tools: %w", err)
+	}
+
+	out, err := symlink.ToolGraph(tools, rootConfig.Profiles).Render(format)
+	if err != nil {
+		return err
+	}
+	fmt.Print(out)
+
+	for _, cycle := range symlink.DependencyCycles(tools) {
+		cli.Warning("dependency cycle between: %s", strings.Join(cycle, ", "))
+	}
+	if orphans := symlink.OrphanTools(tools, rootConfig.Profiles); len(orphans) > 0 {
+		cli.Warning("tools in no profile: %s", strings.Join(orphans, ", "))
+	}
+	return nil
+}
+
+// graphFormat returns --format, checked before any graph is built
+func graphFormat(cmd *cobra.Command) (string, error) {
+	format, _ := cmd.Flags().GetString("format")
+	if !slices.Contains(graph.Formats, format) {
+		return "", fmt.Errorf("unknown format %q (must be: %s)", format, strings.Join(graph.Formats, " or "))
+	}
+	return format, nil
+}
@@ -0,0 +1,267 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/ildx/merlin/internal/cli"
+	"github.com/ildx/merlin/internal/config"
+	"github.com/ildx/merlin/internal/installer"
+	"github.com/ildx/merlin/internal/logger"
+	"github.com/ildx/merlin/internal/models"
+	"github.com/ildx/merlin/internal/parser"
+	"github.com/ildx/merlin/internal/symlink"
+	"github.com/ildx/merlin/internal/system"
+	"github.com/spf13/cobra"
+)
+
+var infoCmd = &cobra.Command{
+	Use:   "info <tool|package>",
+	Short: "Show a tool's links, notes and README, or why a package is declared",
+	Long: `Show everything merlin knows about one tool, or one declared package.
+
+BEHAVIOR
+	Prints the tool's description, state and dependencies, each link with
+	whether it is in place, the [tool] notes from its merlin.toml and the
+	README.md in the tool's directory. Use notes and the README to record why
+	a config exists and which manual steps remain.
+
+	When no tool has the name, the formula, cask or App Store app (by name
+	or ID) declared in brew.toml or mas.toml is shown instead: description,
+	category, and its reason, added_by and added_date, plus the installed
+	formulae that depend on a formula, to judge whether it can be dropped.
+
+FLAGS
+	--no-readme   Leave out README.md
+	--json        Print the tool as JSON (notes and README text included)
+
+EXAMPLES
+	merlin info nvim
+	merlin info nvim --no-readme
+	merlin info nvim --json
+	merlin info libvips`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runInfo(cmd, args[0]); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(infoCmd)
+	infoCmd.Flags().Bool("no-readme", false, "Leave out README.md")
+	infoCmd.Flags().Bool("json", false, "Print the tool as JSON")
+}
+
+// toolInfo is the JSON form of merlin info
+type toolInfo struct {
+	*symlink.ToolConfig
+	Linked     map[string]bool `json:"linked"` // target → symlink to its source in place
+	ReadmeText string          `json:"readme_text,omitempty"`
+}
+
+func runInfo(cmd *cobra.Command, toolName string) error {
+	noReadme, _ := cmd.Flags().GetBool("no-readme")
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	repo, err := config.FindDotfilesRepo()
+	if err != nil {
+		return fmt.Errorf("dotfiles repository not found: %w", err)
+	}
+	if !repo.ToolExists(toolName) {
+		pkg, err := findDeclaredPackage(repo, toolName)
+		if err != nil {
+			return err
+		}
+		if pkg == nil {
+			return fmt.Errorf("no tool or declared package named '%s' in the dotfiles repository", toolName)
+		}
+		return printPackageInfo(pkg, asJSON)
+	}
+	rootConfig, err := parser.ParseRootMerlinTOML(repo.GetRootMerlinConfig())
+	if err != nil {
+		return fmt.Errorf("error parsing root config: %w", err)
+	}
+	vars, err := symlink.GetVariablesFromRoot(rootConfig)
+	if err != nil {
+		return fmt.Errorf("error getting variables: %w", err)
+	}
+	tool, err := symlink.DiscoverToolConfig(repo, toolName, vars)
+	if err != nil {
+		return err
+	}
+
+	info := toolInfo{ToolConfig: tool, Linked: make(map[string]bool)}
+	for _, link := range tool.Links {
+		info.Linked[link.Target], _ = symlink.IsLinked(link.Source, link.Target)
+	}
+	if tool.Readme != "" && !noReadme {
+		data, err := os.ReadFile(tool.Readme)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", repo.Rel(tool.Readme), err)
+		}
+		info.ReadmeText = string(data)
+	}
+
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(info)
+	}
+
+	fmt.Printf("\n🧰 %s\n", tool.Name)
+	if tool.Description != "" {
+		fmt.Printf("   %s\n", tool.Description)
+	}
+	state := "enabled"
+	if tool.Disabled {
+		state = "disabled"
+	}
+	fmt.Printf("   Location: %s (%s)\n", repo.Rel(tool.ToolRoot), state)
+	if len(tool.Dependencies) > 0 {
+		fmt.Printf("   Depends on: %s\n", strings.Join(tool.Dependencies, ", "))
+	}
+
+	fmt.Printf("\n🔗 Links (%d):\n", len(tool.Links))
+	if len(tool.Links) > 0 {
+		table := newTable(cmd, "TARGET", "SOURCE", "STATUS")
+		for _, link := range tool.Links {
+			status := "not linked"
+			if info.Linked[link.Target] {
+				status = "linked"
+			}
+			table.AddRow(link.Target, repo.Rel(link.Source), status)
+		}
+		table.Render(os.Stdout)
+		targetNotes := loadTargetNotes()
+		for _, link := range tool.Links {
+			if texts := targetNotes.Texts(link.Target); len(texts) > 0 {
+				fmt.Printf("   %s\n", link.Target)
+				printTargetNotes(targetNotes, link.Target, "     ")
+			}
+		}
+	}
+
+	if notes := strings.TrimSpace(tool.Notes); notes != "" {
+		fmt.Println("\n📝 Notes:")
+		printIndented(notes)
+	}
+	if text := strings.TrimSpace(info.ReadmeText); text != "" {
+		fmt.Printf("\n📖 %s:\n", repo.Rel(tool.Readme))
+		printIndented(text)
+	}
+	return nil
+}
+
+// printIndented prints text with every line indented under a section heading
+func printIndented(text string) {
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			fmt.Println()
+			continue
+		}
+		fmt.Printf("   %s\n", line)
+	}
+}
+
+// packageInfo is a brew.toml or mas.toml entry as merlin info shows it
+type packageInfo struct {
+	Kind        string      `json:"kind"` // formula, cask or app
+	Name        string      `json:"name"`
+	ID          int         `json:"id,omitempty"` // App Store ID
+	Description string      `json:"description,omitempty"`
+	Category    string      `json:"category,omitempty"`
+	Reason      string      `json:"reason,omitempty"`
+	AddedBy     string      `json:"added_by,omitempty"`
+	AddedDate   models.Date `json:"added_date,omitempty"`
+	RequiredBy  []string    `json:"required_by,omitempty"` // installed formulae depending on a formula
+}
+
+// findDeclaredPackage looks name up among the declared formulae, casks
+// (also by the short name of a tap package) and App Store apps (also by
+// ID); nil when none matches
+func findDeclaredPackage(repo *config.DotfilesRepo, name string) (*packageInfo, error) {
+	if brewDir := repo.GetToolConfigDir("brew"); parser.HasBrewConfig(brewDir) {
+		brewConfig, err := parser.ParseBrewConfig(brewDir)
+		if err != nil {
+			return nil, err
+		}
+		for _, group := range []struct {
+			kind     string
+			packages []models.BrewPackage
+		}{{"formula", brewConfig.Formulae}, {"cask", brewConfig.Casks}} {
+			for _, p := range group.packages {
+				if p.Name == name || path.Base(p.Name) == name {
+					return &packageInfo{Kind: group.kind, Name: p.Name, Description: p.Description, Category: p.Category,
+						Reason: p.Reason, AddedBy: p.AddedBy, AddedDate: p.AddedDate}, nil
+				}
+			}
+		}
+	}
+
+	masPath := filepath.Join(repo.GetToolConfigDir("mas"), "mas.toml")
+	if _, err := os.Stat(masPath); err != nil {
+		return nil, nil
+	}
+	masConfig, err := parser.ParseMASTOML(masPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse mas.toml: %w", err)
+	}
+	if apps, _ := installer.SelectNamedApps(masConfig.Apps, []string{name}); len(apps) > 0 {
+		a := apps[0]
+		return &packageInfo{Kind: "app", Name: a.Name, ID: a.ID, Description: a.Description, Category: a.Category,
+			Reason: a.Reason, AddedBy: a.AddedBy, AddedDate: a.AddedDate}, nil
+	}
+	return nil, nil
+}
+
+func printPackageInfo(pkg *packageInfo, asJSON bool) error {
+	if pkg.Kind == "formula" && system.CheckHomebrew().Exists {
+		users, err := installer.BrewUses(nil, pkg.Name)
+		if err != nil {
+			logger.Debug("dependents not listed", "error", err)
+		}
+		pkg.RequiredBy = users
+	}
+
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(pkg)
+	}
+
+	fmt.Printf("\n📦 %s (%s", pkg.Name, pkg.Kind)
+	if pkg.ID != 0 {
+		fmt.Printf(" %d", pkg.ID)
+	}
+	fmt.Println(")")
+	if pkg.Description != "" {
+		fmt.Printf("   %s\n", pkg.Description)
+	}
+	if pkg.Category != "" {
+		fmt.Printf("   Category: %s\n", pkg.Category)
+	}
+	fmt.Println()
+	if pkg.Reason != "" {
+		fmt.Printf("   %-12s %s\n", "Why:", pkg.Reason)
+	}
+	if pkg.AddedBy != "" {
+		fmt.Printf("   %-12s %s\n", "Added by:", pkg.AddedBy)
+	}
+	if pkg.AddedDate != "" {
+		fmt.Printf("   %-12s %s\n", "Added on:", pkg.AddedDate)
+	}
+	if pkg.Reason == "" && pkg.AddedBy == "" && pkg.AddedDate == "" {
+		fmt.Println(cli.Dim("   No reason recorded; add reason = \"...\" to its entry"))
+	}
+	if len(pkg.RequiredBy) > 0 {
+		fmt.Printf("   %-12s %s\n", "Required by:", strings.Join(pkg.RequiredBy, ", "))
+	}
+	return nil
+}
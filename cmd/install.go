@@ -9,8 +9,15 @@ import (
 	"github.com/ildx/merlin/internal/cli"
 	"github.com/ildx/merlin/internal/config"
 	"github.com/ildx/merlin/internal/installer"
+	"github.com/ildx/merlin/internal/logger"
+	"github.com/ildx/merlin/internal/machine"
 	"github.com/ildx/merlin/internal/models"
+	"github.com/ildx/merlin/internal/notify"
 	"github.com/ildx/merlin/internal/parser"
+	"github.com/ildx/merlin/internal/report"
+	"github.com/ildx/merlin/internal/scripts"
+	"github.com/ildx/merlin/internal/state"
+	"github.com/ildx/merlin/internal/symlink"
 	"github.com/ildx/merlin/internal/system"
 	"github.com/spf13/cobra"
 )
@@ -21,8 +28,10 @@ var installCmd = &cobra.Command{
 	Long: `Install Homebrew packages and Mac App Store applications defined in TOML.
 
 SUBCOMMANDS
-	brew   Install Homebrew formulae & casks from brew.toml
-	mas    Install Mac App Store apps from mas.toml
+	brew         Install Homebrew formulae & casks from brew.toml
+	mas          Install Mac App Store apps from mas.toml
+	extensions   Install VS Code / Cursor extensions from [[extension]] entries
+	tool <name>  Install one tool's packages, then link it and run its scripts
 
 BEHAVIOR
 	Interactive selector is shown unless --all or --dry-run is used.
@@ -33,12 +42,36 @@ FLAGS (brew)
 	--formulae-only  Only install formulae
 	--casks-only     Only install casks
 	--dry-run        Show what would be installed
-	--verbose,-v     More detailed output
+	-vv              Stream brew's output while installing
 
 FLAGS (mas)
 	--all            Install all apps without prompting
 	--dry-run        Preview actions only
-	--verbose,-v     More detailed output
+	-vv              Stream mas output while installing
+
+FLAGS (extensions)
+	--editor <name>  Only install extensions for code or cursor
+	--dry-run        Preview actions only
+
+FLAGS (tool)
+	--no-link        Only install the packages
+	--no-scripts     Don't run the tool's scripts after linking
+	--strategy <s>   Conflict strategy for linking (skip|backup|overwrite)
+	--trust-all      Run new or changed scripts without confirmation
+	--param n=v      Value for a script param (repeatable)
+
+FLAGS (brew, mas)
+	--selection <file>       Install the packages listed in a selection file
+	                         instead of picking them
+	--save-selection <file>  Save the packages picked (or installed) to a
+	                         selection file, to replay with --selection
+
+FLAGS (all)
+	--retries <n>    Retry network/download failures n times with backoff
+	                 (default: settings.install_retries)
+	--summary        Print the final summary and failures only
+	--quiet          Print errors only; brew and mas need --all or
+	                 --selection
 
 EXAMPLES
 	merlin install brew                 # Interactive picker
@@ -46,9 +79,17 @@ EXAMPLES
 	merlin install brew --formulae-only # Only CLI tools
 	merlin install mas                  # Interactive MAS selection
 	merlin install mas --all --dry-run  # Preview full install
+	merlin install brew --save-selection ~/work.toml  # Pick once...
+	merlin install brew --selection ~/work.toml       # ...replay elsewhere
+	merlin install extensions           # Every tool's [[extension]] list
+	merlin install extensions cursor    # Only config/cursor/merlin.toml
+	merlin install tool karabiner       # Packages, links and scripts
 
 NOTES
+	• With [settings.notify] webhook_url set, a summary is posted when an
+	  install finishes (on_failure_only = true: only when something failed).
 	• For MAS installs you must be signed into the App Store.
+	• With --offline, installs are skipped (dry runs still work).
 	• Use merlin list brew|mas to inspect definitions first.
 	• Combine with --dry-run to stage changes safely.`,
 	Run: func(cmd *cobra.Command, args []string) {
@@ -63,7 +104,10 @@ var installBrewCmd = &cobra.Command{
 
 By default, this command will interactively prompt you to select which packages to install.
 Use --all to install all packages without prompting.
-Use --dry-run to preview what would be installed without actually installing.`,
+Use --dry-run to preview what would be installed without actually installing.
+
+Taps declared with [[tap]] are checked first (auth_env variables set, remote
+reachable without a password prompt) and tapped before any package installs.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := runInstallBrew(cmd); err != nil {
 			cli.Error("%v", err)
@@ -90,10 +134,57 @@ Note: You must be signed into the Mac App Store for installation to work.`,
 	},
 }
 
+var installExtensionsCmd = &cobra.Command{
+	Use:   "extensions [tool]",
+	Short: "Install VS Code / Cursor extensions",
+	Long: `Install editor extensions declared as [[extension]] entries in tool merlin.toml
+files, using the editor's CLI (code or cursor).
+
+Extensions the editor already lists (--list-extensions) are skipped, so the
+command is safe to re-run. Pass a tool name to install only that tool's list.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runInstallExtensions(cmd, args); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var installToolCmd = &cobra.Command{
+	Use:   "tool <name>",
+	Short: "Set up one tool: its packages, links and scripts",
+	Long: `Install exactly the packages a tool needs, then link it and run its scripts.
+
+The packages are the tool's dependencies (matched by name against brew.toml
+formulae and casks, then mas.toml apps) plus its optional [packages] block:
+
+	[packages]
+	formulae = ["goku"]
+	casks = ["karabiner-elements"]
+	mas = ["Xcode"]   # names from mas.toml
+
+Dependencies matching no package (e.g. "brew" itself) are listed and skipped.
+When a package fails to install, linking and scripts are skipped.
+
+A report of the phases (packages, links, scripts) with their failures,
+durations and follow-ups such as pending manual steps is printed at the end;
+--report also writes it to ~/.merlin/reports/<timestamp>.md.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runInstallTool(cmd, args[0]); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
 func init() {
 	rootCmd.AddCommand(installCmd)
 	installCmd.AddCommand(installBrewCmd)
 	installCmd.AddCommand(installMASCmd)
+	installCmd.AddCommand(installExtensionsCmd)
+	installCmd.AddCommand(installToolCmd)
 
 	// Brew flags
 	installBrewCmd.Flags().Bool("formulae-only", false, "Install only formulae")
@@ -102,15 +193,57 @@ func init() {
 
 	// MAS flags
 	installMASCmd.Flags().Bool("all", false, "Install all apps without prompting")
+
+	for _, c := range []*cobra.Command{installBrewCmd, installMASCmd} {
+		c.Flags().String("selection", "", "Install the packages listed in this selection file instead of prompting")
+		c.Flags().String("save-selection", "", "Save the chosen packages to this selection file")
+	}
+
+	// Extension flags
+	installExtensionsCmd.Flags().String("editor", "", "Only install extensions for this editor (code or cursor)")
+
+	// Tool flags
+	installToolCmd.Flags().Bool("no-link", false, "Only install the tool's packages")
+	installToolCmd.Flags().Bool("no-scripts", false, "Don't run the tool's scripts")
+	installToolCmd.Flags().String("strategy", "skip", "Conflict strategy for linking (skip|backup|overwrite)")
+	installToolCmd.Flags().Bool("report", false, "Also write the final report to ~/.merlin/reports")
+	installToolCmd.Flags().BoolVar(&scriptsTrustAll, "trust-all", false, "Run new or changed scripts without confirmation")
+	installToolCmd.Flags().StringArrayVar(&scriptsParams, "param", nil, "Value for a script param as name=value")
+
+	installCmd.PersistentFlags().Int("retries", 0, "Retry network failures this many times (default: settings.install_retries)")
+	addOutputFlags(installCmd.PersistentFlags())
+}
+
+// installRetryPolicy resolves the retry count from --retries, falling back to the
+// install_retries setting in the root merlin.toml.
+func installRetryPolicy(cmd *cobra.Command, repo *config.DotfilesRepo) installer.RetryPolicy {
+	if cmd.Flags().Changed("retries") {
+		retries, _ := cmd.Flags().GetInt("retries")
+		return installer.RetryPolicy{Retries: retries}
+	}
+	if repo != nil {
+		if rootConfig, err := parser.ParseRootMerlinTOML(repo.GetRootMerlinConfig()); err == nil {
+			return installer.RetryPolicy{Retries: rootConfig.Settings.InstallRetries}
+		}
+	}
+	return installer.RetryPolicy{}
 }
 
 func runInstallBrew(cmd *cobra.Command) error {
 	// Get flags
 	dryRun, _ := cmd.Flags().GetBool("dry-run")
-	verbose, _ := cmd.Flags().GetBool("verbose")
+	verbosity := verbosityLevel(cmd)
 	formulaeOnly, _ := cmd.Flags().GetBool("formulae-only")
 	casksOnly, _ := cmd.Flags().GetBool("casks-only")
 	installAll, _ := cmd.Flags().GetBool("all")
+	selectionPath, _ := cmd.Flags().GetString("selection")
+	savePath, _ := cmd.Flags().GetString("save-selection")
+
+	if offlineMode(cmd) && !dryRun {
+		cli.Warning("Offline mode: skipping Homebrew installs (network required)")
+		fmt.Println("   Preview with --dry-run or inspect definitions with 'merlin list brew'.")
+		return nil
+	}
 
 	// Check prerequisites
 	fmt.Println("\n🔍 Checking prerequisites...")
@@ -130,19 +263,27 @@ func runInstallBrew(cmd *cobra.Command) error {
 
 	// Find and parse brew.toml
 	fmt.Println("\n📋 Loading package list...")
-	brewPath := filepath.Join(repo.GetToolConfigDir("brew"), "brew.toml")
-	if _, err := os.Stat(brewPath); os.IsNotExist(err) {
-		return fmt.Errorf("brew.toml not found at %s", brewPath)
-	}
-
-	brewConfig, err := parser.ParseBrewTOML(brewPath)
+	brewConfig, err := parser.ParseBrewConfig(repo.GetToolConfigDir("brew"))
 	if err != nil {
-		return fmt.Errorf("failed to parse brew.toml: %w", err)
+		return err
 	}
 
 	totalPackages := len(brewConfig.Formulae) + len(brewConfig.Casks)
 	fmt.Printf("   ✓ Found %d packages (%d formulae, %d casks)\n",
 		totalPackages, len(brewConfig.Formulae), len(brewConfig.Casks))
+	brewConfig = brewConfig.ForHost(machine.CurrentHost(repo))
+	if skipped := totalPackages - len(brewConfig.Formulae) - len(brewConfig.Casks); skipped > 0 {
+		fmt.Printf("   ⊘ %d not for this machine (when conditions)\n", skipped)
+	}
+
+	// An inaccessible private tap would fail brew halfway through, so check first
+	if len(brewConfig.Taps) > 0 {
+		fmt.Println("\n🔑 Checking taps...")
+		if err := installer.CheckTaps(nil, brewConfig.Taps); err != nil {
+			return err
+		}
+		fmt.Printf("   ✓ %d taps accessible\n", len(brewConfig.Taps))
+	}
 
 	// Filter packages based on flags
 	var formulae, casks []models.BrewPackage
@@ -153,13 +294,36 @@ func runInstallBrew(cmd *cobra.Command) error {
 		casks = brewConfig.Casks
 	}
 
+	if selectionPath != "" {
+		selection, err := installer.LoadSelection(selectionPath)
+		if err != nil {
+			return err
+		}
+		var unknownFormulae, unknownCasks []string
+		formulae, unknownFormulae = installer.SelectNamed(formulae, selection.Formulae)
+		casks, unknownCasks = installer.SelectNamed(casks, selection.Casks)
+		if casksOnly {
+			unknownFormulae = nil
+		}
+		if formulaeOnly {
+			unknownCasks = nil
+		}
+		fmt.Printf("   ✓ Selection %s: %d formulae, %d casks\n", selectionPath, len(formulae), len(casks))
+		if unknown := append(unknownFormulae, unknownCasks...); len(unknown) > 0 {
+			cli.Warning("%d selected package(s) not declared for this machine: %s", len(unknown), strings.Join(unknown, ", "))
+		}
+	}
+
 	if len(formulae) == 0 && len(casks) == 0 {
 		fmt.Println("\n⚠️  No packages to install (check your flags)")
 		return nil
 	}
 
-	// Interactive selection (unless --all is specified or dry-run)
-	if !installAll && !dryRun {
+	// Interactive selection (unless --all, --selection or dry-run)
+	if !installAll && !dryRun && selectionPath == "" {
+		if err := cli.CheckPrompt("the package picker (use --all or --selection)"); err != nil {
+			return err
+		}
 		var err error
 
 		// Select formulae
@@ -195,19 +359,46 @@ func runInstallBrew(cmd *cobra.Command) error {
 		}
 	}
 
+	if savePath != "" {
+		selection := &installer.Selection{}
+		if !casksOnly {
+			selection.Formulae = installer.PackageNames(formulae)
+		}
+		if !formulaeOnly {
+			selection.Casks = installer.PackageNames(casks)
+		}
+		if err := installer.SaveSelection(savePath, selection); err != nil {
+			return err
+		}
+		fmt.Printf("\n💾 Saved selection to %s (replay with --selection %s)\n", savePath, savePath)
+	}
+
 	// Dry run notification
 	if dryRun {
 		fmt.Println("\n🔍 DRY RUN MODE - No packages will be installed")
 	}
 
 	// Create installer
-	brewInstaller := installer.NewBrewInstaller(dryRun, verbose)
+	brewInstaller := installer.NewBrewInstaller(dryRun, verbosity)
+	brewInstaller.Retry = installRetryPolicy(cmd, repo)
 
 	// Install packages
 	fmt.Printf("\n%s\n", strings.Repeat("═", 80))
 	fmt.Println("Starting Installation")
 	fmt.Println(strings.Repeat("═", 80))
 
+	tapped, err := installer.TapAll(nil, brewConfig.Taps, dryRun, verbosity.Stream(), os.Stdout)
+	if err != nil {
+		return err
+	}
+	if len(tapped) > 0 {
+		verb := "Tapped"
+		if dryRun {
+			verb = "Would tap"
+		}
+		fmt.Printf("\n🚰 %s: %s\n", verb, strings.Join(tapped, ", "))
+	}
+
 	var formulaeResults, caskResults []*installer.InstallResult
 
 	// Install formulae
@@ -221,7 +412,9 @@ func runInstallBrew(cmd *cobra.Command) error {
 	}
 
 	// Print summary
-	installer.PrintSummary(formulaeResults, caskResults, os.Stdout)
+	installer.PrintSummary(formulaeResults, caskResults, cli.SummaryOutput())
+	reportInstall(cmd, repo, installSummary("install brew", formulaeResults, caskResults))
+	recordMachineSync(cmd, repo)
 
 	return nil
 }
@@ -229,8 +422,16 @@ func runInstallBrew(cmd *cobra.Command) error {
 func runInstallMAS(cmd *cobra.Command) error {
 	// Get flags
 	dryRun, _ := cmd.Flags().GetBool("dry-run")
-	verbose, _ := cmd.Flags().GetBool("verbose")
+	verbosity := verbosityLevel(cmd)
 	installAll, _ := cmd.Flags().GetBool("all")
+	selectionPath, _ := cmd.Flags().GetString("selection")
+	savePath, _ := cmd.Flags().GetString("save-selection")
+
+	if offlineMode(cmd) && !dryRun {
+		cli.Warning("Offline mode: skipping Mac App Store installs (network required)")
+		fmt.Println("   Preview with --dry-run or inspect definitions with 'merlin list mas'.")
+		return nil
+	}
 
 	// Check prerequisites
 	fmt.Println("\n🔍 Checking prerequisites...")
@@ -243,7 +444,7 @@ func runInstallMAS(cmd *cobra.Command) error {
 	fmt.Printf("   ✓ mas-cli found: %s\n", masCheck.Version)
 
 	// Check if signed into Mac App Store
-	masInstaller := installer.NewMASInstaller(dryRun, verbose)
+	masInstaller := installer.NewMASInstaller(dryRun, verbosity)
 	signedIn, account, err := masInstaller.CheckMASAccount()
 	if err != nil {
 		return fmt.Errorf("failed to check Mac App Store account: %w", err)
@@ -285,12 +486,38 @@ func runInstallMAS(cmd *cobra.Command) error {
 	}
 
 	fmt.Printf("   ✓ Found %d app(s)\n", len(masConfig.Apps))
+	total := len(masConfig.Apps)
+	masConfig = masConfig.ForHost(machine.CurrentHost(repo))
+	if skipped := total - len(masConfig.Apps); skipped > 0 {
+		fmt.Printf("   ⊘ %d not for this machine (when conditions)\n", skipped)
+	}
+	masInstaller.Retry = installRetryPolicy(cmd, repo)
 
 	// Get apps list
 	apps := masConfig.Apps
 
-	// Interactive selection (unless --all is specified or dry-run)
-	if !installAll && !dryRun {
+	if selectionPath != "" {
+		selection, err := installer.LoadSelection(selectionPath)
+		if err != nil {
+			return err
+		}
+		var unknown []string
+		apps, unknown = installer.SelectNamedApps(apps, selection.MAS)
+		fmt.Printf("   ✓ Selection %s: %d app(s)\n", selectionPath, len(apps))
+		if len(unknown) > 0 {
+			cli.Warning("%d selected app(s) not declared for this machine: %s", len(unknown), strings.Join(unknown, ", "))
+		}
+		if len(apps) == 0 {
+			fmt.Println("\n⚠️  No apps to install")
+			return nil
+		}
+	}
+
+	// Interactive selection (unless --all, --selection or dry-run)
+	if !installAll && !dryRun && selectionPath == "" {
+		if err := cli.CheckPrompt("the app picker (use --all or --selection)"); err != nil {
+			return err
+		}
 		var err error
 
 		// Select apps
@@ -316,6 +543,13 @@ func runInstallMAS(cmd *cobra.Command) error {
 		}
 	}
 
+	if savePath != "" {
+		if err := installer.SaveSelection(savePath, &installer.Selection{MAS: installer.AppNames(apps)}); err != nil {
+			return err
+		}
+		fmt.Printf("\n💾 Saved selection to %s (replay with --selection %s)\n", savePath, savePath)
+	}
+
 	// Dry run notification
 	if dryRun {
 		fmt.Println("\n🔍 DRY RUN MODE - No apps will be installed")
@@ -329,7 +563,326 @@ func runInstallMAS(cmd *cobra.Command) error {
 	results := masInstaller.InstallApps(apps, os.Stdout)
 
 	// Print summary
-	installer.PrintMASSummary(results, os.Stdout)
+	installer.PrintMASSummary(results, cli.SummaryOutput())
+	reportInstall(cmd, repo, installSummary("install mas", results))
+	recordMachineSync(cmd, repo)
 
 	return nil
 }
+
+func runInstallExtensions(cmd *cobra.Command, args []string) error {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	verbosity := verbosityLevel(cmd)
+	editor, _ := cmd.Flags().GetString("editor")
+
+	switch editor {
+	case "", models.EditorCode, models.EditorCursor:
+	default:
+		return fmt.Errorf("invalid --editor %q (must be: code or cursor)", editor)
+	}
+
+	if offlineMode(cmd) && !dryRun {
+		cli.Warning("Offline mode: skipping extension installs (network required)")
+		fmt.Println("   Preview with --dry-run.")
+		return nil
+	}
+
+	repo, err := config.FindDotfilesRepo()
+	if err != nil {
+		return fmt.Errorf("dotfiles repository not found: %w", err)
+	}
+
+	tools, err := repo.ListTools()
+	if err != nil {
+		return fmt.Errorf("failed to list tools: %w", err)
+	}
+	if len(args) == 1 {
+		if !repo.ToolExists(args[0]) {
+			return fmt.Errorf("tool '%s' not found in dotfiles repository", args[0])
+		}
+		tools = []string{args[0]}
+	}
+
+	var extensions []models.Extension
+	for _, tool := range tools {
+		merlinPath := repo.GetToolMerlinConfig(tool)
+		if _, err := os.Stat(merlinPath); os.IsNotExist(err) {
+			continue
+		}
+		toolConfig, err := parser.ParseToolMerlinTOML(merlinPath)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", repo.Rel(merlinPath), err)
+		}
+		if !toolConfig.IsEnabled() && len(args) == 0 {
+			continue
+		}
+		if err := parser.ValidateExtensions(toolConfig.Extensions); err != nil {
+			return fmt.Errorf("%s: %w", repo.Rel(merlinPath), err)
+		}
+		for _, ext := range toolConfig.Extensions {
+			if editor == "" || ext.Editor == editor {
+				extensions = append(extensions, ext)
+			}
+		}
+	}
+
+	if len(extensions) == 0 {
+		fmt.Println("No [[extension]] entries to install")
+		return nil
+	}
+
+	if dryRun {
+		fmt.Println("\n🔍 DRY RUN MODE - No extensions will be installed")
+	}
+
+	extInstaller := installer.NewExtensionInstaller(dryRun, verbosity)
+	extInstaller.Retry = installRetryPolicy(cmd, repo)
+
+	results := extInstaller.InstallExtensions(extensions, os.Stdout)
+	installer.PrintExtensionSummary(results, cli.SummaryOutput())
+	reportInstall(cmd, repo, installSummary("install extensions", results))
+	recordMachineSync(cmd, repo)
+
+	return nil
+}
+
+func runInstallTool(cmd *cobra.Command, toolName string) error {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	verbosity := verbosityLevel(cmd)
+	noLink, _ := cmd.Flags().GetBool("no-link")
+	noScripts, _ := cmd.Flags().GetBool("no-scripts")
+	strategyFlag, _ := cmd.Flags().GetString("strategy")
+	writeReport, _ := cmd.Flags().GetBool("report")
+
+	strategy, err := symlink.ParseStrategy(strategyFlag)
+	if err != nil {
+		return err
+	}
+	if err := checkLinkPrompts(strategy, dryRun); err != nil {
+		return err
+	}
+
+	repo, err := config.FindDotfilesRepo()
+	if err != nil {
+		return fmt.Errorf("dotfiles repository not found: %w", err)
+	}
+	if !repo.ToolExists(toolName) {
+		return fmt.Errorf("tool '%s' not found in dotfiles repository", toolName)
+	}
+	merlinPath := repo.GetToolMerlinConfig(toolName)
+	toolConfig, err := parser.ParseToolMerlinTOML(merlinPath)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", repo.Rel(merlinPath), err)
+	}
+	if !toolConfig.IsEnabled() {
+		return fmt.Errorf("tool '%s' is disabled (run 'merlin tool enable %s' first)", toolName, toolName)
+	}
+
+	brewConfig, masConfig, err := loadPackageDefinitions(repo)
+	if err != nil {
+		return err
+	}
+	pkgs := installer.ResolveToolPackages(toolConfig, brewConfig, masConfig)
+	skipped := pkgs.ForHost(machine.CurrentHost(repo))
+
+	rep := report.New("install tool "+toolName, dryRun)
+	defer func() {
+		rep.Finish()
+		fmt.Fprintln(cli.SummaryOutput())
+		fmt.Fprint(cli.SummaryOutput(), rep.Text())
+		if writeReport {
+			if path, err := rep.Write(); err != nil {
+				cli.Warning("Report not written: %v", err)
+			} else {
+				fmt.Println(cli.Dim("Report written to " + path))
+			}
+		}
+	}()
+
+	fmt.Printf("\n🧰 Setting up %s\n", toolName)
+	for _, name := range pkgs.Unresolved {
+		fmt.Println(cli.Dim(fmt.Sprintf("   %s is not in brew.toml or mas.toml; skipped", name)))
+	}
+	for _, name := range skipped {
+		fmt.Println(cli.Dim(fmt.Sprintf("   %s is not for this machine (when); skipped", name)))
+	}
+
+	done := rep.Track("packages")
+	summary, err := installToolPackages(cmd, repo, toolName, pkgs, dryRun, verbosity)
+	if err != nil {
+		rep.Skip("packages", err.Error())
+		return err
+	}
+	done(summary.Succeeded, summary.Failures)
+	if summary.Failed > 0 {
+		rep.Skip("links", "package failures")
+		rep.Skip("scripts", "package failures")
+		rep.Todo("Fix the failed package(s), then re-run: merlin install tool %s", toolName)
+		return fmt.Errorf("%d package(s) failed to install; linking and scripts skipped (re-run 'merlin install tool %s' after fixing them)", summary.Failed, toolName)
+	}
+
+	if noLink {
+		rep.Skip("links", "--no-link")
+	} else {
+		rootConfig, err := parser.ParseRootMerlinTOML(repo.GetRootMerlinConfig())
+		if err != nil {
+			return fmt.Errorf("failed to parse root config: %w", err)
+		}
+		vars, err := symlink.GetVariablesFromRoot(rootConfig)
+		if err != nil {
+			return fmt.Errorf("failed to get variables: %w", err)
+		}
+		fmt.Println()
+		done := rep.Track("links")
+		linked, failed := linkOutcome(runLinkTool(repo, toolName, vars, strategy, dryRun, verbosity, false))
+		done(linked, failed)
+		if len(failed) > 0 {
+			rep.Todo("Resolve the link conflicts: merlin conflicts %s", toolName)
+		}
+	}
+
+	switch {
+	case noScripts:
+		rep.Skip("scripts", "--no-scripts")
+	case !toolConfig.HasScripts():
+		rep.Skip("scripts", "none configured")
+	default:
+		fmt.Println()
+		done := rep.Track("scripts")
+		results, err := runToolScripts(toolName, dryRun, verbosity)
+		ran, failed := scriptOutcome(results)
+		done(ran, failed)
+		if err != nil {
+			if len(failed) > 0 {
+				rep.Todo("Check the script output: merlin scripts logs %s", toolName)
+			}
+			return err
+		}
+	}
+
+	addPendingSteps(rep, toolName, toolConfig.ManualSteps)
+	recordMachineSync(cmd, repo)
+	return nil
+}
+
+// linkOutcome counts the links that are in place and names the targets that
+// conflicted or failed
+func linkOutcome(results []*symlink.LinkResult) (linked int, failed []string) {
+	for _, r := range results {
+		switch r.Status {
+		case symlink.LinkStatusSuccess, symlink.LinkStatusAlreadyLinked:
+			linked++
+		case symlink.LinkStatusConflict, symlink.LinkStatusError:
+			failed = append(failed, r.Target)
+		}
+	}
+	return linked, failed
+}
+
+// scriptOutcome counts the scripts that ran and names the ones that failed
+func scriptOutcome(results []*scripts.ScriptResult) (ran int, failed []string) {
+	for _, r := range results {
+		switch {
+		case r.Skipped:
+		case r.Success:
+			ran++
+		default:
+			failed = append(failed, r.Script)
+		}
+	}
+	return ran, failed
+}
+
+// addPendingSteps lists the tool's manual steps not yet done on this machine
+// as follow-ups
+func addPendingSteps(rep *report.Report, toolName string, steps []models.ManualStep) {
+	if len(steps) == 0 {
+		return
+	}
+	todo, err := state.LoadTodoState()
+	if err != nil {
+		logger.Debug("manual step state not loaded", "error", err)
+	}
+	for _, step := range steps {
+		address := toolName + "/" + step.ID
+		if !todo.IsDone(address) {
+			rep.Todo("%s (then: merlin todo done %s)", step.Description, address)
+		}
+	}
+}
+
+// loadPackageDefinitions parses brew.toml and mas.toml; a missing file
+// yields nil
+func loadPackageDefinitions(repo *config.DotfilesRepo) (*models.BrewConfig, *models.MASConfig, error) {
+	var brewConfig *models.BrewConfig
+	if brewDir := repo.GetToolConfigDir("brew"); parser.HasBrewConfig(brewDir) {
+		var err error
+		if brewConfig, err = parser.ParseBrewConfig(brewDir); err != nil {
+			return nil, nil, err
+		}
+	}
+	var masConfig *models.MASConfig
+	masPath := filepath.Join(repo.GetToolConfigDir("mas"), "mas.toml")
+	if _, err := os.Stat(masPath); err == nil {
+		if masConfig, err = parser.ParseMASTOML(masPath); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse mas.toml: %w", err)
+		}
+	}
+	return brewConfig, masConfig, nil
+}
+
+// installToolPackages installs a tool's resolved packages without prompting
+// and summarises the outcome
+func installToolPackages(cmd *cobra.Command, repo *config.DotfilesRepo, toolName string, pkgs *installer.ToolPackages, dryRun bool, verbosity cli.Verbosity) (notify.Summary, error) {
+	if pkgs.Empty() {
+		fmt.Println("   No packages to install")
+		return notify.Summary{}, nil
+	}
+	if offlineMode(cmd) && !dryRun {
+		cli.Warning("Offline mode: skipping package installs (network required)")
+		return notify.Summary{}, nil
+	}
+	if dryRun {
+		fmt.Println("\n🔍 DRY RUN MODE - No packages will be installed")
+	}
+
+	retry := installRetryPolicy(cmd, repo)
+	var formulaeResults, caskResults, appResults []*installer.InstallResult
+	if len(pkgs.Formulae) > 0 || len(pkgs.Casks) > 0 {
+		if !dryRun && !system.CheckHomebrew().Exists {
+			return notify.Summary{}, fmt.Errorf("Homebrew is not installed. Install it from https://brew.sh")
+		}
+		brewInstaller := installer.NewBrewInstaller(dryRun, verbosity)
+		brewInstaller.Retry = retry
+		if len(pkgs.Formulae) > 0 {
+			formulaeResults = brewInstaller.InstallFormulae(pkgs.Formulae, os.Stdout)
+		}
+		if len(pkgs.Casks) > 0 {
+			caskResults = brewInstaller.InstallCasks(pkgs.Casks, os.Stdout)
+		}
+		installer.PrintSummary(formulaeResults, caskResults, os.Stdout)
+	}
+
+	if len(pkgs.Apps) > 0 {
+		masInstaller := installer.NewMASInstaller(dryRun, verbosity)
+		masInstaller.Retry = retry
+		if !dryRun {
+			if !system.CheckMAS().Exists {
+				return notify.Summary{}, fmt.Errorf("mas-cli is not installed. Install it with: brew install mas")
+			}
+			signedIn, _, err := masInstaller.CheckMASAccount()
+			if err != nil {
+				return notify.Summary{}, fmt.Errorf("failed to check Mac App Store account: %w", err)
+			}
+			if !signedIn {
+				return notify.Summary{}, fmt.Errorf("not signed into Mac App Store")
+			}
+		}
+		appResults = masInstaller.InstallApps(pkgs.Apps, os.Stdout)
+		installer.PrintMASSummary(appResults, os.Stdout)
+	}
+
+	summary := installSummary("install tool "+toolName, formulaeResults, caskResults, appResults)
+	reportInstall(cmd, repo, summary)
+	return summary, nil
+}
@@ -4,47 +4,36 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
-	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/ildx/merlin/internal/backup"
 	"github.com/ildx/merlin/internal/cli"
 	"github.com/ildx/merlin/internal/config"
 	"github.com/ildx/merlin/internal/git"
+	"github.com/ildx/merlin/internal/machine"
+	"github.com/ildx/merlin/internal/metrics"
 	"github.com/ildx/merlin/internal/models"
 	"github.com/ildx/merlin/internal/parser"
+	"github.com/ildx/merlin/internal/progress"
 	"github.com/ildx/merlin/internal/scripts"
 	"github.com/ildx/merlin/internal/symlink"
+	"github.com/ildx/merlin/internal/system"
 	"github.com/spf13/cobra"
 )
 
-// buildLinkCommitMessage crafts a concise commit message for auto-commit after linking.
-// Format examples:
-//
-//	chore(link): link zsh
-//	chore(link): link zsh, git (2 tools)
-//	chore(link): link 5 tools (zsh, git, eza, mise, zellij)
-func buildLinkCommitMessage(tools []string) string {
-	if len(tools) == 0 {
-		return "chore(link): no tools"
-	}
-	if len(tools) == 1 {
-		return fmt.Sprintf("chore(link): link %s", tools[0])
-	}
-	joined := strings.Join(tools, ", ")
-	if len(tools) <= 3 {
-		return fmt.Sprintf("chore(link): link %s (%d tools)", joined, len(tools))
-	}
-	// For many tools, keep message short and list first 3 + ellipsis
-	preview := strings.Join(tools[:3], ", ")
-	return fmt.Sprintf("chore(link): link %d tools (%s, …)", len(tools), preview)
-}
-
 var (
 	linkStrategy     string
 	linkAll          bool
 	linkRunScripts   bool
 	linkProfile      string
 	linkNoAutoCommit bool // per-invocation override for auto-commit
+	linkCommitAnyway bool // auto-commit despite unrelated changes in the repository
+	linkSudo         bool // retry permission-denied links via sudo after confirmation
+	linkExcept       []string
+	linkYes          bool // skip the --all review prompt
+	linkJobs         int  // tools linked at once by --all; 0 is symlink.ToolWorkers
 )
 
 var linkCmd = &cobra.Command{
@@ -54,9 +43,17 @@ var linkCmd = &cobra.Command{
 
 BEHAVIOR
 	• Without flags: link a single tool's configuration.
-	• --all links every discovered tool.
+	• --all links every discovered tool. Disabled tools (enabled = false) are
+	  skipped and listed.
 	• --profile filters tools by a named profile from root merlin.toml.
-	• Variable placeholders in targets (e.g. {home_dir}) are expanded.
+	• Before a batch runs, a table lists each tool's links, existing links and
+	  expected conflicts. On a terminal you can then skip tools by number or
+	  name; --except skips them up front and --yes doesn't ask.
+	• Variable placeholders in targets (e.g. {home_dir}, {xdg_config}) are expanded.
+	• A batch links tools concurrently, except tools whose targets collide or
+	  share a parent directory, which are linked one after another. Output
+	  and post-link steps (scripts, launch agents) follow per tool in the
+	  usual order, each once that tool is linked.
 
 CONFLICT STRATEGIES
 	skip (default)    Leave existing files untouched
@@ -67,9 +64,22 @@ FLAGS
 	--all             Link all tools
 	--strategy <s>    Conflict strategy (skip|backup|overwrite)
 	--run-scripts     Run tool scripts after linking (if defined)
+	--trust-all       Skip confirmation for new/changed scripts (CI)
+	--keep-going      Run remaining scripts after one fails
+	--param n=v       Value for a script param (repeatable)
 	--profile <name>  Filter tools to profile list
+	--except a,b      With --all/--profile, skip these tools
+	-y, --yes         With --all/--profile, don't ask which tools to skip
+	-j, --jobs <n>    With --all/--profile, tools to link at once (default:
+	                  CPUs, at most 8; 1 links them one by one)
+	--sudo            Retry permission-denied links with sudo (asks first)
+	--commit-anyway   Auto-commit even if the repository has unrelated changes
 	--dry-run         Preview actions only
-	--verbose,-v      Detailed per-link output
+	--summary         Print the final counts and failures only
+	--quiet           Print errors only (--all/--profile need --yes)
+	-v                Print every link, including skipped and already linked
+	-vv               Also stream post-link script output
+	-vvv              Also print resolved sources and timings
 
 EXAMPLES
 	merlin link git                            # Link git configs
@@ -78,6 +88,7 @@ EXAMPLES
 	merlin link --all                          # Link everything
 	merlin link --all --profile personal       # Profile-filtered batch
 	merlin link zellij --run-scripts           # Link + run scripts
+	merlin link hosts --sudo                   # Link into /etc with escalation
 
 SEE ALSO
 	merlin unlink   Remove symlinks
@@ -86,7 +97,7 @@ SEE ALSO
 	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		dryRun, _ := cmd.Flags().GetBool("dry-run")
-		verbose, _ := cmd.Flags().GetBool("verbose")
+		verbosity := verbosityLevel(cmd)
 
 		// Parse strategy
 		strategy, err := symlink.ParseStrategy(linkStrategy)
@@ -94,6 +105,10 @@ SEE ALSO
 			cli.Error("%v", err)
 			os.Exit(1)
 		}
+		if err := checkLinkPrompts(strategy, dryRun); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
 
 		// Find dotfiles repo
 		repo, err := config.FindDotfilesRepo()
@@ -102,7 +117,7 @@ SEE ALSO
 			os.Exit(1)
 		}
 
-		if verbose {
+		if verbosity.Debug() {
 			fmt.Printf("Dotfiles repository: %s\n", repo.Root)
 			fmt.Printf("Conflict strategy: %s\n", strategy)
 			if dryRun {
@@ -128,29 +143,36 @@ SEE ALSO
 
 		processedTools := []string{}
 		if linkAll || linkProfile != "" {
-			processedTools = runLinkAll(repo, vars, strategy, dryRun, verbose, linkRunScripts, rootConfig)
+			processedTools = runLinkAll(cmd, repo, vars, strategy, dryRun, verbosity, linkRunScripts, rootConfig)
 		} else if len(args) == 1 {
-			runLinkTool(repo, args[0], vars, strategy, dryRun, verbose, linkRunScripts)
+			runLinkTool(repo, args[0], vars, strategy, dryRun, verbosity, linkRunScripts)
 			processedTools = append(processedTools, args[0])
 		} else {
 			cmd.Help()
 			os.Exit(0)
 		}
 
+		recordMachineSync(cmd, repo)
+
 		// Auto-commit hook (Phase 13 integration + safety) unless overridden
 		if rootConfig.Settings.AutoCommit && !linkNoAutoCommit && !dryRun && git.IsGitAvailable() {
 			if len(processedTools) > 0 {
 				if repoGit, err := git.Open(rootConfigPathDir(repo)); err == nil {
 					paths := make([]string, 0, len(processedTools))
 					for _, t := range processedTools {
-						paths = append(paths, filepath.Join("config", t))
+						paths = append(paths, repo.Rel(repo.GetToolRoot(t)))
 					}
-					// Safety: abort if unrelated unstaged/untracked changes outside allowed paths
-					if unrelated, uErr := repoGit.HasUnrelatedChanges(paths); uErr == nil && unrelated {
-						cli.Warning("auto-commit skipped: unrelated changes detected outside tool directories")
+					// The sync stamp of a registered machine goes with the link
+					paths = append(paths, machine.InventoryFile)
+					info := git.ToolsCommit("link", processedTools)
+					// Safety: skip if unrelated unstaged/untracked changes outside allowed paths
+					if autoCommitBlocked(repoGit, info, repoGit.FilterPaths(paths), linkCommitAnyway) {
+						// reported with the blocking paths
+					} else if batchWindow(rootConfig.Settings.Git) > 0 {
+						queueAutoCommit(repoGit, rootConfig.Settings.Git, info, repoGit.FilterPaths(paths))
 					} else {
 						paths = repoGit.FilterPaths(paths)
-						msg := buildLinkCommitMessage(processedTools)
+						msg := git.CommitMessage(rootConfig.Settings.Git.CommitTemplate, info)
 						if err := repoGit.Commit(msg, paths); err != nil {
 							if strings.Contains(err.Error(), "no staged changes") {
 								// Allow empty commit for traceability
@@ -173,6 +195,61 @@ SEE ALSO
 	},
 }
 
+// backupConflictsBeforeLink snapshots every file the batch would replace into a single
+// backup so one restore undoes the whole run. Only strategies that modify conflicting
+// targets (overwrite, backup) trigger it. Returns the backup ID, or "" if none was made.
+func backupConflictsBeforeLink(tools []*symlink.ToolConfig, strategy symlink.ConflictStrategy, dryRun bool) string {
+	if strategy != symlink.StrategyOverwrite && strategy != symlink.StrategyBackup {
+		return ""
+	}
+
+	conflicts, err := symlink.ConflictTargets(tools)
+	if err != nil {
+		cli.Warning("pre-link backup skipped: %v", err)
+		return ""
+	}
+	if len(conflicts) == 0 {
+		return ""
+	}
+
+	if dryRun {
+		fmt.Printf("Would back up %d conflicting file(s) before linking\n\n", len(conflicts))
+		return ""
+	}
+
+	manifest, err := backup.CreateBackup(conflicts, fmt.Sprintf("Before link --all (%d tools)", len(tools)))
+	if err != nil {
+		cli.Error("pre-link backup failed: %v", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Backed up %d conflicting file(s) (ID: %s)\n\n", len(manifest.Files), manifest.ID)
+	return manifest.ID
+}
+
+// filterToolsByProfile keeps only the tools listed in the named profile.
+// A profile without a tools list selects every tool.
+func filterToolsByProfile(tools []*symlink.ToolConfig, rootConfig *models.RootMerlinConfig, name string) ([]*symlink.ToolConfig, error) {
+	profile := rootConfig.GetProfileByName(name)
+	if profile == nil {
+		return nil, fmt.Errorf("Profile '%s' not found", name)
+	}
+	if len(profile.Tools) == 0 {
+		return tools, nil
+	}
+
+	profileToolSet := make(map[string]bool)
+	for _, toolName := range profile.Tools {
+		profileToolSet[toolName] = true
+	}
+	filteredTools := make([]*symlink.ToolConfig, 0)
+	for _, tool := range tools {
+		if profileToolSet[tool.Name] {
+			filteredTools = append(filteredTools, tool)
+		}
+	}
+	return filteredTools, nil
+}
+
 // rootConfigPathDir extracts repo root directory from DotfilesRepo
 func rootConfigPathDir(repo *config.DotfilesRepo) string { return repo.Root }
 
@@ -181,11 +258,22 @@ func init() {
 	linkCmd.Flags().StringVar(&linkStrategy, "strategy", "skip", "Conflict resolution strategy (skip, backup, overwrite)")
 	linkCmd.Flags().BoolVar(&linkAll, "all", false, "Link all discovered configs")
 	linkCmd.Flags().BoolVar(&linkRunScripts, "run-scripts", false, "Run tool scripts after linking")
+	linkCmd.Flags().BoolVar(&scriptsTrustAll, "trust-all", false, "With --run-scripts, run new or changed scripts without confirmation")
+	linkCmd.Flags().BoolVar(&scriptsKeepGoing, "keep-going", false, "With --run-scripts, run remaining scripts after one fails")
+	linkCmd.Flags().StringArrayVar(&scriptsParams, "param", nil, "With --run-scripts, value for a script param as name=value")
 	linkCmd.Flags().StringVar(&linkProfile, "profile", "", "Use specific profile to filter tools")
 	linkCmd.Flags().BoolVar(&linkNoAutoCommit, "no-auto-commit", false, "Disable auto-commit even if enabled in settings")
+	linkCmd.Flags().BoolVar(&linkCommitAnyway, "commit-anyway", false, "Auto-commit the linked tools even if other files changed")
+	linkCmd.Flags().BoolVar(&linkSudo, "sudo", false, "Retry permission-denied links with sudo after confirmation")
+	linkCmd.Flags().StringSliceVar(&linkExcept, "except", nil, "With --all or --profile, skip these tools (comma-separated)")
+	linkCmd.Flags().BoolVarP(&linkYes, "yes", "y", false, "With --all or --profile, link without reviewing the tool list")
+	linkCmd.Flags().IntVarP(&linkJobs, "jobs", "j", 0, "With --all or --profile, tools to link at once (1 links them one by one)")
+	addOutputFlags(linkCmd.Flags())
 }
 
-func runLinkTool(repo *config.DotfilesRepo, toolName string, vars symlink.Variables, strategy symlink.ConflictStrategy, dryRun, verbose, runScripts bool) {
+// runLinkTool links one tool and returns the link results, nil when it has
+// no links
+func runLinkTool(repo *config.DotfilesRepo, toolName string, vars symlink.Variables, strategy symlink.ConflictStrategy, dryRun bool, verbosity cli.Verbosity, runScripts bool) []*symlink.LinkResult {
 	// Check if tool exists
 	if !repo.ToolExists(toolName) {
 		cli.Error("Tool '%s' not found in dotfiles repository", toolName)
@@ -199,10 +287,20 @@ func runLinkTool(repo *config.DotfilesRepo, toolName string, vars symlink.Variab
 		os.Exit(1)
 	}
 
+	if tool.Disabled {
+		cli.Error("Tool '%s' is disabled (run 'merlin tool enable %s' to link it)", toolName, toolName)
+		os.Exit(1)
+	}
+
 	if len(tool.Links) == 0 {
 		fmt.Printf("No links configured for %s\n", toolName)
-		return
+		if !tool.HasMerlinTOML && vars.RequireMerlinTOML {
+			fmt.Println(cli.Dim("require_merlin_toml is set; add a merlin.toml with [[link]] entries to link it"))
+		}
+		return nil
 	}
+	exitOnCollisions(repo, vars, []*symlink.ToolConfig{tool})
+	warnMissingPermissions([]*symlink.ToolConfig{tool})
 
 	// Display tool info
 	fmt.Printf("Linking %s", toolName)
@@ -211,7 +309,7 @@ func runLinkTool(repo *config.DotfilesRepo, toolName string, vars symlink.Variab
 	}
 	fmt.Println()
 
-	if verbose {
+	if verbosity.Debug() {
 		fmt.Printf("  Links to create: %d\n", len(tool.Links))
 		for i, link := range tool.Links {
 			fmt.Printf("  %d. %s → %s\n", i+1, link.Source, link.Target)
@@ -220,21 +318,36 @@ func runLinkTool(repo *config.DotfilesRepo, toolName string, vars symlink.Variab
 	}
 
 	// Link the tool
+	timings := metrics.Start()
+	phase := progress.Start("link", 1)
+	phase.Begin(tool.Name)
 	results, err := symlink.LinkToolWithStrategy(tool, strategy, dryRun)
 	if err != nil {
 		cli.Warning("linking tool: %v", err)
 	}
+	finishLinkProgress(phase, tool.Name, results)
+	phase.End()
+	escalatePermissionDenied(results, dryRun)
+	timings.Add(tool.Name, len(results), timings.Total())
+	registry := loadLinkRegistry()
+	recordLinks(registry, tool.Name, results)
+	saveLinkRegistry(registry, dryRun)
+	recordLinkState([]*symlink.ToolConfig{tool}, dryRun)
 
 	// Display results
-	displayLinkResults(results, verbose)
+	displayLinkResults(results, verbosity)
+	fmt.Println(cli.Dim(timings.Summary("linked", "file(s)")))
+	loadLaunchAgents(tool, dryRun)
+	markExecutables(tool, dryRun)
 
 	// Run post-link scripts if requested
 	if runScripts {
-		runPostLinkScripts(repo, toolName, vars, dryRun, verbose)
+		runPostLinkScripts(repo, toolName, vars, dryRun, verbosity)
 	}
+	return results
 }
 
-func runPostLinkScripts(repo *config.DotfilesRepo, toolName string, vars symlink.Variables, dryRun, verbose bool) {
+func runPostLinkScripts(repo *config.DotfilesRepo, toolName string, vars symlink.Variables, dryRun bool, verbosity cli.Verbosity) {
 	// Parse tool's merlin.toml
 	merlinPath := repo.GetToolMerlinConfig(toolName)
 	toolConfig, err := parser.ParseToolMerlinTOML(merlinPath)
@@ -254,8 +367,18 @@ func runPostLinkScripts(repo *config.DotfilesRepo, toolName string, vars symlink
 	toolRoot := repo.GetToolRoot(toolName)
 	env := scripts.GetDefaultEnvironment(toolRoot, toolName, vars.HomeDir, vars.ConfigDir)
 
+	if err := ensureScriptsTrusted(toolName, toolRoot, toolConfig, dryRun); err != nil {
+		cli.Warning("Skipping scripts: %v", err)
+		return
+	}
+
 	// Run scripts
-	runner := scripts.NewScriptRunner(toolRoot, env, dryRun, verbose, os.Stdout)
+	runner := scripts.NewScriptRunner(toolRoot, env, dryRun, verbosity, os.Stdout)
+	runner.KeepGoing = scriptsKeepGoing
+	if err := configureScriptParams(runner); err != nil {
+		cli.Warning("Skipping scripts: %v", err)
+		return
+	}
 	scriptResults, err := runner.RunScripts(toolConfig)
 	if err != nil {
 		cli.Warning("Failed to run scripts: %v", err)
@@ -264,11 +387,14 @@ func runPostLinkScripts(repo *config.DotfilesRepo, toolName string, vars symlink
 
 	// Display results
 	for _, result := range scriptResults {
-		fmt.Println(scripts.FormatScriptResult(result, verbose))
+		fmt.Println(scripts.FormatScriptResult(result, verbosity))
+	}
+	if timings := scripts.ScriptTimings(scriptResults); len(timings.Items) > 0 {
+		fmt.Println(cli.Dim("  " + timings.Summary("ran", "script(s)")))
 	}
 }
 
-func runLinkAll(repo *config.DotfilesRepo, vars symlink.Variables, strategy symlink.ConflictStrategy, dryRun, verbose, runScripts bool, rootConfig *models.RootMerlinConfig) []string {
+func runLinkAll(cmd *cobra.Command, repo *config.DotfilesRepo, vars symlink.Variables, strategy symlink.ConflictStrategy, dryRun bool, verbosity cli.Verbosity, runScripts bool, rootConfig *models.RootMerlinConfig) []string {
 	// Discover all tools
 	tools, err := symlink.DiscoverTools(repo, vars)
 	if err != nil {
@@ -276,6 +402,10 @@ func runLinkAll(repo *config.DotfilesRepo, vars symlink.Variables, strategy syml
 		os.Exit(1)
 	}
 
+	if disabled := symlink.DisabledTools(repo); len(disabled) > 0 {
+		fmt.Println(cli.Dim(fmt.Sprintf("Skipping disabled tools: %s", strings.Join(disabled, ", "))))
+	}
+
 	if len(tools) == 0 {
 		fmt.Println("No tools found to link")
 		return []string{}
@@ -283,29 +413,15 @@ func runLinkAll(repo *config.DotfilesRepo, vars symlink.Variables, strategy syml
 
 	// Filter by profile if specified
 	if linkProfile != "" {
-		profile := rootConfig.GetProfileByName(linkProfile)
-		if profile == nil {
-			cli.Error("Profile '%s' not found", linkProfile)
+		filtered, err := filterToolsByProfile(tools, rootConfig, linkProfile)
+		if err != nil {
+			cli.Error("%v", err)
 			os.Exit(1)
 		}
-
-		// Filter tools to only those in profile
-		if len(profile.Tools) > 0 {
-			filteredTools := make([]*symlink.ToolConfig, 0)
-			profileToolSet := make(map[string]bool)
-			for _, name := range profile.Tools {
-				profileToolSet[name] = true
-			}
-
-			for _, tool := range tools {
-				if profileToolSet[tool.Name] {
-					filteredTools = append(filteredTools, tool)
-				}
-			}
-
-			tools = filteredTools
-			fmt.Printf("Using profile '%s' (%d tools)\n\n", linkProfile, len(tools))
+		if len(filtered) != len(tools) {
+			fmt.Printf("Using profile '%s' (%d tools)\n\n", linkProfile, len(filtered))
 		}
+		tools = filtered
 	}
 
 	if len(tools) == 0 {
@@ -313,15 +429,93 @@ func runLinkAll(repo *config.DotfilesRepo, vars symlink.Variables, strategy syml
 		return []string{}
 	}
 
+	tools, err = excludeTools(tools, linkExcept)
+	if err != nil {
+		cli.Error("%v", err)
+		os.Exit(1)
+	}
+	tools = withLinks(tools)
+	if len(tools) == 0 {
+		fmt.Println("No tools left to link")
+		return []string{}
+	}
+	prompt := !linkYes && !dryRun && stdinIsTerminal()
+	if prompt {
+		if err := cli.CheckPrompt("the tool review (use --yes)"); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	}
+	review := &batchReview{
+		Verb:  "link",
+		Table: newTable(cmd, "#", "TOOL", "LINKS", "LINKED", "CONFLICTS"),
+		Row: func(tool *symlink.ToolConfig) []string {
+			total, linked, conflicts := symlink.CountLinks(tool)
+			return []string{strconv.Itoa(total), strconv.Itoa(linked), strconv.Itoa(conflicts)}
+		},
+		Prompt: prompt,
+		Input:  os.Stdin,
+		Output: os.Stdout,
+	}
+	tools, err = review.Review(tools)
+	if err != nil {
+		cli.Error("%v", err)
+		os.Exit(1)
+	}
+	if review.Cancelled || len(tools) == 0 {
+		fmt.Println("Nothing linked.")
+		return []string{}
+	}
+
+	exitOnCollisions(repo, vars, tools)
+	warnMissingPermissions(tools)
 	fmt.Printf("Linking %d tools\n\n", len(tools))
 
+	preLinkBackupID := ""
+	if rootConfig.Settings.AutoBackupBeforeLink {
+		preLinkBackupID = backupConflictsBeforeLink(tools, strategy, dryRun)
+	}
+
 	successCount := 0
 	skipCount := 0
 	errorCount := 0
 	conflictCount := 0
+	var backupIDs []string
 
 	processed := []string{}
-	for _, tool := range tools {
+	var linkedTools []*symlink.ToolConfig
+	timings := metrics.Start()
+
+	// Tools whose targets don't overlap are linked concurrently. Output and
+	// the post-link steps (launch agents, executables, scripts) follow tool by
+	// tool in order, each as soon as that tool is linked
+	type toolRun struct {
+		results []*symlink.LinkResult
+		elapsed time.Duration
+		done    chan struct{}
+	}
+	runs := make([]toolRun, len(tools))
+	for i := range runs {
+		runs[i].done = make(chan struct{})
+	}
+	phase := progress.Start("link", len(tools))
+	linked := make(chan struct{})
+	go func() {
+		defer close(linked)
+		symlink.RunToolGroups(symlink.IndependentGroups(tools, vars), linkToolJobs(strategy, dryRun), func(i int) {
+			defer close(runs[i].done)
+			phase.Begin(tools[i].Name)
+			start := time.Now()
+			runs[i].results, _ = symlink.LinkToolWithStrategy(tools[i], strategy, dryRun)
+			runs[i].elapsed = time.Since(start)
+			finishLinkProgress(phase, tools[i].Name, runs[i].results)
+		})
+	}()
+
+	registry := loadLinkRegistry()
+	notes := loadTargetNotes()
+	for i, tool := range tools {
+		<-runs[i].done
 		if len(tool.Links) == 0 {
 			continue
 		}
@@ -332,37 +526,43 @@ func runLinkAll(repo *config.DotfilesRepo, vars symlink.Variables, strategy syml
 		}
 		fmt.Println()
 
-		results, _ := symlink.LinkToolWithStrategy(tool, strategy, dryRun)
+		results := runs[i].results
+		escalatePermissionDenied(results, dryRun)
+		backupIDs = append(backupIDs, linkBackupIDs(results)...)
+		recordLinks(registry, tool.Name, results)
 
 		for _, result := range results {
 			switch result.Status {
 			case symlink.LinkStatusSuccess:
 				successCount++
-				if verbose {
+				if verbosity.Items() || result.Preview != "" {
 					fmt.Printf("  ✓ %s\n", result.Target)
 				}
+				printPreview(result)
 			case symlink.LinkStatusSkipped:
 				skipCount++
-				if verbose {
+				if verbosity.Items() {
 					fmt.Printf("  ⊘ %s (skipped)\n", result.Target)
 				}
 			case symlink.LinkStatusAlreadyLinked:
 				successCount++
-				if verbose {
+				if verbosity.Items() {
 					fmt.Printf("  ✓ %s (already linked)\n", result.Target)
 				}
 			case symlink.LinkStatusConflict:
 				conflictCount++
-				if verbose {
+				if verbosity.Items() {
 					fmt.Printf("  ⚠ %s (conflict: %s)\n", result.Target, result.Message)
+					printTargetNotes(notes, result.Target, "      ")
 				}
 			case symlink.LinkStatusError:
 				errorCount++
-				fmt.Printf("  ✗ %s (error: %s)\n", result.Target, result.Message)
+				fmt.Fprintf(cli.Failures(), "  ✗ %s (error: %s)\n", result.Target, result.Message)
+				printTargetNotes(notes, result.Target, "      ")
 			}
 		}
 
-		if !verbose {
+		if !verbosity.Items() {
 			// Show summary per tool
 			toolSuccess := 0
 			toolSkip := 0
@@ -378,20 +578,36 @@ func runLinkAll(repo *config.DotfilesRepo, vars symlink.Variables, strategy syml
 			}
 			fmt.Printf("  %d linked, %d skipped, %d errors\n", toolSuccess, toolSkip, toolError)
 		}
+		elapsed := runs[i].elapsed
+		timings.Add(tool.Name, len(results), elapsed)
+		printLinkTiming(verbosity, len(results), elapsed)
+		loadLaunchAgents(tool, dryRun)
+		markExecutables(tool, dryRun)
 
 		fmt.Println()
 
 		// Run post-link scripts if requested
 		if runScripts {
-			runPostLinkScripts(repo, tool.Name, vars, dryRun, verbose)
+			runPostLinkScripts(repo, tool.Name, vars, dryRun, verbosity)
 		}
 		processed = append(processed, tool.Name)
+		linkedTools = append(linkedTools, tool)
 	}
+	<-linked
+	phase.End()
+
+	saveLinkRegistry(registry, dryRun)
+	recordLinkState(linkedTools, dryRun)
 
 	// Summary
 	fmt.Println(strings.Repeat("─", 60))
-	fmt.Printf("Summary: %d linked, %d skipped, %d conflicts, %d errors\n",
+	cli.Summary("Summary: %d linked, %d skipped, %d conflicts, %d errors",
 		successCount, skipCount, conflictCount, errorCount)
+	fmt.Println(cli.Dim(timings.Summary("linked", "file(s)")))
+	if preLinkBackupID != "" {
+		fmt.Printf("Pre-link backup: %s (undo with: merlin backup restore %s)\n", preLinkBackupID, preLinkBackupID)
+	}
+	printLinkBackups(backupIDs)
 
 	if dryRun {
 		fmt.Println("\nThis was a dry run. No changes were made.")
@@ -399,22 +615,54 @@ func runLinkAll(repo *config.DotfilesRepo, vars symlink.Variables, strategy syml
 	return processed
 }
 
-func displayLinkResults(results []*symlink.LinkResult, verbose bool) {
+// finishLinkProgress reports tool as failed in the progress stream when any
+// of its links failed, else as done
+func finishLinkProgress(phase *progress.Phase, tool string, results []*symlink.LinkResult) {
+	counts := make(map[symlink.LinkStatus]int)
+	for _, r := range results {
+		counts[r.Status]++
+	}
+	message := fmt.Sprintf("%d linked, %d already linked, %d skipped, %d conflicts, %d errors",
+		counts[symlink.LinkStatusSuccess], counts[symlink.LinkStatusAlreadyLinked], counts[symlink.LinkStatusSkipped],
+		counts[symlink.LinkStatusConflict], counts[symlink.LinkStatusError])
+	status := progress.StatusDone
+	if counts[symlink.LinkStatusError] > 0 {
+		status = progress.StatusFailed
+	}
+	phase.Finish(tool, status, message)
+}
+
+// linkToolJobs returns how many tools link --all links at once: --jobs, or
+// symlink.ToolWorkers. Backups are keyed by timestamp and conflict prompts
+// can't interleave, so those strategies link one tool at a time.
+func linkToolJobs(strategy symlink.ConflictStrategy, dryRun bool) int {
+	if (strategy == symlink.StrategyBackup && !dryRun) || strategy == symlink.StrategyInteractive {
+		return 1
+	}
+	if linkJobs > 0 {
+		return linkJobs
+	}
+	return symlink.ToolWorkers
+}
+
+func displayLinkResults(results []*symlink.LinkResult, verbosity cli.Verbosity) {
 	successCount := 0
 	skipCount := 0
 	errorCount := 0
+	notes := loadTargetNotes()
 
 	for _, result := range results {
 		switch result.Status {
 		case symlink.LinkStatusSuccess:
 			successCount++
 			symbol := "✓"
-			if verbose {
+			if verbosity.Debug() {
 				fmt.Printf("  %s %s\n", symbol, result.Target)
 				fmt.Printf("    → %s\n", result.Source)
 			} else {
 				fmt.Printf("  %s %s\n", symbol, result.Target)
 			}
+			printPreview(result)
 		case symlink.LinkStatusSkipped:
 			skipCount++
 			fmt.Printf("  ⊘ %s (skipped)\n", result.Target)
@@ -424,13 +672,153 @@ func displayLinkResults(results []*symlink.LinkResult, verbose bool) {
 		case symlink.LinkStatusConflict:
 			skipCount++
 			fmt.Printf("  ⚠ %s (conflict: %s)\n", result.Target, result.Message)
+			printTargetNotes(notes, result.Target, "      ")
 		case symlink.LinkStatusError:
 			errorCount++
-			fmt.Printf("  ✗ %s (error: %s)\n", result.Target, result.Message)
+			fmt.Fprintf(cli.Failures(), "  ✗ %s (error: %s)\n", result.Target, result.Message)
+			printTargetNotes(notes, result.Target, "      ")
 		}
 	}
 
 	fmt.Println()
-	fmt.Printf("Summary: %d linked, %d skipped, %d errors\n",
+	cli.Summary("Summary: %d linked, %d skipped, %d errors",
 		successCount, skipCount, errorCount)
+	printLinkBackups(linkBackupIDs(results))
+}
+
+// printLinkTiming reports how long resolving a tool's links took (-vvv)
+func printLinkTiming(verbosity cli.Verbosity, links int, elapsed time.Duration) {
+	if verbosity.Debug() {
+		fmt.Println(cli.Dim(fmt.Sprintf("  %d link(s) resolved in %s", links, metrics.Format(elapsed))))
+	}
+}
+
+// linkBackupIDs returns the backups created while resolving conflicts
+func linkBackupIDs(results []*symlink.LinkResult) []string {
+	var ids []string
+	for _, result := range results {
+		if result.BackupID != "" {
+			ids = append(ids, result.BackupID)
+		}
+	}
+	return ids
+}
+
+// printLinkBackups lists the backups a link run created and the operation
+// that groups them, so they can be found again with backup list --operation
+// and restored together.
+func printLinkBackups(ids []string) {
+	if len(ids) == 0 {
+		return
+	}
+	fmt.Printf("Backups: %s\n", strings.Join(ids, ", "))
+	if op := backup.Operation(); op != "" {
+		fmt.Println(cli.Dim(fmt.Sprintf("List with: merlin backup list --operation %s", op)))
+		fmt.Println(cli.Dim(fmt.Sprintf("Undo with: merlin backup restore %s", op)))
+	}
+}
+
+// loadLaunchAgents activates plists linked by launchd = true links. launchctl
+// only exists on macOS; elsewhere the flag is noted and ignored.
+func loadLaunchAgents(tool *symlink.ToolConfig, dryRun bool) {
+	if len(symlink.LaunchAgents(tool)) == 0 {
+		return
+	}
+	if !system.IsMacOS() {
+		fmt.Println(cli.Dim("  launchd = true ignored: launchctl is only available on macOS"))
+		return
+	}
+	loaded, err := symlink.LoadLaunchAgents(tool, dryRun)
+	for _, agent := range loaded {
+		if dryRun {
+			fmt.Printf("  ↻ %s (would load with launchctl)\n", agent)
+		} else {
+			fmt.Printf("  ↻ %s (loaded)\n", agent)
+		}
+	}
+	if err != nil {
+		cli.Warning("loading launch agents: %v", err)
+	}
+}
+
+// warnedBinDirs holds the bin directories already reported missing from PATH
+var warnedBinDirs = map[string]bool{}
+
+// markExecutables makes the sources of [[bin]] links executable and warns,
+// once per run, when their bin directory is not on PATH
+func markExecutables(tool *symlink.ToolConfig, dryRun bool) {
+	changed, err := symlink.MarkExecutables(tool, dryRun)
+	for _, source := range changed {
+		if dryRun {
+			fmt.Printf("  ⚙ %s (would chmod +x)\n", source)
+		} else {
+			fmt.Printf("  ⚙ %s (chmod +x)\n", source)
+		}
+	}
+	if err != nil {
+		cli.Warning("marking executables: %v", err)
+	}
+	for _, dir := range symlink.BinDirs(tool) {
+		if !symlink.InPath(dir) && !warnedBinDirs[dir] {
+			warnedBinDirs[dir] = true
+			cli.Warning("%s is not on PATH; add it to your shell profile: export PATH=\"%s:$PATH\"", dir, dir)
+		}
+	}
+}
+
+// printPreview shows a dry-run result's content diff under its line, so a
+// planned overwrite can be reviewed before running it for real
+func printPreview(result *symlink.LinkResult) {
+	if result.Preview == "" {
+		return
+	}
+	fmt.Printf("    %s\n", result.Message)
+	fmt.Print(cli.Diff(result.Preview, "    "))
+}
+
+// checkLinkPrompts fails up front when --summary or --quiet would hide a
+// prompt linking needs: interactive conflict resolution, or --sudo asking
+// before escalating
+func checkLinkPrompts(strategy symlink.ConflictStrategy, dryRun bool) error {
+	if strategy == symlink.StrategyInteractive {
+		if err := cli.CheckPrompt("conflict prompts (use --strategy skip, backup or overwrite)"); err != nil {
+			return err
+		}
+	}
+	if linkSudo && !dryRun {
+		return cli.CheckPrompt("the sudo prompt (drop --sudo)")
+	}
+	return nil
+}
+
+// escalatePermissionDenied offers to retry EPERM/EACCES failures via sudo when --sudo is set.
+// Without --sudo the per-result message already carries remediation guidance.
+func escalatePermissionDenied(results []*symlink.LinkResult, dryRun bool) {
+	if !linkSudo || dryRun {
+		return
+	}
+	var denied []string
+	for _, r := range results {
+		if r.PermissionDenied {
+			denied = append(denied, fmt.Sprintf("%s → %s", r.Target, r.Source))
+		}
+	}
+	if len(denied) == 0 {
+		return
+	}
+	if err := cli.CheckPrompt("the sudo prompt"); err != nil {
+		cli.Error("%v; %d link(s) not created", err, len(denied))
+		return
+	}
+	fmt.Printf("\n🔐 %d link(s) need elevated permissions:\n", len(denied))
+	fmt.Print(cli.BulletList(denied))
+	fmt.Print("Create them with sudo? [y/N]: ")
+	var response string
+	fmt.Scanln(&response)
+	response = strings.ToLower(strings.TrimSpace(response))
+	if response != "y" && response != "yes" {
+		fmt.Println("Skipped sudo escalation.")
+		return
+	}
+	symlink.EscalateWithSudo(results)
 }
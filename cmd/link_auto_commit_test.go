@@ -4,81 +4,36 @@ import (
 	"bytes"
 	"io"
 	"os"
-	"os/exec"
-	"path/filepath"
+	"strings"
 	"testing"
-	"time"
-)
 
-// executeLink runs `merlin link` (single tool) inside isolated temp repo.
-func executeLinkSingle(t *testing.T, repo string, home string, tool string, autoCommit bool) (string, error) {
-	t.Helper()
-	os.Setenv("MERLIN_DOTFILES", repo)
-	os.Setenv("HOME", home)
-	writeRootConfig(t, repo, autoCommit)
-	ensureToolConfig(t, repo, tool)
-	initAndCommitRepo(t, repo)
-	return runMerlinCommand(t, repo, []string{"link", tool})
-}
+	"github.com/ildx/merlin/internal/installer/installertest"
+)
 
-// executeLinkAll runs `merlin link --all` inside isolated temp repo with provided tools.
-func executeLinkAll(t *testing.T, repo string, home string, tools []string, autoCommit bool) (string, error) {
+// newCommitSandbox creates a sandbox whose root merlin.toml sets auto_commit
+// and any extra settings, adds tools with one file each and commits it all
+func newCommitSandbox(t *testing.T, autoCommit bool, settings string, tools ...string) *installertest.Sandbox {
 	t.Helper()
-	os.Setenv("MERLIN_DOTFILES", repo)
-	os.Setenv("HOME", home)
-	writeRootConfig(t, repo, autoCommit)
+	s := installertest.NewSandbox(t)
+	s.WriteFile("merlin.toml", rootConfig(autoCommit)+settings)
 	for _, tool := range tools {
-		ensureToolConfig(t, repo, tool)
+		s.AddTool(tool, map[string]string{"sample.txt": "data"})
 	}
-	initAndCommitRepo(t, repo)
-	return runMerlinCommand(t, repo, []string{"link", "--all"})
+	s.InitGit()
+	return s
 }
 
-// writeRootConfig creates a minimal root merlin.toml with auto_commit flag.
-func writeRootConfig(t *testing.T, repo string, auto bool) {
-	t.Helper()
-	if err := os.MkdirAll(repo, 0755); err != nil {
-		t.Fatalf("mkdir repo: %v", err)
-	}
+// rootConfig is a minimal root merlin.toml with the auto_commit flag
+func rootConfig(autoCommit bool) string {
 	ac := "false"
-	if auto {
+	if autoCommit {
 		ac = "true"
 	}
-	root := []byte("metadata = { name = \"test\" }\n[settings]\nauto_commit = " + ac + "\nconflict_strategy = \"skip\"\n")
-	if err := os.WriteFile(filepath.Join(repo, "merlin.toml"), root, 0644); err != nil {
-		t.Fatalf("write root merlin.toml: %v", err)
-	}
-	if err := os.MkdirAll(filepath.Join(repo, "config"), 0755); err != nil {
-		t.Fatalf("mkdir config root: %v", err)
-	}
-}
-
-// ensureToolConfig sets up minimal tool config directory structure w/ config/<tool>/config + file.
-func ensureToolConfig(t *testing.T, repo, tool string) {
-	t.Helper()
-	dir := filepath.Join(repo, "config", tool, "config")
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		t.Fatalf("mkdir tool config: %v", err)
-	}
-	// create a sample file to link
-	sample := filepath.Join(dir, "sample.txt")
-	if err := os.WriteFile(sample, []byte("data"), 0644); err != nil {
-		t.Fatalf("write sample: %v", err)
-	}
-}
-
-// initAndCommitRepo initializes git repo & makes initial commit.
-func initAndCommitRepo(t *testing.T, repo string) {
-	t.Helper()
-	runGitOrFail(t, repo, "init")
-	runGitOrFail(t, repo, "config", "user.email", "tester@example.com")
-	runGitOrFail(t, repo, "config", "user.name", "Tester")
-	runGitOrFail(t, repo, "add", ".")
-	runGitOrFail(t, repo, "commit", "-m", "chore: init")
+	return "metadata = { name = \"test\" }\n[settings]\nauto_commit = " + ac + "\nconflict_strategy = \"skip\"\n"
 }
 
 // runMerlinCommand executes rootCmd with provided args capturing combined stdout+stderr.
-func runMerlinCommand(t *testing.T, repo string, args []string) (string, error) {
+func runMerlinCommand(t *testing.T, args []string) (string, error) {
 	t.Helper()
 	// Capture IO
 	rOut, wOut, _ := os.Pipe()
@@ -98,84 +53,111 @@ func runMerlinCommand(t *testing.T, repo string, args []string) (string, error)
 	return buf.String(), err
 }
 
-// git helpers
-func runGitOrFail(t *testing.T, repo string, args ...string) {
-	t.Helper()
-	full := append([]string{"-C", repo}, args...)
-	cmd := exec.Command("git", full...)
-	if err := cmd.Run(); err != nil {
-		t.Fatalf("git %v failed: %v", args, err)
-	}
-}
-
-func gitOutput(t *testing.T, repo string, args ...string) []byte {
-	t.Helper()
-	full := append([]string{"-C", repo}, args...)
-	cmd := exec.Command("git", full...)
-	out, err := cmd.Output()
-	if err != nil {
-		t.Fatalf("git %v output failed: %v", args, err)
-	}
-	return out
-}
-
 // Test auto-commit enabled for single tool
 func TestLinkAutoCommitSingleTool(t *testing.T) {
-	if _, err := exec.Command("git", "--version").Output(); err != nil {
-		t.Skip("git not available")
-	}
-	repo := t.TempDir()
-	home := t.TempDir()
-	out, err := executeLinkSingle(t, repo, home, "zsh", true)
+	s := newCommitSandbox(t, true, "", "zsh")
+	linkAll = false // flags persist on rootCmd between tests
+	out, err := runMerlinCommand(t, []string{"link", "zsh"})
 	if err != nil {
 		t.Fatalf("link command failed: %v\nOutput: %s", err, out)
 	}
-	// Wait briefly for commit
-	time.Sleep(50 * time.Millisecond)
-	msg := bytes.TrimSpace(gitOutput(t, repo, "log", "-1", "--pretty=%s"))
-	if !bytes.Equal(msg, []byte("chore(link): link zsh")) {
-		t.Fatalf("unexpected commit message: %s", string(msg))
+	if msg := s.LastCommitSubject(); msg != "chore(link): link zsh" {
+		t.Fatalf("unexpected commit message: %s", msg)
 	}
 	// Repo should be clean
-	status := gitOutput(t, repo, "status", "--porcelain")
-	if len(status) != 0 {
-		t.Fatalf("expected clean repo, status: %s", string(status))
+	if status := s.GitStatus(); status != "" {
+		t.Fatalf("expected clean repo, status: %s", status)
 	}
 }
 
 // Test auto-commit disabled (no new commit beyond init)
 func TestLinkAutoCommitDisabled(t *testing.T) {
-	if _, err := exec.Command("git", "--version").Output(); err != nil {
-		t.Skip("git not available")
-	}
-	repo := t.TempDir()
-	home := t.TempDir()
-	out, err := executeLinkSingle(t, repo, home, "zsh", false)
+	s := newCommitSandbox(t, false, "", "zsh")
+	linkAll = false
+	out, err := runMerlinCommand(t, []string{"link", "zsh"})
 	if err != nil {
 		t.Fatalf("link command failed: %v\nOutput: %s", err, out)
 	}
 	// Latest commit should still be init
-	msg := bytes.TrimSpace(gitOutput(t, repo, "log", "-1", "--pretty=%s"))
-	if !bytes.Equal(msg, []byte("chore: init")) {
-		t.Fatalf("unexpected commit message when disabled: %s", string(msg))
+	if msg := s.LastCommitSubject(); msg != "chore: init" {
+		t.Fatalf("unexpected commit message when disabled: %s", msg)
 	}
 }
 
 // Test multi-tool commit message formatting (>3 tools triggers ellipsis)
 func TestLinkAutoCommitMultiToolMessage(t *testing.T) {
-	if _, err := exec.Command("git", "--version").Output(); err != nil {
-		t.Skip("git not available")
-	}
-	repo := t.TempDir()
-	home := t.TempDir()
-	tools := []string{"zsh", "git", "eza", "brew", "mas"}
-	out, err := executeLinkAll(t, repo, home, tools, true)
+	s := newCommitSandbox(t, true, "", "zsh", "git", "eza", "brew", "mas")
+	out, err := runMerlinCommand(t, []string{"link", "--all"})
 	if err != nil {
 		t.Fatalf("link --all failed: %v\nOutput: %s", err, out)
 	}
-	time.Sleep(50 * time.Millisecond)
-	msg := string(bytes.TrimSpace(gitOutput(t, repo, "log", "-1", "--pretty=%s")))
-	if !bytes.HasPrefix([]byte(msg), []byte("chore(link): link 5 tools (")) || !bytes.Contains([]byte(msg), []byte("…)")) {
+	msg := s.LastCommitSubject()
+	if !strings.HasPrefix(msg, "chore(link): link 5 tools (") || !strings.Contains(msg, "…)") {
 		t.Fatalf("multi-tool message format mismatch: %s", msg)
 	}
 }
+
+// Test [settings.git] commit_template shapes the auto-commit message
+func TestLinkAutoCommitTemplate(t *testing.T) {
+	s := newCommitSandbox(t, true, "[settings.git]\ncommit_template = \"build(dotfiles): {op} {tools} [{count}]\"\n", "zsh")
+	linkAll = false
+	out, err := runMerlinCommand(t, []string{"link", "zsh"})
+	if err != nil {
+		t.Fatalf("link command failed: %v\nOutput: %s", err, out)
+	}
+	if msg := s.LastCommitSubject(); msg != "build(dotfiles): link zsh [1]" {
+		t.Fatalf("unexpected commit message: %s", msg)
+	}
+}
+
+// Test batch_window queues link auto-commits until merlin repo flush
+func TestLinkAutoCommitBatch(t *testing.T) {
+	s := newCommitSandbox(t, true, "[settings.git]\nbatch_window = \"1h\"\n", "zsh", "git")
+	linkAll = false
+	for _, tool := range []string{"zsh", "git"} {
+		if out, err := runMerlinCommand(t, []string{"link", tool}); err != nil {
+			t.Fatalf("link %s failed: %v\nOutput: %s", tool, err, out)
+		}
+	}
+	if msg := s.LastCommitSubject(); msg != "chore: init" {
+		t.Fatalf("link committed despite batch_window: %s", msg)
+	}
+
+	if out, err := runMerlinCommand(t, []string{"repo", "flush"}); err != nil {
+		t.Fatalf("repo flush failed: %v\nOutput: %s", err, out)
+	}
+	msg := s.LastCommit()
+	if !strings.HasPrefix(msg, "chore(link): 2 operations (link zsh; link git)") || strings.Count(msg, "Merlin-Action:") != 2 {
+		t.Fatalf("unexpected batch commit:\n%s", msg)
+	}
+}
+
+// Test unrelated changes block the auto-commit, are listed, and merlin repo
+// commit creates the skipped commit with the included path
+func TestLinkAutoCommitBlockedByUnrelatedChanges(t *testing.T) {
+	s := newCommitSandbox(t, true, "", "zsh")
+	s.WriteFile("notes.txt", "wip")
+	s.WriteFile("config/zsh/config/extra", "x")
+
+	linkAll = false
+	out, err := runMerlinCommand(t, []string{"link", "zsh"})
+	if err != nil {
+		t.Fatalf("link failed: %v\nOutput: %s", err, out)
+	}
+	if !strings.Contains(out, "?? notes.txt") || !strings.Contains(out, "--commit-anyway") {
+		t.Fatalf("expected blocking path and follow-ups, got:\n%s", out)
+	}
+	if msg := s.LastCommitSubject(); msg != "chore: init" {
+		t.Fatalf("link committed despite unrelated changes: %s", msg)
+	}
+
+	if out, err := runMerlinCommand(t, []string{"repo", "commit", "--include", "notes.txt"}); err != nil {
+		t.Fatalf("repo commit failed: %v\nOutput: %s", err, out)
+	}
+	if msg := s.LastCommitSubject(); msg != "chore(link): link zsh" {
+		t.Fatalf("unexpected commit: %s", msg)
+	}
+	if status := s.GitStatus(); status != "" {
+		t.Fatalf("expected clean repo, status: %s", status)
+	}
+}
@@ -6,10 +6,15 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
+	"github.com/ildx/merlin/internal/cli"
 	"github.com/ildx/merlin/internal/config"
+	"github.com/ildx/merlin/internal/logger"
 	"github.com/ildx/merlin/internal/models"
 	"github.com/ildx/merlin/internal/parser"
+	"github.com/ildx/merlin/internal/state"
+	"github.com/ildx/merlin/internal/symlink"
 	"github.com/ildx/merlin/internal/system"
 	"github.com/spf13/cobra"
 )
@@ -84,9 +89,15 @@ var listConfigsCmd = &cobra.Command{
 	Use:     "configs",
 	Aliases: []string{"tools"},
 	Short:   "List available config tools",
-	Long:    "List all available configuration tools in the dotfiles repository",
+	Long: `List all available configuration tools in the dotfiles repository.
+
+Each tool shows its link status as of the last merlin link or unlink:
+✓ linked, ◐ partly linked, ⚠ conflicts or ✗ unlinked. The status is cached
+in ~/.merlin/cache/link-state.json, so listing doesn't check every target;
+--refresh checks them all and updates the cache.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		if err := runListConfigs(); err != nil {
+		refresh, _ := cmd.Flags().GetBool("refresh")
+		if err := runListConfigs(refresh); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
@@ -116,6 +127,7 @@ func init() {
 	listBrewCmd.Flags().StringP("category", "c", "", "Filter by category")
 	listBrewCmd.Flags().Bool("formulae-only", false, "Show only formulae")
 	listBrewCmd.Flags().Bool("casks-only", false, "Show only casks")
+	listConfigsCmd.Flags().Bool("refresh", false, "Check every tool's links and update the cached link status")
 
 	listMASCmd.Flags().StringP("category", "c", "", "Filter by category")
 }
@@ -133,8 +145,7 @@ func runListAll(cmd *cobra.Command) error {
 	fmt.Printf("════════════════════════════════════════════════════════════════════════════════\n")
 
 	// List Homebrew packages
-	brewPath := filepath.Join(repo.GetToolConfigDir("brew"), "brew.toml")
-	if _, err := os.Stat(brewPath); err == nil {
+	if parser.HasBrewConfig(repo.GetToolConfigDir("brew")) {
 		if err := runListBrew(cmd); err != nil {
 			fmt.Fprintf(os.Stderr, "\n⚠️  Failed to list brew packages: %v\n", err)
 		}
@@ -157,7 +168,7 @@ func runListAll(cmd *cobra.Command) error {
 	}
 
 	// List config tools
-	if err := runListConfigs(); err != nil {
+	if err := runListConfigs(false); err != nil {
 		fmt.Fprintf(os.Stderr, "\n⚠️  Failed to list config tools: %v\n", err)
 	}
 
@@ -177,16 +188,10 @@ func runListBrew(cmd *cobra.Command) error {
 		return fmt.Errorf("dotfiles repository not found: %w", err)
 	}
 
-	// Find brew.toml
-	brewPath := filepath.Join(repo.GetToolConfigDir("brew"), "brew.toml")
-	if _, err := os.Stat(brewPath); os.IsNotExist(err) {
-		return fmt.Errorf("brew.toml not found at %s", brewPath)
-	}
-
-	// Parse brew.toml
-	brewConfig, err := parser.ParseBrewTOML(brewPath)
+	// Parse brew.toml and brew.d/*.toml
+	brewConfig, err := parser.ParseBrewConfig(repo.GetToolConfigDir("brew"))
 	if err != nil {
-		return fmt.Errorf("failed to parse brew.toml: %w", err)
+		return err
 	}
 
 	// Get filter flags
@@ -202,7 +207,7 @@ func runListBrew(cmd *cobra.Command) error {
 	if !casksOnly && len(brewConfig.Formulae) > 0 {
 		fmt.Printf("🔧 Formulae (%d)\n", len(brewConfig.Formulae))
 		fmt.Println(strings.Repeat("─", 80))
-		printBrewPackages(brewConfig.Formulae, categoryFilter)
+		printBrewPackages(brewConfig.Formulae, categoryFilter, noTruncate(cmd))
 		fmt.Println()
 	}
 
@@ -210,7 +215,7 @@ func runListBrew(cmd *cobra.Command) error {
 	if !formulaeOnly && len(brewConfig.Casks) > 0 {
 		fmt.Printf("📱 Casks (%d)\n", len(brewConfig.Casks))
 		fmt.Println(strings.Repeat("─", 80))
-		printBrewPackages(brewConfig.Casks, categoryFilter)
+		printBrewPackages(brewConfig.Casks, categoryFilter, noTruncate(cmd))
 		fmt.Println()
 	}
 
@@ -272,9 +277,16 @@ func runListMAS(cmd *cobra.Command) error {
 
 		fmt.Printf("%-30s [%d]\n", app.Name, app.ID)
 		if app.Description != "" {
-			fmt.Printf("  %s\n", app.Description)
+			desc := "  " + app.Description
+			if !noTruncate(cmd) {
+				desc = cli.TruncateEnd(desc, cli.TerminalWidth())
+			}
+			fmt.Println(desc)
 		}
 		fmt.Printf("  Category: %s\n", category)
+		if why := provenanceLine(app.Reason, app.AddedBy, app.AddedDate); why != "" {
+			fmt.Printf("  %s\n", why)
+		}
 		fmt.Println()
 	}
 
@@ -288,12 +300,19 @@ func runListMAS(cmd *cobra.Command) error {
 	return nil
 }
 
-func runListConfigs() error {
+func runListConfigs(refresh bool) error {
 	// Find dotfiles repository
 	repo, err := config.FindDotfilesRepo()
 	if err != nil {
 		return fmt.Errorf("dotfiles repository not found: %w", err)
 	}
+	if refresh {
+		if err := refreshLinkState(repo); err != nil {
+			return err
+		}
+	}
+	linkStates := state.LoadLinkStateCache()
+	unchecked := 0
 
 	// Get all tools
 	tools, err := repo.ListTools()
@@ -341,6 +360,9 @@ func runListConfigs() error {
 		if !hasConfigDir && !hasMerlinConfig {
 			status = "⚠"
 		}
+		if toolConfig != nil && !toolConfig.IsEnabled() {
+			status = "⊘"
+		}
 
 		fmt.Printf("%s %-20s", status, tool)
 
@@ -352,10 +374,21 @@ func runListConfigs() error {
 
 		// Print details
 		details := []string{}
+		if toolConfig == nil || toolConfig.IsEnabled() {
+			if s, ok := linkStates.Get(repo.GetToolRoot(tool)); ok {
+				details = append(details, linkStateLabel(s))
+			} else if hasConfigDir || (toolConfig != nil && toolConfig.HasLinks()) {
+				details = append(details, "link status unknown")
+				unchecked++
+			}
+		}
 		if hasMerlinConfig {
 			details = append(details, "has merlin.toml")
 
 			if toolConfig != nil {
+				if !toolConfig.IsEnabled() {
+					details = append(details, "disabled")
+				}
 				if toolConfig.HasLinks() {
 					details = append(details, fmt.Sprintf("%d link(s)", len(toolConfig.Links)))
 				}
@@ -377,9 +410,62 @@ func runListConfigs() error {
 		fmt.Println()
 	}
 
+	if unchecked > 0 {
+		fmt.Println(cli.Dim(fmt.Sprintf("%d tool(s) not linked or checked yet; check them with: merlin list configs --refresh", unchecked)))
+	}
 	return nil
 }
 
+// linkStateLabel renders a tool's cached link status
+func linkStateLabel(s state.ToolLinkState) string {
+	switch s.Status() {
+	case "linked":
+		return fmt.Sprintf("✓ linked (%d)", s.Links)
+	case "conflict":
+		return fmt.Sprintf("⚠ %d conflict(s), %d/%d linked", s.Conflicts, s.Linked, s.Links)
+	case "partial":
+		return fmt.Sprintf("◐ %d/%d linked", s.Linked, s.Links)
+	default:
+		return "✗ unlinked"
+	}
+}
+
+// refreshLinkState checks the links of every enabled tool and caches the
+// result
+func refreshLinkState(repo *config.DotfilesRepo) error {
+	rootConfig, err := parser.ParseRootMerlinTOML(repo.GetRootMerlinConfig())
+	if err != nil {
+		return fmt.Errorf("failed to parse root config: %w", err)
+	}
+	vars, err := symlink.GetVariablesFromRoot(rootConfig)
+	if err != nil {
+		return fmt.Errorf("failed to get variables: %w", err)
+	}
+	tools, err := symlink.DiscoverTools(repo, vars)
+	if err != nil {
+		return fmt.Errorf("discovering tools: %w", err)
+	}
+	recordLinkState(withLinks(tools), false)
+	return nil
+}
+
+// recordLinkState checks the links of tools and caches the result for
+// merlin list configs; dry runs change nothing, so they record nothing
+func recordLinkState(tools []*symlink.ToolConfig, dryRun bool) {
+	if dryRun || len(tools) == 0 {
+		return
+	}
+	cache := state.LoadLinkStateCache()
+	now := time.Now()
+	for _, tool := range tools {
+		total, linked, conflicts := symlink.CountLinks(tool)
+		cache.Set(tool.ToolRoot, state.ToolLinkState{Links: total, Linked: linked, Conflicts: conflicts, CheckedAt: now})
+	}
+	if err := cache.Save(); err != nil {
+		logger.Debug("link state not cached", "error", err)
+	}
+}
+
 func runListProfiles() error {
 	// Find dotfiles repository
 	repo, err := config.FindDotfilesRepo()
@@ -441,7 +527,7 @@ func runListProfiles() error {
 	return nil
 }
 
-func printBrewPackages(packages []models.BrewPackage, categoryFilter string) {
+func printBrewPackages(packages []models.BrewPackage, categoryFilter string, wide bool) {
 	// Group packages by category
 	byCategory := make(map[string][]models.BrewPackage)
 	for _, pkg := range packages {
@@ -486,11 +572,44 @@ func printBrewPackages(packages []models.BrewPackage, categoryFilter string) {
 		})
 
 		for _, pkg := range packages {
-			fmt.Printf("  • %-30s", pkg.Name)
+			line := fmt.Sprintf("  • %-30s", pkg.Name)
 			if pkg.Description != "" {
-				fmt.Printf(" - %s", pkg.Description)
+				line += " - " + pkg.Description
+			}
+			if !wide {
+				line = cli.TruncateEnd(line, cli.TerminalWidth())
+			}
+			fmt.Println(line)
+			if why := provenanceLine(pkg.Reason, pkg.AddedBy, pkg.AddedDate); why != "" {
+				if !wide {
+					why = cli.TruncateEnd(why, cli.TerminalWidth()-6)
+				}
+				fmt.Println("      " + cli.Dim(why))
 			}
-			fmt.Println()
 		}
 	}
 }
+
+// provenanceLine describes why and when a package was declared, e.g.
+// "Why: thumbnails for the blog (added by merlin audit brew on 2024-03-01)";
+// empty when nothing is recorded
+func provenanceLine(reason, addedBy string, addedDate models.Date) string {
+	var added string
+	switch {
+	case addedBy != "" && addedDate != "":
+		added = fmt.Sprintf("added by %s on %s", addedBy, addedDate)
+	case addedBy != "":
+		added = "added by " + addedBy
+	case addedDate != "":
+		added = fmt.Sprintf("added on %s", addedDate)
+	}
+	switch {
+	case reason != "" && added != "":
+		return fmt.Sprintf("Why: %s (%s)", reason, added)
+	case reason != "":
+		return "Why: " + reason
+	case added != "":
+		return strings.ToUpper(added[:1]) + added[1:]
+	}
+	return ""
+}
@@ -0,0 +1,322 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ildx/merlin/internal/cli"
+	"github.com/ildx/merlin/internal/config"
+	"github.com/ildx/merlin/internal/machine"
+	"github.com/ildx/merlin/internal/parser"
+	"github.com/ildx/merlin/internal/state"
+	"github.com/spf13/cobra"
+)
+
+var machineCmd = &cobra.Command{
+	Use:   "machine",
+	Short: "Track which machines use this repository",
+	Long: `Keep an inventory of the machines using this dotfiles repository.
+
+BEHAVIOR
+	Machines are recorded in .merlin-meta/machines.toml inside the repository
+	(hostname, OS, arch, profile and last sync), which is committed so every
+	machine sees the others. A registered machine's last sync is updated by
+	each merlin link and merlin install run on it.
+
+	publish records this machine's installed packages and links in
+	.merlin-meta/machines/<name>.json, which 'merlin diff --machine <name>'
+	on another machine compares against.
+
+SUBCOMMANDS
+	register          Record this machine, or refresh its entry
+	list              Show registered machines and how stale each is (default)
+	publish           Publish this machine's package and link snapshot
+	forget <name>     Remove a machine (and its snapshot) that no longer uses
+	                  the repository
+
+FLAGS
+	register --profile <name>   Profile this machine uses (default: the one
+	                            matching its hostname, then the default one)
+	list --stale-days <n>       Mark machines not synced for n days (default 30)
+	list --json                 Print the inventory as JSON
+
+EXAMPLES
+	merlin machine register
+	merlin machine register --profile work
+	merlin machine list
+	merlin machine publish
+	merlin machine forget old-mbp`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runMachineList(cmd); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var machineRegisterCmd = &cobra.Command{
+	Use:   "register",
+	Short: "Record this machine in the repository's inventory",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runMachineRegister(cmd); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var machineListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the machines using this repository",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runMachineList(cmd); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var machinePublishCmd = &cobra.Command{
+	Use:   "publish",
+	Short: "Publish this machine's packages and links for diff --machine",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runMachinePublish(cmd); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var machineForgetCmd = &cobra.Command{
+	Use:   "forget <name>",
+	Short: "Remove a machine from the inventory",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runMachineForget(cmd, args[0]); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(machineCmd)
+	machineCmd.AddCommand(machineRegisterCmd)
+	machineCmd.AddCommand(machineListCmd)
+	machineCmd.AddCommand(machinePublishCmd)
+	machineCmd.AddCommand(machineForgetCmd)
+	machineRegisterCmd.Flags().String("profile", "", "Profile this machine uses")
+	for _, c := range []*cobra.Command{machineCmd, machineListCmd} {
+		c.Flags().Int("stale-days", 30, "Mark machines not synced for this many days")
+		c.Flags().Bool("json", false, "Print the inventory as JSON")
+	}
+}
+
+func runMachineRegister(cmd *cobra.Command) error {
+	profile, _ := cmd.Flags().GetString("profile")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	repo, err := config.FindDotfilesRepo()
+	if err != nil {
+		return fmt.Errorf("dotfiles repository not found: %w", err)
+	}
+	rootConfig, err := parser.ParseRootMerlinTOML(repo.GetRootMerlinConfig())
+	if err != nil {
+		return fmt.Errorf("error parsing root config: %w", err)
+	}
+	if profile != "" && rootConfig.GetProfileByName(profile) == nil {
+		return fmt.Errorf("profile '%s' not found", profile)
+	}
+
+	current, err := machine.Current(rootConfig, profile)
+	if err != nil {
+		return err
+	}
+	inv, err := machine.Load(repo.Root)
+	if err != nil {
+		return err
+	}
+	added := inv.Register(current, time.Now())
+
+	verb := "Updated"
+	if added {
+		verb = "Registered"
+	}
+	if dryRun {
+		fmt.Printf("🔍 Would record %s (%s/%s, profile %s) in %s\n", current.Name, current.OS, current.Arch, orNone(current.Profile), machine.InventoryFile)
+		return nil
+	}
+	if err := inv.Save(repo.Root); err != nil {
+		return fmt.Errorf("failed to save %s: %w", machine.InventoryFile, err)
+	}
+	cli.Success("%s %s (%s/%s, profile %s)", verb, current.Name, current.OS, current.Arch, orNone(current.Profile))
+	fmt.Printf("Commit %s to share it with your other machines.\n", machine.InventoryFile)
+	return nil
+}
+
+func runMachineList(cmd *cobra.Command) error {
+	staleDays, _ := cmd.Flags().GetInt("stale-days")
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	repo, err := config.FindDotfilesRepo()
+	if err != nil {
+		return fmt.Errorf("dotfiles repository not found: %w", err)
+	}
+	inv, err := machine.Load(repo.Root)
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if inv.Machines == nil {
+			inv.Machines = []machine.Machine{}
+		}
+		return enc.Encode(inv.Machines)
+	}
+
+	if len(inv.Machines) == 0 {
+		cli.Info("No machines registered; run 'merlin machine register' on each machine")
+		return nil
+	}
+
+	hostname, _ := os.Hostname()
+	here := machine.ShortName(hostname)
+	now := time.Now()
+	stale := 0
+	table := newTable(cmd, "MACHINE", "PROFILE", "PLATFORM", "LAST SYNC", "REGISTERED")
+	for _, m := range inv.Machines {
+		name := m.Name
+		if name == here {
+			name += " (this machine)"
+		}
+		age := now.Sub(m.LastSync)
+		lastSync := formatSince(age)
+		if staleDays > 0 && age > time.Duration(staleDays)*24*time.Hour {
+			lastSync += " ⚠️ stale"
+			stale++
+		}
+		table.AddRow(name, orNone(m.Profile), m.OS+"/"+m.Arch, lastSync, m.Registered.Format("2006-01-02"))
+	}
+
+	fmt.Printf("\n🖥  Machines using %s\n\n", repo.Root)
+	table.Render(os.Stdout)
+	if stale > 0 {
+		fmt.Printf("\n%d machine(s) not synced for over %d days; 'merlin machine forget <name>' removes retired ones.\n", stale, staleDays)
+	}
+	return nil
+}
+
+func runMachinePublish(cmd *cobra.Command) error {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	repo, err := config.FindDotfilesRepo()
+	if err != nil {
+		return fmt.Errorf("dotfiles repository not found: %w", err)
+	}
+	if offlineMode(cmd) {
+		return fmt.Errorf("publishing needs the installed package lists; run it without --offline")
+	}
+
+	snap, err := currentMachineSnapshot(repo)
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		fmt.Printf("🔍 Would publish %d formulae, %d casks, %d apps, %d extensions and %d links to %s\n",
+			len(snap.Formulae), len(snap.Casks), len(snap.MASApps), len(snap.Extensions), len(snap.Links), repo.Rel(machine.SnapshotPath(repo.Root, snap.Machine)))
+		return nil
+	}
+	if err := machine.SaveSnapshot(repo.Root, snap); err != nil {
+		return fmt.Errorf("failed to publish snapshot: %w", err)
+	}
+	cli.Success("Published %s: %d formulae, %d casks, %d apps, %d extensions, %d links",
+		snap.Machine, len(snap.Formulae), len(snap.Casks), len(snap.MASApps), len(snap.Extensions), len(snap.Links))
+	fmt.Printf("Commit %s so other machines can compare with 'merlin diff --machine %s'.\n", machine.SnapshotDir, snap.Machine)
+	return nil
+}
+
+// currentMachineSnapshot collects this machine's state in its published form
+func currentMachineSnapshot(repo *config.DotfilesRepo) (*machine.Snapshot, error) {
+	return machineSnapshotFrom(repo, state.CollectSnapshot(repo.Root))
+}
+
+// machineSnapshotFrom names snap after this machine and summarizes it as
+// machine publish would
+func machineSnapshotFrom(repo *config.DotfilesRepo, snap *state.SystemSnapshot) (*machine.Snapshot, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hostname: %w", err)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("get home directory: %w", err)
+	}
+	return machine.NewSnapshot(machine.ShortName(hostname), snap, repo.Root, home, time.Now()), nil
+}
+
+func runMachineForget(cmd *cobra.Command, name string) error {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	repo, err := config.FindDotfilesRepo()
+	if err != nil {
+		return fmt.Errorf("dotfiles repository not found: %w", err)
+	}
+	inv, err := machine.Load(repo.Root)
+	if err != nil {
+		return err
+	}
+	if !inv.Remove(name) {
+		return fmt.Errorf("machine '%s' is not registered (see 'merlin machine list')", name)
+	}
+	if dryRun {
+		fmt.Printf("🔍 Would remove %s from %s\n", name, machine.InventoryFile)
+		return nil
+	}
+	if err := inv.Save(repo.Root); err != nil {
+		return fmt.Errorf("failed to save %s: %w", machine.InventoryFile, err)
+	}
+	if err := os.Remove(machine.SnapshotPath(repo.Root, name)); err != nil && !os.IsNotExist(err) {
+		cli.Warning("snapshot of %s not removed: %v", name, err)
+	}
+	cli.Success("Removed %s", name)
+	return nil
+}
+
+// recordMachineSync stamps this machine's last sync in the repository's
+// inventory after a link or install run, if the machine is registered.
+// Dry runs don't count; failures only matter to machine list.
+func recordMachineSync(cmd *cobra.Command, repo *config.DotfilesRepo) {
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		return
+	}
+	if _, err := machine.TouchCurrent(repo.Root, time.Now()); err != nil {
+		cli.Warning("machine inventory not updated: %v", err)
+	}
+}
+
+// formatSince renders how long ago something happened in its largest unit
+func formatSince(age time.Duration) string {
+	switch {
+	case age < time.Minute:
+		return "just now"
+	case age < time.Hour:
+		return fmt.Sprintf("%dm ago", int(age.Minutes()))
+	case age < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(age.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(age.Hours()/24))
+	}
+}
+
+// orNone returns s, or "-" when it is empty
+func orNone(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
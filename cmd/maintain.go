@@ -0,0 +1,404 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ildx/merlin/internal/backup"
+	"github.com/ildx/merlin/internal/cli"
+	"github.com/ildx/merlin/internal/config"
+	"github.com/ildx/merlin/internal/diff"
+	"github.com/ildx/merlin/internal/git"
+	"github.com/ildx/merlin/internal/installer"
+	"github.com/ildx/merlin/internal/logger"
+	"github.com/ildx/merlin/internal/machine"
+	"github.com/ildx/merlin/internal/models"
+	"github.com/ildx/merlin/internal/notify"
+	"github.com/ildx/merlin/internal/parser"
+	"github.com/ildx/merlin/internal/progress"
+	"github.com/ildx/merlin/internal/state"
+	"github.com/ildx/merlin/internal/system"
+	"github.com/spf13/cobra"
+)
+
+var maintainCmd = &cobra.Command{
+	Use:   "maintain",
+	Short: "Run routine upkeep: upgrades, cleanup, backup pruning, snapshot, sync",
+	Long: `Run the routine upkeep of a machine in one go, e.g. weekly from a scheduler.
+
+STEPS
+	pull           git pull --rebase the dotfiles repository
+	brew-upgrade   brew update, then upgrade the outdated packages brew.toml
+	               declares (undeclared packages are left alone)
+	brew-cleanup   brew autoremove and brew cleanup
+	backup-prune   Delete backups beyond [maintenance] backup_keep or older
+	               than backup_max_age days
+	snapshot       Publish this machine's snapshot (as 'merlin machine publish')
+	diff           Report drift between the repository and this machine
+	push           Commit the published snapshot and push unpushed commits
+
+BEHAVIOR
+	Runs the steps listed in [maintenance] steps of the root merlin.toml, in
+	that order, or every step above when it is unset. A failing step doesn't
+	stop the others; the summary lists each step's outcome and the command
+	exits 1 when any failed. Steps that need the network are skipped offline,
+	pull and push are skipped without an upstream branch, and backup-prune is
+	skipped until backup_keep or backup_max_age is set. Nothing prompts, and
+	a [settings.notify] webhook gets the summary.
+
+FLAGS
+	--steps <a,b>   Run these steps instead of the configured ones
+	--skip <a,b>    Skip these steps
+	--dry-run       Show what each step would do
+
+EXAMPLES
+	merlin maintain
+	merlin maintain --steps brew-upgrade,brew-cleanup
+	merlin maintain --skip push --dry-run
+
+	# merlin.toml
+	[maintenance]
+	steps = ["pull", "brew-upgrade", "backup-prune", "diff"]
+	backup_keep = 20
+	backup_max_age = 90`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runMaintain(cmd); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(maintainCmd)
+	maintainCmd.Flags().StringSlice("steps", nil, "Run these steps instead of the configured ones")
+	maintainCmd.Flags().StringSlice("skip", nil, "Skip these steps")
+}
+
+// maintenance is what the maintain steps share
+type maintenance struct {
+	cmd       *cobra.Command
+	repo      *config.DotfilesRepo
+	root      *models.RootMerlinConfig
+	dryRun    bool
+	offline   bool
+	output    io.Writer // subprocess output, shown with -vv
+	snap      *state.SystemSnapshot
+	published string // snapshot path written by the snapshot step, relative to the repository
+}
+
+// stepSkipped is returned by steps that didn't apply, with the reason
+type stepSkipped string
+
+func (s stepSkipped) Error() string { return string(s) }
+
+// maintenanceSteps run each step in models.MaintenanceSteps and return a
+// one-line outcome
+var maintenanceSteps = map[string]func(*maintenance) (string, error){
+	"pull":         (*maintenance).pull,
+	"brew-upgrade": (*maintenance).brewUpgrade,
+	"brew-cleanup": (*maintenance).brewCleanup,
+	"backup-prune": (*maintenance).backupPrune,
+	"snapshot":     (*maintenance).snapshot,
+	"diff":         (*maintenance).diff,
+	"push":         (*maintenance).push,
+}
+
+func runMaintain(cmd *cobra.Command) error {
+	repo, err := config.FindDotfilesRepo()
+	if err != nil {
+		return fmt.Errorf("dotfiles repository not found: %w", err)
+	}
+	rootConfig, err := parser.ParseRootMerlinTOML(repo.GetRootMerlinConfig())
+	if err != nil {
+		return fmt.Errorf("parse root config: %w", err)
+	}
+
+	steps := rootConfig.Maintenance.EnabledSteps()
+	if only, _ := cmd.Flags().GetStringSlice("steps"); len(only) > 0 {
+		steps = only
+	}
+	skip, _ := cmd.Flags().GetStringSlice("skip")
+	for _, step := range append(slices.Clone(steps), skip...) {
+		if _, ok := maintenanceSteps[step]; !ok {
+			return fmt.Errorf("unknown maintenance step %q (use %s)", step, strings.Join(models.MaintenanceSteps, ", "))
+		}
+	}
+
+	m := &maintenance{cmd: cmd, repo: repo, root: rootConfig, offline: offlineMode(cmd), output: io.Discard}
+	m.dryRun, _ = cmd.Flags().GetBool("dry-run")
+	if verbosityLevel(cmd).Stream() {
+		m.output = os.Stdout
+	}
+
+	table := newTable(cmd, "STEP", "RESULT").Fixed(0)
+	succeeded := 0
+	var failed []string
+	steps = slices.DeleteFunc(slices.Clone(steps), func(step string) bool { return slices.Contains(skip, step) })
+	phase := progress.Start("maintain", len(steps))
+	for _, step := range steps {
+		fmt.Printf("▶ %s\n", step)
+		phase.Begin(step)
+		result, err := maintenanceSteps[step](m)
+		var skipped stepSkipped
+		switch {
+		case errors.As(err, &skipped):
+			fmt.Println(cli.Dim("  skipped: " + skipped.Error()))
+			table.AddRow(step, cli.Dim("– skipped: "+skipped.Error()))
+			phase.Finish(step, progress.StatusSkipped, skipped.Error())
+		case err != nil:
+			cli.Warning("%s failed: %v", step, err)
+			table.AddRow(step, "✗ "+firstLine(err.Error()))
+			failed = append(failed, step)
+			phase.Finish(step, progress.StatusFailed, firstLine(err.Error()))
+		default:
+			fmt.Printf("  %s\n", result)
+			table.AddRow(step, "✓ "+result)
+			succeeded++
+			phase.Finish(step, progress.StatusDone, result)
+		}
+	}
+	phase.End()
+
+	fmt.Println()
+	table.Render(os.Stdout)
+	notifyWebhook(cmd, repo, notify.NewSummary("maintain", succeeded, failed))
+	if len(failed) > 0 {
+		return fmt.Errorf("maintenance finished with %d failed step(s): %s", len(failed), strings.Join(failed, ", "))
+	}
+	fmt.Println()
+	cli.Success("Maintenance complete")
+	return nil
+}
+
+// firstLine returns s up to its first newline
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
+
+// systemSnapshot collects the installed packages and links once for the
+// steps needing them; offline it reuses the package state diff cached
+func (m *maintenance) systemSnapshot() *state.SystemSnapshot {
+	if m.snap != nil {
+		return m.snap
+	}
+	if m.offline {
+		m.snap, _ = state.CollectOfflineSnapshot(m.repo.Root)
+		return m.snap
+	}
+	m.snap = state.CollectSnapshot(m.repo.Root)
+	if err := state.SavePackageCache(m.snap); err != nil {
+		logger.Debug("package cache not saved", "error", err)
+	}
+	return m.snap
+}
+
+// gitRepo opens the dotfiles repository for pull and push, or explains why
+// the step doesn't apply
+func (m *maintenance) gitRepo() (*git.Repo, string, error) {
+	if m.offline {
+		return nil, "", stepSkipped("offline")
+	}
+	if !git.IsGitAvailable() {
+		return nil, "", stepSkipped("git is not installed")
+	}
+	repo, err := git.Open(m.repo.Root)
+	if err != nil {
+		return nil, "", stepSkipped("the dotfiles repository is not a git repository")
+	}
+	upstream := repo.Upstream()
+	if upstream == "" {
+		return nil, "", stepSkipped("the current branch has no upstream")
+	}
+	return repo, upstream, nil
+}
+
+func (m *maintenance) pull() (string, error) {
+	repo, upstream, err := m.gitRepo()
+	if err != nil {
+		return "", err
+	}
+	if m.dryRun {
+		return "would pull from " + upstream, nil
+	}
+	if err := repo.Pull(m.output); err != nil {
+		return "", err
+	}
+	return "pulled from " + upstream, nil
+}
+
+func (m *maintenance) brewUpgrade() (string, error) {
+	if m.offline {
+		return "", stepSkipped("offline")
+	}
+	if check := system.CheckHomebrew(); !check.Exists {
+		return "", stepSkipped("Homebrew is not installed")
+	}
+	brewConfig, err := parser.ParseBrewConfig(m.repo.GetToolConfigDir("brew"))
+	if err != nil {
+		return "", err
+	}
+	brewConfig = brewConfig.ForHost(machine.CurrentHost(m.repo))
+
+	if !m.dryRun {
+		if err := installer.BrewUpdate(m.output != io.Discard, m.output); err != nil {
+			return "", err
+		}
+	}
+	formulae, casks, err := installer.OutdatedDeclared(brewConfig)
+	if err != nil {
+		return "", err
+	}
+	outdated := append(slices.Clone(formulae), casks...)
+	switch {
+	case len(outdated) == 0:
+		return "declared packages are up to date", nil
+	case m.dryRun:
+		return fmt.Sprintf("would upgrade %d: %s", len(outdated), strings.Join(outdated, ", ")), nil
+	}
+	if err := installer.UpgradeBrew(formulae, casks, m.output != io.Discard, m.output); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("upgraded %d: %s", len(outdated), strings.Join(outdated, ", ")), nil
+}
+
+func (m *maintenance) brewCleanup() (string, error) {
+	if check := system.CheckHomebrew(); !check.Exists {
+		return "", stepSkipped("Homebrew is not installed")
+	}
+	plan, err := installer.PlanBrewCleanup(nil, false)
+	if err != nil {
+		return "", err
+	}
+	if m.dryRun {
+		return fmt.Sprintf("would remove %d unneeded dependencies and free %s", len(plan.Autoremove), cli.FormatBytes(plan.Reclaimable)), nil
+	}
+	freed, err := installer.ApplyBrewCleanup(plan, m.output != io.Discard, m.output)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("removed %d unneeded dependencies, freed %s", len(plan.Autoremove), cli.FormatBytes(freed)), nil
+}
+
+func (m *maintenance) backupPrune() (string, error) {
+	settings := m.root.Maintenance
+	if settings.BackupKeep <= 0 && settings.BackupMaxAge <= 0 {
+		return "", stepSkipped("set [maintenance] backup_keep or backup_max_age")
+	}
+	backups, err := backup.ListBackups()
+	if err != nil {
+		return "", fmt.Errorf("list backups: %w", err)
+	}
+	toDelete := backupsToPrune(backups, settings.BackupKeep, settings.BackupMaxAge, time.Now())
+	switch {
+	case len(toDelete) == 0:
+		return fmt.Sprintf("nothing to prune (%d backups)", len(backups)), nil
+	case m.dryRun:
+		return fmt.Sprintf("would delete %d of %d backups", len(toDelete), len(backups)), nil
+	}
+	var failed []string
+	for _, b := range toDelete {
+		if err := backup.DeleteBackup(b.ID); err != nil {
+			failed = append(failed, b.ID)
+		}
+	}
+	if len(failed) > 0 {
+		return "", fmt.Errorf("could not delete %s", strings.Join(failed, ", "))
+	}
+	return fmt.Sprintf("deleted %d of %d backups", len(toDelete), len(backups)), nil
+}
+
+func (m *maintenance) snapshot() (string, error) {
+	if m.offline {
+		return "", stepSkipped("offline (publishing needs the installed package lists)")
+	}
+	snap, err := machineSnapshotFrom(m.repo, m.systemSnapshot())
+	if err != nil {
+		return "", err
+	}
+	path := m.repo.Rel(machine.SnapshotPath(m.repo.Root, snap.Machine))
+	if m.dryRun {
+		return "would publish " + path, nil
+	}
+	if err := machine.SaveSnapshot(m.repo.Root, snap); err != nil {
+		return "", fmt.Errorf("publish snapshot: %w", err)
+	}
+	m.published = path
+	return fmt.Sprintf("published %s (%d formulae, %d casks, %d links)", path, len(snap.Formulae), len(snap.Casks), len(snap.Links)), nil
+}
+
+func (m *maintenance) diff() (string, error) {
+	result, err := diff.Compute(m.cmd.Context(), m.repo, m.systemSnapshot())
+	if err != nil {
+		return "", fmt.Errorf("compute diff: %w", err)
+	}
+	drift := result.Drift(m.repo.Root)
+	if err := state.SaveDrift(drift); err != nil {
+		logger.Debug("drift summary not saved", "error", err)
+	}
+	if drift.Total() == 0 {
+		return "in sync", nil
+	}
+	fmt.Println(result.HumanReadable(true, true, true))
+	return fmt.Sprintf("%d missing, %d broken, %d extra (see 'merlin diff')", drift.Missing, drift.Broken, drift.Extra), nil
+}
+
+func (m *maintenance) push() (string, error) {
+	repo, upstream, err := m.gitRepo()
+	if err != nil {
+		return "", err
+	}
+
+	committed := false
+	if m.published != "" {
+		if committed, err = m.commitSnapshot(repo); err != nil {
+			return "", err
+		}
+	}
+	ahead, err := repo.Ahead()
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case m.dryRun && ahead > 0:
+		return fmt.Sprintf("would push %d commit(s) to %s", ahead, upstream), nil
+	case ahead == 0:
+		return "nothing to push", nil
+	}
+	if err := repo.Push(m.output); err != nil {
+		return "", err
+	}
+	result := fmt.Sprintf("pushed %d commit(s) to %s", ahead, upstream)
+	if committed {
+		result += ", including the snapshot"
+	}
+	return result, nil
+}
+
+// commitSnapshot commits the snapshot the snapshot step published, when it
+// changed and nothing else is staged
+func (m *maintenance) commitSnapshot(repo *git.Repo) (bool, error) {
+	status, err := repo.Status()
+	if err != nil {
+		return false, err
+	}
+	if !slices.Contains(status.Unstaged, m.published) && !slices.Contains(status.Untracked, m.published) {
+		return false, nil
+	}
+	if len(status.Staged) > 0 {
+		cli.Warning("Not committing %s: other changes are staged", m.published)
+		return false, nil
+	}
+	info := git.CommitInfo{Op: "maintain", Summary: "publish " + m.published, Count: 1, Date: time.Now()}
+	if err := repo.Commit(git.CommitMessage(m.root.Settings.Git.CommitTemplate, info), []string{m.published}); err != nil {
+		return false, fmt.Errorf("commit snapshot: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ildx/merlin/internal/backup"
+	"github.com/ildx/merlin/internal/models"
+)
+
+func TestBackupsToPrune(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	var backups []*backup.BackupManifest
+	for i, days := range []int{1, 10, 40, 100} {
+		backups = append(backups, &backup.BackupManifest{ID: string(rune('a' + i)), Timestamp: now.AddDate(0, 0, -days)})
+	}
+	ids := func(list []*backup.BackupManifest) string {
+		var s string
+		for _, b := range list {
+			s += b.ID
+		}
+		return s
+	}
+
+	cases := []struct {
+		keep, olderThan int
+		want            string
+	}{
+		{0, 0, ""},
+		{3, 0, "d"},
+		{0, 30, "cd"},
+		{1, 30, "cd"}, // both: beyond the newest and older than 30 days
+		{3, 30, "d"},
+		{1, 365, ""},
+		{10, 365, ""},
+	}
+	for _, c := range cases {
+		if got := ids(backupsToPrune(backups, c.keep, c.olderThan, now)); got != c.want {
+			t.Errorf("backupsToPrune(keep=%d, older=%d) = %q, want %q", c.keep, c.olderThan, got, c.want)
+		}
+	}
+}
+
+func TestMaintenanceSkipsUnconfiguredPrune(t *testing.T) {
+	m := &maintenance{root: &models.RootMerlinConfig{}}
+	var skipped stepSkipped
+	if _, err := m.backupPrune(); !errors.As(err, &skipped) {
+		t.Errorf("backupPrune() = %v, want skipped without backup_keep or backup_max_age", err)
+	}
+	for _, step := range models.MaintenanceSteps {
+		if maintenanceSteps[step] == nil {
+			t.Errorf("step %q has no implementation", step)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ildx/merlin/internal/cli"
+	"github.com/ildx/merlin/internal/config"
+	"github.com/ildx/merlin/internal/migrate"
+	"github.com/spf13/cobra"
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Convert an existing dotfiles setup to merlin's layout",
+	Long: `Analyze dotfiles managed another way and generate config/<tool>/config
+directories plus merlin.toml link declarations.
+
+SUBCOMMANDS
+	stow <dir>          GNU stow directory (one package per tool)
+	bare <repo>         Bare git repository whose work tree is $HOME
+	chezmoi [source]    chezmoi source state (default: ~/.local/share/chezmoi)
+	dotbot <config>     dotbot install.conf.yaml / .json
+
+BEHAVIOR
+	Files are copied, never moved: your current setup keeps working until you
+	remove the old links and run 'merlin link --all'. Tools that already exist
+	in the output repository are left untouched. Anything that cannot be mapped
+	(symlinks, submodules, templates, encrypted files, shell commands, ignore
+	rules, missing files) is listed at the end.
+
+FLAGS
+	--output <dir>  Repository to write into (default: current dotfiles repo,
+	                otherwise the current directory)
+	--home <dir>    Home directory for bare work trees and dotbot targets
+	                (default: your home)
+	--dry-run       Show the generated layout without writing
+	--verbose,-v    Print each generated merlin.toml
+
+EXAMPLES
+	merlin migrate stow ~/dotfiles --output ~/merlin-dotfiles --dry-run
+	merlin migrate bare ~/.cfg --output ~/merlin-dotfiles
+	merlin migrate chezmoi --dry-run
+	merlin migrate dotbot ~/dotfiles/install.conf.yaml`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+var migrateStowCmd = &cobra.Command{
+	Use:   "stow <dir>",
+	Short: "Generate tools from a GNU stow directory",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runMigrate(cmd, func() (*migrate.Plan, error) {
+			return migrate.AnalyzeStow(args[0])
+		}); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var migrateBareCmd = &cobra.Command{
+	Use:   "bare <repo>",
+	Short: "Generate tools from a bare git repository tracking $HOME",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runMigrate(cmd, func() (*migrate.Plan, error) {
+			return migrate.AnalyzeBare(args[0], migrateHomeDir(cmd))
+		}); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var migrateChezmoiCmd = &cobra.Command{
+	Use:   "chezmoi [source]",
+	Short: "Generate tools from a chezmoi source directory",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runMigrate(cmd, func() (*migrate.Plan, error) {
+			if len(args) == 1 {
+				return migrate.AnalyzeChezmoi(args[0])
+			}
+			source, err := migrate.DefaultChezmoiSource()
+			if err != nil {
+				return nil, err
+			}
+			return migrate.AnalyzeChezmoi(source)
+		}); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var migrateDotbotCmd = &cobra.Command{
+	Use:   "dotbot <install.conf.yaml>",
+	Short: "Generate tools from a dotbot config",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runMigrate(cmd, func() (*migrate.Plan, error) {
+			return migrate.AnalyzeDotbot(args[0], migrateHomeDir(cmd))
+		}); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+	migrateCmd.AddCommand(migrateStowCmd)
+	migrateCmd.AddCommand(migrateBareCmd)
+	migrateCmd.AddCommand(migrateChezmoiCmd)
+	migrateCmd.AddCommand(migrateDotbotCmd)
+
+	migrateCmd.PersistentFlags().String("output", "", "Repository to write into (default: current dotfiles repo or .)")
+	migrateCmd.PersistentFlags().String("home", "", "Home directory for bare work trees and dotbot targets")
+}
+
+// migrateHomeDir resolves --home, defaulting to the user's home directory
+func migrateHomeDir(cmd *cobra.Command) string {
+	if home, _ := cmd.Flags().GetString("home"); home != "" {
+		return home
+	}
+	home, _ := os.UserHomeDir()
+	return home
+}
+
+// migrateOutputDir resolves --output, falling back to the current dotfiles repo
+func migrateOutputDir(cmd *cobra.Command) (string, error) {
+	if output, _ := cmd.Flags().GetString("output"); output != "" {
+		return filepath.Abs(output)
+	}
+	if repo, err := config.FindDotfilesRepo(); err == nil {
+		return repo.Root, nil
+	}
+	return os.Getwd()
+}
+
+func runMigrate(cmd *cobra.Command, analyze func() (*migrate.Plan, error)) error {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	verbosity := verbosityLevel(cmd)
+
+	output, err := migrateOutputDir(cmd)
+	if err != nil {
+		return err
+	}
+
+	plan, err := analyze()
+	if err != nil {
+		return err
+	}
+	if len(plan.Tools) == 0 {
+		migrate.PrintReport(os.Stdout, plan)
+		return fmt.Errorf("nothing to migrate")
+	}
+
+	if verbosity.Items() || dryRun {
+		for _, tool := range plan.Tools {
+			fmt.Printf("\n# config/%s/merlin.toml\n%s", tool.Name, migrate.ToolTOML(tool))
+		}
+		fmt.Println()
+	}
+
+	if dryRun {
+		fmt.Printf("Would write %d tools to %s:\n", len(plan.Tools), output)
+		migrate.PrintReport(os.Stdout, plan)
+		fmt.Println("\nThis was a dry run. No files were written.")
+		return nil
+	}
+
+	if err := migrate.Write(plan, output); err != nil {
+		return err
+	}
+
+	fmt.Printf("Migrated %d tools into %s:\n", len(plan.Tools), output)
+	migrate.PrintReport(os.Stdout, plan)
+	fmt.Println("\nNext steps:")
+	fmt.Println("  1. Review the generated merlin.toml files: merlin validate")
+	fmt.Println("  2. Remove the old links (e.g. 'stow -D <pkg>')")
+	fmt.Println("  3. Link with merlin: merlin link --all --dry-run")
+	return nil
+}
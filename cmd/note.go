@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ildx/merlin/internal/cli"
+	"github.com/ildx/merlin/internal/logger"
+	"github.com/ildx/merlin/internal/state"
+	"github.com/spf13/cobra"
+)
+
+var noteCmd = &cobra.Command{
+	Use:   "note",
+	Short: "Attach notes to managed targets",
+	Long: `Record why a target needs care, e.g. a file that must not be overwritten.
+
+BEHAVIOR
+	Notes are kept per target in ~/.merlin/state/notes.json and shown
+	whenever the target appears in merlin conflicts, merlin diff, link
+	conflicts and merlin info. Targets are stored as absolute paths; a
+	leading ~ is expanded.
+
+SUBCOMMANDS
+	add <target> <text>       Attach a note
+	list [target]             Show notes (default)
+	remove <target> [n]       Remove note n, or all of the target's notes
+
+FLAGS
+	list --json   Print the notes as JSON
+
+EXAMPLES
+	merlin note add ~/.zshrc "uses work plugin; don't overwrite"
+	merlin note list
+	merlin note remove ~/.zshrc 1`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runNoteList(cmd, args); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var noteAddCmd = &cobra.Command{
+	Use:   "add <target> <text>",
+	Short: "Attach a note to a target",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runNoteAdd(args[0], strings.Join(args[1:], " ")); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var noteListCmd = &cobra.Command{
+	Use:   "list [target]",
+	Short: "Show target notes",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runNoteList(cmd, args); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var noteRemoveCmd = &cobra.Command{
+	Use:   "remove <target> [n]",
+	Short: "Remove a target's notes",
+	Args:  cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runNoteRemove(args); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(noteCmd)
+	noteCmd.AddCommand(noteAddCmd)
+	noteCmd.AddCommand(noteListCmd)
+	noteCmd.AddCommand(noteRemoveCmd)
+	for _, c := range []*cobra.Command{noteCmd, noteListCmd} {
+		c.Flags().Bool("json", false, "Print the notes as JSON")
+	}
+}
+
+func runNoteAdd(path, text string) error {
+	if strings.TrimSpace(text) == "" {
+		return fmt.Errorf("note text is empty")
+	}
+	target, err := state.NoteTarget(path)
+	if err != nil {
+		return err
+	}
+	notes, err := state.LoadTargetNotes()
+	if err != nil {
+		return err
+	}
+	notes.Add(target, text, time.Now())
+	if err := notes.Save(); err != nil {
+		return fmt.Errorf("failed to save notes: %w", err)
+	}
+	cli.Success("Noted %s", target)
+	return nil
+}
+
+func runNoteList(cmd *cobra.Command, args []string) error {
+	asJSON, _ := cmd.Flags().GetBool("json")
+	notes, err := state.LoadTargetNotes()
+	if err != nil {
+		return err
+	}
+	targets := notes.Targets()
+	if len(args) == 1 {
+		target, err := state.NoteTarget(args[0])
+		if err != nil {
+			return err
+		}
+		targets = []string{target}
+	}
+
+	if asJSON {
+		selected := make(map[string][]state.Note)
+		for _, target := range targets {
+			if n := notes.For(target); len(n) > 0 {
+				selected[target] = n
+			}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(selected)
+	}
+
+	shown := 0
+	for _, target := range targets {
+		n := notes.For(target)
+		if len(n) == 0 {
+			continue
+		}
+		shown++
+		fmt.Printf("📝 %s\n", target)
+		for i, note := range n {
+			fmt.Printf("   %d. %s %s\n", i+1, note.Text, cli.Dim("("+note.Added.Format("2006-01-02")+")"))
+		}
+	}
+	if shown == 0 {
+		cli.Info("No notes; add one with: merlin note add <target> <text>")
+	}
+	return nil
+}
+
+func runNoteRemove(args []string) error {
+	target, err := state.NoteTarget(args[0])
+	if err != nil {
+		return err
+	}
+	index := 0
+	if len(args) == 2 {
+		if index, err = strconv.Atoi(args[1]); err != nil || index < 1 {
+			return fmt.Errorf("invalid note number %q", args[1])
+		}
+	}
+	notes, err := state.LoadTargetNotes()
+	if err != nil {
+		return err
+	}
+	if !notes.Remove(target, index) {
+		if index == 0 {
+			return fmt.Errorf("%s has no notes", target)
+		}
+		return fmt.Errorf("%s has no note %d (see 'merlin note list')", target, index)
+	}
+	if err := notes.Save(); err != nil {
+		return fmt.Errorf("failed to save notes: %w", err)
+	}
+	cli.Success("Removed note(s) from %s", target)
+	return nil
+}
+
+// loadTargetNotes reads the target notes for display; a broken notes file
+// only costs the notes
+func loadTargetNotes() *state.TargetNotes {
+	notes, err := state.LoadTargetNotes()
+	if err != nil {
+		logger.Debug("target notes not loaded", "error", err)
+	}
+	return notes
+}
+
+// printTargetNotes prints target's notes below an output line, indented
+func printTargetNotes(notes *state.TargetNotes, target, indent string) {
+	for _, text := range notes.Texts(target) {
+		fmt.Printf("%s📝 %s\n", indent, text)
+	}
+}
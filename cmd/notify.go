@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/ildx/merlin/internal/cli"
+	"github.com/ildx/merlin/internal/config"
+	"github.com/ildx/merlin/internal/installer"
+	"github.com/ildx/merlin/internal/logger"
+	"github.com/ildx/merlin/internal/notify"
+	"github.com/ildx/merlin/internal/parser"
+	"github.com/spf13/cobra"
+)
+
+// installSummary summarises install results for notifications. Items that
+// were already installed count as neither succeeded nor failed.
+func installSummary(op string, results ...[]*installer.InstallResult) notify.Summary {
+	succeeded := 0
+	var failures []string
+	for _, group := range results {
+		for _, r := range group {
+			switch {
+			case r.AlreadyExists:
+			case r.Success:
+				succeeded++
+			default:
+				failures = append(failures, r.Package)
+			}
+		}
+	}
+	return notify.NewSummary(op, succeeded, failures)
+}
+
+// reportInstall reports the outcome of an install once its summary has been
+// printed: as an error when anything failed under --quiet, which drops the
+// summary, and to the webhook
+func reportInstall(cmd *cobra.Command, repo *config.DotfilesRepo, summary notify.Summary) {
+	if summary.Failed > 0 {
+		cli.Failure("%s (%s)", summary.Message, strings.Join(summary.Failures, ", "))
+	}
+	notifyWebhook(cmd, repo, summary)
+}
+
+// notifyWebhook posts summary to the [settings.notify] webhook, if one is
+// configured. Dry runs and offline runs never notify, and a webhook that
+// can't be reached only warns: the operation itself already finished.
+func notifyWebhook(cmd *cobra.Command, repo *config.DotfilesRepo, summary notify.Summary) {
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun || offlineMode(cmd) || repo == nil {
+		return
+	}
+	rootConfig, err := parser.ParseRootMerlinTOML(repo.GetRootMerlinConfig())
+	if err != nil {
+		return
+	}
+	webhook := notify.NewWebhook(rootConfig.Settings.Notify)
+	if webhook == nil {
+		return
+	}
+	if err := webhook.Send(summary); err != nil {
+		cli.Warning("Notification not sent: %v", err)
+		return
+	}
+	logger.Debug("Sent webhook notification", "op", summary.Op, "status", summary.Status)
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/ildx/merlin/internal/cli"
+	"github.com/ildx/merlin/internal/config"
+	"github.com/ildx/merlin/internal/state"
+	"github.com/ildx/merlin/internal/symlink"
+)
+
+// exitOnCollisions stops before linking when a target of the tools about to
+// be linked is claimed twice, by them or by another enabled tool
+func exitOnCollisions(repo *config.DotfilesRepo, vars symlink.Variables, linking []*symlink.ToolConfig) {
+	all, _ := symlink.DiscoverTools(repo, vars)
+	names := make(map[string]bool, len(linking))
+	for _, tool := range linking {
+		names[tool.Name] = true
+	}
+	// The tools being linked are included even when discovery skipped them
+	claimants := append([]*symlink.ToolConfig{}, linking...)
+	for _, tool := range all {
+		if !names[tool.Name] {
+			claimants = append(claimants, tool)
+		}
+	}
+
+	var collisions []symlink.Collision
+	for _, c := range symlink.FindCollisions(claimants) {
+		if c.Involves(names) {
+			collisions = append(collisions, c)
+		}
+	}
+	if len(collisions) == 0 {
+		return
+	}
+	cli.Error("%d link target(s) are claimed by more than one source:", len(collisions))
+	for _, c := range collisions {
+		cli.Error("  %s", c)
+	}
+	cli.Error("rename or drop one of the files (see 'merlin validate')")
+	os.Exit(1)
+}
+
+// loadLinkRegistry reads the link registry, warning (and starting empty) when
+// it can't be read. An unparsable registry is kept as links.json.corrupt.
+func loadLinkRegistry() *state.LinkRegistry {
+	registry, err := state.LoadLinkRegistry()
+	if err != nil {
+		cli.Warning("link registry: %v", err)
+	}
+	return registry
+}
+
+// saveLinkRegistry writes the registry unless nothing was changed (dry runs)
+func saveLinkRegistry(registry *state.LinkRegistry, dryRun bool) {
+	if dryRun {
+		return
+	}
+	if err := registry.Save(); err != nil {
+		cli.Warning("link registry: %v", err)
+	}
+}
+
+// recordLinks records tool as the owner of every target it now links
+func recordLinks(registry *state.LinkRegistry, tool string, results []*symlink.LinkResult) {
+	for _, r := range results {
+		if r.Status == symlink.LinkStatusSuccess || r.Status == symlink.LinkStatusAlreadyLinked {
+			registry.Record(tool, r.Source, r.Target)
+		}
+	}
+}
+
+// unlinkOwned removes the links the registry attributes to tool that its
+// config no longer declares (e.g. a file deleted from a merged directory),
+// then forgets every target tool no longer links. results are the
+// UnlinkTool results for the declared links.
+func unlinkOwned(registry *state.LinkRegistry, tool *symlink.ToolConfig, results []*symlink.UnlinkResult, dryRun bool) []*symlink.UnlinkResult {
+	declared := make(map[string]bool)
+	for _, link := range symlink.ExpandLinks(tool.Links) {
+		declared[link.Target] = true
+	}
+	for _, target := range registry.Targets(tool.Name) {
+		if declared[target] {
+			continue
+		}
+		owner, _ := registry.Owner(target)
+		result, _ := symlink.RemoveSymlink(owner.Source, target, dryRun)
+		results = append(results, result)
+	}
+
+	if dryRun {
+		return results
+	}
+	for _, r := range results {
+		// Anything not left in place on error is no longer tool's link
+		if owner, ok := registry.Owner(r.Target); ok && owner.Tool == tool.Name && r.Status != symlink.LinkStatusError {
+			registry.Forget(r.Target)
+		}
+	}
+	return results
+}
+
+// unregisteredMessage marks declared links unlink --all left in place because
+// the link registry doesn't attribute them to the tool
+const unregisteredMessage = "not in the link registry (--force removes it)"
+
+// unlinkRegistered is UnlinkTool for unlink --all: a declared target that is
+// a symlink is only removed when the registry records the tool as its owner,
+// so a link the user made by hand at a path a refactored config now declares
+// is left alone. With force every declared link is removed, like UnlinkTool.
+func unlinkRegistered(registry *state.LinkRegistry, tool *symlink.ToolConfig, dryRun, force bool) []*symlink.UnlinkResult {
+	if force {
+		results, _ := symlink.UnlinkTool(tool, dryRun)
+		return results
+	}
+	var results []*symlink.UnlinkResult
+	for _, link := range symlink.ExpandLinks(tool.Links) {
+		owner, recorded := registry.Owner(link.Target)
+		if symlink.IsSymlink(link.Target) && (!recorded || owner.Tool != tool.Name) {
+			results = append(results, &symlink.UnlinkResult{Target: link.Target, Status: symlink.LinkStatusSkipped, Message: unregisteredMessage})
+			continue
+		}
+		result, _ := symlink.RemoveSymlink(link.Source, link.Target, dryRun)
+		results = append(results, result)
+	}
+	return results
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ildx/merlin/internal/installer/installertest"
+	"github.com/ildx/merlin/internal/symlink"
+)
+
+func TestUnlinkOwnedRemovesUndeclaredLinks(t *testing.T) {
+	s := installertest.NewMemSandbox(t)
+	confd := filepath.Join(s.Home, ".config", "zsh", "conf.d")
+	s.WriteFile("config/zsh/conf.d/aliases.zsh", "alias ll='ls -l'\n")
+	stale := s.WriteFile("config/zsh/conf.d/old.zsh", "# removed later\n")
+	s.WriteFile("config/work/conf.d/work.zsh", "export WORK=1\n")
+
+	contents := func(tool string) *symlink.ToolConfig {
+		return &symlink.ToolConfig{Name: tool, Links: []symlink.ResolvedLink{{
+			Source: filepath.Join(s.Repo.ConfigDir, tool, "conf.d"), Target: confd, IsDir: true, Contents: true,
+		}}}
+	}
+
+	registry := loadLinkRegistry()
+	for _, tool := range []string{"zsh", "work"} {
+		results, _ := symlink.LinkToolWithStrategy(contents(tool), symlink.StrategySkip, false)
+		recordLinks(registry, tool, results)
+	}
+	if owner, _ := registry.Owner(filepath.Join(confd, "work.zsh")); owner.Tool != "work" {
+		t.Fatalf("work.zsh owner = %+v", owner)
+	}
+
+	// old.zsh leaves the repository; unlinking zsh must still remove its link
+	s.Remove(stale)
+	zsh := contents("zsh")
+	results, _ := symlink.UnlinkTool(zsh, false)
+	results = unlinkOwned(registry, zsh, results, false)
+
+	for _, name := range []string{"aliases.zsh", "old.zsh"} {
+		if s.Exists(filepath.Join(confd, name)) {
+			t.Errorf("%s still present after unlinking zsh (results %+v)", name, results)
+		}
+	}
+	if !s.Exists(filepath.Join(confd, "work.zsh")) {
+		t.Errorf("work.zsh, owned by work, was removed")
+	}
+	if targets := registry.Targets("zsh"); len(targets) != 0 {
+		t.Errorf("registry still lists zsh targets %v", targets)
+	}
+	if targets := registry.Targets("work"); len(targets) != 1 {
+		t.Errorf("registry targets for work = %v", targets)
+	}
+}
+
+func TestUnlinkRegisteredKeepsUnrecordedLinks(t *testing.T) {
+	s := installertest.NewMemSandbox(t)
+	source := s.WriteFile("config/git/gitconfig", "[user]\n")
+	manual := s.WriteFile("config/git/gitignore", "*.swp\n")
+	gitconfig := filepath.Join(s.Home, ".gitconfig")
+	gitignore := filepath.Join(s.Home, ".gitignore")
+	tool := &symlink.ToolConfig{Name: "git", Links: []symlink.ResolvedLink{
+		{Source: source, Target: gitconfig},
+		{Source: manual, Target: gitignore},
+	}}
+
+	// merlin linked .gitconfig; the user made .gitignore by hand
+	registry := loadLinkRegistry()
+	results, _ := symlink.LinkToolWithStrategy(&symlink.ToolConfig{Name: "git", Links: tool.Links[:1]}, symlink.StrategySkip, false)
+	recordLinks(registry, "git", results)
+	s.Symlink(manual, gitignore)
+
+	unlinked := unlinkRegistered(registry, tool, false, false)
+	if s.Exists(gitconfig) {
+		t.Error(".gitconfig should be removed")
+	}
+	if !s.Exists(gitignore) {
+		t.Error(".gitignore has no registry entry and should be kept")
+	}
+	if n := countUnregistered(unlinked); n != 1 {
+		t.Errorf("countUnregistered = %d, want 1 (results %+v)", n, unlinked)
+	}
+
+	unlinkRegistered(registry, tool, false, true)
+	if s.Exists(gitignore) {
+		t.Error("--force should remove .gitignore")
+	}
+}
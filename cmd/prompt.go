@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ildx/merlin/internal/cli"
+	"github.com/ildx/merlin/internal/config"
+	"github.com/ildx/merlin/internal/diff"
+	"github.com/ildx/merlin/internal/state"
+	"github.com/spf13/cobra"
+)
+
+var promptCmd = &cobra.Command{
+	Use:   "prompt",
+	Short: "Print a terse drift indicator for shell prompts",
+	Long: `Print a one-line drift indicator such as "✔" or "3⇡ 2✘", meant to be
+embedded in starship, powerlevel10k or tmux status lines.
+
+BEHAVIOR
+	The indicator comes from the summary saved by the last 'merlin diff', so
+	it costs a file read: nothing is recomputed and brew/mas are never run.
+	"?" is printed when no summary exists yet. --refresh recomputes it from
+	the live symlinks and the cached package state (still without running
+	brew/mas) before printing.
+
+	⇡  declared but not installed or linked
+	✘  broken, divergent or orphaned links, stale apps, missing scripts
+	+  installed or present but not declared
+
+FORMAT PLACEHOLDERS
+	{status}    "✔" or the non-zero counts, e.g. "3⇡ 2✘" (default format)
+	{missing}   Count of ⇡ items
+	{broken}    Count of ✘ items
+	{extra}     Count of + items
+	{total}     Sum of the three
+	{age}       Time since the summary was taken, e.g. "12m"
+
+FLAGS
+	--format <template>   Output template (default "{status}")
+	--refresh             Recompute the summary first
+
+EXAMPLES
+	merlin prompt
+	merlin prompt --format '{status} ({age})'
+	merlin prompt --refresh
+
+	# starship.toml
+	[custom.merlin]
+	command = "merlin prompt"
+	when = true`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runPrompt(cmd); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(promptCmd)
+	promptCmd.Flags().String("format", state.DefaultPromptFormat, "Output template ({status}, {missing}, {broken}, {extra}, {total}, {age})")
+	promptCmd.Flags().Bool("refresh", false, "Recompute the drift summary from live links and cached packages first")
+}
+
+func runPrompt(cmd *cobra.Command) error {
+	format, _ := cmd.Flags().GetString("format")
+	refresh, _ := cmd.Flags().GetBool("refresh")
+
+	if refresh {
+		if err := refreshDrift(); err != nil {
+			return err
+		}
+	}
+
+	drift, err := state.LoadDrift()
+	if err != nil {
+		// A prompt must keep rendering: no summary yet is not an error
+		fmt.Println("?")
+		return nil
+	}
+	fmt.Println(drift.Format(format, time.Now()))
+	return nil
+}
+
+// refreshDrift recomputes the drift summary offline: live symlinks plus the
+// package state cached by the last online diff
+func refreshDrift() error {
+	repo, err := config.FindDotfilesRepo()
+	if err != nil {
+		return fmt.Errorf("dotfiles repository not found: %w", err)
+	}
+	snap, cache := state.CollectOfflineSnapshot(repo.Root)
+	result, err := diff.Compute(context.Background(), repo, snap)
+	if err != nil {
+		return fmt.Errorf("compute diff: %w", err)
+	}
+	if cache == nil {
+		// Without cached packages every declared package would count as missing
+		result.BrewFormulae, result.BrewCasks, result.MASApps, result.Extensions = diff.PackageDiff{}, diff.PackageDiff{}, diff.PackageDiff{}, diff.PackageDiff{}
+	}
+	return state.SaveDrift(result.Drift(repo.Root))
+}
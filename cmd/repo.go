@@ -0,0 +1,251 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ildx/merlin/internal/cli"
+	"github.com/ildx/merlin/internal/config"
+	"github.com/ildx/merlin/internal/git"
+	"github.com/ildx/merlin/internal/parser"
+	"github.com/spf13/cobra"
+)
+
+var repoCmd = &cobra.Command{
+	Use:   "repo",
+	Short: "Maintain the dotfiles repository itself",
+	Long: `Housekeeping for the git repository holding your dotfiles.
+
+SUBCOMMANDS
+	gitignore sync   Write merlin's managed block into .gitignore
+	flush            Commit auto-commits queued by batch_window
+	commit           Create auto-commits skipped because of unrelated changes`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+var repoGitignoreCmd = &cobra.Command{
+	Use:   "gitignore",
+	Short: "Manage merlin's block in .gitignore",
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+var repoGitignoreSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Write merlin's managed block into .gitignore",
+	Long: `Keep files merlin generates inside the repository out of git.
+
+BEHAVIOR
+	Writes a block delimited by "# >>> merlin managed" markers into the
+	repository's .gitignore, creating the file if needed. The block ignores
+	merlin.local.toml and state under .merlin-meta/ (except the backup index,
+	which auto-commit records, and the machine inventory and snapshots), plus
+	any [settings.git] ignore patterns.
+	Everything outside the block is left alone; re-running updates the block
+	in place.
+
+FLAGS
+	--dry-run   Print the block without writing .gitignore
+
+EXAMPLES
+	merlin repo gitignore sync
+	merlin repo gitignore sync --dry-run`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runRepoGitignoreSync(cmd); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var repoFlushCmd = &cobra.Command{
+	Use:   "flush",
+	Short: "Commit queued auto-commits now",
+	Long: `Commit the auto-commits queued while [settings.git] batch_window is set.
+
+BEHAVIOR
+	With batch_window (e.g. "10m"), link, unlink and backup stage their
+	changes and queue the action instead of committing. The queue becomes a
+	single commit once the next auto-commit finds it quiet for the window, or
+	when you run this command. The commit body lists every action as a
+	Merlin-Action trailer.
+
+FLAGS
+	--dry-run   List the queued actions without committing
+
+EXAMPLES
+	merlin repo flush
+	merlin repo flush --dry-run`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runRepoFlush(cmd); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var repoCommitCmd = &cobra.Command{
+	Use:   "commit",
+	Short: "Create auto-commits skipped because of unrelated changes",
+	Long: `Create the auto-commits that link, unlink or backup skipped.
+
+BEHAVIOR
+	An auto-commit is skipped when the repository has changes outside the
+	operation's own files; the skipped commit is remembered and the blocking
+	paths are listed. This command stages the remembered paths, plus any
+	--include paths, and commits them with the usual auto-commit message
+	(one Merlin-Action trailer per skipped operation).
+
+FLAGS
+	--include <path>   Also commit path (relative to the repository root);
+	                   repeatable
+	-m, --message      Commit message; required when no commit was skipped
+	--dry-run          List what would be committed
+
+EXAMPLES
+	merlin repo commit
+	merlin repo commit --include config/zsh/notes.md
+	merlin repo commit --include Brewfile -m "chore: track Brewfile"`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runRepoCommit(cmd); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(repoCmd)
+	repoCmd.AddCommand(repoGitignoreCmd)
+	repoGitignoreCmd.AddCommand(repoGitignoreSyncCmd)
+	repoCmd.AddCommand(repoFlushCmd)
+	repoCmd.AddCommand(repoCommitCmd)
+	repoCommitCmd.Flags().StringArray("include", nil, "Also commit this path (repeatable)")
+	repoCommitCmd.Flags().StringP("message", "m", "", "Commit message (required when no commit was skipped)")
+}
+
+func runRepoGitignoreSync(cmd *cobra.Command) error {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	repo, err := config.FindDotfilesRepo()
+	if err != nil {
+		return fmt.Errorf("dotfiles repository not found: %w", err)
+	}
+	rootConfig, err := parser.ParseRootMerlinTOML(repo.GetRootMerlinConfig())
+	if err != nil {
+		return err
+	}
+
+	patterns := git.ManagedIgnores(rootConfig.Settings.Git.Ignore)
+	changed, err := git.SyncGitignore(repo.Root, patterns, dryRun)
+	if err != nil {
+		return fmt.Errorf("failed to update .gitignore: %w", err)
+	}
+
+	switch {
+	case !changed:
+		cli.Success(".gitignore is up to date")
+	case dryRun:
+		fmt.Println("🔍 Would write to .gitignore:")
+		fmt.Println(git.GitignoreBegin)
+		for _, p := range patterns {
+			fmt.Println(p)
+		}
+		fmt.Println(git.GitignoreEnd)
+	default:
+		cli.Success("Updated .gitignore (%d managed patterns)", len(patterns))
+	}
+	return nil
+}
+
+func runRepoFlush(cmd *cobra.Command) error {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	repo, err := config.FindDotfilesRepo()
+	if err != nil {
+		return fmt.Errorf("dotfiles repository not found: %w", err)
+	}
+	rootConfig, err := parser.ParseRootMerlinTOML(repo.GetRootMerlinConfig())
+	if err != nil {
+		return err
+	}
+	repoGit, err := git.Open(repo.Root)
+	if err != nil {
+		return err
+	}
+	pending, err := repoGit.PendingCommits()
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		cli.Success("No queued auto-commits")
+		return nil
+	}
+
+	if dryRun {
+		fmt.Printf("🔍 %d queued auto-commit(s) would be committed as:\n", len(pending))
+		fmt.Printf("   %s\n", git.CommitMessage(rootConfig.Settings.Git.CommitTemplate, git.BatchInfo(pending)))
+		for _, p := range pending {
+			fmt.Printf("   %s: %s %s\n", git.BatchTrailer, p.Date.Format(time.RFC3339), p.Summary)
+		}
+		return nil
+	}
+
+	msg, err := repoGit.FlushCommits(rootConfig.Settings.Git.CommitTemplate)
+	if err != nil {
+		return err
+	}
+	cli.Success("Auto-commit created (%s)", msg)
+	return nil
+}
+
+func runRepoCommit(cmd *cobra.Command) error {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	include, _ := cmd.Flags().GetStringArray("include")
+	message, _ := cmd.Flags().GetString("message")
+
+	repo, err := config.FindDotfilesRepo()
+	if err != nil {
+		return fmt.Errorf("dotfiles repository not found: %w", err)
+	}
+	rootConfig, err := parser.ParseRootMerlinTOML(repo.GetRootMerlinConfig())
+	if err != nil {
+		return err
+	}
+	repoGit, err := git.Open(repo.Root)
+	if err != nil {
+		return err
+	}
+	blocked, err := repoGit.BlockedCommits()
+	if err != nil {
+		return err
+	}
+	if len(blocked) == 0 && len(include) == 0 {
+		cli.Success("No skipped auto-commits")
+		return nil
+	}
+
+	if dryRun {
+		fmt.Println("🔍 Would commit:")
+		for _, b := range blocked {
+			fmt.Printf("   %s: %s\n", b.Summary, strings.Join(b.Paths, ", "))
+		}
+		for _, p := range include {
+			fmt.Printf("   included: %s\n", p)
+		}
+		return nil
+	}
+
+	msg, err := repoGit.CommitBlocked(rootConfig.Settings.Git.CommitTemplate, include, message)
+	if err != nil {
+		return err
+	}
+	cli.Success("Commit created (%s)", msg)
+	return nil
+}
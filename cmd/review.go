@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/ildx/merlin/internal/cli"
+	"github.com/ildx/merlin/internal/symlink"
+)
+
+// stdinIsTerminal reports whether prompts can be answered interactively
+func stdinIsTerminal() bool {
+	return term.IsTerminal(os.Stdin.Fd())
+}
+
+// excludeTools drops the tools named by --except. Names that match no tool
+// are an error, so a typo doesn't silently touch the tool it meant to skip.
+func excludeTools(tools []*symlink.ToolConfig, except []string) ([]*symlink.ToolConfig, error) {
+	if len(except) == 0 {
+		return tools, nil
+	}
+	skip := make(map[string]bool, len(except))
+	for _, name := range except {
+		skip[strings.TrimSpace(name)] = true
+	}
+	var kept []*symlink.ToolConfig
+	for _, tool := range tools {
+		if skip[tool.Name] {
+			delete(skip, tool.Name)
+			continue
+		}
+		kept = append(kept, tool)
+	}
+	if len(skip) > 0 {
+		var unknown []string
+		for _, name := range except {
+			if skip[strings.TrimSpace(name)] {
+				unknown = append(unknown, strings.TrimSpace(name))
+			}
+		}
+		return nil, fmt.Errorf("--except names unknown tool(s): %s", strings.Join(unknown, ", "))
+	}
+	return kept, nil
+}
+
+// batchReview is the summary shown before link --all and unlink --all touch
+// every tool
+type batchReview struct {
+	Verb      string // "link" or "unlink"
+	Table     *cli.Table
+	Row       func(tool *symlink.ToolConfig) []string
+	Prompt    bool // ask which tools to skip (a terminal, no --yes, no --dry-run)
+	Input     io.Reader
+	Output    io.Writer
+	Cancelled bool // set when the user cancels
+}
+
+// Review prints one numbered row per tool and, when prompting, lets the user
+// deselect tools by number, range or name. It returns the tools to process.
+func (r *batchReview) Review(tools []*symlink.ToolConfig) ([]*symlink.ToolConfig, error) {
+	for i, tool := range tools {
+		r.Table.AddRow(append([]string{strconv.Itoa(i + 1), tool.Name}, r.Row(tool)...)...)
+	}
+	fmt.Fprintf(r.Output, "About to %s %d tool(s):\n\n", r.Verb, len(tools))
+	r.Table.Render(r.Output)
+	fmt.Fprintln(r.Output)
+	if !r.Prompt {
+		return tools, nil
+	}
+
+	fmt.Fprintf(r.Output, "Press Enter to %s all, list tools to skip (e.g. '2 4-6 karabiner'), or 'q' to cancel: ", r.Verb)
+	line, err := bufio.NewReader(r.Input).ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return nil, fmt.Errorf("failed to read input")
+	}
+	answer := strings.TrimSpace(line)
+	switch strings.ToLower(answer) {
+	case "":
+		return tools, nil
+	case "q", "quit", "n", "no":
+		r.Cancelled = true
+		return nil, nil
+	}
+
+	skip, err := parseToolSelection(answer, tools)
+	if err != nil {
+		return nil, err
+	}
+	var kept []*symlink.ToolConfig
+	var skipped []string
+	for i, tool := range tools {
+		if skip[i] {
+			skipped = append(skipped, tool.Name)
+			continue
+		}
+		kept = append(kept, tool)
+	}
+	fmt.Fprintf(r.Output, "Skipping: %s\n\n", strings.Join(skipped, ", "))
+	return kept, nil
+}
+
+// parseToolSelection resolves 1-based numbers, ranges ("4-6") and tool names
+// to indexes into tools
+func parseToolSelection(input string, tools []*symlink.ToolConfig) (map[int]bool, error) {
+	selected := make(map[int]bool)
+	for _, part := range strings.Fields(strings.ReplaceAll(input, ",", " ")) {
+		if from, to, ok := strings.Cut(part, "-"); ok {
+			start, err1 := strconv.Atoi(from)
+			end, err2 := strconv.Atoi(to)
+			if err1 == nil && err2 == nil {
+				if start < 1 || end > len(tools) || start > end {
+					return nil, fmt.Errorf("invalid range %q (tools are numbered 1-%d)", part, len(tools))
+				}
+				for i := start; i <= end; i++ {
+					selected[i-1] = true
+				}
+				continue
+			}
+		}
+		if n, err := strconv.Atoi(part); err == nil {
+			if n < 1 || n > len(tools) {
+				return nil, fmt.Errorf("invalid number %d (tools are numbered 1-%d)", n, len(tools))
+			}
+			selected[n-1] = true
+			continue
+		}
+		found := false
+		for i, tool := range tools {
+			if tool.Name == part {
+				selected[i] = true
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown tool %q", part)
+		}
+	}
+	return selected, nil
+}
+
+// withLinks drops tools without links, which an --all run passes over anyway
+func withLinks(tools []*symlink.ToolConfig) []*symlink.ToolConfig {
+	var kept []*symlink.ToolConfig
+	for _, tool := range tools {
+		if len(tool.Links) > 0 {
+			kept = append(kept, tool)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ildx/merlin/internal/cli"
+	"github.com/ildx/merlin/internal/symlink"
+)
+
+func reviewTools(names ...string) []*symlink.ToolConfig {
+	var tools []*symlink.ToolConfig
+	for _, name := range names {
+		tools = append(tools, &symlink.ToolConfig{Name: name})
+	}
+	return tools
+}
+
+func toolNames(tools []*symlink.ToolConfig) string {
+	var names []string
+	for _, tool := range tools {
+		names = append(names, tool.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+func TestExcludeTools(t *testing.T) {
+	tools := reviewTools("git", "zsh", "karabiner")
+
+	kept, err := excludeTools(tools, []string{"zsh", " karabiner"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := toolNames(kept); got != "git" {
+		t.Errorf("kept %s, want git", got)
+	}
+
+	if _, err := excludeTools(tools, []string{"zhs"}); err == nil || !strings.Contains(err.Error(), "zhs") {
+		t.Errorf("expected unknown tool error, got %v", err)
+	}
+}
+
+func TestBatchReview(t *testing.T) {
+	newReview := func(input string, prompt bool) (*batchReview, *bytes.Buffer) {
+		var out bytes.Buffer
+		return &batchReview{
+			Verb:   "link",
+			Table:  cli.NewTable("#", "TOOL", "LINKS"),
+			Row:    func(*symlink.ToolConfig) []string { return []string{"1"} },
+			Prompt: prompt,
+			Input:  strings.NewReader(input),
+			Output: &out,
+		}, &out
+	}
+	tools := reviewTools("git", "zsh", "karabiner", "tmux")
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"\n", "git,zsh,karabiner,tmux"},
+		{"2 4\n", "git,karabiner"},
+		{"2-3\n", "git,tmux"},
+		{"karabiner, 1\n", "zsh,tmux"},
+	}
+	for _, tt := range tests {
+		review, out := newReview(tt.input, true)
+		kept, err := review.Review(tools)
+		if err != nil {
+			t.Fatalf("Review(%q) error = %v", tt.input, err)
+		}
+		if got := toolNames(kept); got != tt.want {
+			t.Errorf("Review(%q) kept %s, want %s", tt.input, got, tt.want)
+		}
+		if !strings.Contains(out.String(), "About to link 4 tool(s)") {
+			t.Errorf("summary missing from output:\n%s", out.String())
+		}
+	}
+
+	review, _ := newReview("q\n", true)
+	if kept, err := review.Review(tools); err != nil || len(kept) != 0 || !review.Cancelled {
+		t.Errorf("'q' should cancel, got %v, %v", kept, err)
+	}
+
+	review, _ = newReview("9\n", true)
+	if _, err := review.Review(tools); err == nil {
+		t.Error("expected error for a number out of range")
+	}
+
+	review, _ = newReview("", false)
+	if kept, err := review.Review(tools); err != nil || len(kept) != 4 {
+		t.Errorf("without prompting every tool should be kept, got %v, %v", kept, err)
+	}
+}
@@ -2,10 +2,20 @@ package cmd
 
 import (
 	"os"
+	"strings"
 
+	"github.com/ildx/merlin/internal/backup"
 	"github.com/ildx/merlin/internal/cli"
+	"github.com/ildx/merlin/internal/config"
+	"github.com/ildx/merlin/internal/installer"
 	"github.com/ildx/merlin/internal/logger"
+	"github.com/ildx/merlin/internal/parser"
+	"github.com/ildx/merlin/internal/progress"
+	"github.com/ildx/merlin/internal/protect"
+	"github.com/ildx/merlin/internal/symlink"
+	"github.com/ildx/merlin/internal/system"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 const (
@@ -29,7 +39,14 @@ CONFIG SOURCE
 
 GLOBAL FLAGS
 	--dry-run    Preview actions without changing the system
-	--verbose,-v More detailed output & debug logging
+	--verbose,-v More detailed output; repeat for more (-vv streams
+	             brew/mas/script output, -vvv adds paths, timings and
+	             debug logging)
+	--wide       Do not truncate table columns to terminal width
+	--offline    Skip network operations; use cached package state
+	--progress-fd <n>, --progress-file <path>
+	             Stream progress events as JSON lines to a file
+	             descriptor or a file/named pipe (see docs/USAGE.md)
 
 EXAMPLES
 	merlin                 # Launch interactive TUI
@@ -58,8 +75,7 @@ Built with Go and Charm for a beautiful terminal experience.`,
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
 	// Initialize logging
-	verbose, _ := rootCmd.Flags().GetBool("verbose")
-	if err := logger.Init(logger.LevelInfo, verbose); err != nil {
+	if err := logger.Init(logger.LevelInfo, false); err != nil {
 		cli.Warning("Failed to initialize logging: %v", err)
 	}
 
@@ -72,22 +88,128 @@ func Execute() {
 
 func init() {
 	// Global flags
-	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Enable verbose output")
+	rootCmd.PersistentFlags().CountP("verbose", "v", "Verbose output (-v items, -vv subprocess output, -vvv debug)")
 	rootCmd.PersistentFlags().Bool("dry-run", false, "Show what would be done without doing it")
+	rootCmd.PersistentFlags().Bool("wide", false, "Print full table cells instead of fitting terminal width")
+	rootCmd.PersistentFlags().Bool("no-truncate", false, "Alias for --wide")
+	rootCmd.PersistentFlags().Bool("offline", false, "Skip operations that need the network (also: MERLIN_OFFLINE=1)")
+	rootCmd.PersistentFlags().Int("progress-fd", 0, "Write progress events as JSON lines to this file descriptor")
+	rootCmd.PersistentFlags().String("progress-file", "", "Write progress events as JSON lines to this file or named pipe")
 
 	// Initialize logging early
-	cobra.OnInitialize(initLogging)
+	cobra.OnInitialize(initLogging, initRootSettings, initOperation)
+
+	// Flags are parsed by now: fill in [defaults] before reading any
+	rootCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		initCommandDefaults(cmd)
+		initOffline()
+		initProgress(cmd)
+		initOutput(cmd)
+	}
 
 	// Hide the default completion command
 	rootCmd.CompletionOptions.DisableDefaultCmd = true
 }
 
 func initLogging() {
-	verbose, _ := rootCmd.Flags().GetBool("verbose")
-	if err := logger.Init(logger.LevelInfo, verbose); err != nil {
+	if err := logger.Init(logger.LevelInfo, verbosityLevel(rootCmd).Debug()); err != nil {
 		// Non-fatal - just print warning
 		cli.Warning("Failed to initialize logging: %v", err)
 	}
 
 	logger.Debug("Merlin starting", "version", version)
 }
+
+// initOffline exports MERLIN_OFFLINE so scripts run by merlin can skip network work too.
+// It runs after [defaults] are applied, so offline = true there counts as well.
+func initOffline() {
+	if offline, _ := rootCmd.PersistentFlags().GetBool("offline"); offline {
+		os.Setenv("MERLIN_OFFLINE", "1")
+	}
+}
+
+// initProgress opens the progress event stream requested by --progress-fd
+// or --progress-file, tagging events with the command, e.g. "install brew"
+func initProgress(cmd *cobra.Command) {
+	fd, _ := cmd.Flags().GetInt("progress-fd")
+	path, _ := cmd.Flags().GetString("progress-file")
+	if err := progress.Open(fd, path, strings.TrimPrefix(cmd.CommandPath(), rootCmd.Name()+" ")); err != nil {
+		cli.Warning("%v", err)
+	}
+}
+
+// addOutputFlags adds --quiet and --summary to the commands that honor them
+func addOutputFlags(flags *pflag.FlagSet) {
+	flags.Bool("quiet", false, "Print errors only (for cron and schedulers)")
+	flags.Bool("summary", false, "Print final counts and failures instead of per-item lines")
+}
+
+// initOutput applies --quiet or --summary; --quiet wins when both are given
+func initOutput(cmd *cobra.Command) {
+	mode := cli.OutputNormal
+	if summary, _ := cmd.Flags().GetBool("summary"); summary {
+		mode = cli.OutputSummary
+	}
+	if quiet, _ := cmd.Flags().GetBool("quiet"); quiet {
+		mode = cli.OutputQuiet
+	}
+	if err := cli.SetOutputMode(mode); err != nil {
+		cli.Warning("%v", err)
+	}
+}
+
+// initRootSettings applies the root merlin.toml settings every command obeys:
+// protected_paths, which the symlink engine and backup restore refuse to
+// touch, [settings.brew], and the backup store (backup_dir, backup_roots).
+// Commands report unreadable configs themselves, so errors are ignored here.
+func initRootSettings() {
+	repo, err := config.FindDotfilesRepo()
+	if err != nil {
+		return
+	}
+	rootConfig, err := parser.ParseRootMerlinTOML(repo.GetRootMerlinConfig())
+	if err != nil {
+		return
+	}
+	installer.ConfigureBrew(rootConfig.Settings.Brew)
+	vars, err := symlink.GetVariablesFromRoot(rootConfig)
+	if err != nil {
+		return
+	}
+	protect.Set(rootConfig.Settings.ProtectedPaths, vars.HomeDir)
+	backup.Configure(rootConfig.Settings.BackupDir, rootConfig.Settings.BackupRoots, vars.HomeDir)
+}
+
+// offlineMode reports whether --offline was passed or MERLIN_OFFLINE is set.
+func offlineMode(cmd *cobra.Command) bool {
+	if offline, _ := cmd.Flags().GetBool("offline"); offline {
+		return true
+	}
+	return system.IsOffline()
+}
+
+// noTruncate reports whether --wide or --no-truncate was passed.
+func noTruncate(cmd *cobra.Command) bool {
+	wide, _ := cmd.Flags().GetBool("wide")
+	full, _ := cmd.Flags().GetBool("no-truncate")
+	return wide || full
+}
+
+// newTable creates a width-aware table honoring the global truncation flags.
+func newTable(cmd *cobra.Command, headers ...string) *cli.Table {
+	t := cli.NewTable(headers...)
+	t.NoTruncate = noTruncate(cmd)
+	return t
+}
+
+// initOperation tags every backup created during this run with one
+// operation ID, linking link results and safety backups to their command.
+func initOperation() {
+	backup.StartOperation()
+}
+
+// verbosityLevel returns how many times -v/--verbose was given
+func verbosityLevel(cmd *cobra.Command) cli.Verbosity {
+	n, _ := cmd.Flags().GetCount("verbose")
+	return cli.Verbosity(n)
+}
@@ -19,11 +19,31 @@ var runCmd = &cobra.Command{
 
 BEHAVIOR
 	Scripts defined under [scripts] are executed sequentially in the order listed.
+	By default the first failure stops the run and the remaining scripts are
+	reported as skipped; set on_error = "continue" under [scripts] or pass
+	--keep-going to run them anyway.
 	Dry-run mode shows what would execute without running the scripts.
 
 FLAGS
 	--dry-run     Preview script execution plan
-	--verbose,-v  Stream each script's output lines
+	-v            Show the tool's script list before running
+	-vv           Stream each script's output lines
+	-vvv          Also print script paths and durations
+	--trust-all   Run new or changed scripts without confirmation (CI)
+	--keep-going  Run remaining scripts after one fails
+	--param name=value
+	              Value for a script param (repeatable)
+
+PARAMS
+	Scripts may declare args = ["--flag"] and params = [{ name = "region",
+	default = "eu" }]. Params without a --param value are prompted for when
+	stdin is a terminal and otherwise take their default. Values are exported
+	as MERLIN_PARAM_<NAME>, expand {name} in args, and are recorded at the top
+	of the run's log (merlin scripts logs).
+
+TRUST
+	New or modified scripts are shown and must be confirmed before they run.
+	Approve ahead of time with: merlin scripts trust <tool>
 
 VALIDATION
 	Before execution, scripts are validated for existence. Missing scripts abort.
@@ -31,7 +51,8 @@ VALIDATION
 EXAMPLES
 	merlin run zellij                 # Run zellij scripts
 	merlin run cursor --dry-run       # Preview cursor scripts
-	merlin run git --verbose          # Detailed streaming output
+	merlin run git -vv                # Stream script output
+	merlin run aws --param region=us  # Set a script param
 
 TIPS
 	Combine after linking: merlin link zellij --run-scripts
@@ -40,11 +61,11 @@ TIPS
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		dryRun, _ := cmd.Flags().GetBool("dry-run")
-		verbose, _ := cmd.Flags().GetBool("verbose")
+		verbosity := verbosityLevel(cmd)
 
 		toolName := args[0]
 
-		if err := runToolScripts(toolName, dryRun, verbose); err != nil {
+		if _, err := runToolScripts(toolName, dryRun, verbosity); err != nil {
 			cli.Error("%v", err)
 			os.Exit(1)
 		}
@@ -53,51 +74,56 @@ TIPS
 
 func init() {
 	rootCmd.AddCommand(runCmd)
+	runCmd.Flags().BoolVar(&scriptsTrustAll, "trust-all", false, "Run new or changed scripts without confirmation")
+	runCmd.Flags().BoolVar(&scriptsKeepGoing, "keep-going", false, "Run remaining scripts after one fails (overrides on_error)")
+	runCmd.Flags().StringArrayVar(&scriptsParams, "param", nil, "Value for a script param as name=value (repeatable)")
 }
 
-func runToolScripts(toolName string, dryRun, verbose bool) error {
+// runToolScripts runs a tool's scripts and returns their results; the error
+// reports failed scripts as well as scripts that couldn't be started
+func runToolScripts(toolName string, dryRun bool, verbosity cli.Verbosity) ([]*scripts.ScriptResult, error) {
 	// Find dotfiles repo
 	repo, err := config.FindDotfilesRepo()
 	if err != nil {
-		return fmt.Errorf("dotfiles repository not found: %w", err)
+		return nil, fmt.Errorf("dotfiles repository not found: %w", err)
 	}
 
-	if verbose {
+	if verbosity.Debug() {
 		fmt.Printf("Dotfiles repository: %s\n", repo.Root)
 		if dryRun {
-			fmt.Println("Mode: Dry run (no scripts will be executed)")
+			fmt.Println("Mode: Dry run (only scripts with dry_run_supported will be executed)")
 		}
 		fmt.Println()
 	}
 
 	// Check if tool exists
 	if !repo.ToolExists(toolName) {
-		return fmt.Errorf("tool '%s' not found in dotfiles repository", toolName)
+		return nil, fmt.Errorf("tool '%s' not found in dotfiles repository", toolName)
 	}
 
 	// Parse tool's merlin.toml
 	merlinPath := repo.GetToolMerlinConfig(toolName)
 	toolConfig, err := parser.ParseToolMerlinTOML(merlinPath)
 	if err != nil {
-		return fmt.Errorf("failed to parse %s: %w", merlinPath, err)
+		return nil, fmt.Errorf("failed to parse %s: %w", merlinPath, err)
 	}
 
 	// Check if tool has scripts
 	if !toolConfig.HasScripts() {
 		fmt.Printf("Tool '%s' has no scripts configured\n", toolName)
-		return nil
+		return nil, nil
 	}
 
 	// Get environment variables
 	rootConfigPath := repo.GetRootMerlinConfig()
 	rootConfig, err := parser.ParseRootMerlinTOML(rootConfigPath)
 	if err != nil {
-		return fmt.Errorf("failed to parse root config: %w", err)
+		return nil, fmt.Errorf("failed to parse root config: %w", err)
 	}
 
 	vars, err := symlink.GetVariablesFromRoot(rootConfig)
 	if err != nil {
-		return fmt.Errorf("failed to get variables: %w", err)
+		return nil, fmt.Errorf("failed to get variables: %w", err)
 	}
 
 	// Create environment for scripts
@@ -111,11 +137,11 @@ func runToolScripts(toolName string, dryRun, verbose bool) error {
 	}
 	fmt.Println()
 
-	if verbose {
+	if verbosity.Items() {
 		fmt.Printf("  Script directory: %s\n", toolConfig.Scripts.Directory)
 		fmt.Printf("  Scripts to run: %d\n", len(toolConfig.Scripts.Scripts))
 		for i, script := range toolConfig.Scripts.Scripts {
-			fmt.Printf("    %d. %s\n", i+1, script)
+			fmt.Printf("    %d. %s\n", i+1, script.File)
 		}
 		fmt.Println()
 	}
@@ -126,29 +152,38 @@ func runToolScripts(toolName string, dryRun, verbose bool) error {
 		for _, err := range errors {
 			fmt.Printf("  - %s\n", err)
 		}
-		return fmt.Errorf("script validation failed")
+		return nil, fmt.Errorf("script validation failed")
+	}
+
+	if err := ensureScriptsTrusted(toolName, toolRoot, toolConfig, dryRun); err != nil {
+		return nil, err
 	}
 
 	// Run scripts
-	runner := scripts.NewScriptRunner(toolRoot, env, dryRun, verbose, os.Stdout)
+	runner := scripts.NewScriptRunner(toolRoot, env, dryRun, verbosity, os.Stdout)
+	runner.KeepGoing = scriptsKeepGoing
+	if err := configureScriptParams(runner); err != nil {
+		return nil, err
+	}
 	results, err := runner.RunScripts(toolConfig)
 	if err != nil {
-		return fmt.Errorf("failed to run scripts: %w", err)
+		return nil, fmt.Errorf("failed to run scripts: %w", err)
 	}
 
 	// Display results
-	if !verbose {
-		fmt.Println()
-		for _, result := range results {
-			fmt.Println(scripts.FormatScriptResult(result, verbose))
-		}
+	fmt.Println()
+	for _, result := range results {
+		fmt.Println(scripts.FormatScriptResult(result, verbosity))
 	}
 
 	// Summary
 	successCount := 0
 	failureCount := 0
+	skippedCount := 0
 	for _, result := range results {
-		if result.Success {
+		if result.Skipped {
+			skippedCount++
+		} else if result.Success {
 			successCount++
 		} else {
 			failureCount++
@@ -156,16 +191,30 @@ func runToolScripts(toolName string, dryRun, verbose bool) error {
 	}
 
 	fmt.Println()
+	if timings := scripts.ScriptTimings(results); len(timings.Items) > 0 {
+		fmt.Println(cli.Dim(timings.Summary("ran", "script(s)")))
+	}
 	if failureCount > 0 {
-		fmt.Printf("Summary: %d succeeded, %d failed\n", successCount, failureCount)
-		return fmt.Errorf("some scripts failed")
+		fmt.Printf("Summary: %d succeeded, %d failed", successCount, failureCount)
+		if skippedCount > 0 {
+			fmt.Printf(", %d skipped after failure (use --keep-going or on_error = \"continue\" to run them)", skippedCount)
+		}
+		fmt.Println()
+		fmt.Printf("View output with: merlin scripts logs %s\n", toolName)
+		return results, fmt.Errorf("some scripts failed")
+	} else if skippedCount > 0 {
+		fmt.Printf("Summary: %d previewed, %d skipped (no dry_run_supported)\n", successCount, skippedCount)
 	} else {
 		fmt.Printf("Summary: All %d scripts completed successfully\n", successCount)
 	}
 
 	if dryRun {
-		fmt.Println("\nThis was a dry run. No scripts were executed.")
+		if successCount > 0 {
+			fmt.Println("\nThis was a dry run. Only scripts with dry_run_supported ran (with MERLIN_DRY_RUN=1).")
+		} else {
+			fmt.Println("\nThis was a dry run. No scripts were executed.")
+		}
 	}
 
-	return nil
+	return results, nil
 }
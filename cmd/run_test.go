@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ildx/merlin/internal/cli"
+	"github.com/ildx/merlin/internal/installer/installertest"
+)
+
+// A dry run still executes dry_run_supported scripts, so an unapproved one
+// must not run until it is trusted
+func TestRunToolScriptsDryRunRequiresTrust(t *testing.T) {
+	s := installertest.NewSandbox(t)
+	marker := filepath.Join(s.Home, "ran")
+	script := s.WriteFile("config/demo/scripts/preview.sh", "#!/bin/sh\necho \"$MERLIN_DRY_RUN\" > "+marker+"\n")
+	if err := os.Chmod(script, 0755); err != nil {
+		t.Fatal(err)
+	}
+	s.WriteFile("config/demo/merlin.toml", "[tool]\nname = \"demo\"\n\n[scripts]\nscripts = [{ file = \"preview.sh\", dry_run_supported = true }]\n")
+
+	decline := s.WriteHomeFile("stdin", "n\n")
+	stdin, err := os.Open(decline)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdin.Close()
+	savedStdin, savedStdout := os.Stdin, os.Stdout
+	os.Stdin = stdin
+	if os.Stdout, err = os.Create(filepath.Join(s.Home, "stdout")); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { os.Stdin, os.Stdout = savedStdin, savedStdout }()
+
+	if _, err := runToolScripts("demo", true, cli.VerbosityNormal); err == nil || !strings.Contains(err.Error(), "not trusted") {
+		t.Fatalf("runToolScripts() error = %v, want not trusted", err)
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Fatalf("declined script ran under --dry-run (stat err = %v)", err)
+	}
+
+	scriptsTrustAll = true
+	defer func() { scriptsTrustAll = false }()
+	if _, err := runToolScripts("demo", true, cli.VerbosityNormal); err != nil {
+		t.Fatalf("runToolScripts() with --trust-all error = %v", err)
+	}
+	if data, err := os.ReadFile(marker); err != nil || strings.TrimSpace(string(data)) != "1" {
+		t.Fatalf("trusted script output = %q, %v; want MERLIN_DRY_RUN=1", data, err)
+	}
+}
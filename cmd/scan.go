@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/ildx/merlin/internal/cli"
+	"github.com/ildx/merlin/internal/config"
+	"github.com/ildx/merlin/internal/migrate"
+	"github.com/ildx/merlin/internal/models"
+	"github.com/ildx/merlin/internal/parser"
+	"github.com/ildx/merlin/internal/symlink"
+	"github.com/spf13/cobra"
+)
+
+var scanCmd = &cobra.Command{
+	Use:   "scan",
+	Short: "Find dotfiles in your home directory that merlin doesn't manage",
+	Long: `Walk your home directory for classic dotfiles and config directories that
+are not managed by merlin, and suggest how to adopt each one.
+
+BEHAVIOR
+	Hidden entries in $HOME and every entry of ~/.config are candidates.
+	Anything that is a symlink into the dotfiles repository, a declared link
+	target, or a directory containing one is managed and left out. Caches,
+	history files, secrets (~/.ssh, ~/.gnupg, ...) and the macOS user folders
+	are ignored by default. Nothing is modified.
+
+	Depth and extra ignore patterns can be set under [settings.scan] in the
+	root merlin.toml:
+
+	    [settings.scan]
+	    depth = 3
+	    ignore = [".android", ".config/Code"]
+
+FLAGS
+	--depth <n>         Directory levels below $HOME to search (default 2)
+	--ignore <pattern>  Skip entries matching a name or ~-relative path
+	                    pattern (repeatable)
+	--json              Output JSON instead of text
+
+EXAMPLES
+	merlin scan
+	merlin scan --depth 3 --ignore '.android'
+	merlin scan --json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runScan(cmd); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(scanCmd)
+	scanCmd.Flags().Int("depth", 0, "Directory levels below $HOME to search (default 2)")
+	scanCmd.Flags().StringArray("ignore", nil, "Skip entries matching this name or path pattern (repeatable)")
+	scanCmd.Flags().Bool("json", false, "Output JSON instead of human-readable text")
+}
+
+func runScan(cmd *cobra.Command) error {
+	depth, _ := cmd.Flags().GetInt("depth")
+	ignore, _ := cmd.Flags().GetStringArray("ignore")
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("get home directory: %w", err)
+	}
+	opts := migrate.ScanOptions{HomeDir: home, Depth: depth, Ignore: ignore}
+
+	// Without a repository everything found is unmanaged
+	if repo, err := config.FindDotfilesRepo(); err == nil {
+		opts.RepoRoot = repo.Root
+		if rootConfig, err := parser.ParseRootMerlinTOML(repo.GetRootMerlinConfig()); err == nil {
+			if opts.Depth == 0 {
+				opts.Depth = rootConfig.Settings.Scan.Depth
+			}
+			opts.Ignore = append(opts.Ignore, rootConfig.Settings.Scan.Ignore...)
+			if opts.Managed, err = declaredTargets(repo, rootConfig); err != nil {
+				return err
+			}
+		}
+	}
+
+	findings, err := migrate.Scan(opts)
+	if err != nil {
+		return fmt.Errorf("scan %s: %w", home, err)
+	}
+
+	if asJSON {
+		if findings == nil {
+			findings = []migrate.Finding{}
+		}
+		data, err := json.MarshalIndent(findings, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(findings) == 0 {
+		cli.Success("No unmanaged dotfiles found in %s", home)
+		return nil
+	}
+
+	fmt.Printf("Found %d unmanaged dotfile(s) in %s:\n\n", len(findings), home)
+	table := newTable(cmd, "PATH", "KIND", "ADOPT WITH").TruncateMiddle(0).Fixed(1)
+	for _, f := range findings {
+		kind := "file"
+		if f.IsDir {
+			kind = fmt.Sprintf("dir (%d files)", f.Files)
+		}
+		table.AddRow("~/"+f.Rel, kind, adoptCommand(f))
+	}
+	table.Render(os.Stdout)
+	fmt.Println()
+	fmt.Println(cli.Dim("Preview an adoption with --dry-run; hide entries with --ignore or [settings.scan] ignore."))
+	return nil
+}
+
+// declaredTargets returns the link targets of every tool, disabled ones
+// included: their files are declared even while they aren't linked
+func declaredTargets(repo *config.DotfilesRepo, rootConfig *models.RootMerlinConfig) ([]string, error) {
+	vars, err := symlink.GetVariablesFromRoot(rootConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get variables: %w", err)
+	}
+	tools, err := symlink.DiscoverTools(repo, vars)
+	if err != nil {
+		return nil, fmt.Errorf("discovering tools: %w", err)
+	}
+	for _, name := range symlink.DisabledTools(repo) {
+		if tool, err := symlink.DiscoverToolConfig(repo, name, vars); err == nil {
+			tools = append(tools, tool)
+		}
+	}
+
+	var targets []string
+	for _, tool := range tools {
+		for _, link := range tool.Links {
+			targets = append(targets, link.Target)
+		}
+	}
+	return targets, nil
+}
+
+// adoptCommand is the suggested `merlin adopt` invocation for a finding
+func adoptCommand(f migrate.Finding) string {
+	return fmt.Sprintf("merlin adopt ~/%s --tool %s", f.Rel, f.Tool)
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/ildx/merlin/internal/cli"
+	"github.com/ildx/merlin/internal/schema"
+	"github.com/spf13/cobra"
+)
+
+var schemaCmd = &cobra.Command{
+	Use:       "schema <root|tool|brew|mas>",
+	Short:     "Print JSON Schema for merlin's TOML files",
+	ValidArgs: schema.Kinds(),
+	Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	Long: `Print a JSON Schema document for one of merlin's TOML formats, for editor
+validation and autocomplete (e.g. Even Better TOML in VS Code or Cursor).
+
+The schemas are generated from the same Go types the parser decodes into, so
+they always match what this version of merlin accepts.
+
+SCHEMAS
+	root   merlin.toml at the repository root
+	tool   config/<tool>/merlin.toml
+	brew   config/brew/brew.toml (and brew.d/*.toml)
+	mas    config/mas/mas.toml
+
+EXAMPLES
+	merlin schema tool > schemas/merlin-tool.json
+	merlin schema root > schemas/merlin-root.json
+
+	Then reference it from the top of a TOML file:
+	#:schema ../../schemas/merlin-tool.json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runSchema(args[0]); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+}
+
+func runSchema(kind string) error {
+	doc, err := schema.Generate(kind)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode schema: %w", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
@@ -0,0 +1,396 @@
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"strings"
+
+	"github.com/ildx/merlin/internal/cli"
+	"github.com/ildx/merlin/internal/config"
+	"github.com/ildx/merlin/internal/models"
+	"github.com/ildx/merlin/internal/parser"
+	"github.com/ildx/merlin/internal/scripts"
+	"github.com/ildx/merlin/internal/symlink"
+	"github.com/ildx/merlin/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+// scriptsTrustAll skips trust prompts for run and link --run-scripts (CI use)
+var scriptsTrustAll bool
+
+// scriptsKeepGoing runs remaining scripts after a failure, overriding on_error
+var scriptsKeepGoing bool
+
+// scriptsParams are --param name=value values for scripts declaring params
+var scriptsParams []string
+
+var scriptsCmd = &cobra.Command{
+	Use:   "scripts",
+	Short: "Run and manage tool setup scripts",
+	Long: `Run, inspect and approve the setup scripts defined in tool merlin.toml files.
+
+INTERACTIVE
+	Without a subcommand, merlin scripts opens a picker: choose a tool, then
+	its scripts (tags are shown next to each), and watch them run. When a
+	script fails, its log is shown at the end.
+
+TRUST
+	Scripts run with your full user privileges. Before a script runs for the
+	first time, or after its content changes, merlin shows the script body and
+	asks for confirmation. Approved content hashes are stored in
+	~/.merlin/trusted_scripts.json.
+
+LOGS
+	Every run's stdout/stderr is saved under
+	~/.merlin/logs/scripts/<tool>/<script>-<timestamp>.log
+
+EXAMPLES
+	merlin scripts                  # Pick a tool and scripts to run
+	merlin scripts run --all --tags post-install
+	merlin scripts trust cursor     # Approve cursor's current scripts
+	merlin run cursor --trust-all   # Skip prompts (CI)
+	merlin scripts logs cursor      # Output of cursor's last script runs
+	merlin scripts logs cursor install_extensions.sh`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !stdinIsTerminal() {
+			cmd.Help()
+			return
+		}
+		if err := tui.LaunchScriptRunner(); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var scriptsTrustCmd = &cobra.Command{
+	Use:   "trust <tool>",
+	Short: "Approve the current content of a tool's scripts",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runScriptsTrust(args[0]); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var scriptsLogsCmd = &cobra.Command{
+	Use:   "logs <tool> [script]",
+	Short: "Show output from the last run of a tool's scripts",
+	Args:  cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		script := ""
+		if len(args) == 2 {
+			script = args[1]
+		}
+		if err := runScriptsLogs(args[0], script); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var scriptsRunCmd = &cobra.Command{
+	Use:   "run --all [--tags tag,...]",
+	Short: "Run tagged scripts across every tool",
+	Long: `Run the scripts of every enabled tool that carry one of the given tags,
+e.g. all font-cache refreshers.
+
+BEHAVIOR
+	Tools run in dependency order: a tool runs after the tools named in its
+	[tool] dependencies. Within a tool, scripts keep their merlin.toml order.
+	Every matched script runs; failures are listed at the end. Without
+	--tags, all scripts of all tools run.
+
+	On a terminal the run shows a progress display, and failed scripts' logs
+	are shown at the end. Otherwise, and with --dry-run or -vv, results are
+	printed per tool.
+
+FLAGS
+	--all         Gather scripts from every tool (required)
+	--tags        Only scripts with at least one of these tags
+	--trust-all   Run new or changed scripts without confirmation (CI)
+	--param name=value
+	              Value for a script param (repeatable)
+
+EXAMPLES
+	merlin scripts run --all --tags post-install
+	merlin scripts run --all --tags fonts --dry-run`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		all, _ := cmd.Flags().GetBool("all")
+		if !all {
+			cli.Error("specify --all (use 'merlin run <tool>' for a single tool)")
+			os.Exit(1)
+		}
+		tags, _ := cmd.Flags().GetStringSlice("tags")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		if err := runScriptsAll(tags, dryRun, verbosityLevel(cmd)); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(scriptsCmd)
+	scriptsCmd.AddCommand(scriptsTrustCmd)
+	scriptsCmd.AddCommand(scriptsRunCmd)
+	scriptsRunCmd.Flags().Bool("all", false, "Gather scripts from every tool")
+	scriptsRunCmd.Flags().StringSlice("tags", nil, "Only run scripts with one of these tags")
+	scriptsRunCmd.Flags().BoolVar(&scriptsTrustAll, "trust-all", false, "Run new or changed scripts without confirmation")
+	scriptsRunCmd.Flags().StringArrayVar(&scriptsParams, "param", nil, "Value for a script param as name=value (repeatable)")
+	scriptsCmd.AddCommand(scriptsLogsCmd)
+}
+
+// scriptJobs gathers the scripts tagged with one of tags from every enabled
+// tool, in tool dependency order. Each tool's scripts are validated and
+// trusted, and their params resolved, before anything runs.
+func scriptJobs(tags []string, dryRun bool, verbosity cli.Verbosity) ([]tui.ScriptJob, error) {
+	repo, err := config.FindDotfilesRepo()
+	if err != nil {
+		return nil, fmt.Errorf("dotfiles repository not found: %w", err)
+	}
+	rootConfig, err := parser.ParseRootMerlinTOML(repo.GetRootMerlinConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse root config: %w", err)
+	}
+	vars, err := symlink.GetVariablesFromRoot(rootConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get variables: %w", err)
+	}
+	tools, err := symlink.DiscoverTools(repo, vars)
+	if err != nil {
+		return nil, err
+	}
+	if tools, err = symlink.SortByDependencies(tools); err != nil {
+		return nil, err
+	}
+
+	var jobs []tui.ScriptJob
+	for _, tool := range tools {
+		if !tool.HasMerlinTOML {
+			continue
+		}
+		toolConfig, err := parser.ParseToolMerlinTOML(repo.GetToolMerlinConfig(tool.Name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s merlin.toml: %w", tool.Name, err)
+		}
+		items := toolConfig.FilterScriptsByTag(tags)
+		if len(items) == 0 {
+			continue
+		}
+
+		// Validation and trust only concern the selected scripts
+		selected := *toolConfig
+		selected.Scripts.Scripts = items
+		if errs := scripts.ValidateScripts(tool.ToolRoot, &selected); len(errs) > 0 {
+			fmt.Printf("\n⚠️  Script validation errors in %s:\n", tool.Name)
+			for _, err := range errs {
+				fmt.Printf("  - %s\n", err)
+			}
+			return nil, fmt.Errorf("script validation failed")
+		}
+		if err := ensureScriptsTrusted(tool.Name, tool.ToolRoot, &selected, dryRun); err != nil {
+			return nil, err
+		}
+
+		env := scripts.GetDefaultEnvironment(tool.ToolRoot, tool.Name, vars.HomeDir, vars.ConfigDir)
+		runner := scripts.NewScriptRunner(tool.ToolRoot, env, dryRun, verbosity, os.Stdout)
+		if err := configureScriptParams(runner); err != nil {
+			return nil, err
+		}
+		// Params are asked for up front so prompts don't interrupt the run
+		if !dryRun {
+			for _, item := range items {
+				values, err := scripts.ResolveParams(item, runner.Params, runner.Prompt)
+				if err != nil {
+					return nil, err
+				}
+				maps.Copy(runner.Params, values)
+			}
+			runner.Prompt = nil
+		}
+
+		scriptDir := scripts.ScriptDirectory(tool.ToolRoot, toolConfig)
+		for _, item := range items {
+			jobs = append(jobs, tui.ScriptJob{Tool: tool.Name, ScriptDir: scriptDir, SharedEnv: toolConfig.Scripts.Env, Runner: runner, Script: item})
+		}
+	}
+	return jobs, nil
+}
+
+// runScriptsAll runs the tagged scripts of every tool, with the progress
+// display on a terminal and as plain output otherwise
+func runScriptsAll(tags []string, dryRun bool, verbosity cli.Verbosity) error {
+	jobs, err := scriptJobs(tags, dryRun, verbosity)
+	if err != nil {
+		return err
+	}
+	what := "scripts"
+	if len(tags) > 0 {
+		what = fmt.Sprintf("scripts tagged %s", strings.Join(tags, ", "))
+	}
+	if len(jobs) == 0 {
+		cli.Info("No %s found", what)
+		return nil
+	}
+
+	if stdinIsTerminal() && !dryRun && !verbosity.Stream() {
+		title := fmt.Sprintf("📜 Running %s (%d)", what, len(jobs))
+		failed, err := tui.RunScripts(tui.NewBatchScriptRunnerModel(title, jobs))
+		if err != nil {
+			return err
+		}
+		if len(failed) > 0 {
+			return fmt.Errorf("%d of %d script(s) failed", len(failed), len(jobs))
+		}
+		cli.Success("All %d script(s) completed", len(jobs))
+		return nil
+	}
+
+	fmt.Printf("Running %s: %d script(s)\n", what, len(jobs))
+	var results []*scripts.ScriptResult
+	failed := 0
+	for i, job := range jobs {
+		if i == 0 || jobs[i-1].Tool != job.Tool {
+			fmt.Printf("\n%s\n", job.Tool)
+		}
+		result := job.Runner.RunScriptItem(job.ScriptDir, job.Script, job.SharedEnv)
+		results = append(results, result)
+		if !result.Success && !result.Skipped {
+			failed++
+		}
+		fmt.Println(scripts.FormatScriptResult(result, verbosity))
+	}
+
+	fmt.Println()
+	if timings := scripts.ScriptTimings(results); len(timings.Items) > 0 {
+		fmt.Println(cli.Dim(timings.Summary("ran", "script(s)")))
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d script(s) failed", failed, len(jobs))
+	}
+	cli.Success("All %d script(s) completed", len(jobs))
+	return nil
+}
+
+func runScriptsLogs(toolName, script string) error {
+	logs, err := scripts.LatestLogs(toolName, script)
+	if err != nil {
+		return err
+	}
+	if len(logs) == 0 {
+		if script != "" {
+			fmt.Printf("No logs found for %s/%s\n", toolName, script)
+		} else {
+			fmt.Printf("No script logs found for %s\n", toolName)
+		}
+		fmt.Printf("\nRun scripts with: merlin run %s\n", toolName)
+		return nil
+	}
+
+	for i, l := range logs {
+		if i > 0 {
+			fmt.Println()
+		}
+		data, err := os.ReadFile(l.Path)
+		if err != nil {
+			return fmt.Errorf("read log %s: %w", l.Path, err)
+		}
+		fmt.Printf("📜 %s — %s\n", l.Script, l.RunAt.Format("2006-01-02 15:04:05"))
+		fmt.Printf("   %s\n", l.Path)
+		fmt.Println(strings.Repeat("─", 80))
+		fmt.Print(string(data))
+	}
+	return nil
+}
+
+func runScriptsTrust(toolName string) error {
+	repo, err := config.FindDotfilesRepo()
+	if err != nil {
+		return fmt.Errorf("dotfiles repository not found: %w", err)
+	}
+	if !repo.ToolExists(toolName) {
+		return fmt.Errorf("tool '%s' not found in dotfiles repository", toolName)
+	}
+
+	toolConfig, err := parser.ParseToolMerlinTOML(repo.GetToolMerlinConfig(toolName))
+	if err != nil {
+		return fmt.Errorf("failed to parse tool config: %w", err)
+	}
+	if !toolConfig.HasScripts() {
+		fmt.Printf("Tool '%s' has no scripts configured\n", toolName)
+		return nil
+	}
+
+	scriptDir := scripts.ScriptDirectory(repo.GetToolRoot(toolName), toolConfig)
+	approved, err := scripts.TrustAll(toolName, scriptDir, toolConfig.Scripts.Scripts)
+	if err != nil {
+		return err
+	}
+	if len(approved) == 0 {
+		cli.Success("All %s scripts are already trusted", toolName)
+		return nil
+	}
+	for _, u := range approved {
+		fmt.Printf("  ✓ %s (%s)\n", u.Script, u.State)
+	}
+	cli.Success("Trusted %d script(s) for %s", len(approved), toolName)
+	return nil
+}
+
+// ensureScriptsTrusted gates script execution on the trust store;
+// --trust-all approves them without prompting. Dry runs still execute scripts
+// with dry_run_supported, so those need approval as in a real run; the others
+// are only reported.
+func ensureScriptsTrusted(toolName, toolRoot string, toolConfig *models.ToolMerlinConfig, dryRun bool) error {
+	scriptDir := scripts.ScriptDirectory(toolRoot, toolConfig)
+	items := toolConfig.Scripts.Scripts
+
+	if dryRun {
+		var executed, previewed []models.ScriptItem
+		for _, item := range items {
+			if item.DryRunSupported {
+				executed = append(executed, item)
+			} else {
+				previewed = append(previewed, item)
+			}
+		}
+		store, err := scripts.LoadTrustStore()
+		if err != nil {
+			return err
+		}
+		untrusted, err := store.Untrusted(toolName, scriptDir, previewed)
+		if err != nil {
+			return err
+		}
+		for _, u := range untrusted {
+			fmt.Printf("  🔐 %s is %s and would require approval\n", u.Script, u.State)
+		}
+		items = executed
+	}
+
+	if scriptsTrustAll {
+		_, err := scripts.TrustAll(toolName, scriptDir, items)
+		return err
+	}
+	return scripts.ConfirmUntrusted(toolName, scriptDir, items, os.Stdin, os.Stdout)
+}
+
+// configureScriptParams gives runner the --param values and, when stdin is a
+// terminal, a prompt for the params they leave unset; otherwise defaults apply.
+func configureScriptParams(runner *scripts.ScriptRunner) error {
+	params, err := scripts.ParseParamFlags(scriptsParams)
+	if err != nil {
+		return err
+	}
+	runner.Params = params
+	if stdinIsTerminal() {
+		runner.Prompt = scripts.PromptParams(os.Stdin, os.Stdout)
+	}
+	return nil
+}
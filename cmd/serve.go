@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/ildx/merlin/internal/cli"
+	"github.com/ildx/merlin/internal/config"
+	"github.com/ildx/merlin/internal/server"
+	"github.com/spf13/cobra"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve a localhost JSON API for status, diff, link, install and backups",
+	Long: `Run an HTTP/JSON API on localhost so fleet tooling or a GUI can drive
+merlin without shelling out and parsing terminal output.
+
+BEHAVIOR
+	Every request needs "Authorization: Bearer <token>". The token is taken
+	from --token, then MERLIN_API_TOKEN, then ~/.merlin/serve.token, which is
+	generated (readable only by you) on first use.
+	Only loopback addresses are accepted for --addr.
+	Requests that change the system (link, install, backup, restore) run one
+	at a time. Errors are answered as {"error": "..."}.
+
+ENDPOINTS
+	GET  /v1/status                 Version, repository, machine and last drift
+	GET  /v1/diff[?offline=true]    Drift between the repository and the system
+	POST /v1/link                   {"tools": [...], "strategy": "skip", "dry_run": false}
+	POST /v1/install                {"kind": "brew"|"mas", "packages": [...], "dry_run": false}
+	GET  /v1/backups                List backups
+	POST /v1/backups                {"files": [...], "reason": "..."}
+	POST /v1/backups/{id}/restore   {"files": [...], "dry_run": false, "no_safety": false}
+
+	An empty tools or packages list means everything enabled or declared for
+	this machine.
+
+FLAGS
+	--addr    Address to listen on (default 127.0.0.1:7420)
+	--token   Token clients must send (overrides MERLIN_API_TOKEN and the token file)
+
+EXAMPLES
+	merlin serve
+	curl -H "Authorization: Bearer $(cat ~/.merlin/serve.token)" localhost:7420/v1/status
+	curl -H "Authorization: Bearer $TOKEN" -d '{"tools":["zsh"],"dry_run":true}' localhost:7420/v1/link`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runServe(cmd); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().String("addr", server.DefaultAddr, "address to listen on (loopback only)")
+	serveCmd.Flags().String("token", "", "token clients must send")
+}
+
+func runServe(cmd *cobra.Command) error {
+	addr, _ := cmd.Flags().GetString("addr")
+	token, _ := cmd.Flags().GetString("token")
+
+	if err := checkLoopback(addr); err != nil {
+		return err
+	}
+	repo, err := config.FindDotfilesRepo()
+	if err != nil {
+		return fmt.Errorf("dotfiles repository not found: %w", err)
+	}
+	if token == "" {
+		if token, err = server.LoadOrCreateToken(); err != nil {
+			return err
+		}
+	}
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           (&server.Server{Repo: repo, Token: token, Version: version}).Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("listen on %s: %w", addr, err)
+	}
+
+	cli.Success("Serving the merlin API on http://%s", listener.Addr())
+	if os.Getenv(server.TokenEnv) == "" && !cmd.Flags().Changed("token") {
+		if path, err := server.TokenPath(); err == nil {
+			fmt.Println(cli.Dim("Token: " + path))
+		}
+	}
+	fmt.Println(cli.Dim("Press Ctrl+C to stop"))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	errs := make(chan error, 1)
+	go func() { errs <- srv.Serve(listener) }()
+
+	select {
+	case err := <-errs:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("serve: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("shut down: %w", err)
+	}
+	fmt.Println("Stopped")
+	return nil
+}
+
+// checkLoopback refuses addresses reachable from other machines; the API can
+// change the system, and a token alone is not meant to guard a network port
+func checkLoopback(addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid address %q: %w", addr, err)
+	}
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+	return fmt.Errorf("refusing to listen on %s: only loopback addresses (127.0.0.1, ::1, localhost) are allowed", addr)
+}
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ildx/merlin/internal/cli"
+	"github.com/ildx/merlin/internal/config"
+	"github.com/ildx/merlin/internal/parser"
+	"github.com/ildx/merlin/internal/symlink"
+	"github.com/ildx/merlin/internal/system"
+	"github.com/spf13/cobra"
+)
+
+var suggestCmd = &cobra.Command{
+	Use:   "suggest",
+	Short: "Suggest tools to link based on what is installed",
+	Long: `Check which tools' programs are installed on this machine and compare that
+with what is linked.
+
+BEHAVIOR
+	A tool counts as installed when its [tool] command is found: a binary on
+	PATH, or an app bundle ("Name.app") in /Applications or ~/Applications.
+	Without command, a binary named like the tool is looked for.
+
+	Installed tools that aren't (fully) linked are listed with the shortest
+	merlin link command line that links them. Tools that are linked although
+	their program is missing are flagged: config without the app, which
+	'merlin unlink <tool>' or 'merlin tool disable <tool>' cleans up.
+	Disabled tools and tools without links are ignored.
+
+EXAMPLES
+	merlin suggest
+
+	# config/karabiner/merlin.toml
+	[tool]
+	name = "karabiner"
+	command = "Karabiner-Elements.app"`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runSuggest(cmd); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(suggestCmd)
+}
+
+// toolPresence is whether a tool's program is installed and its links are in place
+type toolPresence struct {
+	Tool      *symlink.ToolConfig
+	Installed bool
+	Path      string // where the program was found
+	Links     int
+	Linked    int
+}
+
+func runSuggest(cmd *cobra.Command) error {
+	repo, err := config.FindDotfilesRepo()
+	if err != nil {
+		return fmt.Errorf("dotfiles repository not found: %w", err)
+	}
+	rootConfig, err := parser.ParseRootMerlinTOML(repo.GetRootMerlinConfig())
+	if err != nil {
+		return fmt.Errorf("parse root config: %w", err)
+	}
+	vars, err := symlink.GetVariablesFromRoot(rootConfig)
+	if err != nil {
+		return fmt.Errorf("get variables: %w", err)
+	}
+	tools, err := symlink.DiscoverTools(repo, vars)
+	if err != nil {
+		return fmt.Errorf("discover tools: %w", err)
+	}
+
+	var enabled []string
+	var toLink, orphaned []toolPresence
+	for _, tool := range tools {
+		if tool.Disabled || len(tool.Links) == 0 {
+			continue
+		}
+		enabled = append(enabled, tool.Name)
+		p := toolPresence{Tool: tool}
+		p.Path, p.Installed = findToolProgram(tool)
+		p.Links, p.Linked, _ = symlink.CountLinks(tool)
+		switch {
+		case p.Installed && p.Linked < p.Links:
+			toLink = append(toLink, p)
+		case !p.Installed && p.Linked > 0:
+			orphaned = append(orphaned, p)
+		}
+	}
+
+	if len(toLink) == 0 && len(orphaned) == 0 {
+		cli.Success("Every installed tool is linked, and every linked tool is installed")
+		return nil
+	}
+
+	if len(toLink) > 0 {
+		fmt.Printf("Installed but not linked (%d):\n\n", len(toLink))
+		table := newTable(cmd, "TOOL", "FOUND", "LINKED").Fixed(0).Fixed(2).TruncateMiddle(1)
+		var names []string
+		for _, p := range toLink {
+			table.AddRow(p.Tool.Name, p.Path, fmt.Sprintf("%d/%d", p.Linked, p.Links))
+			names = append(names, p.Tool.Name)
+		}
+		table.Render(os.Stdout)
+		fmt.Printf("\nLink them with:\n  %s\n", linkCommandLine(names, enabled))
+	}
+
+	if len(orphaned) > 0 {
+		if len(toLink) > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("Linked but not installed (%d):\n\n", len(orphaned))
+		table := newTable(cmd, "TOOL", "LOOKED FOR", "LINKED").Fixed(0).Fixed(2)
+		for _, p := range orphaned {
+			table.AddRow(p.Tool.Name, toolProgram(p.Tool), fmt.Sprintf("%d/%d", p.Linked, p.Links))
+		}
+		table.Render(os.Stdout)
+		fmt.Println()
+		fmt.Println(cli.Dim("Install the program, set [tool] command if it has another name, or unlink the tool"))
+	}
+	return nil
+}
+
+// toolProgram is the binary or app bundle showing tool is installed
+func toolProgram(tool *symlink.ToolConfig) string {
+	if tool.Command != "" {
+		return tool.Command
+	}
+	return tool.Name
+}
+
+// findToolProgram looks for the tool's program on PATH, or for an app bundle
+// in the Applications folders, and returns where it was found
+func findToolProgram(tool *symlink.ToolConfig) (string, bool) {
+	program := toolProgram(tool)
+	if !strings.HasSuffix(program, ".app") {
+		check := system.CheckCommand(program)
+		return check.Path, check.Exists
+	}
+	dirs := []string{"/Applications"}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, "Applications"))
+	}
+	if filepath.IsAbs(program) {
+		dirs = []string{""}
+	}
+	for _, dir := range dirs {
+		path := filepath.Join(dir, program)
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// linkCommandLine returns the shortest merlin link command line linking
+// names: one link per tool, or --all skipping the other enabled tools
+func linkCommandLine(names, enabled []string) string {
+	var single []string
+	for _, name := range names {
+		single = append(single, "merlin link "+name)
+	}
+	perTool := strings.Join(single, " && ")
+
+	var except []string
+	for _, name := range enabled {
+		if !slices.Contains(names, name) {
+			except = append(except, name)
+		}
+	}
+	all := "merlin link --all --yes"
+	if len(except) > 0 {
+		all += " --except " + strings.Join(except, ",")
+	}
+	if len(names) > 1 && len(all) < len(perTool) {
+		return all
+	}
+	return perTool
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ildx/merlin/internal/symlink"
+)
+
+func TestLinkCommandLine(t *testing.T) {
+	enabled := []string{"git", "zsh", "nvim", "tmux", "karabiner"}
+	cases := []struct {
+		names []string
+		want  string
+	}{
+		{[]string{"zsh"}, "merlin link zsh"},
+		{[]string{"git", "zsh"}, "merlin link git && merlin link zsh"},
+		{[]string{"git", "zsh", "nvim", "tmux"}, "merlin link --all --yes --except karabiner"},
+	}
+	for _, c := range cases {
+		if got := linkCommandLine(c.names, enabled); got != c.want {
+			t.Errorf("linkCommandLine(%v) = %q, want %q", c.names, got, c.want)
+		}
+	}
+}
+
+func TestFindToolProgram(t *testing.T) {
+	bin := t.TempDir()
+	home := t.TempDir()
+	t.Setenv("PATH", bin)
+	t.Setenv("HOME", home)
+	os.WriteFile(filepath.Join(bin, "nvim"), []byte("#!/bin/sh\n"), 0755)
+	os.MkdirAll(filepath.Join(home, "Applications", "Karabiner-Elements.app"), 0755)
+
+	cases := []struct {
+		tool *symlink.ToolConfig
+		want bool
+	}{
+		{&symlink.ToolConfig{Name: "nvim"}, true},
+		{&symlink.ToolConfig{Name: "neovim", Command: "nvim"}, true},
+		{&symlink.ToolConfig{Name: "tmux"}, false},
+		{&symlink.ToolConfig{Name: "karabiner", Command: "Karabiner-Elements.app"}, true},
+		{&symlink.ToolConfig{Name: "raycast", Command: "Raycast.app"}, false},
+	}
+	for _, c := range cases {
+		if path, got := findToolProgram(c.tool); got != c.want {
+			t.Errorf("findToolProgram(%s) = %q, %v; want %v", c.tool.Name, path, got, c.want)
+		}
+	}
+}
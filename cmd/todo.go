@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/ildx/merlin/internal/cli"
+	"github.com/ildx/merlin/internal/config"
+	"github.com/ildx/merlin/internal/models"
+	"github.com/ildx/merlin/internal/parser"
+	"github.com/ildx/merlin/internal/state"
+	"github.com/ildx/merlin/internal/symlink"
+	"github.com/spf13/cobra"
+)
+
+var todoCmd = &cobra.Command{
+	Use:   "todo",
+	Short: "List the manual setup steps left on this machine",
+	Long: `Track the setup steps merlin can't automate.
+
+BEHAVIOR
+	Lists the [[manual_step]] entries of the root merlin.toml and of every
+	enabled tool that haven't been checked off on this machine. Root steps
+	are addressed by their id, a tool's steps as tool/id. Done/pending state
+	lives in ~/.merlin/state/todo.json, so each machine keeps its own list.
+
+SUBCOMMANDS
+	done <step>...   Check steps off
+	undo <step>...   Put steps back on the list
+
+FLAGS
+	--all    Include steps already done
+	--json   Print the steps as JSON
+
+EXAMPLES
+	merlin todo
+	merlin todo --all
+	merlin todo done karabiner/input-monitoring
+	merlin todo undo app-store`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runTodo(cmd); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var todoDoneCmd = &cobra.Command{
+	Use:   "done <step>...",
+	Short: "Check manual steps off",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runTodoMark(args, true); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var todoUndoCmd = &cobra.Command{
+	Use:   "undo <step>...",
+	Short: "Mark manual steps as pending again",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runTodoMark(args, false); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(todoCmd)
+	todoCmd.AddCommand(todoDoneCmd)
+	todoCmd.AddCommand(todoUndoCmd)
+	todoCmd.Flags().Bool("all", false, "Include steps already done")
+	todoCmd.Flags().Bool("json", false, "Print the steps as JSON")
+}
+
+// todoStep is a manual step with its address and state on this machine
+type todoStep struct {
+	Step        string     `json:"step"`           // id, or tool/id
+	Tool        string     `json:"tool,omitempty"` // empty for root steps
+	Description string     `json:"description"`
+	URL         string     `json:"url,omitempty"`
+	Done        bool       `json:"done"`
+	DoneAt      *time.Time `json:"done_at,omitempty"`
+}
+
+// collectManualSteps returns the root steps followed by each enabled tool's,
+// tools in name order
+func collectManualSteps() ([]todoStep, error) {
+	repo, err := config.FindDotfilesRepo()
+	if err != nil {
+		return nil, fmt.Errorf("dotfiles repository not found: %w", err)
+	}
+	rootConfig, err := parser.ParseRootMerlinTOML(repo.GetRootMerlinConfig())
+	if err != nil {
+		return nil, fmt.Errorf("error parsing root config: %w", err)
+	}
+	vars, err := symlink.GetVariablesFromRoot(rootConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error getting variables: %w", err)
+	}
+	tools, err := symlink.DiscoverTools(repo, vars)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(tools, func(i, j int) bool { return tools[i].Name < tools[j].Name })
+
+	var steps []todoStep
+	add := func(tool string, manual []models.ManualStep) {
+		for _, m := range manual {
+			address := m.ID
+			if tool != "" {
+				address = tool + "/" + m.ID
+			}
+			steps = append(steps, todoStep{Step: address, Tool: tool, Description: m.Description, URL: m.URL})
+		}
+	}
+	add("", rootConfig.ManualSteps)
+	for _, tool := range tools {
+		add(tool.Name, tool.ManualSteps)
+	}
+	return steps, nil
+}
+
+func runTodo(cmd *cobra.Command) error {
+	all, _ := cmd.Flags().GetBool("all")
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	steps, err := collectManualSteps()
+	if err != nil {
+		return err
+	}
+	todo, err := state.LoadTodoState()
+	if err != nil {
+		return err
+	}
+
+	listed := []todoStep{}
+	pending := 0
+	for _, step := range steps {
+		if at, ok := todo.Done[step.Step]; ok {
+			step.Done, step.DoneAt = true, &at
+		} else {
+			pending++
+		}
+		if all || !step.Done {
+			listed = append(listed, step)
+		}
+	}
+
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(listed)
+	}
+
+	if len(steps) == 0 {
+		cli.Info("No [[manual_step]] entries are declared")
+		return nil
+	}
+	if pending == 0 && !all {
+		cli.Success("All %d manual step(s) are done", len(steps))
+		return nil
+	}
+
+	width := 0
+	for _, step := range listed {
+		width = max(width, len(step.Step))
+	}
+	fmt.Printf("\n📋 Manual steps (%d of %d pending):\n\n", pending, len(steps))
+	for _, step := range listed {
+		mark := "[ ]"
+		if step.Done {
+			mark = "[x]"
+		}
+		fmt.Printf("  %s %-*s  %s\n", mark, width, step.Step, step.Description)
+		if step.URL != "" {
+			fmt.Printf("      %-*s  %s\n", width, "", cli.Dim(step.URL))
+		}
+	}
+	if pending > 0 {
+		fmt.Println("\nCheck a step off with 'merlin todo done <step>'.")
+	}
+	return nil
+}
+
+// runTodoMark checks the given steps off (done) or puts them back on the list
+func runTodoMark(addresses []string, done bool) error {
+	steps, err := collectManualSteps()
+	if err != nil {
+		return err
+	}
+	known := make(map[string]bool, len(steps))
+	for _, step := range steps {
+		known[step.Step] = true
+	}
+	for _, address := range addresses {
+		if !known[address] {
+			return fmt.Errorf("unknown manual step '%s' (see 'merlin todo --all')", address)
+		}
+	}
+
+	todo, err := state.LoadTodoState()
+	if err != nil {
+		return err
+	}
+	for _, address := range addresses {
+		if done {
+			todo.MarkDone(address)
+			cli.Success("Done: %s", address)
+		} else {
+			todo.MarkPending(address)
+			cli.Success("Pending: %s", address)
+		}
+	}
+	if err := todo.Save(); err != nil {
+		return fmt.Errorf("failed to save todo state: %w", err)
+	}
+	return nil
+}
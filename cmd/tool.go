@@ -0,0 +1,561 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ildx/merlin/internal/backup"
+	"github.com/ildx/merlin/internal/cli"
+	"github.com/ildx/merlin/internal/config"
+	"github.com/ildx/merlin/internal/git"
+	"github.com/ildx/merlin/internal/models"
+	"github.com/ildx/merlin/internal/parser"
+	"github.com/ildx/merlin/internal/protect"
+	"github.com/ildx/merlin/internal/symlink"
+	"github.com/spf13/cobra"
+)
+
+var toolCmd = &cobra.Command{
+	Use:   "tool",
+	Short: "Manage tools in the dotfiles repository",
+	Long: `Manage individual tools under config/.
+
+SUBCOMMANDS
+	disable <name>   Set enabled = false in the tool's merlin.toml
+	enable <name>    Remove enabled = false again
+	remove <name>    Unlink a tool and delete it from the repository
+	rename <old> <new>  Rename a tool, keeping its links working
+
+BEHAVIOR
+	A disabled tool stays in the repository but is skipped by discovery:
+	link --all, diff and validate ignore it and report it as disabled, and
+	'merlin link <name>' refuses to link it. Existing links are left in place;
+	remove them with 'merlin unlink <name>'.
+
+	Tools without a merlin.toml get one declaring their default link
+	(config/ → {config_dir}/<name>), so enabling them again keeps behavior.
+
+EXAMPLES
+	merlin tool disable alacritty
+	merlin tool enable alacritty
+	merlin tool remove alacritty --archive
+	merlin tool rename vim nvim`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+var toolDisableCmd = &cobra.Command{
+	Use:   "disable <name>",
+	Short: "Exclude a tool from linking without deleting it",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runToolSetEnabled(cmd, args[0], false); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var toolEnableCmd = &cobra.Command{
+	Use:   "enable <name>",
+	Short: "Re-enable a disabled tool",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runToolSetEnabled(cmd, args[0], true); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var toolRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Unlink a tool and delete it from the repository",
+	Long: `Remove a tool completely, in one step.
+
+BEHAVIOR
+	1. The tool's links are removed, as 'merlin unlink <name>' does.
+	2. With --delete-config, what is left at its targets afterwards is
+	   deleted too: directories contents links filled and files that were
+	   never links. The files are backed up first ('merlin backup list').
+	   Targets another tool links to, and {home_dir}, {config_dir} and
+	   {bin_dir} themselves, are never deleted.
+	3. config/<name> is deleted, or moved to .archive/<name> with --archive.
+	4. The tool is dropped from the tools of every [[profile]] in
+	   merlin.toml, keeping the rest of the file as written.
+	5. The removal is committed, unless --no-commit or other changes in the
+	   repository would end up in the commit (see --commit-anyway).
+
+	Tools that list it in their dependencies are reported, not changed.
+
+FLAGS
+	--delete-config   Also delete what's left at the tool's targets
+	--archive         Move config/<name> to .archive/<name> instead of deleting it
+	--no-commit       Leave the changes uncommitted
+	--commit-anyway   Commit even if the repository has unrelated changes
+	-y, --yes         Don't ask for confirmation
+	--dry-run         Show what would be removed
+
+EXAMPLES
+	merlin tool remove alacritty --dry-run
+	merlin tool remove alacritty --archive
+	merlin tool remove nvim --delete-config -y`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runToolRemove(cmd, args[0]); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var toolRenameCmd = &cobra.Command{
+	Use:   "rename <old> <new>",
+	Short: "Rename a tool, keeping its links working",
+	Long: `Rename a tool in the repository and on this machine.
+
+BEHAVIOR
+	1. If the tool is linked, its links are removed while they still
+	   point at config/<old>.
+	2. config/<old> is moved to config/<new> and the name in its
+	   merlin.toml is updated. A tool without merlin.toml gets one keeping
+	   its default target ({config_dir}/<old>), so the app still finds its
+	   config; targets are never renamed.
+	3. <old> is renamed in the tools of every [[profile]] in merlin.toml
+	   and in the dependencies of other tools.
+	4. If it was linked, the tool is linked again under its new name, so
+	   the links point at config/<new>.
+	5. The rename is committed, unless --no-commit or other changes in the
+	   repository would end up in the commit (see --commit-anyway).
+
+FLAGS
+	--no-commit       Leave the changes uncommitted
+	--commit-anyway   Commit even if the repository has unrelated changes
+	--dry-run         Show what would change
+
+EXAMPLES
+	merlin tool rename vim nvim --dry-run
+	merlin tool rename vim nvim`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runToolRename(cmd, args[0], args[1]); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(toolCmd)
+	toolCmd.AddCommand(toolDisableCmd)
+	toolCmd.AddCommand(toolEnableCmd)
+	toolCmd.AddCommand(toolRemoveCmd)
+	toolCmd.AddCommand(toolRenameCmd)
+
+	toolRemoveCmd.Flags().Bool("delete-config", false, "Also delete what's left at the tool's targets")
+	toolRemoveCmd.Flags().Bool("archive", false, "Move the tool to .archive/ instead of deleting it")
+	toolRemoveCmd.Flags().Bool("no-commit", false, "Leave the changes uncommitted")
+	toolRemoveCmd.Flags().Bool("commit-anyway", false, "Commit even if the repository has unrelated changes")
+	toolRemoveCmd.Flags().BoolP("yes", "y", false, "Don't ask for confirmation")
+
+	toolRenameCmd.Flags().Bool("no-commit", false, "Leave the changes uncommitted")
+	toolRenameCmd.Flags().Bool("commit-anyway", false, "Commit even if the repository has unrelated changes")
+}
+
+func runToolSetEnabled(cmd *cobra.Command, toolName string, enabled bool) error {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	repo, err := config.FindDotfilesRepo()
+	if err != nil {
+		return fmt.Errorf("dotfiles repository not found: %w", err)
+	}
+	if !repo.ToolExists(toolName) {
+		return fmt.Errorf("tool '%s' not found in dotfiles repository", toolName)
+	}
+
+	state := "enabled"
+	if !enabled {
+		state = "disabled"
+	}
+
+	merlinPath := repo.GetToolMerlinConfig(toolName)
+	data, err := os.ReadFile(merlinPath)
+	switch {
+	case os.IsNotExist(err) && enabled:
+		cli.Info("%s is already enabled", toolName)
+		return nil
+	case os.IsNotExist(err):
+		data = []byte(defaultToolTOML(repo, toolName, toolName))
+	case err != nil:
+		return fmt.Errorf("read %s: %w", merlinPath, err)
+	default:
+		current, err := parser.ParseToolMerlinTOML(merlinPath)
+		if err != nil {
+			return err
+		}
+		if current.IsEnabled() == enabled {
+			cli.Info("%s is already %s", toolName, state)
+			return nil
+		}
+	}
+
+	updated := parser.SetToolEnabled(string(data), toolName, enabled)
+
+	if dryRun {
+		cli.Info("Would mark %s as %s in config/%s/merlin.toml", toolName, state, toolName)
+		return nil
+	}
+	if err := os.WriteFile(merlinPath, []byte(updated), 0644); err != nil {
+		return fmt.Errorf("write %s: %w", merlinPath, err)
+	}
+
+	if enabled {
+		cli.Success("Enabled %s", toolName)
+		fmt.Printf("  Link it again with: merlin link %s\n", toolName)
+	} else {
+		cli.Success("Disabled %s (config/%s is kept)", toolName, toolName)
+		fmt.Printf("  Existing links are left in place; remove them with: merlin unlink %s\n", toolName)
+	}
+	return nil
+}
+
+// defaultToolTOML declares the implicit config/ → {config_dir}/<target> link
+// explicitly, since a merlin.toml without links would link nothing. target is
+// the tool's name, or its previous name after a rename.
+func defaultToolTOML(repo *config.DotfilesRepo, toolName, target string) string {
+	content := fmt.Sprintf("[tool]\nname = %q\n", toolName)
+	if info, err := os.Stat(repo.GetToolConfigDir(toolName)); err == nil && info.IsDir() {
+		content += fmt.Sprintf("\n[[link]]\ntarget = \"{config_dir}/%s\"\n", target)
+	}
+	return content
+}
+
+// archiveDir is where tool remove --archive moves tools, relative to the
+// repository root; hidden, so flat layouts don't discover it as a tool
+const archiveDir = ".archive"
+
+func runToolRemove(cmd *cobra.Command, toolName string) error {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	deleteConfig, _ := cmd.Flags().GetBool("delete-config")
+	archive, _ := cmd.Flags().GetBool("archive")
+	noCommit, _ := cmd.Flags().GetBool("no-commit")
+	commitAnyway, _ := cmd.Flags().GetBool("commit-anyway")
+	yes, _ := cmd.Flags().GetBool("yes")
+
+	repo, err := config.FindDotfilesRepo()
+	if err != nil {
+		return fmt.Errorf("dotfiles repository not found: %w", err)
+	}
+	if !repo.ToolExists(toolName) {
+		return fmt.Errorf("tool '%s' not found in dotfiles repository", toolName)
+	}
+	rootPath := repo.GetRootMerlinConfig()
+	rootConfig, err := parser.ParseRootMerlinTOML(rootPath)
+	if err != nil {
+		return fmt.Errorf("parsing root config: %w", err)
+	}
+	vars, err := symlink.GetVariablesFromRoot(rootConfig)
+	if err != nil {
+		return fmt.Errorf("getting variables: %w", err)
+	}
+
+	toolRoot := repo.GetToolRoot(toolName)
+	archived := filepath.Join(repo.Root, archiveDir, toolName)
+	if archive {
+		if _, err := os.Lstat(archived); err == nil {
+			return fmt.Errorf("%s already exists; move it away first", repo.Rel(archived))
+		}
+	}
+	var profiles []string
+	for _, p := range rootConfig.Profiles {
+		if slices.Contains(p.Tools, toolName) {
+			profiles = append(profiles, p.Name)
+		}
+	}
+
+	fmt.Printf("Removing %s:\n", toolName)
+	fmt.Printf("  • unlink its links\n")
+	if deleteConfig {
+		fmt.Printf("  • delete what's left at its targets (backed up first)\n")
+	}
+	if archive {
+		fmt.Printf("  • move %s to %s\n", repo.Rel(toolRoot), repo.Rel(archived))
+	} else {
+		fmt.Printf("  • delete %s\n", repo.Rel(toolRoot))
+	}
+	if len(profiles) > 0 {
+		fmt.Printf("  • drop it from profiles: %s\n", strings.Join(profiles, ", "))
+	}
+	others, err := symlink.DiscoverTools(repo, vars)
+	if err != nil {
+		return fmt.Errorf("discovering tools: %w", err)
+	}
+	for _, other := range others {
+		if slices.Contains(other.Dependencies, toolName) {
+			cli.Warning("%s lists %s in its dependencies; update config/%s/merlin.toml", other.Name, toolName, other.Name)
+		}
+	}
+
+	if !yes && !dryRun {
+		fmt.Print("\n⚠️  Continue? [y/N]: ")
+		var response string
+		fmt.Scanln(&response)
+		response = strings.ToLower(strings.TrimSpace(response))
+		if response != "y" && response != "yes" {
+			fmt.Println("Removal cancelled.")
+			return nil
+		}
+	}
+	fmt.Println()
+
+	tool, err := unlinkTool(repo, toolName, vars, dryRun, verbosityLevel(cmd))
+	if err != nil {
+		return err
+	}
+
+	if deleteConfig {
+		if err := deleteLeftovers(tool, others, vars, dryRun); err != nil {
+			return err
+		}
+	}
+
+	commitPaths := []string{repo.Rel(toolRoot)}
+	switch {
+	case dryRun:
+	case archive:
+		if err := os.MkdirAll(filepath.Dir(archived), 0755); err != nil {
+			return fmt.Errorf("create %s: %w", archiveDir, err)
+		}
+		if err := os.Rename(toolRoot, archived); err != nil {
+			return fmt.Errorf("archive %s: %w", toolName, err)
+		}
+		commitPaths = append(commitPaths, repo.Rel(archived))
+		cli.Success("Moved %s to %s", repo.Rel(toolRoot), repo.Rel(archived))
+	default:
+		if err := os.RemoveAll(toolRoot); err != nil {
+			return fmt.Errorf("delete %s: %w", repo.Rel(toolRoot), err)
+		}
+		cli.Success("Deleted %s", repo.Rel(toolRoot))
+	}
+
+	if len(profiles) > 0 && !dryRun {
+		if err := rewriteFile(rootPath, func(data string) string { return parser.RemoveProfileTool(data, toolName) }); err != nil {
+			return err
+		}
+		commitPaths = append(commitPaths, repo.Rel(rootPath))
+		cli.Success("Dropped %s from profiles: %s", toolName, strings.Join(profiles, ", "))
+	}
+
+	if dryRun {
+		fmt.Println("\nThis was a dry run. No changes were made.")
+		return nil
+	}
+	if !noCommit {
+		commitToolChange(repo, rootConfig, git.ToolsCommit("remove", []string{toolName}), commitPaths, commitAnyway)
+	}
+	return nil
+}
+
+// commitToolChange commits paths (relative to the repository root) for tool
+// remove and rename, unless unrelated changes block it
+func commitToolChange(repo *config.DotfilesRepo, rootConfig *models.RootMerlinConfig, info git.CommitInfo, paths []string, commitAnyway bool) {
+	if !git.IsGitAvailable() {
+		return
+	}
+	repoGit, err := git.Open(repo.Root)
+	if err != nil || autoCommitBlocked(repoGit, info, paths, commitAnyway) {
+		return
+	}
+	msg := git.CommitMessage(rootConfig.Settings.Git.CommitTemplate, info)
+	if err := repoGit.Commit(msg, paths); err != nil {
+		cli.Warning("commit failed: %v", err)
+		return
+	}
+	cli.Success("Committed (%s)", msg)
+}
+
+// deleteLeftovers backs up and deletes what is left at the targets of tool
+// after unlinking (see symlink.Leftovers)
+func deleteLeftovers(tool *symlink.ToolConfig, others []*symlink.ToolConfig, vars symlink.Variables, dryRun bool) error {
+	var leftovers, files []string
+	for _, target := range symlink.Leftovers(tool, others, vars) {
+		if err := protect.Check(target); err != nil {
+			cli.Warning("%v", err)
+			continue
+		}
+		leftovers = append(leftovers, target)
+		filepath.WalkDir(target, func(path string, d fs.DirEntry, err error) error {
+			if err == nil && d.Type().IsRegular() {
+				files = append(files, path)
+			}
+			return nil
+		})
+	}
+	if len(leftovers) == 0 {
+		fmt.Println("Nothing left at the targets")
+		return nil
+	}
+	if dryRun {
+		fmt.Printf("Would delete (%d file(s) backed up first):\n", len(files))
+		fmt.Print(cli.BulletList(leftovers))
+		return nil
+	}
+
+	if len(files) > 0 {
+		manifest, err := backup.CreateToolBackup(files, fmt.Sprintf("Before removing %s", tool.Name), tool.Name)
+		if err != nil {
+			return fmt.Errorf("back up leftovers: %w", err)
+		}
+		fmt.Printf("Backed up %d file(s) (merlin backup restore %s)\n", len(manifest.Files), manifest.ID)
+	}
+	for _, target := range leftovers {
+		if err := os.RemoveAll(target); err != nil {
+			return fmt.Errorf("delete %s: %w", target, err)
+		}
+		fmt.Printf("  ✓ %s (deleted)\n", target)
+	}
+	return nil
+}
+
+func runToolRename(cmd *cobra.Command, oldName, newName string) error {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	noCommit, _ := cmd.Flags().GetBool("no-commit")
+	commitAnyway, _ := cmd.Flags().GetBool("commit-anyway")
+	verbosity := verbosityLevel(cmd)
+
+	repo, err := config.FindDotfilesRepo()
+	if err != nil {
+		return fmt.Errorf("dotfiles repository not found: %w", err)
+	}
+	if !repo.ToolExists(oldName) {
+		return fmt.Errorf("tool '%s' not found in dotfiles repository", oldName)
+	}
+	if newName == "" || newName == oldName || strings.HasPrefix(newName, ".") || strings.ContainsAny(newName, `/\"'`) {
+		return fmt.Errorf("invalid tool name %q", newName)
+	}
+	oldRoot, newRoot := repo.GetToolRoot(oldName), repo.GetToolRoot(newName)
+	if _, err := os.Lstat(newRoot); err == nil {
+		return fmt.Errorf("%s already exists", repo.Rel(newRoot))
+	}
+	rootPath := repo.GetRootMerlinConfig()
+	rootConfig, err := parser.ParseRootMerlinTOML(rootPath)
+	if err != nil {
+		return fmt.Errorf("parsing root config: %w", err)
+	}
+	vars, err := symlink.GetVariablesFromRoot(rootConfig)
+	if err != nil {
+		return fmt.Errorf("getting variables: %w", err)
+	}
+
+	tool, err := symlink.DiscoverToolConfig(repo, oldName, vars)
+	if err != nil {
+		return fmt.Errorf("discovering tool config: %w", err)
+	}
+	_, linked, _ := symlink.CountLinks(tool)
+	var profiles []string
+	for _, p := range rootConfig.Profiles {
+		if slices.Contains(p.Tools, oldName) {
+			profiles = append(profiles, p.Name)
+		}
+	}
+	var dependents []string
+	others, err := symlink.DiscoverTools(repo, vars)
+	if err != nil {
+		return fmt.Errorf("discovering tools: %w", err)
+	}
+	for _, other := range others {
+		if other.HasMerlinTOML && slices.Contains(other.Dependencies, oldName) {
+			dependents = append(dependents, other.Name)
+		}
+	}
+
+	fmt.Printf("Renaming %s to %s:\n", oldName, newName)
+	fmt.Printf("  • move %s to %s\n", repo.Rel(oldRoot), repo.Rel(newRoot))
+	if linked > 0 {
+		fmt.Printf("  • relink its %d link(s) to the new path\n", linked)
+	}
+	if len(profiles) > 0 {
+		fmt.Printf("  • update profiles: %s\n", strings.Join(profiles, ", "))
+	}
+	if len(dependents) > 0 {
+		fmt.Printf("  • update dependencies of: %s\n", strings.Join(dependents, ", "))
+	}
+	if dryRun {
+		fmt.Println("\nThis was a dry run. No changes were made.")
+		return nil
+	}
+	fmt.Println()
+
+	if linked > 0 {
+		if _, err := unlinkTool(repo, oldName, vars, false, verbosity); err != nil {
+			return err
+		}
+		fmt.Println()
+	}
+
+	if err := os.Rename(oldRoot, newRoot); err != nil {
+		return fmt.Errorf("move %s: %w", repo.Rel(oldRoot), err)
+	}
+	commitPaths := []string{repo.Rel(oldRoot), repo.Rel(newRoot)}
+
+	merlinPath := repo.GetToolMerlinConfig(newName)
+	data, err := os.ReadFile(merlinPath)
+	switch {
+	case os.IsNotExist(err):
+		// Keep the implicit config/ → {config_dir}/<old> link where it was
+		data = []byte(defaultToolTOML(repo, newName, oldName))
+	case err != nil:
+		return fmt.Errorf("read %s: %w", merlinPath, err)
+	default:
+		data = []byte(parser.SetToolName(string(data), newName))
+	}
+	if err := os.WriteFile(merlinPath, data, 0644); err != nil {
+		return fmt.Errorf("write %s: %w", merlinPath, err)
+	}
+	cli.Success("Moved %s to %s", repo.Rel(oldRoot), repo.Rel(newRoot))
+
+	if len(profiles) > 0 {
+		if err := rewriteFile(rootPath, func(data string) string { return parser.RenameProfileTool(data, oldName, newName) }); err != nil {
+			return err
+		}
+		commitPaths = append(commitPaths, repo.Rel(rootPath))
+		cli.Success("Updated profiles: %s", strings.Join(profiles, ", "))
+	}
+	for _, name := range dependents {
+		path := repo.GetToolMerlinConfig(name)
+		if err := rewriteFile(path, func(data string) string { return parser.RenameToolDependency(data, oldName, newName) }); err != nil {
+			return err
+		}
+		commitPaths = append(commitPaths, repo.Rel(path))
+	}
+	if len(dependents) > 0 {
+		cli.Success("Updated dependencies of: %s", strings.Join(dependents, ", "))
+	}
+
+	if linked > 0 {
+		fmt.Println()
+		runLinkTool(repo, newName, vars, symlink.StrategySkip, false, verbosity, false)
+	}
+
+	if !noCommit {
+		commitToolChange(repo, rootConfig, git.RenameCommit(oldName, newName), commitPaths, commitAnyway)
+	}
+	return nil
+}
+
+// rewriteFile replaces the contents of path with edit applied to them
+func rewriteFile(path string, edit func(string) string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, []byte(edit(string(data))), 0644); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ildx/merlin/internal/cli"
+	"github.com/ildx/merlin/internal/config"
+	"github.com/ildx/merlin/internal/installer"
+	"github.com/ildx/merlin/internal/parser"
+	"github.com/ildx/merlin/internal/system"
+	"github.com/spf13/cobra"
+)
+
+var uninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove installed apps",
+	Long: `Remove applications installed outside of Homebrew.
+
+SUBCOMMANDS
+	mas   Uninstall Mac App Store apps (sudo mas uninstall)
+
+For Homebrew leaves not declared in brew.toml, see merlin clean --leaves.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+var uninstallMASCmd = &cobra.Command{
+	Use:   "mas [app...]",
+	Short: "Uninstall Mac App Store apps",
+	Long: `Uninstall Mac App Store apps by App Store ID or name.
+
+mas keeps listing apps after their bundle is dragged to the Trash, and does not
+list App Store apps it didn't install. Merlin reconciles 'mas list' with the
+bundles in /Applications and ~/Applications, so every command here works on
+what is actually on disk.
+
+BEHAVIOR
+	Without arguments, prints the reconciled state: stale apps (listed by
+	mas, bundle deleted) and untracked ones (bundle present, not listed).
+	Apps are removed with 'sudo mas uninstall' (mas 1.6+). When mas can't
+	remove an app, merlin prints the bundle to move to the Trash instead.
+
+FLAGS
+	--undeclared   Uninstall every present app not declared in mas.toml
+	--force        Skip the confirmation prompt
+	--dry-run      Show what would be removed
+
+EXAMPLES
+	merlin uninstall mas                      # Show stale/untracked apps
+	merlin uninstall mas 497799835            # By App Store ID
+	merlin uninstall mas "Pixelmator Pro"     # By name
+	merlin uninstall mas --undeclared --dry-run`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runUninstallMAS(cmd, args); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(uninstallCmd)
+	uninstallCmd.AddCommand(uninstallMASCmd)
+
+	uninstallMASCmd.Flags().Bool("undeclared", false, "Uninstall every present app not declared in mas.toml")
+	uninstallMASCmd.Flags().Bool("force", false, "Skip confirmation prompt")
+}
+
+func runUninstallMAS(cmd *cobra.Command, args []string) error {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	verbosity := verbosityLevel(cmd)
+	undeclared, _ := cmd.Flags().GetBool("undeclared")
+	force, _ := cmd.Flags().GetBool("force")
+
+	if !system.CheckMAS().Exists {
+		return fmt.Errorf("mas-cli is not installed. Install it with: brew install mas")
+	}
+
+	apps, err := installer.ScanMASApps()
+	if err != nil {
+		return err
+	}
+
+	var selected []installer.MASAppState
+	switch {
+	case undeclared:
+		if len(args) > 0 {
+			return fmt.Errorf("--undeclared cannot be combined with app arguments")
+		}
+		if selected, err = undeclaredMASApps(apps); err != nil {
+			return err
+		}
+		if len(selected) == 0 {
+			fmt.Println("✓ Every installed App Store app is declared in mas.toml")
+			return nil
+		}
+	case len(args) > 0:
+		for _, arg := range args {
+			app, err := findMASApp(apps, arg)
+			if err != nil {
+				return err
+			}
+			selected = append(selected, app)
+		}
+	default:
+		printMASAppState(apps)
+		return nil
+	}
+
+	fmt.Printf("Will uninstall %d app(s):\n", len(selected))
+	for _, app := range selected {
+		fmt.Printf("  • %s\n", app.Label())
+	}
+
+	if !force && !dryRun {
+		fmt.Print("\n⚠️  This will delete the apps and their bundles. Continue? [y/N]: ")
+		var response string
+		fmt.Scanln(&response)
+		response = strings.ToLower(strings.TrimSpace(response))
+		if response != "y" && response != "yes" {
+			fmt.Println("Uninstall cancelled.")
+			return nil
+		}
+	}
+	fmt.Println()
+
+	masInstaller := installer.NewMASInstaller(dryRun, verbosity)
+	failed := 0
+	for _, app := range selected {
+		if result := masInstaller.UninstallApp(app, os.Stdout); !result.Success {
+			failed++
+			if result.Error != nil && verbosity.Items() {
+				fmt.Println(cli.Dim("    " + result.Error.Error()))
+			}
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d app(s) could not be uninstalled", failed)
+	}
+	return nil
+}
+
+// findMASApp resolves an App Store ID or a case-insensitive app name
+func findMASApp(apps []installer.MASAppState, arg string) (installer.MASAppState, error) {
+	id, _ := strconv.Atoi(arg)
+	for _, app := range apps {
+		if (id != 0 && app.ID == id) || strings.EqualFold(app.Name, arg) {
+			return app, nil
+		}
+	}
+	return installer.MASAppState{}, fmt.Errorf("no App Store app matching %q (see: merlin uninstall mas)", arg)
+}
+
+// undeclaredMASApps returns the present apps missing from mas.toml
+func undeclaredMASApps(apps []installer.MASAppState) ([]installer.MASAppState, error) {
+	repo, err := config.FindDotfilesRepo()
+	if err != nil {
+		return nil, fmt.Errorf("dotfiles repository not found: %w", err)
+	}
+	masConfig, err := parser.ParseMASTOML(filepath.Join(repo.GetToolConfigDir("mas"), "mas.toml"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse mas.toml: %w", err)
+	}
+
+	var undeclared []installer.MASAppState
+	for _, app := range apps {
+		if app.Present && masConfig.FindByID(app.ID) == nil {
+			undeclared = append(undeclared, app)
+		}
+	}
+	return undeclared, nil
+}
+
+// printMASAppState lists installed apps and flags the ones mas misreports
+func printMASAppState(apps []installer.MASAppState) {
+	var stale, untracked []installer.MASAppState
+	present := 0
+	for _, app := range apps {
+		switch {
+		case app.Stale():
+			stale = append(stale, app)
+		case app.Untracked():
+			untracked = append(untracked, app)
+		}
+		if app.Present {
+			present++
+		}
+	}
+
+	fmt.Printf("🍎 %d App Store app(s) installed\n", present)
+	if len(stale) > 0 {
+		fmt.Printf("\n⚠️  Listed by mas but deleted from Applications (%d):\n", len(stale))
+		for _, app := range stale {
+			fmt.Printf("  • %s\n", app.Label())
+		}
+		fmt.Println(cli.Dim("  merlin diff treats these as not installed."))
+	}
+	if len(untracked) > 0 {
+		fmt.Printf("\nℹ️  Present but not listed by mas (%d):\n", len(untracked))
+		for _, app := range untracked {
+			fmt.Printf("  • %s  %s\n", app.Label(), cli.Dim(app.Path))
+		}
+	}
+	if len(stale) == 0 && len(untracked) == 0 {
+		fmt.Println("✓ mas list matches the apps on disk")
+	}
+	fmt.Println("\nUninstall with: merlin uninstall mas <id|name>")
+}
@@ -4,19 +4,25 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
-	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/ildx/merlin/internal/cli"
 	"github.com/ildx/merlin/internal/config"
 	"github.com/ildx/merlin/internal/git"
 	"github.com/ildx/merlin/internal/parser"
+	"github.com/ildx/merlin/internal/progress"
 	"github.com/ildx/merlin/internal/symlink"
+	"github.com/ildx/merlin/internal/system"
 	"github.com/spf13/cobra"
 )
 
 var unlinkAll bool
 var unlinkNoAutoCommit bool
+var unlinkCommitAnyway bool
+var unlinkExcept []string
+var unlinkYes bool
+var unlinkForce bool
 
 var unlinkCmd = &cobra.Command{
 	Use:   "unlink [tool]",
@@ -26,11 +32,20 @@ var unlinkCmd = &cobra.Command{
 SAFETY
 	• Only removes symlinks that point back into your dotfiles repo
 	• Regular files / foreign symlinks are left untouched
+	• With --all, only symlinks merlin recorded creating (the link registry
+	  in ~/.merlin/links.json) are removed, unless --force
 
 FLAGS
-	--all        Unlink all discovered tools
-	--dry-run    Preview what would be removed
-	--verbose    Show each evaluated path
+	--all            Unlink all discovered tools, after reviewing the list
+	--except a,b     With --all, skip these tools
+	-y, --yes        With --all, don't ask which tools to skip
+	--force          With --all, also remove links missing from the link registry
+	--dry-run        Preview what would be removed
+	--commit-anyway  Auto-commit even if the repository has unrelated changes
+	--summary        Print the final counts and failures only
+	--quiet          Print errors only (--all needs --yes)
+	-v               Show each evaluated path
+	-vvv             Also print the planned links and repository path
 
 EXAMPLES
 	merlin unlink git            # Remove git links
@@ -43,7 +58,7 @@ TIPS
 	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		dryRun, _ := cmd.Flags().GetBool("dry-run")
-		verbose, _ := cmd.Flags().GetBool("verbose")
+		verbosity := verbosityLevel(cmd)
 
 		// Find dotfiles repo
 		repo, err := config.FindDotfilesRepo()
@@ -52,7 +67,7 @@ TIPS
 			os.Exit(1)
 		}
 
-		if verbose {
+		if verbosity.Debug() {
 			fmt.Printf("Dotfiles repository: %s\n", repo.Root)
 			if dryRun {
 				fmt.Println("Mode: Dry run (no changes will be made)")
@@ -77,9 +92,9 @@ TIPS
 
 		processedTools := []string{}
 		if unlinkAll {
-			processedTools = runUnlinkAll(repo, vars, dryRun, verbose)
+			processedTools = runUnlinkAll(cmd, repo, vars, dryRun, verbosity)
 		} else if len(args) == 1 {
-			runUnlinkTool(repo, args[0], vars, dryRun, verbose)
+			runUnlinkTool(repo, args[0], vars, dryRun, verbosity)
 			processedTools = append(processedTools, args[0])
 		} else {
 			cmd.Help()
@@ -92,13 +107,16 @@ TIPS
 				if repoGit, err := git.Open(repo.Root); err == nil && len(processedTools) > 0 {
 					paths := make([]string, 0, len(processedTools))
 					for _, t := range processedTools {
-						paths = append(paths, filepath.Join("config", t))
+						paths = append(paths, repo.Rel(repo.GetToolRoot(t)))
 					}
-					if unrelated, uErr := repoGit.HasUnrelatedChanges(paths); uErr == nil && unrelated {
-						cli.Warning("auto-commit skipped: unrelated changes detected outside tool directories")
+					info := git.ToolsCommit("unlink", processedTools)
+					if autoCommitBlocked(repoGit, info, repoGit.FilterPaths(paths), unlinkCommitAnyway) {
+						// reported with the blocking paths
+					} else if batchWindow(rootConfig.Settings.Git) > 0 {
+						queueAutoCommit(repoGit, rootConfig.Settings.Git, info, repoGit.FilterPaths(paths))
 					} else {
 						paths = repoGit.FilterPaths(paths)
-						msg := buildUnlinkCommitMessage(processedTools)
+						msg := git.CommitMessage(rootConfig.Settings.Git.CommitTemplate, info)
 						if err := repoGit.Commit(msg, paths); err != nil {
 							if strings.Contains(err.Error(), "no staged changes") {
 								cmdGit := exec.Command("git", "-C", repoGit.Root, "commit", "--allow-empty", "-m", msg)
@@ -124,25 +142,37 @@ func init() {
 	rootCmd.AddCommand(unlinkCmd)
 	unlinkCmd.Flags().BoolVar(&unlinkAll, "all", false, "Unlink all discovered configs")
 	unlinkCmd.Flags().BoolVar(&unlinkNoAutoCommit, "no-auto-commit", false, "Disable auto-commit even if enabled in settings")
+	unlinkCmd.Flags().BoolVar(&unlinkCommitAnyway, "commit-anyway", false, "Auto-commit the unlinked tools even if other files changed")
+	unlinkCmd.Flags().StringSliceVar(&unlinkExcept, "except", nil, "With --all, skip these tools (comma-separated)")
+	unlinkCmd.Flags().BoolVarP(&unlinkYes, "yes", "y", false, "With --all, unlink without reviewing the tool list")
+	unlinkCmd.Flags().BoolVar(&unlinkForce, "force", false, "With --all, also remove links merlin has no record of creating")
+	addOutputFlags(unlinkCmd.Flags())
 }
 
-func runUnlinkTool(repo *config.DotfilesRepo, toolName string, vars symlink.Variables, dryRun, verbose bool) {
+func runUnlinkTool(repo *config.DotfilesRepo, toolName string, vars symlink.Variables, dryRun bool, verbosity cli.Verbosity) {
+	if _, err := unlinkTool(repo, toolName, vars, dryRun, verbosity); err != nil {
+		cli.Error("%v", err)
+		os.Exit(1)
+	}
+}
+
+// unlinkTool removes the links of one tool and displays the results,
+// returning the discovered tool
+func unlinkTool(repo *config.DotfilesRepo, toolName string, vars symlink.Variables, dryRun bool, verbosity cli.Verbosity) (*symlink.ToolConfig, error) {
 	// Check if tool exists
 	if !repo.ToolExists(toolName) {
-		cli.Error("Tool '%s' not found in dotfiles repository", toolName)
-		os.Exit(1)
+		return nil, fmt.Errorf("tool '%s' not found in dotfiles repository", toolName)
 	}
 
 	// Discover tool config
 	tool, err := symlink.DiscoverToolConfig(repo, toolName, vars)
 	if err != nil {
-		cli.Error("discovering tool config: %v", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("discovering tool config: %w", err)
 	}
 
 	if len(tool.Links) == 0 {
 		fmt.Printf("No links configured for %s\n", toolName)
-		return
+		return tool, nil
 	}
 
 	// Display tool info
@@ -152,7 +182,7 @@ func runUnlinkTool(repo *config.DotfilesRepo, toolName string, vars symlink.Vari
 	}
 	fmt.Println()
 
-	if verbose {
+	if verbosity.Debug() {
 		fmt.Printf("  Links to remove: %d\n", len(tool.Links))
 		for i, link := range tool.Links {
 			fmt.Printf("  %d. %s\n", i+1, link.Target)
@@ -160,17 +190,29 @@ func runUnlinkTool(repo *config.DotfilesRepo, toolName string, vars symlink.Vari
 		fmt.Println()
 	}
 
+	// Unload launch agents while their plists are still linked
+	unloadLaunchAgents(tool, dryRun)
+
 	// Unlink the tool
+	phase := progress.Start("unlink", 1)
+	phase.Begin(tool.Name)
 	results, err := symlink.UnlinkTool(tool, dryRun)
 	if err != nil {
 		cli.Warning("unlinking tool: %v", err)
 	}
+	registry := loadLinkRegistry()
+	results = unlinkOwned(registry, tool, results, dryRun)
+	finishUnlinkProgress(phase, tool.Name, results)
+	phase.End()
+	saveLinkRegistry(registry, dryRun)
+	recordLinkState([]*symlink.ToolConfig{tool}, dryRun)
 
 	// Display results
-	displayUnlinkResults(results, verbose)
+	displayUnlinkResults(results, verbosity)
+	return tool, nil
 }
 
-func runUnlinkAll(repo *config.DotfilesRepo, vars symlink.Variables, dryRun, verbose bool) []string {
+func runUnlinkAll(cmd *cobra.Command, repo *config.DotfilesRepo, vars symlink.Variables, dryRun bool, verbosity cli.Verbosity) []string {
 	// Discover all tools
 	tools, err := symlink.DiscoverTools(repo, vars)
 	if err != nil {
@@ -178,18 +220,57 @@ func runUnlinkAll(repo *config.DotfilesRepo, vars symlink.Variables, dryRun, ver
 		os.Exit(1)
 	}
 
+	tools, err = excludeTools(tools, unlinkExcept)
+	if err != nil {
+		cli.Error("%v", err)
+		os.Exit(1)
+	}
+	tools = withLinks(tools)
 	if len(tools) == 0 {
 		fmt.Println("No tools found to unlink")
 		return []string{}
 	}
 
+	prompt := !unlinkYes && !dryRun && stdinIsTerminal()
+	if prompt {
+		if err := cli.CheckPrompt("the tool review (use --yes)"); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	}
+	review := &batchReview{
+		Verb:  "unlink",
+		Table: newTable(cmd, "#", "TOOL", "LINKS", "LINKED"),
+		Row: func(tool *symlink.ToolConfig) []string {
+			total, linked, _ := symlink.CountLinks(tool)
+			return []string{strconv.Itoa(total), strconv.Itoa(linked)}
+		},
+		Prompt: prompt,
+		Input:  os.Stdin,
+		Output: os.Stdout,
+	}
+	tools, err = review.Review(tools)
+	if err != nil {
+		cli.Error("%v", err)
+		os.Exit(1)
+	}
+	if review.Cancelled || len(tools) == 0 {
+		fmt.Println("Nothing unlinked.")
+		return []string{}
+	}
+
 	fmt.Printf("Unlinking %d tools\n\n", len(tools))
 
 	successCount := 0
 	skipCount := 0
 	errorCount := 0
+	unregistered := 0
 
 	processed := []string{}
+	var unlinked []*symlink.ToolConfig
+	registry := loadLinkRegistry()
+	phase := progress.Start("unlink", len(tools))
+	defer phase.End()
 	for _, tool := range tools {
 		if len(tool.Links) == 0 {
 			continue
@@ -201,27 +282,31 @@ func runUnlinkAll(repo *config.DotfilesRepo, vars symlink.Variables, dryRun, ver
 		}
 		fmt.Println()
 
-		results, _ := symlink.UnlinkTool(tool, dryRun)
+		phase.Begin(tool.Name)
+		unloadLaunchAgents(tool, dryRun)
+		results := unlinkRegistered(registry, tool, dryRun, unlinkForce)
+		results = unlinkOwned(registry, tool, results, dryRun)
+		finishUnlinkProgress(phase, tool.Name, results)
 
 		for _, result := range results {
 			switch result.Status {
 			case symlink.LinkStatusSuccess:
 				successCount++
-				if verbose {
+				if verbosity.Items() {
 					fmt.Printf("  ✓ %s\n", result.Target)
 				}
 			case symlink.LinkStatusSkipped:
 				skipCount++
-				if verbose {
+				if verbosity.Items() {
 					fmt.Printf("  ⊘ %s (%s)\n", result.Target, result.Message)
 				}
 			case symlink.LinkStatusError:
 				errorCount++
-				fmt.Printf("  ✗ %s (error: %s)\n", result.Target, result.Message)
+				fmt.Fprintf(cli.Failures(), "  ✗ %s (error: %s)\n", result.Target, result.Message)
 			}
 		}
 
-		if !verbose {
+		if !verbosity.Items() {
 			// Show summary per tool
 			toolSuccess := 0
 			toolSkip := 0
@@ -237,15 +322,25 @@ func runUnlinkAll(repo *config.DotfilesRepo, vars symlink.Variables, dryRun, ver
 			}
 			fmt.Printf("  %d removed, %d skipped, %d errors\n", toolSuccess, toolSkip, toolError)
 		}
+		if n := countUnregistered(results); n > 0 {
+			unregistered += n
+			fmt.Printf("  %d link(s) left in place: merlin has no record of creating them\n", n)
+		}
 
 		fmt.Println()
 		processed = append(processed, tool.Name)
+		unlinked = append(unlinked, tool)
 	}
+	saveLinkRegistry(registry, dryRun)
+	recordLinkState(unlinked, dryRun)
 
 	// Summary
 	fmt.Println(strings.Repeat("─", 60))
-	fmt.Printf("Summary: %d removed, %d skipped, %d errors\n",
+	cli.Summary("Summary: %d removed, %d skipped, %d errors",
 		successCount, skipCount, errorCount)
+	if unregistered > 0 {
+		fmt.Println(cli.Dim(fmt.Sprintf("%d link(s) not in the link registry were kept; check them with -v and remove them with --force", unregistered)))
+	}
 
 	if dryRun {
 		fmt.Println("\nThis was a dry run. No changes were made.")
@@ -253,7 +348,52 @@ func runUnlinkAll(repo *config.DotfilesRepo, vars symlink.Variables, dryRun, ver
 	return processed
 }
 
-func displayUnlinkResults(results []*symlink.UnlinkResult, verbose bool) {
+// finishUnlinkProgress reports tool as failed in the progress stream when any
+// of its links couldn't be removed, else as done
+func finishUnlinkProgress(phase *progress.Phase, tool string, results []*symlink.UnlinkResult) {
+	counts := make(map[symlink.LinkStatus]int)
+	for _, r := range results {
+		counts[r.Status]++
+	}
+	message := fmt.Sprintf("%d removed, %d skipped, %d errors",
+		counts[symlink.LinkStatusSuccess], counts[symlink.LinkStatusSkipped], counts[symlink.LinkStatusError])
+	status := progress.StatusDone
+	if counts[symlink.LinkStatusError] > 0 {
+		status = progress.StatusFailed
+	}
+	phase.Finish(tool, status, message)
+}
+
+// countUnregistered counts the links unlinkRegistered left in place
+func countUnregistered(results []*symlink.UnlinkResult) int {
+	n := 0
+	for _, r := range results {
+		if r.Status == symlink.LinkStatusSkipped && r.Message == unregisteredMessage {
+			n++
+		}
+	}
+	return n
+}
+
+// unloadLaunchAgents deactivates plists linked by launchd = true links (macOS only)
+func unloadLaunchAgents(tool *symlink.ToolConfig, dryRun bool) {
+	if !system.IsMacOS() {
+		return
+	}
+	unloaded, err := symlink.UnloadLaunchAgents(tool, dryRun)
+	for _, agent := range unloaded {
+		if dryRun {
+			fmt.Printf("  ↻ %s (would unload with launchctl)\n", agent)
+		} else {
+			fmt.Printf("  ↻ %s (unloaded)\n", agent)
+		}
+	}
+	if err != nil {
+		cli.Warning("unloading launch agents: %v", err)
+	}
+}
+
+func displayUnlinkResults(results []*symlink.UnlinkResult, verbosity cli.Verbosity) {
 	successCount := 0
 	skipCount := 0
 	errorCount := 0
@@ -262,7 +402,7 @@ func displayUnlinkResults(results []*symlink.UnlinkResult, verbose bool) {
 		switch result.Status {
 		case symlink.LinkStatusSuccess:
 			successCount++
-			if verbose {
+			if verbosity.Items() {
 				fmt.Printf("  ✓ %s (removed)\n", result.Target)
 			} else {
 				fmt.Printf("  ✓ %s\n", result.Target)
@@ -272,28 +412,11 @@ func displayUnlinkResults(results []*symlink.UnlinkResult, verbose bool) {
 			fmt.Printf("  ⊘ %s (%s)\n", result.Target, result.Message)
 		case symlink.LinkStatusError:
 			errorCount++
-			fmt.Printf("  ✗ %s (error: %s)\n", result.Target, result.Message)
+			fmt.Fprintf(cli.Failures(), "  ✗ %s (error: %s)\n", result.Target, result.Message)
 		}
 	}
 
 	fmt.Println()
-	fmt.Printf("Summary: %d removed, %d skipped, %d errors\n",
+	cli.Summary("Summary: %d removed, %d skipped, %d errors",
 		successCount, skipCount, errorCount)
 }
-
-// buildUnlinkCommitMessage constructs a commit message summarizing unlink operations.
-// Mirrors link commit style to keep history coherent.
-func buildUnlinkCommitMessage(tools []string) string {
-	if len(tools) == 0 {
-		return "chore(unlink): no tools"
-	}
-	if len(tools) == 1 {
-		return fmt.Sprintf("chore(unlink): unlink %s", tools[0])
-	}
-	joined := strings.Join(tools, ", ")
-	if len(tools) <= 3 {
-		return fmt.Sprintf("chore(unlink): unlink %s (%d tools)", joined, len(tools))
-	}
-	preview := strings.Join(tools[:3], ", ")
-	return fmt.Sprintf("chore(unlink): unlink %d tools (%s, …)", len(tools), preview)
-}
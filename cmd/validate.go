@@ -2,14 +2,24 @@ package cmd
 
 import (
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/ildx/merlin/internal/cli"
 	"github.com/ildx/merlin/internal/config"
+	"github.com/ildx/merlin/internal/git"
 	"github.com/ildx/merlin/internal/logger"
+	"github.com/ildx/merlin/internal/models"
+	"github.com/ildx/merlin/internal/notify"
 	"github.com/ildx/merlin/internal/parser"
+	"github.com/ildx/merlin/internal/protect"
+	"github.com/ildx/merlin/internal/scripts"
+	"github.com/ildx/merlin/internal/secrets"
+	"github.com/ildx/merlin/internal/symlink"
 	"github.com/spf13/cobra"
 )
 
@@ -20,16 +30,28 @@ var validateCmd = &cobra.Command{
 
 CHECKS PERFORMED
 	• TOML syntax errors
-	• Duplicate packages/apps/profile names
+	• Duplicate packages/apps/profile names, also across brew.d/*.toml files
 	• Invalid conflict strategies
+	• Package when conditions with an unknown arch or undefined profile
+	• Relative protected_paths entries
 	• Missing tool config files
-	• Broken or missing link sources
+	• Missing link sources: an error, a warning with optional = "warn",
+	  ignored with optional = true
 	• Missing or invalid script references
+	• Invalid or duplicate editor extensions
+	• Manual steps without an id or description, or with duplicate ids
+	• Unknown [tool] permissions
+	• Broken symlinks at declared targets (e.g. after renaming a tool)
+	• Tools without merlin.toml when require_merlin_toml is true or "warn"
+	• Likely secrets in linked files (private keys, tokens, password = ...);
+	  silence false positives in .merlin-secrets-allow
+
+	Tools with enabled = false are listed as disabled and not checked.
 
 FLAGS
 	--strict   Treat warnings as errors (non‑zero exit code)
 	--dry-run  (Global) No effect here but accepted for consistency
-	--verbose  Show additional internal logging
+	-vvv       Show additional internal logging
 
 EXIT STATUS
 	0 if no errors (warnings allowed unless --strict)
@@ -41,7 +63,7 @@ EXAMPLES
 
 TIPS
 	Run before linking or installing for early feedback.
-	Combine with --verbose to see debug log output (file: ~/.merlin/merlin.log).`,
+	Combine with -vvv to see debug log output (file: ~/.merlin/merlin.log).`,
 	Run: func(cmd *cobra.Command, args []string) {
 		strict, _ := cmd.Flags().GetBool("strict")
 
@@ -93,12 +115,16 @@ func runValidate(strict bool) error {
 		results = append(results, *masResult)
 	}
 
-	// Validate tool configs
+	// Validate tool configs (disabled tools are reported, not checked)
+	disabled := symlink.DisabledTools(repo)
 	tools, err := repo.ListTools()
 	if err != nil {
 		logger.Warn("Failed to list tools", "error", err)
 	} else {
 		for _, tool := range tools {
+			if slices.Contains(disabled, tool) {
+				continue
+			}
 			toolResult := validateToolConfig(repo, tool)
 			if toolResult != nil {
 				results = append(results, *toolResult)
@@ -106,6 +132,14 @@ func runValidate(strict bool) error {
 		}
 	}
 
+	// Report tools relying on the implicit default link
+	if rootConfig, err := parser.ParseRootMerlinTOML(repo.GetRootMerlinConfig()); err == nil && tools != nil {
+		results = append(results, validateMerlinTOMLRequirement(repo, tools, disabled, rootConfig.Settings.RequireMerlinTOML)...)
+	}
+
+	// Check managed targets for symlinks left dangling
+	results = append(results, validateTargets(repo)...)
+
 	// Print results
 	totalErrors := 0
 	totalWarnings := 0
@@ -128,6 +162,13 @@ func runValidate(strict bool) error {
 		}
 	}
 
+	for _, tool := range disabled {
+		fmt.Printf("⊘ config/%s (disabled)\n", tool)
+	}
+	if len(disabled) > 0 {
+		fmt.Println()
+	}
+
 	// Summary
 	fmt.Println(strings.Repeat("─", 60))
 
@@ -173,6 +214,49 @@ func validateRootConfig(repo *config.DotfilesRepo) ValidationResult {
 		return result
 	}
 
+	// A typo in the commit template would end up verbatim in every auto-commit
+	for _, placeholder := range git.UnknownPlaceholders(rootConfig.Settings.Git.CommitTemplate) {
+		result.Warnings = append(result.Warnings,
+			fmt.Sprintf("commit_template placeholder %s is unknown (use %s)", placeholder, "{"+strings.Join(git.CommitPlaceholders, "}, {")+"}"))
+	}
+
+	if window := rootConfig.Settings.Git.BatchWindow; window != "" {
+		if d, err := time.ParseDuration(window); err != nil || d < 0 {
+			result.Errors = append(result.Errors, fmt.Sprintf("Invalid batch_window %q (use a duration such as \"10m\")", window))
+		}
+	}
+
+	for _, step := range rootConfig.Maintenance.Steps {
+		if !slices.Contains(models.MaintenanceSteps, step) {
+			result.Errors = append(result.Errors, fmt.Sprintf("Unknown maintenance step %q (use %s)", step, strings.Join(models.MaintenanceSteps, ", ")))
+		}
+	}
+
+	notifySettings := rootConfig.Settings.Notify
+	if u := notifySettings.WebhookURL; u != "" && !strings.HasPrefix(u, "https://") && !strings.HasPrefix(u, "http://") {
+		result.Errors = append(result.Errors, fmt.Sprintf("notify webhook_url must be an http(s) URL, got %q", u))
+	}
+	for _, placeholder := range notify.UnknownPlaceholders(notifySettings.PayloadTemplate) {
+		result.Warnings = append(result.Warnings,
+			fmt.Sprintf("payload_template placeholder %s is unknown (use %s)", placeholder, "{"+strings.Join(notify.Placeholders, "}, {")+"}"))
+	}
+
+	// Per-machine overrides must not be committed with the tracked config
+	if _, err := os.Stat(parser.LocalConfigPath(rootPath)); err == nil {
+		if gitRepo, err := git.Open(repo.Root); err == nil && !gitRepo.IsIgnored(parser.LocalConfigName) {
+			result.Warnings = append(result.Warnings,
+				fmt.Sprintf("%s is not gitignored; run 'merlin repo gitignore sync' so machine-specific overrides stay local", parser.LocalConfigName))
+		}
+	}
+
+	// Auto-commit stages whole tool directories; generated files must be ignored
+	if rootConfig.Settings.AutoCommit {
+		if stale, err := git.SyncGitignore(repo.Root, git.ManagedIgnores(rootConfig.Settings.Git.Ignore), true); err == nil && stale {
+			result.Warnings = append(result.Warnings,
+				"auto_commit is on but merlin's .gitignore block is missing or out of date; run 'merlin repo gitignore sync'")
+		}
+	}
+
 	// Validate metadata
 	if rootConfig.Metadata.Name == "" {
 		result.Warnings = append(result.Warnings, "Metadata name is empty")
@@ -195,6 +279,26 @@ func validateRootConfig(repo *config.DotfilesRepo) ValidationResult {
 		}
 	}
 
+	// Protected paths must be absolute or home-relative to match anything
+	for _, p := range rootConfig.Settings.ProtectedPaths {
+		if !filepath.IsAbs(protect.Expand(p, "/")) {
+			result.Warnings = append(result.Warnings,
+				fmt.Sprintf("protected_paths entry '%s' is relative and will be ignored (use ~/ or an absolute path)", p))
+		}
+	}
+
+	// The backup store is only moved by absolute or home-relative paths too
+	backupDirs := rootConfig.Settings.BackupRoots
+	if rootConfig.Settings.BackupDir != "" {
+		backupDirs = append([]string{rootConfig.Settings.BackupDir}, backupDirs...)
+	}
+	for _, p := range backupDirs {
+		if !filepath.IsAbs(protect.Expand(p, "/")) {
+			result.Warnings = append(result.Warnings,
+				fmt.Sprintf("backup directory '%s' is relative and will be ignored (use ~/ or an absolute path)", p))
+		}
+	}
+
 	// Validate profiles
 	profileNames := make(map[string]bool)
 	for i, profile := range rootConfig.Profiles {
@@ -211,52 +315,123 @@ func validateRootConfig(repo *config.DotfilesRepo) ValidationResult {
 		}
 	}
 
+	if err := parser.ValidateManualSteps(rootConfig.ManualSteps); err != nil {
+		result.Errors = append(result.Errors, err.Error())
+	}
+
+	result.Warnings = append(result.Warnings, checkCommandDefaults(rootConfig.Defaults)...)
+
 	return result
 }
 
 func validateBrewConfig(repo *config.DotfilesRepo) *ValidationResult {
-	brewPath := filepath.Join(repo.GetToolConfigDir("brew"), "brew.toml")
+	brewDir := repo.GetToolConfigDir("brew")
+	paths := parser.BrewConfigFiles(brewDir)
 
-	// Skip if file doesn't exist
-	if _, err := os.Stat(brewPath); os.IsNotExist(err) {
+	// Skip if there is no package list
+	if len(paths) == 0 {
 		return nil
 	}
 
 	result := &ValidationResult{
-		File: "config/brew/config/brew.toml",
+		File: repo.Rel(paths[0]),
+	}
+	if len(paths) > 1 || paths[0] != filepath.Join(brewDir, "brew.toml") {
+		result.File = repo.Rel(brewDir) + " (brew.toml, " + parser.BrewDropinDir + "/*.toml)"
 	}
 
 	// Parse brew config
-	brewConfig, err := parser.ParseBrewTOML(brewPath)
+	files, err := parser.ParseBrewFiles(brewDir)
 	if err != nil {
 		result.Errors = append(result.Errors, fmt.Sprintf("Failed to parse: %v", err))
 		return result
 	}
 
-	// Check for duplicates
-	formulaeNames := make(map[string]bool)
-	for _, pkg := range brewConfig.Formulae {
-		if pkg.Name == "" {
-			result.Errors = append(result.Errors, "Formulae entry with empty name")
-		} else if formulaeNames[pkg.Name] {
-			result.Errors = append(result.Errors, fmt.Sprintf("Duplicate formulae: %s", pkg.Name))
-		} else {
-			formulaeNames[pkg.Name] = true
+	profiles := profileNames(repo)
+
+	// Check for duplicates, within and across files
+	formulaeFiles := make(map[string]string)
+	caskFiles := make(map[string]string)
+	tapFiles := make(map[string]string)
+	for _, file := range files {
+		name := parser.BrewFileName(brewDir, file.Path)
+		for _, tap := range file.Config.Taps {
+			if err := parser.ValidateBrewTap(tap); err != nil {
+				result.Errors = append(result.Errors, err.Error())
+			} else if msg := brewDuplicate(tapFiles, "tap", tap.Name, name); msg != "" {
+				result.Errors = append(result.Errors, msg)
+			}
+		}
+		for _, pkg := range file.Config.Formulae {
+			if msg := brewDuplicate(formulaeFiles, "formulae", pkg.Name, name); msg != "" {
+				result.Errors = append(result.Errors, msg)
+			}
+		}
+		for _, pkg := range file.Config.Casks {
+			if msg := brewDuplicate(caskFiles, "cask", pkg.Name, name); msg != "" {
+				result.Errors = append(result.Errors, msg)
+			}
+		}
+		for _, pkg := range file.Config.GetAllPackages() {
+			if err := parser.ValidateBrewArch(pkg); err != nil {
+				result.Errors = append(result.Errors, err.Error())
+			}
+			checkCondition(result, "package "+pkg.Name, pkg.When, profiles)
 		}
 	}
 
-	caskNames := make(map[string]bool)
-	for _, pkg := range brewConfig.Casks {
-		if pkg.Name == "" {
-			result.Errors = append(result.Errors, "Cask entry with empty name")
-		} else if caskNames[pkg.Name] {
-			result.Errors = append(result.Errors, fmt.Sprintf("Duplicate cask: %s", pkg.Name))
-		} else {
-			caskNames[pkg.Name] = true
+	return result
+}
+
+// profileNames returns the profiles of the root merlin.toml, or nil when it
+// can't be read
+func profileNames(repo *config.DotfilesRepo) map[string]bool {
+	rootConfig, err := parser.ParseRootMerlinTOML(repo.GetRootMerlinConfig())
+	if err != nil {
+		return nil
+	}
+	names := make(map[string]bool, len(rootConfig.Profiles))
+	for _, p := range rootConfig.Profiles {
+		names[p.Name] = true
+	}
+	return names
+}
+
+// checkCondition reports an unknown architecture in a when condition as an
+// error, and a profile missing from profiles as a warning: no machine would
+// match it
+func checkCondition(result *ValidationResult, what string, when *models.Condition, profiles map[string]bool) {
+	if when == nil {
+		return
+	}
+	for _, arch := range when.Arch {
+		if arch != "arm64" && arch != "x86_64" && arch != "amd64" {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: invalid when arch %q (must be: arm64 or x86_64)", what, arch))
+		}
+	}
+	for _, profile := range when.Profile {
+		if profiles != nil && !profiles[profile] {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: when profile %q is not defined in merlin.toml", what, profile))
 		}
 	}
+}
 
-	return result
+// brewDuplicate records that a kind ("formulae", "cask" or "tap") name is
+// declared in file and describes the problem when it is unnamed or seen
+// before. Duplicates across files name both files.
+func brewDuplicate(seen map[string]string, kind, name, file string) string {
+	if name == "" {
+		return fmt.Sprintf("%s entry with empty name (%s)", strings.ToUpper(kind[:1])+kind[1:], file)
+	}
+	first, ok := seen[name]
+	if !ok {
+		seen[name] = file
+		return ""
+	}
+	if first == file {
+		return fmt.Sprintf("Duplicate %s: %s (%s)", kind, name, file)
+	}
+	return fmt.Sprintf("Duplicate %s: %s (in %s and %s)", kind, name, first, file)
 }
 
 func validateMASConfig(repo *config.DotfilesRepo) *ValidationResult {
@@ -268,7 +443,7 @@ func validateMASConfig(repo *config.DotfilesRepo) *ValidationResult {
 	}
 
 	result := &ValidationResult{
-		File: "config/mas/config/mas.toml",
+		File: repo.Rel(masPath),
 	}
 
 	// Parse MAS config
@@ -278,6 +453,8 @@ func validateMASConfig(repo *config.DotfilesRepo) *ValidationResult {
 		return result
 	}
 
+	profiles := profileNames(repo)
+
 	// Check for duplicates
 	appIDs := make(map[int]string)
 	appNames := make(map[string]bool)
@@ -299,11 +476,36 @@ func validateMASConfig(repo *config.DotfilesRepo) *ValidationResult {
 		} else {
 			appIDs[app.ID] = app.Name
 		}
+		checkCondition(result, "app "+app.Name, app.When, profiles)
 	}
 
 	return result
 }
 
+// validateMerlinTOMLRequirement warns about tools without a merlin.toml when
+// settings.require_merlin_toml is set: with true they are never linked, with
+// "warn" they still get the implicit config/ → {config_dir}/TOOL link
+func validateMerlinTOMLRequirement(repo *config.DotfilesRepo, tools, disabled []string, requirement models.Requirement) []ValidationResult {
+	if requirement == models.RequirementOff {
+		return nil
+	}
+	var results []ValidationResult
+	for _, tool := range tools {
+		if slices.Contains(disabled, tool) {
+			continue
+		}
+		if _, err := os.Stat(repo.GetToolMerlinConfig(tool)); !os.IsNotExist(err) {
+			continue
+		}
+		warning := "No merlin.toml; relies on the implicit config/ link (require_merlin_toml = \"warn\")"
+		if requirement.Enforced() {
+			warning = "No merlin.toml; never linked because require_merlin_toml is set"
+		}
+		results = append(results, ValidationResult{File: repo.Rel(repo.GetToolRoot(tool)), Warnings: []string{warning}})
+	}
+	return results
+}
+
 func validateToolConfig(repo *config.DotfilesRepo, toolName string) *ValidationResult {
 	merlinPath := repo.GetToolMerlinConfig(toolName)
 
@@ -313,7 +515,7 @@ func validateToolConfig(repo *config.DotfilesRepo, toolName string) *ValidationR
 	}
 
 	result := &ValidationResult{
-		File: fmt.Sprintf("config/%s/merlin.toml", toolName),
+		File: repo.Rel(merlinPath),
 	}
 
 	// Parse tool config
@@ -334,17 +536,74 @@ func validateToolConfig(repo *config.DotfilesRepo, toolName string) *ValidationR
 		if link.Target == "" {
 			result.Errors = append(result.Errors, fmt.Sprintf("Link %d is missing target", i))
 		}
+		if suggested, ok := symlink.SuggestXDG(link.Target); ok {
+			result.Warnings = append(result.Warnings,
+				fmt.Sprintf("Link %d: target %s hard-codes an XDG directory; use %s so XDG_* overrides are respected", i, link.Target, suggested))
+		}
+
+		// Check if source exists (if specified); globs must match something
+		if err := parser.ValidateRename(link.Rename); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("Link %d: %v", i, err))
+		}
+		if (len(link.Rename) > 0 || link.DotPrefix) && !link.Contents {
+			result.Warnings = append(result.Warnings,
+				fmt.Sprintf("Link %d: rename and dot_prefix only apply with contents = true", i))
+		} else {
+			sourceDir := repo.GetToolConfigDir(toolName)
+			if link.Source != "" {
+				sourceDir = filepath.Join(repo.GetToolRoot(toolName), link.Source)
+			}
+			for _, from := range slices.Sorted(maps.Keys(link.Rename)) {
+				if _, err := os.Lstat(filepath.Join(sourceDir, from)); err != nil {
+					result.Warnings = append(result.Warnings,
+						fmt.Sprintf("Link %d: rename source doesn't exist: %s", i, from))
+				}
+			}
+		}
 
-		// Check if source exists (if specified)
-		if link.Source != "" {
-			sourcePath := filepath.Join(repo.GetToolRoot(toolName), link.Source)
-			if _, err := os.Stat(sourcePath); os.IsNotExist(err) {
-				result.Warnings = append(result.Warnings,
-					fmt.Sprintf("Link source doesn't exist: %s", link.Source))
+		sources := []string{link.Source}
+		for _, file := range link.Files {
+			sources = append(sources, file.Source)
+		}
+		for _, source := range sources {
+			if source == "" || link.Optional == models.OptionalYes {
+				continue
+			}
+			if symlink.IsGlob(source) {
+				if matches, err := symlink.ExpandGlob(repo.GetToolRoot(toolName), source); err != nil || len(matches) == 0 {
+					result.Warnings = append(result.Warnings,
+						fmt.Sprintf("Link source pattern matches no files: %s", source))
+				}
+				continue
+			}
+			if _, err := os.Stat(filepath.Join(repo.GetToolRoot(toolName), source)); os.IsNotExist(err) {
+				if link.Optional == models.OptionalWarn {
+					result.Warnings = append(result.Warnings,
+						fmt.Sprintf("Optional link source doesn't exist: %s", source))
+				} else {
+					result.Errors = append(result.Errors,
+						fmt.Sprintf("Link source doesn't exist: %s (set optional = true if it may be absent)", source))
+				}
 			}
 		}
 	}
 
+	if err := parser.ValidateLinkOrder(toolConfig.Links); err != nil {
+		result.Errors = append(result.Errors, err.Error())
+	}
+
+	if err := parser.ValidateExtensions(toolConfig.Extensions); err != nil {
+		result.Errors = append(result.Errors, err.Error())
+	}
+
+	if err := parser.ValidatePermissions(toolConfig.Tool.Permissions); err != nil {
+		result.Errors = append(result.Errors, err.Error())
+	}
+
+	if err := parser.ValidateManualSteps(toolConfig.ManualSteps); err != nil {
+		result.Errors = append(result.Errors, err.Error())
+	}
+
 	// Validate scripts
 	if toolConfig.HasScripts() {
 		scriptsDir := filepath.Join(repo.GetToolRoot(toolName), toolConfig.Scripts.Directory)
@@ -356,7 +615,90 @@ func validateToolConfig(repo *config.DotfilesRepo, toolName string) *ValidationR
 			}
 			// Warn if script has tags but directory missing (already handled above) - placeholder for future advanced validation
 		}
+		for _, err := range scripts.ValidateScriptEnv(toolConfig) {
+			result.Errors = append(result.Errors, err.Error())
+		}
+		for _, err := range scripts.ValidateScriptParams(toolConfig) {
+			result.Errors = append(result.Errors, err.Error())
+		}
 	}
 
 	return result
 }
+
+// validateTargets reports dangling symlinks at each enabled tool's targets,
+// one result per tool so the offending tool is named, and targets claimed by
+// more than one link
+func validateTargets(repo *config.DotfilesRepo) []ValidationResult {
+	rootConfig, err := parser.ParseRootMerlinTOML(repo.GetRootMerlinConfig())
+	if err != nil {
+		return nil // already reported by validateRootConfig
+	}
+	vars, err := symlink.GetVariablesFromRoot(rootConfig)
+	if err != nil {
+		logger.Warn("Failed to resolve variables", "error", err)
+		return nil
+	}
+	tools, err := symlink.DiscoverTools(repo, vars)
+	if err != nil {
+		logger.Warn("Failed to discover tools", "error", err)
+		return nil
+	}
+
+	var results []ValidationResult
+	for _, tool := range tools {
+		broken := symlink.BrokenLinks(tool, repo.Root)
+		if len(broken) == 0 {
+			continue
+		}
+		result := ValidationResult{File: repo.Rel(tool.ToolRoot) + " (targets)"}
+		for _, link := range broken {
+			result.Warnings = append(result.Warnings,
+				fmt.Sprintf("Broken symlink %s -> %s (source no longer exists)", link.Target, link.Dest))
+		}
+		results = append(results, result)
+	}
+
+	if collisions := symlink.FindCollisions(tools); len(collisions) > 0 {
+		result := ValidationResult{File: "link targets"}
+		for _, c := range collisions {
+			result.Errors = append(result.Errors, fmt.Sprintf("Target claimed by more than one link: %s", c))
+		}
+		results = append(results, result)
+	}
+	if result := validateSecrets(repo, tools); result != nil {
+		results = append(results, *result)
+	}
+	return results
+}
+
+// validateSecrets scans every linked source for likely credentials. Entries
+// in .merlin-secrets-allow (path patterns or fingerprints) silence false
+// positives.
+func validateSecrets(repo *config.DotfilesRepo, tools []*symlink.ToolConfig) *ValidationResult {
+	allow, err := secrets.LoadAllowlist(repo.Root)
+	if err != nil {
+		return &ValidationResult{File: secrets.AllowlistName, Errors: []string{fmt.Sprintf("Failed to read: %v", err)}}
+	}
+	var sources []string
+	for _, tool := range tools {
+		for _, link := range tool.Links {
+			sources = append(sources, link.Source)
+		}
+	}
+	findings, err := secrets.Scan(repo.Root, sources, allow)
+	if err != nil {
+		logger.Warn("Failed to scan for secrets", "error", err)
+		return nil
+	}
+	if len(findings) == 0 {
+		return nil
+	}
+
+	// Linked files are usually committed, so these are worth a loud warning
+	result := &ValidationResult{File: fmt.Sprintf("possible secrets (false positives: add the path or [fingerprint] to %s)", secrets.AllowlistName)}
+	for _, f := range findings {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("🔑 %s", f))
+	}
+	return result
+}
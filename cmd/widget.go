@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ildx/merlin/internal/cli"
+	"github.com/ildx/merlin/internal/config"
+	"github.com/ildx/merlin/internal/state"
+	"github.com/spf13/cobra"
+)
+
+var widgetCmd = &cobra.Command{
+	Use:   "widget",
+	Short: "Print status for menu bar plugins (xbar, SwiftBar, sketchybar)",
+	Long: `Print a compact status for menu bar plugins, built only from cached state
+(the drift summary of the last 'merlin diff' and the link status cache), so
+it runs in milliseconds and is safe on a short timer.
+
+FORMATS
+	xbar         One summary line, then "---" and detail lines; action lines run
+	             merlin commands (also works with SwiftBar)
+	sketchybar   One "property=value" per line, for 'sketchybar --set'; clicking
+	             the item opens 'merlin diff' in Terminal
+
+	The summary is "✔" when in sync, otherwise the counts of 'merlin prompt'
+	(⇡ missing, ✘ broken, + undeclared), and "?" before the first diff.
+	A summary older than a day is marked stale.
+
+FLAGS
+	--format <xbar|sketchybar>   Output format (default xbar)
+
+EXAMPLES
+	# ~/Library/Application Support/xbar/plugins/merlin.5m.sh
+	#!/bin/sh
+	exec /opt/homebrew/bin/merlin widget
+
+	# sketchybar plugin (bash)
+	mapfile -t props < <(merlin widget --format sketchybar)
+	sketchybar --set "$NAME" "${props[@]}"`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runWidget(cmd); err != nil {
+			cli.Error("%v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(widgetCmd)
+	widgetCmd.Flags().String("format", "xbar", "Output format: xbar or sketchybar")
+}
+
+// widgetStaleAfter is the age after which the drift summary is marked stale
+const widgetStaleAfter = 24 * time.Hour
+
+// widgetStatus is what the widget shows, read from cached state
+type widgetStatus struct {
+	Drift *state.Drift   // nil before the first diff
+	Links map[string]int // tool count per cached link status
+	Now   time.Time
+}
+
+func runWidget(cmd *cobra.Command) error {
+	format, _ := cmd.Flags().GetString("format")
+
+	status := widgetStatus{Links: make(map[string]int), Now: time.Now()}
+	if drift, err := state.LoadDrift(); err == nil {
+		status.Drift = drift
+	}
+	if repo, err := config.FindDotfilesRepo(); err == nil {
+		prefix := repo.Root + string(os.PathSeparator)
+		for root, s := range state.LoadLinkStateCache().Tools {
+			if strings.HasPrefix(root, prefix) {
+				status.Links[s.Status()]++
+			}
+		}
+	}
+
+	// Menu bar apps run plugins with a minimal PATH, so actions use the full path
+	merlin, err := os.Executable()
+	if err != nil {
+		merlin = "merlin"
+	}
+
+	switch format {
+	case "xbar":
+		renderXbar(os.Stdout, status, merlin)
+	case "sketchybar":
+		renderSketchybar(os.Stdout, status, merlin)
+	default:
+		return fmt.Errorf("unknown format %q (must be: xbar or sketchybar)", format)
+	}
+	return nil
+}
+
+// summary returns the menu bar text and whether it needs attention
+func (s widgetStatus) summary() (string, bool) {
+	if s.Drift == nil {
+		return "?", true
+	}
+	return s.Drift.Status(), s.Drift.Total() > 0
+}
+
+// details returns the lines shown under the summary
+func (s widgetStatus) details() []string {
+	var lines []string
+	if s.Drift == nil {
+		lines = append(lines, "No drift summary yet: run merlin diff")
+	} else {
+		line := fmt.Sprintf("Checked %s ago", s.Drift.Format("{age}", s.Now))
+		if s.Now.Sub(s.Drift.CollectedAt) > widgetStaleAfter {
+			line += " (stale)"
+		}
+		lines = append(lines, line)
+		for _, c := range []struct {
+			n     int
+			label string
+		}{{s.Drift.Missing, "missing"}, {s.Drift.Broken, "broken"}, {s.Drift.Extra, "undeclared"}} {
+			if c.n > 0 {
+				lines = append(lines, fmt.Sprintf("%d %s", c.n, c.label))
+			}
+		}
+	}
+	var links []string
+	for _, status := range []string{"linked", "partial", "conflict", "unlinked"} {
+		if n := s.Links[status]; n > 0 {
+			links = append(links, fmt.Sprintf("%d %s", n, status))
+		}
+	}
+	if len(links) > 0 {
+		lines = append(lines, "Tools: "+strings.Join(links, ", "))
+	}
+	return lines
+}
+
+// renderXbar writes the xbar/SwiftBar plugin format
+func renderXbar(w io.Writer, s widgetStatus, merlin string) {
+	text, attention := s.summary()
+	color := ""
+	if attention {
+		color = " | color=orange"
+	}
+	fmt.Fprintf(w, "merlin %s%s\n", text, color)
+	fmt.Fprintln(w, "---")
+	for _, line := range s.details() {
+		fmt.Fprintln(w, line)
+	}
+	fmt.Fprintln(w, "---")
+	for _, action := range []struct {
+		label    string
+		args     []string
+		terminal bool
+	}{
+		{"Refresh", []string{"prompt", "--refresh"}, false},
+		{"Show diff…", []string{"diff"}, true},
+		{"Link all tools…", []string{"link", "--all"}, true},
+		{"Open merlin…", []string{"tui"}, true},
+	} {
+		fmt.Fprintf(w, "%s | shell=%s", action.label, merlin)
+		for i, arg := range action.args {
+			fmt.Fprintf(w, " param%d=%s", i+1, arg)
+		}
+		fmt.Fprintf(w, " terminal=%t refresh=true\n", action.terminal)
+	}
+}
+
+// renderSketchybar writes one sketchybar property per line
+func renderSketchybar(w io.Writer, s widgetStatus, merlin string) {
+	text, attention := s.summary()
+	color := "0xffa6da95" // green
+	if attention {
+		color = "0xfff5a97f" // orange
+	}
+	fmt.Fprintf(w, "label=%s\n", text)
+	fmt.Fprintf(w, "label.color=%s\n", color)
+	fmt.Fprintf(w, "click_script=osascript -e 'tell application \"Terminal\" to do script \"%s diff\"'\n", merlin)
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ildx/merlin/internal/state"
+)
+
+func TestRenderXbar(t *testing.T) {
+	now := time.Date(2025, 1, 8, 15, 0, 0, 0, time.UTC)
+
+	var out bytes.Buffer
+	renderXbar(&out, widgetStatus{Now: now}, "/bin/merlin")
+	if first := strings.SplitN(out.String(), "\n", 2)[0]; first != "merlin ? | color=orange" {
+		t.Errorf("summary without drift = %q", first)
+	}
+
+	out.Reset()
+	renderXbar(&out, widgetStatus{
+		Drift: &state.Drift{CollectedAt: now.Add(-48 * time.Hour), Missing: 3, Broken: 2},
+		Links: map[string]int{"linked": 4, "conflict": 1},
+		Now:   now,
+	}, "/bin/merlin")
+	got := out.String()
+	for _, want := range []string{
+		"merlin 3⇡ 2✘ | color=orange\n---\n",
+		"Checked 2d ago (stale)\n3 missing\n2 broken\nTools: 4 linked, 1 conflict\n",
+		"Show diff… | shell=/bin/merlin param1=diff terminal=true refresh=true\n",
+		"Refresh | shell=/bin/merlin param1=prompt param2=--refresh terminal=false refresh=true\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestRenderSketchybar(t *testing.T) {
+	var out bytes.Buffer
+	renderSketchybar(&out, widgetStatus{Drift: &state.Drift{}}, "/bin/merlin")
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || lines[0] != "label=✔" || lines[1] != "label.color=0xffa6da95" {
+		t.Errorf("properties = %q", lines)
+	}
+}
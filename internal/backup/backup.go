@@ -4,21 +4,34 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"sort"
 	"time"
+
+	"github.com/ildx/merlin/internal/protect"
 )
 
 // BackupManifest contains metadata about a backup operation
 type BackupManifest struct {
-	ID        string        `json:"id"`         // Timestamp-based unique identifier
-	Timestamp time.Time     `json:"timestamp"`  // When backup was created
-	Reason    string        `json:"reason"`     // Why this backup was created
-	Files     []BackupEntry `json:"files"`      // Files included in this backup
-	MerlinDir string        `json:"merlin_dir"` // Base Merlin directory at time of backup
+	ID        string        `json:"id"`                  // Timestamp-based unique identifier
+	Timestamp time.Time     `json:"timestamp"`           // When backup was created
+	Reason    string        `json:"reason"`              // Why this backup was created
+	Files     []BackupEntry `json:"files"`               // Files included in this backup
+	MerlinDir string        `json:"merlin_dir"`          // Base Merlin directory at time of backup
+	Operation string        `json:"operation,omitempty"` // ID of the merlin run that created it
+	Tool      string        `json:"tool,omitempty"`      // Tool being linked when the backup was made
+
+	dir string // directory the manifest was loaded from
+}
+
+// Dir returns the directory holding the backup, which may be any of the
+// backup roots
+func (m *BackupManifest) Dir() string {
+	return m.dir
 }
 
 // BackupEntry represents a single backed up file
@@ -29,15 +42,6 @@ type BackupEntry struct {
 	Checksum     string `json:"checksum"`      // SHA256 hash for integrity verification
 }
 
-// BackupLocation returns the base directory for all backups
-func BackupLocation() (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("get home directory: %w", err)
-	}
-	return filepath.Join(home, ".merlin", "backups"), nil
-}
-
 // GenerateBackupID creates a unique backup identifier from current timestamp
 func GenerateBackupID() string {
 	return time.Now().Format("20060102_150405")
@@ -45,19 +49,42 @@ func GenerateBackupID() string {
 
 // CreateBackup copies files to a new backup location and generates manifest
 func CreateBackup(files []string, reason string) (*BackupManifest, error) {
+	return CreateToolBackup(files, reason, "")
+}
+
+// CreateToolBackup creates a backup like CreateBackup, attributed to the
+// tool whose links replace the files
+func CreateToolBackup(files []string, reason, tool string) (*BackupManifest, error) {
 	if len(files) == 0 {
 		return nil, fmt.Errorf("no files specified for backup")
 	}
 
-	backupID := GenerateBackupID()
 	baseDir, err := BackupLocation()
 	if err != nil {
 		return nil, err
 	}
+	if err := os.MkdirAll(baseDir, 0755); err != nil {
+		return nil, fmt.Errorf("create backup directory: %w", err)
+	}
 
+	// IDs have one-second resolution; a second backup within the same second
+	// (e.g. a pre-restore safety backup) gets a numeric suffix instead of
+	// overwriting the first. IDs already taken in another backup root are
+	// skipped too, or the older backup would shadow the new one.
+	backupID := GenerateBackupID()
 	backupDir := filepath.Join(baseDir, backupID)
-	if err := os.MkdirAll(backupDir, 0755); err != nil {
-		return nil, fmt.Errorf("create backup directory: %w", err)
+	for n := 2; ; n++ {
+		if _, err := findBackup(backupID); err != nil {
+			err := os.Mkdir(backupDir, 0755)
+			if err == nil {
+				break
+			}
+			if !os.IsExist(err) {
+				return nil, fmt.Errorf("create backup directory: %w", err)
+			}
+		}
+		backupID = fmt.Sprintf("%s_%d", GenerateBackupID(), n)
+		backupDir = filepath.Join(baseDir, backupID)
 	}
 
 	manifest := &BackupManifest{
@@ -65,12 +92,18 @@ func CreateBackup(files []string, reason string) (*BackupManifest, error) {
 		Timestamp: time.Now(),
 		Reason:    reason,
 		Files:     make([]BackupEntry, 0, len(files)),
+		Operation: operation,
+		Tool:      tool,
+		dir:       backupDir,
 	}
 
 	// Get Merlin directory for reference
 	home, _ := os.UserHomeDir()
 	manifest.MerlinDir = filepath.Join(home, ".merlin")
 
+	// Track names used inside the backup dir so same-named files don't collide
+	usedNames := make(map[string]bool)
+
 	// Copy each file to backup location
 	for _, originalPath := range files {
 		// Expand home directory
@@ -92,8 +125,12 @@ func CreateBackup(files []string, reason string) (*BackupManifest, error) {
 			continue
 		}
 
-		// Calculate relative backup path to preserve structure
+		// Calculate relative backup path; disambiguate duplicate base names
 		relPath := filepath.Base(originalPath)
+		for n := 1; usedNames[relPath]; n++ {
+			relPath = fmt.Sprintf("%d_%s", n, filepath.Base(originalPath))
+		}
+		usedNames[relPath] = true
 		backupFilePath := filepath.Join(backupDir, relPath)
 
 		// Copy file
@@ -125,33 +162,39 @@ func CreateBackup(files []string, reason string) (*BackupManifest, error) {
 	return manifest, nil
 }
 
-// ListBackups returns all available backups sorted by timestamp (newest first)
+// ListBackups returns all available backups across BackupRoots sorted by
+// timestamp (newest first). An ID present in several roots is listed once,
+// from the first root holding it.
 func ListBackups() ([]*BackupManifest, error) {
-	baseDir, err := BackupLocation()
+	roots, err := BackupRoots()
 	if err != nil {
 		return nil, err
 	}
 
-	entries, err := os.ReadDir(baseDir)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return []*BackupManifest{}, nil // No backups yet
+	manifests := []*BackupManifest{}
+	seen := make(map[string]bool)
+	for _, baseDir := range roots {
+		entries, err := os.ReadDir(baseDir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue // No backups here yet
+			}
+			return nil, fmt.Errorf("read backup directory: %w", err)
 		}
-		return nil, fmt.Errorf("read backup directory: %w", err)
-	}
 
-	var manifests []*BackupManifest
-	for _, entry := range entries {
-		if !entry.IsDir() {
-			continue
-		}
+		for _, entry := range entries {
+			if !entry.IsDir() || seen[entry.Name()] {
+				continue
+			}
 
-		manifestPath := filepath.Join(baseDir, entry.Name(), "manifest.json")
-		manifest, err := loadManifest(manifestPath)
-		if err != nil {
-			continue // Skip invalid backups
+			manifestPath := filepath.Join(baseDir, entry.Name(), "manifest.json")
+			manifest, err := loadManifest(manifestPath)
+			if err != nil {
+				continue // Skip invalid backups
+			}
+			seen[entry.Name()] = true
+			manifests = append(manifests, manifest)
 		}
-		manifests = append(manifests, manifest)
 	}
 
 	// Sort by timestamp, newest first
@@ -164,68 +207,212 @@ func ListBackups() ([]*BackupManifest, error) {
 
 // GetBackupInfo loads and returns a specific backup manifest
 func GetBackupInfo(backupID string) (*BackupManifest, error) {
-	baseDir, err := BackupLocation()
+	backupDir, err := findBackup(backupID)
 	if err != nil {
 		return nil, err
 	}
 
-	manifestPath := filepath.Join(baseDir, backupID, "manifest.json")
+	manifestPath := filepath.Join(backupDir, "manifest.json")
 	return loadManifest(manifestPath)
 }
 
-// RestoreBackup restores files from a backup, optionally filtering by specific files
-func RestoreBackup(backupID string, selectiveFiles []string) error {
+// CreateSafetyBackup backs up the files a restore of backupID would overwrite,
+// so the restore itself can be undone. mapping is the same explicit path map
+// passed to RestoreBackupMapped. It returns nil when none of the files
+// currently exist.
+func CreateSafetyBackup(backupID string, selectiveFiles []string, mapping PathMap) (*BackupManifest, error) {
 	manifest, err := GetBackupInfo(backupID)
 	if err != nil {
-		return fmt.Errorf("load backup manifest: %w", err)
+		return nil, fmt.Errorf("load backup manifest: %w", err)
 	}
 
-	// Create set of selective files for quick lookup
-	selective := make(map[string]bool)
-	for _, f := range selectiveFiles {
-		selective[f] = true
+	var existing []string
+	for _, entry := range restoreEntries(manifest, selectiveFiles, mapping) {
+		if info, err := os.Stat(entry.OriginalPath); err == nil && info.Mode().IsRegular() {
+			existing = append(existing, entry.OriginalPath)
+		}
+	}
+	if len(existing) == 0 {
+		return nil, nil
 	}
 
-	for _, entry := range manifest.Files {
-		// Skip if selective restore and file not in list
-		if len(selectiveFiles) > 0 && !selective[entry.OriginalPath] {
-			continue
+	return CreateToolBackup(existing, fmt.Sprintf("pre-restore of %s", backupID), manifest.Tool)
+}
+
+// RestoreStatus is the outcome of restoring one file from a backup
+type RestoreStatus string
+
+const (
+	RestoreRestored     RestoreStatus = "restored"
+	RestoreWouldRestore RestoreStatus = "would restore" // dry run
+	RestoreSkipped      RestoreStatus = "skipped"       // target already matches the backup
+	RestoreFailed       RestoreStatus = "failed"
+)
+
+// RestoreResult is the outcome of restoring a single backed up file
+type RestoreResult struct {
+	Path    string // Target path, after remapping
+	Status  RestoreStatus
+	Warning string // Set when the target changed after the backup was taken
+	Err     error  // Set when Status is RestoreFailed
+}
+
+// RestoreBackup restores files from a backup, optionally filtering by specific files
+func RestoreBackup(backupID string, selectiveFiles []string) error {
+	return RestoreBackupMapped(backupID, selectiveFiles, nil)
+}
+
+// RestoreBackupMapped restores files from a backup with original paths
+// rewritten by RestorePathMap, so backups taken under another home directory
+// land in the current one. selectiveFiles may name either the original or
+// the remapped paths. It returns the errors of every file that failed.
+func RestoreBackupMapped(backupID string, selectiveFiles []string, mapping PathMap) error {
+	results, err := RestoreBackupResults(backupID, selectiveFiles, mapping, false)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, result := range results {
+		if result.Err != nil {
+			errs = append(errs, result.Err)
 		}
+	}
+	return errors.Join(errs...)
+}
+
+// RestoreBackupResults restores files like RestoreBackupMapped but reports
+// each file's outcome instead of an error, carrying on past files that fail
+// verification or can't be written. With dryRun nothing is written and files
+// that would be restored are reported as RestoreWouldRestore. The returned
+// error is only set when the backup can't be loaded or a target is
+// protected, in which case nothing is restored.
+func RestoreBackupResults(backupID string, selectiveFiles []string, mapping PathMap, dryRun bool) ([]RestoreResult, error) {
+	manifest, err := GetBackupInfo(backupID)
+	if err != nil {
+		return nil, fmt.Errorf("load backup manifest: %w", err)
+	}
 
-		// Verify backup file still exists and checksum matches
-		if err := verifyBackupFile(entry); err != nil {
-			return fmt.Errorf("verify backup file %s: %w", entry.BackupPath, err)
+	entries := restoreEntries(manifest, selectiveFiles, mapping)
+	for _, entry := range entries {
+		// Refuse before restoring anything so a protected path never leaves a partial restore
+		if err := protect.Check(entry.OriginalPath); err != nil {
+			return nil, err
 		}
+	}
+
+	results := make([]RestoreResult, 0, len(entries))
+	for _, entry := range entries {
+		results = append(results, restoreEntry(manifest, entry, dryRun))
+	}
+	return results, nil
+}
+
+// restoreEntry verifies one backed up file and copies it over its target
+func restoreEntry(manifest *BackupManifest, entry BackupEntry, dryRun bool) RestoreResult {
+	result := RestoreResult{Path: entry.OriginalPath, Status: RestoreRestored}
+	fail := func(err error) RestoreResult {
+		result.Status, result.Err = RestoreFailed, err
+		return result
+	}
 
-		// Ensure target directory exists
-		targetDir := filepath.Dir(entry.OriginalPath)
-		if err := os.MkdirAll(targetDir, 0755); err != nil {
-			return fmt.Errorf("create target directory %s: %w", targetDir, err)
+	// Verify backup file still exists and checksum matches
+	if err := verifyBackupFile(entry); err != nil {
+		return fail(fmt.Errorf("verify backup file %s: %w", entry.BackupPath, err))
+	}
+
+	if info, err := os.Stat(entry.OriginalPath); err == nil && info.Mode().IsRegular() {
+		if sum, err := calculateChecksum(entry.OriginalPath); err == nil && sum == entry.Checksum {
+			result.Status = RestoreSkipped
+			return result
+		}
+		if info.ModTime().After(manifest.Timestamp) {
+			result.Warning = fmt.Sprintf("target modified %s, after the backup", info.ModTime().Format("2006-01-02 15:04"))
 		}
+	}
+
+	if dryRun {
+		result.Status = RestoreWouldRestore
+		return result
+	}
 
-		// Copy file back to original location
-		if err := copyFile(entry.BackupPath, entry.OriginalPath); err != nil {
-			return fmt.Errorf("restore file %s: %w", entry.OriginalPath, err)
+	// Ensure target directory exists
+	targetDir := filepath.Dir(entry.OriginalPath)
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		return fail(fmt.Errorf("create target directory %s: %w", targetDir, err))
+	}
+
+	// A symlink made over the file (e.g. by a link run with the backup
+	// strategy) is replaced; copying through it would overwrite its source
+	if info, err := os.Lstat(entry.OriginalPath); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		if err := os.Remove(entry.OriginalPath); err != nil {
+			return fail(fmt.Errorf("remove symlink %s: %w", entry.OriginalPath, err))
 		}
 	}
 
-	return nil
+	// Copy file back to original location
+	if err := copyFile(entry.BackupPath, entry.OriginalPath); err != nil {
+		return fail(fmt.Errorf("restore file %s: %w", entry.OriginalPath, err))
+	}
+	return result
 }
 
 // DeleteBackup removes a backup and its manifest
 func DeleteBackup(backupID string) error {
-	baseDir, err := BackupLocation()
+	backupDir, err := findBackup(backupID)
 	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil // Already gone
+		}
 		return err
 	}
 
-	backupDir := filepath.Join(baseDir, backupID)
 	return os.RemoveAll(backupDir)
 }
 
 // Helper functions
 
+// restoreEntries returns the manifest entries selected for restore (all of
+// them, or only those listed in selectiveFiles) with OriginalPath remapped
+// and BackupPath resolved against the directory the backup was found in
+func restoreEntries(manifest *BackupManifest, selectiveFiles []string, mapping PathMap) []BackupEntry {
+	pathMap := RestorePathMap(manifest, mapping)
+	backupDir := manifest.Dir()
+	if backupDir == "" {
+		if baseDir, err := BackupLocation(); err == nil {
+			backupDir = filepath.Join(baseDir, manifest.ID)
+		}
+	}
+
+	// Create set of selective files for quick lookup
+	selective := make(map[string]bool)
+	for _, f := range selectiveFiles {
+		selective[f] = true
+	}
+
+	var entries []BackupEntry
+	for _, entry := range manifest.Files {
+		target := pathMap.Apply(entry.OriginalPath)
+		// Skip if selective restore and file not in list
+		if len(selectiveFiles) > 0 && !selective[entry.OriginalPath] && !selective[target] {
+			continue
+		}
+		entry.OriginalPath = target
+		// A backup copied from another machine still records its old location
+		if _, err := os.Stat(entry.BackupPath); err != nil && backupDir != "" {
+			entry.BackupPath = filepath.Join(backupDir, filepath.Base(entry.BackupPath))
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// copyFile copies src to dst with src's permissions. A new dst is cloned when
+// the filesystem supports it (APFS), falling back to a byte copy.
 func copyFile(src, dst string) error {
+	if _, err := os.Lstat(dst); os.IsNotExist(err) && cloneFile(src, dst) == nil {
+		return nil
+	}
+
 	source, err := os.Open(src)
 	if err != nil {
 		return err
@@ -307,5 +494,6 @@ func loadManifest(path string) (*BackupManifest, error) {
 	if err := json.Unmarshal(data, &manifest); err != nil {
 		return nil, err
 	}
+	manifest.dir = filepath.Dir(path)
 	return &manifest, nil
 }
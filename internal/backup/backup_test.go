@@ -1,10 +1,13 @@
 package backup
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
 	"time"
+
+	"github.com/ildx/merlin/internal/protect"
 )
 
 func TestGenerateBackupID(t *testing.T) {
@@ -142,6 +145,43 @@ func TestSelectiveRestore(t *testing.T) {
 	}
 }
 
+func TestCreateBackupSameBaseName(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", originalHome)
+
+	// Two files sharing a base name, as happens when backing up many tool configs
+	fileA := filepath.Join(tmpDir, "a", "config.toml")
+	fileB := filepath.Join(tmpDir, "b", "config.toml")
+	os.MkdirAll(filepath.Dir(fileA), 0755)
+	os.MkdirAll(filepath.Dir(fileB), 0755)
+	os.WriteFile(fileA, []byte("a"), 0644)
+	os.WriteFile(fileB, []byte("b"), 0644)
+
+	manifest, err := CreateBackup([]string{fileA, fileB}, "collision test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Files[0].BackupPath == manifest.Files[1].BackupPath {
+		t.Fatalf("backup paths collide: %s", manifest.Files[0].BackupPath)
+	}
+
+	os.WriteFile(fileA, []byte("changed"), 0644)
+	os.WriteFile(fileB, []byte("changed"), 0644)
+	if err := RestoreBackup(manifest.ID, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, _ := os.ReadFile(fileA); string(got) != "a" {
+		t.Errorf("fileA restored as %q, want %q", got, "a")
+	}
+	if got, _ := os.ReadFile(fileB); string(got) != "b" {
+		t.Errorf("fileB restored as %q, want %q", got, "b")
+	}
+}
+
 func TestListBackups(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -281,3 +321,309 @@ func TestChecksumVerification(t *testing.T) {
 		t.Error("Expected restore to fail with corrupted backup")
 	}
 }
+
+func TestRestoreBackupResults(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", originalHome)
+
+	changed := filepath.Join(tmpDir, "changed.txt")
+	same := filepath.Join(tmpDir, "same.txt")
+	corrupt := filepath.Join(tmpDir, "corrupt.txt")
+	for _, f := range []string{changed, same, corrupt} {
+		os.WriteFile(f, []byte("original "+filepath.Base(f)), 0644)
+	}
+
+	manifest, err := CreateBackup([]string{changed, same, corrupt}, "results test")
+	if err != nil {
+		t.Fatalf("CreateBackup failed: %v", err)
+	}
+	os.WriteFile(changed, []byte("edited"), 0644)
+	later := manifest.Timestamp.Add(time.Hour)
+	os.Chtimes(changed, later, later)
+	for _, entry := range manifest.Files {
+		if entry.OriginalPath == corrupt {
+			os.WriteFile(entry.BackupPath, []byte("corrupted"), 0644)
+		}
+	}
+
+	statuses := func(results []RestoreResult) map[string]RestoreResult {
+		byPath := make(map[string]RestoreResult)
+		for _, r := range results {
+			byPath[r.Path] = r
+		}
+		return byPath
+	}
+
+	results, err := RestoreBackupResults(manifest.ID, nil, nil, true)
+	if err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+	got := statuses(results)
+	if got[changed].Status != RestoreWouldRestore || got[changed].Warning == "" {
+		t.Errorf("changed.txt: got %+v, want would restore with a newer-target warning", got[changed])
+	}
+	if got[same].Status != RestoreSkipped {
+		t.Errorf("same.txt: got %s, want skipped", got[same].Status)
+	}
+	if got[corrupt].Status != RestoreFailed || got[corrupt].Err == nil {
+		t.Errorf("corrupt.txt: got %+v, want failed", got[corrupt])
+	}
+	if data, _ := os.ReadFile(changed); string(data) != "edited" {
+		t.Errorf("dry run wrote changed.txt: %q", data)
+	}
+
+	// A failing file doesn't stop the others from being restored
+	results, err = RestoreBackupResults(manifest.ID, nil, nil, false)
+	if err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	got = statuses(results)
+	if got[changed].Status != RestoreRestored || got[corrupt].Status != RestoreFailed {
+		t.Errorf("unexpected results: %+v", results)
+	}
+	if data, _ := os.ReadFile(changed); string(data) != "original changed.txt" {
+		t.Errorf("changed.txt not restored: %q", data)
+	}
+	if err := RestoreBackup(manifest.ID, nil); err == nil {
+		t.Error("expected RestoreBackup to report the corrupt file")
+	}
+}
+
+func TestRestoreBackupProtected(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", originalHome)
+
+	plain := filepath.Join(tmpDir, "plain.txt")
+	keys := filepath.Join(tmpDir, ".ssh", "authorized_keys")
+	os.MkdirAll(filepath.Dir(keys), 0700)
+	os.WriteFile(plain, []byte("old"), 0644)
+	os.WriteFile(keys, []byte("old keys"), 0600)
+
+	manifest, err := CreateBackup([]string{plain, keys}, "test backup")
+	if err != nil {
+		t.Fatalf("CreateBackup failed: %v", err)
+	}
+	os.WriteFile(plain, []byte("new"), 0644)
+	os.WriteFile(keys, []byte("new keys"), 0600)
+
+	protect.Set([]string{"~/.ssh"}, tmpDir)
+	defer protect.Set(nil, "")
+
+	if err := RestoreBackup(manifest.ID, nil); !errors.Is(err, protect.ErrProtected) {
+		t.Fatalf("expected protected error, got %v", err)
+	}
+	// Nothing is restored when any file is refused
+	if data, _ := os.ReadFile(plain); string(data) != "new" {
+		t.Errorf("plain.txt restored despite refusal: %q", data)
+	}
+	if data, _ := os.ReadFile(keys); string(data) != "new keys" {
+		t.Errorf("protected file restored: %q", data)
+	}
+
+	// Selective restore of unprotected files still works
+	if err := RestoreBackup(manifest.ID, []string{plain}); err != nil {
+		t.Fatalf("selective restore failed: %v", err)
+	}
+	if data, _ := os.ReadFile(plain); string(data) != "old" {
+		t.Errorf("plain.txt not restored: %q", data)
+	}
+}
+
+func TestCreateSafetyBackup(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", originalHome)
+
+	kept := filepath.Join(tmpDir, "kept.txt")
+	removed := filepath.Join(tmpDir, "removed.txt")
+	os.WriteFile(kept, []byte("old"), 0644)
+	os.WriteFile(removed, []byte("old"), 0644)
+
+	manifest, err := CreateBackup([]string{kept, removed}, "test backup")
+	if err != nil {
+		t.Fatalf("CreateBackup failed: %v", err)
+	}
+	os.WriteFile(kept, []byte("current"), 0644)
+	os.Remove(removed)
+
+	// Created within the same second as the original; must not overwrite it
+	safety, err := CreateSafetyBackup(manifest.ID, nil, nil)
+	if err != nil {
+		t.Fatalf("CreateSafetyBackup failed: %v", err)
+	}
+	if safety == nil || safety.ID == manifest.ID {
+		t.Fatalf("expected a separate safety backup, got %+v", safety)
+	}
+	if want := "pre-restore of " + manifest.ID; safety.Reason != want {
+		t.Errorf("reason = %q, want %q", safety.Reason, want)
+	}
+	// Only files that currently exist are saved
+	if len(safety.Files) != 1 || safety.Files[0].OriginalPath != kept {
+		t.Fatalf("unexpected safety backup files: %+v", safety.Files)
+	}
+
+	if err := RestoreBackup(manifest.ID, nil); err != nil {
+		t.Fatalf("RestoreBackup failed: %v", err)
+	}
+	if original, err := GetBackupInfo(manifest.ID); err != nil || len(original.Files) != 2 {
+		t.Fatalf("original backup damaged: %v", err)
+	}
+
+	// Restoring the safety backup undoes the restore
+	if err := RestoreBackup(safety.ID, nil); err != nil {
+		t.Fatalf("undo failed: %v", err)
+	}
+	if data, _ := os.ReadFile(kept); string(data) != "current" {
+		t.Errorf("kept.txt = %q after undo, want %q", data, "current")
+	}
+
+	// Nothing to save when the selected files don't exist
+	os.Remove(kept)
+	if safety, err := CreateSafetyBackup(manifest.ID, []string{kept}, nil); err != nil || safety != nil {
+		t.Errorf("expected no safety backup, got %+v, %v", safety, err)
+	}
+}
+
+func TestBackupOperation(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	file := filepath.Join(tmpDir, "file.txt")
+	os.WriteFile(file, []byte("content"), 0644)
+
+	first := StartOperation()
+	a, err := CreateBackup([]string{file}, "first")
+	if err != nil {
+		t.Fatalf("CreateBackup failed: %v", err)
+	}
+	second := StartOperation()
+	if second == first || Operation() != second {
+		t.Fatalf("StartOperation did not start a new operation: %q, %q", first, second)
+	}
+	b, err := CreateBackup([]string{file}, "second")
+	if err != nil {
+		t.Fatalf("CreateBackup failed: %v", err)
+	}
+
+	backups, err := ListBackups()
+	if err != nil {
+		t.Fatalf("ListBackups failed: %v", err)
+	}
+	if got := FilterByOperation(backups, first); len(got) != 1 || got[0].ID != a.ID {
+		t.Errorf("FilterByOperation(first) = %v", got)
+	}
+	if got := FilterByOperation(backups, second); len(got) != 1 || got[0].ID != b.ID {
+		t.Errorf("FilterByOperation(second) = %v", got)
+	}
+}
+
+func TestCopyFileClone(t *testing.T) {
+	tmpDir := t.TempDir()
+	src := filepath.Join(tmpDir, "src")
+	os.WriteFile(src, []byte("payload"), 0600)
+
+	var cloned []string
+	orig := cloneFile
+	defer func() { cloneFile = orig }()
+	cloneFile = func(src, dst string) error {
+		cloned = append(cloned, dst)
+		return os.Link(src, dst)
+	}
+
+	// A new destination is cloned
+	dst := filepath.Join(tmpDir, "clone")
+	if err := copyFile(src, dst); err != nil || len(cloned) != 1 {
+		t.Fatalf("copyFile() = %v with clones %v", err, cloned)
+	}
+
+	// An existing destination is overwritten by copying
+	os.WriteFile(filepath.Join(tmpDir, "existing"), []byte("old"), 0644)
+	if err := copyFile(src, filepath.Join(tmpDir, "existing")); err != nil || len(cloned) != 1 {
+		t.Fatalf("copyFile(existing) = %v with clones %v", err, cloned)
+	}
+
+	// Unsupported clones fall back to a byte copy with the same permissions
+	cloneFile = func(string, string) error { return errCloneUnsupported }
+	dst = filepath.Join(tmpDir, "copy")
+	if err := copyFile(src, dst); err != nil {
+		t.Fatalf("copyFile(fallback) error = %v", err)
+	}
+	for _, path := range []string{filepath.Join(tmpDir, "existing"), dst} {
+		data, _ := os.ReadFile(path)
+		info, _ := os.Stat(path)
+		if string(data) != "payload" || info.Mode().Perm() != 0600 {
+			t.Errorf("%s = %q (%v), want payload (0600)", path, data, info.Mode().Perm())
+		}
+	}
+}
+
+func TestGroupByOperation(t *testing.T) {
+	now := time.Now()
+	manifests := []*BackupManifest{
+		{ID: "c", Timestamp: now, Operation: "op_2", Tool: "zsh", Reason: "Before linking a", Files: make([]BackupEntry, 1)},
+		{ID: "b", Timestamp: now.Add(-time.Second), Operation: "op_2", Tool: "git", Reason: "Before linking b", Files: make([]BackupEntry, 2)},
+		{ID: "a", Timestamp: now.Add(-time.Hour), Operation: "op_1", Reason: "manual", Files: make([]BackupEntry, 1)},
+		{ID: "old", Timestamp: now.Add(-2 * time.Hour), Reason: "legacy"},
+	}
+
+	batches := GroupByOperation(manifests)
+	if len(batches) != 3 {
+		t.Fatalf("Expected 3 batches, got %d", len(batches))
+	}
+	run := batches[0]
+	if run.ID() != "op_2" || run.Files() != 3 || !run.Timestamp().Equal(now.Add(-time.Second)) {
+		t.Errorf("link run batch = %s, %d files, %v", run.ID(), run.Files(), run.Timestamp())
+	}
+	if tools := run.Tools(); len(tools) != 2 || tools[0] != "git" || tools[1] != "zsh" {
+		t.Errorf("Tools() = %v", tools)
+	}
+	if run.Reason() != "2 backups" {
+		t.Errorf("Reason() = %q", run.Reason())
+	}
+	if batches[1].ID() != "a" || batches[1].Reason() != "manual" {
+		t.Errorf("single-backup batch = %s, %q", batches[1].ID(), batches[1].Reason())
+	}
+	if batches[2].ID() != "old" {
+		t.Errorf("backup without operation = %s", batches[2].ID())
+	}
+	if !IsOperationID("op_2") || IsOperationID("20250108_143022") {
+		t.Error("IsOperationID misclassified an ID")
+	}
+}
+
+func TestRestoreReplacesSymlink(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	target := filepath.Join(tmpDir, ".zshrc")
+	source := filepath.Join(tmpDir, "repo", "zshrc")
+	os.MkdirAll(filepath.Dir(source), 0755)
+	os.WriteFile(target, []byte("original"), 0644)
+	os.WriteFile(source, []byte("from repo"), 0644)
+
+	manifest, err := CreateBackup([]string{target}, "before link")
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(target)
+	if err := os.Symlink(source, target); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RestoreBackup(manifest.ID, nil); err != nil {
+		t.Fatalf("RestoreBackup failed: %v", err)
+	}
+	if info, err := os.Lstat(target); err != nil || info.Mode()&os.ModeSymlink != 0 {
+		t.Errorf("target should be a regular file again, got %v, %v", info, err)
+	}
+	if data, _ := os.ReadFile(target); string(data) != "original" {
+		t.Errorf("restored content = %q", data)
+	}
+	if data, _ := os.ReadFile(source); string(data) != "from repo" {
+		t.Errorf("symlink source was overwritten: %q", data)
+	}
+}
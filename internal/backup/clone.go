@@ -0,0 +1,10 @@
+package backup
+
+import "errors"
+
+// errCloneUnsupported is returned where no copy-on-write clone is available
+var errCloneUnsupported = errors.New("file cloning not supported")
+
+// cloneFile makes dst a copy-on-write clone of src, which must not exist yet;
+// replaced in tests
+var cloneFile = clonefile
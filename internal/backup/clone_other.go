@@ -0,0 +1,7 @@
+//go:build !darwin
+
+package backup
+
+func clonefile(src, dst string) error {
+	return errCloneUnsupported
+}
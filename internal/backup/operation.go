@@ -0,0 +1,121 @@
+package backup
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// operation is the ID of the current merlin run, stamped on every backup it
+// creates (see StartOperation)
+var operation string
+
+// StartOperation generates a new operation ID and makes it current, so all
+// backups created by one command can be found together with
+// `merlin backup list --operation <id>`. It returns the ID.
+func StartOperation() string {
+	suffix := make([]byte, 2)
+	rand.Read(suffix)
+	operation = "op_" + time.Now().Format("20060102_150405") + "_" + hex.EncodeToString(suffix)
+	return operation
+}
+
+// Operation returns the current operation ID, or "" if none was started
+func Operation() string {
+	return operation
+}
+
+// FilterByOperation returns the manifests created by the given operation
+func FilterByOperation(manifests []*BackupManifest, id string) []*BackupManifest {
+	var filtered []*BackupManifest
+	for _, m := range manifests {
+		if m.Operation == id {
+			filtered = append(filtered, m)
+		}
+	}
+	return filtered
+}
+
+// IsOperationID reports whether id names an operation rather than a backup
+func IsOperationID(id string) bool {
+	return strings.HasPrefix(id, "op_")
+}
+
+// Batch is the backups one operation created, e.g. one per conflicting
+// target of a link run with the backup strategy
+type Batch struct {
+	Operation string            // empty for a backup made outside any operation
+	Backups   []*BackupManifest // newest first
+}
+
+// ID is what restores the whole batch: the operation ID, or the backup ID
+// when the batch holds a single backup
+func (b *Batch) ID() string {
+	if len(b.Backups) == 1 {
+		return b.Backups[0].ID
+	}
+	return b.Operation
+}
+
+// Timestamp returns when the batch's first backup was taken
+func (b *Batch) Timestamp() time.Time {
+	return b.Backups[len(b.Backups)-1].Timestamp
+}
+
+// Files returns the number of files across the batch's backups
+func (b *Batch) Files() int {
+	n := 0
+	for _, m := range b.Backups {
+		n += len(m.Files)
+	}
+	return n
+}
+
+// Tools returns the sorted names of the tools the backups were made for
+func (b *Batch) Tools() []string {
+	var tools []string
+	for _, m := range b.Backups {
+		if m.Tool != "" && !slices.Contains(tools, m.Tool) {
+			tools = append(tools, m.Tool)
+		}
+	}
+	sort.Strings(tools)
+	return tools
+}
+
+// Reason describes the batch: the backup's reason, or how many backups it
+// holds and the reason they share
+func (b *Batch) Reason() string {
+	if len(b.Backups) == 1 {
+		return b.Backups[0].Reason
+	}
+	reason := b.Backups[0].Reason
+	for _, m := range b.Backups[1:] {
+		if m.Reason != reason {
+			return fmt.Sprintf("%d backups", len(b.Backups))
+		}
+	}
+	return fmt.Sprintf("%d backups: %s", len(b.Backups), reason)
+}
+
+// GroupByOperation groups manifests (sorted newest first) into batches, one
+// per operation, ordered by their newest backup. Backups without an
+// operation are batches of their own.
+func GroupByOperation(manifests []*BackupManifest) []*Batch {
+	var batches []*Batch
+	byOperation := make(map[string]*Batch)
+	for _, m := range manifests {
+		if batch, ok := byOperation[m.Operation]; ok && m.Operation != "" {
+			batch.Backups = append(batch.Backups, m)
+			continue
+		}
+		batch := &Batch{Operation: m.Operation, Backups: []*BackupManifest{m}}
+		byOperation[m.Operation] = batch
+		batches = append(batches, batch)
+	}
+	return batches
+}
@@ -0,0 +1,94 @@
+package backup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// PathMap rewrites path prefixes (old → new) so a backup made under another
+// home directory or on another machine restores to the right place
+type PathMap map[string]string
+
+// ParsePathMap parses "old=new" specs as given to --map. Both sides must be
+// absolute after expanding a leading ~.
+func ParsePathMap(specs []string) (PathMap, error) {
+	home, _ := os.UserHomeDir()
+	m := make(PathMap)
+	for _, spec := range specs {
+		oldPath, newPath, ok := strings.Cut(spec, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid path mapping %q (expected old=new)", spec)
+		}
+		oldPath, newPath = expandHome(strings.TrimSpace(oldPath), home), expandHome(strings.TrimSpace(newPath), home)
+		if !filepath.IsAbs(oldPath) || !filepath.IsAbs(newPath) {
+			return nil, fmt.Errorf("invalid path mapping %q (both paths must be absolute)", spec)
+		}
+		m[filepath.Clean(oldPath)] = filepath.Clean(newPath)
+	}
+	return m, nil
+}
+
+// Apply rewrites path using the longest matching prefix; paths outside every
+// mapped prefix are returned unchanged
+func (m PathMap) Apply(path string) string {
+	best := ""
+	for prefix := range m {
+		if len(prefix) > len(best) && within(path, prefix) {
+			best = prefix
+		}
+	}
+	if best == "" {
+		return path
+	}
+	return filepath.Join(m[best], strings.TrimPrefix(path, best))
+}
+
+// Pairs returns the mappings as sorted "old → new" strings for display
+func (m PathMap) Pairs() []string {
+	pairs := make([]string, 0, len(m))
+	for oldPath, newPath := range m {
+		if oldPath != newPath {
+			pairs = append(pairs, fmt.Sprintf("%s → %s", oldPath, newPath))
+		}
+	}
+	sort.Strings(pairs)
+	return pairs
+}
+
+// RestorePathMap returns the mapping used to restore manifest: its original
+// home directory is mapped to the current one, and explicit entries are
+// added on top (overriding the home mapping for the same prefix)
+func RestorePathMap(manifest *BackupManifest, explicit PathMap) PathMap {
+	m := make(PathMap)
+	home, err := os.UserHomeDir()
+	if err == nil && filepath.Base(manifest.MerlinDir) == ".merlin" {
+		if oldHome := filepath.Dir(manifest.MerlinDir); oldHome != home {
+			m[oldHome] = home
+		}
+	}
+	for oldPath, newPath := range explicit {
+		m[oldPath] = newPath
+	}
+	return m
+}
+
+// within reports whether path is dir or inside it
+func within(path, dir string) bool {
+	if dir == string(filepath.Separator) {
+		return filepath.IsAbs(path)
+	}
+	return path == dir || strings.HasPrefix(path, dir+string(filepath.Separator))
+}
+
+func expandHome(path, home string) string {
+	if path == "~" {
+		return home
+	}
+	if strings.HasPrefix(path, "~/") {
+		return filepath.Join(home, path[2:])
+	}
+	return path
+}
@@ -0,0 +1,100 @@
+package backup
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParsePathMap(t *testing.T) {
+	t.Setenv("HOME", "/home/new")
+
+	m, err := ParsePathMap([]string{"/Users/old=~", "/Volumes/a/=/Volumes/b"})
+	if err != nil {
+		t.Fatalf("ParsePathMap failed: %v", err)
+	}
+	if m["/Users/old"] != "/home/new" || m["/Volumes/a"] != "/Volumes/b" {
+		t.Errorf("unexpected map: %v", m)
+	}
+
+	for _, spec := range []string{"/Users/old", "relative=/x", "/x=relative"} {
+		if _, err := ParsePathMap([]string{spec}); err == nil {
+			t.Errorf("expected error for %q", spec)
+		}
+	}
+}
+
+func TestPathMapApply(t *testing.T) {
+	m := PathMap{
+		"/Users/old":      "/Users/new",
+		"/Users/old/work": "/srv/work",
+	}
+	tests := []struct{ in, want string }{
+		{"/Users/old/.zshrc", "/Users/new/.zshrc"},
+		{"/Users/old", "/Users/new"},
+		{"/Users/old/work/notes.md", "/srv/work/notes.md"}, // longest prefix wins
+		{"/Users/older/.zshrc", "/Users/older/.zshrc"},     // not a path boundary
+		{"/etc/hosts", "/etc/hosts"},
+	}
+	for _, tt := range tests {
+		if got := m.Apply(tt.in); got != tt.want {
+			t.Errorf("Apply(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRestoreBackupFromOtherHome(t *testing.T) {
+	oldHome := t.TempDir()
+	newHome := t.TempDir()
+	t.Setenv("HOME", oldHome)
+
+	rc := filepath.Join(oldHome, ".zshrc")
+	os.WriteFile(rc, []byte("from old machine"), 0644)
+	manifest, err := CreateBackup([]string{rc}, "test backup")
+	if err != nil {
+		t.Fatalf("CreateBackup failed: %v", err)
+	}
+
+	// Copy the backup directory to the new home, as when moving machines
+	oldDir := filepath.Join(oldHome, ".merlin", "backups", manifest.ID)
+	newDir := filepath.Join(newHome, ".merlin", "backups", manifest.ID)
+	os.MkdirAll(newDir, 0755)
+	entries, _ := os.ReadDir(oldDir)
+	for _, e := range entries {
+		data, _ := os.ReadFile(filepath.Join(oldDir, e.Name()))
+		os.WriteFile(filepath.Join(newDir, e.Name()), data, 0644)
+	}
+	os.RemoveAll(oldHome)
+	t.Setenv("HOME", newHome)
+
+	if pairs := RestorePathMap(manifest, nil).Pairs(); len(pairs) != 1 {
+		t.Errorf("expected automatic home mapping, got %v", pairs)
+	}
+
+	// Selecting by the original path still works
+	if err := RestoreBackup(manifest.ID, []string{rc}); err != nil {
+		t.Fatalf("RestoreBackup failed: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(newHome, ".zshrc")); string(data) != "from old machine" {
+		t.Errorf("file not restored into new home: %q", data)
+	}
+
+	// Explicit mappings override the home mapping
+	target := filepath.Join(newHome, "elsewhere")
+	mapping, _ := ParsePathMap([]string{oldHome + "=" + target})
+	if err := RestoreBackupMapped(manifest.ID, nil, mapping); err != nil {
+		t.Fatalf("RestoreBackupMapped failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(target, ".zshrc")); err != nil {
+		t.Errorf("explicit mapping not applied: %v", err)
+	}
+
+	// The manifest itself is unchanged
+	data, _ := os.ReadFile(filepath.Join(newDir, "manifest.json"))
+	var saved BackupManifest
+	json.Unmarshal(data, &saved)
+	if saved.Files[0].OriginalPath != rc {
+		t.Errorf("manifest rewritten: %q", saved.Files[0].OriginalPath)
+	}
+}
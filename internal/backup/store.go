@@ -0,0 +1,177 @@
+package backup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ildx/merlin/internal/protect"
+)
+
+// EnvVarBackupDir overrides where backups are written, taking precedence over
+// backup_dir in the root merlin.toml
+const EnvVarBackupDir = "MERLIN_BACKUP_DIR"
+
+// location and extraRoots are set once at startup (see Configure) and only
+// read afterwards
+var (
+	location   string
+	extraRoots []string
+)
+
+// Configure sets the backup store from backup_dir and backup_roots in the
+// root merlin.toml. Entries may start with ~/, $HOME/ or {home_dir}/;
+// relative entries are ignored.
+func Configure(dir string, roots []string, homeDir string) {
+	location = ""
+	if expanded := protect.Expand(dir, homeDir); dir != "" && filepath.IsAbs(expanded) {
+		location = expanded
+	}
+	extraRoots = nil
+	for _, root := range roots {
+		if expanded := protect.Expand(root, homeDir); filepath.IsAbs(expanded) {
+			extraRoots = append(extraRoots, expanded)
+		}
+	}
+}
+
+// BackupLocation returns the directory new backups are written to:
+// MERLIN_BACKUP_DIR, then backup_dir, then ~/.merlin/backups
+func BackupLocation() (string, error) {
+	if env := os.Getenv(EnvVarBackupDir); env != "" {
+		home, _ := os.UserHomeDir()
+		dir := protect.Expand(env, home)
+		if !filepath.IsAbs(dir) {
+			return "", fmt.Errorf("%s must be an absolute path, got %q", EnvVarBackupDir, env)
+		}
+		return dir, nil
+	}
+	if location != "" {
+		return location, nil
+	}
+	return defaultLocation()
+}
+
+// defaultLocation is where backups live unless configured otherwise
+func defaultLocation() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("get home directory: %w", err)
+	}
+	return filepath.Join(home, ".merlin", "backups"), nil
+}
+
+// BackupRoots returns every directory backups are read from: the backup
+// location first, then backup_roots, then ~/.merlin/backups so backups made
+// before the location changed stay visible until moved with MoveStore
+func BackupRoots() ([]string, error) {
+	primary, err := BackupLocation()
+	if err != nil {
+		return nil, err
+	}
+	roots := []string{primary}
+	seen := map[string]bool{primary: true}
+	candidates := append([]string{}, extraRoots...)
+	if def, err := defaultLocation(); err == nil {
+		candidates = append(candidates, def)
+	}
+	for _, root := range candidates {
+		if !seen[root] {
+			seen[root] = true
+			roots = append(roots, root)
+		}
+	}
+	return roots, nil
+}
+
+// findBackup returns the directory holding backupID, searching the roots in
+// order
+func findBackup(backupID string) (string, error) {
+	if backupID == "" || backupID != filepath.Base(backupID) {
+		return "", fmt.Errorf("invalid backup ID %q", backupID)
+	}
+	roots, err := BackupRoots()
+	if err != nil {
+		return "", err
+	}
+	for _, root := range roots {
+		dir := filepath.Join(root, backupID)
+		if _, err := os.Stat(filepath.Join(dir, "manifest.json")); err == nil {
+			return dir, nil
+		}
+	}
+	return "", fmt.Errorf("backup %s: %w", backupID, os.ErrNotExist)
+}
+
+// MoveResult reports what MoveStore did
+type MoveResult struct {
+	Moved   []string // IDs now stored under the destination
+	Skipped []string // IDs left in place because the destination already has one
+}
+
+// MoveStore moves every backup found under BackupRoots into dest and rewrites
+// their manifests to point at the new files. Backups already in dest are left
+// alone. Moves fall back to copy-and-delete across filesystems.
+func MoveStore(dest string) (*MoveResult, error) {
+	if !filepath.IsAbs(dest) {
+		return nil, fmt.Errorf("destination must be an absolute path, got %q", dest)
+	}
+	dest = filepath.Clean(dest)
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return nil, fmt.Errorf("create backup directory: %w", err)
+	}
+
+	manifests, err := ListBackups()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MoveResult{}
+	for _, m := range manifests {
+		if filepath.Dir(m.Dir()) == dest {
+			continue
+		}
+		target := filepath.Join(dest, m.ID)
+		if _, err := os.Lstat(target); err == nil {
+			result.Skipped = append(result.Skipped, m.ID)
+			continue
+		}
+		if err := moveDir(m.Dir(), target); err != nil {
+			return result, fmt.Errorf("move backup %s: %w", m.ID, err)
+		}
+		for i := range m.Files {
+			m.Files[i].BackupPath = filepath.Join(target, filepath.Base(m.Files[i].BackupPath))
+		}
+		if err := saveManifest(m, filepath.Join(target, "manifest.json")); err != nil {
+			return result, fmt.Errorf("update manifest of %s: %w", m.ID, err)
+		}
+		result.Moved = append(result.Moved, m.ID)
+	}
+	return result, nil
+}
+
+// moveDir renames src to dst, copying the (flat) backup directory and
+// removing the original when a rename isn't possible
+func moveDir(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	if err := os.Mkdir(dst, 0755); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		if err := copyFile(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name())); err != nil {
+			os.RemoveAll(dst)
+			return err
+		}
+	}
+	return os.RemoveAll(src)
+}
@@ -0,0 +1,122 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBackupLocation(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(EnvVarBackupDir, "")
+	t.Cleanup(func() { Configure("", nil, "") })
+
+	Configure("", nil, home)
+	if got, _ := BackupLocation(); got != filepath.Join(home, ".merlin", "backups") {
+		t.Errorf("default location = %s", got)
+	}
+
+	Configure("~/sync/backups", []string{"relative", "/mnt/old"}, home)
+	if got, _ := BackupLocation(); got != filepath.Join(home, "sync", "backups") {
+		t.Errorf("backup_dir location = %s", got)
+	}
+	roots, _ := BackupRoots()
+	want := []string{filepath.Join(home, "sync", "backups"), "/mnt/old", filepath.Join(home, ".merlin", "backups")}
+	if len(roots) != len(want) {
+		t.Fatalf("roots = %v, want %v", roots, want)
+	}
+	for i := range want {
+		if roots[i] != want[i] {
+			t.Errorf("roots[%d] = %s, want %s", i, roots[i], want[i])
+		}
+	}
+
+	t.Setenv(EnvVarBackupDir, "/srv/backups")
+	if got, _ := BackupLocation(); got != "/srv/backups" {
+		t.Errorf("%s location = %s", EnvVarBackupDir, got)
+	}
+	t.Setenv(EnvVarBackupDir, "relative")
+	if _, err := BackupLocation(); err == nil {
+		t.Errorf("relative %s should be rejected", EnvVarBackupDir)
+	}
+}
+
+func TestMultipleRootsAndMoveStore(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(EnvVarBackupDir, "")
+	t.Cleanup(func() { Configure("", nil, "") })
+
+	file := filepath.Join(home, "rc")
+	os.WriteFile(file, []byte("old"), 0644)
+
+	// One backup in the default location, one in a configured root
+	Configure("", nil, home)
+	legacy, err := CreateBackup([]string{file}, "legacy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	external := filepath.Join(home, "external")
+	t.Setenv(EnvVarBackupDir, external)
+	os.WriteFile(file, []byte("new"), 0644)
+	current, err := CreateBackup([]string{file}, "current")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current.ID == legacy.ID {
+		t.Fatalf("backup ID %s reused across roots", current.ID)
+	}
+
+	backups, err := ListBackups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("listed %d backup(s) across roots, want 2", len(backups))
+	}
+	info, err := GetBackupInfo(legacy.ID)
+	if err != nil || info.Dir() != filepath.Join(home, ".merlin", "backups", legacy.ID) {
+		t.Fatalf("legacy backup not found in default root: %v %v", info, err)
+	}
+
+	// Move everything into a new store
+	dest := filepath.Join(home, "moved")
+	result, err := MoveStore(dest)
+	if err != nil {
+		t.Fatalf("MoveStore: %v", err)
+	}
+	if len(result.Moved) != 2 || len(result.Skipped) != 0 {
+		t.Fatalf("moved %v, skipped %v", result.Moved, result.Skipped)
+	}
+	if _, err := os.Stat(legacy.Dir()); !os.IsNotExist(err) {
+		t.Errorf("old backup directory still exists: %v", err)
+	}
+
+	t.Setenv(EnvVarBackupDir, dest)
+	moved, err := GetBackupInfo(legacy.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved.Files[0].BackupPath != filepath.Join(dest, legacy.ID, "rc") {
+		t.Errorf("manifest not rewritten: %s", moved.Files[0].BackupPath)
+	}
+	if err := RestoreBackup(legacy.ID, nil); err != nil {
+		t.Fatalf("restore from moved store: %v", err)
+	}
+	if got, _ := os.ReadFile(file); string(got) != "old" {
+		t.Errorf("restored %q, want %q", got, "old")
+	}
+
+	// Moving again is a no-op
+	if result, err := MoveStore(dest); err != nil || len(result.Moved) != 0 {
+		t.Errorf("second move: %v %v", result, err)
+	}
+}
+
+func TestFindBackupRejectsPaths(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if _, err := GetBackupInfo("../etc"); err == nil {
+		t.Error("expected an error for a backup ID containing a path")
+	}
+}
@@ -0,0 +1,46 @@
+package backup
+
+import (
+	"path/filepath"
+	"time"
+)
+
+// FileVersion is one backed up copy of a file
+type FileVersion struct {
+	Backup *BackupManifest
+	Entry  BackupEntry // as recorded in the manifest (OriginalPath not remapped)
+}
+
+// FileVersions returns every backed up copy of path across all backups,
+// newest first. Backups taken under another home directory match through
+// RestorePathMap, the same way restoring them would.
+func FileVersions(path string) ([]FileVersion, error) {
+	manifests, err := ListBackups()
+	if err != nil {
+		return nil, err
+	}
+	path = filepath.Clean(path)
+
+	var versions []FileVersion
+	for _, manifest := range manifests {
+		pathMap := RestorePathMap(manifest, nil)
+		for _, entry := range manifest.Files {
+			if entry.OriginalPath == path || pathMap.Apply(entry.OriginalPath) == path {
+				versions = append(versions, FileVersion{Backup: manifest, Entry: entry})
+				break
+			}
+		}
+	}
+	return versions, nil
+}
+
+// VersionAt returns the newest of versions (sorted newest first) taken at or
+// before t, or nil when every version is newer
+func VersionAt(versions []FileVersion, t time.Time) *FileVersion {
+	for i := range versions {
+		if !versions[i].Backup.Timestamp.After(t) {
+			return &versions[i]
+		}
+	}
+	return nil
+}
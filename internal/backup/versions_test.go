@@ -0,0 +1,62 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileVersions(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", originalHome)
+
+	zshrc := filepath.Join(tmpDir, ".zshrc")
+	other := filepath.Join(tmpDir, ".gitconfig")
+	os.WriteFile(zshrc, []byte("v1"), 0644)
+	os.WriteFile(other, []byte("git"), 0644)
+
+	if _, err := CreateBackup([]string{zshrc, other}, "first"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(1 * time.Second) // Need full second for different timestamps
+	if _, err := CreateBackup([]string{other}, "only git"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(1 * time.Second)
+	os.WriteFile(zshrc, []byte("v2"), 0644)
+	if _, err := CreateBackup([]string{zshrc}, "second"); err != nil {
+		t.Fatal(err)
+	}
+
+	versions, err := FileVersions(zshrc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("Expected 2 versions, got %d", len(versions))
+	}
+	if versions[0].Backup.Reason != "second" || versions[1].Backup.Reason != "first" {
+		t.Errorf("versions not newest first: %s, %s", versions[0].Backup.Reason, versions[1].Backup.Reason)
+	}
+	if versions[0].Entry.Checksum == versions[1].Entry.Checksum {
+		t.Error("Expected different checksums for different contents")
+	}
+
+	if v := VersionAt(versions, versions[1].Backup.Timestamp.Add(time.Millisecond)); v == nil || v.Backup.Reason != "first" {
+		t.Errorf("VersionAt(first) = %+v", v)
+	}
+	if v := VersionAt(versions, time.Now().Add(time.Hour)); v == nil || v.Backup.Reason != "second" {
+		t.Errorf("VersionAt(now) = %+v", v)
+	}
+	if v := VersionAt(versions, versions[1].Backup.Timestamp.Add(-time.Hour)); v != nil {
+		t.Errorf("VersionAt(before all) = %+v, want nil", v)
+	}
+
+	if versions, _ := FileVersions(filepath.Join(tmpDir, ".bashrc")); len(versions) != 0 {
+		t.Errorf("Expected no versions of an unknown file, got %d", len(versions))
+	}
+}
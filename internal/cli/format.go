@@ -10,6 +10,7 @@ import (
 const (
 	colorReset   = "\033[0m"
 	colorRed     = "\033[31m"
+	colorGreen   = "\033[32m"
 	colorYellow  = "\033[33m"
 	colorBlue    = "\033[34m"
 	colorMagenta = "\033[35m"
@@ -23,8 +24,11 @@ func Error(format string, args ...interface{}) {
 	fmt.Fprintf(os.Stderr, "%s✗ Error:%s %s\n", colorRed, colorReset, msg)
 }
 
-// Warning prints a yellow warning message to stderr.
+// Warning prints a yellow warning message to stderr, except in quiet mode.
 func Warning(format string, args ...interface{}) {
+	if outputMode == OutputQuiet {
+		return
+	}
 	msg := fmt.Sprintf(format, args...)
 	fmt.Fprintf(os.Stderr, "%s⚠ Warning:%s %s\n", colorYellow, colorReset, msg)
 }
@@ -44,6 +48,34 @@ func Success(format string, args ...interface{}) {
 // Dim returns a dimmed (gray) version of a string for inline usage.
 func Dim(s string) string { return colorGray + s + colorReset }
 
+// Diff colors a unified diff (removals red, additions green, hunk headers cyan)
+// and indents every line with prefix.
+func Diff(diff, prefix string) string {
+	var b strings.Builder
+	for _, line := range strings.SplitAfter(strings.TrimSuffix(diff, "\n"), "\n") {
+		line = strings.TrimSuffix(line, "\n")
+		color := ""
+		switch {
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+			color = colorGray
+		case strings.HasPrefix(line, "@@"):
+			color = colorCyan
+		case strings.HasPrefix(line, "-"):
+			color = colorRed
+		case strings.HasPrefix(line, "+"):
+			color = colorGreen
+		}
+		b.WriteString(prefix)
+		if color != "" {
+			b.WriteString(color + line + colorReset)
+		} else {
+			b.WriteString(line)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
 // BulletList formats a slice of strings as an indented bullet list.
 func BulletList(items []string) string {
 	if len(items) == 0 {
@@ -57,3 +89,17 @@ func BulletList(items []string) string {
 	}
 	return b.String()
 }
+
+// FormatBytes renders a byte count with a binary unit suffix (e.g. "1.5 GB").
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
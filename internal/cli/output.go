@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// OutputMode is how much a command prints, selected with --summary or --quiet
+type OutputMode int
+
+const (
+	// OutputNormal prints what the verbosity level allows
+	OutputNormal OutputMode = iota
+	// OutputSummary (--summary) prints final counts and failures only
+	OutputSummary
+	// OutputQuiet (--quiet) prints errors only, for cron and other schedulers
+	OutputQuiet
+)
+
+var (
+	outputMode OutputMode
+	stdout     *os.File // standard output while os.Stdout is redirected
+)
+
+// SetOutputMode applies mode for the rest of the run. Summary and quiet
+// point os.Stdout at the null device, so the progress and per-item lines a
+// command prints (and the subprocess output it streams) are dropped without
+// the command checking the mode; final counts go through Summary and
+// failures through Failures, which stay visible.
+func SetOutputMode(mode OutputMode) error {
+	if mode == OutputNormal || outputMode != OutputNormal {
+		return nil
+	}
+	null, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("redirect output: %w", err)
+	}
+	outputMode = mode
+	stdout, os.Stdout = os.Stdout, null
+	return nil
+}
+
+// Mode returns the output mode in effect
+func Mode() OutputMode {
+	return outputMode
+}
+
+// SummaryOutput is where final counts and summaries are written: standard
+// output, except in quiet mode, where they are dropped
+func SummaryOutput() io.Writer {
+	switch outputMode {
+	case OutputQuiet:
+		return io.Discard
+	case OutputSummary:
+		return stdout
+	}
+	return os.Stdout
+}
+
+// Summary prints a line of final counts, e.g. "Summary: 3 linked", to
+// SummaryOutput
+func Summary(format string, args ...interface{}) {
+	fmt.Fprintf(SummaryOutput(), format+"\n", args...)
+}
+
+// Failures is where per-item failures are written, e.g. "✗ ~/.zshrc
+// (error: ...)": standard output normally, standard error in summary and
+// quiet mode, so they are never dropped
+func Failures() io.Writer {
+	if outputMode == OutputNormal {
+		return os.Stdout
+	}
+	return os.Stderr
+}
+
+// Failure reports a failure that the summary already shows, e.g. the
+// packages an install summary lists as failed. Only quiet mode, which drops
+// the summary, prints it, to standard error like Error.
+func Failure(format string, args ...interface{}) {
+	if outputMode == OutputQuiet {
+		Error(format, args...)
+	}
+}
+
+// CheckPrompt returns an error when summary or quiet mode would hide a
+// prompt, naming what needs it, e.g. "the package picker (use --all)"
+func CheckPrompt(what string) error {
+	if outputMode == OutputNormal {
+		return nil
+	}
+	return fmt.Errorf("--summary and --quiet can't show %s", what)
+}
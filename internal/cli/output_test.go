@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// captureOutput points os.Stdout and os.Stderr at files for the test, puts
+// the output mode back afterwards and returns a func reading both files
+func captureOutput(t *testing.T) func() (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	out, err := os.Create(filepath.Join(dir, "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	errOut, err := os.Create(filepath.Join(dir, "stderr"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	savedOut, savedErr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = out, errOut
+	t.Cleanup(func() {
+		os.Stdout, os.Stderr, stdout, outputMode = savedOut, savedErr, nil, OutputNormal
+		out.Close()
+		errOut.Close()
+	})
+	return func() (string, string) {
+		o, _ := os.ReadFile(out.Name())
+		e, _ := os.ReadFile(errOut.Name())
+		return string(o), string(e)
+	}
+}
+
+// emit prints one line through each kind of output
+func emit() {
+	fmt.Println("per-item")
+	Success("done")
+	Warning("careful")
+	fmt.Fprintln(Failures(), "✗ item (error: boom)")
+	Failure("1 failed")
+	Summary("Summary: %d linked", 3)
+}
+
+func TestOutputModes(t *testing.T) {
+	tests := []struct {
+		mode       OutputMode
+		wantOut    []string
+		wantNotOut []string
+		wantErr    []string
+		wantNotErr []string
+	}{
+		{
+			mode:       OutputNormal,
+			wantOut:    []string{"per-item", "done", "✗ item", "Summary: 3 linked"},
+			wantErr:    []string{"careful"},
+			wantNotErr: []string{"1 failed"},
+		},
+		{
+			mode:       OutputSummary,
+			wantOut:    []string{"Summary: 3 linked"},
+			wantNotOut: []string{"per-item", "done", "✗ item"},
+			wantErr:    []string{"careful", "✗ item"},
+			wantNotErr: []string{"1 failed"},
+		},
+		{
+			mode:       OutputQuiet,
+			wantNotOut: []string{"per-item", "done", "Summary"},
+			wantErr:    []string{"✗ item", "1 failed"},
+			wantNotErr: []string{"careful"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.mode), func(t *testing.T) {
+			read := captureOutput(t)
+			if err := SetOutputMode(tt.mode); err != nil {
+				t.Fatal(err)
+			}
+			if Mode() != tt.mode {
+				t.Errorf("Mode() = %v, want %v", Mode(), tt.mode)
+			}
+			emit()
+			out, errOut := read()
+			for _, s := range tt.wantOut {
+				if !strings.Contains(out, s) {
+					t.Errorf("stdout %q lacks %q", out, s)
+				}
+			}
+			for _, s := range tt.wantNotOut {
+				if strings.Contains(out, s) {
+					t.Errorf("stdout %q has %q", out, s)
+				}
+			}
+			for _, s := range tt.wantErr {
+				if !strings.Contains(errOut, s) {
+					t.Errorf("stderr %q lacks %q", errOut, s)
+				}
+			}
+			for _, s := range tt.wantNotErr {
+				if strings.Contains(errOut, s) {
+					t.Errorf("stderr %q has %q", errOut, s)
+				}
+			}
+			if err := CheckPrompt("the picker"); (err != nil) != (tt.mode != OutputNormal) {
+				t.Errorf("CheckPrompt() = %v", err)
+			}
+		})
+	}
+}
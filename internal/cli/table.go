@@ -0,0 +1,191 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/x/term"
+)
+
+const (
+	// DefaultWidth is used when the terminal width cannot be detected (e.g. output piped).
+	DefaultWidth = 80
+
+	// minColumnWidth is the narrowest a truncatable column will be squeezed to.
+	minColumnWidth = 8
+
+	// columnGap matches the padding previously used with tabwriter.
+	columnGap = 3
+
+	ellipsis = "…"
+)
+
+// TerminalWidth returns the usable width of stdout. COLUMNS overrides detection;
+// non-terminal output falls back to DefaultWidth.
+func TerminalWidth() int {
+	if v := os.Getenv("COLUMNS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+	}
+	if w, _, err := term.GetSize(os.Stdout.Fd()); err == nil && w > 0 {
+		return w
+	}
+	return DefaultWidth
+}
+
+// TruncateMiddle shortens s to at most max runes by replacing the middle with an ellipsis.
+// Useful for paths where both the root and the file name carry meaning.
+func TruncateMiddle(s string, max int) string {
+	n := utf8.RuneCountInString(s)
+	if max <= 0 || n <= max {
+		return s
+	}
+	if max == 1 {
+		return ellipsis
+	}
+	r := []rune(s)
+	keep := max - 1
+	head := keep / 2
+	tail := keep - head
+	return string(r[:head]) + ellipsis + string(r[n-tail:])
+}
+
+// TruncateEnd shortens s to at most max runes, ending with an ellipsis when cut.
+func TruncateEnd(s string, max int) string {
+	n := utf8.RuneCountInString(s)
+	if max <= 0 || n <= max {
+		return s
+	}
+	if max == 1 {
+		return ellipsis
+	}
+	r := []rune(s)
+	return string(r[:max-1]) + ellipsis
+}
+
+// Table renders aligned columns that fit the terminal width.
+// Columns marked with TruncateMiddle (typically paths) are shortened in the middle;
+// other columns are cut at the end. Set NoTruncate to print full cell contents.
+type Table struct {
+	Width      int  // total width budget (defaults to TerminalWidth)
+	NoTruncate bool // print cells verbatim, ignoring Width
+
+	headers []string
+	rows    [][]string
+	middle  map[int]bool
+	fixed   map[int]bool
+}
+
+// NewTable creates a table with the given column headers.
+func NewTable(headers ...string) *Table {
+	return &Table{
+		headers: headers,
+		middle:  map[int]bool{},
+		fixed:   map[int]bool{},
+	}
+}
+
+// TruncateMiddle marks a column to be shortened in the middle instead of at the end.
+func (t *Table) TruncateMiddle(col int) *Table {
+	t.middle[col] = true
+	return t
+}
+
+// Fixed marks a column that must never be truncated (IDs, counts, timestamps).
+func (t *Table) Fixed(col int) *Table {
+	t.fixed[col] = true
+	return t
+}
+
+// AddRow appends a row; missing cells are rendered empty.
+func (t *Table) AddRow(cells ...string) {
+	t.rows = append(t.rows, cells)
+}
+
+// Render writes the header, a separator line and all rows to w.
+func (t *Table) Render(w io.Writer) {
+	cols := len(t.headers)
+	widths := make([]int, cols)
+	for i, h := range t.headers {
+		widths[i] = utf8.RuneCountInString(h)
+	}
+	for _, row := range t.rows {
+		for i := 0; i < cols && i < len(row); i++ {
+			if n := utf8.RuneCountInString(row[i]); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+
+	if !t.NoTruncate {
+		budget := t.Width
+		if budget <= 0 {
+			budget = TerminalWidth()
+		}
+		t.shrink(widths, budget)
+	}
+
+	separators := make([]string, cols)
+	for i, h := range t.headers {
+		separators[i] = strings.Repeat("-", utf8.RuneCountInString(h))
+	}
+	t.writeRow(w, t.headers, widths)
+	t.writeRow(w, separators, widths)
+	for _, row := range t.rows {
+		t.writeRow(w, row, widths)
+	}
+}
+
+// shrink reduces the widest truncatable column until the table fits budget.
+func (t *Table) shrink(widths []int, budget int) {
+	total := func() int {
+		sum := columnGap * (len(widths) - 1)
+		for _, w := range widths {
+			sum += w
+		}
+		return sum
+	}
+	for total() > budget {
+		widest := -1
+		for i, w := range widths {
+			if t.fixed[i] || w <= minColumnWidth {
+				continue
+			}
+			if widest == -1 || w > widths[widest] {
+				widest = i
+			}
+		}
+		if widest == -1 {
+			return // nothing left to shrink; let the terminal wrap
+		}
+		widths[widest]--
+	}
+}
+
+func (t *Table) writeRow(w io.Writer, row []string, widths []int) {
+	var b strings.Builder
+	for i, width := range widths {
+		cell := ""
+		if i < len(row) {
+			cell = row[i]
+		}
+		if !t.NoTruncate {
+			if t.middle[i] {
+				cell = TruncateMiddle(cell, width)
+			} else {
+				cell = TruncateEnd(cell, width)
+			}
+		}
+		b.WriteString(cell)
+		if i < len(widths)-1 {
+			pad := width - utf8.RuneCountInString(cell) + columnGap
+			b.WriteString(strings.Repeat(" ", pad))
+		}
+	}
+	fmt.Fprintln(w, strings.TrimRight(b.String(), " "))
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateMiddle(t *testing.T) {
+	got := TruncateMiddle("/Users/me/.config/very/long/path/file.toml", 20)
+	if utf8.RuneCountInString(got) != 20 {
+		t.Fatalf("expected 20 runes, got %d (%q)", utf8.RuneCountInString(got), got)
+	}
+	if !strings.HasPrefix(got, "/Users") || !strings.HasSuffix(got, "file.toml") || !strings.Contains(got, "…") {
+		t.Errorf("unexpected middle truncation: %q", got)
+	}
+	if TruncateMiddle("short", 20) != "short" {
+		t.Error("short strings should be unchanged")
+	}
+}
+
+func TestTruncateEnd(t *testing.T) {
+	if got := TruncateEnd("abcdefghij", 5); got != "abcd…" {
+		t.Errorf("TruncateEnd = %q, want abcd…", got)
+	}
+}
+
+func TestTableFitsWidth(t *testing.T) {
+	table := NewTable("ID", "PATH").Fixed(0).TruncateMiddle(1)
+	table.Width = 40
+	table.AddRow("20250108_143022", "/Users/me/.config/some/deeply/nested/config/file.toml")
+	var buf bytes.Buffer
+	table.Render(&buf)
+	for _, line := range strings.Split(strings.TrimRight(buf.String(), "\n"), "\n") {
+		if n := utf8.RuneCountInString(line); n > 40 {
+			t.Errorf("line exceeds width (%d): %q", n, line)
+		}
+	}
+	if !strings.Contains(buf.String(), "20250108_143022") {
+		t.Error("fixed column should not be truncated")
+	}
+}
+
+func TestTableNoTruncate(t *testing.T) {
+	long := "/Users/me/.config/some/deeply/nested/config/file.toml"
+	table := NewTable("PATH")
+	table.Width = 10
+	table.NoTruncate = true
+	table.AddRow(long)
+	var buf bytes.Buffer
+	table.Render(&buf)
+	if !strings.Contains(buf.String(), long) {
+		t.Errorf("expected full path with NoTruncate, got %q", buf.String())
+	}
+}
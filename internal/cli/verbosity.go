@@ -0,0 +1,25 @@
+package cli
+
+// Verbosity is the output detail level selected with repeated -v flags
+type Verbosity int
+
+const (
+	// VerbosityNormal prints summaries and failures only
+	VerbosityNormal Verbosity = iota
+	// VerbosityItems (-v) prints a result line per link, package or script
+	VerbosityItems
+	// VerbosityStream (-vv) also streams subprocess output (brew, mas, scripts)
+	VerbosityStream
+	// VerbosityDebug (-vvv) also prints internal details such as resolved
+	// paths and timings, and enables debug logging
+	VerbosityDebug
+)
+
+// Items reports whether per-item results should be printed
+func (v Verbosity) Items() bool { return v >= VerbosityItems }
+
+// Stream reports whether subprocess output should be streamed
+func (v Verbosity) Stream() bool { return v >= VerbosityStream }
+
+// Debug reports whether internal details should be printed
+func (v Verbosity) Debug() bool { return v >= VerbosityDebug }
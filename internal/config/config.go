@@ -28,19 +28,27 @@ const (
 // DotfilesRepo represents a dotfiles repository
 type DotfilesRepo struct {
 	Root      string // Absolute path to the dotfiles repository root
-	ConfigDir string // Absolute path to the config directory
+	ConfigDir string // Absolute path to the tools directory ([settings.layout] tools_dir, default config/)
+
+	ToolConfigSubdir string // Directory linked by default inside each tool; empty means config/
+	Flat             bool   // Tools are the repository's top-level directories
 }
 
 // FindDotfilesRepo attempts to locate the dotfiles repository in the following order:
 // 1. MERLIN_DOTFILES environment variable
 // 2. Current directory (if it contains merlin.toml)
 // 3. Parent directories (walking up until merlin.toml is found)
+// 4. The repository recorded in the user config (see UserConfig)
 func FindDotfilesRepo() (*DotfilesRepo, error) {
 	// Strategy 1: Check environment variable
 	if envPath := os.Getenv(EnvVarDotfiles); envPath != "" {
-		if repo, err := LoadDotfilesRepo(envPath); err == nil {
+		repo, err := LoadDotfilesRepo(envPath)
+		if err == nil {
 			return repo, nil
 		}
+		if errors.Is(err, ErrInvalidLayout) {
+			return nil, err
+		}
 	}
 	
 	// Strategy 2 & 3: Check current directory and walk up
@@ -49,7 +57,18 @@ func FindDotfilesRepo() (*DotfilesRepo, error) {
 		return nil, err
 	}
 	
-	return findDotfilesInPath(cwd)
+	repo, err := findDotfilesInPath(cwd)
+	if !errors.Is(err, ErrDotfilesNotFound) {
+		return repo, err
+	}
+
+	// Strategy 4: Fall back to the repository recorded by merlin clone
+	if user, uerr := LoadUserConfig(); uerr == nil && user.Dotfiles != "" {
+		if recorded, rerr := LoadDotfilesRepo(user.Dotfiles); rerr == nil || errors.Is(rerr, ErrInvalidLayout) {
+			return recorded, rerr
+		}
+	}
+	return nil, err
 }
 
 // LoadDotfilesRepo loads a dotfiles repository from a specific path
@@ -70,17 +89,19 @@ func LoadDotfilesRepo(path string) (*DotfilesRepo, error) {
 		return nil, ErrNotADotfilesRepo
 	}
 	
-	// Verify this is a root config, not a per-tool config
-	// Root repos should have a config/ directory
-	configDir := filepath.Join(absPath, ConfigDir)
-	if info, err := os.Stat(configDir); err != nil || !info.IsDir() {
-		return nil, ErrNotADotfilesRepo
+	// Verify this is a root config, not a per-tool config, and resolve its layout
+	layout, err := readLayout(configPath)
+	if err != nil {
+		return nil, err
+	}
+	repo := &DotfilesRepo{Root: absPath}
+	if err := applyLayout(repo, layout); err != nil {
+		return nil, err
 	}
 	
-	// Build the repo struct
-	repo := &DotfilesRepo{
-		Root:      absPath,
-		ConfigDir: configDir,
+	// Root repos should have their tools directory
+	if info, err := os.Stat(repo.ConfigDir); err != nil || !info.IsDir() {
+		return nil, ErrNotADotfilesRepo
 	}
 	
 	return repo, nil
@@ -97,9 +118,13 @@ func findDotfilesInPath(startPath string) (*DotfilesRepo, error) {
 		parentDir := filepath.Dir(currentPath)
 		if filepath.Base(parentDir) != ConfigDir {
 			// Try to load from current path
-			if repo, err := LoadDotfilesRepo(currentPath); err == nil {
+			repo, err := LoadDotfilesRepo(currentPath)
+			if err == nil {
 				return repo, nil
 			}
+			if errors.Is(err, ErrInvalidLayout) {
+				return nil, err
+			}
 		}
 		
 		// Get parent directory
@@ -124,7 +149,7 @@ func IsValidDotfilesRepo(path string) bool {
 
 // GetToolConfigDir returns the path to a specific tool's config directory
 func (r *DotfilesRepo) GetToolConfigDir(toolName string) string {
-	return filepath.Join(r.ConfigDir, toolName, ConfigDir)
+	return filepath.Join(r.ConfigDir, toolName, r.toolConfigSubdir())
 }
 
 // GetToolRoot returns the path to a specific tool's root directory
@@ -161,7 +186,7 @@ func (r *DotfilesRepo) ListTools() ([]string, error) {
 	
 	var tools []string
 	for _, entry := range entries {
-		if entry.IsDir() {
+		if r.isToolDir(entry) {
 			tools = append(tools, entry.Name())
 		}
 	}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/ildx/merlin/internal/models"
+)
+
+// ErrInvalidLayout is returned when [settings.layout] can't be applied
+var ErrInvalidLayout = errors.New("invalid [settings.layout]")
+
+// readLayout reads [settings.layout] from a root merlin.toml. Only that table
+// is decoded; a file that doesn't parse gets the default layout so the parser
+// can report the problem. A per-tool merlin.toml (one with a [tool] table)
+// returns ErrNotADotfilesRepo.
+func readLayout(configPath string) (models.LayoutSettings, error) {
+	var root struct {
+		Settings struct {
+			Layout models.LayoutSettings `toml:"layout"`
+		} `toml:"settings"`
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return models.LayoutSettings{}, err
+	}
+	md, err := toml.Decode(string(data), &root)
+	if err != nil {
+		return models.LayoutSettings{}, nil
+	}
+	if md.IsDefined("tool") {
+		return models.LayoutSettings{}, ErrNotADotfilesRepo
+	}
+	return root.Settings.Layout, nil
+}
+
+// applyLayout resolves the tools directory and per-tool config subdirectory
+// of repo from layout, rejecting paths that leave the repository
+func applyLayout(repo *DotfilesRepo, layout models.LayoutSettings) error {
+	toolsDir := layout.ToolsDir
+	switch {
+	case layout.Flat:
+		if toolsDir != "" && filepath.Clean(toolsDir) != "." {
+			return fmt.Errorf("%w: flat = true cannot be combined with tools_dir = %q", ErrInvalidLayout, toolsDir)
+		}
+		toolsDir = "."
+	case toolsDir == "":
+		toolsDir = ConfigDir
+	}
+	if !withinRepo(toolsDir) {
+		return fmt.Errorf("%w: tools_dir %q must be a path inside the repository", ErrInvalidLayout, toolsDir)
+	}
+	if layout.ToolConfigSubdir != "" && !withinRepo(layout.ToolConfigSubdir) {
+		return fmt.Errorf("%w: tool_config_subdir %q must be a path inside the tool", ErrInvalidLayout, layout.ToolConfigSubdir)
+	}
+
+	repo.ConfigDir = filepath.Join(repo.Root, toolsDir)
+	repo.ToolConfigSubdir = layout.ToolConfigSubdir
+	repo.Flat = filepath.Clean(toolsDir) == "."
+	return nil
+}
+
+// withinRepo reports whether rel is a relative path that stays inside its base
+func withinRepo(rel string) bool {
+	clean := filepath.Clean(rel)
+	return !filepath.IsAbs(clean) && clean != ".." && !strings.HasPrefix(clean, ".."+string(filepath.Separator))
+}
+
+// toolConfigSubdir is the directory linked by default inside each tool
+func (r *DotfilesRepo) toolConfigSubdir() string {
+	if r.ToolConfigSubdir == "" {
+		return ConfigDir
+	}
+	return r.ToolConfigSubdir
+}
+
+// isToolDir reports whether a directory entry of the tools directory is a tool.
+// In a flat layout the repository's hidden directories (.git, .github) are not.
+func (r *DotfilesRepo) isToolDir(entry os.DirEntry) bool {
+	if !entry.IsDir() {
+		return false
+	}
+	return !r.Flat || !strings.HasPrefix(entry.Name(), ".")
+}
+
+// Rel returns path relative to the repository root, or path itself when it
+// lies outside the repository
+func (r *DotfilesRepo) Rel(path string) string {
+	rel, err := filepath.Rel(r.Root, path)
+	if err != nil || !withinRepo(rel) {
+		return path
+	}
+	return rel
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeLayoutRepo creates a repository with the given root merlin.toml and
+// directories (relative to the root)
+func writeLayoutRepo(t *testing.T, rootToml string, dirs ...string) string {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, RootConfigFile), []byte(rootToml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range dirs {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestCustomLayout(t *testing.T) {
+	root := writeLayoutRepo(t, "[settings.layout]\ntools_dir = \"dotfiles/tools\"\ntool_config_subdir = \"files\"\n",
+		"dotfiles/tools/zsh/files", "dotfiles/tools/git")
+
+	repo, err := LoadDotfilesRepo(root)
+	if err != nil {
+		t.Fatalf("LoadDotfilesRepo() error = %v", err)
+	}
+	if want := filepath.Join(root, "dotfiles", "tools"); repo.ConfigDir != want {
+		t.Errorf("ConfigDir = %s, want %s", repo.ConfigDir, want)
+	}
+	if got, want := repo.GetToolConfigDir("zsh"), filepath.Join(root, "dotfiles", "tools", "zsh", "files"); got != want {
+		t.Errorf("GetToolConfigDir() = %s, want %s", got, want)
+	}
+	if got := repo.Rel(repo.GetToolRoot("zsh")); got != filepath.Join("dotfiles", "tools", "zsh") {
+		t.Errorf("Rel() = %s", got)
+	}
+	tools, _ := repo.ListTools()
+	if !reflect.DeepEqual(tools, []string{"git", "zsh"}) {
+		t.Errorf("ListTools() = %v", tools)
+	}
+
+	// A tool's own merlin.toml is never taken for the repository root
+	os.WriteFile(filepath.Join(root, "dotfiles", "tools", "zsh", RootConfigFile), []byte("[tool]\nname = \"zsh\"\n"), 0644)
+	found, err := findDotfilesInPath(filepath.Join(root, "dotfiles", "tools", "zsh", "files"))
+	if err != nil || found.Root != root {
+		t.Errorf("findDotfilesInPath() = %+v, %v; want root %s", found, err, root)
+	}
+}
+
+func TestFlatLayout(t *testing.T) {
+	root := writeLayoutRepo(t, "[settings.layout]\nflat = true\ntool_config_subdir = \".\"\n", ".git", "nvim", "zsh")
+
+	repo, err := LoadDotfilesRepo(root)
+	if err != nil {
+		t.Fatalf("LoadDotfilesRepo() error = %v", err)
+	}
+	if repo.ConfigDir != root || !repo.Flat {
+		t.Errorf("repo = %+v, want the root as tools directory", repo)
+	}
+	if got := repo.GetToolConfigDir("nvim"); got != filepath.Join(root, "nvim") {
+		t.Errorf("GetToolConfigDir() = %s, want the tool directory itself", got)
+	}
+	tools, _ := repo.ListTools()
+	if !reflect.DeepEqual(tools, []string{"nvim", "zsh"}) {
+		t.Errorf("ListTools() = %v, want hidden directories skipped", tools)
+	}
+}
+
+func TestInvalidLayout(t *testing.T) {
+	cases := map[string]string{
+		"outside repo":   "[settings.layout]\ntools_dir = \"../elsewhere\"\n",
+		"absolute":       "[settings.layout]\ntools_dir = \"/etc\"\n",
+		"flat and dir":   "[settings.layout]\nflat = true\ntools_dir = \"tools\"\n",
+		"subdir escapes": "[settings.layout]\ntool_config_subdir = \"../..\"\n",
+	}
+	for name, rootToml := range cases {
+		t.Run(name, func(t *testing.T) {
+			root := writeLayoutRepo(t, rootToml, ConfigDir, "tools")
+			t.Setenv(EnvVarDotfiles, root)
+			if _, err := FindDotfilesRepo(); !errors.Is(err, ErrInvalidLayout) {
+				t.Errorf("FindDotfilesRepo() error = %v, want ErrInvalidLayout", err)
+			}
+		})
+	}
+}
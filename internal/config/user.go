@@ -0,0 +1,55 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+)
+
+// UserConfig holds per-user settings kept outside any dotfiles repository
+type UserConfig struct {
+	// Dotfiles is the repository used when neither MERLIN_DOTFILES nor the
+	// current directory points at one (recorded by merlin clone)
+	Dotfiles string `toml:"dotfiles"`
+}
+
+// UserConfigPath returns the location of the user config (~/.merlin/config.toml)
+func UserConfigPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("get home directory: %w", err)
+	}
+	return filepath.Join(home, ".merlin", "config.toml"), nil
+}
+
+// LoadUserConfig reads the user config; a missing file is an empty config
+func LoadUserConfig() (*UserConfig, error) {
+	cfg := &UserConfig{}
+	path, err := UserConfigPath()
+	if err != nil {
+		return cfg, err
+	}
+	if _, err := toml.DecodeFile(path, cfg); err != nil && !os.IsNotExist(err) {
+		return cfg, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Save writes the user config
+func (c *UserConfig) Save() error {
+	path, err := UserConfigPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create config directory: %w", err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	defer f.Close()
+	return toml.NewEncoder(f).Encode(c)
+}
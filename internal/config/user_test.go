@@ -0,0 +1,36 @@
+package config
+
+import (
+	"os"
+	"testing"
+)
+
+func TestFindDotfilesRepo_UserConfig(t *testing.T) {
+	repoPath, _ := setupTestRepo(t)
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(EnvVarDotfiles, "")
+
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir(t.TempDir())
+
+	if _, err := FindDotfilesRepo(); err != ErrDotfilesNotFound {
+		t.Fatalf("expected ErrDotfilesNotFound without a recorded repo, got %v", err)
+	}
+
+	if err := (&UserConfig{Dotfiles: repoPath}).Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	user, err := LoadUserConfig()
+	if err != nil || user.Dotfiles != repoPath {
+		t.Fatalf("LoadUserConfig() = %+v, %v", user, err)
+	}
+
+	repo, err := FindDotfilesRepo()
+	if err != nil {
+		t.Fatalf("FindDotfilesRepo() error = %v", err)
+	}
+	if repo.Root != repoPath {
+		t.Errorf("Root = %s, want %s", repo.Root, repoPath)
+	}
+}
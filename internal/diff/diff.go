@@ -1,18 +1,29 @@
 package diff
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/ildx/merlin/internal/config"
+	"github.com/ildx/merlin/internal/installer"
+	"github.com/ildx/merlin/internal/logger"
+	"github.com/ildx/merlin/internal/machine"
+	"github.com/ildx/merlin/internal/models"
 	"github.com/ildx/merlin/internal/parser"
 	"github.com/ildx/merlin/internal/state"
+	"github.com/ildx/merlin/internal/symlink"
 )
 
 // PackageDiff captures differences for brew/mas packages
@@ -29,13 +40,17 @@ type PackageDiff struct {
 // MissingLinks: declared in tool configs but not present as symlink
 // OrphanedLinks: symlinks pointing into repo not declared in any tool config
 // BrokenLinks: symlinks whose target does not exist
-// DivergentLinks: reserved for future content hashing support
-// For now DivergentLinks stays empty.
+// DivergentLinks: declared and present, but the file reached through the link
+// differs in content from the declared source
+// StaleLinks: symlinks into the repo, in managed target directories, whose
+// source was deleted (see symlink.StaleLinks); not repeated as broken or
+// orphaned
 type SymlinkDiff struct {
 	MissingLinks   []string `json:"missing_links"`
 	OrphanedLinks  []string `json:"orphaned_links"`
 	BrokenLinks    []string `json:"broken_links"`
 	DivergentLinks []string `json:"divergent_links"`
+	StaleLinks     []string `json:"stale_links"`
 }
 
 // DiffResult aggregates all diff categories.
@@ -43,49 +58,106 @@ type DiffResult struct {
 	BrewFormulae PackageDiff `json:"brew_formulae"`
 	BrewCasks    PackageDiff `json:"brew_casks"`
 	MASApps      PackageDiff `json:"mas_apps"`
+	MASStale     []string    `json:"mas_stale,omitempty"` // listed by mas but removed from Applications
+	Extensions   PackageDiff `json:"extensions"`          // editor:id, only for editors with declarations
 	Symlinks     SymlinkDiff `json:"symlinks"`
 	Scripts      PackageDiff `json:"scripts"` // Added/ Missing semantics: file exists vs declared
+
+	// Notes added with 'merlin note add' for the symlink targets above
+	Notes map[string][]string `json:"notes,omitempty"`
 }
 
-// Compute generates a DiffResult by comparing the repository definitions with a system snapshot.
-func Compute(repo *config.DotfilesRepo, snap *state.SystemSnapshot) (*DiffResult, error) {
-	result := &DiffResult{}
-
-	// Brew diff
-	brewConfig, brewErr := parser.ParseBrewTOML(filepath.Join(repo.ConfigDir, "brew", "config", "brew.toml"))
-	if brewErr == nil && brewConfig != nil {
-		formulaDeclared := make(map[string]bool)
-		caskDeclared := make(map[string]bool)
-		for _, f := range brewConfig.Formulae {
-			formulaDeclared[f.Name] = true
-		}
-		for _, c := range brewConfig.Casks {
-			caskDeclared[c.Name] = true
-		}
-		result.BrewFormulae = buildPackageDiff(formulaDeclared, snap.BrewFormulae)
-		result.BrewCasks = buildPackageDiff(caskDeclared, snap.BrewCasks)
-	}
-
-	// MAS diff
-	masConfig, masErr := parser.ParseMASTOML(filepath.Join(repo.ConfigDir, "mas", "config", "mas.toml"))
-	if masErr == nil && masConfig != nil {
-		appsDeclared := make(map[string]bool)
-		for _, a := range masConfig.Apps {
-			// MAS IDs are integers in config; snapshot keys are string IDs from `mas list`
-			if a.ID > 0 {
-				appsDeclared[strconv.Itoa(a.ID)] = true
-			}
+// Compute generates a DiffResult by comparing the repository definitions with
+// a system snapshot. The package, extension, symlink and script sections are
+// independent and computed concurrently; cancelling ctx stops the file
+// hashing of the symlink section and makes Compute return ctx.Err().
+func Compute(ctx context.Context, repo *config.DotfilesRepo, snap *state.SystemSnapshot) (*DiffResult, error) {
+	result := &DiffResult{MASStale: snap.MASStale}
+	hashes := state.LoadHashCache()
+	host := machine.CurrentHost(repo)
+
+	// Each section writes only its own fields of result
+	var wg sync.WaitGroup
+	section := func(compute func()) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			compute()
+		}()
+	}
+	section(func() { result.BrewFormulae, result.BrewCasks = computeBrewDiff(repo, snap, host) })
+	section(func() { result.MASApps = computeMASDiff(repo, snap, host) })
+	section(func() { result.Extensions = computeExtensionDiff(repo, snap) })
+	section(func() {
+		if symlinkDiff, err := computeSymlinkDiff(ctx, repo, snap, hashes); err == nil {
+			result.Symlinks = *symlinkDiff
 		}
-		result.MASApps = buildPackageDiff(appsDeclared, snap.MASApps)
+	})
+	section(func() { result.Scripts = computeScriptDiff(repo) })
+	var stale []string
+	section(func() { stale = computeStaleLinks(repo) })
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	result.Symlinks.StaleLinks = stale
+	isStale := func(target string) bool { return slices.Contains(stale, target) }
+	result.Symlinks.BrokenLinks = slices.DeleteFunc(result.Symlinks.BrokenLinks, isStale)
+	result.Symlinks.OrphanedLinks = slices.DeleteFunc(result.Symlinks.OrphanedLinks, isStale)
+	if notes, err := state.LoadTargetNotes(); err == nil {
+		result.Notes = symlinkNotes(&result.Symlinks, notes)
+	}
+	if err := hashes.Save(); err != nil {
+		logger.Debug("hash cache not saved", "error", err)
 	}
+	return result, nil
+}
 
-	// Symlink diff
-	symlinkDiff, err := computeSymlinkDiff(repo, snap)
-	if err == nil {
-		result.Symlinks = *symlinkDiff
+// computeBrewDiff compares brew.toml formulae and casks with installed ones.
+// Packages whose when condition host doesn't match aren't expected, but
+// aren't extra when installed either.
+func computeBrewDiff(repo *config.DotfilesRepo, snap *state.SystemSnapshot, host models.Host) (formulae, casks PackageDiff) {
+	brewConfig, err := parser.ParseBrewConfig(repo.GetToolConfigDir("brew"))
+	if err != nil || brewConfig == nil {
+		return PackageDiff{}, PackageDiff{}
+	}
+	formulaDeclared := make(map[string]bool)
+	caskDeclared := make(map[string]bool)
+	for _, f := range brewConfig.Formulae {
+		formulaDeclared[f.Name] = formulaDeclared[f.Name] || f.When.Matches(host)
 	}
+	for _, c := range brewConfig.Casks {
+		caskDeclared[c.Name] = caskDeclared[c.Name] || c.When.Matches(host)
+	}
+	casks = buildPackageDiff(caskDeclared, snap.BrewCasks)
+	casks.Missing = slices.DeleteFunc(casks.Missing, func(name string) bool {
+		return installer.CaskInAppdir(nil, name)
+	})
+	return buildPackageDiff(formulaDeclared, snap.BrewFormulae), casks
+}
+
+// computeMASDiff compares mas.toml apps with installed ones, expecting only
+// the apps whose when condition host matches
+func computeMASDiff(repo *config.DotfilesRepo, snap *state.SystemSnapshot, host models.Host) PackageDiff {
+	masConfig, err := parser.ParseMASTOML(filepath.Join(repo.GetToolConfigDir("mas"), "mas.toml"))
+	if err != nil || masConfig == nil {
+		return PackageDiff{}
+	}
+	appsDeclared := make(map[string]bool)
+	for _, a := range masConfig.Apps {
+		// MAS IDs are integers in config; snapshot keys are string IDs from `mas list`
+		if a.ID > 0 {
+			id := strconv.Itoa(a.ID)
+			appsDeclared[id] = appsDeclared[id] || a.When.Matches(host)
+		}
+	}
+	return buildPackageDiff(appsDeclared, snap.MASApps)
+}
 
-	// Script diff (aggregate across tools)
+// computeScriptDiff compares declared scripts of enabled tools with the files
+// in their script directories
+func computeScriptDiff(repo *config.DotfilesRepo) PackageDiff {
 	scriptsDeclared := map[string]bool{}
 	scriptsPresent := map[string]bool{}
 
@@ -94,7 +166,7 @@ func Compute(repo *config.DotfilesRepo, snap *state.SystemSnapshot) (*DiffResult
 		for _, tool := range tools {
 			cfgPath := repo.GetToolMerlinConfig(tool)
 			c, perr := parser.ParseToolMerlinTOML(cfgPath)
-			if perr != nil || c == nil || !c.HasScripts() {
+			if perr != nil || c == nil || !c.IsEnabled() || !c.HasScripts() {
 				continue
 			}
 			// Determine script directory (default to "scripts" if unspecified)
@@ -127,23 +199,54 @@ func Compute(repo *config.DotfilesRepo, snap *state.SystemSnapshot) (*DiffResult
 			}
 		}
 	}
-	result.Scripts = buildPackageDiff(scriptsDeclared, scriptsPresent)
+	return buildPackageDiff(scriptsDeclared, scriptsPresent)
+}
 
-	return result, nil
+// computeExtensionDiff compares [[extension]] declarations of enabled tools
+// with installed extensions. Installed extensions only count as Added for
+// editors the repo declares extensions for, so an unmanaged editor is ignored.
+func computeExtensionDiff(repo *config.DotfilesRepo, snap *state.SystemSnapshot) PackageDiff {
+	declared := make(map[string]bool)
+	editors := make(map[string]bool)
+	tools, err := repo.ListTools()
+	if err != nil {
+		return PackageDiff{}
+	}
+	for _, tool := range tools {
+		c, perr := parser.ParseToolMerlinTOML(repo.GetToolMerlinConfig(tool))
+		if perr != nil || c == nil || !c.IsEnabled() {
+			continue
+		}
+		for _, ext := range c.Extensions {
+			declared[ext.Editor+":"+strings.ToLower(ext.ID)] = true
+			editors[ext.Editor] = true
+		}
+	}
+
+	installed := make(map[string]bool)
+	for key := range snap.Extensions {
+		editor, _, _ := strings.Cut(key, ":")
+		if editors[editor] {
+			installed[key] = true
+		}
+	}
+	return buildPackageDiff(declared, installed)
 }
 
-// buildPackageDiff computes Added (installed not declared) and Missing (declared not installed)
+// buildPackageDiff computes Added (installed not declared) and Missing (declared not installed).
+// A name declared false is known but not expected on this machine: it is
+// neither added nor missing.
 func buildPackageDiff(declared map[string]bool, installed map[string]bool) PackageDiff {
 	var added []string
 	var missing []string
 
 	for name := range installed {
-		if !declared[name] {
+		if _, known := declared[name]; !known {
 			added = append(added, name)
 		}
 	}
-	for name := range declared {
-		if !installed[name] {
+	for name, expected := range declared {
+		if expected && !installed[name] {
 			missing = append(missing, name)
 		}
 	}
@@ -151,7 +254,8 @@ func buildPackageDiff(declared map[string]bool, installed map[string]bool) Packa
 }
 
 // computeSymlinkDiff walks tool link declarations and compares with system symlink snapshot.
-func computeSymlinkDiff(repo *config.DotfilesRepo, snap *state.SystemSnapshot) (*SymlinkDiff, error) {
+// File hashes come from hashes when it is non-nil.
+func computeSymlinkDiff(ctx context.Context, repo *config.DotfilesRepo, snap *state.SystemSnapshot, hashes *state.HashCache) (*SymlinkDiff, error) {
 	declaredTargets := make(map[string]bool)
 	// Map of target -> source for declared
 	declaredSourceByTarget := make(map[string]string)
@@ -160,27 +264,58 @@ func computeSymlinkDiff(repo *config.DotfilesRepo, snap *state.SystemSnapshot) (
 	if err != nil {
 		return nil, err
 	}
+	bins := binDir(repo)
 
 	for _, tool := range tools {
 		toolConfigPath := repo.GetToolMerlinConfig(tool)
 		c, err := parser.ParseToolMerlinTOML(toolConfigPath)
-		if err != nil || c == nil {
+		if err != nil || c == nil || !c.IsEnabled() {
 			continue
 		}
+		toolRoot := repo.GetToolRoot(tool)
+		// declare records one target, expanding glob sources to each match
+		declare := func(source, target string) {
+			if !symlink.IsGlob(source) {
+				declaredTargets[target] = true
+				declaredSourceByTarget[target] = buildSourcePath(toolRoot, source)
+				return
+			}
+			matches, _ := symlink.ExpandGlob(toolRoot, source)
+			for _, m := range matches {
+				matchTarget := filepath.Join(target, m.Rel)
+				declaredTargets[matchTarget] = true
+				declaredSourceByTarget[matchTarget] = m.Path
+			}
+		}
 		for _, l := range c.Links {
 			if len(l.Files) == 0 {
-				resolvedTarget := resolveVariables(l.Target, repo)
-				declaredTargets[resolvedTarget] = true
-				declaredSourceByTarget[resolvedTarget] = buildSourcePath(repo.GetToolRoot(tool), l.Source)
+				declare(l.Source, resolveVariables(l.Target, repo))
 			} else {
 				for _, f := range l.Files {
 					baseTarget := resolveVariables(l.Target, repo)
-					resolvedTarget := filepath.Join(baseTarget, f.Target)
-					declaredTargets[resolvedTarget] = true
-					declaredSourceByTarget[resolvedTarget] = buildSourcePath(repo.GetToolRoot(tool), f.Source)
+					declare(f.Source, filepath.Join(baseTarget, f.Target))
 				}
 			}
 		}
+		// [[bin]] entries link flat into the bin directory
+		for _, bin := range c.Bins {
+			if symlink.IsGlob(bin.Source) {
+				matches, _ := symlink.ExpandGlob(toolRoot, bin.Source)
+				for _, m := range matches {
+					target := filepath.Join(bins, filepath.Base(m.Path))
+					declaredTargets[target] = true
+					declaredSourceByTarget[target] = m.Path
+				}
+				continue
+			}
+			name := bin.Name
+			if name == "" {
+				name = filepath.Base(bin.Source)
+			}
+			target := filepath.Join(bins, name)
+			declaredTargets[target] = true
+			declaredSourceByTarget[target] = buildSourcePath(toolRoot, bin.Source)
+		}
 	}
 
 	// Build sets from snapshot
@@ -193,7 +328,6 @@ func computeSymlinkDiff(repo *config.DotfilesRepo, snap *state.SystemSnapshot) (
 	var missing []string
 	var orphaned []string
 	var broken []string
-	var divergent []string
 
 	// Declared but not present
 	for target := range declaredTargets {
@@ -204,40 +338,152 @@ func computeSymlinkDiff(repo *config.DotfilesRepo, snap *state.SystemSnapshot) (
 
 	// Orphaned: exists as symlink pointing into repo but not declared
 	repoRoot := repo.Root
+	var candidates []hashPair
 	for target, entry := range snapshotTargets {
 		if !declaredTargets[target] {
 			// Check if its target path points into repo root
 			if strings.HasPrefix(entry.TargetPath, repoRoot) {
 				orphaned = append(orphaned, target)
 			}
-		} else {
+		} else if !entry.Broken {
 			// Divergence check: declared & present & not broken
-			if !entry.Broken {
-				src := declaredSourceByTarget[target]
-				// Compare file hashes if both exist and are regular files
-				if same, err := compareFileContent(src, entry.TargetPath); err == nil && !same {
-					divergent = append(divergent, target)
-				}
-			}
+			candidates = append(candidates, hashPair{target: target, src: declaredSourceByTarget[target], dst: entry.TargetPath})
 		}
 		if entry.Broken {
 			broken = append(broken, target)
 		}
 	}
 
+	divergent, err := divergentTargets(ctx, candidates, hashes)
+	if err != nil {
+		return nil, err
+	}
+
 	return &SymlinkDiff{MissingLinks: missing, OrphanedLinks: orphaned, BrokenLinks: broken, DivergentLinks: divergent}, nil
 }
 
-// resolveVariables performs simple placeholder resolution for {home_dir} and {config_dir}
+// computeStaleLinks lists the symlinks left dangling into the repository
+// when their source was deleted, in the target directories of the tools and
+// of the links the registry recorded
+func computeStaleLinks(repo *config.DotfilesRepo) []string {
+	rootConfig, err := parser.ParseRootMerlinTOML(repo.GetRootMerlinConfig())
+	if err != nil {
+		return nil
+	}
+	vars, err := symlink.GetVariablesFromRoot(rootConfig)
+	if err != nil {
+		return nil
+	}
+	tools, err := symlink.DiscoverTools(repo, vars)
+	if err != nil {
+		return nil
+	}
+	registry, err := state.LoadLinkRegistry()
+	if err != nil {
+		logger.Debug("link registry not loaded", "error", err)
+	}
+	var targets []string
+	for _, link := range symlink.StaleLinks(tools, registry.ToolsByTarget(), repo.Root) {
+		targets = append(targets, link.Target)
+	}
+	return targets
+}
+
+// hashWorkers bounds concurrent file hashing in the divergence check
+var hashWorkers = min(runtime.NumCPU()*2, 16)
+
+// hashPair is a declared source and the file its link resolves to
+type hashPair struct {
+	target, src, dst string
+}
+
+// divergentTargets compares the contents of each pair on hashWorkers
+// goroutines and returns the targets whose files differ, sorted. It stops
+// handing out pairs once ctx is cancelled and returns ctx.Err(). hashes,
+// when non-nil, answers for files unchanged since they were last hashed.
+func divergentTargets(ctx context.Context, pairs []hashPair, hashes *state.HashCache) ([]string, error) {
+	differs := make([]bool, len(pairs))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(hashWorkers, len(pairs)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				// Compare file hashes if both exist and are regular files
+				if same, err := compareFileContent(pairs[i].src, pairs[i].dst, hashes); err == nil && !same {
+					differs[i] = true
+				}
+			}
+		}()
+	}
+feed:
+	for i := range pairs {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var divergent []string
+	for i, differ := range differs {
+		if differ {
+			divergent = append(divergent, pairs[i].target)
+		}
+	}
+	sort.Strings(divergent)
+	return divergent, nil
+}
+
+// resolveVariables performs simple placeholder resolution for {home_dir}, {config_dir},
+// {app_support}, {launch_agents} and the {xdg_*} directories
 // Future: reuse existing parser variable expansion logic if available.
 func resolveVariables(t string, repo *config.DotfilesRepo) string {
 	// home_dir
 	home, _ := os.UserHomeDir()
 	res := strings.ReplaceAll(t, "{home_dir}", home)
 	res = strings.ReplaceAll(res, "{config_dir}", filepath.Join(home, ".config"))
+	res = strings.ReplaceAll(res, "{app_support}", filepath.Join(home, "Library", "Application Support"))
+	res = strings.ReplaceAll(res, "{launch_agents}", filepath.Join(home, "Library", "LaunchAgents"))
+	for variable, dir := range symlink.XDGDirs(home) {
+		res = strings.ReplaceAll(res, variable, dir)
+	}
 	return res
 }
 
+// binDir returns where [[bin]] entries are linked: bin_dir from the root
+// merlin.toml, or {home_dir}/bin
+func binDir(repo *config.DotfilesRepo) string {
+	if rootConfig, err := parser.ParseRootMerlinTOML(repo.GetRootMerlinConfig()); err == nil {
+		if vars, err := symlink.GetVariablesFromRoot(rootConfig); err == nil && vars.BinDir != "" {
+			return vars.BinDir
+		}
+	}
+	return resolveVariables("{home_dir}/bin", repo)
+}
+
+// Drift summarizes the result for prompt integrations: declared but absent
+// packages and links are missing; broken, stale, divergent and orphaned links,
+// stale apps and missing scripts are broken; undeclared packages and scripts
+// are extra.
+func (d *DiffResult) Drift(repoRoot string) *state.Drift {
+	drift := &state.Drift{CollectedAt: time.Now(), Repo: repoRoot}
+	for _, p := range []PackageDiff{d.BrewFormulae, d.BrewCasks, d.MASApps, d.Extensions} {
+		drift.Missing += len(p.Missing)
+		drift.Extra += len(p.Added)
+	}
+	drift.Missing += len(d.Symlinks.MissingLinks)
+	drift.Broken += len(d.Symlinks.BrokenLinks) + len(d.Symlinks.StaleLinks) + len(d.Symlinks.DivergentLinks) + len(d.Symlinks.OrphanedLinks) + len(d.MASStale) + len(d.Scripts.Missing)
+	drift.Extra += len(d.Scripts.Added)
+	return drift
+}
+
 // ToJSON marshals the DiffResult into pretty JSON.
 func (d *DiffResult) ToJSON() (string, error) {
 	b, err := json.MarshalIndent(d, "", "  ")
@@ -260,13 +506,20 @@ func (d *DiffResult) HumanReadable(includePackages, includeConfigs, includeScrip
 		b.WriteString("\n== MAS Apps ==\n")
 		b.WriteString(renderSet("Added", d.MASApps.Added))
 		b.WriteString(renderSet("Missing", d.MASApps.Missing))
+		if len(d.MASStale) > 0 {
+			b.WriteString(renderSet("Stale (listed by mas, app deleted)", d.MASStale))
+		}
+		b.WriteString("\n== Editor Extensions ==\n")
+		b.WriteString(renderSet("Added", d.Extensions.Added))
+		b.WriteString(renderSet("Missing", d.Extensions.Missing))
 	}
 	if includeConfigs {
 		b.WriteString("\n== Symlinks ==\n")
-		b.WriteString(renderSet("Missing", d.Symlinks.MissingLinks))
-		b.WriteString(renderSet("Orphaned", d.Symlinks.OrphanedLinks))
-		b.WriteString(renderSet("Broken", d.Symlinks.BrokenLinks))
-		b.WriteString(renderSet("Divergent", d.Symlinks.DivergentLinks))
+		b.WriteString(d.renderTargets("Missing", d.Symlinks.MissingLinks))
+		b.WriteString(d.renderTargets("Orphaned", d.Symlinks.OrphanedLinks))
+		b.WriteString(d.renderTargets("Broken", d.Symlinks.BrokenLinks))
+		b.WriteString(d.renderTargets("Stale", d.Symlinks.StaleLinks))
+		b.WriteString(d.renderTargets("Divergent", d.Symlinks.DivergentLinks))
 	}
 	if includeScripts {
 		b.WriteString("\n== Scripts ==\n")
@@ -276,6 +529,39 @@ func (d *DiffResult) HumanReadable(includePackages, includeConfigs, includeScrip
 	return b.String()
 }
 
+// renderTargets is renderSet for symlink targets, with each target's notes
+// below it
+func (d *DiffResult) renderTargets(label string, targets []string) string {
+	if len(targets) == 0 || len(d.Notes) == 0 {
+		return renderSet(label, targets)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (%d):\n", label, len(targets))
+	for _, target := range targets {
+		fmt.Fprintf(&b, "  - %s\n", target)
+		for _, note := range d.Notes[target] {
+			fmt.Fprintf(&b, "      📝 %s\n", note)
+		}
+	}
+	return b.String()
+}
+
+// symlinkNotes picks the notes of the targets in diff
+func symlinkNotes(diff *SymlinkDiff, notes *state.TargetNotes) map[string][]string {
+	var picked map[string][]string
+	for _, set := range [][]string{diff.MissingLinks, diff.OrphanedLinks, diff.BrokenLinks, diff.StaleLinks, diff.DivergentLinks} {
+		for _, target := range set {
+			if texts := notes.Texts(target); len(texts) > 0 {
+				if picked == nil {
+					picked = make(map[string][]string)
+				}
+				picked[target] = texts
+			}
+		}
+	}
+	return picked
+}
+
 func renderSet(label string, items []string) string {
 	if len(items) == 0 {
 		return fmt.Sprintf("%s: none\n", label)
@@ -295,7 +581,8 @@ func buildSourcePath(toolRoot, src string) string {
 
 // compareFileContent returns true if files have identical SHA256, false if different.
 // If either file does not exist or is a directory, returns true (treat as non-divergent).
-func compareFileContent(src, dst string) (bool, error) {
+// Hashes are looked up in hashes when it is non-nil.
+func compareFileContent(src, dst string, hashes *state.HashCache) (bool, error) {
 	si, serr := os.Stat(src)
 	if serr != nil || si.IsDir() {
 		return true, nil
@@ -304,11 +591,15 @@ func compareFileContent(src, dst string) (bool, error) {
 	if derr != nil || di.IsDir() {
 		return true, nil
 	}
-	sh, err := hashFile(src)
+	sum := hashFile
+	if hashes != nil {
+		sum = func(p string) (string, error) { return hashes.Sum(p, hashFile) }
+	}
+	sh, err := sum(src)
 	if err != nil {
 		return true, err
 	}
-	dh, err := hashFile(dst)
+	dh, err := sum(dst)
 	if err != nil {
 		return true, err
 	}
@@ -1,6 +1,7 @@
 package diff
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"strings"
@@ -22,6 +23,12 @@ func TestBuildPackageDiff(t *testing.T) {
 	if len(d.Missing) != 1 || d.Missing[0] != "a" {
 		t.Errorf("expected missing=a, got %#v", d.Missing)
 	}
+
+	// Declared for other machines: neither expected nor extra
+	d = buildPackageDiff(map[string]bool{"a": false, "b": false}, inst)
+	if len(d.Added) != 1 || d.Added[0] != "c" || len(d.Missing) != 0 {
+		t.Errorf("expected only added=c, got %#v", d)
+	}
 }
 
 func TestComputeSymlinkDiffBasic(t *testing.T) {
@@ -43,7 +50,7 @@ func TestComputeSymlinkDiffBasic(t *testing.T) {
 		},
 	}
 
-	d, err := computeSymlinkDiff(repo, snap)
+	d, err := computeSymlinkDiff(context.Background(), repo, snap, nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -86,7 +93,7 @@ func TestSymlinkDivergenceDetection(t *testing.T) {
 	}
 
 	snap := &state.SystemSnapshot{Symlinks: []state.SymlinkEntry{{LinkPath: targetPath, TargetPath: otherFile, Broken: false}}}
-	d, err := computeSymlinkDiff(repo, snap)
+	d, err := computeSymlinkDiff(context.Background(), repo, snap, nil)
 	if err != nil {
 		t.Fatalf("diff err: %v", err)
 	}
@@ -95,8 +102,39 @@ func TestSymlinkDivergenceDetection(t *testing.T) {
 	}
 }
 
+func TestSymlinkDiffBins(t *testing.T) {
+	tmp := t.TempDir()
+	repoRoot := filepath.Join(tmp, "repo")
+	toolRoot := filepath.Join(repoRoot, "config", "tools")
+	binDir := filepath.Join(tmp, "bin")
+	os.MkdirAll(filepath.Join(toolRoot, "bin"), 0755)
+	os.WriteFile(filepath.Join(toolRoot, "bin", "deploy.sh"), []byte("#!/bin/sh\n"), 0755)
+	os.WriteFile(filepath.Join(toolRoot, "bin", "sync"), []byte("#!/bin/sh\n"), 0755)
+	os.WriteFile(filepath.Join(repoRoot, "merlin.toml"), []byte("[settings]\nbin_dir = \""+binDir+"\"\n"), 0644)
+	os.WriteFile(filepath.Join(toolRoot, "merlin.toml"), []byte("[tool]\nname = \"tools\"\n\n[[bin]]\nsource = \"bin/deploy.sh\"\nname = \"deploy\"\n\n[[bin]]\nsource = \"bin/s*\"\n"), 0644)
+	repo := &config.DotfilesRepo{Root: repoRoot, ConfigDir: filepath.Join(repoRoot, "config")}
+
+	// deploy is linked; sync is not
+	snap := &state.SystemSnapshot{Symlinks: []state.SymlinkEntry{
+		{LinkPath: filepath.Join(binDir, "deploy"), TargetPath: filepath.Join(toolRoot, "bin", "deploy.sh")},
+	}}
+	d, err := computeSymlinkDiff(context.Background(), repo, snap, nil)
+	if err != nil {
+		t.Fatalf("diff err: %v", err)
+	}
+	if len(d.MissingLinks) != 1 || d.MissingLinks[0] != filepath.Join(binDir, "sync") {
+		t.Errorf("expected missing bin/sync, got %v", d.MissingLinks)
+	}
+	if len(d.OrphanedLinks) != 0 {
+		t.Errorf("declared bin reported orphaned: %v", d.OrphanedLinks)
+	}
+}
+
 func TestScriptDiff(t *testing.T) {
 	tmp := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", tmp)
+	defer os.Setenv("HOME", originalHome)
 	repoRoot := filepath.Join(tmp, "repo")
 	configDir := filepath.Join(repoRoot, "config", "tool")
 	scriptDir := filepath.Join(configDir, "scripts")
@@ -119,7 +157,7 @@ func TestScriptDiff(t *testing.T) {
 	}
 	repo := &config.DotfilesRepo{Root: repoRoot, ConfigDir: filepath.Join(repoRoot, "config")}
 	snap := &state.SystemSnapshot{} // scripts diff does not rely on snapshot currently
-	result, err := Compute(repo, snap)
+	result, err := Compute(context.Background(), repo, snap)
 	if err != nil {
 		t.Fatalf("compute err: %v", err)
 	}
@@ -146,3 +184,100 @@ func TestScriptDiff(t *testing.T) {
 		t.Fatalf("expected missing.sh in Missing, got %#v", result.Scripts.Missing)
 	}
 }
+
+func TestComputeExtensionDiff(t *testing.T) {
+	tmp := t.TempDir()
+	repoRoot := filepath.Join(tmp, "repo")
+	toolDir := filepath.Join(repoRoot, "config", "cursor")
+	if err := os.MkdirAll(toolDir, 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	content := "[tool]\nname = \"cursor\"\n\n[[extension]]\neditor = \"cursor\"\nid = \"golang.Go\"\n\n[[extension]]\neditor = \"cursor\"\nid = \"esbenp.prettier-vscode\"\n"
+	if err := os.WriteFile(filepath.Join(toolDir, "merlin.toml"), []byte(content), 0644); err != nil {
+		t.Fatalf("write merlin: %v", err)
+	}
+
+	repo := &config.DotfilesRepo{Root: repoRoot, ConfigDir: filepath.Join(repoRoot, "config")}
+	snap := &state.SystemSnapshot{Extensions: map[string]bool{
+		"cursor:golang.go":        true,
+		"cursor:ms-python.python": true,
+		"code:ms-vscode.cpptools": true, // no code declarations: not managed
+	}}
+
+	d := computeExtensionDiff(repo, snap)
+	if len(d.Added) != 1 || d.Added[0] != "cursor:ms-python.python" {
+		t.Errorf("Added = %v, want [cursor:ms-python.python]", d.Added)
+	}
+	if len(d.Missing) != 1 || d.Missing[0] != "cursor:esbenp.prettier-vscode" {
+		t.Errorf("Missing = %v, want [cursor:esbenp.prettier-vscode]", d.Missing)
+	}
+}
+
+func TestDiffResultDrift(t *testing.T) {
+	result := &DiffResult{
+		BrewFormulae: PackageDiff{Added: []string{"htop"}, Missing: []string{"bat", "eza"}},
+		MASApps:      PackageDiff{Missing: []string{"Xcode"}},
+		MASStale:     []string{"Gone (1)"},
+		Symlinks: SymlinkDiff{
+			MissingLinks:   []string{"a"},
+			BrokenLinks:    []string{"b"},
+			DivergentLinks: []string{"c"},
+			StaleLinks:     []string{"d"},
+		},
+		Scripts: PackageDiff{Added: []string{"zsh/extra.sh"}},
+	}
+	drift := result.Drift("/repo")
+	if drift.Missing != 4 || drift.Broken != 4 || drift.Extra != 2 || drift.Repo != "/repo" {
+		t.Errorf("Drift() = %+v, want 4 missing, 4 broken, 2 extra", drift)
+	}
+}
+
+func TestDivergentTargets(t *testing.T) {
+	tmp := t.TempDir()
+	write := func(name, content string) string {
+		p := filepath.Join(tmp, name)
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		return p
+	}
+	src := write("src", "a")
+	pairs := []hashPair{
+		{target: "z", src: src, dst: write("z", "b")},
+		{target: "same", src: src, dst: write("same", "a")},
+		{target: "y", src: src, dst: write("y", "c")},
+	}
+
+	got, err := divergentTargets(context.Background(), pairs, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(got, ",") != "y,z" {
+		t.Errorf("divergent = %v, want [y z]", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := divergentTargets(ctx, pairs, nil); err != context.Canceled {
+		t.Errorf("cancelled: err = %v, want context.Canceled", err)
+	}
+	repo := &config.DotfilesRepo{Root: tmp, ConfigDir: filepath.Join(tmp, "config")}
+	if _, err := Compute(ctx, repo, &state.SystemSnapshot{}); err != context.Canceled {
+		t.Errorf("Compute cancelled: err = %v, want context.Canceled", err)
+	}
+}
+
+func TestSymlinkNotes(t *testing.T) {
+	notes := &state.TargetNotes{Notes: map[string][]state.Note{
+		"/home/u/.zshrc": {{Text: "uses work plugin; don't overwrite"}},
+		"/home/u/.other": {{Text: "not in the diff"}},
+	}}
+	result := &DiffResult{Symlinks: SymlinkDiff{DivergentLinks: []string{"/home/u/.zshrc", "/home/u/.vimrc"}}}
+	result.Notes = symlinkNotes(&result.Symlinks, notes)
+	if len(result.Notes) != 1 || result.Notes["/home/u/.zshrc"][0] != "uses work plugin; don't overwrite" {
+		t.Fatalf("Notes = %v", result.Notes)
+	}
+	if out := result.HumanReadable(false, true, false); !strings.Contains(out, "  - /home/u/.zshrc\n      📝 uses work plugin; don't overwrite\n") {
+		t.Errorf("note missing from output:\n%s", out)
+	}
+}
@@ -0,0 +1,150 @@
+// Package export renders the declared dotfiles state in formats that work
+// without merlin installed.
+package export
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ildx/merlin/internal/models"
+	"github.com/ildx/merlin/internal/symlink"
+)
+
+// ScriptSpec is the declared state a bootstrap script reproduces
+type ScriptSpec struct {
+	RepoRoot string // Dotfiles repository at export time
+	HomeDir  string // Home directory link targets were resolved against
+	Taps     []models.BrewTap
+	Formulae []models.BrewPackage
+	Casks    []models.BrewPackage
+	Apps     []models.MASApp
+	Links    []symlink.ResolvedLink // File-level links (see symlink.ExpandLinks)
+}
+
+const scriptHelpers = `set -euo pipefail
+
+# brew_formula/brew_cask <name> [post_install command]
+brew_formula() { brew list --formula "$1" >/dev/null 2>&1 || { brew install "$1" && post_install "${2:-}"; }; }
+brew_cask()    { brew list --cask "$1" >/dev/null 2>&1 || { brew install --cask "$1" && post_install "${2:-}"; }; }
+post_install() { [ -z "$1" ] || sh -c "$1"; }
+mas_app()      { mas list | grep -q "^$1 " || mas install "$1"; }
+
+# link <source relative to $DOTFILES> <target>: existing files are moved aside
+link() {
+  local src="$DOTFILES/$1" dst="$2"
+  if [ -e "$dst" ] && [ ! -L "$dst" ]; then
+    mv "$dst" "$dst.backup.$(date +%Y%m%d%H%M%S)"
+  fi
+  mkdir -p "$(dirname "$dst")"
+  ln -sfn "$src" "$dst"
+}
+`
+
+// WriteScript writes a standalone bash script that installs the packages and
+// creates the symlinks in spec. Paths under the home directory are written
+// relative to $HOME and sources relative to $DOTFILES, so the script works
+// for other users and checkout locations.
+func WriteScript(w io.Writer, spec ScriptSpec) error {
+	var sb strings.Builder
+	sb.WriteString("#!/usr/bin/env bash\n")
+	fmt.Fprintf(&sb, "# Generated by 'merlin export script' on %s\n", time.Now().Format("2006-01-02"))
+	sb.WriteString("# Reproduces the declared setup without merlin. Override the dotfiles\n")
+	sb.WriteString("# checkout location with DOTFILES=/path/to/dotfiles.\n")
+	sb.WriteString(scriptHelpers)
+	// A plain assignment, so the quoted default isn't nested in double quotes
+	fmt.Fprintf(&sb, "\n[ -n \"${DOTFILES:-}\" ] || DOTFILES=%s\n", homeRelative(spec.RepoRoot, spec.HomeDir))
+
+	if len(spec.Formulae)+len(spec.Casks) > 0 {
+		sb.WriteString("\n# Homebrew\n")
+		sb.WriteString("command -v brew >/dev/null 2>&1 || { echo \"Homebrew is required: https://brew.sh\" >&2; exit 1; }\n")
+		for _, tap := range spec.Taps {
+			// Fail before brew does when a private tap's credentials are missing
+			for _, name := range tap.AuthEnv {
+				fmt.Fprintf(&sb, ": \"${%s:?tap %s needs %s}\"\n", name, tap.Name, name)
+			}
+			command := "brew tap " + shellQuote(tap.Name)
+			if tap.URL != "" {
+				command += " " + shellQuote(tap.URL)
+			}
+			sb.WriteString(command + "\n")
+		}
+		for _, pkg := range spec.Formulae {
+			writeCommand(&sb, brewCommand("brew_formula", pkg), pkg.Description)
+		}
+		for _, pkg := range spec.Casks {
+			writeCommand(&sb, brewCommand("brew_cask", pkg), pkg.Description)
+		}
+	}
+
+	if len(spec.Apps) > 0 {
+		sb.WriteString("\n# Mac App Store (requires App Store sign-in)\n")
+		sb.WriteString("command -v mas >/dev/null 2>&1 || brew install mas\n")
+		for _, app := range spec.Apps {
+			writeCommand(&sb, fmt.Sprintf("mas_app %d", app.ID), app.Name)
+		}
+	}
+
+	if len(spec.Links) > 0 {
+		sb.WriteString("\n# Symlinks\n")
+		for _, link := range spec.Links {
+			source, err := filepath.Rel(spec.RepoRoot, link.Source)
+			if err != nil || strings.HasPrefix(source, "..") {
+				return fmt.Errorf("link source %s is outside the dotfiles repository", link.Source)
+			}
+			fmt.Fprintf(&sb, "link %s %s\n", shellQuote(filepath.ToSlash(source)), homeRelative(link.Target, spec.HomeDir))
+		}
+	}
+
+	sb.WriteString("\necho \"Done.\"\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func writeCommand(sb *strings.Builder, command, comment string) {
+	if comment != "" {
+		fmt.Fprintf(sb, "%s  # %s\n", command, strings.ReplaceAll(comment, "\n", " "))
+		return
+	}
+	sb.WriteString(command + "\n")
+}
+
+// homeRelative renders path as a shell word, using "$HOME" for the home prefix
+func homeRelative(path, homeDir string) string {
+	if homeDir != "" {
+		if rel, err := filepath.Rel(homeDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+			if rel == "." {
+				return `"$HOME"`
+			}
+			return `"$HOME"/` + shellQuote(filepath.ToSlash(rel))
+		}
+	}
+	return shellQuote(path)
+}
+
+// shellQuote single-quotes s unless it only contains safe characters
+func shellQuote(s string) string {
+	safe := s != ""
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./@%+=:,", r)) {
+			safe = false
+			break
+		}
+	}
+	if safe {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// brewCommand renders the helper call installing pkg, passing its
+// post_install command along
+func brewCommand(helper string, pkg models.BrewPackage) string {
+	command := helper + " " + shellQuote(pkg.Name)
+	if pkg.PostInstall != "" {
+		command += " " + shellQuote(pkg.PostInstall)
+	}
+	return command
+}
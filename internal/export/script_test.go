@@ -0,0 +1,96 @@
+package export
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ildx/merlin/internal/models"
+	"github.com/ildx/merlin/internal/symlink"
+)
+
+func TestWriteScript(t *testing.T) {
+	home := "/Users/me"
+	spec := ScriptSpec{
+		RepoRoot: "/Users/me/dotfiles",
+		HomeDir:  home,
+		Taps:     []models.BrewTap{{Name: "acme/tools", URL: "git@git.acme.dev:brew/tools.git", AuthEnv: []string{"ACME_TOKEN"}}},
+		Formulae: []models.BrewPackage{{Name: "git", Description: "Version control"}},
+		Casks:    []models.BrewPackage{{Name: "ghostty", PostInstall: "open -a Ghostty"}},
+		Apps:     []models.MASApp{{Name: "Things 3", ID: 904280696}},
+		Links: []symlink.ResolvedLink{
+			{Source: "/Users/me/dotfiles/config/zsh/config/.zshrc", Target: "/Users/me/.zshrc"},
+			{Source: "/Users/me/dotfiles/config/cursor/config/settings.json", Target: "/Users/me/Library/Application Support/Cursor/User/settings.json"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteScript(&buf, spec); err != nil {
+		t.Fatal(err)
+	}
+	script := buf.String()
+
+	for _, want := range []string{
+		`[ -n "${DOTFILES:-}" ] || DOTFILES="$HOME"/dotfiles`,
+		`: "${ACME_TOKEN:?tap acme/tools needs ACME_TOKEN}"`,
+		"brew tap acme/tools git@git.acme.dev:brew/tools.git",
+		"brew_formula git  # Version control",
+		"brew_cask ghostty 'open -a Ghostty'",
+		"mas_app 904280696  # Things 3",
+		`link config/zsh/config/.zshrc "$HOME"/.zshrc`,
+		`link config/cursor/config/settings.json "$HOME"/'Library/Application Support/Cursor/User/settings.json'`,
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script missing %q:\n%s", want, script)
+		}
+	}
+
+	if _, err := exec.LookPath("bash"); err == nil {
+		path := filepath.Join(t.TempDir(), "setup.sh")
+		os.WriteFile(path, buf.Bytes(), 0755)
+		if out, err := exec.Command("bash", "-n", path).CombinedOutput(); err != nil {
+			t.Errorf("generated script has syntax errors: %v\n%s", err, out)
+		}
+	}
+}
+
+func TestWriteScriptLinksRun(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not available")
+	}
+	// The repository path is quoted when it has a space, e.g. under iCloud
+	for _, name := range []string{"dotfiles", "my dots"} {
+		t.Run(name, func(t *testing.T) {
+			home := t.TempDir()
+			repo := filepath.Join(home, name)
+			source := filepath.Join(repo, "config", "git", "config", "config")
+			os.MkdirAll(filepath.Dir(source), 0755)
+			os.WriteFile(source, []byte("[user]"), 0644)
+			target := filepath.Join(home, ".config", "git", "config")
+
+			var buf bytes.Buffer
+			err := WriteScript(&buf, ScriptSpec{
+				RepoRoot: repo,
+				HomeDir:  home,
+				Links:    []symlink.ResolvedLink{{Source: source, Target: target}},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(t.TempDir(), "setup.sh")
+			os.WriteFile(path, buf.Bytes(), 0755)
+
+			cmd := exec.Command("bash", path)
+			cmd.Env = append(os.Environ(), "HOME="+home, "DOTFILES=")
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("script failed: %v\n%s", err, out)
+			}
+			if dest, err := os.Readlink(target); err != nil || dest != source {
+				t.Errorf("expected %s -> %s, got %q (%v)", target, source, dest, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package git
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// batchFile holds the pending auto-commits, inside the git directory so it is
+// per repository and never committed
+const batchFile = "merlin-pending-commits.json"
+
+// BatchTrailer prefixes the line each batched action gets in the commit body
+const BatchTrailer = "Merlin-Action"
+
+// PendingCommit is an auto-commit whose paths are staged but not committed
+// yet ([settings.git] batch_window)
+type PendingCommit struct {
+	Op      string    `json:"op"`
+	Summary string    `json:"summary"`
+	Tools   []string  `json:"tools,omitempty"`
+	Count   int       `json:"count"`
+	Paths   []string  `json:"paths,omitempty"`
+	Date    time.Time `json:"date"`
+}
+
+func (r *Repo) batchPath() (string, error) {
+	return r.gitDirPath(batchFile)
+}
+
+// gitDirPath returns the path of name inside the repository's git directory
+func (r *Repo) gitDirPath(name string) (string, error) {
+	out, err := exec.Command("git", "-C", r.Root, "rev-parse", "--absolute-git-dir").Output()
+	if err != nil {
+		return "", fmt.Errorf("locate git directory: %w", err)
+	}
+	return filepath.Join(strings.TrimSpace(string(out)), name), nil
+}
+
+// PendingCommits returns the queued auto-commits, oldest first
+func (r *Repo) PendingCommits() ([]PendingCommit, error) {
+	path, err := r.batchPath()
+	if err != nil {
+		return nil, err
+	}
+	return readCommits(path)
+}
+
+// readCommits reads a list of recorded auto-commits; a missing file is empty
+func readCommits(path string) ([]PendingCommit, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var commits []PendingCommit
+	if err := json.Unmarshal(data, &commits); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return commits, nil
+}
+
+// QueueCommit stages paths and records info as pending instead of committing
+func (r *Repo) QueueCommit(info CommitInfo, paths []string) error {
+	if len(paths) > 0 {
+		args := append([]string{"-C", r.Root, "add"}, paths...)
+		if err := exec.Command("git", args...).Run(); err != nil {
+			return err
+		}
+	}
+	pending, err := r.PendingCommits()
+	if err != nil {
+		return err
+	}
+	pending = append(pending, PendingCommit{
+		Op: info.Op, Summary: info.Summary, Tools: info.Tools, Count: info.Count, Paths: paths, Date: info.Date,
+	})
+	return r.savePending(pending)
+}
+
+func (r *Repo) savePending(pending []PendingCommit) error {
+	path, err := r.batchPath()
+	if err != nil {
+		return err
+	}
+	return writeCommits(path, pending)
+}
+
+// writeCommits writes a list of recorded auto-commits, removing the file
+// when the list is empty
+func writeCommits(path string, commits []PendingCommit) error {
+	if len(commits) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(commits, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// BatchDue reports whether the pending commits have been quiet for window,
+// measured from the most recent one
+func (r *Repo) BatchDue(window time.Duration, now time.Time) (bool, error) {
+	pending, err := r.PendingCommits()
+	if err != nil || len(pending) == 0 {
+		return false, err
+	}
+	return now.Sub(pending[len(pending)-1].Date) >= window, nil
+}
+
+// BatchInfo combines pending commits into the CommitInfo of one commit: the
+// shared op (or "batch" when they differ), every tool involved, and a summary
+// naming the first actions
+func BatchInfo(pending []PendingCommit) CommitInfo {
+	if len(pending) == 1 {
+		p := pending[0]
+		return CommitInfo{Op: p.Op, Summary: p.Summary, Tools: p.Tools, Count: p.Count, Date: p.Date}
+	}
+	info := CommitInfo{Op: pending[0].Op, Count: len(pending), Date: time.Now()}
+	var summaries []string
+	for _, p := range pending {
+		if p.Op != info.Op {
+			info.Op = "batch"
+		}
+		for _, tool := range p.Tools {
+			if !slices.Contains(info.Tools, tool) {
+				info.Tools = append(info.Tools, tool)
+			}
+		}
+		summaries = append(summaries, p.Summary)
+	}
+	if len(summaries) > 3 {
+		summaries = append(summaries[:3], "…")
+	}
+	info.Summary = fmt.Sprintf("%d operations (%s)", len(pending), strings.Join(summaries, "; "))
+	return info
+}
+
+// FlushCommits commits everything staged for the pending auto-commits as one
+// commit whose subject renders template (see CommitMessage) and whose body
+// lists each action as a Merlin-Action trailer. Like single auto-commits, an
+// empty commit keeps the audit trail when nothing changed. It returns the
+// subject, or "" when nothing was pending.
+func (r *Repo) FlushCommits(template string) (string, error) {
+	pending, err := r.PendingCommits()
+	if err != nil || len(pending) == 0 {
+		return "", err
+	}
+	var paths []string
+	for _, p := range pending {
+		paths = append(paths, p.Paths...)
+	}
+	subject, err := r.commitRecorded(template, pending, paths)
+	if err != nil {
+		return "", err
+	}
+	return subject, r.savePending(nil)
+}
+
+// commitRecorded commits paths (staged already) for recorded auto-commits,
+// with a subject from template and BatchInfo and one Merlin-Action trailer
+// per action. Nothing else staged is included; with no paths the commit is
+// empty.
+func (r *Repo) commitRecorded(template string, commits []PendingCommit, paths []string) (string, error) {
+	subject := CommitMessage(template, BatchInfo(commits))
+	var body strings.Builder
+	for _, p := range commits {
+		fmt.Fprintf(&body, "%s: %s %s\n", BatchTrailer, p.Date.Format(time.RFC3339), p.Summary)
+	}
+	out, err := exec.Command("git", commitOnly(r.Root, paths, "-m", subject, "-m", strings.TrimSpace(body.String()))...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git commit: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return subject, nil
+}
+
+// commitOnly returns the git arguments committing just paths, or an empty
+// commit when there are none, whatever else is staged
+func commitOnly(root string, paths []string, message ...string) []string {
+	args := append([]string{"-C", root, "commit", "--allow-empty", "--only"}, message...)
+	if len(paths) > 0 {
+		args = append(append(args, "--"), paths...)
+	}
+	return args
+}
@@ -0,0 +1,92 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBatchInfo(t *testing.T) {
+	pending := []PendingCommit{
+		{Op: "link", Summary: "link zsh", Tools: []string{"zsh"}, Count: 1},
+		{Op: "link", Summary: "link git, zsh (2 tools)", Tools: []string{"git", "zsh"}, Count: 2},
+	}
+	info := BatchInfo(pending)
+	if info.Op != "link" || info.Count != 2 || strings.Join(info.Tools, ",") != "zsh,git" ||
+		info.Summary != "2 operations (link zsh; link git, zsh (2 tools))" {
+		t.Errorf("BatchInfo() = %+v", info)
+	}
+
+	pending = append(pending, PendingCommit{Op: "backup", Summary: "record 1"}, PendingCommit{Op: "unlink", Summary: "unlink eza"})
+	info = BatchInfo(pending)
+	if info.Op != "batch" || !strings.HasSuffix(info.Summary, "; record 1; …)") {
+		t.Errorf("mixed BatchInfo() = %+v", info)
+	}
+}
+
+func TestQueueAndFlushCommits(t *testing.T) {
+	if !IsGitAvailable() {
+		t.Skip("git not available")
+	}
+	tmp := t.TempDir()
+	for _, args := range [][]string{{"init"}, {"config", "user.email", "t@example.com"}, {"config", "user.name", "T"}} {
+		if out, err := exec.Command("git", append([]string{"-C", tmp}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v %s", args, err, out)
+		}
+	}
+	repo, err := Open(tmp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now().Add(-time.Hour)
+	for i, tool := range []string{"zsh", "git"} {
+		os.MkdirAll(filepath.Join(tmp, "config", tool), 0755)
+		os.WriteFile(filepath.Join(tmp, "config", tool, "rc"), []byte(tool), 0644)
+		info := ToolsCommit("link", []string{tool})
+		info.Date = start.Add(time.Duration(i) * time.Minute)
+		if err := repo.QueueCommit(info, []string{"config/" + tool}); err != nil {
+			t.Fatalf("QueueCommit(%s): %v", tool, err)
+		}
+	}
+
+	// A direct commit while the batch is pending leaves the batch staged
+	os.WriteFile(filepath.Join(tmp, "notes.txt"), []byte("notes"), 0644)
+	if err := repo.Commit("direct", []string{"notes.txt"}); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	if out, _ := exec.Command("git", "-C", tmp, "show", "--name-only", "--format=", "HEAD").Output(); strings.TrimSpace(string(out)) != "notes.txt" {
+		t.Errorf("direct commit included %q", out)
+	}
+
+	if due, _ := repo.BatchDue(30*time.Minute, start.Add(10*time.Minute)); due {
+		t.Error("batch due before the quiet period elapsed")
+	}
+	if due, _ := repo.BatchDue(30*time.Minute, time.Now()); !due {
+		t.Error("batch not due after the quiet period")
+	}
+
+	subject, err := repo.FlushCommits("")
+	if err != nil {
+		t.Fatalf("FlushCommits: %v", err)
+	}
+	if subject != "chore(link): 2 operations (link zsh; link git)" {
+		t.Errorf("subject = %q", subject)
+	}
+	out, _ := exec.Command("git", "-C", tmp, "log", "-1", "--format=%(trailers:key="+BatchTrailer+",valueonly)").Output()
+	if lines := strings.Fields(string(out)); len(lines) != 6 || lines[2] != "zsh" || lines[5] != "git" {
+		t.Errorf("trailers = %q", out)
+	}
+	if out, _ := exec.Command("git", "-C", tmp, "show", "--name-only", "--format=", "HEAD").Output(); strings.Join(strings.Fields(string(out)), ",") != "config/git/rc,config/zsh/rc" {
+		t.Errorf("flushed commit included %q", out)
+	}
+	if pending, _ := repo.PendingCommits(); len(pending) != 0 {
+		t.Errorf("pending after flush = %v", pending)
+	}
+	if st, _ := repo.Status(); !st.Clean {
+		t.Errorf("repository not clean after flush: %+v", st)
+	}
+}
@@ -0,0 +1,90 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// blockedFile holds auto-commits skipped because of unrelated changes, inside
+// the git directory like the batch queue
+const blockedFile = "merlin-blocked-commits.json"
+
+func (r *Repo) blockedPath() (string, error) {
+	return r.gitDirPath(blockedFile)
+}
+
+// BlockedCommits returns the auto-commits skipped because unrelated changes
+// were present, oldest first
+func (r *Repo) BlockedCommits() ([]PendingCommit, error) {
+	path, err := r.blockedPath()
+	if err != nil {
+		return nil, err
+	}
+	return readCommits(path)
+}
+
+// RecordBlockedCommit remembers an auto-commit that was skipped, so merlin
+// repo commit can create it once the user decides what to do with the
+// unrelated changes. Nothing is staged.
+func (r *Repo) RecordBlockedCommit(info CommitInfo, paths []string) error {
+	blocked, err := r.BlockedCommits()
+	if err != nil {
+		return err
+	}
+	blocked = append(blocked, PendingCommit{
+		Op: info.Op, Summary: info.Summary, Tools: info.Tools, Count: info.Count, Paths: paths, Date: info.Date,
+	})
+	path, err := r.blockedPath()
+	if err != nil {
+		return err
+	}
+	return writeCommits(path, blocked)
+}
+
+// CommitBlocked stages the paths of the blocked auto-commits plus include
+// (relative to the repository root) and commits them as one commit, rendered
+// like a flushed batch. It returns the subject, or "" when nothing was
+// blocked and include is empty. message replaces the rendered subject when
+// set, and is required when only include is given.
+func (r *Repo) CommitBlocked(template string, include []string, message string) (string, error) {
+	blocked, err := r.BlockedCommits()
+	if err != nil {
+		return "", err
+	}
+	if len(blocked) == 0 && len(include) == 0 {
+		return "", nil
+	}
+
+	if message == "" && len(blocked) == 0 {
+		return "", errors.New("a commit message is required when no auto-commit is blocked")
+	}
+
+	var paths []string
+	for _, b := range blocked {
+		paths = append(paths, b.Paths...)
+	}
+	paths = append(paths, include...)
+	if len(paths) > 0 {
+		args := append([]string{"-C", r.Root, "add", "--all", "--"}, paths...)
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			return "", fmt.Errorf("git add: %v: %s", err, strings.TrimSpace(string(out)))
+		}
+	}
+
+	subject := message
+	if subject == "" {
+		if subject, err = r.commitRecorded(template, blocked, paths); err != nil {
+			return "", err
+		}
+	} else if out, err := exec.Command("git", commitOnly(r.Root, paths, "-m", subject)...).CombinedOutput(); err != nil {
+		return "", fmt.Errorf("git commit: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+
+	path, err := r.blockedPath()
+	if err != nil {
+		return "", err
+	}
+	return subject, writeCommits(path, nil)
+}
@@ -3,6 +3,8 @@ package git
 import (
 	"bytes"
 	"errors"
+	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -67,12 +69,33 @@ func (r *Repo) Status() (*Status, error) {
 	return st, nil
 }
 
-// HasUnrelatedChanges returns true if there are unstaged or untracked changes outside the allowlist prefixes.
-// allowPrefixes should be relative paths (directories) under repo root considered safe to commit.
-func (r *Repo) HasUnrelatedChanges(allowPrefixes []string) (bool, error) {
+// UnrelatedChanges lists the changes that would block an auto-commit of
+// allowPrefixes: untracked, unstaged and conflicted paths outside them
+type UnrelatedChanges struct {
+	Untracked  []string `json:"untracked,omitempty"`
+	Unstaged   []string `json:"unstaged,omitempty"`
+	Conflicted []string `json:"conflicted,omitempty"`
+}
+
+// Paths returns every blocking path: conflicted first, then unstaged and untracked
+func (u *UnrelatedChanges) Paths() []string {
+	paths := append([]string{}, u.Conflicted...)
+	paths = append(paths, u.Unstaged...)
+	return append(paths, u.Untracked...)
+}
+
+// Empty reports whether nothing blocks the commit
+func (u *UnrelatedChanges) Empty() bool {
+	return len(u.Untracked) == 0 && len(u.Unstaged) == 0 && len(u.Conflicted) == 0
+}
+
+// FindUnrelatedChanges reports the unstaged, untracked and conflicted changes
+// outside the allowlist prefixes. allowPrefixes should be relative paths
+// (directories or files) under repo root considered safe to commit.
+func (r *Repo) FindUnrelatedChanges(allowPrefixes []string) (*UnrelatedChanges, error) {
 	st, err := r.Status()
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 	// helper to test membership
 	inAllowed := func(p string) bool {
@@ -89,17 +112,69 @@ func (r *Repo) HasUnrelatedChanges(allowPrefixes []string) (bool, error) {
 		}
 		return false
 	}
-	for _, lists := range [][]string{st.Untracked, st.Unstaged, st.Conflicted} {
-		for _, path := range lists {
+	outside := func(paths []string) []string {
+		var out []string
+		for _, path := range paths {
 			if !inAllowed(path) {
-				return true, nil
+				out = append(out, path)
 			}
 		}
+		return out
+	}
+	// Status lists a new directory as one entry ("config/"); when an allowed
+	// path lies inside it, its files are checked one by one instead
+	var untracked []string
+	for _, path := range st.Untracked {
+		if strings.HasSuffix(path, "/") && containsPrefix(path, allowPrefixes) {
+			untracked = append(untracked, r.untrackedFiles(path)...)
+		} else {
+			untracked = append(untracked, path)
+		}
+	}
+	return &UnrelatedChanges{
+		Untracked:  outside(untracked),
+		Unstaged:   outside(st.Unstaged),
+		Conflicted: outside(st.Conflicted),
+	}, nil
+}
+
+// containsPrefix reports whether any of prefixes lies inside dir
+func containsPrefix(dir string, prefixes []string) bool {
+	for _, pref := range prefixes {
+		if pref != "" && strings.HasPrefix(pref, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// untrackedFiles lists the untracked, not ignored files under dir
+func (r *Repo) untrackedFiles(dir string) []string {
+	out, err := exec.Command("git", "-C", r.Root, "ls-files", "--others", "--exclude-standard", "--", dir).Output()
+	if err != nil {
+		return []string{dir}
+	}
+	var files []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if line != "" {
+			files = append(files, line)
+		}
+	}
+	return files
+}
+
+// HasUnrelatedChanges returns true if there are unstaged or untracked changes outside the allowlist prefixes.
+// allowPrefixes should be relative paths (directories) under repo root considered safe to commit.
+func (r *Repo) HasUnrelatedChanges(allowPrefixes []string) (bool, error) {
+	unrelated, err := r.FindUnrelatedChanges(allowPrefixes)
+	if err != nil {
+		return false, err
 	}
-	return false, nil
+	return !unrelated.Empty(), nil
 }
 
-// Commit stages provided paths (relative to repo root) and creates a commit.
+// Commit stages provided paths (relative to repo root) and commits only them,
+// leaving anything else staged (e.g. a pending auto-commit batch) for later.
 // If paths is empty, it commits all staged changes; if none staged returns error.
 func (r *Repo) Commit(message string, paths []string) error {
 	if len(paths) > 0 {
@@ -117,9 +192,74 @@ func (r *Repo) Commit(message string, paths []string) error {
 	if len(st.Staged) == 0 {
 		return errors.New("no staged changes to commit")
 	}
-	cmd := exec.Command("git", "-C", r.Root, "commit", "-m", message)
+	args := []string{"-C", r.Root, "commit", "-m", message}
+	if len(paths) > 0 {
+		args = append(append(args, "--"), paths...)
+	}
+	if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("git commit: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// IsIgnored reports whether path (relative to repo root) is excluded by the
+// repository's ignore rules.
+func (r *Repo) IsIgnored(path string) bool {
+	return exec.Command("git", "-C", r.Root, "check-ignore", "-q", path).Run() == nil
+}
+
+// Clone clones url into dest, streaming git's progress to output, and opens
+// the new repository.
+func Clone(url, dest string, output io.Writer) (*Repo, error) {
+	cmd := exec.Command("git", "clone", "--", url, dest)
+	cmd.Stdout, cmd.Stderr = output, output
 	if err := cmd.Run(); err != nil {
-		return err
+		return nil, fmt.Errorf("git clone %s: %w", url, err)
+	}
+	return Open(dest)
+}
+
+// Upstream returns the branch the current branch tracks, e.g. "origin/main",
+// or "" when it tracks none.
+func (r *Repo) Upstream() string {
+	out, err := exec.Command("git", "-C", r.Root, "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{u}").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// Ahead returns how many local commits the upstream doesn't have yet.
+func (r *Repo) Ahead() (int, error) {
+	out, err := exec.Command("git", "-C", r.Root, "rev-list", "--count", "@{u}..HEAD").Output()
+	if err != nil {
+		return 0, fmt.Errorf("git rev-list: %w", err)
+	}
+	var n int
+	if _, err := fmt.Sscan(string(out), &n); err != nil {
+		return 0, fmt.Errorf("git rev-list: %w", err)
+	}
+	return n, nil
+}
+
+// Pull rebases the current branch onto its upstream, stashing local changes
+// for the duration, and streams git's output to output.
+func (r *Repo) Pull(output io.Writer) error {
+	cmd := exec.Command("git", "-C", r.Root, "pull", "--rebase", "--autostash")
+	cmd.Stdout, cmd.Stderr = output, output
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git pull: %w", err)
+	}
+	return nil
+}
+
+// Push pushes the current branch to its upstream, streaming git's output to
+// output.
+func (r *Repo) Push(output io.Writer) error {
+	cmd := exec.Command("git", "-C", r.Root, "push")
+	cmd.Stdout, cmd.Stderr = output, output
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git push: %w", err)
 	}
 	return nil
 }
@@ -1,6 +1,7 @@
 package git
 
 import (
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -54,3 +55,118 @@ func TestStatusAndCommit(t *testing.T) {
 		t.Fatalf("expected clean repo after commit")
 	}
 }
+
+func TestIsIgnored(t *testing.T) {
+	if !IsGitAvailable() {
+		t.Skip("git not available")
+	}
+	tmp := t.TempDir()
+	if out, err := exec.Command("git", "-C", tmp, "init").CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v %s", err, string(out))
+	}
+	repo, err := Open(tmp)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmp, ".gitignore"), []byte("*.local.toml\n"), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if !repo.IsIgnored("merlin.local.toml") {
+		t.Errorf("expected merlin.local.toml to be ignored")
+	}
+	if repo.IsIgnored("merlin.toml") {
+		t.Errorf("expected merlin.toml not to be ignored")
+	}
+}
+
+func TestFindUnrelatedChanges(t *testing.T) {
+	if !IsGitAvailable() {
+		t.Skip("git not available")
+	}
+	tmp := t.TempDir()
+	if out, err := exec.Command("git", "-C", tmp, "init").CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v %s", err, out)
+	}
+	os.MkdirAll(filepath.Join(tmp, "config", "zsh"), 0755)
+	os.WriteFile(filepath.Join(tmp, "config", "zsh", "zshrc"), []byte("x"), 0644)
+	os.WriteFile(filepath.Join(tmp, "notes.txt"), []byte("x"), 0644)
+	repo, err := Open(tmp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unrelated, err := repo.FindUnrelatedChanges([]string{"config/zsh"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := unrelated.Paths(); len(got) != 1 || got[0] != "notes.txt" {
+		t.Errorf("Paths() = %v, want [notes.txt]", got)
+	}
+	if has, _ := repo.HasUnrelatedChanges([]string{"config/zsh", "notes.txt"}); has {
+		t.Error("expected no unrelated changes once notes.txt is allowed")
+	}
+
+	// A new directory without allowed paths is reported as one entry
+	os.MkdirAll(filepath.Join(tmp, "scratch"), 0755)
+	os.WriteFile(filepath.Join(tmp, "scratch", "a"), []byte("x"), 0644)
+	os.WriteFile(filepath.Join(tmp, "scratch", "b"), []byte("x"), 0644)
+	unrelated, _ = repo.FindUnrelatedChanges([]string{"config/zsh", "notes.txt"})
+	if got := unrelated.Paths(); len(got) != 1 || got[0] != "scratch/" {
+		t.Errorf("Paths() = %v, want [scratch/]", got)
+	}
+}
+
+func TestPullPush(t *testing.T) {
+	if !IsGitAvailable() {
+		t.Skip("git not available")
+	}
+	tmp := t.TempDir()
+	remote := filepath.Join(tmp, "remote.git")
+	seed := filepath.Join(tmp, "seed")
+	for _, args := range [][]string{
+		{"init", "--bare", remote},
+		{"init", seed},
+		{"-C", seed, "commit", "--allow-empty", "-m", "init"},
+		{"-C", seed, "push", remote, "HEAD"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v %s", args, err, out)
+		}
+	}
+	if repo, _ := Open(seed); repo.Upstream() != "" {
+		t.Errorf("Upstream() = %q for a branch tracking nothing", repo.Upstream())
+	}
+
+	a, err := Clone(remote, filepath.Join(tmp, "a"), io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Clone(remote, filepath.Join(tmp, "b"), io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Upstream() == "" {
+		t.Error("expected a clone to track its remote branch")
+	}
+
+	os.WriteFile(filepath.Join(a.Root, "zshrc"), []byte("x"), 0644)
+	if err := a.Commit("add zshrc", []string{"zshrc"}); err != nil {
+		t.Fatal(err)
+	}
+	if ahead, err := a.Ahead(); err != nil || ahead != 1 {
+		t.Fatalf("Ahead() = %d, %v; want 1", ahead, err)
+	}
+	if err := a.Push(io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if ahead, _ := a.Ahead(); ahead != 0 {
+		t.Errorf("Ahead() after push = %d, want 0", ahead)
+	}
+
+	if err := b.Pull(io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(b.Root, "zshrc")); err != nil {
+		t.Errorf("expected pull to bring zshrc: %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Markers delimiting the block of .gitignore that merlin maintains
+const (
+	GitignoreBegin = "# >>> merlin managed (merlin repo gitignore sync) >>>"
+	GitignoreEnd   = "# <<< merlin managed <<<"
+)
+
+// DefaultIgnores are the files merlin generates inside a dotfiles repository
+// that must never be committed. The backup index, machine inventory and
+// published machine snapshots are the state in .merlin-meta meant to be tracked.
+var DefaultIgnores = []string{
+	"merlin.local.toml",
+	".merlin-meta/*",
+	"!.merlin-meta/backups.json",
+	"!.merlin-meta/machines.toml",
+	"!.merlin-meta/machines/",
+}
+
+// ManagedIgnores returns DefaultIgnores followed by extra (from [settings.git]
+// ignore), without duplicates
+func ManagedIgnores(extra []string) []string {
+	patterns := slices.Clone(DefaultIgnores)
+	for _, p := range extra {
+		if p = strings.TrimSpace(p); p != "" && !slices.Contains(patterns, p) {
+			patterns = append(patterns, p)
+		}
+	}
+	return patterns
+}
+
+// RenderGitignore returns content with the managed block replaced by
+// patterns, or appended when content has none. Lines outside the block are
+// kept as written.
+func RenderGitignore(content string, patterns []string) string {
+	block := GitignoreBegin + "\n" + strings.Join(patterns, "\n") + "\n" + GitignoreEnd + "\n"
+
+	begin := strings.Index(content, GitignoreBegin)
+	if begin >= 0 {
+		if end := strings.Index(content[begin:], GitignoreEnd); end >= 0 {
+			rest := content[begin+end+len(GitignoreEnd):]
+			return content[:begin] + block + strings.TrimPrefix(rest, "\n")
+		}
+	}
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	if content != "" {
+		content += "\n"
+	}
+	return content + block
+}
+
+// SyncGitignore writes the managed block of root/.gitignore, creating the
+// file if needed. It reports whether the file changed; with dryRun nothing is
+// written.
+func SyncGitignore(root string, patterns []string, dryRun bool) (bool, error) {
+	path := filepath.Join(root, ".gitignore")
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	updated := RenderGitignore(string(data), patterns)
+	if updated == string(data) {
+		return false, nil
+	}
+	if dryRun {
+		return true, nil
+	}
+	return true, os.WriteFile(path, []byte(updated), 0644)
+}
@@ -0,0 +1,48 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderGitignore(t *testing.T) {
+	patterns := ManagedIgnores([]string{"config/*/generated/", "merlin.local.toml"})
+	if len(patterns) != len(DefaultIgnores)+1 {
+		t.Fatalf("ManagedIgnores kept a duplicate: %v", patterns)
+	}
+
+	// Appended after existing rules, separated by a blank line
+	got := RenderGitignore(".DS_Store", patterns)
+	if !strings.HasPrefix(got, ".DS_Store\n\n"+GitignoreBegin+"\nmerlin.local.toml\n") || !strings.HasSuffix(got, "config/*/generated/\n"+GitignoreEnd+"\n") {
+		t.Errorf("appended block:\n%s", got)
+	}
+
+	// Replaced in place, keeping the lines around it
+	existing := "node_modules/\n" + GitignoreBegin + "\nold-pattern\n" + GitignoreEnd + "\n*.swp\n"
+	got = RenderGitignore(existing, []string{"merlin.local.toml"})
+	want := "node_modules/\n" + GitignoreBegin + "\nmerlin.local.toml\n" + GitignoreEnd + "\n*.swp\n"
+	if got != want {
+		t.Errorf("replaced block = %q, want %q", got, want)
+	}
+	if again := RenderGitignore(got, []string{"merlin.local.toml"}); again != got {
+		t.Errorf("re-rendering changed the file: %q", again)
+	}
+}
+
+func TestSyncGitignore(t *testing.T) {
+	root := t.TempDir()
+	if changed, err := SyncGitignore(root, DefaultIgnores, true); err != nil || !changed {
+		t.Fatalf("dry run = %v, %v; want a pending change", changed, err)
+	}
+	if _, err := os.Stat(filepath.Join(root, ".gitignore")); !os.IsNotExist(err) {
+		t.Fatal("dry run wrote .gitignore")
+	}
+	if changed, err := SyncGitignore(root, DefaultIgnores, false); err != nil || !changed {
+		t.Fatalf("sync = %v, %v", changed, err)
+	}
+	if changed, _ := SyncGitignore(root, DefaultIgnores, false); changed {
+		t.Error("second sync reported a change")
+	}
+}
@@ -0,0 +1,92 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ildx/merlin/internal/placeholder"
+)
+
+// DefaultCommitTemplate renders the auto-commit messages merlin has always
+// written, e.g. "chore(link): link zsh, git (2 tools)"
+const DefaultCommitTemplate = "chore({op}): {summary}"
+
+// CommitPlaceholders are the names a commit template may use
+var CommitPlaceholders = []string{"op", "summary", "tools", "count", "date"}
+
+// CommitInfo describes the operation an auto-commit records
+type CommitInfo struct {
+	Op      string    // link, unlink, backup, remove or rename
+	Summary string    // e.g. "link zsh, git (2 tools)"
+	Tools   []string  // tools involved, if any
+	Count   int       // number of tools, or files for a backup
+	Date    time.Time // when the operation ran
+}
+
+// ToolsCommit describes linking or unlinking tools. Long tool lists are
+// shortened in the summary to the first three names.
+func ToolsCommit(op string, tools []string) CommitInfo {
+	info := CommitInfo{Op: op, Tools: tools, Count: len(tools), Date: time.Now()}
+	switch {
+	case len(tools) == 0:
+		info.Summary = "no tools"
+	case len(tools) == 1:
+		info.Summary = fmt.Sprintf("%s %s", op, tools[0])
+	case len(tools) <= 3:
+		info.Summary = fmt.Sprintf("%s %s (%d tools)", op, strings.Join(tools, ", "), len(tools))
+	default:
+		info.Summary = fmt.Sprintf("%s %d tools (%s, …)", op, len(tools), strings.Join(tools[:3], ", "))
+	}
+	return info
+}
+
+// BackupCommit describes recording a backup in the repository's backup index
+func BackupCommit(id string, files int) CommitInfo {
+	return CommitInfo{
+		Op:      "backup",
+		Summary: fmt.Sprintf("record %s (%d files)", id, files),
+		Count:   files,
+		Date:    time.Now(),
+	}
+}
+
+// RenameCommit describes renaming tool oldName to newName
+func RenameCommit(oldName, newName string) CommitInfo {
+	return CommitInfo{
+		Op:      "rename",
+		Summary: fmt.Sprintf("rename %s to %s", oldName, newName),
+		Tools:   []string{oldName, newName},
+		Count:   1,
+		Date:    time.Now(),
+	}
+}
+
+// CommitMessage renders template ([settings.git] commit_template, or
+// DefaultCommitTemplate when empty) for info. Placeholders: {op}, {summary},
+// {tools} (comma-separated), {count} and {date} (YYYY-MM-DD). Unknown
+// placeholders are left as written.
+func CommitMessage(template string, info CommitInfo) string {
+	if strings.TrimSpace(template) == "" {
+		template = DefaultCommitTemplate
+	}
+	values := map[string]string{
+		"op":      info.Op,
+		"summary": info.Summary,
+		"tools":   strings.Join(info.Tools, ", "),
+		"count":   fmt.Sprintf("%d", info.Count),
+		"date":    info.Date.Format("2006-01-02"),
+	}
+	return placeholder.Pattern.ReplaceAllStringFunc(template, func(m string) string {
+		if value, ok := values[m[1:len(m)-1]]; ok {
+			return value
+		}
+		return m
+	})
+}
+
+// UnknownPlaceholders returns the {name} placeholders in template that
+// CommitMessage doesn't know
+func UnknownPlaceholders(template string) []string {
+	return placeholder.Unknown(template, CommitPlaceholders)
+}
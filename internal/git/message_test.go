@@ -0,0 +1,58 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestToolsCommitSummary(t *testing.T) {
+	cases := []struct {
+		tools []string
+		want  string
+	}{
+		{nil, "no tools"},
+		{[]string{"zsh"}, "link zsh"},
+		{[]string{"zsh", "git"}, "link zsh, git (2 tools)"},
+		{[]string{"zsh", "git", "eza", "mise", "zellij"}, "link 5 tools (zsh, git, eza, …)"},
+	}
+	for _, c := range cases {
+		if got := ToolsCommit("link", c.tools).Summary; got != c.want {
+			t.Errorf("ToolsCommit(%v).Summary = %q, want %q", c.tools, got, c.want)
+		}
+	}
+}
+
+func TestCommitMessage(t *testing.T) {
+	info := ToolsCommit("unlink", []string{"zsh", "git"})
+	info.Date = time.Date(2025, 1, 8, 14, 30, 0, 0, time.UTC)
+
+	cases := map[string]string{
+		"":                                   "chore(unlink): unlink zsh, git (2 tools)",
+		"chore({op}): {summary}":             "chore(unlink): unlink zsh, git (2 tools)",
+		"dotfiles: {op} {tools} ({count})":   "dotfiles: unlink zsh, git (2)",
+		"[{date}] {op}: {summary} {unknown}": "[2025-01-08] unlink: unlink zsh, git (2 tools) {unknown}",
+	}
+	for template, want := range cases {
+		if got := CommitMessage(template, info); got != want {
+			t.Errorf("CommitMessage(%q) = %q, want %q", template, got, want)
+		}
+	}
+
+	backup := CommitMessage("", BackupCommit("20250108_143022", 3))
+	if backup != "chore(backup): record 20250108_143022 (3 files)" {
+		t.Errorf("backup message = %q", backup)
+	}
+
+	rename := CommitMessage("{op}: {tools}", RenameCommit("vim", "nvim"))
+	if rename != "rename: vim, nvim" {
+		t.Errorf("rename message = %q", rename)
+	}
+}
+
+func TestUnknownPlaceholders(t *testing.T) {
+	got := UnknownPlaceholders("{op}: {summary} {ticket} {date} {user}")
+	if want := []string{"{ticket}", "{user}"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("UnknownPlaceholders = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,193 @@
+// Package graph renders small directed graphs (brew formulae, tools) as
+// Graphviz DOT or Mermaid flowcharts.
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Formats lists the output formats Render accepts
+var Formats = []string{"dot", "mermaid"}
+
+// Style is how the nodes of one class are drawn
+type Style struct {
+	Fill   string // fill color, e.g. "#c6f6d5"; empty leaves the default
+	Dashed bool   // dashed border
+	Label  string // legend entry, e.g. "declared leaf"
+}
+
+// Node is a vertex; Class picks its Style
+type Node struct {
+	ID    string
+	Label string // shown instead of ID when set
+	Class string
+}
+
+// Edge points From a node To another, e.g. a formula to its dependency
+type Edge struct {
+	From, To string
+	Flagged  bool // drawn in red, e.g. part of a cycle
+	Dashed   bool // a looser relation than dependency, e.g. profile membership
+}
+
+// Graph is a directed graph with styled node classes
+type Graph struct {
+	Name    string
+	Nodes   []Node
+	Edges   []Edge
+	Styles  map[string]Style // by node class
+	Classes []string         // classes drawn, in order; nodes of other classes are left out
+}
+
+// AddNode adds a node unless one with the same ID exists
+func (g *Graph) AddNode(id, class string) {
+	g.AddLabeledNode(id, "", class)
+}
+
+// AddLabeledNode adds a node shown as label unless one with the same ID exists
+func (g *Graph) AddLabeledNode(id, label, class string) {
+	for _, n := range g.Nodes {
+		if n.ID == id {
+			return
+		}
+	}
+	g.Nodes = append(g.Nodes, Node{ID: id, Label: label, Class: class})
+}
+
+// Render returns g in format ("dot" or "mermaid")
+func (g *Graph) Render(format string) (string, error) {
+	switch format {
+	case "dot":
+		return g.DOT(), nil
+	case "mermaid":
+		return g.Mermaid(), nil
+	}
+	return "", fmt.Errorf("unknown format %q (must be: %s)", format, strings.Join(Formats, " or "))
+}
+
+// DOT renders g for Graphviz, e.g. `dot -Tsvg`
+func (g *Graph) DOT() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %q {\n", g.Name)
+	b.WriteString("  rankdir=LR;\n  node [shape=box, style=rounded];\n")
+	for _, class := range g.Classes {
+		style := g.Styles[class]
+		var attrs []string
+		styles := []string{"rounded"}
+		if style.Fill != "" {
+			styles = append(styles, "filled")
+			attrs = append(attrs, fmt.Sprintf("fillcolor=%q", style.Fill))
+		}
+		if style.Dashed {
+			styles = append(styles, "dashed")
+		}
+		attrs = append([]string{fmt.Sprintf("style=%q", strings.Join(styles, ","))}, attrs...)
+		fmt.Fprintf(&b, "\n  // %s\n", style.Label)
+		for _, n := range g.nodesOf(class) {
+			if n.Label != "" {
+				fmt.Fprintf(&b, "  %q [label=%q, %s];\n", n.ID, n.Label, strings.Join(attrs, ", "))
+			} else {
+				fmt.Fprintf(&b, "  %q [%s];\n", n.ID, strings.Join(attrs, ", "))
+			}
+		}
+	}
+	if len(g.Edges) > 0 {
+		b.WriteString("\n")
+	}
+	for _, e := range g.sortedEdges() {
+		var attrs []string
+		if e.Flagged {
+			attrs = append(attrs, "color=red")
+		}
+		if e.Dashed {
+			attrs = append(attrs, "style=dashed")
+		}
+		if len(attrs) > 0 {
+			fmt.Fprintf(&b, "  %q -> %q [%s];\n", e.From, e.To, strings.Join(attrs, ", "))
+		} else {
+			fmt.Fprintf(&b, "  %q -> %q;\n", e.From, e.To)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Mermaid renders g as a Mermaid flowchart, e.g. for a Markdown file
+func (g *Graph) Mermaid() string {
+	// Names such as "python@3.12" aren't valid Mermaid IDs, so nodes get
+	// numbered IDs and keep their name as the label
+	ids := make(map[string]string, len(g.Nodes))
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for _, class := range g.Classes {
+		style := g.Styles[class]
+		fmt.Fprintf(&b, "  %%%% %s\n", style.Label)
+		for _, n := range g.nodesOf(class) {
+			ids[n.ID] = fmt.Sprintf("n%d", len(ids))
+			label := n.Label
+			if label == "" {
+				label = n.ID
+			}
+			fmt.Fprintf(&b, "  %s[\"%s\"]:::%s\n", ids[n.ID], strings.ReplaceAll(label, `"`, "#quot;"), mermaidClass(class))
+		}
+	}
+	var flagged []string
+	for i, e := range g.sortedEdges() {
+		arrow := "-->"
+		if e.Dashed {
+			arrow = "-.->"
+		}
+		fmt.Fprintf(&b, "  %s %s %s\n", ids[e.From], arrow, ids[e.To])
+		if e.Flagged {
+			flagged = append(flagged, fmt.Sprint(i))
+		}
+	}
+	for _, class := range g.Classes {
+		style := g.Styles[class]
+		var props []string
+		if style.Fill != "" {
+			props = append(props, "fill:"+style.Fill)
+		}
+		if style.Dashed {
+			props = append(props, "stroke-dasharray:4 3")
+		}
+		if len(props) > 0 {
+			fmt.Fprintf(&b, "  classDef %s %s\n", mermaidClass(class), strings.Join(props, ","))
+		}
+	}
+	if len(flagged) > 0 {
+		fmt.Fprintf(&b, "  linkStyle %s stroke:red\n", strings.Join(flagged, ","))
+	}
+	return b.String()
+}
+
+// nodesOf returns the nodes of class sorted by ID
+func (g *Graph) nodesOf(class string) []Node {
+	var nodes []Node
+	for _, n := range g.Nodes {
+		if n.Class == class {
+			nodes = append(nodes, n)
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+	return nodes
+}
+
+// sortedEdges returns the edges ordered by From, then To, so output is stable
+func (g *Graph) sortedEdges() []Edge {
+	edges := append([]Edge(nil), g.Edges...)
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].From != edges[j].From {
+			return edges[i].From < edges[j].From
+		}
+		return edges[i].To < edges[j].To
+	})
+	return edges
+}
+
+// mermaidClass turns a class name into a Mermaid class identifier
+func mermaidClass(class string) string {
+	return strings.NewReplacer("-", "_", " ", "_").Replace(class)
+}
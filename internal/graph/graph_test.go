@@ -0,0 +1,61 @@
+package graph
+
+import (
+	"strings"
+	"testing"
+)
+
+func testGraph() *Graph {
+	g := &Graph{
+		Name:    "brew",
+		Classes: []string{"leaf", "dependency"},
+		Styles: map[string]Style{
+			"leaf":       {Fill: "#c6f6d5", Label: "leaves"},
+			"dependency": {Dashed: true, Label: "dependencies"},
+		},
+	}
+	g.AddNode("python@3.12", "dependency")
+	g.AddNode("git", "leaf")
+	g.AddNode("git", "dependency")
+	g.AddLabeledNode("profile:work", "work", "leaf")
+	g.Edges = []Edge{{From: "git", To: "python@3.12", Flagged: true}, {From: "profile:work", To: "git", Dashed: true}}
+	return g
+}
+
+func TestDOT(t *testing.T) {
+	out := testGraph().DOT()
+	for _, want := range []string{
+		`digraph "brew" {`,
+		`"git" [style="rounded,filled", fillcolor="#c6f6d5"];`,
+		`"python@3.12" [style="rounded,dashed"];`,
+		`"git" -> "python@3.12" [color=red];`,
+		`"profile:work" [label="work", style="rounded,filled", fillcolor="#c6f6d5"];`,
+		`"profile:work" -> "git" [style=dashed];`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("DOT output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestMermaid(t *testing.T) {
+	out := testGraph().Mermaid()
+	for _, want := range []string{
+		"flowchart LR\n",
+		`n0["git"]:::leaf`,
+		`n1["work"]:::leaf`,
+		`n2["python@3.12"]:::dependency`,
+		"n0 --> n2",
+		"n1 -.-> n0",
+		"classDef leaf fill:#c6f6d5",
+		"classDef dependency stroke-dasharray:4 3",
+		"linkStyle 0 stroke:red",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Mermaid output missing %q:\n%s", want, out)
+		}
+	}
+	if _, err := testGraph().Render("svg"); err == nil {
+		t.Error("Render(svg) succeeded, want an unknown format error")
+	}
+}
@@ -0,0 +1,104 @@
+package installer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ildx/merlin/internal/models"
+)
+
+// BrewAudit compares the installed leaf formulae with brew.toml
+type BrewAudit struct {
+	Undeclared     []string         `json:"undeclared"`      // leaves missing from brew.toml: capture or remove them
+	DependencyOnly []DependencyOnly `json:"dependency_only"` // declared formulae other formulae already pull in: candidates to drop
+}
+
+// DependencyOnly is a declared formula that isn't a leaf because installed
+// formulae depend on it
+type DependencyOnly struct {
+	Name       string   `json:"name"`
+	RequiredBy []string `json:"required_by"`
+}
+
+// AuditBrew runs brew leaves, brew list and brew uses through p (nil runs the
+// real brew) and compares them with declared. Declared formulae that aren't
+// installed are left to `merlin diff`.
+func AuditBrew(p Provider, declared *models.BrewConfig) (*BrewAudit, error) {
+	p = providerOrExec(p)
+
+	out, err := p.Query("brew", "leaves")
+	if err != nil {
+		return nil, fmt.Errorf("brew leaves: %w", err)
+	}
+	leaves := strings.Fields(string(out))
+
+	out, err = p.Query("brew", "list", "--formula")
+	if err != nil {
+		return nil, fmt.Errorf("brew list: %w", err)
+	}
+	installed := shortNames(strings.Fields(string(out)))
+	isLeaf := shortNames(leaves)
+
+	audit := &BrewAudit{Undeclared: UndeclaredLeaves(leaves, declared)}
+	if declared == nil {
+		return audit, nil
+	}
+	for _, pkg := range declared.Formulae {
+		short := shortName(pkg.Name)
+		if !installed[short] || isLeaf[short] {
+			continue
+		}
+		users, err := BrewUses(p, pkg.Name)
+		if err != nil {
+			return nil, err
+		}
+		audit.DependencyOnly = append(audit.DependencyOnly, DependencyOnly{Name: pkg.Name, RequiredBy: users})
+	}
+	return audit, nil
+}
+
+// BrewUses returns the installed formulae that depend on formula, sorted,
+// running brew through p (nil runs the real brew)
+func BrewUses(p Provider, formula string) ([]string, error) {
+	out, err := providerOrExec(p).Query("brew", "uses", "--installed", formula)
+	if err != nil {
+		return nil, fmt.Errorf("brew uses %s: %w", formula, err)
+	}
+	users := strings.Fields(string(out))
+	sort.Strings(users)
+	return users, nil
+}
+
+// BrewDescriptions returns the one-line description of each formula, keyed
+// by name, running brew desc through p (nil runs the real brew)
+func BrewDescriptions(p Provider, formulae []string) (map[string]string, error) {
+	descs := make(map[string]string)
+	if len(formulae) == 0 {
+		return descs, nil
+	}
+	out, err := providerOrExec(p).Query("brew", append([]string{"desc", "--formula"}, formulae...)...)
+	if err != nil {
+		return nil, fmt.Errorf("brew desc: %w", err)
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		name, desc, ok := strings.Cut(line, ": ")
+		if ok {
+			descs[strings.TrimSpace(name)] = strings.TrimSpace(desc)
+		}
+	}
+	return descs, nil
+}
+
+// shortName strips the tap from "owner/tap/name"
+func shortName(name string) string {
+	return name[strings.LastIndex(name, "/")+1:]
+}
+
+func shortNames(names []string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[shortName(name)] = true
+	}
+	return set
+}
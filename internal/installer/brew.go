@@ -1,19 +1,23 @@
 package installer
 
 import (
-	"bufio"
 	"fmt"
 	"io"
-	"os/exec"
 	"strings"
+	"time"
 
+	"github.com/ildx/merlin/internal/cli"
 	"github.com/ildx/merlin/internal/models"
+	"github.com/ildx/merlin/internal/progress"
+	"github.com/ildx/merlin/internal/system"
 )
 
 // BrewInstaller handles Homebrew package installation
 type BrewInstaller struct {
-	DryRun  bool
-	Verbose bool
+	DryRun    bool
+	Verbosity cli.Verbosity
+	Retry     RetryPolicy
+	Provider  Provider // Runs brew; nil runs the real binary
 }
 
 // InstallResult represents the result of an installation attempt
@@ -23,30 +27,48 @@ type InstallResult struct {
 	AlreadyExists bool
 	Error         error
 	Output        string
+	Attempts      int           // Number of install attempts made (0 when skipped)
+	Retryable     bool          // Last failure looked like a transient network error
+	Duration      time.Duration // Wall time of the install, including retries
+	PostInstall   string        // post_install command that ran (or, in dry runs, would run)
 }
 
 // NewBrewInstaller creates a new Homebrew installer
-func NewBrewInstaller(dryRun, verbose bool) *BrewInstaller {
+func NewBrewInstaller(dryRun bool, verbosity cli.Verbosity) *BrewInstaller {
 	return &BrewInstaller{
-		DryRun:  dryRun,
-		Verbose: verbose,
+		DryRun:    dryRun,
+		Verbosity: verbosity,
 	}
 }
 
 // IsFormulaInstalled checks if a Homebrew formula is installed
 func (b *BrewInstaller) IsFormulaInstalled(name string) (bool, error) {
-	cmd := exec.Command("brew", "list", "--formula", name)
-	err := cmd.Run()
-	return err == nil, nil
+	return b.isInstalled("", "--formula", name)
 }
 
 // IsCaskInstalled checks if a Homebrew cask is installed
 func (b *BrewInstaller) IsCaskInstalled(name string) (bool, error) {
-	cmd := exec.Command("brew", "list", "--cask", name)
-	err := cmd.Run()
+	return b.isCaskInstalled("", name)
+}
+
+// isInstalled asks the brew for arch (see system.BrewArchCommand) whether
+// it has the formula or cask
+func (b *BrewInstaller) isInstalled(arch, kind, name string) (bool, error) {
+	command, args := system.BrewArchCommand(arch, "list", kind, name)
+	_, err := providerOrExec(b.Provider).Query(command, args...)
 	return err == nil, nil
 }
 
+// isCaskInstalled is isInstalled for casks, also counting casks whose apps
+// are already in the cask_appdir (see CaskInAppdir)
+func (b *BrewInstaller) isCaskInstalled(arch, name string) (bool, error) {
+	installed, err := b.isInstalled(arch, "--cask", name)
+	if err != nil || installed {
+		return installed, err
+	}
+	return CaskInAppdir(b.Provider, name), nil
+}
+
 // InstallFormula installs a single Homebrew formula
 func (b *BrewInstaller) InstallFormula(pkg models.BrewPackage, output io.Writer) *InstallResult {
 	result := &InstallResult{
@@ -55,7 +77,7 @@ func (b *BrewInstaller) InstallFormula(pkg models.BrewPackage, output io.Writer)
 	}
 
 	// Check if already installed
-	installed, err := b.IsFormulaInstalled(pkg.Name)
+	installed, err := b.isInstalled(pkg.Arch, "--formula", pkg.Name)
 	if err != nil {
 		result.Error = fmt.Errorf("failed to check if installed: %w", err)
 		return result
@@ -76,6 +98,7 @@ func (b *BrewInstaller) InstallFormula(pkg models.BrewPackage, output io.Writer)
 			fmt.Fprintf(output, "  [DRY RUN] Would install: %s\n", pkg.Name)
 		}
 		result.Success = true
+		b.postInstall(output, pkg, result)
 		return result
 	}
 
@@ -84,64 +107,15 @@ func (b *BrewInstaller) InstallFormula(pkg models.BrewPackage, output io.Writer)
 		fmt.Fprintf(output, "  📦 Installing %s...\n", pkg.Name)
 	}
 
-	cmd := exec.Command("brew", "install", pkg.Name)
-	
-	// Stream output if verbose
-	if b.Verbose && output != nil {
-		stdout, err := cmd.StdoutPipe()
-		if err != nil {
-			result.Error = err
-			return result
-		}
-		stderr, err := cmd.StderrPipe()
-		if err != nil {
-			result.Error = err
-			return result
-		}
-
-		if err := cmd.Start(); err != nil {
-			result.Error = err
-			return result
-		}
-
-		// Stream stdout
-		go func() {
-			scanner := bufio.NewScanner(stdout)
-			for scanner.Scan() {
-				fmt.Fprintf(output, "     %s\n", scanner.Text())
-			}
-		}()
-
-		// Stream stderr
-		go func() {
-			scanner := bufio.NewScanner(stderr)
-			for scanner.Scan() {
-				fmt.Fprintf(output, "     %s\n", scanner.Text())
-			}
-		}()
-
-		err = cmd.Wait()
-		if err != nil {
-			result.Error = fmt.Errorf("installation failed: %w", err)
-			return result
-		}
-	} else {
-		// Non-verbose: just run and capture output
-		outputBytes, err := cmd.CombinedOutput()
-		result.Output = string(outputBytes)
-		if err != nil {
-			result.Error = fmt.Errorf("installation failed: %w", err)
-			if output != nil {
-				fmt.Fprintf(output, "     Error: %v\n", err)
-			}
-			return result
-		}
+	if !b.install(output, pkg, result, "install", pkg.Name) {
+		return result
 	}
 
 	result.Success = true
 	if output != nil {
 		fmt.Fprintf(output, "  ✓ %s installed successfully\n", pkg.Name)
 	}
+	b.postInstall(output, pkg, result)
 
 	return result
 }
@@ -154,7 +128,7 @@ func (b *BrewInstaller) InstallCask(pkg models.BrewPackage, output io.Writer) *I
 	}
 
 	// Check if already installed
-	installed, err := b.IsCaskInstalled(pkg.Name)
+	installed, err := b.isCaskInstalled(pkg.Arch, pkg.Name)
 	if err != nil {
 		result.Error = fmt.Errorf("failed to check if installed: %w", err)
 		return result
@@ -175,6 +149,7 @@ func (b *BrewInstaller) InstallCask(pkg models.BrewPackage, output io.Writer) *I
 			fmt.Fprintf(output, "  [DRY RUN] Would install: %s\n", pkg.Name)
 		}
 		result.Success = true
+		b.postInstall(output, pkg, result)
 		return result
 	}
 
@@ -183,66 +158,68 @@ func (b *BrewInstaller) InstallCask(pkg models.BrewPackage, output io.Writer) *I
 		fmt.Fprintf(output, "  📱 Installing %s...\n", pkg.Name)
 	}
 
-	cmd := exec.Command("brew", "install", "--cask", pkg.Name)
-	
-	// Stream output if verbose
-	if b.Verbose && output != nil {
-		stdout, err := cmd.StdoutPipe()
-		if err != nil {
-			result.Error = err
-			return result
-		}
-		stderr, err := cmd.StderrPipe()
-		if err != nil {
-			result.Error = err
-			return result
-		}
+	if !b.install(output, pkg, result, caskInstallArgs(pkg.Name)...) {
+		return result
+	}
 
-		if err := cmd.Start(); err != nil {
-			result.Error = err
-			return result
-		}
+	result.Success = true
+	if output != nil {
+		fmt.Fprintf(output, "  ✓ %s installed successfully\n", pkg.Name)
+	}
+	b.postInstall(output, pkg, result)
 
-		// Stream stdout
-		go func() {
-			scanner := bufio.NewScanner(stdout)
-			for scanner.Scan() {
-				fmt.Fprintf(output, "     %s\n", scanner.Text())
-			}
-		}()
+	return result
+}
 
-		// Stream stderr
-		go func() {
-			scanner := bufio.NewScanner(stderr)
-			for scanner.Scan() {
-				fmt.Fprintf(output, "     %s\n", scanner.Text())
-			}
-		}()
+// install runs "brew <args>" through the provider with the retry policy,
+// using the brew for pkg's arch hint
+func (b *BrewInstaller) install(output io.Writer, pkg models.BrewPackage, result *InstallResult, args ...string) bool {
+	echo := b.Verbosity.Stream()
+	command, args := system.BrewArchCommand(pkg.Arch, args...)
+	return runInstallWith(b.Retry, echo, output, pkg.Name, func() (string, error) {
+		return providerOrExec(b.Provider).Install(echo, output, command, args...)
+	}, result)
+}
 
-		err = cmd.Wait()
-		if err != nil {
-			result.Error = fmt.Errorf("installation failed: %w", err)
-			return result
-		}
-	} else {
-		// Non-verbose: just run and capture output
-		outputBytes, err := cmd.CombinedOutput()
-		result.Output = string(outputBytes)
-		if err != nil {
-			result.Error = fmt.Errorf("installation failed: %w", err)
-			if output != nil {
-				fmt.Fprintf(output, "     Error: %v\n", err)
-			}
-			return result
+// postInstall runs pkg's post_install command after a fresh install (dry
+// runs only report it). A failing command fails the result: the package is
+// installed, so the next install skips it and won't run the command again.
+func (b *BrewInstaller) postInstall(output io.Writer, pkg models.BrewPackage, result *InstallResult) {
+	if pkg.PostInstall == "" {
+		return
+	}
+	result.PostInstall = pkg.PostInstall
+	if b.DryRun {
+		if output != nil {
+			fmt.Fprintf(output, "  [DRY RUN] Would run post_install: %s\n", pkg.PostInstall)
 		}
+		return
 	}
 
-	result.Success = true
 	if output != nil {
-		fmt.Fprintf(output, "  ✓ %s installed successfully\n", pkg.Name)
+		fmt.Fprintf(output, "  ⚙  %s post_install: %s\n", pkg.Name, pkg.PostInstall)
+	}
+	out, err := providerOrExec(b.Provider).Install(b.Verbosity.Stream(), output, "sh", "-c", pkg.PostInstall)
+	if err != nil {
+		result.Success = false
+		result.Retryable = false
+		result.Output = out
+		result.Error = fmt.Errorf("installed, but post_install failed (%w); run it by hand: %s", err, pkg.PostInstall)
 	}
+}
 
-	return result
+// reportResult finishes result's package in the progress stream
+func reportResult(phase *progress.Phase, result *InstallResult) {
+	switch {
+	case result.AlreadyExists:
+		phase.Finish(result.Package, progress.StatusSkipped, "already installed")
+	case result.Success:
+		phase.Finish(result.Package, progress.StatusDone, "")
+	case result.Error != nil:
+		phase.Finish(result.Package, progress.StatusFailed, result.Error.Error())
+	default:
+		phase.Finish(result.Package, progress.StatusFailed, "")
+	}
 }
 
 // InstallFormulae installs multiple formulae
@@ -253,10 +230,14 @@ func (b *BrewInstaller) InstallFormulae(packages []models.BrewPackage, output io
 		fmt.Fprintf(output, "\n🔧 Installing %d formulae...\n\n", len(packages))
 	}
 
+	phase := progress.Start("formulae", len(packages))
 	for _, pkg := range packages {
+		phase.Begin(pkg.Name)
 		result := b.InstallFormula(pkg, output)
+		reportResult(phase, result)
 		results = append(results, result)
 	}
+	phase.End()
 
 	return results
 }
@@ -269,10 +250,14 @@ func (b *BrewInstaller) InstallCasks(packages []models.BrewPackage, output io.Wr
 		fmt.Fprintf(output, "\n📱 Installing %d casks...\n\n", len(packages))
 	}
 
+	phase := progress.Start("casks", len(packages))
 	for _, pkg := range packages {
+		phase.Begin(pkg.Name)
 		result := b.InstallCask(pkg, output)
+		reportResult(phase, result)
 		results = append(results, result)
 	}
+	phase.End()
 
 	return results
 }
@@ -370,9 +355,19 @@ func PrintSummary(formulaeResults, caskResults []*InstallResult, output io.Write
 	if len(failures) > 0 {
 		fmt.Fprintf(output, "\n❌ Failed installations:\n")
 		for _, failure := range failures {
-			fmt.Fprintf(output, "   • %s: %v\n", failure.Package, failure.Error)
+			fmt.Fprintf(output, "   • %s: %s\n", failure.Package, DescribeFailure(failure))
 		}
 	}
+	var postInstalls []string
+	for _, result := range append(formulaeResults, caskResults...) {
+		if result.PostInstall != "" && result.Success {
+			postInstalls = append(postInstalls, result.Package)
+		}
+	}
+	if len(postInstalls) > 0 {
+		fmt.Fprintf(output, "\n⚙  post_install: %s\n", strings.Join(postInstalls, ", "))
+	}
+	printInstallTimings(output, formulaeResults, caskResults)
 
 	fmt.Fprintln(output, strings.Repeat("═", 80))
 	fmt.Println()
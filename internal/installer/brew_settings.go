@@ -0,0 +1,122 @@
+package installer
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ildx/merlin/internal/models"
+	"github.com/ildx/merlin/internal/system"
+)
+
+// brewSettings is set once at startup (see ConfigureBrew) and only read afterwards
+var brewSettings models.BrewSettings
+
+// ConfigureBrew applies [settings.brew]. Its HOMEBREW_* variables are exported
+// into merlin's own environment so every brew process merlin starts, including
+// those run by scripts, inherits them; no_quarantine and cask_appdir are
+// applied to cask installs and brew_path selects the brew binary every command
+// runs.
+func ConfigureBrew(settings models.BrewSettings) {
+	brewSettings = settings
+	system.SetBrewPath(settings.BrewPath)
+	for key, value := range settings.Env() {
+		os.Setenv(key, value)
+	}
+}
+
+// CaskAppdir returns the configured cask_appdir with "~" expanded, or "" when
+// casks go to brew's default /Applications
+func CaskAppdir() string {
+	dir := brewSettings.CaskAppdir
+	if dir == "~" || strings.HasPrefix(dir, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			dir = filepath.Join(home, dir[1:])
+		}
+	}
+	return dir
+}
+
+// caskInstallArgs returns the brew arguments installing cask name
+func caskInstallArgs(name string) []string {
+	args := []string{"install", "--cask", name}
+	if brewSettings.NoQuarantine {
+		args = append(args, "--no-quarantine")
+	}
+	if dir := CaskAppdir(); dir != "" {
+		args = append(args, "--appdir="+dir)
+	}
+	return args
+}
+
+// CaskInAppdir reports whether every app cask name ships is present in the
+// configured cask_appdir. It catches casks brew no longer lists as installed
+// although their apps are there, e.g. ones installed by hand or before a
+// reinstall of brew, so they aren't reported missing on every run.
+func CaskInAppdir(p Provider, name string) bool {
+	dir := CaskAppdir()
+	if dir == "" {
+		return false
+	}
+	out, err := providerOrExec(p).Query("brew", "info", "--cask", "--json=v2", name)
+	if err != nil {
+		return false
+	}
+	apps := caskApps(out)
+	if len(apps) == 0 {
+		return false
+	}
+	for _, app := range apps {
+		if _, err := os.Stat(filepath.Join(dir, app)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// caskApps returns the app bundles named by "brew info --cask --json=v2"
+// output, using the target name where the cask renames the app
+func caskApps(data []byte) []string {
+	var info struct {
+		Casks []struct {
+			Artifacts []map[string]json.RawMessage `json:"artifacts"`
+		} `json:"casks"`
+	}
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil
+	}
+	var apps []string
+	for _, cask := range info.Casks {
+		for _, artifact := range cask.Artifacts {
+			raw, ok := artifact["app"]
+			if !ok {
+				continue
+			}
+			var entries []json.RawMessage
+			if err := json.Unmarshal(raw, &entries); err != nil {
+				continue
+			}
+			// ["Foo.app"] or ["Foo.app", {"target": "Bar.app"}]
+			var app string
+			for _, entry := range entries {
+				var source string
+				var opts struct {
+					Target string `json:"target"`
+				}
+				if json.Unmarshal(entry, &source) == nil {
+					if app != "" {
+						apps = append(apps, filepath.Base(app))
+					}
+					app = source
+				} else if json.Unmarshal(entry, &opts) == nil && opts.Target != "" {
+					app = opts.Target
+				}
+			}
+			if app != "" {
+				apps = append(apps, filepath.Base(app))
+			}
+		}
+	}
+	return apps
+}
@@ -0,0 +1,90 @@
+package installer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/ildx/merlin/internal/models"
+)
+
+func TestConfigureBrew(t *testing.T) {
+	t.Setenv("HOMEBREW_NO_ANALYTICS", "")
+	t.Setenv("HOMEBREW_UPGRADE_GREEDY", "")
+	defer func() { brewSettings = models.BrewSettings{} }()
+
+	if args := caskInstallArgs("firefox"); slices.Contains(args, "--no-quarantine") {
+		t.Errorf("--no-quarantine added without the setting: %v", args)
+	}
+
+	analytics := false
+	ConfigureBrew(models.BrewSettings{Analytics: &analytics, NoQuarantine: true, GreedyUpgrades: true})
+
+	if got := os.Getenv("HOMEBREW_NO_ANALYTICS"); got != "1" {
+		t.Errorf("HOMEBREW_NO_ANALYTICS = %q, want 1", got)
+	}
+	if got := os.Getenv("HOMEBREW_UPGRADE_GREEDY"); got != "1" {
+		t.Errorf("HOMEBREW_UPGRADE_GREEDY = %q, want 1", got)
+	}
+	want := []string{"install", "--cask", "firefox", "--no-quarantine"}
+	if args := caskInstallArgs("firefox"); !slices.Equal(args, want) {
+		t.Errorf("caskInstallArgs = %v, want %v", args, want)
+	}
+}
+
+// caskInfoProvider answers "brew info --cask --json=v2" with a canned reply
+// and fails everything else, like a brew that doesn't list the cask
+type caskInfoProvider string
+
+func (p caskInfoProvider) Query(name string, args ...string) ([]byte, error) {
+	if len(args) > 1 && args[0] == "info" {
+		return []byte(p), nil
+	}
+	return nil, fmt.Errorf("exit status 1")
+}
+
+func (caskInfoProvider) Install(bool, io.Writer, string, ...string) (string, error) {
+	return "", fmt.Errorf("unexpected install")
+}
+
+func TestCaskAppdir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	defer func() { brewSettings = models.BrewSettings{} }()
+
+	info := caskInfoProvider(`{"casks":[{"token":"firefox","artifacts":[{"uninstall":[]},{"app":["Firefox.app"]}]},` +
+		`{"token":"renamed","artifacts":[{"app":["Foo.app",{"target":"Bar.app"}]}]}]}`)
+	if CaskInAppdir(info, "firefox") {
+		t.Error("CaskInAppdir without cask_appdir should be false")
+	}
+
+	ConfigureBrew(models.BrewSettings{CaskAppdir: "~/Applications"})
+	appdir := filepath.Join(home, "Applications")
+	want := []string{"install", "--cask", "firefox", "--appdir=" + appdir}
+	if args := caskInstallArgs("firefox"); !slices.Equal(args, want) {
+		t.Errorf("caskInstallArgs = %v, want %v", args, want)
+	}
+
+	if got := caskApps([]byte(info)); !slices.Equal(got, []string{"Firefox.app", "Bar.app"}) {
+		t.Errorf("caskApps = %v", got)
+	}
+	if CaskInAppdir(info, "firefox") {
+		t.Error("CaskInAppdir should be false before the apps exist")
+	}
+	for _, app := range []string{"Firefox.app", "Bar.app"} {
+		if err := os.MkdirAll(filepath.Join(appdir, app), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !CaskInAppdir(info, "firefox") {
+		t.Error("CaskInAppdir should find the apps in the appdir")
+	}
+
+	brew := &BrewInstaller{Provider: info}
+	if installed, _ := brew.IsCaskInstalled("firefox"); !installed {
+		t.Error("IsCaskInstalled should count casks found in the appdir")
+	}
+}
@@ -0,0 +1,143 @@
+package installer
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ildx/merlin/internal/models"
+	"github.com/ildx/merlin/internal/system"
+)
+
+// BrewCleanupPlan describes what `merlin clean brew` would remove
+type BrewCleanupPlan struct {
+	Autoremove  []string // Formulae only installed as dependencies and no longer needed
+	Reclaimable int64    // Bytes `brew cleanup` reports it would free
+	Undeclared  []string // Leaf formulae not declared in brew.toml (only with leaves)
+}
+
+var freedPattern = regexp.MustCompile(`(?i)approximately\s+([0-9.]+)\s*([KMGT]?B)`)
+
+// PlanBrewCleanup previews brew autoremove and brew cleanup. When leaves is set,
+// installed leaf formulae missing from declared are listed for removal as well.
+func PlanBrewCleanup(declared *models.BrewConfig, leaves bool) (*BrewCleanupPlan, error) {
+	plan := &BrewCleanupPlan{}
+
+	out, err := exec.Command(system.BrewPath(), "autoremove", "--dry-run").CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("brew autoremove --dry-run: %w\n%s", err, out)
+	}
+	plan.Autoremove = ParseAutoremoveOutput(string(out))
+
+	out, err = exec.Command(system.BrewPath(), "cleanup", "--dry-run").CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("brew cleanup --dry-run: %w\n%s", err, out)
+	}
+	plan.Reclaimable = ParseFreedSpace(string(out))
+
+	if leaves {
+		out, err = exec.Command(system.BrewPath(), "leaves").Output()
+		if err != nil {
+			return nil, fmt.Errorf("brew leaves: %w", err)
+		}
+		plan.Undeclared = UndeclaredLeaves(strings.Fields(string(out)), declared)
+	}
+
+	return plan, nil
+}
+
+// ApplyBrewCleanup uninstalls undeclared leaves (when present in plan), then runs
+// brew autoremove and brew cleanup. It returns the space brew cleanup reports freed.
+func ApplyBrewCleanup(plan *BrewCleanupPlan, verbose bool, output io.Writer) (int64, error) {
+	if len(plan.Undeclared) > 0 {
+		fmt.Fprintf(output, "  🗑  Uninstalling %d undeclared leaves...\n", len(plan.Undeclared))
+		args := append([]string{"uninstall", "--formula"}, plan.Undeclared...)
+		out, err := exec.Command(system.BrewPath(), args...).CombinedOutput()
+		if err != nil {
+			return 0, fmt.Errorf("brew uninstall: %w\n%s", err, out)
+		}
+		if verbose {
+			fmt.Fprint(output, string(out))
+		}
+	}
+
+	// Autoremove after uninstalling leaves so their dependencies go too
+	fmt.Fprintln(output, "  🧹 Running brew autoremove...")
+	out, err := exec.Command(system.BrewPath(), "autoremove").CombinedOutput()
+	if err != nil {
+		return 0, fmt.Errorf("brew autoremove: %w\n%s", err, out)
+	}
+	if verbose {
+		fmt.Fprint(output, string(out))
+	}
+
+	fmt.Fprintln(output, "  🧹 Running brew cleanup...")
+	out, err = exec.Command(system.BrewPath(), "cleanup").CombinedOutput()
+	if err != nil {
+		return 0, fmt.Errorf("brew cleanup: %w\n%s", err, out)
+	}
+	if verbose {
+		fmt.Fprint(output, string(out))
+	}
+	return ParseFreedSpace(string(out)), nil
+}
+
+// ParseAutoremoveOutput extracts formula names from `brew autoremove --dry-run`
+func ParseAutoremoveOutput(out string) []string {
+	var names []string
+	inList := false
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "==>") {
+			inList = strings.Contains(line, "autoremove")
+			continue
+		}
+		if inList && line != "" {
+			names = append(names, line)
+		}
+	}
+	return names
+}
+
+// ParseFreedSpace reads the "approximately 1.2GB" figure printed by brew cleanup
+func ParseFreedSpace(out string) int64 {
+	m := freedPattern.FindStringSubmatch(out)
+	if m == nil {
+		return 0
+	}
+	value, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0
+	}
+	multiplier := map[string]float64{
+		"B":  1,
+		"KB": 1 << 10,
+		"MB": 1 << 20,
+		"GB": 1 << 30,
+		"TB": 1 << 40,
+	}[strings.ToUpper(m[2])]
+	return int64(value * multiplier)
+}
+
+// UndeclaredLeaves returns the leaves not listed as formulae in declared. Tapped
+// leaves ("owner/tap/name") match declarations by full or short name.
+func UndeclaredLeaves(leaves []string, declared *models.BrewConfig) []string {
+	known := make(map[string]bool)
+	if declared != nil {
+		for _, pkg := range declared.Formulae {
+			known[pkg.Name] = true
+		}
+	}
+
+	var undeclared []string
+	for _, leaf := range leaves {
+		short := leaf[strings.LastIndex(leaf, "/")+1:]
+		if !known[leaf] && !known[short] {
+			undeclared = append(undeclared, leaf)
+		}
+	}
+	return undeclared
+}
@@ -0,0 +1,45 @@
+package installer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ildx/merlin/internal/models"
+)
+
+func TestParseAutoremoveOutput(t *testing.T) {
+	out := "==> Would autoremove 2 unneeded formulae:\nlibyaml\nm4\n"
+	if got, want := ParseAutoremoveOutput(out), []string{"libyaml", "m4"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseAutoremoveOutput() = %v, want %v", got, want)
+	}
+	if got := ParseAutoremoveOutput(""); len(got) != 0 {
+		t.Errorf("expected nothing to autoremove, got %v", got)
+	}
+}
+
+func TestParseFreedSpace(t *testing.T) {
+	tests := []struct {
+		output string
+		want   int64
+	}{
+		{"Would remove: /x (1.1MB)\n==> This operation would free approximately 1.5GB of disk space.", 3 << 29},
+		{"==> This operation has freed approximately 512KB of disk space.", 512 << 10},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if got := ParseFreedSpace(tt.output); got != tt.want {
+			t.Errorf("ParseFreedSpace(%q) = %d, want %d", tt.output, got, tt.want)
+		}
+	}
+}
+
+func TestUndeclaredLeaves(t *testing.T) {
+	declared := &models.BrewConfig{
+		Formulae: []models.BrewPackage{{Name: "git"}, {Name: "zellij"}},
+	}
+	leaves := []string{"git", "htop", "homebrew/core/zellij", "owner/tap/tool"}
+	want := []string{"htop", "owner/tap/tool"}
+	if got := UndeclaredLeaves(leaves, declared); !reflect.DeepEqual(got, want) {
+		t.Errorf("UndeclaredLeaves() = %v, want %v", got, want)
+	}
+}
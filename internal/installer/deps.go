@@ -0,0 +1,107 @@
+package installer
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ildx/merlin/internal/graph"
+	"github.com/ildx/merlin/internal/models"
+)
+
+// Node classes of BrewGraph
+const (
+	NodeLeaf         = "leaf"                // declared; no other formula in the graph needs it
+	NodeDeclaredDep  = "declared-dependency" // declared, but another formula pulls it in: a candidate to drop
+	NodeDependency   = "dependency"          // pulled in, not declared
+	NodeNotInstalled = "not-installed"       // declared but not installed, so its dependencies are unknown
+)
+
+// BrewDependencies returns the direct dependencies of every installed
+// formula, keyed by short name, from "brew info --json=v2 --installed" run
+// through p (nil runs the real brew)
+func BrewDependencies(p Provider) (map[string][]string, error) {
+	out, err := providerOrExec(p).Query("brew", "info", "--json=v2", "--installed")
+	if err != nil {
+		return nil, fmt.Errorf("brew info --installed: %w", err)
+	}
+	var info struct {
+		Formulae []struct {
+			Name         string   `json:"name"`
+			Dependencies []string `json:"dependencies"`
+		} `json:"formulae"`
+	}
+	if err := json.Unmarshal(out, &info); err != nil {
+		return nil, fmt.Errorf("parse brew info: %w", err)
+	}
+	deps := make(map[string][]string, len(info.Formulae))
+	for _, f := range info.Formulae {
+		short := make([]string, len(f.Dependencies))
+		for i, dep := range f.Dependencies {
+			short[i] = shortName(dep)
+		}
+		deps[shortName(f.Name)] = short
+	}
+	return deps, nil
+}
+
+// BrewGraph returns the dependency graph of the formulae declared (casks
+// have no formula dependencies): every declared formula and what it pulls
+// in, transitively. See the Node constants for how formulae are classed.
+func BrewGraph(p Provider, declared *models.BrewConfig) (*graph.Graph, error) {
+	deps, err := BrewDependencies(p)
+	if err != nil {
+		return nil, err
+	}
+
+	isDeclared := make(map[string]bool)
+	var queue []string
+	if declared != nil {
+		for _, pkg := range declared.Formulae {
+			name := shortName(pkg.Name)
+			if !isDeclared[name] {
+				isDeclared[name] = true
+				queue = append(queue, name)
+			}
+		}
+	}
+
+	g := &graph.Graph{
+		Name:    "brew",
+		Classes: []string{NodeLeaf, NodeDeclaredDep, NodeDependency, NodeNotInstalled},
+		Styles: map[string]graph.Style{
+			NodeLeaf:         {Fill: "#c6f6d5", Label: "declared leaves"},
+			NodeDeclaredDep:  {Fill: "#fefcbf", Label: "declared, but pulled in by another formula"},
+			NodeDependency:   {Label: "dependencies not declared"},
+			NodeNotInstalled: {Dashed: true, Label: "declared, not installed"},
+		},
+	}
+	needed := make(map[string]bool)
+	visited := make(map[string]bool)
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if visited[name] {
+			continue
+		}
+		visited[name] = true
+		for _, dep := range deps[name] {
+			g.Edges = append(g.Edges, graph.Edge{From: name, To: dep})
+			needed[dep] = true
+			queue = append(queue, dep)
+		}
+	}
+	for name := range visited {
+		_, installed := deps[name]
+		switch {
+		case isDeclared[name] && !installed:
+			g.AddNode(name, NodeNotInstalled)
+		case isDeclared[name] && needed[name]:
+			g.AddNode(name, NodeDeclaredDep)
+		case isDeclared[name]:
+			g.AddNode(name, NodeLeaf)
+		default:
+			g.AddNode(name, NodeDependency)
+		}
+	}
+	return g, nil
+}
@@ -0,0 +1,181 @@
+package installer
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/ildx/merlin/internal/cli"
+	"github.com/ildx/merlin/internal/models"
+)
+
+// ExtensionInstaller installs editor extensions through the editor CLI
+// (`code` for VS Code, `cursor` for Cursor)
+type ExtensionInstaller struct {
+	DryRun    bool
+	Verbosity cli.Verbosity
+	Retry     RetryPolicy
+
+	installed map[string]map[string]bool // editor → lowercased ids, listed once per run
+}
+
+// NewExtensionInstaller creates a new editor extension installer
+func NewExtensionInstaller(dryRun bool, verbosity cli.Verbosity) *ExtensionInstaller {
+	return &ExtensionInstaller{
+		DryRun:    dryRun,
+		Verbosity: verbosity,
+		installed: make(map[string]map[string]bool),
+	}
+}
+
+// listExtensions runs `<editor> --list-extensions`; replaced in tests
+var listExtensions = func(editor string) ([]byte, error) {
+	if _, err := exec.LookPath(editor); err != nil {
+		return nil, fmt.Errorf("%s CLI not found in PATH (install it from the editor's command palette)", editor)
+	}
+	out, err := exec.Command(editor, "--list-extensions").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s extensions: %w", editor, err)
+	}
+	return out, nil
+}
+
+// InstalledExtensions returns the extensions an editor reports, keyed by
+// lowercased id since marketplace ids are case-insensitive
+func InstalledExtensions(editor string) (map[string]bool, error) {
+	out, err := listExtensions(editor)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]bool)
+	for _, line := range strings.Split(string(out), "\n") {
+		// Newer CLIs may append @version with --show-versions; plain output is just the id
+		id, _, _ := strings.Cut(strings.TrimSpace(line), "@")
+		if id != "" {
+			ids[strings.ToLower(id)] = true
+		}
+	}
+	return ids, nil
+}
+
+// IsExtensionInstalled checks an extension against the editor's list, which is
+// fetched on first use and reused for the rest of the run
+func (e *ExtensionInstaller) IsExtensionInstalled(ext models.Extension) (bool, error) {
+	ids, ok := e.installed[ext.Editor]
+	if !ok {
+		var err error
+		if ids, err = InstalledExtensions(ext.Editor); err != nil {
+			return false, err
+		}
+		e.installed[ext.Editor] = ids
+	}
+	return ids[strings.ToLower(ext.ID)], nil
+}
+
+// InstallExtension installs a single extension unless it is already present
+func (e *ExtensionInstaller) InstallExtension(ext models.Extension, output io.Writer) *InstallResult {
+	name := fmt.Sprintf("%s (%s)", ext.ID, ext.Editor)
+	result := &InstallResult{
+		Package: name,
+		Success: false,
+	}
+
+	installed, err := e.IsExtensionInstalled(ext)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+
+	if installed {
+		result.AlreadyExists = true
+		result.Success = true
+		if output != nil {
+			fmt.Fprintf(output, "  ⏭  %s (already installed)\n", name)
+		}
+		return result
+	}
+
+	// Dry run mode
+	if e.DryRun {
+		if output != nil {
+			fmt.Fprintf(output, "  [DRY RUN] Would install: %s\n", name)
+		}
+		result.Success = true
+		return result
+	}
+
+	if output != nil {
+		fmt.Fprintf(output, "  🧩 Installing %s...\n", name)
+	}
+
+	if !runInstall(e.Retry, e.Verbosity.Stream(), output, name, func() *exec.Cmd {
+		return exec.Command(ext.Editor, "--install-extension", ext.ID)
+	}, result) {
+		return result
+	}
+
+	e.installed[ext.Editor][strings.ToLower(ext.ID)] = true
+	result.Success = true
+	if output != nil {
+		fmt.Fprintf(output, "  ✓ %s installed successfully\n", name)
+	}
+
+	return result
+}
+
+// InstallExtensions installs multiple extensions
+func (e *ExtensionInstaller) InstallExtensions(extensions []models.Extension, output io.Writer) []*InstallResult {
+	results := make([]*InstallResult, 0, len(extensions))
+
+	if output != nil {
+		fmt.Fprintf(output, "\n🧩 Installing %d editor extension(s)...\n\n", len(extensions))
+	}
+
+	for _, ext := range extensions {
+		results = append(results, e.InstallExtension(ext, output))
+	}
+
+	return results
+}
+
+// PrintExtensionSummary prints a summary of extension installation results
+func PrintExtensionSummary(results []*InstallResult, output io.Writer) {
+	if len(results) == 0 {
+		return
+	}
+
+	successCount := 0
+	alreadyInstalledCount := 0
+	var failures []*InstallResult
+	for _, result := range results {
+		switch {
+		case result.AlreadyExists:
+			alreadyInstalledCount++
+		case result.Success:
+			successCount++
+		default:
+			failures = append(failures, result)
+		}
+	}
+
+	fmt.Fprintf(output, "\n")
+	fmt.Fprintln(output, strings.Repeat("═", 80))
+	fmt.Fprintf(output, "Editor Extension Installation Summary\n")
+	fmt.Fprintln(output, strings.Repeat("═", 80))
+
+	fmt.Fprintf(output, "\n🧩 Extensions (%d total):\n", len(results))
+	fmt.Fprintf(output, "   ✓ %d installed\n", successCount)
+	fmt.Fprintf(output, "   ⏭  %d already installed\n", alreadyInstalledCount)
+	if len(failures) > 0 {
+		fmt.Fprintf(output, "   ✗ %d failed\n", len(failures))
+		fmt.Fprintf(output, "\n❌ Failed installations:\n")
+		for _, failure := range failures {
+			fmt.Fprintf(output, "   • %s: %s\n", failure.Package, DescribeFailure(failure))
+		}
+	}
+	printInstallTimings(output, results)
+
+	fmt.Fprintln(output, strings.Repeat("═", 80))
+	fmt.Fprintln(output)
+}
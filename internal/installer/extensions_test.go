@@ -0,0 +1,67 @@
+package installer
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ildx/merlin/internal/models"
+)
+
+func stubListExtensions(t *testing.T, lists map[string]string) *int {
+	t.Helper()
+	calls := 0
+	orig := listExtensions
+	listExtensions = func(editor string) ([]byte, error) {
+		calls++
+		out, ok := lists[editor]
+		if !ok {
+			return nil, errors.New(editor + " CLI not found in PATH")
+		}
+		return []byte(out), nil
+	}
+	t.Cleanup(func() { listExtensions = orig })
+	return &calls
+}
+
+func TestInstalledExtensions(t *testing.T) {
+	stubListExtensions(t, map[string]string{"code": "Golang.Go\nesbenp.prettier-vscode@10.1.0\n\n"})
+
+	ids, err := InstalledExtensions("code")
+	if err != nil {
+		t.Fatalf("InstalledExtensions() error = %v", err)
+	}
+	if len(ids) != 2 || !ids["golang.go"] || !ids["esbenp.prettier-vscode"] {
+		t.Errorf("InstalledExtensions() = %v", ids)
+	}
+	if _, err := InstalledExtensions("cursor"); err == nil {
+		t.Error("expected error for editor without CLI")
+	}
+}
+
+func TestInstallExtensionsDryRun(t *testing.T) {
+	calls := stubListExtensions(t, map[string]string{"cursor": "golang.go\n"})
+
+	inst := NewExtensionInstaller(true, 0)
+	var out bytes.Buffer
+	results := inst.InstallExtensions([]models.Extension{
+		{Editor: models.EditorCursor, ID: "golang.Go"},
+		{Editor: models.EditorCursor, ID: "esbenp.prettier-vscode"},
+		{Editor: models.EditorCode, ID: "golang.go"},
+	}, &out)
+
+	if !results[0].AlreadyExists {
+		t.Error("golang.Go should match the installed id case-insensitively")
+	}
+	if !results[1].Success || results[1].AlreadyExists || !strings.Contains(out.String(), "Would install: esbenp.prettier-vscode (cursor)") {
+		t.Errorf("prettier should be a dry-run install, got %+v\n%s", results[1], out.String())
+	}
+	if results[2].Success || results[2].Error == nil {
+		t.Error("missing code CLI should fail the code extension")
+	}
+	// cursor listed once, code attempted once
+	if *calls != 2 {
+		t.Errorf("listExtensions called %d times, want 2", *calls)
+	}
+}
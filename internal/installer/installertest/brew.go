@@ -0,0 +1,195 @@
+package installertest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Brew is an in-memory Homebrew. It answers "brew list", "brew leaves",
+// "brew uses --installed" and "brew info --json=v2 --installed" from its installed sets and dependency graph, and
+// "brew install" adds to them, unless a failure is queued with Fail.
+type Brew struct {
+	recorder
+	formulae map[string]bool
+	casks    map[string]bool
+	deps     map[string][]string
+}
+
+// NewBrew returns a fake Homebrew with the given formulae already installed
+func NewBrew(formulae ...string) *Brew {
+	b := &Brew{formulae: make(map[string]bool), casks: make(map[string]bool), deps: make(map[string][]string)}
+	for _, name := range formulae {
+		b.formulae[name] = true
+	}
+	return b
+}
+
+// AddCask marks casks as installed
+func (b *Brew) AddCask(names ...string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, name := range names {
+		b.casks[name] = true
+	}
+}
+
+// SetDeps declares the formulae formula depends on. Dependencies are not
+// installed automatically; add them to NewBrew as well.
+func (b *Brew) SetDeps(formula string, deps ...string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.deps[formula] = deps
+}
+
+// HasFormula reports whether formula name is installed
+func (b *Brew) HasFormula(name string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.formulae[name]
+}
+
+// HasCask reports whether cask name is installed
+func (b *Brew) HasCask(name string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.casks[name]
+}
+
+// Query answers "brew list --formula|--cask [name]", "brew leaves",
+// "brew uses --installed <name>" and "brew info --json=v2 --installed"
+func (b *Brew) Query(name string, args ...string) ([]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.record(name, args)
+
+	switch {
+	case name == "brew" && len(args) == 1 && args[0] == "leaves":
+		return b.leaves(), nil
+	case name == "brew" && len(args) == 3 && args[0] == "uses" && args[1] == "--installed":
+		return b.uses(args[2]), nil
+	case name == "brew" && strings.Join(args, " ") == "info --json=v2 --installed":
+		return b.info()
+	case name != "brew" || len(args) < 2 || args[0] != "list":
+		return nil, fmt.Errorf("installertest: unsupported command %s %s", name, strings.Join(args, " "))
+	}
+	set := b.formulae
+	if args[1] == "--cask" {
+		set = b.casks
+	}
+	if len(args) > 2 {
+		if !set[args[2]] {
+			return nil, errExit
+		}
+		return []byte(args[2] + "\n"), nil
+	}
+	return lines(set), nil
+}
+
+// leaves lists installed formulae no other installed formula depends on
+func (b *Brew) leaves() []byte {
+	leaves := make(map[string]bool)
+	for formula := range b.formulae {
+		leaves[formula] = true
+	}
+	for formula := range b.formulae {
+		for _, dep := range b.deps[formula] {
+			delete(leaves, dep)
+		}
+	}
+	return lines(leaves)
+}
+
+// uses lists installed formulae that depend on formula
+func (b *Brew) uses(formula string) []byte {
+	users := make(map[string]bool)
+	for f := range b.formulae {
+		for _, dep := range b.deps[f] {
+			if dep == formula {
+				users[f] = true
+			}
+		}
+	}
+	return lines(users)
+}
+
+// info renders the installed formulae and their dependencies as
+// "brew info --json=v2" does
+func (b *Brew) info() ([]byte, error) {
+	type formula struct {
+		Name         string   `json:"name"`
+		Dependencies []string `json:"dependencies"`
+	}
+	info := struct {
+		Formulae []formula `json:"formulae"`
+		Casks    []any     `json:"casks"`
+	}{Casks: []any{}}
+	for _, name := range strings.Split(string(lines(b.formulae)), "\n") {
+		if name != "" {
+			info.Formulae = append(info.Formulae, formula{Name: name, Dependencies: append([]string{}, b.deps[name]...)})
+		}
+	}
+	return json.Marshal(info)
+}
+
+// lines renders a set one name per line, sorted
+func lines(set map[string]bool) []byte {
+	names := make([]string, 0, len(set))
+	for n := range set {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return []byte(strings.Join(names, "\n"))
+}
+
+// Install handles "brew install [--cask] name [flags]" and the "sh -c
+// command" of post_install hooks, which succeed unless a failure is queued
+// for the command
+func (b *Brew) Install(echo bool, w io.Writer, name string, args ...string) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.record(name, args)
+
+	if name == "sh" && len(args) == 2 && args[0] == "-c" {
+		if failure, ok := b.nextFailure(args[1]); ok {
+			echoOutput(echo, w, failure+"\n")
+			return failure + "\n", errExit
+		}
+		return "", nil
+	}
+
+	if name != "brew" || len(args) < 2 || args[0] != "install" {
+		return "", fmt.Errorf("installertest: unsupported command %s %s", name, strings.Join(args, " "))
+	}
+	set, pkg := b.formulae, ""
+	for _, arg := range args[1:] {
+		switch {
+		case arg == "--cask":
+			set = b.casks
+		case !strings.HasPrefix(arg, "-"):
+			pkg = arg
+		}
+	}
+
+	out := "==> Installing " + pkg + "\n"
+	if failure, ok := b.nextFailure(pkg); ok {
+		out = failure + "\n"
+		echoOutput(echo, w, out)
+		return out, errExit
+	}
+	set[pkg] = true
+	echoOutput(echo, w, out)
+	return out, nil
+}
+
+// echoOutput streams out to w the way installer.ExecProvider does when echo is set
+func echoOutput(echo bool, w io.Writer, out string) {
+	if !echo || w == nil {
+		return
+	}
+	for _, line := range strings.Split(strings.TrimRight(out, "\n"), "\n") {
+		fmt.Fprintf(w, "     %s\n", line)
+	}
+}
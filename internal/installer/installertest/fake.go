@@ -0,0 +1,57 @@
+// Package installertest provides in-memory package managers implementing
+// installer.Provider, an in-memory filesystem for the symlink engine (MemFS)
+// and a throwaway home/dotfiles sandbox with git helpers, so installers,
+// integrations, plugins and merlin's own commands can be tested without
+// touching the real system.
+package installertest
+
+import (
+	"errors"
+	"strings"
+	"sync"
+)
+
+// errExit is what a failed fake command returns, like a non-zero exit status
+var errExit = errors.New("exit status 1")
+
+// recorder is the bookkeeping shared by the fakes: the commands they were
+// asked to run and the failures queued for upcoming installs
+type recorder struct {
+	mu       sync.Mutex
+	calls    []string
+	failures map[string][]string
+}
+
+// Fail queues failures for name: its next len(outputs) installs fail, each
+// printing the corresponding output. Outputs matching installer.IsRetryable
+// (e.g. "curl: (6) Could not resolve host") are retried by the installers.
+func (r *recorder) Fail(name string, outputs ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failures == nil {
+		r.failures = make(map[string][]string)
+	}
+	r.failures[name] = append(r.failures[name], outputs...)
+}
+
+// Calls returns the commands run so far, e.g. "brew install --cask foo"
+func (r *recorder) Calls() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.calls...)
+}
+
+// record logs a command; the caller holds r.mu
+func (r *recorder) record(name string, args []string) {
+	r.calls = append(r.calls, strings.Join(append([]string{name}, args...), " "))
+}
+
+// nextFailure pops the queued failure for name, if any; the caller holds r.mu
+func (r *recorder) nextFailure(name string) (string, bool) {
+	queued := r.failures[name]
+	if len(queued) == 0 {
+		return "", false
+	}
+	r.failures[name] = queued[1:]
+	return queued[0], true
+}
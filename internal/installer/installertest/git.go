@@ -0,0 +1,49 @@
+package installertest
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// InitGit makes the sandbox repository a git repository with a test identity
+// and commits its current content as "chore: init", so auto-commits can be
+// checked with LastCommit and GitStatus. The test is skipped without git.
+func (s *Sandbox) InitGit() {
+	s.t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		s.t.Skip("git not available")
+	}
+	s.git("init")
+	s.git("config", "user.email", "tester@example.com")
+	s.git("config", "user.name", "Tester")
+	s.git("add", ".")
+	s.git("commit", "-m", "chore: init")
+}
+
+// LastCommit returns the message of the latest commit
+func (s *Sandbox) LastCommit() string {
+	s.t.Helper()
+	return s.git("log", "-1", "--pretty=%B")
+}
+
+// LastCommitSubject returns the first line of the latest commit message
+func (s *Sandbox) LastCommitSubject() string {
+	s.t.Helper()
+	return s.git("log", "-1", "--pretty=%s")
+}
+
+// GitStatus returns "git status --porcelain" of the repository; empty when clean
+func (s *Sandbox) GitStatus() string {
+	s.t.Helper()
+	return s.git("status", "--porcelain")
+}
+
+// git runs git in the repository and returns its trimmed output
+func (s *Sandbox) git(args ...string) string {
+	s.t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", s.Repo.Root}, args...)...).CombinedOutput()
+	if err != nil {
+		s.t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,209 @@
+package installertest
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ildx/merlin/internal/installer"
+	"github.com/ildx/merlin/internal/models"
+	"github.com/ildx/merlin/internal/symlink"
+)
+
+func TestBrewInstaller(t *testing.T) {
+	brew := NewBrew("git")
+	brew.Fail("ripgrep", `Error: No available formula with the name "ripgrep"`)
+
+	b := installer.NewBrewInstaller(false, 0)
+	b.Provider = brew
+
+	var out bytes.Buffer
+	results := b.InstallFormulae([]models.BrewPackage{{Name: "git"}, {Name: "bat"}, {Name: "ripgrep"}}, &out)
+	if !results[0].AlreadyExists || !results[1].Success || results[2].Success {
+		t.Fatalf("results = %+v %+v %+v", results[0], results[1], results[2])
+	}
+	if !brew.HasFormula("bat") || brew.HasFormula("ripgrep") {
+		t.Errorf("installed formulae not tracked: bat=%v ripgrep=%v", brew.HasFormula("bat"), brew.HasFormula("ripgrep"))
+	}
+
+	if r := b.InstallCask(models.BrewPackage{Name: "ghostty"}, nil); !r.Success || !brew.HasCask("ghostty") {
+		t.Errorf("cask install = %+v, installed = %v", r, brew.HasCask("ghostty"))
+	}
+	calls := brew.Calls()
+	if last := calls[len(calls)-1]; !strings.HasPrefix(last, "brew install --cask ghostty") {
+		t.Errorf("last call = %q", last)
+	}
+}
+
+func TestBrewInstallerRetries(t *testing.T) {
+	brew := NewBrew()
+	brew.Fail("bat", "curl: (6) Could not resolve host: ghcr.io")
+
+	b := installer.NewBrewInstaller(false, 0)
+	b.Provider = brew
+	b.Retry = installer.RetryPolicy{Retries: 1, Backoff: 1}
+
+	r := b.InstallFormula(models.BrewPackage{Name: "bat"}, nil)
+	if !r.Success || r.Attempts != 2 {
+		t.Errorf("result = %+v, want success on the second attempt", r)
+	}
+}
+
+func TestBrewInstallerPostInstall(t *testing.T) {
+	brew := NewBrew("git")
+	brew.Fail("broken-hook", "hook exploded")
+
+	b := installer.NewBrewInstaller(false, 0)
+	b.Provider = brew
+
+	fzf := models.BrewPackage{Name: "fzf", PostInstall: "$(brew --prefix)/opt/fzf/install --all"}
+	if r := b.InstallFormula(fzf, nil); !r.Success || r.PostInstall != fzf.PostInstall {
+		t.Errorf("fzf = %+v, want success with post_install recorded", r)
+	}
+	calls := brew.Calls()
+	if last := calls[len(calls)-1]; last != "sh -c "+fzf.PostInstall {
+		t.Errorf("last call = %q, want the post_install command", last)
+	}
+
+	// Already installed: the hook doesn't run again
+	if r := b.InstallFormula(models.BrewPackage{Name: "git", PostInstall: "echo git"}, nil); !r.AlreadyExists || r.PostInstall != "" {
+		t.Errorf("git = %+v, want no post_install", r)
+	}
+
+	if r := b.InstallCask(models.BrewPackage{Name: "kitty", PostInstall: "broken-hook"}, nil); r.Success || !brew.HasCask("kitty") || !strings.Contains(r.Error.Error(), "post_install") {
+		t.Errorf("kitty = %+v, want an installed cask with a failed post_install", r)
+	}
+
+	b.DryRun = true
+	before := len(brew.Calls())
+	if r := b.InstallFormula(models.BrewPackage{Name: "bat", PostInstall: "echo bat"}, nil); !r.Success || r.PostInstall != "echo bat" || len(brew.Calls()) != before+1 {
+		t.Errorf("dry run = %+v, calls = %v; want the hook reported but not run", r, brew.Calls()[before:])
+	}
+}
+
+func TestMASInstaller(t *testing.T) {
+	mas := NewMAS("me@example.com")
+	mas.AddApp(497799835, "Xcode")
+
+	m := installer.NewMASInstaller(false, 0)
+	m.Provider = mas
+
+	if ok, account, _ := m.CheckMASAccount(); !ok || account != "me@example.com" {
+		t.Errorf("CheckMASAccount() = %v, %q", ok, account)
+	}
+	results := m.InstallApps([]models.MASApp{{Name: "Xcode", ID: 497799835}, {Name: "Things", ID: 904280696}}, nil)
+	if !results[0].AlreadyExists || !results[1].Success || !mas.HasApp(904280696) {
+		t.Errorf("results = %+v %+v", results[0], results[1])
+	}
+
+	m.Provider = NewMAS("")
+	if ok, _, _ := m.CheckMASAccount(); ok {
+		t.Error("signed-out fake reported an account")
+	}
+}
+
+func TestSandboxLink(t *testing.T) {
+	s := NewSandbox(t)
+	s.AddTool("bat", map[string]string{"config": "--theme=ansi\n"})
+
+	s.Link("bat")
+	want := filepath.Join(s.Repo.ConfigDir, "bat", "config")
+	if got := s.LinkTarget(".config/bat"); got != want {
+		t.Errorf(".config/bat -> %q, want %q", got, want)
+	}
+}
+
+func TestMemSandboxLink(t *testing.T) {
+	s := NewMemSandbox(t)
+	zshrc := s.WriteFile("config/zsh/.zshrc", "export EDITOR=vi\n")
+	aliases := s.WriteFile("config/zsh/conf.d/aliases.zsh", "alias ll='ls -l'\n")
+	s.WriteHomeFile(".zprofile", "# hand-written\n")
+	tool := &symlink.ToolConfig{Name: "zsh", Links: []symlink.ResolvedLink{
+		{Source: zshrc, Target: filepath.Join(s.Home, ".zshrc")},
+		{Source: filepath.Dir(aliases), Target: filepath.Join(s.Home, ".config", "zsh"), IsDir: true, Contents: true},
+		{Source: zshrc, Target: filepath.Join(s.Home, ".zprofile")},
+	}}
+
+	results, _ := symlink.LinkToolWithStrategy(tool, symlink.StrategySkip, false)
+	want := []symlink.LinkStatus{symlink.LinkStatusSuccess, symlink.LinkStatusSuccess, symlink.LinkStatusSkipped}
+	for i, r := range results {
+		if r.Status != want[i] {
+			t.Errorf("%s: status %s, want %s (%s)", r.Target, r.Status, want[i], r.Message)
+		}
+	}
+	if got := s.LinkTarget(".config/zsh/aliases.zsh"); got != aliases {
+		t.Errorf(".config/zsh/aliases.zsh -> %q, want %q", got, aliases)
+	}
+	if conflicts := symlink.FindConflicts([]*symlink.ToolConfig{tool}); len(conflicts) != 1 || conflicts[0].Existing != "file" {
+		t.Errorf("FindConflicts() = %+v, want .zprofile as a file", conflicts)
+	}
+	if _, err := os.Lstat(filepath.Join(s.Home, ".zshrc")); !os.IsNotExist(err) {
+		t.Errorf("link was created on disk: %v", err)
+	}
+
+	symlink.UnlinkTool(tool, false)
+	if s.Exists(filepath.Join(s.Home, ".zshrc")) || !s.Exists(filepath.Join(s.Home, ".zprofile")) {
+		t.Error("unlink should remove .zshrc and keep the unmanaged .zprofile")
+	}
+}
+
+func TestAuditBrew(t *testing.T) {
+	brew := NewBrew("bat", "git", "pcre2", "ripgrep", "neovim", "luajit")
+	brew.SetDeps("git", "pcre2")
+	brew.SetDeps("ripgrep", "pcre2")
+	brew.SetDeps("neovim", "luajit")
+
+	declared := &models.BrewConfig{Formulae: []models.BrewPackage{
+		{Name: "git"}, {Name: "pcre2"}, {Name: "neovim"}, {Name: "missing"},
+	}}
+	audit, err := installer.AuditBrew(brew, declared)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(audit.Undeclared, ",") != "bat,ripgrep" {
+		t.Errorf("Undeclared = %v, want [bat ripgrep]", audit.Undeclared)
+	}
+	if len(audit.DependencyOnly) != 1 || audit.DependencyOnly[0].Name != "pcre2" ||
+		strings.Join(audit.DependencyOnly[0].RequiredBy, ",") != "git,ripgrep" {
+		t.Errorf("DependencyOnly = %+v, want pcre2 required by git and ripgrep", audit.DependencyOnly)
+	}
+}
+
+func TestBrewGraph(t *testing.T) {
+	brew := NewBrew("git", "pcre2", "gettext", "neovim", "luajit", "bat")
+	brew.SetDeps("git", "pcre2", "gettext")
+	brew.SetDeps("neovim", "luajit", "gettext")
+
+	declared := &models.BrewConfig{Formulae: []models.BrewPackage{
+		{Name: "git"}, {Name: "gettext"}, {Name: "neovim"}, {Name: "user/tap/missing"},
+	}}
+	g, err := installer.BrewGraph(brew, declared)
+	if err != nil {
+		t.Fatal(err)
+	}
+	classes := make(map[string]string)
+	for _, n := range g.Nodes {
+		classes[n.ID] = n.Class
+	}
+	want := map[string]string{
+		"git":     installer.NodeLeaf,
+		"neovim":  installer.NodeLeaf,
+		"gettext": installer.NodeDeclaredDep,
+		"pcre2":   installer.NodeDependency,
+		"luajit":  installer.NodeDependency,
+		"missing": installer.NodeNotInstalled,
+	}
+	if len(classes) != len(want) {
+		t.Errorf("nodes = %v, want %v (bat isn't declared or needed)", classes, want)
+	}
+	for name, class := range want {
+		if classes[name] != class {
+			t.Errorf("%s is %q, want %q", name, classes[name], class)
+		}
+	}
+	if len(g.Edges) != 4 {
+		t.Errorf("edges = %+v, want git and neovim to their two dependencies each", g.Edges)
+	}
+}
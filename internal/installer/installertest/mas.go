@@ -0,0 +1,97 @@
+package installertest
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MAS is an in-memory Mac App Store CLI. It answers "mas list" and
+// "mas account" from its state and "mas install" adds apps to it, unless a
+// failure is queued with Fail (keyed by the app ID as a string).
+type MAS struct {
+	recorder
+	apps    map[int]string
+	account string
+}
+
+// NewMAS returns a fake mas signed in as account ("" means signed out)
+func NewMAS(account string) *MAS {
+	return &MAS{apps: make(map[int]string), account: account}
+}
+
+// AddApp marks an app as installed
+func (m *MAS) AddApp(id int, name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.apps[id] = name
+}
+
+// HasApp reports whether the app with id is installed
+func (m *MAS) HasApp(id int) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.apps[id]
+	return ok
+}
+
+// Query answers "mas list" and "mas account"
+func (m *MAS) Query(name string, args ...string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.record(name, args)
+
+	if name != "mas" || len(args) != 1 {
+		return nil, fmt.Errorf("installertest: unsupported command %s %s", name, strings.Join(args, " "))
+	}
+	switch args[0] {
+	case "list":
+		ids := make([]int, 0, len(m.apps))
+		for id := range m.apps {
+			ids = append(ids, id)
+		}
+		sort.Ints(ids)
+		var out strings.Builder
+		for _, id := range ids {
+			// Same layout as the real CLI: "497799835 Xcode (16.0)"
+			fmt.Fprintf(&out, "%d %s (1.0)\n", id, m.apps[id])
+		}
+		return []byte(out.String()), nil
+	case "account":
+		if m.account == "" {
+			return []byte("Not signed in\n"), errExit
+		}
+		return []byte(m.account + "\n"), nil
+	}
+	return nil, fmt.Errorf("installertest: unsupported command %s %s", name, args[0])
+}
+
+// Install handles "mas install <id>". Apps installed this way are named after
+// their ID since the fake has no store catalog.
+func (m *MAS) Install(echo bool, w io.Writer, name string, args ...string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.record(name, args)
+
+	if name != "mas" || len(args) != 2 || args[0] != "install" {
+		return "", fmt.Errorf("installertest: unsupported command %s %s", name, strings.Join(args, " "))
+	}
+	id, err := strconv.Atoi(args[1])
+	if err != nil {
+		return "", fmt.Errorf("installertest: invalid app ID %q", args[1])
+	}
+
+	if failure, ok := m.nextFailure(args[1]); ok {
+		out := failure + "\n"
+		echoOutput(echo, w, out)
+		return out, errExit
+	}
+	if _, ok := m.apps[id]; !ok {
+		m.apps[id] = args[1]
+	}
+	out := "==> Installed " + args[1] + "\n"
+	echoOutput(echo, w, out)
+	return out, nil
+}
@@ -0,0 +1,306 @@
+package installertest
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// MemFS is an in-memory filesystem implementing symlink.FS. Install it with
+// symlink.SetFS (or use NewMemSandbox) to run the symlink engine without
+// touching the disk. Paths are absolute and slash-separated; symlinks are
+// followed like on disk, including in the middle of a path.
+type MemFS struct {
+	mu    sync.Mutex
+	nodes map[string]*memNode // Keyed by cleaned absolute path; "/" is implied
+}
+
+type memNode struct {
+	mode    fs.FileMode
+	data    []byte
+	dest    string // Symlink destination
+	modTime time.Time
+}
+
+// NewMemFS returns an empty filesystem holding only the root directory
+func NewMemFS() *MemFS {
+	return &MemFS{nodes: map[string]*memNode{"/": {mode: fs.ModeDir | 0755}}}
+}
+
+// WriteFile creates or replaces the regular file name, creating its parents
+func (m *MemFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, err := m.mkdirAll("write", filepath.Dir(name)); err != nil {
+		return err
+	}
+	p, err := m.resolve("write", name, true)
+	if err != nil {
+		return err
+	}
+	if n, ok := m.nodes[p]; ok && n.mode.IsDir() {
+		return pathError("write", name, syscall.EISDIR)
+	}
+	m.nodes[p] = &memNode{mode: perm.Perm(), data: append([]byte(nil), data...), modTime: time.Now()}
+	return nil
+}
+
+// Lstat describes name without following a final symlink
+func (m *MemFS) Lstat(name string) (fs.FileInfo, error) {
+	return m.stat("lstat", name, false)
+}
+
+// Stat describes name, following symlinks
+func (m *MemFS) Stat(name string) (fs.FileInfo, error) {
+	return m.stat("stat", name, true)
+}
+
+func (m *MemFS) stat(op, name string, follow bool) (fs.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, err := m.resolve(op, name, follow)
+	if err != nil {
+		return nil, err
+	}
+	n, ok := m.nodes[p]
+	if !ok {
+		return nil, pathError(op, name, fs.ErrNotExist)
+	}
+	return memInfo{name: path.Base(p), node: n}, nil
+}
+
+// ReadDir lists the directory name, sorted by name
+func (m *MemFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	dir, err := m.existing("readdir", name, true)
+	if err != nil {
+		return nil, err
+	}
+	if !m.nodes[dir].mode.IsDir() {
+		return nil, pathError("readdir", name, syscall.ENOTDIR)
+	}
+	var entries []fs.DirEntry
+	for _, child := range m.children(dir) {
+		entries = append(entries, fs.FileInfoToDirEntry(memInfo{name: path.Base(child), node: m.nodes[child]}))
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+// ReadFile returns the content of the regular file name
+func (m *MemFS) ReadFile(name string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, err := m.existing("read", name, true)
+	if err != nil {
+		return nil, err
+	}
+	if m.nodes[p].mode.IsDir() {
+		return nil, pathError("read", name, syscall.EISDIR)
+	}
+	return append([]byte(nil), m.nodes[p].data...), nil
+}
+
+// Readlink returns the destination of the symlink name
+func (m *MemFS) Readlink(name string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, err := m.existing("readlink", name, false)
+	if err != nil {
+		return "", err
+	}
+	if m.nodes[p].mode&fs.ModeSymlink == 0 {
+		return "", pathError("readlink", name, syscall.EINVAL)
+	}
+	return m.nodes[p].dest, nil
+}
+
+// Symlink creates newname pointing at oldname; its parent must exist
+func (m *MemFS) Symlink(oldname, newname string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	dir, err := m.existing("symlink", filepath.Dir(newname), true)
+	if err != nil {
+		return err
+	}
+	if !m.nodes[dir].mode.IsDir() {
+		return pathError("symlink", newname, syscall.ENOTDIR)
+	}
+	p := path.Join(dir, filepath.Base(newname))
+	if _, ok := m.nodes[p]; ok {
+		return pathError("symlink", newname, fs.ErrExist)
+	}
+	m.nodes[p] = &memNode{mode: fs.ModeSymlink | 0777, dest: oldname, modTime: time.Now()}
+	return nil
+}
+
+// MkdirAll creates the directory name and any missing parents
+func (m *MemFS) MkdirAll(name string, perm fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, err := m.mkdirAll("mkdir", name)
+	return err
+}
+
+// Remove deletes the file, symlink or empty directory name
+func (m *MemFS) Remove(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, err := m.existing("remove", name, false)
+	if err != nil {
+		return err
+	}
+	if m.nodes[p].mode.IsDir() && len(m.children(p)) > 0 {
+		return pathError("remove", name, syscall.ENOTEMPTY)
+	}
+	delete(m.nodes, p)
+	return nil
+}
+
+// RemoveAll deletes name and everything beneath it; a missing name is not an error
+func (m *MemFS) RemoveAll(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, err := m.existing("removeall", name, false)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for key := range m.nodes {
+		if key == p || strings.HasPrefix(key, p+"/") {
+			delete(m.nodes, key)
+		}
+	}
+	return nil
+}
+
+// Chmod sets the permission bits of name
+func (m *MemFS) Chmod(name string, mode fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, err := m.existing("chmod", name, true)
+	if err != nil {
+		return err
+	}
+	n := m.nodes[p]
+	n.mode = n.mode&^fs.ModePerm | mode.Perm()
+	return nil
+}
+
+// existing resolves name and checks something is there; the caller holds m.mu
+func (m *MemFS) existing(op, name string, follow bool) (string, error) {
+	p, err := m.resolve(op, name, follow)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := m.nodes[p]; !ok {
+		return "", pathError(op, name, fs.ErrNotExist)
+	}
+	return p, nil
+}
+
+// resolve maps name to its key, following symlinks in every element but the
+// last, and in the last too when follow is set. Missing elements are only an
+// error before the last one. The caller holds m.mu.
+func (m *MemFS) resolve(op, name string, follow bool) (string, error) {
+	if !filepath.IsAbs(name) {
+		return "", pathError(op, name, errors.New("memfs: path is not absolute"))
+	}
+	pending := strings.Split(strings.Trim(filepath.ToSlash(filepath.Clean(name)), "/"), "/")
+	current := "/"
+	for hops := 0; len(pending) > 0; {
+		elem := pending[0]
+		pending = pending[1:]
+		if elem == "" || elem == "." {
+			continue
+		}
+		if elem == ".." {
+			current = path.Dir(current)
+			continue
+		}
+		next := path.Join(current, elem)
+		n, ok := m.nodes[next]
+		switch {
+		case !ok && len(pending) > 0:
+			return "", pathError(op, name, fs.ErrNotExist)
+		case ok && n.mode&fs.ModeSymlink != 0 && (len(pending) > 0 || follow):
+			if hops++; hops > 40 {
+				return "", pathError(op, name, syscall.ELOOP)
+			}
+			dest := filepath.ToSlash(n.dest)
+			if path.IsAbs(dest) {
+				current = "/"
+			}
+			pending = append(strings.Split(strings.Trim(dest, "/"), "/"), pending...)
+		case ok && !n.mode.IsDir() && len(pending) > 0:
+			return "", pathError(op, name, syscall.ENOTDIR)
+		default:
+			current = next
+		}
+	}
+	return current, nil
+}
+
+// mkdirAll creates name and its parents and returns its key; the caller
+// holds m.mu
+func (m *MemFS) mkdirAll(op, name string) (string, error) {
+	if !filepath.IsAbs(name) {
+		return "", pathError(op, name, errors.New("memfs: path is not absolute"))
+	}
+	p, err := m.resolve(op, name, true)
+	if errors.Is(err, fs.ErrNotExist) {
+		if _, err := m.mkdirAll(op, filepath.Dir(name)); err != nil {
+			return "", err
+		}
+		p, err = m.resolve(op, name, true)
+	}
+	if err != nil {
+		return "", err
+	}
+	if n, ok := m.nodes[p]; ok {
+		if !n.mode.IsDir() {
+			return "", pathError(op, name, syscall.ENOTDIR)
+		}
+		return p, nil
+	}
+	m.nodes[p] = &memNode{mode: fs.ModeDir | 0755, modTime: time.Now()}
+	return p, nil
+}
+
+// children lists the keys directly inside dir; the caller holds m.mu
+func (m *MemFS) children(dir string) []string {
+	prefix := strings.TrimSuffix(dir, "/") + "/"
+	var keys []string
+	for key := range m.nodes {
+		if key != "/" && strings.HasPrefix(key, prefix) && !strings.Contains(key[len(prefix):], "/") {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func pathError(op, name string, err error) error {
+	return &fs.PathError{Op: op, Path: name, Err: err}
+}
+
+// memInfo is the fs.FileInfo of a MemFS node
+type memInfo struct {
+	name string
+	node *memNode
+}
+
+func (i memInfo) Name() string       { return i.name }
+func (i memInfo) Size() int64        { return int64(len(i.node.data)) }
+func (i memInfo) Mode() fs.FileMode  { return i.node.mode }
+func (i memInfo) ModTime() time.Time { return i.node.modTime }
+func (i memInfo) IsDir() bool        { return i.node.mode.IsDir() }
+func (i memInfo) Sys() any           { return nil }
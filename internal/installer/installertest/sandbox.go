@@ -0,0 +1,175 @@
+package installertest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ildx/merlin/internal/config"
+	"github.com/ildx/merlin/internal/symlink"
+)
+
+// Sandbox is a throwaway home directory and dotfiles repository under
+// t.TempDir(). HOME and MERLIN_DOTFILES point at it for the rest of the test,
+// so the real symlink engine, state and backups only ever touch the sandbox.
+type Sandbox struct {
+	Home string               // Stand-in home directory
+	Repo *config.DotfilesRepo // Dotfiles repository with a minimal merlin.toml
+	FS   *MemFS               // In-memory files and links (NewMemSandbox); nil on disk
+
+	t testing.TB
+}
+
+// NewSandbox creates the sandbox and points HOME and MERLIN_DOTFILES at it
+func NewSandbox(t testing.TB) *Sandbox {
+	t.Helper()
+	dir := t.TempDir()
+	s := &Sandbox{Home: filepath.Join(dir, "home"), t: t}
+
+	root := filepath.Join(dir, "dotfiles")
+	s.mkdir(s.Home)
+	s.mkdir(filepath.Join(root, config.ConfigDir))
+	s.write(filepath.Join(root, config.RootConfigFile), "metadata = { name = \"sandbox\" }\n")
+
+	repo, err := config.LoadDotfilesRepo(root)
+	if err != nil {
+		t.Fatalf("load sandbox repo: %v", err)
+	}
+	s.Repo = repo
+
+	t.Setenv("HOME", s.Home)
+	t.Setenv(config.EnvVarDotfiles, root)
+	return s
+}
+
+// NewMemSandbox is NewSandbox with the symlink engine switched to a MemFS
+// for the rest of the test: the files the sandbox writes and the links the
+// engine creates live in memory. merlin.toml files, which are parsed from
+// disk, and merlin's state (link registry, backups) stay on disk.
+func NewMemSandbox(t testing.TB) *Sandbox {
+	t.Helper()
+	s := NewSandbox(t)
+	s.FS = NewMemFS()
+	s.mkdir(s.Home)
+	s.mkdir(s.Repo.ConfigDir)
+	t.Cleanup(symlink.SetFS(s.FS))
+	return s
+}
+
+// WriteFile writes content to rel inside the repository and returns its path
+func (s *Sandbox) WriteFile(rel, content string) string {
+	s.t.Helper()
+	path := filepath.Join(s.Repo.Root, rel)
+	if filepath.Base(rel) == config.RootConfigFile {
+		s.writeDisk(path, content)
+		return path
+	}
+	s.mkdir(filepath.Dir(path))
+	s.write(path, content)
+	return path
+}
+
+// WriteHomeFile writes content to rel inside the home directory and returns its path
+func (s *Sandbox) WriteHomeFile(rel, content string) string {
+	s.t.Helper()
+	path := filepath.Join(s.Home, rel)
+	s.mkdir(filepath.Dir(path))
+	s.write(path, content)
+	return path
+}
+
+// AddTool creates config/<name>/config/ with files (relative path → content),
+// which links into ~/.config/<name> without a tool merlin.toml
+func (s *Sandbox) AddTool(name string, files map[string]string) {
+	s.t.Helper()
+	for rel, content := range files {
+		s.WriteFile(filepath.Join(config.ConfigDir, name, "config", rel), content)
+	}
+}
+
+// Variables returns the link variables for the sandbox home
+func (s *Sandbox) Variables() symlink.Variables {
+	return symlink.Variables{HomeDir: s.Home, ConfigDir: filepath.Join(s.Home, ".config")}
+}
+
+// Link discovers and links tool with the real symlink engine
+func (s *Sandbox) Link(tool string) []*symlink.LinkResult {
+	s.t.Helper()
+	cfg, err := symlink.DiscoverToolConfig(s.Repo, tool, s.Variables())
+	if err != nil {
+		s.t.Fatalf("discover %s: %v", tool, err)
+	}
+	results, err := symlink.LinkTool(cfg, false)
+	if err != nil {
+		s.t.Fatalf("link %s: %v", tool, err)
+	}
+	return results
+}
+
+// LinkTarget returns where the symlink at rel (relative to Home) points, or
+// "" when rel is not a symlink
+func (s *Sandbox) LinkTarget(rel string) string {
+	dest, err := s.fs().Readlink(filepath.Join(s.Home, rel))
+	if err != nil {
+		return ""
+	}
+	return dest
+}
+
+// Symlink creates a symlink at path pointing at dest, e.g. one made by hand
+func (s *Sandbox) Symlink(dest, path string) {
+	s.t.Helper()
+	if err := s.fs().Symlink(dest, path); err != nil {
+		s.t.Fatalf("symlink %s: %v", path, err)
+	}
+}
+
+// Remove deletes the file or symlink at path
+func (s *Sandbox) Remove(path string) {
+	s.t.Helper()
+	if err := s.fs().Remove(path); err != nil {
+		s.t.Fatalf("remove %s: %v", path, err)
+	}
+}
+
+// Exists reports whether anything, including a dangling symlink, is at path
+func (s *Sandbox) Exists(path string) bool {
+	_, err := s.fs().Lstat(path)
+	return err == nil
+}
+
+// fs is the filesystem the sandbox's files live in
+func (s *Sandbox) fs() symlink.FS {
+	if s.FS != nil {
+		return s.FS
+	}
+	return symlink.OSFS{}
+}
+
+func (s *Sandbox) mkdir(dir string) {
+	s.t.Helper()
+	if err := s.fs().MkdirAll(dir, 0755); err != nil {
+		s.t.Fatalf("mkdir %s: %v", dir, err)
+	}
+}
+
+func (s *Sandbox) write(path, content string) {
+	s.t.Helper()
+	if s.FS == nil {
+		s.writeDisk(path, content)
+		return
+	}
+	if err := s.FS.WriteFile(path, []byte(content), 0644); err != nil {
+		s.t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func (s *Sandbox) writeDisk(path, content string) {
+	s.t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		s.t.Fatalf("mkdir %s: %v", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		s.t.Fatalf("write %s: %v", path, err)
+	}
+}
@@ -4,31 +4,33 @@ import (
 	"bufio"
 	"fmt"
 	"io"
-	"os/exec"
 	"strconv"
 	"strings"
 
+	"github.com/ildx/merlin/internal/cli"
 	"github.com/ildx/merlin/internal/models"
+	"github.com/ildx/merlin/internal/progress"
 )
 
 // MASInstaller handles Mac App Store app installation
 type MASInstaller struct {
-	DryRun  bool
-	Verbose bool
+	DryRun    bool
+	Verbosity cli.Verbosity
+	Retry     RetryPolicy
+	Provider  Provider // Runs mas; nil runs the real binary
 }
 
 // NewMASInstaller creates a new Mac App Store installer
-func NewMASInstaller(dryRun, verbose bool) *MASInstaller {
+func NewMASInstaller(dryRun bool, verbosity cli.Verbosity) *MASInstaller {
 	return &MASInstaller{
-		DryRun:  dryRun,
-		Verbose: verbose,
+		DryRun:    dryRun,
+		Verbosity: verbosity,
 	}
 }
 
 // IsAppInstalled checks if a Mac App Store app is installed
 func (m *MASInstaller) IsAppInstalled(appID int) (bool, error) {
-	cmd := exec.Command("mas", "list")
-	output, err := cmd.Output()
+	output, err := providerOrExec(m.Provider).Query("mas", "list")
 	if err != nil {
 		return false, fmt.Errorf("failed to list installed apps: %w", err)
 	}
@@ -52,8 +54,7 @@ func (m *MASInstaller) IsAppInstalled(appID int) (bool, error) {
 
 // CheckMASAccount checks if the user is signed into the Mac App Store
 func (m *MASInstaller) CheckMASAccount() (bool, string, error) {
-	cmd := exec.Command("mas", "account")
-	output, err := cmd.Output()
+	output, err := providerOrExec(m.Provider).Query("mas", "account")
 	
 	if err != nil {
 		// Exit code 1 usually means not signed in
@@ -105,58 +106,11 @@ func (m *MASInstaller) InstallApp(app models.MASApp, output io.Writer) *InstallR
 		fmt.Fprintf(output, "  🍎 Installing %s (ID: %d)...\n", app.Name, app.ID)
 	}
 
-	cmd := exec.Command("mas", "install", strconv.Itoa(app.ID))
-
-	// Stream output if verbose
-	if m.Verbose && output != nil {
-		stdout, err := cmd.StdoutPipe()
-		if err != nil {
-			result.Error = err
-			return result
-		}
-		stderr, err := cmd.StderrPipe()
-		if err != nil {
-			result.Error = err
-			return result
-		}
-
-		if err := cmd.Start(); err != nil {
-			result.Error = err
-			return result
-		}
-
-		// Stream stdout
-		go func() {
-			scanner := bufio.NewScanner(stdout)
-			for scanner.Scan() {
-				fmt.Fprintf(output, "     %s\n", scanner.Text())
-			}
-		}()
-
-		// Stream stderr
-		go func() {
-			scanner := bufio.NewScanner(stderr)
-			for scanner.Scan() {
-				fmt.Fprintf(output, "     %s\n", scanner.Text())
-			}
-		}()
-
-		err = cmd.Wait()
-		if err != nil {
-			result.Error = fmt.Errorf("installation failed: %w", err)
-			return result
-		}
-	} else {
-		// Non-verbose: just run and capture output
-		outputBytes, err := cmd.CombinedOutput()
-		result.Output = string(outputBytes)
-		if err != nil {
-			result.Error = fmt.Errorf("installation failed: %w", err)
-			if output != nil {
-				fmt.Fprintf(output, "     Error: %v\n", err)
-			}
-			return result
-		}
+	echo := m.Verbosity.Stream()
+	if !runInstallWith(m.Retry, echo, output, app.Name, func() (string, error) {
+		return providerOrExec(m.Provider).Install(echo, output, "mas", "install", strconv.Itoa(app.ID))
+	}, result) {
+		return result
 	}
 
 	result.Success = true
@@ -175,10 +129,14 @@ func (m *MASInstaller) InstallApps(apps []models.MASApp, output io.Writer) []*In
 		fmt.Fprintf(output, "\n🍎 Installing %d Mac App Store app(s)...\n\n", len(apps))
 	}
 
+	phase := progress.Start("apps", len(apps))
 	for _, app := range apps {
+		phase.Begin(app.Name)
 		result := m.InstallApp(app, output)
+		reportResult(phase, result)
 		results = append(results, result)
 	}
+	phase.End()
 
 	return results
 }
@@ -226,9 +184,10 @@ func PrintMASSummary(results []*InstallResult, output io.Writer) {
 	if len(failures) > 0 {
 		fmt.Fprintf(output, "\n❌ Failed installations:\n")
 		for _, failure := range failures {
-			fmt.Fprintf(output, "   • %s: %v\n", failure.Package, failure.Error)
+			fmt.Fprintf(output, "   • %s: %s\n", failure.Package, DescribeFailure(failure))
 		}
 	}
+	printInstallTimings(output, results)
 
 	fmt.Fprintln(output, strings.Repeat("═", 80))
 	fmt.Println()
@@ -0,0 +1,216 @@
+package installer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MASAppState reconciles what `mas list` reports with the app bundles that
+// are actually on disk. mas keeps listing apps that were dragged to the
+// Trash, and misses apps copied between machines or installed under another
+// Apple ID.
+type MASAppState struct {
+	ID      int
+	Name    string
+	Path    string // app bundle, when found
+	Listed  bool   // reported by `mas list`
+	Present bool   // bundle found in an applications folder
+}
+
+// Stale reports an app mas still lists although its bundle is gone
+func (s MASAppState) Stale() bool {
+	return s.Listed && !s.Present
+}
+
+// Untracked reports an App Store bundle mas doesn't list
+func (s MASAppState) Untracked() bool {
+	return s.Present && !s.Listed
+}
+
+// Label returns "Name (id)" for display
+func (s MASAppState) Label() string {
+	return fmt.Sprintf("%s (%d)", s.Name, s.ID)
+}
+
+// masList runs `mas list`; replaced in tests
+var masList = func() ([]byte, error) {
+	if _, err := exec.LookPath("mas"); err != nil {
+		return nil, fmt.Errorf("mas-cli is not installed. Install it with: brew install mas")
+	}
+	out, err := exec.Command("mas", "list").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list installed apps: %w", err)
+	}
+	return out, nil
+}
+
+// applicationDirs returns the folders scanned for app bundles; replaced in tests
+var applicationDirs = func() []string {
+	dirs := []string{"/Applications"}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, "Applications"))
+	}
+	return dirs
+}
+
+// appStoreID reads a bundle's App Store id from Spotlight metadata; replaced in tests
+var appStoreID = func(bundle string) int {
+	out, err := exec.Command("mdls", "-name", "kMDItemAppStoreAdamID", "-raw", bundle).Output()
+	if err != nil {
+		return 0
+	}
+	id, _ := strconv.Atoi(strings.TrimSpace(string(out)))
+	return id
+}
+
+// ScanMASApps merges `mas list` with the App Store bundles (those carrying a
+// _MASReceipt) in the applications folders. Listed apps are matched to
+// bundles by App Store id, falling back to "<name>.app". Results are sorted
+// by name.
+func ScanMASApps() ([]MASAppState, error) {
+	out, err := masList()
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int]*MASAppState)
+	for _, app := range parseMASList(out) {
+		byID[app.ID] = &app
+	}
+
+	for _, dir := range applicationDirs() {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if !strings.HasSuffix(entry.Name(), ".app") {
+				continue
+			}
+			bundle := filepath.Join(dir, entry.Name())
+			name := strings.TrimSuffix(entry.Name(), ".app")
+			id := 0
+			if _, err := os.Stat(filepath.Join(bundle, "Contents", "_MASReceipt", "receipt")); err == nil {
+				id = appStoreID(bundle)
+			}
+
+			switch app := byID[id]; {
+			case id != 0 && app != nil:
+				app.Path, app.Present = bundle, true
+			case id != 0:
+				byID[id] = &MASAppState{ID: id, Name: name, Path: bundle, Present: true}
+			default:
+				// No receipt or no metadata: match a listed app by bundle name
+				for _, app := range byID {
+					if !app.Present && app.Listed && app.Name == name {
+						app.Path, app.Present = bundle, true
+						break
+					}
+				}
+			}
+		}
+	}
+
+	apps := make([]MASAppState, 0, len(byID))
+	for _, app := range byID {
+		apps = append(apps, *app)
+	}
+	sort.Slice(apps, func(i, j int) bool {
+		if apps[i].Name != apps[j].Name {
+			return apps[i].Name < apps[j].Name
+		}
+		return apps[i].ID < apps[j].ID
+	})
+	return apps, nil
+}
+
+// parseMASList parses `mas list` lines such as "497799835  Xcode  (16.0)"
+func parseMASList(out []byte) []MASAppState {
+	var apps []MASAppState
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	for scanner.Scan() {
+		idText, rest, ok := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+		if !ok {
+			continue
+		}
+		id, err := strconv.Atoi(idText)
+		if err != nil {
+			continue
+		}
+		name := strings.TrimSpace(rest)
+		if i := strings.LastIndex(name, " ("); i > 0 && strings.HasSuffix(name, ")") {
+			name = strings.TrimSpace(name[:i])
+		}
+		apps = append(apps, MASAppState{ID: id, Name: name, Listed: true})
+	}
+	return apps
+}
+
+// masUninstall runs `sudo mas uninstall <id>`; replaced in tests
+var masUninstall = func(id int, output io.Writer) error {
+	cmd := exec.Command("sudo", "mas", "uninstall", strconv.Itoa(id))
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = output
+	cmd.Stderr = output
+	return cmd.Run()
+}
+
+// masSupportsUninstall reports whether the installed mas has an uninstall
+// command (mas 1.6+); replaced in tests
+var masSupportsUninstall = func() bool {
+	return exec.Command("mas", "help", "uninstall").Run() == nil
+}
+
+// UninstallApp removes an App Store app with `sudo mas uninstall`. When mas
+// can't do it (too old, or the app is not one it lists), the result fails
+// with instructions for removing the bundle by hand. Apps whose bundle is
+// already gone are reported as already removed.
+func (m *MASInstaller) UninstallApp(app MASAppState, output io.Writer) *InstallResult {
+	result := &InstallResult{Package: app.Name}
+
+	if !app.Present {
+		result.AlreadyExists = true
+		result.Success = true
+		if output != nil {
+			fmt.Fprintf(output, "  ⏭  %s (already removed from Applications)\n", app.Label())
+		}
+		return result
+	}
+
+	if m.DryRun {
+		if output != nil {
+			fmt.Fprintf(output, "  [DRY RUN] Would uninstall: %s (%s)\n", app.Label(), app.Path)
+		}
+		result.Success = true
+		return result
+	}
+
+	if !app.Listed || !masSupportsUninstall() {
+		result.Error = fmt.Errorf("mas cannot uninstall this app; move %s to the Trash in Finder", app.Path)
+		if output != nil {
+			fmt.Fprintf(output, "  ✗ %s: remove it manually by moving %s to the Trash in Finder\n", app.Label(), app.Path)
+		}
+		return result
+	}
+
+	if output != nil {
+		fmt.Fprintf(output, "  🗑  Uninstalling %s (sudo may ask for your password)...\n", app.Label())
+	}
+	if err := masUninstall(app.ID, output); err != nil {
+		result.Error = fmt.Errorf("mas uninstall failed: %w (move %s to the Trash in Finder instead)", err, app.Path)
+		return result
+	}
+
+	result.Success = true
+	if output != nil {
+		fmt.Fprintf(output, "  ✓ %s uninstalled\n", app.Label())
+	}
+	return result
+}
@@ -0,0 +1,120 @@
+package installer
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// stubMASApps fakes `mas list` and an applications folder holding bundles;
+// ids maps bundle names to App Store ids and marks them with a receipt
+func stubMASApps(t *testing.T, list string, bundles []string, ids map[string]int) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, name := range bundles {
+		contents := filepath.Join(dir, name+".app", "Contents")
+		os.MkdirAll(contents, 0755)
+		if _, ok := ids[name]; ok {
+			os.MkdirAll(filepath.Join(contents, "_MASReceipt"), 0755)
+			os.WriteFile(filepath.Join(contents, "_MASReceipt", "receipt"), []byte("r"), 0644)
+		}
+	}
+
+	origList, origDirs, origID := masList, applicationDirs, appStoreID
+	masList = func() ([]byte, error) { return []byte(list), nil }
+	applicationDirs = func() []string { return []string{dir} }
+	appStoreID = func(bundle string) int { return ids[strings.TrimSuffix(filepath.Base(bundle), ".app")] }
+	t.Cleanup(func() { masList, applicationDirs, appStoreID = origList, origDirs, origID })
+	return dir
+}
+
+func TestParseMASList(t *testing.T) {
+	apps := parseMASList([]byte("497799835  Xcode  (16.0)\n1289583905 Pixelmator Pro (3.6.1)\nNo installed apps found\n\n"))
+	if len(apps) != 2 {
+		t.Fatalf("parseMASList() = %+v", apps)
+	}
+	if apps[0].ID != 497799835 || apps[0].Name != "Xcode" || !apps[0].Listed {
+		t.Errorf("apps[0] = %+v", apps[0])
+	}
+	if apps[1].Name != "Pixelmator Pro" {
+		t.Errorf("apps[1].Name = %q", apps[1].Name)
+	}
+}
+
+func TestScanMASApps(t *testing.T) {
+	dir := stubMASApps(t,
+		"497799835 Xcode (16.0)\n1289583905 Pixelmator Pro (3.6.1)\n409183694 Keynote (14.0)\n",
+		[]string{"Xcode", "Keynote", "Things3", "Safari"},
+		map[string]int{"Xcode": 497799835, "Things3": 904280696})
+
+	apps, err := ScanMASApps()
+	if err != nil {
+		t.Fatalf("ScanMASApps() error = %v", err)
+	}
+	byName := make(map[string]MASAppState)
+	for _, app := range apps {
+		byName[app.Name] = app
+	}
+	if len(apps) != 4 {
+		t.Fatalf("ScanMASApps() = %+v", apps)
+	}
+
+	if app := byName["Xcode"]; !app.Present || app.Stale() || app.Path != filepath.Join(dir, "Xcode.app") {
+		t.Errorf("Xcode matched by id: %+v", app)
+	}
+	if app := byName["Keynote"]; !app.Present || app.Stale() {
+		t.Errorf("Keynote matched by name without a receipt: %+v", app)
+	}
+	if app := byName["Pixelmator Pro"]; !app.Stale() {
+		t.Errorf("Pixelmator Pro should be stale: %+v", app)
+	}
+	if app := byName["Things3"]; !app.Untracked() || app.ID != 904280696 {
+		t.Errorf("Things3 should be untracked: %+v", app)
+	}
+	if _, ok := byName["Safari"]; ok {
+		t.Error("bundles without a receipt or mas entry are not App Store apps")
+	}
+}
+
+func TestUninstallApp(t *testing.T) {
+	origUninstall, origSupports := masUninstall, masSupportsUninstall
+	t.Cleanup(func() { masUninstall, masSupportsUninstall = origUninstall, origSupports })
+	var uninstalled []int
+	masUninstall = func(id int, _ io.Writer) error {
+		uninstalled = append(uninstalled, id)
+		return nil
+	}
+	supported := true
+	masSupportsUninstall = func() bool { return supported }
+
+	present := MASAppState{ID: 1, Name: "App", Path: "/Applications/App.app", Listed: true, Present: true}
+	var out bytes.Buffer
+
+	if r := NewMASInstaller(true, 0).UninstallApp(present, &out); !r.Success || len(uninstalled) != 0 {
+		t.Errorf("dry run: %+v, uninstalled %v", r, uninstalled)
+	}
+	if r := NewMASInstaller(false, 0).UninstallApp(present, &out); !r.Success || len(uninstalled) != 1 {
+		t.Errorf("uninstall: %+v, uninstalled %v", r, uninstalled)
+	}
+
+	stale := present
+	stale.Present = false
+	if r := NewMASInstaller(false, 0).UninstallApp(stale, &out); !r.AlreadyExists || len(uninstalled) != 1 {
+		t.Errorf("stale app should be reported as already removed: %+v", r)
+	}
+
+	// Old mas or an app mas doesn't list: guide manual removal
+	supported = false
+	if r := NewMASInstaller(false, 0).UninstallApp(present, &out); r.Success || r.Error == nil || !strings.Contains(r.Error.Error(), "Trash") {
+		t.Errorf("expected manual removal guidance, got %+v", r)
+	}
+	supported = true
+	untracked := present
+	untracked.Listed = false
+	if r := NewMASInstaller(false, 0).UninstallApp(untracked, &out); r.Success || len(uninstalled) != 1 {
+		t.Errorf("untracked app must not go through mas: %+v", r)
+	}
+}
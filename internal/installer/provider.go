@@ -0,0 +1,50 @@
+package installer
+
+import (
+	"io"
+	"os/exec"
+
+	"github.com/ildx/merlin/internal/system"
+)
+
+// Provider runs package manager commands (brew, mas) for the installers.
+// Installers without one use ExecProvider; the installertest package has
+// in-memory fakes so installs can be tested without touching the system.
+type Provider interface {
+	// Query runs a read-only command such as "brew list --cask foo" and
+	// returns its standard output
+	Query(name string, args ...string) ([]byte, error)
+	// Install runs a command that changes the system and returns its combined
+	// output. With echo set, output is streamed to w as it arrives.
+	Install(echo bool, w io.Writer, name string, args ...string) (string, error)
+}
+
+// ExecProvider runs the real brew and mas binaries. "brew" is the one
+// system.BrewPath selects, not necessarily the first on PATH.
+type ExecProvider struct{}
+
+// Query runs name with args and returns its standard output
+func (ExecProvider) Query(name string, args ...string) ([]byte, error) {
+	return exec.Command(execName(name), args...).Output()
+}
+
+// Install runs name with args and returns its combined output
+func (ExecProvider) Install(echo bool, w io.Writer, name string, args ...string) (string, error) {
+	return runCommand(exec.Command(execName(name), args...), echo, w)
+}
+
+// execName resolves the command ExecProvider runs for name
+func execName(name string) string {
+	if name == "brew" {
+		return system.BrewPath()
+	}
+	return name
+}
+
+// providerOrExec returns p, or ExecProvider when p is nil
+func providerOrExec(p Provider) Provider {
+	if p == nil {
+		return ExecProvider{}
+	}
+	return p
+}
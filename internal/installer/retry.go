@@ -0,0 +1,187 @@
+package installer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultRetryBackoff is the wait before the first retry; it doubles on each attempt.
+const DefaultRetryBackoff = 2 * time.Second
+
+// RetryPolicy controls how transient install failures are retried
+type RetryPolicy struct {
+	Retries int           // Extra attempts after the first failure (0 disables retrying)
+	Backoff time.Duration // Initial wait between attempts (defaults to DefaultRetryBackoff)
+}
+
+// sleep is swapped out in tests to avoid real backoff delays
+var sleep = time.Sleep
+
+// retryablePatterns are output fragments from brew/mas/curl that indicate a
+// network or download problem rather than a bad package name or build failure.
+// Only the transient curl exit codes count: resolve (6), connect (7), timeout
+// (28), TLS handshake (35), empty reply (52) and receive (56).
+var retryablePatterns = []string{
+	"curl: (6)",
+	"curl: (7)",
+	"curl: (28)",
+	"curl: (35)",
+	"curl: (52)",
+	"curl: (56)",
+	"could not resolve host",
+	"connection reset",
+	"connection refused",
+	"connection timed out",
+	"operation timed out",
+	"failed to download",
+	"download failed",
+	"failed to connect",
+	"network is unreachable",
+	"temporary failure in name resolution",
+	"ssl_error",
+	"ssl connect error",
+	"http/2 stream",
+	"502 bad gateway",
+	"503 service unavailable",
+	"504 gateway timeout",
+	"the network connection was lost",
+}
+
+// permanentPatterns mark download failures that retrying won't fix, such as
+// a missing file (curl 22: HTTP 4xx) or an untrusted certificate. They win
+// over retryablePatterns, since brew follows them with "Download failed" too.
+var permanentPatterns = []string{
+	"curl: (22)",
+	"curl: (60)",
+	"ssl certificate problem",
+	"certificate verify failed",
+}
+
+// IsRetryable reports whether installer output looks like a transient network failure
+func IsRetryable(output string) bool {
+	lower := strings.ToLower(output)
+	for _, pattern := range permanentPatterns {
+		if strings.Contains(lower, pattern) {
+			return false
+		}
+	}
+	for _, pattern := range retryablePatterns {
+		if strings.Contains(lower, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// DescribeFailure formats a failed result for summaries, separating transient
+// network failures that exhausted their retries from permanent errors.
+func DescribeFailure(r *InstallResult) string {
+	switch {
+	case r.Retryable && r.Attempts > 1:
+		return fmt.Sprintf("failed after %d retries (network): %v", r.Attempts-1, r.Error)
+	case r.Retryable:
+		return fmt.Sprintf("network error (not retried): %v", r.Error)
+	default:
+		return fmt.Sprintf("%v", r.Error)
+	}
+}
+
+// runInstall executes the command built by newCmd, retrying retryable failures
+// according to policy, echoing the command's output when echo is set. It fills
+// Output, Error, Attempts, Retryable and Duration on result.
+func runInstall(policy RetryPolicy, echo bool, output io.Writer, name string, newCmd func() *exec.Cmd, result *InstallResult) bool {
+	return runInstallWith(policy, echo, output, name, func() (string, error) {
+		return runCommand(newCmd(), echo, output)
+	}, result)
+}
+
+// runInstallWith is runInstall for an arbitrary install attempt, such as a
+// Provider call. echo reports whether attempt streams its own output.
+func runInstallWith(policy RetryPolicy, echo bool, output io.Writer, name string, attempt func() (string, error), result *InstallResult) bool {
+	start := time.Now()
+	defer func() { result.Duration = time.Since(start) }()
+
+	backoff := policy.Backoff
+	if backoff <= 0 {
+		backoff = DefaultRetryBackoff
+	}
+
+	for i := 0; ; i++ {
+		result.Attempts = i + 1
+		out, err := attempt()
+		result.Output = out
+		if err == nil {
+			result.Error = nil
+			result.Retryable = false
+			return true
+		}
+
+		result.Retryable = IsRetryable(out)
+		result.Error = fmt.Errorf("installation failed: %w", err)
+		if !echo && output != nil {
+			fmt.Fprintf(output, "     Error: %v\n", err)
+		}
+
+		if !result.Retryable || i >= policy.Retries {
+			return false
+		}
+
+		wait := backoff << i
+		if output != nil {
+			fmt.Fprintf(output, "  ↻ %s: network error, retrying in %s (attempt %d/%d)...\n",
+				name, wait, i+2, policy.Retries+1)
+		}
+		sleep(wait)
+	}
+}
+
+// runCommand runs cmd and returns its combined output. With echo set, output is
+// streamed to w (indented) as it arrives while still being captured.
+func runCommand(cmd *exec.Cmd, echo bool, w io.Writer) (string, error) {
+	if !echo || w == nil {
+		out, err := cmd.CombinedOutput()
+		return string(out), err
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return "", err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return "", err
+	}
+	if err := cmd.Start(); err != nil {
+		return "", err
+	}
+
+	var (
+		mu       sync.Mutex
+		captured strings.Builder
+		wg       sync.WaitGroup
+	)
+	stream := func(r io.Reader) {
+		defer wg.Done()
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			line := scanner.Text()
+			mu.Lock()
+			captured.WriteString(line + "\n")
+			fmt.Fprintf(w, "     %s\n", line)
+			mu.Unlock()
+		}
+	}
+	wg.Add(2)
+	go stream(stdout)
+	go stream(stderr)
+
+	// Pipes must be drained before Wait closes them
+	wg.Wait()
+	err = cmd.Wait()
+	return captured.String(), err
+}
@@ -0,0 +1,119 @@
+package installer
+
+import (
+	"bytes"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		output string
+		want   bool
+	}{
+		{"curl: (6) Could not resolve host: ghcr.io", true},
+		{"Error: Download failed on Cask 'cursor'", true},
+		{"Error: 503 Service Unavailable", true},
+		{"Error: No available formula with the name \"nope\"", false},
+		{"Error: An exception occurred within a postinstall block", false},
+		{"curl: (28) Operation timed out after 30000 milliseconds", true},
+		{"curl: (56) Recv failure: Connection reset by peer", true},
+		{"curl: (22) The requested URL returned error: 404\nError: Download failed on Cask 'old'", false},
+		{"curl: (60) SSL certificate problem: unable to get local issuer certificate", false},
+		{"curl: (3) URL using bad/illegal format or missing URL", false},
+		{"test_server_start timed out after 10s\nError: nope built with errors", false},
+	}
+	for _, tt := range tests {
+		if got := IsRetryable(tt.output); got != tt.want {
+			t.Errorf("IsRetryable(%q) = %v, want %v", tt.output, got, tt.want)
+		}
+	}
+}
+
+func TestRunInstallRetriesNetworkErrors(t *testing.T) {
+	var waits []time.Duration
+	sleep = func(d time.Duration) { waits = append(waits, d) }
+	defer func() { sleep = time.Sleep }()
+
+	// Fail with a network error on the first two attempts, then succeed
+	marker := filepath.Join(t.TempDir(), "attempts")
+	script := `echo x >> "$0"; [ $(wc -l < "$0") -ge 3 ] && exit 0; echo "curl: (56) Connection reset by peer"; exit 1`
+	newCmd := func() *exec.Cmd { return exec.Command("sh", "-c", script, marker) }
+
+	var out bytes.Buffer
+	result := &InstallResult{Package: "bat"}
+	ok := runInstall(RetryPolicy{Retries: 3, Backoff: time.Second}, false, &out, "bat", newCmd, result)
+	if !ok {
+		t.Fatalf("runInstall() failed: %v\n%s", result.Error, out.String())
+	}
+	if result.Attempts != 3 {
+		t.Errorf("Attempts = %d, want 3", result.Attempts)
+	}
+	if len(waits) != 2 || waits[0] != time.Second || waits[1] != 2*time.Second {
+		t.Errorf("backoff waits = %v, want [1s 2s]", waits)
+	}
+}
+
+func TestRunInstallPermanentFailure(t *testing.T) {
+	sleep = func(time.Duration) { t.Fatal("permanent failures must not be retried") }
+	defer func() { sleep = time.Sleep }()
+
+	newCmd := func() *exec.Cmd {
+		return exec.Command("sh", "-c", `echo 'Error: No available formula with the name "nope"'; exit 1`)
+	}
+	result := &InstallResult{Package: "nope"}
+	if runInstall(RetryPolicy{Retries: 3}, false, nil, "nope", newCmd, result) {
+		t.Fatal("runInstall() succeeded, want failure")
+	}
+	if result.Attempts != 1 || result.Retryable {
+		t.Errorf("Attempts = %d, Retryable = %v; want 1, false", result.Attempts, result.Retryable)
+	}
+	if desc := DescribeFailure(result); strings.Contains(desc, "retries") {
+		t.Errorf("DescribeFailure() = %q, should not mention retries", desc)
+	}
+}
+
+func TestRunInstallExhaustsRetries(t *testing.T) {
+	sleep = func(time.Duration) {}
+	defer func() { sleep = time.Sleep }()
+
+	newCmd := func() *exec.Cmd {
+		return exec.Command("sh", "-c", "echo 'curl: (28) Operation timed out' >&2; exit 1")
+	}
+	var out bytes.Buffer
+	result := &InstallResult{Package: "cursor"}
+	if runInstall(RetryPolicy{Retries: 2}, true, &out, "cursor", newCmd, result) {
+		t.Fatal("runInstall() succeeded, want failure")
+	}
+	if result.Attempts != 3 {
+		t.Errorf("Attempts = %d, want 3", result.Attempts)
+	}
+	if !strings.Contains(out.String(), "Operation timed out") {
+		t.Errorf("verbose output not streamed: %q", out.String())
+	}
+	if desc := DescribeFailure(result); !strings.Contains(desc, "failed after 2 retries") {
+		t.Errorf("DescribeFailure() = %q", desc)
+	}
+}
+
+func TestInstallTimings(t *testing.T) {
+	formulae := []*InstallResult{
+		{Package: "git", AlreadyExists: true, Success: true},
+		{Package: "ripgrep", Success: true, Attempts: 1, Duration: 2 * time.Second},
+	}
+	casks := []*InstallResult{
+		{Package: "docker", Attempts: 3, Duration: 30 * time.Second},
+	}
+
+	timings := InstallTimings(formulae, casks)
+	if len(timings.Items) != 2 {
+		t.Fatalf("want the 2 attempted packages, got %+v", timings.Items)
+	}
+	want := "installed 1 package(s) in 32.0s; slowest: docker 30.0s"
+	if got := timings.Summary("installed", "package(s)"); got != want {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,115 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"github.com/BurntSushi/toml"
+	"github.com/ildx/merlin/internal/models"
+)
+
+// Selection is a saved choice of packages, replayed with install --selection
+// instead of picking them again. One file can hold brew and mas choices.
+type Selection struct {
+	Formulae []string `toml:"formulae,omitempty"`
+	Casks    []string `toml:"casks,omitempty"`
+	MAS      []string `toml:"mas,omitempty"` // app names (or App Store IDs)
+}
+
+// LoadSelection reads a selection file
+func LoadSelection(path string) (*Selection, error) {
+	s := &Selection{}
+	if _, err := toml.DecodeFile(path, s); err != nil {
+		return nil, fmt.Errorf("read selection %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// SaveSelection writes s to path; lists s leaves nil keep what the file
+// already holds, so brew and mas selections can share a file
+func SaveSelection(path string, s *Selection) error {
+	merged := *s
+	if existing, err := LoadSelection(path); err == nil {
+		if merged.Formulae == nil {
+			merged.Formulae = existing.Formulae
+		}
+		if merged.Casks == nil {
+			merged.Casks = existing.Casks
+		}
+		if merged.MAS == nil {
+			merged.MAS = existing.MAS
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create selection directory: %w", err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("write selection %s: %w", path, err)
+	}
+	defer f.Close()
+	return toml.NewEncoder(f).Encode(merged)
+}
+
+// LastSelectionPath is where the TUI saves the packages last picked
+func LastSelectionPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("get home directory: %w", err)
+	}
+	return filepath.Join(home, ".merlin", "selections", "last.toml"), nil
+}
+
+// PackageNames returns the names of packages
+func PackageNames(packages []models.BrewPackage) []string {
+	names := make([]string, 0, len(packages))
+	for _, p := range packages {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// AppNames returns the names of apps
+func AppNames(apps []models.MASApp) []string {
+	names := make([]string, 0, len(apps))
+	for _, a := range apps {
+		names = append(names, a.Name)
+	}
+	return names
+}
+
+// SelectNamed keeps the packages named in names, in declaration order, and
+// returns the names matching none of them
+func SelectNamed(packages []models.BrewPackage, names []string) (selected []models.BrewPackage, unknown []string) {
+	for _, p := range packages {
+		if slices.Contains(names, p.Name) {
+			selected = append(selected, p)
+		}
+	}
+	for _, name := range names {
+		if !slices.ContainsFunc(packages, func(p models.BrewPackage) bool { return p.Name == name }) {
+			unknown = append(unknown, name)
+		}
+	}
+	return selected, unknown
+}
+
+// SelectNamedApps keeps the apps named (or identified) in names, in
+// declaration order, and returns the names matching none of them
+func SelectNamedApps(apps []models.MASApp, names []string) (selected []models.MASApp, unknown []string) {
+	matches := func(a models.MASApp, name string) bool { return a.Name == name || strconv.Itoa(a.ID) == name }
+	for _, a := range apps {
+		if slices.ContainsFunc(names, func(name string) bool { return matches(a, name) }) {
+			selected = append(selected, a)
+		}
+	}
+	for _, name := range names {
+		if !slices.ContainsFunc(apps, func(a models.MASApp) bool { return matches(a, name) }) {
+			unknown = append(unknown, name)
+		}
+	}
+	return selected, unknown
+}
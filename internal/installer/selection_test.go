@@ -0,0 +1,53 @@
+package installer
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/ildx/merlin/internal/models"
+)
+
+func TestSelectionRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "work.toml")
+
+	if err := SaveSelection(path, &Selection{Formulae: []string{"git", "jq"}, Casks: []string{"raycast"}}); err != nil {
+		t.Fatalf("SaveSelection() error = %v", err)
+	}
+	// A mas selection saved to the same file keeps the brew one
+	if err := SaveSelection(path, &Selection{MAS: []string{"Xcode"}}); err != nil {
+		t.Fatalf("SaveSelection() error = %v", err)
+	}
+
+	s, err := LoadSelection(path)
+	if err != nil {
+		t.Fatalf("LoadSelection() error = %v", err)
+	}
+	if !slices.Equal(s.Formulae, []string{"git", "jq"}) || !slices.Equal(s.Casks, []string{"raycast"}) || !slices.Equal(s.MAS, []string{"Xcode"}) {
+		t.Errorf("selection = %+v", s)
+	}
+
+	if _, err := LoadSelection(filepath.Join(t.TempDir(), "missing.toml")); err == nil {
+		t.Error("expected an error for a missing selection file")
+	}
+}
+
+func TestSelectNamed(t *testing.T) {
+	packages := []models.BrewPackage{{Name: "git"}, {Name: "jq"}, {Name: "yq"}}
+	selected, unknown := SelectNamed(packages, []string{"yq", "git", "htop"})
+	if got := PackageNames(selected); !slices.Equal(got, []string{"git", "yq"}) {
+		t.Errorf("selected = %v", got)
+	}
+	if !slices.Equal(unknown, []string{"htop"}) {
+		t.Errorf("unknown = %v", unknown)
+	}
+
+	apps := []models.MASApp{{Name: "Xcode", ID: 497799835}, {Name: "Things 3", ID: 904280696}}
+	selectedApps, unknown := SelectNamedApps(apps, []string{"904280696", "Xcode", "Pages"})
+	if got := AppNames(selectedApps); !slices.Equal(got, []string{"Xcode", "Things 3"}) {
+		t.Errorf("selected apps = %v", got)
+	}
+	if !slices.Equal(unknown, []string{"Pages"}) {
+		t.Errorf("unknown apps = %v", unknown)
+	}
+}
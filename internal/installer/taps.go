@@ -0,0 +1,124 @@
+package installer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/ildx/merlin/internal/models"
+)
+
+// TappedTaps returns the taps brew has cloned, as listed by "brew tap" run
+// through p (nil runs the real brew)
+func TappedTaps(p Provider) (map[string]bool, error) {
+	out, err := providerOrExec(p).Query("brew", "tap")
+	if err != nil {
+		return nil, fmt.Errorf("brew tap: %w", err)
+	}
+	tapped := make(map[string]bool)
+	for _, name := range strings.Fields(string(out)) {
+		tapped[strings.ToLower(name)] = true
+	}
+	return tapped, nil
+}
+
+// CheckTaps is the preflight for the declared taps brew hasn't cloned yet:
+// every variable in a tap's auth_env must be set, and its remote must answer
+// "git ls-remote" without prompting for credentials. The error lists each
+// tap that fails and what to do about it, so installs stop before brew fails
+// halfway with a clone error. Commands run through p (nil runs the real
+// brew and git).
+func CheckTaps(p Provider, taps []models.BrewTap) error {
+	if len(taps) == 0 {
+		return nil
+	}
+	p = providerOrExec(p)
+	tapped, err := TappedTaps(p)
+	if err != nil {
+		return err
+	}
+
+	var problems []string
+	for _, tap := range taps {
+		if tapped[strings.ToLower(tap.Name)] {
+			continue
+		}
+		if missing := unsetEnv(tap.AuthEnv); len(missing) > 0 {
+			problems = append(problems, fmt.Sprintf("tap %s: %s not set (listed in its auth_env)",
+				tap.Name, strings.Join(missing, ", ")))
+			continue
+		}
+		if err := lsRemote(p, tap.Remote()); err != nil {
+			problems = append(problems, fmt.Sprintf("tap %s can't be reached at %s (%v); check your git credentials or SSH key",
+				tap.Name, tap.Remote(), err))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("taps not accessible:\n  • %s", strings.Join(problems, "\n  • "))
+	}
+	return nil
+}
+
+// TapAll runs brew tap for the declared taps brew hasn't cloned yet, with
+// their url when given, and returns the names tapped. Commands run through
+// p (nil runs the real brew); with dryRun nothing runs and the names that
+// would be tapped are returned.
+func TapAll(p Provider, taps []models.BrewTap, dryRun, verbose bool, w io.Writer) ([]string, error) {
+	if len(taps) == 0 {
+		return nil, nil
+	}
+	p = providerOrExec(p)
+	tapped, err := TappedTaps(p)
+	if err != nil {
+		return nil, err
+	}
+	var added []string
+	for _, tap := range taps {
+		if tapped[strings.ToLower(tap.Name)] {
+			continue
+		}
+		if !dryRun {
+			args := []string{"tap", tap.Name}
+			if tap.URL != "" {
+				args = append(args, tap.URL)
+			}
+			if out, err := p.Install(verbose, w, "brew", args...); err != nil {
+				return added, fmt.Errorf("brew tap %s: %w\n%s", tap.Name, err, out)
+			}
+		}
+		tapped[strings.ToLower(tap.Name)] = true
+		added = append(added, tap.Name)
+	}
+	return added, nil
+}
+
+// unsetEnv returns the names in names whose variable is unset or empty
+func unsetEnv(names []string) []string {
+	var missing []string
+	for _, name := range names {
+		if os.Getenv(name) == "" {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// lsRemote checks git can list url's refs without asking for a password or
+// SSH passphrase, which would hang a batch install
+func lsRemote(p Provider, url string) error {
+	args := []string{"GIT_TERMINAL_PROMPT=0"}
+	if os.Getenv("GIT_SSH_COMMAND") == "" {
+		args = append(args, "GIT_SSH_COMMAND=ssh -o BatchMode=yes")
+	}
+	args = append(args, "git", "ls-remote", "--heads", url)
+	_, err := p.Query("env", args...)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+		line, _, _ := strings.Cut(strings.TrimSpace(string(exitErr.Stderr)), "\n")
+		return errors.New(line)
+	}
+	return err
+}
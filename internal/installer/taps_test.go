@@ -0,0 +1,88 @@
+package installer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ildx/merlin/internal/models"
+)
+
+// tapProvider lists tapped as "brew tap", lets git ls-remote reach only the
+// remotes in reachable and records brew tap commands
+type tapProvider struct {
+	tapped    []string
+	reachable map[string]bool
+	taps      []string
+}
+
+func (p *tapProvider) Query(name string, args ...string) ([]byte, error) {
+	switch {
+	case name == "brew" && len(args) == 1 && args[0] == "tap":
+		return []byte(strings.Join(p.tapped, "\n")), nil
+	case name == "env" && args[len(args)-3] == "ls-remote":
+		if p.reachable[args[len(args)-1]] {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("exit status 128")
+	}
+	return nil, fmt.Errorf("unexpected %s %v", name, args)
+}
+
+func (p *tapProvider) Install(echo bool, w io.Writer, name string, args ...string) (string, error) {
+	p.taps = append(p.taps, strings.Join(args, " "))
+	return "", nil
+}
+
+func TestCheckTaps(t *testing.T) {
+	t.Setenv("ACME_TOKEN", "secret")
+	t.Setenv("MISSING_TOKEN", "")
+	p := &tapProvider{
+		tapped:    []string{"homebrew/core", "acme/cloned"},
+		reachable: map[string]bool{"https://github.com/acme/homebrew-tools": true},
+	}
+
+	ok := []models.BrewTap{
+		{Name: "acme/cloned", AuthEnv: []string{"MISSING_TOKEN"}}, // already tapped: not checked
+		{Name: "acme/tools", AuthEnv: []string{"ACME_TOKEN"}},
+	}
+	if err := CheckTaps(p, ok); err != nil {
+		t.Errorf("CheckTaps() = %v, want nil", err)
+	}
+
+	err := CheckTaps(p, []models.BrewTap{
+		{Name: "acme/secret", AuthEnv: []string{"ACME_TOKEN", "MISSING_TOKEN"}},
+		{Name: "acme/internal", URL: "git@git.acme.dev:brew/internal.git"},
+	})
+	if err == nil {
+		t.Fatal("CheckTaps() = nil, want an error for both taps")
+	}
+	for _, want := range []string{"acme/secret: MISSING_TOKEN not set", "acme/internal can't be reached at git@git.acme.dev:brew/internal.git"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+}
+
+func TestTapAll(t *testing.T) {
+	p := &tapProvider{tapped: []string{"acme/cloned"}}
+	taps := []models.BrewTap{
+		{Name: "acme/cloned"},
+		{Name: "acme/tools"},
+		{Name: "acme/internal", URL: "git@git.acme.dev:brew/internal.git"},
+	}
+
+	added, err := TapAll(p, taps, true, false, io.Discard)
+	if err != nil || strings.Join(added, ",") != "acme/tools,acme/internal" || len(p.taps) != 0 {
+		t.Errorf("dry run: added %v, err %v, ran %v", added, err, p.taps)
+	}
+
+	if _, err := TapAll(p, taps, false, false, io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	want := "tap acme/tools,tap acme/internal git@git.acme.dev:brew/internal.git"
+	if got := strings.Join(p.taps, ","); got != want {
+		t.Errorf("ran %q, want %q", got, want)
+	}
+}
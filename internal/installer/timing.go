@@ -0,0 +1,36 @@
+package installer
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ildx/merlin/internal/metrics"
+)
+
+// InstallTimings collects the durations of the packages that were actually
+// attempted; already-installed and dry-run results are left out. Failed
+// packages count towards the time but not the number installed.
+func InstallTimings(results ...[]*InstallResult) *metrics.Timings {
+	timings := &metrics.Timings{}
+	for _, group := range results {
+		for _, result := range group {
+			if result.Attempts == 0 {
+				continue
+			}
+			count := 0
+			if result.Success {
+				count = 1
+			}
+			timings.Add(result.Package, count, result.Duration)
+		}
+	}
+	return timings
+}
+
+// printInstallTimings writes the timing line of an installation summary
+func printInstallTimings(output io.Writer, results ...[]*InstallResult) {
+	timings := InstallTimings(results...)
+	if len(timings.Items) > 0 {
+		fmt.Fprintf(output, "\n⏱  %s\n", timings.Summary("installed", "package(s)"))
+	}
+}
@@ -0,0 +1,121 @@
+package installer
+
+import (
+	"github.com/ildx/merlin/internal/models"
+)
+
+// ToolPackages are the packages one tool needs, resolved against brew.toml
+// and mas.toml (see ResolveToolPackages)
+type ToolPackages struct {
+	Formulae []models.BrewPackage
+	Casks    []models.BrewPackage
+	Apps     []models.MASApp
+
+	// Unresolved names a dependency matching no package, or a [packages] mas
+	// entry missing from mas.toml (its App Store id is unknown)
+	Unresolved []string
+}
+
+// Empty reports whether the tool needs no packages
+func (p *ToolPackages) Empty() bool {
+	return len(p.Formulae) == 0 && len(p.Casks) == 0 && len(p.Apps) == 0
+}
+
+// ResolveToolPackages collects the packages of a tool's dependencies and its
+// [packages] block. Dependencies are matched by name against brew.toml's
+// formulae and casks, then mas.toml's apps. [packages] formulae and casks
+// missing from brew.toml are installed by name. brew or mas may be nil.
+func ResolveToolPackages(tool *models.ToolMerlinConfig, brew *models.BrewConfig, mas *models.MASConfig) *ToolPackages {
+	if brew == nil {
+		brew = &models.BrewConfig{}
+	}
+	if mas == nil {
+		mas = &models.MASConfig{}
+	}
+	p := &ToolPackages{}
+	seen := make(map[string]bool)
+	addBrew := func(list *[]models.BrewPackage, kind string, pkg models.BrewPackage) {
+		if !seen[kind+"/"+pkg.Name] {
+			seen[kind+"/"+pkg.Name] = true
+			*list = append(*list, pkg)
+		}
+	}
+	addApp := func(app models.MASApp) {
+		if !seen["mas/"+app.Name] {
+			seen["mas/"+app.Name] = true
+			p.Apps = append(p.Apps, app)
+		}
+	}
+
+	for _, dep := range tool.Tool.Dependencies {
+		if pkg := findBrewPackage(brew.Formulae, dep); pkg != nil {
+			addBrew(&p.Formulae, "formula", *pkg)
+		} else if pkg := findBrewPackage(brew.Casks, dep); pkg != nil {
+			addBrew(&p.Casks, "cask", *pkg)
+		} else if app := mas.FindByName(dep); app != nil {
+			addApp(*app)
+		} else {
+			p.Unresolved = append(p.Unresolved, dep)
+		}
+	}
+
+	for _, name := range tool.Packages.Formulae {
+		pkg := models.BrewPackage{Name: name}
+		if found := findBrewPackage(brew.Formulae, name); found != nil {
+			pkg = *found
+		}
+		addBrew(&p.Formulae, "formula", pkg)
+	}
+	for _, name := range tool.Packages.Casks {
+		pkg := models.BrewPackage{Name: name}
+		if found := findBrewPackage(brew.Casks, name); found != nil {
+			pkg = *found
+		}
+		addBrew(&p.Casks, "cask", pkg)
+	}
+	for _, name := range tool.Packages.MAS {
+		if app := mas.FindByName(name); app != nil {
+			addApp(*app)
+		} else {
+			p.Unresolved = append(p.Unresolved, name)
+		}
+	}
+	return p
+}
+
+// ForHost drops the packages whose when condition host doesn't match and
+// returns their names
+func (p *ToolPackages) ForHost(host models.Host) (skipped []string) {
+	keep := func(pkgs []models.BrewPackage) []models.BrewPackage {
+		kept := pkgs[:0]
+		for _, pkg := range pkgs {
+			if pkg.When.Matches(host) {
+				kept = append(kept, pkg)
+			} else {
+				skipped = append(skipped, pkg.Name)
+			}
+		}
+		return kept
+	}
+	p.Formulae = keep(p.Formulae)
+	p.Casks = keep(p.Casks)
+	apps := p.Apps[:0]
+	for _, app := range p.Apps {
+		if app.When.Matches(host) {
+			apps = append(apps, app)
+		} else {
+			skipped = append(skipped, app.Name)
+		}
+	}
+	p.Apps = apps
+	return skipped
+}
+
+func findBrewPackage(packages []models.BrewPackage, name string) *models.BrewPackage {
+	for i := range packages {
+		if packages[i].Name == name {
+			return &packages[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package installer
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/ildx/merlin/internal/models"
+)
+
+func TestResolveToolPackages(t *testing.T) {
+	brew := &models.BrewConfig{
+		Formulae: []models.BrewPackage{{Name: "yq", Category: "cli"}, {Name: "jq"}},
+		Casks:    []models.BrewPackage{{Name: "karabiner-elements", Arch: "arm64"}},
+	}
+	mas := &models.MASConfig{Apps: []models.MASApp{{Name: "Xcode", ID: 497799835}}}
+	tool := &models.ToolMerlinConfig{
+		Tool: models.ToolInfo{Name: "karabiner", Dependencies: []string{"brew", "yq", "karabiner-elements", "Xcode", "yq"}},
+		Packages: models.ToolPackages{
+			Formulae: []string{"jq", "goku"},
+			MAS:      []string{"Unknown App"},
+		},
+	}
+
+	p := ResolveToolPackages(tool, brew, mas)
+
+	names := func(pkgs []models.BrewPackage) []string {
+		var out []string
+		for _, pkg := range pkgs {
+			out = append(out, pkg.Name)
+		}
+		return out
+	}
+	if got := names(p.Formulae); !slices.Equal(got, []string{"yq", "jq", "goku"}) {
+		t.Errorf("formulae = %v", got)
+	}
+	if p.Formulae[0].Category != "cli" {
+		t.Error("formulae from brew.toml should keep their settings")
+	}
+	if len(p.Casks) != 1 || p.Casks[0].Arch != "arm64" {
+		t.Errorf("casks = %+v", p.Casks)
+	}
+	if len(p.Apps) != 1 || p.Apps[0].ID != 497799835 {
+		t.Errorf("apps = %+v", p.Apps)
+	}
+	if !slices.Equal(p.Unresolved, []string{"brew", "Unknown App"}) {
+		t.Errorf("unresolved = %v", p.Unresolved)
+	}
+
+	if empty := ResolveToolPackages(&models.ToolMerlinConfig{}, nil, nil); !empty.Empty() {
+		t.Errorf("tool without dependencies should need no packages, got %+v", empty)
+	}
+}
+
+func TestToolPackagesForHost(t *testing.T) {
+	work := &models.Condition{Profile: models.StringList{"work"}}
+	p := &ToolPackages{
+		Formulae: []models.BrewPackage{{Name: "jq"}, {Name: "awscli", When: work}},
+		Casks:    []models.BrewPackage{{Name: "slack", When: work}},
+		Apps:     []models.MASApp{{Name: "Xcode"}},
+	}
+	skipped := p.ForHost(models.Host{Profile: "personal"})
+	if !slices.Equal(skipped, []string{"awscli", "slack"}) {
+		t.Errorf("skipped = %v", skipped)
+	}
+	if len(p.Formulae) != 1 || p.Formulae[0].Name != "jq" || len(p.Casks) != 0 || len(p.Apps) != 1 {
+		t.Errorf("packages = %+v", p)
+	}
+}
@@ -0,0 +1,83 @@
+package installer
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"path"
+	"strings"
+
+	"github.com/ildx/merlin/internal/models"
+	"github.com/ildx/merlin/internal/system"
+)
+
+// BrewUpdate refreshes Homebrew's formula and cask definitions
+func BrewUpdate(verbose bool, output io.Writer) error {
+	out, err := exec.Command(system.BrewPath(), "update").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("brew update: %w\n%s", err, out)
+	}
+	if verbose {
+		fmt.Fprint(output, string(out))
+	}
+	return nil
+}
+
+// OutdatedDeclared returns the installed formulae and casks with a newer
+// version available that config declares, as of the last brew update
+func OutdatedDeclared(config *models.BrewConfig) (formulae, casks []string, err error) {
+	out, err := exec.Command(system.BrewPath(), "outdated", "--quiet", "--formula").Output()
+	if err != nil {
+		return nil, nil, fmt.Errorf("brew outdated: %w", err)
+	}
+	formulae = DeclaredOnly(strings.Fields(string(out)), config.Formulae)
+
+	out, err = exec.Command(system.BrewPath(), "outdated", "--quiet", "--cask").Output()
+	if err != nil {
+		return nil, nil, fmt.Errorf("brew outdated --cask: %w", err)
+	}
+	casks = DeclaredOnly(strings.Fields(string(out)), config.Casks)
+	return formulae, casks, nil
+}
+
+// UpgradeBrew upgrades formulae and casks, leaving every other outdated
+// package alone
+func UpgradeBrew(formulae, casks []string, verbose bool, output io.Writer) error {
+	for _, group := range []struct {
+		names []string
+		args  []string
+	}{
+		{formulae, []string{"upgrade", "--formula"}},
+		{casks, []string{"upgrade", "--cask"}},
+	} {
+		if len(group.names) == 0 {
+			continue
+		}
+		args := append(group.args, group.names...)
+		out, err := exec.Command(system.BrewPath(), args...).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("brew %s: %w\n%s", strings.Join(group.args, " "), err, out)
+		}
+		if verbose {
+			fmt.Fprint(output, string(out))
+		}
+	}
+	return nil
+}
+
+// DeclaredOnly keeps the names in names that packages declares. Tap
+// packages match by their short name as well ("user/tap/tool" and "tool").
+func DeclaredOnly(names []string, packages []models.BrewPackage) []string {
+	declared := make(map[string]bool)
+	for _, p := range packages {
+		declared[p.Name] = true
+		declared[path.Base(p.Name)] = true
+	}
+	var kept []string
+	for _, name := range names {
+		if declared[name] || declared[path.Base(name)] {
+			kept = append(kept, name)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,19 @@
+package installer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ildx/merlin/internal/models"
+)
+
+func TestDeclaredOnly(t *testing.T) {
+	declared := []models.BrewPackage{{Name: "git"}, {Name: "owner/tap/tool"}}
+	outdated := []string{"git", "htop", "tool"}
+	if got, want := DeclaredOnly(outdated, declared), []string{"git", "tool"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DeclaredOnly() = %v, want %v", got, want)
+	}
+	if got := DeclaredOnly(outdated, nil); len(got) != 0 {
+		t.Errorf("expected nothing declared, got %v", got)
+	}
+}
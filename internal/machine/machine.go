@@ -0,0 +1,174 @@
+// Package machine keeps the inventory of machines using a dotfiles
+// repository in .merlin-meta/machines.toml. The file is tracked in git, so
+// every machine sees the others and when each last synced.
+package machine
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/ildx/merlin/internal/config"
+	"github.com/ildx/merlin/internal/logger"
+	"github.com/ildx/merlin/internal/models"
+	"github.com/ildx/merlin/internal/parser"
+)
+
+// InventoryFile is the inventory's path relative to the repository root
+const InventoryFile = ".merlin-meta/machines.toml"
+
+// Machine is one registered machine
+type Machine struct {
+	Name       string    `toml:"name"` // short hostname, without .local
+	OS         string    `toml:"os"`
+	Arch       string    `toml:"arch"`
+	Profile    string    `toml:"profile,omitempty"`
+	Registered time.Time `toml:"registered"`
+	LastSync   time.Time `toml:"last_sync"` // last link or install run on the machine
+}
+
+// Inventory is the content of machines.toml
+type Inventory struct {
+	Machines []Machine `toml:"machine"`
+}
+
+// Load reads the inventory of the repository at root; a missing file is empty
+func Load(root string) (*Inventory, error) {
+	inv := &Inventory{}
+	data, err := os.ReadFile(filepath.Join(root, InventoryFile))
+	if os.IsNotExist(err) {
+		return inv, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", InventoryFile, err)
+	}
+	if err := toml.Unmarshal(data, inv); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", InventoryFile, err)
+	}
+	return inv, nil
+}
+
+// Save writes the inventory, machines sorted by name
+func (inv *Inventory) Save(root string) error {
+	sort.Slice(inv.Machines, func(i, j int) bool { return inv.Machines[i].Name < inv.Machines[j].Name })
+	path := filepath.Join(root, InventoryFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fmt.Fprintln(f, "# Machines using this repository; maintained by 'merlin machine register'")
+	return toml.NewEncoder(f).Encode(inv)
+}
+
+// Find returns the machine named name, or nil
+func (inv *Inventory) Find(name string) *Machine {
+	for i := range inv.Machines {
+		if inv.Machines[i].Name == name {
+			return &inv.Machines[i]
+		}
+	}
+	return nil
+}
+
+// Register adds m, or updates the machine with its name while keeping the
+// original registration time. It reports whether m was new.
+func (inv *Inventory) Register(m Machine, now time.Time) bool {
+	m.LastSync = now
+	if existing := inv.Find(m.Name); existing != nil {
+		m.Registered = existing.Registered
+		*existing = m
+		return false
+	}
+	m.Registered = now
+	inv.Machines = append(inv.Machines, m)
+	return true
+}
+
+// Remove drops the machine named name, reporting whether it was registered
+func (inv *Inventory) Remove(name string) bool {
+	for i, m := range inv.Machines {
+		if m.Name == name {
+			inv.Machines = append(inv.Machines[:i], inv.Machines[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Current describes this machine. profile is the profile it uses; empty
+// picks the profile matching the hostname, then the default one.
+func Current(rootConfig *models.RootMerlinConfig, profile string) (Machine, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return Machine{}, fmt.Errorf("failed to get hostname: %w", err)
+	}
+	if profile == "" && rootConfig != nil {
+		if p := rootConfig.GetProfileByHostname(hostname); p != nil {
+			profile = p.Name
+		} else if p := rootConfig.GetDefaultProfile(); p != nil {
+			profile = p.Name
+		}
+	}
+	return Machine{
+		Name:    ShortName(hostname),
+		OS:      runtime.GOOS,
+		Arch:    runtime.GOARCH,
+		Profile: profile,
+	}, nil
+}
+
+// CurrentHost returns what package when conditions are evaluated against on
+// this machine. The profile is the one the machine is registered with in the
+// repository's inventory, else the one Current picks; facts that can't be
+// determined stay empty.
+func CurrentHost(repo *config.DotfilesRepo) models.Host {
+	rootConfig, err := parser.ParseRootMerlinTOML(repo.GetRootMerlinConfig())
+	if err != nil {
+		logger.Debug("root config not loaded for when conditions", "error", err)
+	}
+	hostname, _ := os.Hostname()
+	m, err := Current(rootConfig, "")
+	if err != nil {
+		logger.Debug("machine not identified for when conditions", "error", err)
+	}
+	if inv, err := Load(repo.Root); err == nil {
+		if registered := inv.Find(m.Name); registered != nil && registered.Profile != "" {
+			m.Profile = registered.Profile
+		}
+	}
+	return models.Host{OS: runtime.GOOS, Arch: runtime.GOARCH, Hostname: hostname, Profile: m.Profile}
+}
+
+// ShortName strips the domain macOS adds to hostnames (e.g. "mbp.local")
+func ShortName(hostname string) string {
+	name, _, _ := strings.Cut(hostname, ".")
+	return name
+}
+
+// TouchCurrent stamps this machine's last sync when it is registered in the
+// repository at root, returning whether the inventory changed
+func TouchCurrent(root string, now time.Time) (bool, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return false, err
+	}
+	inv, err := Load(root)
+	if err != nil {
+		return false, err
+	}
+	m := inv.Find(ShortName(hostname))
+	if m == nil {
+		return false, nil
+	}
+	m.LastSync = now
+	return true, inv.Save(root)
+}
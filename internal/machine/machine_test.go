@@ -0,0 +1,70 @@
+package machine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ildx/merlin/internal/models"
+)
+
+func TestInventoryRoundTrip(t *testing.T) {
+	root := t.TempDir()
+
+	inv, err := Load(root)
+	if err != nil || len(inv.Machines) != 0 {
+		t.Fatalf("Load() of a repo without inventory = %+v, %v", inv, err)
+	}
+
+	first := time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)
+	if !inv.Register(Machine{Name: "mbp", OS: "darwin", Arch: "arm64", Profile: "personal"}, first) {
+		t.Fatal("expected mbp to be new")
+	}
+	inv.Register(Machine{Name: "imac", OS: "darwin", Arch: "amd64"}, first)
+
+	later := first.Add(48 * time.Hour)
+	if inv.Register(Machine{Name: "mbp", OS: "darwin", Arch: "arm64", Profile: "work"}, later) {
+		t.Fatal("expected re-registering mbp to update it")
+	}
+	if err := inv.Save(root); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Load(root)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(loaded.Machines) != 2 || loaded.Machines[0].Name != "imac" {
+		t.Fatalf("machines = %+v, want imac and mbp sorted by name", loaded.Machines)
+	}
+	mbp := loaded.Find("mbp")
+	if mbp.Profile != "work" || !mbp.Registered.Equal(first) || !mbp.LastSync.Equal(later) {
+		t.Errorf("mbp = %+v, want profile updated, registration kept and sync bumped", mbp)
+	}
+
+	if !loaded.Remove("imac") || loaded.Remove("imac") || loaded.Find("imac") != nil {
+		t.Error("Remove() should drop imac once")
+	}
+}
+
+func TestCurrentProfile(t *testing.T) {
+	root := &models.RootMerlinConfig{Profiles: []models.Profile{
+		{Name: "personal", Default: true},
+		{Name: "work", Hostname: "no-such-host"},
+	}}
+	m, err := Current(root, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Profile != "personal" || m.Name == "" {
+		t.Errorf("Current() = %+v, want the default profile", m)
+	}
+	if m, _ := Current(root, "work"); m.Profile != "work" {
+		t.Errorf("explicit profile ignored: %+v", m)
+	}
+}
+
+func TestShortName(t *testing.T) {
+	if got := ShortName("mbp.local"); got != "mbp" {
+		t.Errorf("ShortName() = %q, want mbp", got)
+	}
+}
@@ -0,0 +1,167 @@
+package machine
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ildx/merlin/internal/state"
+)
+
+// SnapshotDir holds each machine's published snapshot, relative to the
+// repository root
+const SnapshotDir = ".merlin-meta/machines"
+
+// Snapshot is the package and link state a machine published for the others
+// to compare against (see Compare)
+type Snapshot struct {
+	Machine    string    `json:"machine"`
+	TakenAt    time.Time `json:"taken_at"`
+	Formulae   []string  `json:"formulae"`
+	Casks      []string  `json:"casks"`
+	MASApps    []string  `json:"mas_apps"`   // App Store ids
+	Extensions []string  `json:"extensions"` // editor:id
+	Links      []string  `json:"links"`      // ~-relative symlinks into the repository
+}
+
+// SnapshotPath returns where the snapshot of the named machine is stored
+func SnapshotPath(root, name string) string {
+	return filepath.Join(root, SnapshotDir, name+".json")
+}
+
+// NewSnapshot summarizes snap for publishing. Links keep only the symlinks
+// resolving into the repository at repoRoot, with home written as "~" so
+// machines with different user names compare equal.
+func NewSnapshot(name string, snap *state.SystemSnapshot, repoRoot, home string, now time.Time) *Snapshot {
+	s := &Snapshot{
+		Machine:    name,
+		TakenAt:    now,
+		Formulae:   sortedKeys(snap.BrewFormulae),
+		Casks:      sortedKeys(snap.BrewCasks),
+		MASApps:    sortedKeys(snap.MASApps),
+		Extensions: sortedKeys(snap.Extensions),
+		Links:      []string{},
+	}
+	for _, link := range snap.Symlinks {
+		if link.Broken || !within(link.TargetPath, repoRoot) {
+			continue
+		}
+		if rel, err := filepath.Rel(home, link.LinkPath); err == nil && !strings.HasPrefix(rel, "..") {
+			s.Links = append(s.Links, "~/"+filepath.ToSlash(rel))
+		} else {
+			s.Links = append(s.Links, link.LinkPath)
+		}
+	}
+	sort.Strings(s.Links)
+	return s
+}
+
+// SaveSnapshot publishes s in the repository at root
+func SaveSnapshot(root string, s *Snapshot) error {
+	path := SnapshotPath(root, s.Machine)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// LoadSnapshot reads the snapshot the named machine published
+func LoadSnapshot(root, name string) (*Snapshot, error) {
+	data, err := os.ReadFile(SnapshotPath(root, name))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("machine '%s' has not published a snapshot (run 'merlin machine publish' on it and commit %s)", name, SnapshotDir)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var s Snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot of %s: %w", name, err)
+	}
+	return &s, nil
+}
+
+// SetDiff is one kind of state compared between two machines
+type SetDiff struct {
+	Kind      string   `json:"kind"`       // formulae, casks, mas_apps, extensions, links
+	OnlyThere []string `json:"only_there"` // on the other machine but not here
+	OnlyHere  []string `json:"only_here"`  // here but not on the other machine
+}
+
+// Comparison is the result of Compare
+type Comparison struct {
+	Machine string    `json:"machine"`  // the other machine
+	TakenAt time.Time `json:"taken_at"` // when its snapshot was published
+	Sets    []SetDiff `json:"sets"`
+}
+
+// Empty reports whether both machines have the same state
+func (c *Comparison) Empty() bool {
+	for _, set := range c.Sets {
+		if len(set.OnlyThere) > 0 || len(set.OnlyHere) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Compare lists what differs between this machine (here) and another
+// machine's published snapshot (there)
+func Compare(here, there *Snapshot) *Comparison {
+	c := &Comparison{Machine: there.Machine, TakenAt: there.TakenAt}
+	for _, kind := range []struct {
+		name        string
+		here, there []string
+	}{
+		{"formulae", here.Formulae, there.Formulae},
+		{"casks", here.Casks, there.Casks},
+		{"mas_apps", here.MASApps, there.MASApps},
+		{"extensions", here.Extensions, there.Extensions},
+		{"links", here.Links, there.Links},
+	} {
+		c.Sets = append(c.Sets, SetDiff{
+			Kind:      kind.name,
+			OnlyThere: subtract(kind.there, kind.here),
+			OnlyHere:  subtract(kind.here, kind.there),
+		})
+	}
+	return c
+}
+
+// subtract returns the items of a missing from b, in a's order
+func subtract(a, b []string) []string {
+	in := make(map[string]bool, len(b))
+	for _, item := range b {
+		in[item] = true
+	}
+	out := []string{}
+	for _, item := range a {
+		if !in[item] {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// within reports whether path is dir or inside it
+func within(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, "../")
+}
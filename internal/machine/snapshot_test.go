@@ -0,0 +1,55 @@
+package machine
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ildx/merlin/internal/state"
+)
+
+func TestSnapshotCompare(t *testing.T) {
+	root := t.TempDir()
+	home := "/Users/me"
+	repo := filepath.Join(home, "dotfiles")
+	snap := &state.SystemSnapshot{
+		BrewFormulae: map[string]bool{"git": true, "jq": true},
+		BrewCasks:    map[string]bool{"firefox": true},
+		Symlinks: []state.SymlinkEntry{
+			{LinkPath: filepath.Join(home, ".zshrc"), TargetPath: filepath.Join(repo, "config/zsh/config/.zshrc")},
+			{LinkPath: filepath.Join(home, ".config/other"), TargetPath: "/opt/other"},
+			{LinkPath: filepath.Join(home, ".config/gone"), TargetPath: filepath.Join(repo, "gone"), Broken: true},
+		},
+	}
+	desktop := NewSnapshot("desktop", snap, repo, home, time.Now())
+	if !slices.Equal(desktop.Links, []string{"~/.zshrc"}) {
+		t.Errorf("Links = %v, want only the working link into the repo", desktop.Links)
+	}
+	if err := SaveSnapshot(root, desktop); err != nil {
+		t.Fatalf("SaveSnapshot() error = %v", err)
+	}
+	there, err := LoadSnapshot(root, "desktop")
+	if err != nil {
+		t.Fatalf("LoadSnapshot() error = %v", err)
+	}
+	if _, err := LoadSnapshot(root, "laptop"); err == nil {
+		t.Error("expected an error for a machine without snapshot")
+	}
+
+	here := &Snapshot{Machine: "laptop", Formulae: []string{"git", "wget"}, Casks: []string{"firefox"}}
+	c := Compare(here, there)
+	if c.Empty() {
+		t.Fatal("expected differences")
+	}
+	formulae := c.Sets[0]
+	if !slices.Equal(formulae.OnlyThere, []string{"jq"}) || !slices.Equal(formulae.OnlyHere, []string{"wget"}) {
+		t.Errorf("formulae = %+v, want jq only there and wget only here", formulae)
+	}
+	if links := c.Sets[4]; !slices.Equal(links.OnlyThere, []string{"~/.zshrc"}) {
+		t.Errorf("links = %+v", links)
+	}
+	if !Compare(there, there).Empty() {
+		t.Error("a snapshot compared with itself should be empty")
+	}
+}
@@ -0,0 +1,121 @@
+// Package metrics times the items of a run (tools linked, packages installed,
+// scripts executed) and renders the one-line timing summary shown after them.
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Item is one timed unit of work
+type Item struct {
+	Name    string
+	Count   int // things handled by this item, e.g. links of a tool
+	Elapsed time.Duration
+}
+
+// Timings collects items in the order they finished
+type Timings struct {
+	Items []Item
+	start time.Time
+}
+
+// Start returns Timings whose Total is the wall time since now
+func Start() *Timings {
+	return &Timings{start: time.Now()}
+}
+
+// Add records a finished item
+func (t *Timings) Add(name string, count int, elapsed time.Duration) {
+	t.Items = append(t.Items, Item{Name: name, Count: count, Elapsed: elapsed})
+}
+
+// Track starts timing name; call the returned func with the item's count when it is done
+func (t *Timings) Track(name string) func(count int) {
+	began := time.Now()
+	return func(count int) {
+		t.Add(name, count, time.Since(began))
+	}
+}
+
+// Count returns the sum of the items' counts
+func (t *Timings) Count() int {
+	n := 0
+	for _, item := range t.Items {
+		n += item.Count
+	}
+	return n
+}
+
+// Total is the wall time since Start, or the sum of the items for Timings
+// built without it
+func (t *Timings) Total() time.Duration {
+	if !t.start.IsZero() {
+		return time.Since(t.start)
+	}
+	var total time.Duration
+	for _, item := range t.Items {
+		total += item.Elapsed
+	}
+	return total
+}
+
+// Slowest returns the longest item, if any
+func (t *Timings) Slowest() (Item, bool) {
+	if len(t.Items) == 0 {
+		return Item{}, false
+	}
+	slowest := t.Items[0]
+	for _, item := range t.Items[1:] {
+		if item.Elapsed > slowest.Elapsed {
+			slowest = item
+		}
+	}
+	return slowest, true
+}
+
+// Summary renders e.g. "linked 42 file(s) in 1.8s; slowest: nvim 0.9s". The
+// slowest item is named only when there is more than one.
+func (t *Timings) Summary(verb, noun string) string {
+	s := fmt.Sprintf("%s %d %s in %s", verb, t.Count(), noun, Format(t.Total()))
+	if slowest, ok := t.Slowest(); ok && len(t.Items) > 1 {
+		s += fmt.Sprintf("; slowest: %s %s", slowest.Name, Format(slowest.Elapsed))
+	}
+	return s
+}
+
+// MarshalJSON reports durations in milliseconds
+func (t *Timings) MarshalJSON() ([]byte, error) {
+	type item struct {
+		Name      string  `json:"name"`
+		Count     int     `json:"count"`
+		ElapsedMS float64 `json:"elapsed_ms"`
+	}
+	items := make([]item, 0, len(t.Items))
+	for _, it := range t.Items {
+		items = append(items, item{it.Name, it.Count, millis(it.Elapsed)})
+	}
+	return json.Marshal(struct {
+		TotalMS float64 `json:"total_ms"`
+		Items   []item  `json:"items"`
+	}{millis(t.Total()), items})
+}
+
+// Format renders a duration for summaries: "412µs", "850ms", "1.8s", "2m5s"
+func Format(d time.Duration) string {
+	switch {
+	case d < time.Millisecond:
+		return d.Round(time.Microsecond).String()
+	case d < time.Second:
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	case d < time.Minute:
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	default:
+		return d.Round(time.Second).String()
+	}
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestSummary(t *testing.T) {
+	timings := &Timings{}
+	timings.Add("zsh", 12, 300*time.Millisecond)
+	timings.Add("nvim", 30, 900*time.Millisecond)
+	timings.Add("git", 0, 600*time.Millisecond)
+
+	if got := timings.Total(); got != 1800*time.Millisecond {
+		t.Errorf("Total() = %v, want 1.8s", got)
+	}
+	want := "linked 42 file(s) in 1.8s; slowest: nvim 900ms"
+	if got := timings.Summary("linked", "file(s)"); got != want {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+}
+
+func TestSummarySingleItem(t *testing.T) {
+	timings := &Timings{}
+	timings.Add("nvim", 3, 2500*time.Millisecond)
+
+	want := "linked 3 file(s) in 2.5s"
+	if got := timings.Summary("linked", "file(s)"); got != want {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+}
+
+func TestTrack(t *testing.T) {
+	timings := Start()
+	done := timings.Track("brew")
+	done(2)
+
+	if len(timings.Items) != 1 || timings.Items[0].Name != "brew" || timings.Items[0].Count != 2 {
+		t.Fatalf("Items = %+v", timings.Items)
+	}
+	if timings.Total() < timings.Items[0].Elapsed {
+		t.Error("wall-clock Total() must cover the tracked items")
+	}
+}
+
+func TestFormat(t *testing.T) {
+	cases := map[time.Duration]string{
+		412 * time.Microsecond:  "412µs",
+		850 * time.Millisecond:  "850ms",
+		1840 * time.Millisecond: "1.8s",
+		125 * time.Second:       "2m5s",
+	}
+	for d, want := range cases {
+		if got := Format(d); got != want {
+			t.Errorf("Format(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	timings := &Timings{}
+	timings.Add("nvim", 30, 1500*time.Microsecond)
+
+	data, err := json.Marshal(timings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"total_ms":1.5,"items":[{"name":"nvim","count":30,"elapsed_ms":1.5}]}`
+	if string(data) != want {
+		t.Errorf("json = %s, want %s", data, want)
+	}
+}
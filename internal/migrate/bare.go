@@ -0,0 +1,83 @@
+package migrate
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// homeFileTools assigns well-known top-level dotfiles to a tool
+var homeFileTools = map[string]string{
+	".zshrc": "zsh", ".zshenv": "zsh", ".zprofile": "zsh", ".zlogin": "zsh",
+	".bashrc": "bash", ".bash_profile": "bash", ".profile": "bash",
+	".gitconfig": "git", ".gitignore_global": "git", ".gitmessage": "git",
+	".vimrc": "vim", ".tmux.conf": "tmux", ".inputrc": "readline",
+}
+
+// fallbackTool collects dotfiles that don't belong to a recognizable tool
+const fallbackTool = "home"
+
+// AnalyzeBare maps the files tracked by a bare repository whose work tree is
+// homeDir (the `git --git-dir=~/.dotfiles --work-tree=~` pattern). Files under
+// ~/.config/<dir> become tool <dir>; known dotfiles map to their tool and the
+// rest go to a "home" tool.
+func AnalyzeBare(gitDir, homeDir string) (*Plan, error) {
+	out, err := exec.Command("git", "--git-dir", gitDir, "--work-tree", homeDir, "ls-files", "--stage").Output()
+	if err != nil {
+		return nil, fmt.Errorf("list tracked files in %s: %w", gitDir, err)
+	}
+
+	plan := &Plan{}
+	var entries []Entry
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	for scanner.Scan() {
+		// "<mode> <object> <stage>\t<path>"
+		meta, rel, ok := strings.Cut(scanner.Text(), "\t")
+		if !ok {
+			continue
+		}
+		source := filepath.Join(homeDir, filepath.FromSlash(rel))
+
+		switch mode := strings.Fields(meta)[0]; mode {
+		case "160000":
+			plan.Skip(source, "git submodule")
+			continue
+		case "120000":
+			plan.Skip(source, "tracked symlink; recreate it as a link declaration by hand")
+			continue
+		}
+
+		info, err := os.Lstat(source)
+		if err != nil {
+			plan.Skip(source, "tracked but missing from the work tree")
+			continue
+		}
+		if !info.Mode().IsRegular() {
+			plan.Skip(source, "not a regular file")
+			continue
+		}
+		entries = append(entries, Entry{Tool: toolForPath(rel), HomeRel: rel, Source: source})
+	}
+
+	plan.Tools = BuildTools(entries, func(tool string) string {
+		return fmt.Sprintf("Migrated from bare repository %s", filepath.Base(gitDir))
+	})
+	return plan, nil
+}
+
+// toolForPath picks the tool a $HOME-relative path belongs to
+func toolForPath(rel string) string {
+	parts := strings.Split(rel, "/")
+	if parts[0] == ".config" && len(parts) >= 3 {
+		return parts[1]
+	}
+	if len(parts) == 1 {
+		if tool, ok := homeFileTools[parts[0]]; ok {
+			return tool
+		}
+	}
+	return fallbackTool
+}
@@ -0,0 +1,138 @@
+package migrate
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// chezmoiAttributes are source-state prefixes that only affect permissions or
+// directory handling; merlin links the file as-is.
+var chezmoiAttributes = []string{"private_", "readonly_", "empty_", "exact_", "executable_"}
+
+// chezmoiUnsupported are prefixes whose behavior merlin has no equivalent for
+var chezmoiUnsupported = map[string]string{
+	"encrypted_": "encrypted file; decrypt it with chezmoi and add it by hand",
+	"symlink_":   "chezmoi-managed symlink; declare it as a [[link]] by hand",
+	"run_":       "chezmoi script; move it to the tool's scripts/ and list it under [scripts]",
+	"modify_":    "modify script; merlin links whole files",
+	"create_":    "create-only file; merlin links whole files",
+	"remove_":    "remove entry; delete the target by hand",
+	"external_":  "external archive; fetch it with a setup script",
+}
+
+// DefaultChezmoiSource returns chezmoi's default source directory
+func DefaultChezmoiSource() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("get home directory: %w", err)
+	}
+	return filepath.Join(home, ".local", "share", "chezmoi"), nil
+}
+
+// AnalyzeChezmoi maps a chezmoi source directory. Attribute prefixes (dot_,
+// private_, executable_, ...) are decoded into target paths; templates,
+// encrypted files, scripts and other chezmoi-only features are reported.
+func AnalyzeChezmoi(sourceDir string) (*Plan, error) {
+	// .chezmoiroot moves the source state into a subdirectory
+	if data, err := os.ReadFile(filepath.Join(sourceDir, ".chezmoiroot")); err == nil {
+		sourceDir = filepath.Join(sourceDir, strings.TrimSpace(string(data)))
+	}
+	if _, err := os.Stat(sourceDir); err != nil {
+		return nil, fmt.Errorf("read chezmoi source: %w", err)
+	}
+
+	plan := &Plan{}
+	var entries []Entry
+	err := filepath.WalkDir(sourceDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == sourceDir {
+			return nil
+		}
+		name := d.Name()
+
+		// chezmoi ignores dot-prefixed source files except its own special files
+		if strings.HasPrefix(name, ".") {
+			if strings.HasPrefix(name, ".chezmoi") && name != ".chezmoiversion" && name != ".chezmoiroot" {
+				plan.Skip(p, "chezmoi configuration (ignore rules, data, externals, scripts) is not migrated")
+			}
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(sourceDir, p)
+		if err != nil {
+			return err
+		}
+		target, executable, reason := decodeChezmoiPath(filepath.ToSlash(rel))
+		if reason != "" {
+			plan.Skip(p, reason)
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			plan.Skip(p, "not a regular file")
+			return nil
+		}
+		entries = append(entries, Entry{Tool: toolForPath(target), HomeRel: target, Source: p, Executable: executable})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walk chezmoi source: %w", err)
+	}
+
+	plan.Tools = BuildTools(entries, func(tool string) string {
+		return "Migrated from chezmoi"
+	})
+	return plan, nil
+}
+
+// decodeChezmoiPath turns a source path ("dot_config/private_git/executable_hook")
+// into its target (".config/git/hook"). reason is set when the path uses a feature
+// merlin cannot represent.
+func decodeChezmoiPath(rel string) (target string, executable bool, reason string) {
+	parts := strings.Split(rel, "/")
+	for i, part := range parts {
+		for prefix, why := range chezmoiUnsupported {
+			if strings.HasPrefix(part, prefix) {
+				return "", false, why
+			}
+		}
+		if i == len(parts)-1 && strings.HasSuffix(part, ".tmpl") {
+			return "", false, "template; render it with 'chezmoi cat' and add the result by hand"
+		}
+
+		for stripped := true; stripped; {
+			stripped = false
+			for _, attr := range chezmoiAttributes {
+				if rest, ok := strings.CutPrefix(part, attr); ok {
+					part = rest
+					stripped = true
+					if attr == "executable_" && i == len(parts)-1 {
+						executable = true
+					}
+				}
+			}
+		}
+		if rest, ok := strings.CutPrefix(part, "dot_"); ok {
+			part = "." + rest
+		}
+		part = strings.TrimPrefix(part, "literal_")
+		if rest, ok := strings.CutSuffix(part, ".literal"); ok {
+			part = rest
+		}
+		parts[i] = part
+	}
+	return strings.Join(parts, "/"), executable, ""
+}
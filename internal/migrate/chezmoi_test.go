@@ -0,0 +1,59 @@
+package migrate
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDecodeChezmoiPath(t *testing.T) {
+	tests := []struct {
+		source     string
+		target     string
+		executable bool
+		unmapped   bool
+	}{
+		{"dot_zshrc", ".zshrc", false, false},
+		{"private_dot_config/git/config", ".config/git/config", false, false},
+		{"dot_local/bin/executable_hello", ".local/bin/hello", true, false},
+		{"exact_dot_vim/private_readonly_vimrc", ".vim/vimrc", false, false},
+		{"dot_gitconfig.tmpl", "", false, true},
+		{"run_once_install.sh", "", false, true},
+		{"encrypted_private_dot_netrc", "", false, true},
+	}
+	for _, tt := range tests {
+		target, executable, reason := decodeChezmoiPath(tt.source)
+		if (reason != "") != tt.unmapped {
+			t.Errorf("%s: unmapped = %v (%q), want %v", tt.source, reason != "", reason, tt.unmapped)
+			continue
+		}
+		if target != tt.target || executable != tt.executable {
+			t.Errorf("%s: got (%q, %v), want (%q, %v)", tt.source, target, executable, tt.target, tt.executable)
+		}
+	}
+}
+
+func TestAnalyzeChezmoi(t *testing.T) {
+	src := t.TempDir()
+	writeFile(t, filepath.Join(src, "dot_zshrc"), "")
+	writeFile(t, filepath.Join(src, "private_dot_config", "zellij", "config.kdl"), "")
+	writeFile(t, filepath.Join(src, "dot_local", "bin", "executable_hello"), "")
+	writeFile(t, filepath.Join(src, "dot_gitconfig.tmpl"), "")
+	writeFile(t, filepath.Join(src, ".chezmoiignore"), "")
+	writeFile(t, filepath.Join(src, ".git", "HEAD"), "")
+
+	plan, err := AnalyzeChezmoi(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"zsh", "zellij", "home"} {
+		if findTool(plan, name) == nil {
+			t.Errorf("expected tool %s, got %+v", name, plan.Tools)
+		}
+	}
+	if !findTool(plan, "home").Files["config/.local/bin/hello"].Executable {
+		t.Error("executable_ attribute should be preserved")
+	}
+	if len(plan.Issues) != 2 {
+		t.Errorf("expected template and .chezmoiignore to be reported, got %+v", plan.Issues)
+	}
+}
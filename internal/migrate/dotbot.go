@@ -0,0 +1,182 @@
+package migrate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AnalyzeDotbot maps the link directives of a dotbot install.conf.yaml (or
+// .json). Sources are resolved relative to the config file's directory, like
+// dotbot's base directory. shell, create, clean, glob/if options and plugin
+// directives have no merlin equivalent and are reported.
+func AnalyzeDotbot(configPath, homeDir string) (*Plan, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("read dotbot config: %w", err)
+	}
+
+	var doc any
+	if strings.HasSuffix(configPath, ".json") {
+		err = json.Unmarshal(data, &doc)
+	} else {
+		err = yaml.Unmarshal(data, &doc)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", filepath.Base(configPath), err)
+	}
+	tasks, ok := doc.([]any)
+	if !ok {
+		return nil, fmt.Errorf("parse %s: expected a list of directives", filepath.Base(configPath))
+	}
+
+	baseDir := filepath.Dir(configPath)
+	plan := &Plan{}
+	var entries []Entry
+	for _, task := range tasks {
+		directives, ok := task.(map[string]any)
+		if !ok {
+			continue
+		}
+		for _, name := range sortedKeys(directives) {
+			switch value := directives[name]; name {
+			case "link":
+				links, _ := value.(map[string]any)
+				for _, target := range sortedKeys(links) {
+					entries = append(entries, dotbotLink(plan, baseDir, homeDir, target, links[target])...)
+				}
+			case "defaults":
+				if defaults, ok := value.(map[string]any); ok {
+					if linkDefaults, ok := defaults["link"].(map[string]any); ok {
+						for _, opt := range []string{"glob", "if", "relative", "prefix"} {
+							if _, set := linkDefaults[opt]; set {
+								plan.Skip("defaults.link."+opt, "default link option is not supported; check the generated links")
+							}
+						}
+					}
+				}
+			case "shell":
+				commands, _ := value.([]any)
+				for _, c := range commands {
+					plan.Skip("shell: "+dotbotCommand(c), "move the command into a tool script under [scripts]")
+				}
+			case "create":
+				plan.Skip("create", "merlin creates parent directories when linking; create other directories in a script")
+			case "clean":
+				plan.Skip("clean", "dead-link cleanup is not supported; check 'merlin diff' for broken links")
+			default:
+				plan.Skip(name, "unsupported dotbot directive or plugin")
+			}
+		}
+	}
+
+	plan.Tools = BuildTools(entries, func(tool string) string {
+		return "Migrated from dotbot"
+	})
+	return plan, nil
+}
+
+// dotbotLink resolves one link entry into files under $HOME
+func dotbotLink(plan *Plan, baseDir, homeDir, target string, spec any) []Entry {
+	source := ""
+	switch v := spec.(type) {
+	case string:
+		source = v
+	case map[string]any:
+		if p, ok := v["path"].(string); ok {
+			source = p
+		}
+		for _, opt := range []string{"glob", "if", "relative", "prefix", "exclude"} {
+			if set, ok := v[opt]; ok && set != false {
+				plan.Skip(target, fmt.Sprintf("link option %q is not supported", opt))
+				return nil
+			}
+		}
+	}
+	if source == "" {
+		// dotbot's default source is the target's name without a leading dot
+		source = strings.TrimPrefix(path.Base(target), ".")
+	}
+
+	homeRel, ok := homeRelative(target, homeDir)
+	if !ok {
+		plan.Skip(target, "target is outside the home directory")
+		return nil
+	}
+
+	sourcePath := filepath.Join(baseDir, filepath.FromSlash(source))
+	info, err := os.Stat(sourcePath)
+	if err != nil {
+		plan.Skip(target, fmt.Sprintf("source %s does not exist", source))
+		return nil
+	}
+
+	if !info.IsDir() {
+		return []Entry{{Tool: toolForPath(homeRel), HomeRel: homeRel, Source: sourcePath}}
+	}
+
+	// Directory links become file-by-file links of everything inside
+	var entries []Entry
+	filepath.WalkDir(sourcePath, func(p string, d fs.DirEntry, err error) error {
+		switch {
+		case err != nil:
+			return nil
+		case d.IsDir() && d.Name() == ".git":
+			return filepath.SkipDir
+		case d.IsDir():
+			return nil
+		case !d.Type().IsRegular():
+			plan.Skip(p, "not a regular file")
+			return nil
+		}
+		rel, _ := filepath.Rel(sourcePath, p)
+		fileRel := homeRel + "/" + filepath.ToSlash(rel)
+		entries = append(entries, Entry{Tool: toolForPath(fileRel), HomeRel: fileRel, Source: p})
+		return nil
+	})
+	return entries
+}
+
+// homeRelative converts "~/x", "$HOME/x" or an absolute path under homeDir to "x"
+func homeRelative(target, homeDir string) (string, bool) {
+	for _, prefix := range []string{"~/", "$HOME/", "${HOME}/"} {
+		if rest, ok := strings.CutPrefix(target, prefix); ok {
+			return path.Clean(rest), true
+		}
+	}
+	if rel, err := filepath.Rel(homeDir, target); err == nil && filepath.IsAbs(target) && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel), true
+	}
+	return "", false
+}
+
+// dotbotCommand extracts the command from a shell entry (string, [cmd, desc] or {command: cmd})
+func dotbotCommand(c any) string {
+	switch v := c.(type) {
+	case string:
+		return v
+	case []any:
+		if len(v) > 0 {
+			return fmt.Sprint(v[0])
+		}
+	case map[string]any:
+		return fmt.Sprint(v["command"])
+	}
+	return fmt.Sprint(c)
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
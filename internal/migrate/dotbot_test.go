@@ -0,0 +1,88 @@
+package migrate
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Real configs share link options through anchors and write long shell
+// commands as block scalars
+func TestAnalyzeDotbotYAMLFeatures(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "zsh", "zshrc"), "")
+	writeFile(t, filepath.Join(dir, "zsh", "zprofile"), "")
+	config := filepath.Join(dir, "install.conf.yaml")
+	writeFile(t, config, `
+- defaults:
+    link: &opts
+      relink: true
+- link:
+    ~/.zshrc:
+      <<: *opts
+      path: zsh/zshrc
+    ~/.zprofile:
+      <<: *opts
+      path: zsh/zprofile
+- shell:
+  - command: |
+      git submodule update --init
+      ./install-extras.sh
+    description: Installing submodules
+`)
+
+	plan, err := AnalyzeDotbot(config, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	zsh := findTool(plan, "zsh")
+	if zsh == nil || len(zsh.Files) != 2 {
+		t.Fatalf("expected zsh with 2 files, got %+v", plan.Tools)
+	}
+	if len(plan.Issues) != 1 || !strings.Contains(plan.Issues[0].Path, "git submodule update --init\n./install-extras.sh") {
+		t.Errorf("expected the multi-line shell command as the only issue, got %+v", plan.Issues)
+	}
+}
+
+func TestAnalyzeDotbot(t *testing.T) {
+	dir := t.TempDir()
+	home := t.TempDir()
+	writeFile(t, filepath.Join(dir, "vimrc"), "")
+	writeFile(t, filepath.Join(dir, "zsh", "zshrc"), "")
+	writeFile(t, filepath.Join(dir, "git", "config"), "")
+	writeFile(t, filepath.Join(dir, "git", "ignore"), "")
+	config := filepath.Join(dir, "install.conf.yaml")
+	writeFile(t, config, `
+- link:
+    ~/.vimrc:
+    ~/.zshrc: zsh/zshrc
+    ~/.config/git:
+      path: git
+    ~/.config/*:
+      glob: true
+      path: config/*
+    /etc/hosts: hosts
+- shell:
+  - ./install-extras.sh
+- create:
+  - ~/Downloads
+`)
+
+	plan, err := AnalyzeDotbot(config, home)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"vim", "zsh", "git"} {
+		if findTool(plan, name) == nil {
+			t.Errorf("expected tool %s, got %+v", name, plan.Tools)
+		}
+	}
+	git := findTool(plan, "git")
+	if len(git.Files) != 2 || git.Links[0].Target != "{config_dir}/git" || !git.Links[0].Contents {
+		t.Errorf("unexpected git plan: %+v", git)
+	}
+	// glob link, target outside home, shell command, create
+	if len(plan.Issues) != 4 {
+		t.Errorf("expected 4 issues, got %+v", plan.Issues)
+	}
+}
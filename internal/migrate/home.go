@@ -0,0 +1,87 @@
+package migrate
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// AnalyzeHome maps dotfiles and config directories that live directly in
+// homeDir (what `merlin adopt` brings into the repo). Directories are walked
+// recursively; symlinks and .git directories are skipped. Every file goes to
+// tool, or to SuggestTool of its path when tool is empty.
+func AnalyzeHome(paths []string, homeDir, tool string) (*Plan, error) {
+	plan := &Plan{}
+	var entries []Entry
+	for _, p := range paths {
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			return nil, err
+		}
+		rel, err := filepath.Rel(homeDir, abs)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			return nil, fmt.Errorf("%s is not inside %s", p, homeDir)
+		}
+
+		err = filepath.WalkDir(abs, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			switch {
+			case d.Type()&fs.ModeSymlink != 0:
+				plan.Skip(path, "symlink; adopt what it points to instead")
+				return nil
+			case d.IsDir() && d.Name() == ".git":
+				plan.Skip(path, "git repository metadata")
+				return filepath.SkipDir
+			case d.IsDir():
+				return nil
+			case !d.Type().IsRegular():
+				plan.Skip(path, "not a regular file")
+				return nil
+			}
+			fileRel, _ := filepath.Rel(homeDir, path)
+			fileRel = filepath.ToSlash(fileRel)
+			name := tool
+			if name == "" {
+				name = SuggestTool(filepath.ToSlash(rel))
+			}
+			entries = append(entries, Entry{Tool: name, HomeRel: fileRel, Source: path})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	plan.Tools = BuildTools(entries, func(string) string {
+		return fmt.Sprintf("Adopted from %s", homeDir)
+	})
+	return plan, nil
+}
+
+// SuggestTool names the tool a $HOME-relative dotfile or directory belongs
+// to: ~/.config/<tool>, a well-known dotfile's tool, or the name without its
+// leading dot, extension and "rc" suffix (~/.wgetrc -> wget).
+func SuggestTool(rel string) string {
+	parts := strings.Split(rel, "/")
+	name := parts[len(parts)-1]
+	if parts[0] == ".config" && len(parts) >= 2 {
+		name = parts[1]
+	} else if tool, ok := homeFileTools[name]; ok {
+		return tool
+	}
+
+	name = strings.TrimPrefix(name, ".")
+	if ext := filepath.Ext(name); ext != "" && ext != name {
+		name = strings.TrimSuffix(name, ext)
+	}
+	if trimmed := strings.TrimSuffix(name, "rc"); len(trimmed) >= 2 {
+		name = trimmed
+	}
+	if name == "" {
+		return fallbackTool
+	}
+	return strings.ToLower(name)
+}
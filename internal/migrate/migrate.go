@@ -0,0 +1,253 @@
+// Package migrate converts dotfiles managed by other tools into merlin's
+// config/<tool>/config + merlin.toml layout.
+package migrate
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ildx/merlin/internal/models"
+)
+
+// Entry is one dotfile to bring into the repo
+type Entry struct {
+	Tool    string // Tool directory it belongs to
+	HomeRel string // Location relative to $HOME, slash-separated (e.g. ".config/zellij/config.kdl")
+	Source  string // Absolute path of the file to copy
+
+	Executable bool // Make the copy executable regardless of the source mode
+}
+
+// Issue is something a migration could not map automatically
+type Issue struct {
+	Path   string
+	Reason string
+}
+
+// ToolPlan is the generated layout for one tool
+type ToolPlan struct {
+	Name        string
+	Description string
+	Files       map[string]Entry // Repo path under the tool root -> file to copy
+	Links       []models.Link
+}
+
+// Plan is the result of analyzing a legacy setup
+type Plan struct {
+	Tools  []*ToolPlan
+	Issues []Issue
+}
+
+// Skip records an entry the migration could not map
+func (p *Plan) Skip(path, reason string) {
+	p.Issues = append(p.Issues, Issue{Path: path, Reason: reason})
+}
+
+// BuildTools groups entries into tool plans. Files under ~/.config/<dir> and other
+// nested directories become contents links (file-by-file, like stow); top-level
+// files become single file links.
+func BuildTools(entries []Entry, describe func(tool string) string) []*ToolPlan {
+	byTool := make(map[string][]Entry)
+	for _, e := range entries {
+		byTool[e.Tool] = append(byTool[e.Tool], e)
+	}
+
+	var tools []*ToolPlan
+	for _, name := range sortedToolNames(byTool) {
+		tools = append(tools, buildTool(name, byTool[name], describe(name)))
+	}
+	return tools
+}
+
+func buildTool(name string, entries []Entry, description string) *ToolPlan {
+	tool := &ToolPlan{Name: name, Description: description, Files: make(map[string]Entry)}
+
+	// Link roots: ".config/<dir>" or a top-level directory; "" for top-level files
+	roots := make(map[string][]Entry)
+	for _, e := range entries {
+		roots[linkRoot(e.HomeRel)] = append(roots[linkRoot(e.HomeRel)], e)
+	}
+
+	// A tool whose only content is ~/.config/<tool> keeps it directly in config/
+	ownRoot := ".config/" + name
+	flat := len(roots) == 1 && roots[ownRoot] != nil
+
+	keys := make([]string, 0, len(roots))
+	for k := range roots {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, root := range keys {
+		group := roots[root]
+		if root == "" {
+			sort.Slice(group, func(i, j int) bool { return group[i].HomeRel < group[j].HomeRel })
+			for _, e := range group {
+				repoPath := path.Join("config", strings.TrimPrefix(e.HomeRel, ".config/"))
+				tool.Files[repoPath] = e
+				tool.Links = append(tool.Links, models.Link{Source: repoPath, Target: homeTarget(e.HomeRel)})
+			}
+			continue
+		}
+
+		repoDir := path.Join("config", strings.TrimPrefix(root, ".config/"))
+		if flat {
+			repoDir = "config"
+		}
+		hidden := false
+		for _, e := range group {
+			rel := strings.TrimPrefix(e.HomeRel, root+"/")
+			tool.Files[path.Join(repoDir, rel)] = e
+			if strings.HasPrefix(path.Base(rel), ".") {
+				hidden = true
+			}
+		}
+		tool.Links = append(tool.Links, models.Link{
+			Source:        repoDir,
+			Target:        homeTarget(root),
+			Contents:      true,
+			IncludeHidden: hidden,
+		})
+	}
+	return tool
+}
+
+// linkRoot returns the directory a file is linked through, or "" for a single file link
+func linkRoot(homeRel string) string {
+	parts := strings.Split(homeRel, "/")
+	switch {
+	case parts[0] == ".config" && len(parts) >= 3:
+		return ".config/" + parts[1]
+	case parts[0] == ".config" || len(parts) == 1:
+		return ""
+	default:
+		return parts[0]
+	}
+}
+
+// homeTarget expresses a $HOME-relative path with merlin variables
+func homeTarget(homeRel string) string {
+	if rest, ok := strings.CutPrefix(homeRel, ".config/"); ok {
+		return "{config_dir}/" + rest
+	}
+	return "{home_dir}/" + homeRel
+}
+
+func sortedToolNames(m map[string][]Entry) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Write copies each tool's files into repoRoot/config/<tool> and writes its
+// merlin.toml. Tools that already exist in the repo are skipped and reported.
+// A minimal root merlin.toml is created when missing.
+func Write(plan *Plan, repoRoot string) error {
+	return WriteTools(plan, repoRoot, filepath.Join(repoRoot, "config"))
+}
+
+// WriteTools is Write for a repository whose tools live in toolsDir
+// (see [settings.layout])
+func WriteTools(plan *Plan, repoRoot, toolsDir string) error {
+	if err := os.MkdirAll(toolsDir, 0755); err != nil {
+		return fmt.Errorf("create tools directory: %w", err)
+	}
+
+	rootConfig := filepath.Join(repoRoot, "merlin.toml")
+	if _, err := os.Stat(rootConfig); os.IsNotExist(err) {
+		content := "[metadata]\nname = \"dotfiles\"\ndescription = \"Migrated to Merlin\"\n\n[settings]\nconflict_strategy = \"backup\"\n"
+		if err := os.WriteFile(rootConfig, []byte(content), 0644); err != nil {
+			return fmt.Errorf("write root merlin.toml: %w", err)
+		}
+	}
+
+	var written []*ToolPlan
+	for _, tool := range plan.Tools {
+		toolRoot := filepath.Join(toolsDir, tool.Name)
+		if _, err := os.Stat(toolRoot); err == nil {
+			plan.Skip(toolRoot, fmt.Sprintf("tool %s already exists in the repository", tool.Name))
+			continue
+		}
+
+		for repoPath, entry := range tool.Files {
+			if err := copyFile(entry, filepath.Join(toolRoot, filepath.FromSlash(repoPath))); err != nil {
+				return fmt.Errorf("%s: %w", tool.Name, err)
+			}
+		}
+		if err := os.WriteFile(filepath.Join(toolRoot, "merlin.toml"), []byte(ToolTOML(tool)), 0644); err != nil {
+			return fmt.Errorf("write %s/merlin.toml: %w", tool.Name, err)
+		}
+		written = append(written, tool)
+	}
+	plan.Tools = written
+	return nil
+}
+
+// ToolTOML renders a tool plan as a merlin.toml document
+func ToolTOML(tool *ToolPlan) string {
+	var sb strings.Builder
+	sb.WriteString("[tool]\n")
+	fmt.Fprintf(&sb, "name = %s\n", quote(tool.Name))
+	if tool.Description != "" {
+		fmt.Fprintf(&sb, "description = %s\n", quote(tool.Description))
+	}
+	for _, link := range tool.Links {
+		sb.WriteString("\n[[link]]\n")
+		fmt.Fprintf(&sb, "source = %s\n", quote(link.Source))
+		fmt.Fprintf(&sb, "target = %s\n", quote(link.Target))
+		if link.Contents {
+			sb.WriteString("contents = true\n")
+		}
+		if link.IncludeHidden {
+			sb.WriteString("include_hidden = true\n")
+		}
+	}
+	return sb.String()
+}
+
+// PrintReport summarizes the generated tools and anything left for manual review
+func PrintReport(w io.Writer, plan *Plan) {
+	for _, tool := range plan.Tools {
+		fmt.Fprintf(w, "  ✓ %s (%d files, %d links)\n", tool.Name, len(tool.Files), len(tool.Links))
+	}
+	if len(plan.Issues) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n⚠️  Not migrated automatically (%d):\n", len(plan.Issues))
+	for _, issue := range plan.Issues {
+		fmt.Fprintf(w, "  - %s: %s\n", issue.Path, issue.Reason)
+	}
+}
+
+// quote renders s as a TOML basic string
+func quote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`)
+	return `"` + r.Replace(s) + `"`
+}
+
+func copyFile(entry Entry, dst string) error {
+	info, err := os.Stat(entry.Source)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(entry.Source)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	mode := info.Mode().Perm()
+	if entry.Executable {
+		mode |= 0111
+	}
+	return os.WriteFile(dst, data, mode)
+}
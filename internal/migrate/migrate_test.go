@@ -0,0 +1,147 @@
+package migrate
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ildx/merlin/internal/parser"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func findTool(plan *Plan, name string) *ToolPlan {
+	for _, tool := range plan.Tools {
+		if tool.Name == name {
+			return tool
+		}
+	}
+	return nil
+}
+
+func TestAnalyzeStow(t *testing.T) {
+	stowDir := t.TempDir()
+	writeFile(t, filepath.Join(stowDir, "zsh", ".zshrc"), "export A=1")
+	writeFile(t, filepath.Join(stowDir, "zsh", "dot-zprofile"), "")
+	writeFile(t, filepath.Join(stowDir, "nvim", ".config", "nvim", "init.lua"), "")
+	writeFile(t, filepath.Join(stowDir, "nvim", ".config", "nvim", "lua", "plugins.lua"), "")
+	writeFile(t, filepath.Join(stowDir, "bin", ".local", "bin", "hello"), "")
+	writeFile(t, filepath.Join(stowDir, "bin", ".stow-local-ignore"), "README")
+	os.Symlink("/etc/hosts", filepath.Join(stowDir, "bin", ".local", "bin", "hosts"))
+
+	plan, err := AnalyzeStow(stowDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Tools) != 3 {
+		t.Fatalf("expected 3 tools, got %d", len(plan.Tools))
+	}
+
+	zsh := findTool(plan, "zsh")
+	if len(zsh.Links) != 2 || zsh.Links[0].Target != "{home_dir}/.zprofile" || zsh.Links[1].Source != "config/.zshrc" {
+		t.Errorf("unexpected zsh links: %+v", zsh.Links)
+	}
+
+	nvim := findTool(plan, "nvim")
+	if len(nvim.Links) != 1 {
+		t.Fatalf("expected one nvim link, got %+v", nvim.Links)
+	}
+	if l := nvim.Links[0]; l.Source != "config" || l.Target != "{config_dir}/nvim" || !l.Contents {
+		t.Errorf("unexpected nvim link: %+v", l)
+	}
+	if nvim.Files["config/lua/plugins.lua"].Source == "" {
+		t.Errorf("expected nested nvim file, got %v", nvim.Files)
+	}
+
+	bin := findTool(plan, "bin")
+	if l := bin.Links[0]; l.Source != "config/.local" || l.Target != "{home_dir}/.local" || !l.Contents {
+		t.Errorf("unexpected bin link: %+v", l)
+	}
+	if len(plan.Issues) != 2 {
+		t.Errorf("expected ignore file and symlink to be reported, got %+v", plan.Issues)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	stowDir := t.TempDir()
+	writeFile(t, filepath.Join(stowDir, "zsh", ".zshrc"), "export A=1")
+	writeFile(t, filepath.Join(stowDir, "git", ".config", "git", "config"), "[user]")
+
+	repo := t.TempDir()
+	writeFile(t, filepath.Join(repo, "config", "git", "merlin.toml"), "[tool]\nname = \"git\"\n")
+
+	plan, err := AnalyzeStow(stowDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Write(plan, repo); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(plan.Tools) != 1 || plan.Tools[0].Name != "zsh" {
+		t.Errorf("expected only zsh to be written, got %+v", plan.Tools)
+	}
+	if len(plan.Issues) != 1 || !strings.Contains(plan.Issues[0].Reason, "already exists") {
+		t.Errorf("expected existing git tool to be reported, got %+v", plan.Issues)
+	}
+
+	data, err := os.ReadFile(filepath.Join(repo, "config", "zsh", "config", ".zshrc"))
+	if err != nil || string(data) != "export A=1" {
+		t.Errorf("zshrc not copied: %q, %v", data, err)
+	}
+	toolConfig, err := parser.ParseToolMerlinTOML(filepath.Join(repo, "config", "zsh", "merlin.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if toolConfig.Tool.Name != "zsh" || len(toolConfig.Links) != 1 || toolConfig.Links[0].Target != "{home_dir}/.zshrc" {
+		t.Errorf("unexpected generated config: %+v", toolConfig)
+	}
+	if _, err := parser.ParseRootMerlinTOML(filepath.Join(repo, "merlin.toml")); err != nil {
+		t.Errorf("root merlin.toml not generated: %v", err)
+	}
+}
+
+func TestAnalyzeBare(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	home := t.TempDir()
+	gitDir := filepath.Join(t.TempDir(), "dotfiles.git")
+	writeFile(t, filepath.Join(home, ".zshrc"), "")
+	writeFile(t, filepath.Join(home, ".config", "zellij", "config.kdl"), "")
+	writeFile(t, filepath.Join(home, ".ssh", "config"), "")
+
+	git := func(args ...string) {
+		t.Helper()
+		args = append([]string{"--git-dir", gitDir, "--work-tree", home}, args...)
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	if out, err := exec.Command("git", "init", "--bare", "-q", gitDir).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, out)
+	}
+	git("add", ".zshrc", ".config/zellij/config.kdl", ".ssh/config")
+	os.Remove(filepath.Join(home, ".ssh", "config"))
+
+	plan, err := AnalyzeBare(gitDir, home)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if findTool(plan, "zsh") == nil || findTool(plan, "zellij") == nil {
+		t.Errorf("expected zsh and zellij tools, got %+v", plan.Tools)
+	}
+	if len(plan.Issues) != 1 || !strings.Contains(plan.Issues[0].Reason, "missing") {
+		t.Errorf("expected deleted file to be reported, got %+v", plan.Issues)
+	}
+}
@@ -0,0 +1,188 @@
+package migrate
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultScanDepth is how many directory levels below $HOME Scan looks
+// when ScanOptions.Depth is zero: top-level dotfiles plus ~/.config/<dir>
+const DefaultScanDepth = 2
+
+// DefaultScanIgnore lists what Scan never reports: caches, history and
+// state files, secrets, and the standard macOS folders. Patterns match an
+// entry's name or its $HOME-relative path (path.Match syntax).
+var DefaultScanIgnore = []string{
+	// Caches, package stores and application state
+	".cache", ".local", ".npm", ".cargo", ".rustup", ".gradle", ".m2", ".docker",
+	".Trash", ".vscode", ".cups", ".oh-my-zsh", ".zsh_sessions", ".bash_sessions",
+	".merlin", ".git", "node_modules",
+	// History and per-session files
+	"*_history", ".*history", ".lesshst", ".viminfo", ".zcompdump*", ".DS_Store",
+	".CFUserTextEncoding", ".localized",
+	// Secrets that must not end up in a repository
+	".ssh", ".gnupg", ".aws", ".kube", ".netrc", ".password-store",
+	// macOS user folders
+	"Library", "Applications", "Desktop", "Documents", "Downloads", "Movies",
+	"Music", "Pictures", "Public",
+}
+
+// ScanOptions configures Scan
+type ScanOptions struct {
+	HomeDir  string   // Directory to scan
+	RepoRoot string   // Dotfiles repository; symlinks into it are managed
+	Depth    int      // Directory levels to search (default DefaultScanDepth)
+	Ignore   []string // Patterns skipped on top of DefaultScanIgnore
+	Managed  []string // Declared link targets (absolute paths)
+}
+
+// Finding is one unmanaged dotfile or config directory
+type Finding struct {
+	Path  string `json:"path"`            // Absolute path
+	Rel   string `json:"rel"`             // Path relative to $HOME, slash-separated
+	IsDir bool   `json:"is_dir"`          // Directory rather than a single file
+	Files int    `json:"files,omitempty"` // Regular files inside a directory
+	Tool  string `json:"tool"`            // Suggested tool name (see SuggestTool)
+}
+
+// Scan walks opts.HomeDir for classic dotfiles (hidden entries) and
+// ~/.config/<dir> entries that merlin doesn't manage: not a symlink into the
+// repository, not a declared link target, and not a directory containing
+// one. Matches are not descended into. Findings are sorted by path.
+func Scan(opts ScanOptions) ([]Finding, error) {
+	if opts.Depth <= 0 {
+		opts.Depth = DefaultScanDepth
+	}
+	s := &scanner{
+		opts:    opts,
+		ignore:  append(append([]string{}, DefaultScanIgnore...), opts.Ignore...),
+		managed: make(map[string]bool),
+	}
+	for _, target := range opts.Managed {
+		s.managed[filepath.Clean(target)] = true
+	}
+	if opts.RepoRoot != "" {
+		s.repoRoot, _ = filepath.EvalSymlinks(opts.RepoRoot)
+	}
+
+	if err := s.walk(opts.HomeDir, "", 1); err != nil {
+		return nil, err
+	}
+	sort.Slice(s.findings, func(i, j int) bool { return s.findings[i].Rel < s.findings[j].Rel })
+	return s.findings, nil
+}
+
+type scanner struct {
+	opts     ScanOptions
+	ignore   []string
+	managed  map[string]bool
+	repoRoot string
+	findings []Finding
+}
+
+func (s *scanner) walk(dir, rel string, depth int) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if rel != "" && (os.IsPermission(err) || os.IsNotExist(err)) {
+			return nil // Unreadable subdirectories are not worth failing the scan
+		}
+		return err
+	}
+
+	for _, e := range entries {
+		entryRel := path.Join(rel, e.Name())
+		full := filepath.Join(dir, e.Name())
+		if s.ignored(e.Name(), entryRel) || full == s.opts.RepoRoot || s.intoRepo(full) {
+			continue
+		}
+
+		// ~/.config itself is a container: its children are the candidates
+		if entryRel == ".config" && e.IsDir() {
+			if depth < s.opts.Depth {
+				if err := s.walk(full, entryRel, depth+1); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if strings.HasPrefix(e.Name(), ".") || rel == ".config" {
+			if e.Type()&fs.ModeSymlink != 0 || s.containsManaged(full) {
+				continue
+			}
+			s.findings = append(s.findings, s.finding(full, entryRel, e))
+			continue
+		}
+
+		if e.IsDir() && depth < s.opts.Depth {
+			if err := s.walk(full, entryRel, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ignored reports whether an entry matches an ignore pattern by name or path
+func (s *scanner) ignored(name, rel string) bool {
+	for _, pattern := range s.ignore {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, rel); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// intoRepo reports whether path is a symlink resolving into the repository
+func (s *scanner) intoRepo(p string) bool {
+	if s.repoRoot == "" {
+		return false
+	}
+	info, err := os.Lstat(p)
+	if err != nil || info.Mode()&fs.ModeSymlink == 0 {
+		return false
+	}
+	resolved, err := filepath.EvalSymlinks(p)
+	if err != nil {
+		return false
+	}
+	return resolved == s.repoRoot || strings.HasPrefix(resolved, s.repoRoot+string(filepath.Separator))
+}
+
+// containsManaged reports whether p is a declared target or a directory
+// holding one (e.g. ~/.ssh when ~/.ssh/config is linked)
+func (s *scanner) containsManaged(p string) bool {
+	prefix := p + string(filepath.Separator)
+	for target := range s.managed {
+		if target == p || strings.HasPrefix(target, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *scanner) finding(full, rel string, e fs.DirEntry) Finding {
+	f := Finding{Path: full, Rel: rel, IsDir: e.IsDir(), Tool: SuggestTool(rel)}
+	if f.IsDir {
+		filepath.WalkDir(full, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if d.IsDir() && d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			if d.Type().IsRegular() {
+				f.Files++
+			}
+			return nil
+		})
+	}
+	return f
+}
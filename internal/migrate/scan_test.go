@@ -0,0 +1,118 @@
+package migrate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestScan(t *testing.T) {
+	home := t.TempDir()
+	repo := filepath.Join(home, "dotfiles")
+	writeFile(t, filepath.Join(repo, "config", "zsh", "config", ".zshrc"), "")
+	writeFile(t, filepath.Join(home, ".wgetrc"), "")
+	writeFile(t, filepath.Join(home, ".zsh_history"), "")
+	writeFile(t, filepath.Join(home, ".ssh", "id_ed25519"), "")
+	writeFile(t, filepath.Join(home, ".config", "nvim", "init.lua"), "")
+	writeFile(t, filepath.Join(home, ".config", "nvim", "lua", "plugins.lua"), "")
+	writeFile(t, filepath.Join(home, ".config", "starship.toml"), "")
+	writeFile(t, filepath.Join(home, ".config", "git", "config"), "")
+	writeFile(t, filepath.Join(home, ".android", "adbkey"), "")
+	writeFile(t, filepath.Join(home, "projects", ".envrc"), "")
+	writeFile(t, filepath.Join(home, "projects", "deep", ".tool-versions"), "")
+	if err := os.Symlink(filepath.Join(repo, "config", "zsh", "config", ".zshrc"), filepath.Join(home, ".zshrc")); err != nil {
+		t.Fatal(err)
+	}
+
+	findings, err := Scan(ScanOptions{
+		HomeDir:  home,
+		RepoRoot: repo,
+		Ignore:   []string{".android"},
+		Managed:  []string{filepath.Join(home, ".config", "git", "config")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		".wgetrc":               "wget",
+		".config/nvim":          "nvim",
+		".config/starship.toml": "starship",
+		"projects/.envrc":       "env",
+	}
+	if len(findings) != len(want) {
+		t.Fatalf("findings = %+v, want %v", findings, want)
+	}
+	for _, f := range findings {
+		tool, ok := want[f.Rel]
+		if !ok {
+			t.Errorf("unexpected finding %s", f.Rel)
+			continue
+		}
+		if f.Tool != tool {
+			t.Errorf("%s: tool = %s, want %s", f.Rel, f.Tool, tool)
+		}
+		if f.Rel == ".config/nvim" && (!f.IsDir || f.Files != 2) {
+			t.Errorf(".config/nvim: is_dir=%v files=%d, want a directory of 2 files", f.IsDir, f.Files)
+		}
+	}
+
+	// Depth 3 reaches one level further into plain directories
+	findings, err = Scan(ScanOptions{HomeDir: home, RepoRoot: repo, Depth: 3, Ignore: []string{".android"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, f := range findings {
+		found = found || f.Rel == "projects/deep/.tool-versions"
+	}
+	if !found {
+		t.Errorf("depth 3 should find projects/deep/.tool-versions: %+v", findings)
+	}
+}
+
+func TestSuggestTool(t *testing.T) {
+	cases := map[string]string{
+		".config/nvim":          "nvim",
+		".config/starship.toml": "starship",
+		".zshrc":                "zsh",
+		".tmux.conf":            "tmux",
+		".wgetrc":               "wget",
+		".vim":                  "vim",
+		".Xresources":           "xresources",
+	}
+	for rel, want := range cases {
+		if got := SuggestTool(rel); got != want {
+			t.Errorf("SuggestTool(%q) = %q, want %q", rel, got, want)
+		}
+	}
+}
+
+func TestAnalyzeHome(t *testing.T) {
+	home := t.TempDir()
+	writeFile(t, filepath.Join(home, ".config", "nvim", "init.lua"), "")
+	writeFile(t, filepath.Join(home, ".config", "nvim", ".git", "HEAD"), "")
+	writeFile(t, filepath.Join(home, ".wgetrc"), "")
+
+	plan, err := AnalyzeHome([]string{filepath.Join(home, ".config", "nvim"), filepath.Join(home, ".wgetrc")}, home, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Tools) != 2 {
+		t.Fatalf("tools = %d, want 2", len(plan.Tools))
+	}
+	nvim, wget := plan.Tools[0], plan.Tools[1]
+	if nvim.Name != "nvim" || len(nvim.Files) != 1 || nvim.Links[0].Target != "{config_dir}/nvim" {
+		t.Errorf("nvim plan = %+v", nvim)
+	}
+	if wget.Name != "wget" || wget.Links[0].Target != "{home_dir}/.wgetrc" {
+		t.Errorf("wget plan = %+v", wget)
+	}
+	if len(plan.Issues) != 1 {
+		t.Errorf("issues = %+v, want the skipped .git directory", plan.Issues)
+	}
+
+	if _, err := AnalyzeHome([]string{t.TempDir()}, home, "x"); err == nil {
+		t.Error("expected an error for a path outside the home directory")
+	}
+}
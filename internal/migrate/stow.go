@@ -0,0 +1,91 @@
+package migrate
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// stowIgnored are files stow never links
+var stowIgnored = map[string]bool{
+	".git": true, ".gitignore": true, ".gitmodules": true, "README.md": true,
+	"LICENSE": true, ".DS_Store": true,
+}
+
+// AnalyzeStow maps a GNU stow directory: each package (top-level subdirectory)
+// becomes a tool whose files mirror $HOME. Names using stow's --dotfiles
+// convention ("dot-zshrc") are translated to their dotted form.
+func AnalyzeStow(stowDir string) (*Plan, error) {
+	packages, err := os.ReadDir(stowDir)
+	if err != nil {
+		return nil, fmt.Errorf("read stow directory: %w", err)
+	}
+
+	plan := &Plan{}
+	var entries []Entry
+	for _, pkg := range packages {
+		pkgDir := filepath.Join(stowDir, pkg.Name())
+		if !pkg.IsDir() || stowIgnored[pkg.Name()] || strings.HasPrefix(pkg.Name(), ".") {
+			continue
+		}
+
+		found := 0
+		err := filepath.WalkDir(pkgDir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if p == pkgDir {
+				return nil
+			}
+			if stowIgnored[d.Name()] {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.Name() == ".stow-local-ignore" {
+				plan.Skip(p, "stow ignore patterns are not applied; review the generated links")
+				return nil
+			}
+			if d.IsDir() {
+				return nil
+			}
+			if !d.Type().IsRegular() {
+				plan.Skip(p, "not a regular file (symlinks inside packages need manual handling)")
+				return nil
+			}
+
+			rel, err := filepath.Rel(pkgDir, p)
+			if err != nil {
+				return err
+			}
+			entries = append(entries, Entry{Tool: pkg.Name(), HomeRel: undot(filepath.ToSlash(rel)), Source: p})
+			found++
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("walk package %s: %w", pkg.Name(), err)
+		}
+		if found == 0 {
+			plan.Skip(pkgDir, "package contains no files")
+		}
+	}
+
+	plan.Tools = BuildTools(entries, func(tool string) string {
+		return fmt.Sprintf("Migrated from stow package %s", tool)
+	})
+	return plan, nil
+}
+
+// undot translates stow's "dot-" prefix in each path component
+func undot(rel string) string {
+	parts := strings.Split(rel, "/")
+	for i, part := range parts {
+		if rest, ok := strings.CutPrefix(part, "dot-"); ok {
+			parts[i] = "." + rest
+		}
+	}
+	return strings.Join(parts, "/")
+}
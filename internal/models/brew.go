@@ -1,18 +1,63 @@
 package models
 
+import "strings"
+
 // BrewConfig represents the complete brew.toml configuration
 type BrewConfig struct {
 	Metadata Metadata      `toml:"metadata"`
+	Taps     []BrewTap     `toml:"tap"`
 	Formulae []BrewPackage `toml:"brew"`
 	Casks    []BrewPackage `toml:"cask"`
 }
 
+// BrewTap is a third-party tap declared with [[tap]]. Public GitHub taps
+// need no declaration (brew taps them on the first "owner/tap/name"
+// install); private ones declare how they are reached so merlin can check
+// access before installing anything.
+type BrewTap struct {
+	Name    string   `toml:"name"`     // "owner/repo", as given to brew tap
+	URL     string   `toml:"url"`      // clone URL when not https://github.com/owner/homebrew-repo, e.g. an SSH URL
+	AuthEnv []string `toml:"auth_env"` // environment variables access needs, e.g. ["HOMEBREW_GITHUB_API_TOKEN"]; names only, never the secrets
+}
+
+// Remote returns the URL the tap is cloned from
+func (t BrewTap) Remote() string {
+	if t.URL != "" {
+		return t.URL
+	}
+	owner, repo, _ := strings.Cut(t.Name, "/")
+	return "https://github.com/" + owner + "/homebrew-" + strings.TrimPrefix(repo, "homebrew-")
+}
+
 // BrewPackage represents a single Homebrew formula or cask
 type BrewPackage struct {
-	Name         string   `toml:"name"`
-	Description  string   `toml:"description"`
-	Category     string   `toml:"category"`
-	Dependencies []string `toml:"dependencies"`
+	Name         string     `toml:"name"`
+	Description  string     `toml:"description"`
+	Category     string     `toml:"category"`
+	Dependencies []string   `toml:"dependencies"`
+	Arch         string     `toml:"arch" schema:"enum=arm64|x86_64"` // install with the brew for this architecture (x86_64 runs under Rosetta)
+	PostInstall  string     `toml:"post_install"`                    // shell command run after the package is freshly installed, e.g. "$(brew --prefix)/opt/fzf/install --all"
+	When         *Condition `toml:"when"`                            // only machines matching this are expected to have the package
+
+	// Provenance, so a year later it's clear why the package is declared
+	Reason    string `toml:"reason"`     // why it is needed, e.g. "image resizing for the blog"
+	AddedBy   string `toml:"added_by"`   // who or what declared it, e.g. "merlin audit brew"
+	AddedDate Date   `toml:"added_date"` // when it was declared
+}
+
+// ForHost returns the configuration without the packages whose when
+// condition host doesn't match
+func (c *BrewConfig) ForHost(host Host) *BrewConfig {
+	keep := func(pkgs []BrewPackage) []BrewPackage {
+		var kept []BrewPackage
+		for _, pkg := range pkgs {
+			if pkg.When.Matches(host) {
+				kept = append(kept, pkg)
+			}
+		}
+		return kept
+	}
+	return &BrewConfig{Metadata: c.Metadata, Taps: c.Taps, Formulae: keep(c.Formulae), Casks: keep(c.Casks)}
 }
 
 // GetAllPackages returns all formulae and casks combined
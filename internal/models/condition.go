@@ -0,0 +1,98 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// StringList is a list of strings that may also be written as a single
+// string, e.g. profile = "work" or profile = ["work", "lab"]
+type StringList []string
+
+// UnmarshalTOML accepts a string or an array of strings
+func (l *StringList) UnmarshalTOML(data any) error {
+	switch v := data.(type) {
+	case string:
+		*l = StringList{v}
+		return nil
+	case []any:
+		list := make(StringList, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return fmt.Errorf("expected a string, got %v", item)
+			}
+			list = append(list, s)
+		}
+		*l = list
+		return nil
+	}
+	return fmt.Errorf("expected a string or an array of strings, got %v", data)
+}
+
+// JSONSchema describes the two forms UnmarshalTOML accepts (see internal/schema)
+func (StringList) JSONSchema() map[string]any {
+	return map[string]any{
+		"oneOf": []any{
+			map[string]any{"type": "string"},
+			map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+		},
+	}
+}
+
+// Host is what package conditions are evaluated against
+type Host struct {
+	OS       string // runtime.GOOS, e.g. "darwin"
+	Arch     string // runtime.GOARCH, e.g. "arm64" or "amd64"
+	Hostname string
+	Profile  string // active profile; empty when the repository has none
+}
+
+// Condition limits a [[brew]], [[cask]] or [[app]] entry to the machines it
+// matches, e.g. when = { arch = "arm64", profile = ["work"] }. Every key set
+// must match one of its values; unset keys match any machine.
+type Condition struct {
+	OS       StringList `toml:"os"`       // e.g. "darwin"
+	Arch     StringList `toml:"arch"`     // "arm64" or "x86_64" ("amd64" works too)
+	Hostname StringList `toml:"hostname"` // full or short hostname, e.g. "mbp" for mbp.local
+	Profile  StringList `toml:"profile"`  // profile names from the root merlin.toml
+}
+
+// Matches reports whether host satisfies the condition; a nil condition
+// matches every host
+func (c *Condition) Matches(host Host) bool {
+	if c == nil {
+		return true
+	}
+	return matchesAny(c.OS, host.OS, strings.EqualFold) &&
+		matchesAny(c.Arch, host.Arch, sameArch) &&
+		matchesAny(c.Hostname, host.Hostname, sameHost) &&
+		matchesAny(c.Profile, host.Profile, func(a, b string) bool { return a == b })
+}
+
+func matchesAny(values StringList, actual string, equal func(want, actual string) bool) bool {
+	if len(values) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(values, func(want string) bool { return equal(want, actual) })
+}
+
+// sameArch compares architectures, treating brew's x86_64 and Go's amd64 as
+// the same
+func sameArch(want, actual string) bool {
+	canonical := func(arch string) string {
+		if arch = strings.ToLower(arch); arch == "x86_64" {
+			return "amd64"
+		}
+		return arch
+	}
+	return canonical(want) == canonical(actual)
+}
+
+// sameHost matches a hostname against the full or short (before the first
+// dot) host name, ignoring case
+func sameHost(want, actual string) bool {
+	short, _, _ := strings.Cut(actual, ".")
+	return strings.EqualFold(want, actual) || strings.EqualFold(want, short)
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// DateLayout is how Date values are written
+const DateLayout = "2006-01-02"
+
+// Date is a calendar day, e.g. added_date = 2024-03-01. TOML local dates and
+// "YYYY-MM-DD" strings are both accepted.
+type Date string
+
+// Today returns the current date
+func Today() Date {
+	return Date(time.Now().Format(DateLayout))
+}
+
+// UnmarshalTOML accepts a TOML date or a "YYYY-MM-DD" string
+func (d *Date) UnmarshalTOML(data any) error {
+	switch v := data.(type) {
+	case time.Time:
+		*d = Date(v.Format(DateLayout))
+		return nil
+	case string:
+		if _, err := time.Parse(DateLayout, v); err == nil {
+			*d = Date(v)
+			return nil
+		}
+	}
+	return fmt.Errorf("expected a date (YYYY-MM-DD), got %v", data)
+}
+
+// JSONSchema describes the values UnmarshalTOML accepts (see internal/schema)
+func (Date) JSONSchema() map[string]any {
+	return map[string]any{"type": "string", "format": "date"}
+}
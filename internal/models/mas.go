@@ -8,11 +8,29 @@ type MASConfig struct {
 
 // MASApp represents a single Mac App Store application
 type MASApp struct {
-	Name         string   `toml:"name"`
-	ID           int      `toml:"id"`
-	Description  string   `toml:"description"`
-	Category     string   `toml:"category"`
-	Dependencies []string `toml:"dependencies"`
+	Name         string     `toml:"name"`
+	ID           int        `toml:"id"`
+	Description  string     `toml:"description"`
+	Category     string     `toml:"category"`
+	Dependencies []string   `toml:"dependencies"`
+	When         *Condition `toml:"when"` // only machines matching this are expected to have the app
+
+	// Provenance, as for BrewPackage
+	Reason    string `toml:"reason"`
+	AddedBy   string `toml:"added_by"`
+	AddedDate Date   `toml:"added_date"`
+}
+
+// ForHost returns the configuration without the apps whose when condition
+// host doesn't match
+func (c *MASConfig) ForHost(host Host) *MASConfig {
+	var apps []MASApp
+	for _, app := range c.Apps {
+		if app.When.Matches(host) {
+			apps = append(apps, app)
+		}
+	}
+	return &MASConfig{Metadata: c.Metadata, Apps: apps}
 }
 
 // GetByCategory returns all apps in a specific category
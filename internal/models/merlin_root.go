@@ -1,21 +1,161 @@
 package models
 
+import "fmt"
+
 // RootMerlinConfig represents the root merlin.toml configuration
 type RootMerlinConfig struct {
-	Metadata   Metadata           `toml:"metadata"`
-	Settings   Settings           `toml:"settings"`
-	Preinstall PreinstallSettings `toml:"preinstall"`
-	Profiles   []Profile          `toml:"profile"`
+	Metadata    Metadata           `toml:"metadata"`
+	Settings    Settings           `toml:"settings"`
+	Preinstall  PreinstallSettings `toml:"preinstall"`
+	Profiles    []Profile          `toml:"profile"`
+	ManualSteps []ManualStep       `toml:"manual_step"` // machine-wide steps, e.g. signing into the App Store
+	Maintenance Maintenance        `toml:"maintenance"` // what `merlin maintain` runs
+
+	// Defaults maps a command path ("link", "backup restore") to flag values
+	// used when the flag isn't given, e.g. [defaults.link] strategy = "backup"
+	Defaults map[string]map[string]any `toml:"defaults"`
 }
 
 // Settings contains global configuration settings
 type Settings struct {
-	AutoLink             bool   `toml:"auto_link"`
-	ConfirmBeforeInstall bool   `toml:"confirm_before_install"`
-	ConflictStrategy     string `toml:"conflict_strategy"`
-	HomeDir              string `toml:"home_dir"`
-	ConfigDir            string `toml:"config_dir"`
-	AutoCommit           bool   `toml:"auto_commit"` // enable automatic git commits after operations
+	AutoLink             bool           `toml:"auto_link"`
+	ConfirmBeforeInstall bool           `toml:"confirm_before_install"`
+	ConflictStrategy     string         `toml:"conflict_strategy" schema:"enum=backup|skip|overwrite|interactive"`
+	HomeDir              string         `toml:"home_dir"`
+	ConfigDir            string         `toml:"config_dir"`
+	BinDir               string         `toml:"bin_dir"`                 // where [[bin]] executables are linked (default {home_dir}/bin)
+	AutoCommit           bool           `toml:"auto_commit"`             // enable automatic git commits after operations
+	AutoBackupBeforeLink bool           `toml:"auto_backup_before_link"` // snapshot conflicting targets before batch linking
+	InstallRetries       int            `toml:"install_retries"`         // retry brew/mas installs on network errors
+	ProtectedPaths       []string       `toml:"protected_paths"`         // paths links and restores must never modify
+	BackupDir            string         `toml:"backup_dir"`              // where backups are written (default ~/.merlin/backups)
+	BackupRoots          []string       `toml:"backup_roots"`            // extra directories backups are listed and restored from
+	RequireMerlinTOML    Requirement    `toml:"require_merlin_toml"`     // tools without merlin.toml get no implicit link (true) or a validate warning ("warn")
+	Brew                 BrewSettings   `toml:"brew"`                    // [settings.brew]
+	Scan                 ScanSettings   `toml:"scan"`                    // [settings.scan]
+	Git                  GitSettings    `toml:"git"`                     // [settings.git]
+	Layout               LayoutSettings `toml:"layout"`                  // [settings.layout]
+	Notify               NotifySettings `toml:"notify"`                  // [settings.notify]
+}
+
+// Requirement is a setting that is either enforced (true) or only reported
+// by merlin validate ("warn"); false or unset is off
+type Requirement string
+
+const (
+	RequirementOff      Requirement = ""
+	RequirementEnforced Requirement = "true"
+	RequirementWarn     Requirement = "warn"
+)
+
+// UnmarshalTOML accepts true, false and "warn"
+func (r *Requirement) UnmarshalTOML(data any) error {
+	switch v := data.(type) {
+	case bool:
+		*r = RequirementOff
+		if v {
+			*r = RequirementEnforced
+		}
+		return nil
+	case string:
+		if v == string(RequirementWarn) {
+			*r = RequirementWarn
+			return nil
+		}
+	}
+	return fmt.Errorf("expected true, false or \"warn\", got %v", data)
+}
+
+// JSONSchema describes the values UnmarshalTOML accepts (see internal/schema)
+func (Requirement) JSONSchema() map[string]any {
+	return map[string]any{
+		"oneOf": []any{
+			map[string]any{"type": "boolean"},
+			map[string]any{"type": "string", "enum": []any{string(RequirementWarn)}},
+		},
+	}
+}
+
+// Enforced reports whether the requirement is true
+func (r Requirement) Enforced() bool {
+	return r == RequirementEnforced
+}
+
+// NotifySettings configures the webhook merlin posts operation summaries to
+// ([settings.notify]); see notify.Webhook
+type NotifySettings struct {
+	WebhookURL      string `toml:"webhook_url"`      // e.g. a Slack/Discord incoming webhook or an ntfy topic URL
+	PayloadTemplate string `toml:"payload_template"` // request body with {placeholders}; empty posts the summary as JSON
+	OnFailureOnly   bool   `toml:"on_failure_only"`  // only notify when something failed
+}
+
+// LayoutSettings describes where tools live in the repository
+// ([settings.layout]); see config.DotfilesRepo
+type LayoutSettings struct {
+	ToolsDir         string `toml:"tools_dir"`          // directory holding one subdirectory per tool (default "config")
+	ToolConfigSubdir string `toml:"tool_config_subdir"` // files linked by default, inside each tool (default "config"; "." is the tool itself)
+	Flat             bool   `toml:"flat"`               // every top-level directory of the repository is a tool
+}
+
+// GitSettings controls auto-commits and the managed .gitignore block
+// ([settings.git])
+type GitSettings struct {
+	CommitTemplate string   `toml:"commit_template"` // e.g. "chore({op}): {summary}"; see git.CommitMessage
+	Ignore         []string `toml:"ignore"`          // extra patterns for the managed .gitignore block; see git.ManagedIgnores
+	BatchWindow    string   `toml:"batch_window"`    // e.g. "10m": queue auto-commits and commit them together once quiet this long
+}
+
+// ScanSettings configures `merlin scan` ([settings.scan])
+type ScanSettings struct {
+	Depth  int      `toml:"depth"`  // directory levels below $HOME to search (default 2)
+	Ignore []string `toml:"ignore"` // name or $HOME-relative patterns skipped on top of the defaults
+}
+
+// BrewSettings controls how merlin runs Homebrew ([settings.brew])
+type BrewSettings struct {
+	Analytics      *bool `toml:"analytics"`       // false exports HOMEBREW_NO_ANALYTICS; nil leaves brew's setting alone
+	NoQuarantine   bool  `toml:"no_quarantine"`   // install casks with --no-quarantine
+	GreedyUpgrades bool  `toml:"greedy_upgrades"` // exports HOMEBREW_UPGRADE_GREEDY
+
+	// BrewPath is the brew binary to run, e.g. "/opt/homebrew/bin/brew";
+	// unset prefers the native brew over whichever is first on PATH
+	BrewPath string `toml:"brew_path"`
+
+	// CaskAppdir is where casks install their apps, e.g. "~/Applications";
+	// passed to cask installs as --appdir
+	CaskAppdir string `toml:"cask_appdir"`
+}
+
+// Env returns the HOMEBREW_* variables implied by the settings
+func (s BrewSettings) Env() map[string]string {
+	env := make(map[string]string)
+	if s.Analytics != nil && !*s.Analytics {
+		env["HOMEBREW_NO_ANALYTICS"] = "1"
+	}
+	if s.GreedyUpgrades {
+		env["HOMEBREW_UPGRADE_GREEDY"] = "1"
+	}
+	return env
+}
+
+// MaintenanceSteps are the steps `merlin maintain` knows, in the order it
+// runs them when [maintenance] steps is unset
+var MaintenanceSteps = []string{"pull", "brew-upgrade", "brew-cleanup", "backup-prune", "snapshot", "diff", "push"}
+
+// Maintenance configures `merlin maintain` ([maintenance])
+type Maintenance struct {
+	// Steps run in this order (default: MaintenanceSteps)
+	Steps        []string `toml:"steps" schema:"enum=pull|brew-upgrade|brew-cleanup|backup-prune|snapshot|diff|push"`
+	BackupKeep   int      `toml:"backup_keep"`    // backup-prune keeps this many newest backups
+	BackupMaxAge int      `toml:"backup_max_age"` // backup-prune deletes backups older than this many days, beyond backup_keep
+}
+
+// EnabledSteps returns the configured steps, or every step when none are
+func (m Maintenance) EnabledSteps() []string {
+	if len(m.Steps) > 0 {
+		return m.Steps
+	}
+	return MaintenanceSteps
 }
 
 // PreinstallSettings defines system requirements installed before profiles
@@ -4,25 +4,130 @@ import "fmt"
 
 // ToolMerlinConfig represents a per-tool merlin.toml configuration
 type ToolMerlinConfig struct {
-	Tool    ToolInfo       `toml:"tool"`
-	Links   []Link         `toml:"link"`
-	Scripts ScriptsSection `toml:"scripts"`
+	Tool        ToolInfo       `toml:"tool" schema:"required"`
+	Links       []Link         `toml:"link"`
+	Bins        []Bin          `toml:"bin"`
+	Scripts     ScriptsSection `toml:"scripts"`
+	Extensions  []Extension    `toml:"extension"`
+	ManualSteps []ManualStep   `toml:"manual_step"`
+	Packages    ToolPackages   `toml:"packages"`
+}
+
+// ToolPackages lists the packages merlin install tool installs for a tool in
+// addition to its dependencies. Names are looked up in brew.toml and mas.toml.
+type ToolPackages struct {
+	Formulae []string `toml:"formulae"`
+	Casks    []string `toml:"casks"`
+	MAS      []string `toml:"mas"` // App names as listed in mas.toml
 }
 
 // ToolInfo contains basic information about a tool
 type ToolInfo struct {
-	Name         string   `toml:"name"`
+	Name         string   `toml:"name" schema:"required"`
 	Description  string   `toml:"description"`
 	Dependencies []string `toml:"dependencies"`
+	Enabled      *bool    `toml:"enabled"` // nil means enabled
+	Notes        string   `toml:"notes"`   // why the config exists, manual steps left; shown by merlin info
+	Command      string   `toml:"command"` // binary (or Name.app bundle) showing the tool is installed; default: the tool name
+
+	// macOS privacy permissions the tool's apps or scripts need, checked by merlin doctor
+	Permissions []string `toml:"permissions" schema:"enum=full_disk_access|screen_recording|accessibility|input_monitoring"`
 }
 
 // Link represents a symlink configuration
 type Link struct {
-	Source string     `toml:"source"` // Source path relative to tool's config directory
-	Target string     `toml:"target"` // Target path (can contain variables like {config_dir})
-	Files  []FileLink `toml:"files"`  // Optional: multiple files to same base target
+	Source        string            `toml:"source"`                   // Source path relative to tool's config directory
+	Target        string            `toml:"target" schema:"required"` // Target path (can contain variables like {config_dir})
+	Files         []FileLink        `toml:"files"`                    // Optional: multiple files to same base target
+	Contents      bool              `toml:"contents"`                 // Link each file inside a source directory instead of the directory itself
+	IncludeHidden bool              `toml:"include_hidden"`           // Contents mode: also link dotfiles (VCS metadata is always skipped)
+	Rename        map[string]string `toml:"rename"`                   // Contents mode: source-relative path → target-relative path
+	DotPrefix     bool              `toml:"dot_prefix"`               // Contents mode: prepend "." to top-level entries at the target
+	Launchd       bool              `toml:"launchd"`                  // Load .plist targets with launchctl after linking, unload before unlinking (macOS)
+	Order         int               `toml:"order"`                    // Links run in ascending order (default 0); equal values keep file order
+	Optional      Optional          `toml:"optional"`                 // The source may be absent, e.g. a work-only file (see Optional)
 }
 
+// Optional says how a link whose source doesn't exist is treated. Unset or
+// false, a missing source is an error for merlin validate and merlin link.
+// With true the link is skipped silently; with "warn" it is skipped too, but
+// merlin validate still reports it.
+type Optional string
+
+const (
+	OptionalNo   Optional = ""
+	OptionalYes  Optional = "true"
+	OptionalWarn Optional = "warn"
+)
+
+// UnmarshalTOML accepts true, false and "warn"
+func (o *Optional) UnmarshalTOML(data any) error {
+	switch v := data.(type) {
+	case bool:
+		*o = OptionalNo
+		if v {
+			*o = OptionalYes
+		}
+		return nil
+	case string:
+		if v == string(OptionalWarn) {
+			*o = OptionalWarn
+			return nil
+		}
+	}
+	return fmt.Errorf("expected true, false or \"warn\", got %v", data)
+}
+
+// JSONSchema describes the values UnmarshalTOML accepts (see internal/schema)
+func (Optional) JSONSchema() map[string]any {
+	return map[string]any{
+		"oneOf": []any{
+			map[string]any{"type": "boolean"},
+			map[string]any{"type": "string", "enum": []any{string(OptionalWarn)}},
+		},
+	}
+}
+
+// Skippable reports whether a missing source skips the link rather than
+// failing it
+func (o Optional) Skippable() bool {
+	return o != OptionalNo
+}
+
+// Bin is an executable linked into the bin directory ({bin_dir}, ~/bin by default)
+type Bin struct {
+	Source string `toml:"source" schema:"required"` // File or glob relative to the tool root
+	Name   string `toml:"name"`                     // Command name in the bin directory (defaults to the file name; not for globs)
+}
+
+// Extension is an editor extension installed through the editor's CLI
+type Extension struct {
+	Editor string `toml:"editor" schema:"required,enum=code|cursor"` // Editor CLI: "code" (VS Code) or "cursor"
+	ID     string `toml:"id" schema:"required"`                      // Marketplace identifier, publisher.name
+}
+
+// ManualStep is a setup action merlin can't automate, such as signing into an
+// app or granting a permission. merlin todo tracks it per machine.
+type ManualStep struct {
+	ID          string `toml:"id" schema:"required"`          // Unique within its merlin.toml; tool steps are addressed as tool/id
+	Description string `toml:"description" schema:"required"` // What to do
+	URL         string `toml:"url"`                           // Optional instructions or settings pane
+}
+
+// Editors that accept [[extension]] entries
+const (
+	EditorCode   = "code"
+	EditorCursor = "cursor"
+)
+
+// macOS privacy (TCC) permissions a tool can declare in [tool] permissions
+const (
+	PermissionFullDiskAccess  = "full_disk_access"
+	PermissionScreenRecording = "screen_recording"
+	PermissionAccessibility   = "accessibility"
+	PermissionInputMonitoring = "input_monitoring"
+)
+
 // FileLink represents a file to be linked within a base target
 type FileLink struct {
 	Source string `toml:"source"` // Source file path
@@ -31,11 +136,24 @@ type FileLink struct {
 
 // ScriptItem represents a single script with optional tags.
 // Backward compatibility: a plain string in the TOML array becomes ScriptItem{File: <string>}.
-// Extended form: { file = "script.sh", tags = ["tag1", "tag2"] }
+// Extended form: { file = "script.sh", tags = ["tag1", "tag2"], dry_run_supported = true }
 // Alternate key: { name = "script.sh" } is also accepted for convenience.
 type ScriptItem struct {
-	File string   // Actual script file name (relative to scripts directory)
-	Tags []string // Optional tags used for selection/filtering
+	File   string            // Actual script file name (relative to scripts directory)
+	Tags   []string          // Optional tags used for selection/filtering
+	Env    map[string]string // Optional per-script environment (overrides [scripts.env])
+	Args   []string          // Arguments passed to the script ({var} and {param} placeholders expanded)
+	Params []ScriptParam     // Values given with --param or prompted for at run time
+
+	DryRunSupported bool // Script honors MERLIN_DRY_RUN=1, so it runs during --dry-run
+}
+
+// ScriptParam is a run-time value of a script, exported to it as
+// MERLIN_PARAM_<NAME> and available to its args as {name}
+type ScriptParam struct {
+	Name        string
+	Default     string // Used when no value is given; without one the value is required
+	Description string // Shown when prompting
 }
 
 // UnmarshalTOML implements custom decoding to support both string and table entries.
@@ -61,16 +179,151 @@ func (s *ScriptItem) UnmarshalTOML(data any) error {
 				}
 			}
 		}
+		if dryRun, ok := v["dry_run_supported"]; ok {
+			b, ok := dryRun.(bool)
+			if !ok {
+				return fmt.Errorf("script %s: dry_run_supported must be a boolean", s.File)
+			}
+			s.DryRunSupported = b
+		}
+		if rawEnv, ok := v["env"]; ok {
+			envTable, ok := rawEnv.(map[string]any)
+			if !ok {
+				return fmt.Errorf("script %s: env must be a table of strings", s.File)
+			}
+			s.Env = make(map[string]string, len(envTable))
+			for key, val := range envTable {
+				str, ok := val.(string)
+				if !ok {
+					return fmt.Errorf("script %s: env value for %s must be a string", s.File, key)
+				}
+				s.Env[key] = str
+			}
+		}
+		if rawArgs, ok := v["args"]; ok {
+			list, ok := rawArgs.([]any)
+			if !ok {
+				return fmt.Errorf("script %s: args must be an array of strings", s.File)
+			}
+			for _, a := range list {
+				str, ok := a.(string)
+				if !ok {
+					return fmt.Errorf("script %s: args must be an array of strings", s.File)
+				}
+				s.Args = append(s.Args, str)
+			}
+		}
+		if rawParams, ok := v["params"]; ok {
+			params, err := parseScriptParams(s.File, rawParams)
+			if err != nil {
+				return err
+			}
+			s.Params = params
+		}
 		return nil
 	default:
 		return fmt.Errorf("invalid script item type %T", v)
 	}
 }
 
+// parseScriptParams decodes params = [{ name = "region", default = "eu" }]
+func parseScriptParams(file string, raw any) ([]ScriptParam, error) {
+	var tables []map[string]any
+	switch v := raw.(type) {
+	case []map[string]any:
+		tables = v
+	case []any:
+		for _, item := range v {
+			table, ok := item.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("script %s: params must be an array of tables", file)
+			}
+			tables = append(tables, table)
+		}
+	default:
+		return nil, fmt.Errorf("script %s: params must be an array of tables", file)
+	}
+
+	params := make([]ScriptParam, 0, len(tables))
+	for _, table := range tables {
+		var p ScriptParam
+		for key, val := range table {
+			str, ok := val.(string)
+			if !ok {
+				return nil, fmt.Errorf("script %s: param %s must be a string", file, key)
+			}
+			switch key {
+			case "name":
+				p.Name = str
+			case "default":
+				p.Default = str
+			case "description":
+				p.Description = str
+			default:
+				return nil, fmt.Errorf("script %s: unknown param field %q", file, key)
+			}
+		}
+		if p.Name == "" {
+			return nil, fmt.Errorf("script %s: param missing 'name' field", file)
+		}
+		params = append(params, p)
+	}
+	return params, nil
+}
+
+// JSONSchema describes the two forms UnmarshalTOML accepts (see internal/schema)
+func (ScriptItem) JSONSchema() map[string]any {
+	stringMap := map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}}
+	return map[string]any{
+		"oneOf": []any{
+			map[string]any{"type": "string"},
+			map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"file":              map[string]any{"type": "string"},
+					"name":              map[string]any{"type": "string"},
+					"tags":              map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+					"env":               stringMap,
+					"dry_run_supported": map[string]any{"type": "boolean"},
+					"args":              map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+					"params": map[string]any{
+						"type": "array",
+						"items": map[string]any{
+							"type": "object",
+							"properties": map[string]any{
+								"name":        map[string]any{"type": "string"},
+								"default":     map[string]any{"type": "string"},
+								"description": map[string]any{"type": "string"},
+							},
+							"required":             []string{"name"},
+							"additionalProperties": false,
+						},
+					},
+				},
+				"anyOf":                []any{map[string]any{"required": []string{"file"}}, map[string]any{"required": []string{"name"}}},
+				"additionalProperties": false,
+			},
+		},
+	}
+}
+
 // ScriptsSection contains script execution configuration
 type ScriptsSection struct {
-	Directory string       `toml:"directory"` // Directory containing scripts (relative to tool root)
-	Scripts   []ScriptItem `toml:"scripts"`   // Scripts to execute in order
+	Directory string            `toml:"directory"`                            // Directory containing scripts (relative to tool root)
+	Scripts   []ScriptItem      `toml:"scripts"`                              // Scripts to execute in order
+	Env       map[string]string `toml:"env"`                                  // Environment shared by all scripts ({var} placeholders expanded)
+	OnError   string            `toml:"on_error" schema:"enum=stop|continue"` // "stop" (default) or "continue" after a failing script
+}
+
+// Script failure policies for [scripts] on_error
+const (
+	OnErrorStop     = "stop"
+	OnErrorContinue = "continue"
+)
+
+// ContinueOnError reports whether remaining scripts run after one fails
+func (s ScriptsSection) ContinueOnError() bool {
+	return s.OnError == OnErrorContinue
 }
 
 // HasScripts returns true if the tool has scripts to execute
@@ -78,11 +331,26 @@ func (c *ToolMerlinConfig) HasScripts() bool {
 	return len(c.Scripts.Scripts) > 0
 }
 
+// IsEnabled reports whether the tool takes part in discovery (enabled = false retires it)
+func (c *ToolMerlinConfig) IsEnabled() bool {
+	return c.Tool.Enabled == nil || *c.Tool.Enabled
+}
+
 // HasLinks returns true if the tool has symlinks to create
 func (c *ToolMerlinConfig) HasLinks() bool {
 	return len(c.Links) > 0
 }
 
+// HasBins returns true if the tool links executables into the bin directory
+func (c *ToolMerlinConfig) HasBins() bool {
+	return len(c.Bins) > 0
+}
+
+// HasExtensions returns true if the tool declares editor extensions
+func (c *ToolMerlinConfig) HasExtensions() bool {
+	return len(c.Extensions) > 0
+}
+
 // HasDependencies returns true if the tool has dependencies
 func (c *ToolMerlinConfig) HasDependencies() bool {
 	return len(c.Tool.Dependencies) > 0
@@ -48,6 +48,43 @@ func TestBrewConfig(t *testing.T) {
 	})
 }
 
+func TestConditionMatches(t *testing.T) {
+	host := Host{OS: "darwin", Arch: "amd64", Hostname: "mbp.local", Profile: "work"}
+	tests := []struct {
+		name string
+		when *Condition
+		want bool
+	}{
+		{"no condition", nil, true},
+		{"empty condition", &Condition{}, true},
+		{"brew arch name", &Condition{Arch: StringList{"x86_64"}}, true},
+		{"other arch", &Condition{Arch: StringList{"arm64"}}, false},
+		{"short hostname", &Condition{Hostname: StringList{"MBP"}}, true},
+		{"full hostname", &Condition{Hostname: StringList{"mbp.local"}}, true},
+		{"one of the profiles", &Condition{Profile: StringList{"home", "work"}}, true},
+		{"every key must match", &Condition{OS: StringList{"darwin"}, Profile: StringList{"home"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.when.Matches(host); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	config := BrewConfig{
+		Formulae: []BrewPackage{{Name: "git"}, {Name: "asitop", When: &Condition{Arch: StringList{"arm64"}}}},
+		Casks:    []BrewPackage{{Name: "teams", When: &Condition{Profile: StringList{"work"}}}},
+	}
+	filtered := config.ForHost(host)
+	if len(filtered.Formulae) != 1 || filtered.Formulae[0].Name != "git" || len(filtered.Casks) != 1 {
+		t.Errorf("ForHost() = %+v", filtered)
+	}
+	if len(config.Formulae) != 2 {
+		t.Error("ForHost() should not modify the configuration")
+	}
+}
+
 func TestMASConfig(t *testing.T) {
 	config := MASConfig{
 		Metadata: Metadata{
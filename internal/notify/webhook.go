@@ -0,0 +1,168 @@
+// Package notify reports the outcome of merlin operations outside the
+// terminal, e.g. to a Slack, Discord or ntfy webhook.
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ildx/merlin/internal/models"
+	"github.com/ildx/merlin/internal/placeholder"
+)
+
+// Status values for Summary.Status
+const (
+	StatusOK     = "ok"
+	StatusFailed = "failed"
+)
+
+// Placeholders are the names a payload template may use
+var Placeholders = []string{"op", "status", "message", "host", "succeeded", "failed", "failures", "date"}
+
+// Summary describes a completed operation
+type Summary struct {
+	Op        string    `json:"op"`      // e.g. "install brew"
+	Status    string    `json:"status"`  // StatusOK or StatusFailed
+	Message   string    `json:"message"` // e.g. "install brew: 12 succeeded, 1 failed"
+	Host      string    `json:"host"`    // machine the operation ran on
+	Succeeded int       `json:"succeeded"`
+	Failed    int       `json:"failed"`
+	Failures  []string  `json:"failures,omitempty"` // names of the items that failed
+	Date      time.Time `json:"date"`
+}
+
+// NewSummary builds a Summary for op from its success and failure counts.
+// Status is StatusFailed when anything failed.
+func NewSummary(op string, succeeded int, failures []string) Summary {
+	host, _ := os.Hostname()
+	s := Summary{
+		Op:        op,
+		Status:    StatusOK,
+		Host:      host,
+		Succeeded: succeeded,
+		Failed:    len(failures),
+		Failures:  failures,
+		Date:      time.Now(),
+	}
+	s.Message = fmt.Sprintf("%s: %d succeeded", op, succeeded)
+	if len(failures) > 0 {
+		s.Status = StatusFailed
+		s.Message += fmt.Sprintf(", %d failed (%s)", len(failures), strings.Join(failures, ", "))
+	}
+	return s
+}
+
+// Webhook posts summaries to a URL ([settings.notify])
+type Webhook struct {
+	URL           string
+	Template      string // payload with {placeholders}; empty posts the Summary as JSON
+	OnFailureOnly bool   // only post summaries whose Status is StatusFailed
+	Client        *http.Client
+}
+
+// NewWebhook returns the webhook configured by settings, or nil when no
+// webhook_url is set
+func NewWebhook(settings models.NotifySettings) *Webhook {
+	if strings.TrimSpace(settings.WebhookURL) == "" {
+		return nil
+	}
+	return &Webhook{
+		URL:           settings.WebhookURL,
+		Template:      settings.PayloadTemplate,
+		OnFailureOnly: settings.OnFailureOnly,
+		Client:        &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Send posts s to the webhook. Summaries filtered out by OnFailureOnly are
+// dropped without error.
+func (w *Webhook) Send(s Summary) error {
+	if w.OnFailureOnly && s.Status != StatusFailed {
+		return nil
+	}
+	body, contentType, err := Payload(w.Template, s)
+	if err != nil {
+		return err
+	}
+
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Post(w.URL, contentType, bytes.NewReader(body))
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("webhook %s: %w", redactURL(w.URL), err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s returned %s", redactURL(w.URL), resp.Status)
+	}
+	return nil
+}
+
+// Payload renders the request body for s. An empty template yields s as JSON.
+// Placeholder values are JSON-escaped so a template such as
+// {"text": "merlin {message}"} stays valid JSON; templates that aren't JSON
+// (e.g. plain text for ntfy) are sent as text/plain.
+func Payload(template string, s Summary) ([]byte, string, error) {
+	if strings.TrimSpace(template) == "" {
+		body, err := json.Marshal(s)
+		return body, "application/json", err
+	}
+
+	values := map[string]string{
+		"op":        s.Op,
+		"status":    s.Status,
+		"message":   s.Message,
+		"host":      s.Host,
+		"succeeded": fmt.Sprintf("%d", s.Succeeded),
+		"failed":    fmt.Sprintf("%d", s.Failed),
+		"failures":  strings.Join(s.Failures, ", "),
+		"date":      s.Date.Format(time.RFC3339),
+	}
+	isJSON := json.Valid([]byte(placeholder.Pattern.ReplaceAllString(template, "0")))
+	body := placeholder.Pattern.ReplaceAllStringFunc(template, func(m string) string {
+		value, ok := values[m[1:len(m)-1]]
+		if !ok {
+			return m
+		}
+		if isJSON {
+			quoted, _ := json.Marshal(value)
+			return string(quoted[1 : len(quoted)-1])
+		}
+		return value
+	})
+
+	if isJSON {
+		return []byte(body), "application/json", nil
+	}
+	return []byte(body), "text/plain; charset=utf-8", nil
+}
+
+// UnknownPlaceholders returns the {name} placeholders in template that
+// Payload doesn't know
+func UnknownPlaceholders(template string) []string {
+	return placeholder.Unknown(template, Placeholders)
+}
+
+// redactURL keeps the scheme and host of a webhook URL; the path usually
+// carries the token
+func redactURL(raw string) string {
+	if i := strings.Index(raw, "://"); i >= 0 {
+		if j := strings.Index(raw[i+3:], "/"); j >= 0 {
+			return raw[:i+3+j] + "/…"
+		}
+	}
+	return raw
+}
@@ -0,0 +1,88 @@
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testSummary() Summary {
+	s := NewSummary("install brew", 3, []string{"ripgrep", `we"ird`})
+	s.Host = "studio"
+	s.Date = time.Date(2025, 1, 8, 14, 30, 0, 0, time.UTC)
+	return s
+}
+
+func TestNewSummary(t *testing.T) {
+	if s := NewSummary("install mas", 2, nil); s.Status != StatusOK || s.Message != "install mas: 2 succeeded" {
+		t.Errorf("ok summary = %+v", s)
+	}
+	s := testSummary()
+	if s.Status != StatusFailed || s.Failed != 2 || s.Message != `install brew: 3 succeeded, 2 failed (ripgrep, we"ird)` {
+		t.Errorf("failed summary = %+v", s)
+	}
+}
+
+func TestPayload(t *testing.T) {
+	s := testSummary()
+
+	body, contentType, _ := Payload("", s)
+	var decoded Summary
+	if err := json.Unmarshal(body, &decoded); err != nil || contentType != "application/json" || decoded.Failed != 2 {
+		t.Errorf("default payload = %s (%s): %v", body, contentType, err)
+	}
+
+	body, contentType, _ = Payload(`{"text": "{host}: {message}", "failed": {failed}}`, s)
+	var slack struct {
+		Text   string
+		Failed int
+	}
+	if err := json.Unmarshal(body, &slack); err != nil || contentType != "application/json" {
+		t.Fatalf("JSON template payload = %s (%s): %v", body, contentType, err)
+	}
+	if slack.Text != "studio: "+s.Message || slack.Failed != 2 {
+		t.Errorf("JSON template decoded to %+v", slack)
+	}
+
+	body, contentType, _ = Payload("merlin {op} {status} on {date}", s)
+	if string(body) != "merlin install brew failed on 2025-01-08T14:30:00Z" || !strings.HasPrefix(contentType, "text/plain") {
+		t.Errorf("text payload = %q (%s)", body, contentType)
+	}
+}
+
+func TestWebhookSend(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, string(body))
+		if strings.Contains(string(body), "reject") {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	w := &Webhook{URL: server.URL + "/hooks/secret-token", Template: "{status}", OnFailureOnly: true}
+	if err := w.Send(NewSummary("install mas", 1, nil)); err != nil || len(received) != 0 {
+		t.Errorf("OnFailureOnly posted a successful summary: %v %v", err, received)
+	}
+	if err := w.Send(testSummary()); err != nil || len(received) != 1 || received[0] != "failed" {
+		t.Errorf("Send() = %v, received %v", err, received)
+	}
+
+	w.Template = "reject {op}"
+	err := w.Send(testSummary())
+	if err == nil || !strings.Contains(err.Error(), "400") || strings.Contains(err.Error(), "secret-token") {
+		t.Errorf("Send() to a failing endpoint = %v, want a redacted 400 error", err)
+	}
+}
+
+func TestUnknownPlaceholders(t *testing.T) {
+	got := UnknownPlaceholders(`{"text": "{message} {summary} {host}"}`)
+	if len(got) != 1 || got[0] != "{summary}" {
+		t.Errorf("UnknownPlaceholders = %v, want [{summary}]", got)
+	}
+}
@@ -0,0 +1,93 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/ildx/merlin/internal/models"
+)
+
+// BrewDropinDir is the directory next to brew.toml whose *.toml files are
+// merged into it, so packages can be split per domain (dev.toml, fonts.toml)
+const BrewDropinDir = "brew.d"
+
+// BrewFile is one parsed file of the brew package list
+type BrewFile struct {
+	Path   string
+	Config *models.BrewConfig
+}
+
+// BrewConfigFiles returns the files making up the brew package list in
+// brewDir: brew.toml when present, then brew.d/*.toml sorted by name
+func BrewConfigFiles(brewDir string) []string {
+	var files []string
+	if main := filepath.Join(brewDir, "brew.toml"); isFile(main) {
+		files = append(files, main)
+	}
+	dropins, _ := filepath.Glob(filepath.Join(brewDir, BrewDropinDir, "*.toml"))
+	sort.Strings(dropins)
+	for _, path := range dropins {
+		if isFile(path) {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// HasBrewConfig reports whether brewDir holds a brew.toml or brew.d files
+func HasBrewConfig(brewDir string) bool {
+	return len(BrewConfigFiles(brewDir)) > 0
+}
+
+// ParseBrewFiles parses each file of the brew package list in brewDir. The
+// error wraps os.ErrNotExist when there is none.
+func ParseBrewFiles(brewDir string) ([]BrewFile, error) {
+	paths := BrewConfigFiles(brewDir)
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("brew.toml not found at %s: %w", filepath.Join(brewDir, "brew.toml"), os.ErrNotExist)
+	}
+	files := make([]BrewFile, 0, len(paths))
+	for _, path := range paths {
+		config, err := parseBrewFile(path, BrewFileName(brewDir, path))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, BrewFile{Path: path, Config: config})
+	}
+	return files, nil
+}
+
+// ParseBrewConfig parses brewDir's brew.toml and brew.d/*.toml into one
+// package list, in file order. Metadata comes from brew.toml.
+func ParseBrewConfig(brewDir string) (*models.BrewConfig, error) {
+	files, err := ParseBrewFiles(brewDir)
+	if err != nil {
+		return nil, err
+	}
+	merged := &models.BrewConfig{}
+	for _, file := range files {
+		if file.Path == filepath.Join(brewDir, "brew.toml") {
+			merged.Metadata = file.Config.Metadata
+		}
+		merged.Taps = append(merged.Taps, file.Config.Taps...)
+		merged.Formulae = append(merged.Formulae, file.Config.Formulae...)
+		merged.Casks = append(merged.Casks, file.Config.Casks...)
+	}
+	return merged, nil
+}
+
+// BrewFileName names path relative to brewDir for messages, e.g.
+// "brew.d/fonts.toml"
+func BrewFileName(brewDir, path string) string {
+	if rel, err := filepath.Rel(brewDir, path); err == nil {
+		return rel
+	}
+	return path
+}
+
+func isFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
@@ -0,0 +1,71 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// cacheEntry is a decoded config and the file stamp it was decoded from
+type cacheEntry struct {
+	modTime time.Time
+	size    int64
+	value   any
+}
+
+// parseCache holds decoded configs for the lifetime of the process, so the
+// helpers that each parse the same merlin.toml during one command share a
+// single read and decode
+var parseCache = struct {
+	sync.Mutex
+	entries map[string]cacheEntry
+}{entries: make(map[string]cacheEntry)}
+
+// cached returns the config decoded from path, calling parse only when the
+// file is new to the cache or its mtime or size changed. Callers get a
+// shallow copy: replacing fields is safe, but nested slices and maps are
+// shared with the cache and must not be modified in place. Errors are not
+// cached.
+func cached[T any](path string, parse func() (*T, error)) (*T, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		// Let parse report the read error in its usual words
+		return parse()
+	}
+	key, err := filepath.Abs(path)
+	if err != nil {
+		key = path
+	}
+
+	parseCache.Lock()
+	entry, ok := parseCache.entries[key]
+	parseCache.Unlock()
+	if ok && entry.modTime.Equal(info.ModTime()) && entry.size == info.Size() {
+		if value, ok := entry.value.(*T); ok {
+			copied := *value
+			return &copied, nil
+		}
+	}
+
+	value, err := parse()
+	if err != nil {
+		return nil, err
+	}
+
+	// Stamp with the stat taken before reading: a write during the read makes
+	// the next call parse again rather than serve stale data
+	parseCache.Lock()
+	parseCache.entries[key] = cacheEntry{modTime: info.ModTime(), size: info.Size(), value: value}
+	parseCache.Unlock()
+
+	copied := *value
+	return &copied, nil
+}
+
+// resetCache drops all cached configs
+func resetCache() {
+	parseCache.Lock()
+	parseCache.entries = make(map[string]cacheEntry)
+	parseCache.Unlock()
+}
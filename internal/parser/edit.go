@@ -0,0 +1,215 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ildx/merlin/internal/models"
+)
+
+// SetToolEnabled rewrites the enabled key in the [tool] table of a tool
+// merlin.toml, leaving the rest of the file (comments, ordering) untouched.
+// Enabling removes the key since tools are enabled by default. A [tool] table
+// is added at the top of the file if there is none.
+func SetToolEnabled(data, toolName string, enabled bool) string {
+	lines := strings.Split(data, "\n")
+
+	toolStart, toolEnd := -1, len(lines)
+	for i, line := range lines {
+		header := tableHeader(line)
+		if header == "" {
+			continue
+		}
+		if toolStart >= 0 {
+			toolEnd = i
+			break
+		}
+		if header == "[tool]" {
+			toolStart = i
+		}
+	}
+
+	if toolStart < 0 {
+		if enabled {
+			return data
+		}
+		return fmt.Sprintf("[tool]\nname = %q\nenabled = false\n\n", toolName) + data
+	}
+
+	// Replace or remove an existing key; otherwise append after the table's last key
+	last := toolStart
+	for i := toolStart + 1; i < toolEnd; i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		last = i
+		key, _, ok := strings.Cut(trimmed, "=")
+		if !ok || strings.TrimSpace(key) != "enabled" {
+			continue
+		}
+		if enabled {
+			return strings.Join(append(lines[:i:i], lines[i+1:]...), "\n")
+		}
+		lines[i] = "enabled = false"
+		return strings.Join(lines, "\n")
+	}
+
+	if enabled {
+		return data
+	}
+	lines = append(lines[:last+1], append([]string{"enabled = false"}, lines[last+1:]...)...)
+	return strings.Join(lines, "\n")
+}
+
+// AppendBrewEntries returns data with a [[table]] entry ("brew" or "cask")
+// appended for each package, leaving the existing text untouched. Only the
+// name, description and provenance keys are written.
+func AppendBrewEntries(data, table string, packages []models.BrewPackage) string {
+	var b strings.Builder
+	b.WriteString(data)
+	if data != "" && !strings.HasSuffix(data, "\n") {
+		b.WriteString("\n")
+	}
+	for _, p := range packages {
+		fmt.Fprintf(&b, "\n[[%s]]\nname = %q\n", table, p.Name)
+		for _, kv := range [][2]string{{"description", p.Description}, {"reason", p.Reason}, {"added_by", p.AddedBy}} {
+			if kv[1] != "" {
+				fmt.Fprintf(&b, "%s = %q\n", kv[0], kv[1])
+			}
+		}
+		if p.AddedDate != "" {
+			fmt.Fprintf(&b, "added_date = %s\n", p.AddedDate)
+		}
+	}
+	return b.String()
+}
+
+// RemoveProfileTool rewrites the tools arrays of the [[profile]] tables in a
+// root merlin.toml without toolName, leaving the rest of the file untouched.
+// Arrays may span several lines; an entry on a line of its own takes the line
+// with it.
+func RemoveProfileTool(data, toolName string) string {
+	entry := regexp.MustCompile(quotedName(toolName) + `\s*,?[ \t]*`)
+	danglingComma := regexp.MustCompile(`,\s*\]`)
+	return editArray(data, "[[profile]]", "tools", func(code string) string {
+		return danglingComma.ReplaceAllString(entry.ReplaceAllString(code, ""), "]")
+	})
+}
+
+// RenameProfileTool rewrites oldName as newName in the tools arrays of the
+// [[profile]] tables in a root merlin.toml
+func RenameProfileTool(data, oldName, newName string) string {
+	return editArray(data, "[[profile]]", "tools", renameEntry(oldName, newName))
+}
+
+// RenameToolDependency rewrites oldName as newName in the dependencies of
+// the [tool] table in a tool merlin.toml
+func RenameToolDependency(data, oldName, newName string) string {
+	return editArray(data, "[tool]", "dependencies", renameEntry(oldName, newName))
+}
+
+// SetToolName rewrites the name key in the [tool] table of a tool
+// merlin.toml, adding the table at the top of the file if there is none
+func SetToolName(data, name string) string {
+	lines := strings.Split(data, "\n")
+	toolLine := -1
+	for i, line := range lines {
+		if header := tableHeader(line); header != "" {
+			if toolLine >= 0 {
+				break
+			}
+			if header == "[tool]" {
+				toolLine = i
+			}
+			continue
+		}
+		key, _, ok := strings.Cut(line, "=")
+		if toolLine >= 0 && ok && strings.TrimSpace(key) == "name" {
+			_, comment, hasComment := strings.Cut(line, "#")
+			lines[i] = fmt.Sprintf("name = %q", name)
+			if hasComment {
+				lines[i] += " #" + comment
+			}
+			return strings.Join(lines, "\n")
+		}
+	}
+	if toolLine < 0 {
+		return fmt.Sprintf("[tool]\nname = %q\n\n", name) + data
+	}
+	lines = append(lines[:toolLine+1], append([]string{fmt.Sprintf("name = %q", name)}, lines[toolLine+1:]...)...)
+	return strings.Join(lines, "\n")
+}
+
+// quotedName matches name as a basic or literal TOML string
+func quotedName(name string) string {
+	return `("` + regexp.QuoteMeta(name) + `"|'` + regexp.QuoteMeta(name) + `')`
+}
+
+// renameEntry returns an editArray edit replacing oldName with newName,
+// keeping the quotes it was written with
+func renameEntry(oldName, newName string) func(string) string {
+	entry := regexp.MustCompile(quotedName(oldName))
+	return func(code string) string {
+		return entry.ReplaceAllStringFunc(code, func(m string) string {
+			return m[:1] + newName + m[:1]
+		})
+	}
+}
+
+// editArray runs edit over the code (the part before any comment) of each
+// line of the key array in every table with the given header, e.g. tools in
+// [[profile]]. Arrays may span several lines; a line edit leaves empty is
+// dropped along with its comment.
+func editArray(data, header, key string, edit func(code string) string) string {
+	lines := strings.Split(data, "\n")
+	out := make([]string, 0, len(lines))
+	rewrite := func(line string) {
+		code, comment, hasComment := strings.Cut(line, "#")
+		rewritten := edit(code)
+		switch {
+		case rewritten == code:
+			out = append(out, line)
+		case strings.TrimSpace(rewritten) == "":
+		case hasComment:
+			out = append(out, rewritten+"#"+comment)
+		default:
+			out = append(out, rewritten)
+		}
+	}
+
+	inTable, inArray := false, false
+	for _, line := range lines {
+		code, _, _ := strings.Cut(line, "#")
+		if inArray {
+			rewrite(line)
+			inArray = !strings.Contains(code, "]")
+			continue
+		}
+		if h := tableHeader(line); h != "" {
+			inTable = h == header
+			out = append(out, line)
+			continue
+		}
+		if k, value, ok := strings.Cut(code, "="); ok && inTable && strings.TrimSpace(k) == key {
+			rewrite(line)
+			inArray = !strings.Contains(value, "]")
+			continue
+		}
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n")
+}
+
+// tableHeader returns "[name]" or "[[name]]" for a table header line, else ""
+func tableHeader(line string) string {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, "[") {
+		return ""
+	}
+	if before, _, ok := strings.Cut(trimmed, "#"); ok {
+		trimmed = strings.TrimSpace(before)
+	}
+	return trimmed
+}
@@ -0,0 +1,119 @@
+package parser
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/ildx/merlin/internal/models"
+)
+
+// LocalConfigName is the per-machine override file read next to the root
+// merlin.toml. It is meant to stay out of git.
+const LocalConfigName = "merlin.local.toml"
+
+// LocalConfigPath returns the override file belonging to a root merlin.toml
+func LocalConfigPath(rootPath string) string {
+	return filepath.Join(filepath.Dir(rootPath), LocalConfigName)
+}
+
+// localConfig is what merlin.local.toml may contain
+type localConfig struct {
+	Profile  string                    `toml:"profile"` // profile made the default on this machine
+	Settings models.Settings           `toml:"settings"`
+	Defaults map[string]map[string]any `toml:"defaults"`
+
+	defined toml.MetaData
+}
+
+// parseLocalConfig reads merlin.local.toml, returning nil when it doesn't exist
+func parseLocalConfig(path string) (*localConfig, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, nil
+	}
+	return cached(path, func() (*localConfig, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", LocalConfigName, err)
+		}
+
+		var local localConfig
+		md, err := toml.Decode(string(data), &local)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", LocalConfigName, err)
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return nil, fmt.Errorf("%s: unsupported key %s (only [settings], [defaults] and profile can be overridden)", LocalConfigName, undecoded[0])
+		}
+		if md.IsDefined("settings", "layout") {
+			return nil, fmt.Errorf("%s: [settings.layout] describes the repository and can't be overridden per machine", LocalConfigName)
+		}
+		local.defined = md
+		return &local, nil
+	})
+}
+
+// applyLocalConfig overlays the keys set in merlin.local.toml on config.
+// Settings and [defaults] flags are replaced key by key (arrays as a whole);
+// profile marks the named profile as the only default.
+func applyLocalConfig(config *models.RootMerlinConfig, local *localConfig) error {
+	overlay(reflect.ValueOf(&config.Settings).Elem(), reflect.ValueOf(local.Settings), local.defined, []string{"settings"})
+	if len(local.Defaults) > 0 {
+		config.Defaults = mergeDefaults(config.Defaults, local.Defaults)
+	}
+
+	if local.Profile == "" {
+		return nil
+	}
+	if config.GetProfileByName(local.Profile) == nil {
+		return fmt.Errorf("%s: profile '%s' is not defined in merlin.toml", LocalConfigName, local.Profile)
+	}
+	// Copy before changing: the parsed root config is shared through the cache
+	profiles := make([]models.Profile, len(config.Profiles))
+	for i, profile := range config.Profiles {
+		profile.Default = profile.Name == local.Profile
+		profiles[i] = profile
+	}
+	config.Profiles = profiles
+	return nil
+}
+
+// overlay copies the fields of src whose TOML key (under path) is defined in
+// md into dst, descending into nested tables
+func overlay(dst, src reflect.Value, md toml.MetaData, path []string) {
+	for i := 0; i < dst.NumField(); i++ {
+		key, _, _ := strings.Cut(dst.Type().Field(i).Tag.Get("toml"), ",")
+		if key == "" || key == "-" {
+			continue
+		}
+		keyPath := append(append([]string{}, path...), key)
+		if !md.IsDefined(keyPath...) {
+			continue
+		}
+		if dst.Field(i).Kind() == reflect.Struct {
+			overlay(dst.Field(i), src.Field(i), md, keyPath)
+			continue
+		}
+		dst.Field(i).Set(src.Field(i))
+	}
+}
+
+// mergeDefaults returns base with the flags of override replacing its own,
+// command by command. base is copied since it belongs to the cached config.
+func mergeDefaults(base, override map[string]map[string]any) map[string]map[string]any {
+	merged := make(map[string]map[string]any, len(base)+len(override))
+	for command, flags := range base {
+		merged[command] = maps.Clone(flags)
+	}
+	for command, flags := range override {
+		if merged[command] == nil {
+			merged[command] = make(map[string]any, len(flags))
+		}
+		maps.Copy(merged[command], flags)
+	}
+	return merged
+}
@@ -0,0 +1,139 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalConfigOverrides(t *testing.T) {
+	dir := t.TempDir()
+	rootPath := filepath.Join(dir, "merlin.toml")
+	root := `
+[settings]
+conflict_strategy = "backup"
+install_retries = 2
+protected_paths = ["~/.ssh/authorized_keys"]
+
+[settings.brew]
+analytics = false
+no_quarantine = true
+
+[[profile]]
+name = "personal"
+default = true
+
+[[profile]]
+name = "work"
+`
+	if err := os.WriteFile(rootPath, []byte(root), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without a local file the tracked config is used as is
+	config, err := ParseRootMerlinTOML(rootPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Settings.ConflictStrategy != "backup" || !config.GetProfileByName("personal").Default {
+		t.Fatalf("unexpected base config: %+v", config)
+	}
+
+	local := `
+profile = "work"
+
+[settings]
+conflict_strategy = "overwrite"
+home_dir = "/Users/me"
+protected_paths = []
+
+[settings.brew]
+no_quarantine = false
+`
+	if err := os.WriteFile(filepath.Join(dir, LocalConfigName), []byte(local), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err = ParseRootMerlinTOML(rootPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := config.Settings
+	if s.ConflictStrategy != "overwrite" || s.HomeDir != "/Users/me" {
+		t.Errorf("overridden settings not applied: %+v", s)
+	}
+	if s.InstallRetries != 2 || s.ConfigDir != "{home_dir}/.config" {
+		t.Errorf("settings missing from the local file should keep their values: %+v", s)
+	}
+	if len(s.ProtectedPaths) != 0 {
+		t.Errorf("protected_paths = %v, want the local empty list", s.ProtectedPaths)
+	}
+	if s.Brew.NoQuarantine || s.Brew.Analytics == nil || *s.Brew.Analytics {
+		t.Errorf("brew settings = %+v, want no_quarantine overridden and analytics kept", s.Brew)
+	}
+	if def := config.GetDefaultProfile(); def == nil || def.Name != "work" {
+		t.Errorf("default profile = %+v, want work", def)
+	}
+
+	// The cached tracked config is not modified by the overlay
+	os.Remove(filepath.Join(dir, LocalConfigName))
+	config, err = ParseRootMerlinTOML(rootPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !config.GetProfileByName("personal").Default || len(config.Settings.ProtectedPaths) != 1 {
+		t.Errorf("base config changed after removing the local file: %+v", config)
+	}
+}
+
+func TestLocalConfigErrors(t *testing.T) {
+	cases := map[string]string{
+		"unknown profile": `profile = "missing"`,
+		"tracked section": "[[profile]]\nname = \"extra\"",
+		"unknown setting": "[settings]\nno_such_key = 1",
+		"layout":          "[settings.layout]\nflat = true",
+	}
+	for name, local := range cases {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			rootPath := filepath.Join(dir, "merlin.toml")
+			os.WriteFile(rootPath, []byte("[[profile]]\nname = \"work\"\n"), 0644)
+			os.WriteFile(filepath.Join(dir, LocalConfigName), []byte(local), 0644)
+
+			_, err := ParseRootMerlinTOML(rootPath)
+			if err == nil || !strings.Contains(err.Error(), LocalConfigName) {
+				t.Errorf("err = %v, want an error naming %s", err, LocalConfigName)
+			}
+		})
+	}
+}
+
+func TestLocalConfigDefaults(t *testing.T) {
+	dir := t.TempDir()
+	rootPath := filepath.Join(dir, "merlin.toml")
+	os.WriteFile(rootPath, []byte("[defaults.link]\nstrategy = \"skip\"\nverbose = 1\n\n[defaults.diff]\njson = true\n"), 0644)
+	os.WriteFile(filepath.Join(dir, LocalConfigName), []byte("[defaults.link]\nstrategy = \"backup\"\n\n[defaults.install]\nretries = 4\n"), 0644)
+
+	config, err := ParseRootMerlinTOML(rootPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := config.Defaults
+	if d["link"]["strategy"] != "backup" || d["link"]["verbose"] != int64(1) {
+		t.Errorf("link defaults = %v, want strategy overridden and verbose kept", d["link"])
+	}
+	if d["diff"]["json"] != true || d["install"]["retries"] != int64(4) {
+		t.Errorf("defaults = %v", d)
+	}
+
+	// The cached tracked config keeps its own values
+	os.Remove(filepath.Join(dir, LocalConfigName))
+	config, err = ParseRootMerlinTOML(rootPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Defaults["link"]["strategy"] != "skip" || config.Defaults["install"] != nil {
+		t.Errorf("base defaults changed by the overlay: %v", config.Defaults)
+	}
+}
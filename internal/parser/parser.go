@@ -3,6 +3,9 @@ package parser
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 
 	"github.com/BurntSushi/toml"
 	"github.com/ildx/merlin/internal/models"
@@ -10,65 +13,93 @@ import (
 
 // ParseBrewTOML parses a brew.toml file
 func ParseBrewTOML(path string) (*models.BrewConfig, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read brew.toml: %w", err)
-	}
+	return parseBrewFile(path, "brew.toml")
+}
 
-	var config models.BrewConfig
-	if err := toml.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse brew.toml: %w", err)
-	}
+// parseBrewFile parses a brew.toml or brew.d file; name is used in errors
+func parseBrewFile(path, name string) (*models.BrewConfig, error) {
+	return cached(path, func() (*models.BrewConfig, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
 
-	return &config, nil
+		var config models.BrewConfig
+		if err := toml.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+
+		return &config, nil
+	})
 }
 
 // ParseMASTOML parses a mas.toml file
 func ParseMASTOML(path string) (*models.MASConfig, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read mas.toml: %w", err)
-	}
+	return cached(path, func() (*models.MASConfig, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read mas.toml: %w", err)
+		}
 
-	var config models.MASConfig
-	if err := toml.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse mas.toml: %w", err)
-	}
+		var config models.MASConfig
+		if err := toml.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("failed to parse mas.toml: %w", err)
+		}
 
-	return &config, nil
+		return &config, nil
+	})
 }
 
-// ParseRootMerlinTOML parses the root merlin.toml file
+// ParseRootMerlinTOML parses the root merlin.toml file, with the overrides
+// of a merlin.local.toml next to it applied
 func ParseRootMerlinTOML(path string) (*models.RootMerlinConfig, error) {
-	data, err := os.ReadFile(path)
+	root, err := cached(path, func() (*models.RootMerlinConfig, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read root merlin.toml: %w", err)
+		}
+
+		var config models.RootMerlinConfig
+		if err := toml.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("failed to parse root merlin.toml: %w", err)
+		}
+
+		// Set defaults for settings if not provided
+		setRootConfigDefaults(&config)
+
+		return &config, nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to read root merlin.toml: %w", err)
+		return nil, err
 	}
 
-	var config models.RootMerlinConfig
-	if err := toml.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse root merlin.toml: %w", err)
+	local, err := parseLocalConfig(LocalConfigPath(path))
+	if err != nil {
+		return nil, err
 	}
-
-	// Set defaults for settings if not provided
-	setRootConfigDefaults(&config)
-
-	return &config, nil
+	if local != nil {
+		if err := applyLocalConfig(root, local); err != nil {
+			return nil, err
+		}
+	}
+	return root, nil
 }
 
 // ParseToolMerlinTOML parses a per-tool merlin.toml file
 func ParseToolMerlinTOML(path string) (*models.ToolMerlinConfig, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read tool merlin.toml: %w", err)
-	}
+	return cached(path, func() (*models.ToolMerlinConfig, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tool merlin.toml: %w", err)
+		}
 
-	var config models.ToolMerlinConfig
-	if err := toml.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse tool merlin.toml: %w", err)
-	}
+		var config models.ToolMerlinConfig
+		if err := toml.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("failed to parse tool merlin.toml: %w", err)
+		}
 
-	return &config, nil
+		return &config, nil
+	})
 }
 
 // setRootConfigDefaults sets default values for root config if not specified
@@ -98,11 +129,51 @@ func ValidateBrewConfig(config *models.BrewConfig) error {
 			return fmt.Errorf("duplicate package name: %s", pkg.Name)
 		}
 		seen[pkg.Name] = true
+		if err := ValidateBrewArch(pkg); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
+// ValidateBrewArch checks a package's arch hint names an architecture with
+// a known Homebrew prefix
+func ValidateBrewArch(pkg models.BrewPackage) error {
+	switch pkg.Arch {
+	case "", "arm64", "x86_64":
+		return nil
+	}
+	return fmt.Errorf("package %s: invalid arch %q (must be: arm64 or x86_64)", pkg.Name, pkg.Arch)
+}
+
+// envName matches an environment variable name
+var envName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// secretPrefixes start GitHub tokens, which belong in the environment, not
+// in auth_env
+var secretPrefixes = []string{"ghp_", "gho_", "ghu_", "ghs_", "github_pat_"}
+
+// ValidateBrewTap checks a [[tap]] is named "owner/repo" and its auth_env
+// holds variable names rather than secrets
+func ValidateBrewTap(tap models.BrewTap) error {
+	owner, repo, ok := strings.Cut(tap.Name, "/")
+	if !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+		return fmt.Errorf("tap %q: name must be owner/repo", tap.Name)
+	}
+	for _, name := range tap.AuthEnv {
+		for _, prefix := range secretPrefixes {
+			if strings.HasPrefix(name, prefix) {
+				return fmt.Errorf("tap %s: auth_env looks like a token; list the variable holding it (e.g. HOMEBREW_GITHUB_API_TOKEN), never the secret", tap.Name)
+			}
+		}
+		if !envName.MatchString(name) {
+			return fmt.Errorf("tap %s: auth_env %q is not an environment variable name", tap.Name, name)
+		}
+	}
+	return nil
+}
+
 // ValidateMASConfig validates a MASConfig
 func ValidateMASConfig(config *models.MASConfig) error {
 	if len(config.Apps) == 0 {
@@ -159,7 +230,7 @@ func ValidateRootMerlinConfig(config *models.RootMerlinConfig) error {
 			config.Settings.ConflictStrategy)
 	}
 
-	return nil
+	return ValidateManualSteps(config.ManualSteps)
 }
 
 // ValidateToolMerlinConfig validates a ToolMerlinConfig
@@ -173,8 +244,125 @@ func ValidateToolMerlinConfig(config *models.ToolMerlinConfig) error {
 		if link.Target == "" {
 			return fmt.Errorf("link[%d]: target is required", i)
 		}
+		if err := ValidateRename(link.Rename); err != nil {
+			return fmt.Errorf("link[%d]: %w", i, err)
+		}
 	}
+	if err := ValidateLinkOrder(config.Links); err != nil {
+		return err
+	}
+
+	for i, bin := range config.Bins {
+		if bin.Source == "" {
+			return fmt.Errorf("bin[%d]: source is required", i)
+		}
+		if bin.Name != "" && strings.ContainsRune(bin.Name, '/') {
+			return fmt.Errorf("bin[%d]: name must be a file name, got %q", i, bin.Name)
+		}
+	}
+
+	switch config.Scripts.OnError {
+	case "", models.OnErrorStop, models.OnErrorContinue:
+	default:
+		return fmt.Errorf("invalid scripts.on_error: %s (must be: stop or continue)", config.Scripts.OnError)
+	}
+
+	if err := ValidatePermissions(config.Tool.Permissions); err != nil {
+		return err
+	}
+	if err := ValidateManualSteps(config.ManualSteps); err != nil {
+		return err
+	}
+	return ValidateExtensions(config.Extensions)
+}
 
+// ValidatePermissions checks [tool] permissions against the permissions
+// merlin knows how to report
+func ValidatePermissions(permissions []string) error {
+	for _, p := range permissions {
+		switch p {
+		case models.PermissionFullDiskAccess, models.PermissionScreenRecording,
+			models.PermissionAccessibility, models.PermissionInputMonitoring:
+		default:
+			return fmt.Errorf("invalid tool.permissions entry %q (must be: full_disk_access, screen_recording, accessibility or input_monitoring)", p)
+		}
+	}
 	return nil
 }
 
+// ValidateManualSteps checks [[manual_step]] entries: an id without "/"
+// (tool steps are addressed as tool/id), a description, and no duplicate ids
+func ValidateManualSteps(steps []models.ManualStep) error {
+	seen := make(map[string]bool)
+	for i, step := range steps {
+		if step.ID == "" {
+			return fmt.Errorf("manual_step[%d]: id is required", i)
+		}
+		if strings.ContainsAny(step.ID, "/ \t") {
+			return fmt.Errorf("manual_step[%d]: invalid id %q (no slashes or spaces)", i, step.ID)
+		}
+		if strings.TrimSpace(step.Description) == "" {
+			return fmt.Errorf("manual_step[%d]: description is required", i)
+		}
+		if seen[step.ID] {
+			return fmt.Errorf("duplicate manual_step id: %s", step.ID)
+		}
+		seen[step.ID] = true
+	}
+	return nil
+}
+
+// extensionIDPattern matches marketplace identifiers (publisher.name)
+var extensionIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]*\.[A-Za-z0-9][A-Za-z0-9-]*$`)
+
+// ValidateExtensions checks [[extension]] entries: a supported editor, a
+// publisher.name id, and no duplicates (ids are case-insensitive)
+func ValidateExtensions(extensions []models.Extension) error {
+	seen := make(map[string]bool)
+	for i, ext := range extensions {
+		switch ext.Editor {
+		case models.EditorCode, models.EditorCursor:
+		default:
+			return fmt.Errorf("extension[%d]: invalid editor %q (must be: code or cursor)", i, ext.Editor)
+		}
+		if !extensionIDPattern.MatchString(ext.ID) {
+			return fmt.Errorf("extension[%d]: invalid id %q (must be publisher.name)", i, ext.ID)
+		}
+		key := ext.Editor + ":" + strings.ToLower(ext.ID)
+		if seen[key] {
+			return fmt.Errorf("duplicate extension: %s for %s", ext.ID, ext.Editor)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// ValidateLinkOrder rejects [[link]] entries sharing an explicit order value;
+// such ties would silently fall back to file order
+func ValidateLinkOrder(links []models.Link) error {
+	seen := make(map[int]int)
+	for i, link := range links {
+		if link.Order == 0 {
+			continue
+		}
+		if first, ok := seen[link.Order]; ok {
+			return fmt.Errorf("link[%d]: order %d is already used by link[%d]", i, link.Order, first)
+		}
+		seen[link.Order] = i
+	}
+	return nil
+}
+
+// ValidateRename checks that contents-mode rename rules map relative paths to
+// relative paths that stay under the link target
+func ValidateRename(rename map[string]string) error {
+	for from, to := range rename {
+		for _, p := range []string{from, to} {
+			cleaned := filepath.ToSlash(filepath.Clean(p))
+			if p == "" || filepath.IsAbs(p) || cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+				return fmt.Errorf("invalid rename %q = %q: paths must be relative and stay inside the link", from, to)
+			}
+		}
+	}
+	return nil
+}
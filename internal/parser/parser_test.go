@@ -1,9 +1,13 @@
 package parser
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ildx/merlin/internal/models"
 )
@@ -71,6 +75,37 @@ dependencies = []
 		}
 	})
 
+	t.Run("when conditions", func(t *testing.T) {
+		content := `
+[[brew]]
+name = "asitop"
+when = { arch = "arm64", hostname = "studio" }
+
+[[cask]]
+name = "microsoft-teams"
+when = { profile = ["work", "lab"] }
+`
+		path := createTestFile(t, content)
+		defer os.Remove(path)
+
+		config, err := ParseBrewTOML(path)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if when := config.Formulae[0].When; when == nil || len(when.Arch) != 1 || when.Arch[0] != "arm64" || when.Hostname[0] != "studio" {
+			t.Errorf("formula when = %+v", when)
+		}
+		if when := config.Casks[0].When; when == nil || len(when.Profile) != 2 || when.Profile[1] != "lab" {
+			t.Errorf("cask when = %+v", when)
+		}
+
+		bad := createTestFile(t, "[[brew]]\nname = \"x\"\nwhen = { arch = 64 }\n")
+		defer os.Remove(bad)
+		if _, err := ParseBrewTOML(bad); err == nil {
+			t.Error("expected error for a non-string when value")
+		}
+	})
+
 	t.Run("invalid TOML", func(t *testing.T) {
 		content := `invalid toml content [[[`
 		path := createTestFile(t, content)
@@ -83,6 +118,79 @@ dependencies = []
 	})
 }
 
+func TestParseBrewConfig(t *testing.T) {
+	brewDir := t.TempDir()
+	write := func(rel, content string) {
+		t.Helper()
+		path := filepath.Join(brewDir, rel)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if HasBrewConfig(brewDir) {
+		t.Error("empty directory should have no brew config")
+	}
+	if _, err := ParseBrewConfig(brewDir); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected a not-exist error, got %v", err)
+	}
+
+	write("brew.toml", "[metadata]\nversion = \"1.0.0\"\n\n[[brew]]\nname = \"git\"\n")
+	write("brew.d/media.toml", "[[cask]]\nname = \"vlc\"\n")
+	write("brew.d/dev.toml", "[[tap]]\nname = \"acme/tools\"\nauth_env = [\"ACME_TOKEN\"]\n\n[[brew]]\nname = \"go\"\n\n[[cask]]\nname = \"docker\"\n")
+	write("brew.d/README.md", "not a package list")
+
+	files := BrewConfigFiles(brewDir)
+	var names []string
+	for _, path := range files {
+		names = append(names, BrewFileName(brewDir, path))
+	}
+	if got := strings.Join(names, ","); got != "brew.toml,brew.d/dev.toml,brew.d/media.toml" {
+		t.Errorf("BrewConfigFiles() = %s", got)
+	}
+
+	config, err := ParseBrewConfig(brewDir)
+	if err != nil {
+		t.Fatalf("ParseBrewConfig() error = %v", err)
+	}
+	if config.Metadata.Version != "1.0.0" {
+		t.Errorf("metadata should come from brew.toml, got %+v", config.Metadata)
+	}
+	if len(config.Formulae) != 2 || config.Formulae[1].Name != "go" {
+		t.Errorf("Formulae = %+v", config.Formulae)
+	}
+	if len(config.Casks) != 2 || config.Casks[0].Name != "docker" || config.Casks[1].Name != "vlc" {
+		t.Errorf("Casks = %+v", config.Casks)
+	}
+	if len(config.Taps) != 1 || config.Taps[0].Remote() != "https://github.com/acme/homebrew-tools" {
+		t.Errorf("Taps = %+v", config.Taps)
+	}
+
+	write("brew.d/broken.toml", "[[brew]\n")
+	if _, err := ParseBrewConfig(brewDir); err == nil || !strings.Contains(err.Error(), "brew.d/broken.toml") {
+		t.Errorf("expected the broken file to be named, got %v", err)
+	}
+}
+
+func TestValidateBrewTap(t *testing.T) {
+	for _, tc := range []struct {
+		tap  models.BrewTap
+		want string // error substring, "" for valid
+	}{
+		{models.BrewTap{Name: "acme/tools", AuthEnv: []string{"HOMEBREW_GITHUB_API_TOKEN"}}, ""},
+		{models.BrewTap{Name: "acme"}, "owner/repo"},
+		{models.BrewTap{Name: "acme/tools/extra"}, "owner/repo"},
+		{models.BrewTap{Name: "acme/tools", AuthEnv: []string{"ghp_abc123"}}, "looks like a token"},
+		{models.BrewTap{Name: "acme/tools", AuthEnv: []string{"$TOKEN"}}, "not an environment variable name"},
+	} {
+		err := ValidateBrewTap(tc.tap)
+		if tc.want == "" && err != nil || tc.want != "" && (err == nil || !strings.Contains(err.Error(), tc.want)) {
+			t.Errorf("ValidateBrewTap(%+v) = %v, want %q", tc.tap, err, tc.want)
+		}
+	}
+}
+
 func TestParseMASTOML(t *testing.T) {
 	t.Run("valid mas.toml", func(t *testing.T) {
 		content := `
@@ -206,6 +314,72 @@ tools = []
 		if config.Settings.ConfigDir != "{home_dir}/.config" {
 			t.Errorf("expected default config_dir, got %s", config.Settings.ConfigDir)
 		}
+
+		if config.Settings.Brew.Analytics != nil || len(config.Settings.Brew.Env()) != 0 {
+			t.Errorf("expected brew settings to be left alone, got %+v", config.Settings.Brew)
+		}
+	})
+
+	t.Run("brew settings", func(t *testing.T) {
+		content := `
+[metadata]
+name = "test-dotfiles"
+
+[settings.brew]
+analytics = false
+no_quarantine = true
+greedy_upgrades = false
+cask_appdir = "~/Applications"
+`
+		path := createTestFile(t, content)
+		defer os.Remove(path)
+
+		config, err := ParseRootMerlinTOML(path)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+
+		brew := config.Settings.Brew
+		if brew.Analytics == nil || *brew.Analytics {
+			t.Errorf("expected analytics = false, got %v", brew.Analytics)
+		}
+		if !brew.NoQuarantine || brew.GreedyUpgrades || brew.CaskAppdir != "~/Applications" {
+			t.Errorf("unexpected brew settings: %+v", brew)
+		}
+		env := brew.Env()
+		if len(env) != 1 || env["HOMEBREW_NO_ANALYTICS"] != "1" {
+			t.Errorf("expected only HOMEBREW_NO_ANALYTICS, got %v", env)
+		}
+	})
+
+	t.Run("require_merlin_toml", func(t *testing.T) {
+		tests := []struct {
+			value   string
+			want    models.Requirement
+			wantErr bool
+		}{
+			{"true", models.RequirementEnforced, false},
+			{"false", models.RequirementOff, false},
+			{`"warn"`, models.RequirementWarn, false},
+			{`"yes"`, "", true},
+		}
+		for _, tt := range tests {
+			path := createTestFile(t, "[settings]\nrequire_merlin_toml = "+tt.value+"\n")
+			config, err := ParseRootMerlinTOML(path)
+			os.Remove(path)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("require_merlin_toml = %s: expected an error", tt.value)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatalf("require_merlin_toml = %s: %v", tt.value, err)
+			}
+			if got := config.Settings.RequireMerlinTOML; got != tt.want {
+				t.Errorf("require_merlin_toml = %s: got %q, want %q", tt.value, got, tt.want)
+			}
+		}
 	})
 }
 
@@ -335,6 +509,96 @@ scripts = [
 			t.Errorf("expected altname.sh with 1 tag, got %s (%d tags)", config.Scripts.Scripts[2].File, len(config.Scripts.Scripts[2].Tags))
 		}
 	})
+
+	t.Run("tool with script env", func(t *testing.T) {
+		content := `
+[tool]
+name = "cursor"
+
+[scripts]
+scripts = [
+  "plain.sh",
+  { file = "extensions.sh", dry_run_supported = true, env = { EXT_LIST = "{tool_root}/config/extensions.txt" } }
+]
+
+[scripts.env]
+CURSOR_USER_DIR = "{home_dir}/Library/Application Support/Cursor/User"
+`
+		path := createTestFile(t, content)
+		defer os.Remove(path)
+
+		config, err := ParseToolMerlinTOML(path)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+
+		if got := config.Scripts.Env["CURSOR_USER_DIR"]; got != "{home_dir}/Library/Application Support/Cursor/User" {
+			t.Errorf("unexpected [scripts.env] value: %q", got)
+		}
+		if len(config.Scripts.Scripts[0].Env) != 0 {
+			t.Errorf("plain script should have no env, got %v", config.Scripts.Scripts[0].Env)
+		}
+		if got := config.Scripts.Scripts[1].Env["EXT_LIST"]; got != "{tool_root}/config/extensions.txt" {
+			t.Errorf("unexpected per-script env value: %q", got)
+		}
+		if config.Scripts.Scripts[0].DryRunSupported || !config.Scripts.Scripts[1].DryRunSupported {
+			t.Error("expected dry_run_supported only on extensions.sh")
+		}
+	})
+
+	t.Run("tool with script args and params", func(t *testing.T) {
+		content := `
+[tool]
+name = "aws"
+
+[[scripts.scripts]]
+file = "configure.sh"
+args = ["--region", "{region}"]
+params = [{ name = "region", default = "eu", description = "AWS region" }, { name = "account" }]
+`
+		path := createTestFile(t, content)
+		defer os.Remove(path)
+
+		config, err := ParseToolMerlinTOML(path)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		item := config.Scripts.Scripts[0]
+		if len(item.Args) != 2 || item.Args[1] != "{region}" {
+			t.Errorf("unexpected args: %v", item.Args)
+		}
+		want := []models.ScriptParam{{Name: "region", Default: "eu", Description: "AWS region"}, {Name: "account"}}
+		if len(item.Params) != 2 || item.Params[0] != want[0] || item.Params[1] != want[1] {
+			t.Errorf("params = %+v, want %+v", item.Params, want)
+		}
+
+		bad := createTestFile(t, "[tool]\nname = \"aws\"\n\n[[scripts.scripts]]\nfile = \"x.sh\"\nparams = [{ default = \"eu\" }]\n")
+		defer os.Remove(bad)
+		if _, err := ParseToolMerlinTOML(bad); err == nil || !strings.Contains(err.Error(), "name") {
+			t.Errorf("expected missing param name error, got %v", err)
+		}
+	})
+
+	t.Run("optional links", func(t *testing.T) {
+		path := createTestFile(t, "[tool]\nname = \"ssh\"\n\n[[link]]\nsource = \"work\"\ntarget = \"{home_dir}/.ssh/work\"\noptional = true\n\n[[link]]\nsource = \"extra\"\ntarget = \"{home_dir}/.ssh/extra\"\noptional = \"warn\"\n\n[[link]]\nsource = \"config\"\ntarget = \"{home_dir}/.ssh/config\"\n")
+		defer os.Remove(path)
+		config, err := ParseToolMerlinTOML(path)
+		if err != nil {
+			t.Fatalf("ParseToolMerlinTOML() error = %v", err)
+		}
+		want := []models.Optional{models.OptionalYes, models.OptionalWarn, models.OptionalNo}
+		for i, link := range config.Links {
+			if link.Optional != want[i] {
+				t.Errorf("link %d: optional = %q, want %q", i, link.Optional, want[i])
+			}
+		}
+
+		bad := createTestFile(t, "[tool]\nname = \"ssh\"\n\n[[link]]\ntarget = \"x\"\noptional = \"maybe\"\n")
+		defer os.Remove(bad)
+		if _, err := ParseToolMerlinTOML(bad); err == nil {
+			t.Error("expected an error for optional = \"maybe\"")
+		}
+	})
 }
 
 func TestValidateBrewConfig(t *testing.T) {
@@ -375,6 +639,20 @@ func TestValidateBrewConfig(t *testing.T) {
 			t.Error("expected error for duplicate package name")
 		}
 	})
+
+	t.Run("arch hint", func(t *testing.T) {
+		config := &models.BrewConfig{
+			Formulae: []models.BrewPackage{{Name: "node@18", Arch: "x86_64"}},
+		}
+		if err := ValidateBrewConfig(config); err != nil {
+			t.Errorf("expected x86_64 to be accepted, got: %v", err)
+		}
+
+		config.Formulae[0].Arch = "amd64"
+		if err := ValidateBrewConfig(config); err == nil {
+			t.Error("expected error for unknown arch")
+		}
+	})
 }
 
 func TestValidateRootMerlinConfig(t *testing.T) {
@@ -426,6 +704,169 @@ func TestValidateRootMerlinConfig(t *testing.T) {
 	})
 }
 
+func TestValidateToolMerlinConfig(t *testing.T) {
+	t.Run("bin entries", func(t *testing.T) {
+		for _, bin := range []models.Bin{{}, {Source: "bin/deploy.sh", Name: "sub/deploy"}} {
+			config := &models.ToolMerlinConfig{
+				Tool: models.ToolInfo{Name: "scripts"},
+				Bins: []models.Bin{bin},
+			}
+			if err := ValidateToolMerlinConfig(config); err == nil {
+				t.Errorf("bin %+v: expected an error", bin)
+			}
+		}
+	})
+
+	t.Run("link order", func(t *testing.T) {
+		links := []models.Link{{Target: "a", Order: 1}, {Target: "b"}, {Target: "c"}, {Target: "d", Order: 2}}
+		config := &models.ToolMerlinConfig{Tool: models.ToolInfo{Name: "nvim"}, Links: links}
+		if err := ValidateToolMerlinConfig(config); err != nil {
+			t.Errorf("distinct orders: expected no error, got: %v", err)
+		}
+		config.Links = append(config.Links, models.Link{Target: "e", Order: 1})
+		if err := ValidateToolMerlinConfig(config); err == nil || !strings.Contains(err.Error(), "link[0]") {
+			t.Errorf("duplicate order: got %v, want an error naming link[0]", err)
+		}
+	})
+
+	t.Run("valid on_error", func(t *testing.T) {
+		for _, onError := range []string{"", models.OnErrorStop, models.OnErrorContinue} {
+			config := &models.ToolMerlinConfig{
+				Tool:    models.ToolInfo{Name: "cursor"},
+				Scripts: models.ScriptsSection{OnError: onError},
+			}
+			if err := ValidateToolMerlinConfig(config); err != nil {
+				t.Errorf("on_error %q: expected no error, got: %v", onError, err)
+			}
+		}
+	})
+
+	t.Run("invalid on_error", func(t *testing.T) {
+		config := &models.ToolMerlinConfig{
+			Tool:    models.ToolInfo{Name: "cursor"},
+			Scripts: models.ScriptsSection{OnError: "ignore"},
+		}
+
+		err := ValidateToolMerlinConfig(config)
+		if err == nil {
+			t.Error("expected error for invalid on_error")
+		}
+	})
+
+	t.Run("extensions", func(t *testing.T) {
+		valid := []models.Extension{
+			{Editor: models.EditorCursor, ID: "esbenp.prettier-vscode"},
+			{Editor: models.EditorCode, ID: "esbenp.prettier-vscode"},
+			{Editor: models.EditorCode, ID: "golang.Go"},
+		}
+		if err := ValidateExtensions(valid); err != nil {
+			t.Errorf("expected no error, got: %v", err)
+		}
+
+		invalid := map[string][]models.Extension{
+			"unknown editor": {{Editor: "vim", ID: "a.b"}},
+			"missing name":   {{Editor: models.EditorCode, ID: "prettier"}},
+			"duplicate":      {{Editor: models.EditorCode, ID: "golang.go"}, {Editor: models.EditorCode, ID: "golang.Go"}},
+		}
+		for name, exts := range invalid {
+			config := &models.ToolMerlinConfig{Tool: models.ToolInfo{Name: "cursor"}, Extensions: exts}
+			if err := ValidateToolMerlinConfig(config); err == nil {
+				t.Errorf("%s: expected error", name)
+			}
+		}
+	})
+
+	t.Run("permissions", func(t *testing.T) {
+		config := &models.ToolMerlinConfig{Tool: models.ToolInfo{Name: "karabiner", Permissions: []string{models.PermissionInputMonitoring}}}
+		if err := ValidateToolMerlinConfig(config); err != nil {
+			t.Errorf("expected no error, got: %v", err)
+		}
+		config.Tool.Permissions = append(config.Tool.Permissions, "camera")
+		if err := ValidateToolMerlinConfig(config); err == nil {
+			t.Error("expected error for unknown permission")
+		}
+	})
+
+	t.Run("manual steps", func(t *testing.T) {
+		valid := []models.ManualStep{
+			{ID: "input-monitoring", Description: "Grant Input Monitoring"},
+			{ID: "sign-in", Description: "Sign in", URL: "https://example.com"},
+		}
+		if err := ValidateManualSteps(valid); err != nil {
+			t.Errorf("expected no error, got: %v", err)
+		}
+
+		invalid := map[string][]models.ManualStep{
+			"missing id":          {{Description: "Sign in"}},
+			"slash in id":         {{ID: "app/sign-in", Description: "Sign in"}},
+			"missing description": {{ID: "sign-in"}},
+			"duplicate":           {{ID: "sign-in", Description: "a"}, {ID: "sign-in", Description: "b"}},
+		}
+		for name, steps := range invalid {
+			config := &models.ToolMerlinConfig{Tool: models.ToolInfo{Name: "karabiner"}, ManualSteps: steps}
+			if err := ValidateToolMerlinConfig(config); err == nil {
+				t.Errorf("%s: expected error", name)
+			}
+		}
+	})
+}
+
+func TestSetToolEnabled(t *testing.T) {
+	const original = `# zsh setup
+[tool]
+name = "zsh"
+description = "Shell" # login shell
+
+[[link]]
+source = "config"
+target = "{home_dir}"
+`
+
+	parse := func(t *testing.T, data string) *models.ToolMerlinConfig {
+		t.Helper()
+		config, err := ParseToolMerlinTOML(createTestFile(t, data))
+		if err != nil {
+			t.Fatalf("rewritten file does not parse: %v\n%s", err, data)
+		}
+		return config
+	}
+
+	disabled := SetToolEnabled(original, "zsh", false)
+	config := parse(t, disabled)
+	if config.IsEnabled() {
+		t.Errorf("expected tool to be disabled:\n%s", disabled)
+	}
+	if config.Tool.Description != "Shell" || len(config.Links) != 1 {
+		t.Errorf("other settings changed: %+v", config)
+	}
+	if !strings.Contains(disabled, "# login shell") || !strings.HasPrefix(disabled, "# zsh setup") {
+		t.Errorf("comments not preserved:\n%s", disabled)
+	}
+
+	if again := SetToolEnabled(disabled, "zsh", false); again != disabled {
+		t.Errorf("disabling twice changed the file:\n%s", again)
+	}
+
+	if enabled := SetToolEnabled(disabled, "zsh", true); enabled != original {
+		t.Errorf("enabling did not restore the original:\n%s", enabled)
+	}
+
+	t.Run("replaces explicit value", func(t *testing.T) {
+		data := SetToolEnabled("[tool]\nname = \"zsh\"\nenabled = true\n", "zsh", false)
+		if parse(t, data).IsEnabled() {
+			t.Errorf("expected tool to be disabled:\n%s", data)
+		}
+	})
+
+	t.Run("adds tool table", func(t *testing.T) {
+		data := SetToolEnabled("[[link]]\ntarget = \"{config_dir}/zsh\"\n", "zsh", false)
+		config := parse(t, data)
+		if config.IsEnabled() || config.Tool.Name != "zsh" || len(config.Links) != 1 {
+			t.Errorf("unexpected config: %+v\n%s", config, data)
+		}
+	})
+}
+
 // Test with real Covenant files (if available)
 func TestParseRealCovenantFiles(t *testing.T) {
 	covenantPath := "/Users/iivo/Development/personal/covenant"
@@ -545,3 +986,199 @@ func TestParseRealCovenantFiles(t *testing.T) {
 		t.Logf("Found %d links and %d scripts", len(config.Links), len(config.Scripts.Scripts))
 	})
 }
+
+func TestParseCache(t *testing.T) {
+	defer resetCache()
+	path := createTestFile(t, "[tool]\nname = \"zsh\"\n")
+	defer os.Remove(path)
+
+	first, err := ParseToolMerlinTOML(path)
+	if err != nil {
+		t.Fatalf("ParseToolMerlinTOML() error = %v", err)
+	}
+	first.Tool.Name = "changed by caller"
+
+	second, err := ParseToolMerlinTOML(path)
+	if err != nil {
+		t.Fatalf("ParseToolMerlinTOML() error = %v", err)
+	}
+	if second.Tool.Name != "zsh" {
+		t.Errorf("caller's change leaked into the cache: %q", second.Tool.Name)
+	}
+
+	// Served from cache while the file is unchanged
+	parseCache.Lock()
+	abs, _ := filepath.Abs(path)
+	entry := parseCache.entries[abs]
+	entry.value.(*models.ToolMerlinConfig).Tool.Description = "cached"
+	parseCache.Unlock()
+	if third, _ := ParseToolMerlinTOML(path); third.Tool.Description != "cached" {
+		t.Error("expected unchanged file to be served from cache")
+	}
+
+	// A new mtime invalidates the entry
+	if err := os.WriteFile(path, []byte("[tool]\nname = \"fish\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Second)
+	os.Chtimes(path, later, later)
+	fourth, err := ParseToolMerlinTOML(path)
+	if err != nil {
+		t.Fatalf("ParseToolMerlinTOML() error = %v", err)
+	}
+	if fourth.Tool.Name != "fish" || fourth.Tool.Description != "" {
+		t.Errorf("expected re-parse after change, got %+v", fourth.Tool)
+	}
+
+	// Errors are not cached
+	os.WriteFile(path, []byte("not toml ["), 0644)
+	if _, err := ParseToolMerlinTOML(path); err == nil {
+		t.Error("expected parse error")
+	}
+}
+
+func TestAppendBrewEntries(t *testing.T) {
+	const original = `# CLI tools
+[[brew]]
+name = "git"
+`
+	data := AppendBrewEntries(original, "brew", []models.BrewPackage{
+		{Name: "libvips", Description: `Image "processing" library`, Reason: "thumbnails", AddedBy: "merlin audit brew", AddedDate: "2024-03-01"},
+		{Name: "jq"},
+	})
+	if !strings.HasPrefix(data, original) {
+		t.Errorf("existing text changed:\n%s", data)
+	}
+
+	config, err := ParseBrewTOML(createTestFile(t, data))
+	if err != nil {
+		t.Fatalf("appended file does not parse: %v\n%s", err, data)
+	}
+	if len(config.Formulae) != 3 {
+		t.Fatalf("expected 3 formulae, got %d:\n%s", len(config.Formulae), data)
+	}
+	vips := config.Formulae[1]
+	if vips.Description != `Image "processing" library` || vips.Reason != "thumbnails" ||
+		vips.AddedBy != "merlin audit brew" || vips.AddedDate != "2024-03-01" {
+		t.Errorf("provenance not round-tripped: %+v", vips)
+	}
+	if jq := config.Formulae[2]; jq.Name != "jq" || jq.AddedDate != "" {
+		t.Errorf("unexpected entry: %+v", jq)
+	}
+
+	t.Run("quoted date", func(t *testing.T) {
+		config, err := ParseBrewTOML(createTestFile(t, "[[brew]]\nname = \"jq\"\nadded_date = \"2024-03-01\"\n"))
+		if err != nil || config.Formulae[0].AddedDate != "2024-03-01" {
+			t.Errorf("quoted date: %v %+v", err, config)
+		}
+	})
+
+	t.Run("invalid date", func(t *testing.T) {
+		if _, err := ParseBrewTOML(createTestFile(t, "[[brew]]\nname = \"jq\"\nadded_date = \"March\"\n")); err == nil {
+			t.Error("expected error for invalid date")
+		}
+	})
+}
+
+func TestRemoveProfileTool(t *testing.T) {
+	const original = `[settings]
+tools = ["zsh"] # not a profile
+
+[[profile]]
+name = "full"
+tools = ["git", "zsh", "nvim"] # everything
+
+[[profile]]
+name = "work"
+tools = [
+  "zsh",
+  "git", # version control
+  'nvim', "zsh-extras",
+]
+
+[[profile]]
+name = "minimal"
+tools = ["zsh"]
+`
+	const want = `[settings]
+tools = ["zsh"] # not a profile
+
+[[profile]]
+name = "full"
+tools = ["git", "nvim"] # everything
+
+[[profile]]
+name = "work"
+tools = [
+  "git", # version control
+  'nvim', "zsh-extras",
+]
+
+[[profile]]
+name = "minimal"
+tools = []
+`
+	if got := RemoveProfileTool(original, "zsh"); got != want {
+		t.Errorf("RemoveProfileTool =\n%s\nwant\n%s", got, want)
+	}
+	if got := RemoveProfileTool(want, "nvim"); !strings.Contains(got, `tools = ["git"] # everything`) || !strings.Contains(got, "  \"zsh-extras\",\n") {
+		t.Errorf("removing nvim:\n%s", got)
+	}
+	if got := RemoveProfileTool(original, "alacritty"); got != original {
+		t.Errorf("removing an unlisted tool changed the file:\n%s", got)
+	}
+}
+
+func TestRenameTool(t *testing.T) {
+	const profiles = `[[profile]]
+name = "full"
+tools = ["nvim", 'vim', "vim-extras"] # editors
+
+[[profile]]
+name = "work"
+tools = [
+  "vim", # old editor
+]
+`
+	const wantProfiles = `[[profile]]
+name = "full"
+tools = ["nvim", 'neovim', "vim-extras"] # editors
+
+[[profile]]
+name = "work"
+tools = [
+  "neovim", # old editor
+]
+`
+	if got := RenameProfileTool(profiles, "vim", "neovim"); got != wantProfiles {
+		t.Errorf("RenameProfileTool =\n%s\nwant\n%s", got, wantProfiles)
+	}
+
+	const tool = `# my editor
+[tool]
+name = "vim" # was vi
+dependencies = ["vim-plug", "vim"]
+
+[[bin]]
+name = "vim"
+source = "bin/vim"
+`
+	renamed := RenameToolDependency(SetToolName(tool, "neovim"), "vim", "nvim")
+	config, err := ParseToolMerlinTOML(createTestFile(t, renamed))
+	if err != nil {
+		t.Fatalf("renamed file does not parse: %v\n%s", err, renamed)
+	}
+	if config.Tool.Name != "neovim" || !slices.Equal(config.Tool.Dependencies, []string{"vim-plug", "nvim"}) {
+		t.Errorf("unexpected [tool] %+v", config.Tool)
+	}
+	if !strings.Contains(renamed, `name = "neovim" # was vi`) || !strings.Contains(renamed, "[[bin]]\nname = \"vim\"") {
+		t.Errorf("rewrote more than the [tool] name:\n%s", renamed)
+	}
+
+	if got := SetToolName("[[link]]\ntarget = \"{config_dir}/vim\"\n", "neovim"); !strings.HasPrefix(got, "[tool]\nname = \"neovim\"\n\n[[link]]") {
+		t.Errorf("SetToolName without [tool]:\n%s", got)
+	}
+	if got := SetToolName("[tool]\ndescription = \"Editor\"\n", "neovim"); got != "[tool]\nname = \"neovim\"\ndescription = \"Editor\"\n" {
+		t.Errorf("SetToolName without name:\n%s", got)
+	}
+}
@@ -0,0 +1,23 @@
+// Package placeholder scans the {name} placeholders of user-written templates
+// such as commit messages and webhook payloads.
+package placeholder
+
+import (
+	"regexp"
+	"slices"
+)
+
+// Pattern matches a placeholder; its first group is the name
+var Pattern = regexp.MustCompile(`\{([a-z_]+)\}`)
+
+// Unknown returns the placeholders in template whose name isn't in known, in
+// the order they appear
+func Unknown(template string, known []string) []string {
+	var unknown []string
+	for _, m := range Pattern.FindAllStringSubmatch(template, -1) {
+		if !slices.Contains(known, m[1]) {
+			unknown = append(unknown, m[0])
+		}
+	}
+	return unknown
+}
@@ -0,0 +1,16 @@
+package placeholder
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnknown(t *testing.T) {
+	got := Unknown("{op}: {summary} {ticket} {Date} {user}", []string{"op", "summary"})
+	if strings.Join(got, ",") != "{ticket},{user}" {
+		t.Errorf("Unknown() = %v, want [{ticket} {user}]", got)
+	}
+	if got := Unknown("no placeholders", nil); got != nil {
+		t.Errorf("Unknown() = %v, want nil", got)
+	}
+}
@@ -0,0 +1,182 @@
+// Package preview renders what a file will become, as a unified diff, so
+// dry runs show content changes instead of only "would create/overwrite".
+package preview
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Context is the number of unchanged lines shown around each change
+const Context = 3
+
+// maxCells bounds the line-diff table (old lines × new lines); larger files
+// are summarized instead of diffed
+const maxCells = 4_000_000
+
+// File previews replacing current with the content of proposed. A missing
+// current file is shown as all additions; directories, symlinks and binary
+// files are summarized in one line. The result is empty when the contents are identical.
+func File(current, proposed string) (string, error) {
+	newData, err := readFile(proposed)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Lstat(current)
+	switch {
+	case os.IsNotExist(err):
+		return Unified("/dev/null", proposed, nil, newData), nil
+	case err != nil:
+		return "", err
+	case info.IsDir():
+		entries, _ := os.ReadDir(current)
+		return fmt.Sprintf("directory %s (%d entries) would be replaced\n", current, len(entries)), nil
+	case info.Mode()&os.ModeSymlink != 0:
+		dest, _ := os.Readlink(current)
+		return fmt.Sprintf("symlink %s → %s would be replaced\n", current, dest), nil
+	case !info.Mode().IsRegular():
+		return fmt.Sprintf("%s is not a regular file and would be replaced\n", current), nil
+	}
+	oldData, err := readFile(current)
+	if err != nil {
+		return "", err
+	}
+	return Unified(current, proposed, oldData, newData), nil
+}
+
+func readFile(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory", path)
+	}
+	return os.ReadFile(path)
+}
+
+// Unified returns a unified diff from oldData to newData labelled with the
+// given names, or "" when they are equal
+func Unified(oldName, newName string, oldData, newData []byte) string {
+	if bytes.Equal(oldData, newData) {
+		return ""
+	}
+	if isBinary(oldData) || isBinary(newData) {
+		return fmt.Sprintf("binary files %s and %s differ\n", oldName, newName)
+	}
+
+	a, b := splitLines(oldData), splitLines(newData)
+	if len(a)*len(b) > maxCells {
+		return fmt.Sprintf("files differ (%d → %d lines; too large to preview)\n", len(a), len(b))
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+	ops := lineOps(a, b)
+	for start := 0; start < len(ops); {
+		// Find the next change and extend the hunk while changes are close
+		first := start
+		for first < len(ops) && ops[first].kind == ' ' {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+		last := first
+		for i := first; i < len(ops); i++ {
+			if ops[i].kind != ' ' {
+				// Gaps up to 2*Context unchanged lines stay in one hunk
+				if i-last > 2*Context+1 {
+					break
+				}
+				last = i
+			}
+		}
+		from := max(first-Context, start)
+		to := min(last+Context+1, len(ops))
+		writeHunk(&out, ops[from:to])
+		start = to
+	}
+	return out.String()
+}
+
+// op is one line of the edit script: ' ' unchanged, '-' removed, '+' added
+type op struct {
+	kind       byte
+	text       string
+	oldN, newN int // 1-based line numbers before this op in each file
+}
+
+// lineOps computes a shortest edit script through the longest common subsequence
+func lineOps(a, b []string) []op {
+	n, m := len(a), len(b)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var ops []op
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && a[i] == b[j]:
+			ops = append(ops, op{' ', a[i], i + 1, j + 1})
+			i++
+			j++
+		case i < n && (j == m || lcs[i+1][j] >= lcs[i][j+1]):
+			// Removals first on ties, so a changed line reads -old then +new
+			ops = append(ops, op{'-', a[i], i + 1, j + 1})
+			i++
+		default:
+			ops = append(ops, op{'+', b[j], i + 1, j + 1})
+			j++
+		}
+	}
+	return ops
+}
+
+func writeHunk(out *strings.Builder, ops []op) {
+	oldCount, newCount := 0, 0
+	for _, o := range ops {
+		if o.kind != '+' {
+			oldCount++
+		}
+		if o.kind != '-' {
+			newCount++
+		}
+	}
+	// An empty side is addressed by the line before it, as diff -u does
+	oldStart, newStart := ops[0].oldN, ops[0].newN
+	if oldCount == 0 {
+		oldStart--
+	}
+	if newCount == 0 {
+		newStart--
+	}
+	fmt.Fprintf(out, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+	for _, o := range ops {
+		fmt.Fprintf(out, "%c%s\n", o.kind, o.text)
+	}
+}
+
+func splitLines(data []byte) []string {
+	if len(data) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+func isBinary(data []byte) bool {
+	return bytes.IndexByte(data[:min(len(data), 8000)], 0) >= 0
+}
@@ -0,0 +1,65 @@
+package preview
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	old := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\n"
+	new := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\nn\n"
+	want := `--- old
++++ new
+@@ -1,5 +1,5 @@
+ a
+-b
++B
+ c
+ d
+ e
+@@ -11,3 +11,4 @@
+ k
+ l
+ m
++n
+`
+	if got := Unified("old", "new", []byte(old), []byte(new)); got != want {
+		t.Errorf("Unified() =\n%s\nwant\n%s", got, want)
+	}
+
+	if got := Unified("old", "new", []byte(old), []byte(old)); got != "" {
+		t.Errorf("Unified() of equal input = %q, want empty", got)
+	}
+	if got := Unified("/dev/null", "new", nil, []byte("x\ny\n")); !strings.Contains(got, "@@ -0,0 +1,2 @@\n+x\n+y\n") {
+		t.Errorf("Unified() from empty =\n%s", got)
+	}
+	if got := Unified("old", "new", []byte("a\x00"), []byte("b")); !strings.HasPrefix(got, "binary files") {
+		t.Errorf("Unified() of binary = %q", got)
+	}
+}
+
+func TestFile(t *testing.T) {
+	dir := t.TempDir()
+	current := filepath.Join(dir, "current")
+	proposed := filepath.Join(dir, "proposed")
+	os.WriteFile(current, []byte("theme = dark\n"), 0644)
+	os.WriteFile(proposed, []byte("theme = light\n"), 0644)
+
+	got, err := File(current, proposed)
+	if err != nil {
+		t.Fatalf("File() error = %v", err)
+	}
+	if !strings.Contains(got, "-theme = dark\n+theme = light\n") {
+		t.Errorf("File() =\n%s", got)
+	}
+
+	os.Mkdir(filepath.Join(dir, "dir"), 0755)
+	if got, _ := File(filepath.Join(dir, "dir"), proposed); !strings.HasPrefix(got, "directory ") {
+		t.Errorf("File() on a directory = %q", got)
+	}
+	if _, err := File(current, filepath.Join(dir, "missing")); err == nil {
+		t.Error("File() should fail when the proposed file is missing")
+	}
+}
@@ -0,0 +1,139 @@
+// Package progress writes machine-readable progress events, one JSON object
+// per line, for wrappers (IDE tasks, GUIs, provisioning tools) that show
+// their own progress instead of parsing merlin's human output. Nothing is
+// written until Open or SetOutput is called.
+package progress
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Event statuses. A phase reports started and then done; its items report
+// started and then one of done, skipped or failed.
+const (
+	StatusStarted = "started"
+	StatusDone    = "done"
+	StatusSkipped = "skipped"
+	StatusFailed  = "failed"
+)
+
+// Event is one line of the stream
+type Event struct {
+	Time    time.Time `json:"time"`
+	Op      string    `json:"op"`              // command, e.g. "link" or "install brew"
+	Phase   string    `json:"phase"`           // part of the command, e.g. "formulae"
+	Item    string    `json:"item,omitempty"`  // tool, package, script or step; empty for the phase itself
+	Status  string    `json:"status"`          // see the Status constants
+	Percent int       `json:"percent"`         // of the phase's items finished, 0-100
+	Total   int       `json:"total,omitempty"` // items in the phase
+	Message string    `json:"message,omitempty"`
+}
+
+var (
+	mu  sync.Mutex
+	enc *json.Encoder
+	op  string
+)
+
+// Open streams events for operation to file descriptor fd (when > 0) or to
+// path (a file or named pipe, opened for appending)
+func Open(fd int, path, operation string) error {
+	var w io.Writer
+	switch {
+	case fd > 0:
+		f := os.NewFile(uintptr(fd), "progress")
+		if f == nil {
+			return fmt.Errorf("progress: invalid file descriptor %d", fd)
+		}
+		w = f
+	case path != "":
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+		if err != nil {
+			return fmt.Errorf("progress: %w", err)
+		}
+		w = f
+	default:
+		return nil
+	}
+	SetOutput(w, operation)
+	return nil
+}
+
+// SetOutput streams events for operation to w; nil stops the stream
+func SetOutput(w io.Writer, operation string) {
+	mu.Lock()
+	defer mu.Unlock()
+	enc, op = nil, operation
+	if w != nil {
+		enc = json.NewEncoder(w)
+	}
+}
+
+// Enabled reports whether events are being written
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return enc != nil
+}
+
+// emit writes e; mu must be held
+func emit(e Event) {
+	if enc == nil {
+		return
+	}
+	e.Time, e.Op = time.Now(), op
+	// A reader that went away must not fail the operation
+	enc.Encode(e)
+}
+
+// Phase tracks the items of one phase. Its methods are safe to call from
+// several goroutines and do nothing while the stream is off.
+type Phase struct {
+	name     string
+	total    int
+	finished int
+}
+
+// Start reports a phase of total items as started
+func Start(name string, total int) *Phase {
+	mu.Lock()
+	defer mu.Unlock()
+	emit(Event{Phase: name, Status: StatusStarted, Total: total})
+	return &Phase{name: name, total: total}
+}
+
+// Begin reports item as started
+func (p *Phase) Begin(item string) {
+	mu.Lock()
+	defer mu.Unlock()
+	emit(Event{Phase: p.name, Item: item, Status: StatusStarted, Percent: p.percent(), Total: p.total})
+}
+
+// Finish reports item as done, skipped or failed (status), with an optional
+// message such as the error
+func (p *Phase) Finish(item, status, message string) {
+	mu.Lock()
+	defer mu.Unlock()
+	p.finished++
+	emit(Event{Phase: p.name, Item: item, Status: status, Percent: p.percent(), Total: p.total, Message: message})
+}
+
+// End reports the phase as done
+func (p *Phase) End() {
+	mu.Lock()
+	defer mu.Unlock()
+	emit(Event{Phase: p.name, Status: StatusDone, Percent: 100, Total: p.total})
+}
+
+// percent is the share of finished items; mu must be held
+func (p *Phase) percent() int {
+	if p.total <= 0 {
+		return 0
+	}
+	return min(p.finished*100/p.total, 100)
+}
@@ -0,0 +1,56 @@
+package progress
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestPhaseEvents(t *testing.T) {
+	// Disabled: nothing is written and nothing panics
+	SetOutput(nil, "")
+	Start("link", 1).Finish("zsh", StatusDone, "")
+
+	var buf bytes.Buffer
+	SetOutput(&buf, "install brew")
+	defer SetOutput(nil, "")
+
+	phase := Start("formulae", 2)
+	phase.Begin("git")
+	phase.Finish("git", StatusDone, "")
+	phase.Finish("jq", StatusFailed, "no bottle")
+	phase.End()
+
+	var events []Event
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var e Event
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("not a JSON line: %q: %v", line, err)
+		}
+		events = append(events, e)
+	}
+
+	want := []struct {
+		item, status string
+		percent      int
+	}{
+		{"", StatusStarted, 0},
+		{"git", StatusStarted, 0},
+		{"git", StatusDone, 50},
+		{"jq", StatusFailed, 100},
+		{"", StatusDone, 100},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d:\n%s", len(events), len(want), buf.String())
+	}
+	for i, w := range want {
+		e := events[i]
+		if e.Op != "install brew" || e.Phase != "formulae" || e.Total != 2 || e.Item != w.item || e.Status != w.status || e.Percent != w.percent {
+			t.Errorf("event %d = %+v, want %+v", i, e, w)
+		}
+	}
+	if events[3].Message != "no bottle" {
+		t.Errorf("failure message = %q", events[3].Message)
+	}
+}
@@ -0,0 +1,65 @@
+// Package protect holds the paths merlin must never modify. The list comes
+// from protected_paths in the root merlin.toml and is checked by the symlink
+// engine and backup restore regardless of conflict strategy or --force.
+package protect
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// ErrProtected is wrapped by every refusal returned from Check
+var ErrProtected = errors.New("protected path")
+
+// paths is set once at startup (see Set) and only read afterwards
+var paths []string
+
+// Set replaces the protected list. Entries may start with ~/, $HOME/ or
+// {home_dir}/ and are cleaned; relative entries are ignored.
+func Set(list []string, homeDir string) {
+	paths = nil
+	for _, p := range list {
+		if expanded := Expand(p, homeDir); filepath.IsAbs(expanded) {
+			paths = append(paths, expanded)
+		}
+	}
+}
+
+// Paths returns the active protected list
+func Paths() []string {
+	return paths
+}
+
+// Expand resolves a leading ~, $HOME or {home_dir} against homeDir and cleans the result
+func Expand(path, homeDir string) string {
+	for _, prefix := range []string{"~", "$HOME", "${HOME}", "{home_dir}"} {
+		if rest, ok := strings.CutPrefix(path, prefix); ok && (rest == "" || strings.HasPrefix(rest, "/")) {
+			path = homeDir + rest
+			break
+		}
+	}
+	return filepath.Clean(path)
+}
+
+// Check refuses changes to path when it is a protected path, lies inside a
+// protected directory, or is a parent of a protected path (replacing the
+// parent would replace the protected file with it).
+func Check(path string) error {
+	clean := filepath.Clean(path)
+	for _, p := range paths {
+		if clean == p {
+			return fmt.Errorf("%w: refusing to modify %s", ErrProtected, clean)
+		}
+		if within(clean, p) || within(p, clean) {
+			return fmt.Errorf("%w: refusing to modify %s (protected_paths entry %s)", ErrProtected, clean, p)
+		}
+	}
+	return nil
+}
+
+// within reports whether path is strictly inside dir
+func within(path, dir string) bool {
+	return strings.HasPrefix(path, strings.TrimSuffix(dir, string(filepath.Separator))+string(filepath.Separator))
+}
@@ -0,0 +1,55 @@
+package protect
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	Set([]string{"~/.ssh/authorized_keys", "$HOME/.gnupg", "/etc/hosts", "relative/ignored"}, "/Users/me")
+	defer Set(nil, "")
+
+	if got := len(Paths()); got != 3 {
+		t.Fatalf("expected 3 protected paths, got %v", Paths())
+	}
+
+	tests := []struct {
+		path      string
+		protected bool
+	}{
+		{"/Users/me/.ssh/authorized_keys", true},
+		{"/Users/me/.ssh/", true}, // parent of a protected file
+		{"/Users/me", true},
+		{"/Users/me/.gnupg/gpg.conf", true}, // inside a protected directory
+		{"/etc/hosts", true},
+		{"/Users/me/.ssh/config", false},
+		{"/Users/me/.sshrc", false},
+		{"/Users/me/.gnupg-backup", false},
+		{"/etc/hosts.allow", false},
+		{"relative/ignored", false},
+	}
+	for _, tt := range tests {
+		err := Check(tt.path)
+		if (err != nil) != tt.protected {
+			t.Errorf("Check(%q) = %v, want protected=%v", tt.path, err, tt.protected)
+		}
+		if err != nil && !errors.Is(err, ErrProtected) {
+			t.Errorf("Check(%q) error does not wrap ErrProtected: %v", tt.path, err)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	tests := map[string]string{
+		"~":              "/home/u",
+		"~/.ssh":         "/home/u/.ssh",
+		"${HOME}/a/../b": "/home/u/b",
+		"~user/x":        "~user/x",
+		"/abs/path/":     "/abs/path",
+	}
+	for in, want := range tests {
+		if got := Expand(in, "/home/u"); got != want {
+			t.Errorf("Expand(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+// Package report builds the roll-up shown at the end of multi-phase
+// operations such as 'merlin install tool': what each phase did, what failed,
+// how long it took and what is left to do by hand. Reports can be kept as
+// markdown under ~/.merlin/reports.
+package report
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ildx/merlin/internal/metrics"
+)
+
+// Phase is one step of an operation, e.g. installing packages
+type Phase struct {
+	Name      string
+	Succeeded int
+	Failed    []string // items that failed, e.g. package names
+	Skipped   string   // why the phase didn't run; empty when it ran
+	Duration  time.Duration
+}
+
+// Status renders the phase outcome: "ok", "failed" or "skipped"
+func (p Phase) Status() string {
+	switch {
+	case p.Skipped != "":
+		return "skipped"
+	case len(p.Failed) > 0:
+		return "failed"
+	default:
+		return "ok"
+	}
+}
+
+// Report is the roll-up of an operation
+type Report struct {
+	Op      string // e.g. "install tool zsh"
+	Started time.Time
+	Ended   time.Time
+	DryRun  bool
+	Phases  []Phase
+	TODOs   []string // follow-ups, e.g. manual steps still pending
+}
+
+// New starts the report of op
+func New(op string, dryRun bool) *Report {
+	return &Report{Op: op, Started: time.Now(), DryRun: dryRun}
+}
+
+// Track starts timing phase name; call the returned func with the phase's
+// outcome when it is done
+func (r *Report) Track(name string) func(succeeded int, failed []string) {
+	began := time.Now()
+	return func(succeeded int, failed []string) {
+		r.Phases = append(r.Phases, Phase{Name: name, Succeeded: succeeded, Failed: failed, Duration: time.Since(began)})
+	}
+}
+
+// Skip records that phase name didn't run, and why
+func (r *Report) Skip(name, reason string) {
+	r.Phases = append(r.Phases, Phase{Name: name, Skipped: reason})
+}
+
+// Todo adds a follow-up
+func (r *Report) Todo(format string, args ...any) {
+	r.TODOs = append(r.TODOs, fmt.Sprintf(format, args...))
+}
+
+// Finish stamps the end of the operation
+func (r *Report) Finish() {
+	r.Ended = time.Now()
+}
+
+// Failed returns how many items failed across the phases
+func (r *Report) Failed() int {
+	n := 0
+	for _, p := range r.Phases {
+		n += len(p.Failed)
+	}
+	return n
+}
+
+// Duration is the wall time of the operation
+func (r *Report) Duration() time.Duration {
+	if r.Ended.IsZero() {
+		return time.Since(r.Started)
+	}
+	return r.Ended.Sub(r.Started)
+}
+
+// title renders e.g. "install tool zsh (dry run): 1 failure in 12.3s"
+func (r *Report) title() string {
+	s := r.Op
+	if r.DryRun {
+		s += " (dry run)"
+	}
+	if failed := r.Failed(); failed > 0 {
+		return fmt.Sprintf("%s: %d failure(s) in %s", s, failed, metrics.Format(r.Duration()))
+	}
+	return fmt.Sprintf("%s: done in %s", s, metrics.Format(r.Duration()))
+}
+
+// Text renders the report for the terminal
+func (r *Report) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "📋 %s\n", r.title())
+	for _, p := range r.Phases {
+		switch p.Status() {
+		case "skipped":
+			fmt.Fprintf(&b, "   ⊘ %-10s skipped: %s\n", p.Name, p.Skipped)
+		case "failed":
+			fmt.Fprintf(&b, "   ✗ %-10s %d ok, %d failed (%s) in %s\n", p.Name, p.Succeeded, len(p.Failed), strings.Join(p.Failed, ", "), metrics.Format(p.Duration))
+		default:
+			fmt.Fprintf(&b, "   ✓ %-10s %d ok in %s\n", p.Name, p.Succeeded, metrics.Format(p.Duration))
+		}
+	}
+	if len(r.TODOs) > 0 {
+		b.WriteString("   Follow-up:\n")
+		for _, todo := range r.TODOs {
+			fmt.Fprintf(&b, "     • %s\n", todo)
+		}
+	}
+	return b.String()
+}
+
+// Markdown renders the report as a markdown document
+func (r *Report) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# merlin %s\n\n", r.Op)
+	fmt.Fprintf(&b, "- Started: %s\n", r.Started.Format(time.RFC3339))
+	fmt.Fprintf(&b, "- Duration: %s\n", metrics.Format(r.Duration()))
+	if host, err := os.Hostname(); err == nil {
+		fmt.Fprintf(&b, "- Host: %s\n", host)
+	}
+	if r.DryRun {
+		b.WriteString("- Dry run: nothing was changed\n")
+	}
+
+	b.WriteString("\n## Phases\n\n")
+	b.WriteString("| Phase | Status | Succeeded | Failed | Duration |\n")
+	b.WriteString("| --- | --- | --- | --- | --- |\n")
+	for _, p := range r.Phases {
+		status, duration := p.Status(), metrics.Format(p.Duration)
+		if p.Skipped != "" {
+			status, duration = "skipped: "+p.Skipped, "-"
+		}
+		fmt.Fprintf(&b, "| %s | %s | %d | %d | %s |\n", p.Name, status, p.Succeeded, len(p.Failed), duration)
+	}
+
+	if r.Failed() > 0 {
+		b.WriteString("\n## Failures\n\n")
+		for _, p := range r.Phases {
+			for _, item := range p.Failed {
+				fmt.Fprintf(&b, "- %s: %s\n", p.Name, item)
+			}
+		}
+	}
+
+	if len(r.TODOs) > 0 {
+		b.WriteString("\n## Follow-up\n\n")
+		for _, todo := range r.TODOs {
+			fmt.Fprintf(&b, "- [ ] %s\n", todo)
+		}
+	}
+	return b.String()
+}
+
+// Dir returns where reports are written
+func Dir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("get home directory: %w", err)
+	}
+	return filepath.Join(home, ".merlin", "reports"), nil
+}
+
+// Write saves the report as <Dir>/<timestamp>.md and returns its path
+func (r *Report) Write() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("create reports directory: %w", err)
+	}
+	path := filepath.Join(dir, r.Started.Format("20060102-150405")+".md")
+	if err := os.WriteFile(path, []byte(r.Markdown()), 0644); err != nil {
+		return "", fmt.Errorf("write report: %w", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,65 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReport(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	r := New("install tool karabiner", false)
+	r.Track("packages")(2, nil)
+	r.Track("links")(3, []string{"/home/u/.config/karabiner"})
+	r.Skip("scripts", "--no-scripts")
+	r.Todo("Grant input monitoring (then: merlin todo done %s)", "karabiner/input")
+	r.Finish()
+
+	if r.Failed() != 1 {
+		t.Errorf("Failed() = %d, want 1", r.Failed())
+	}
+	if got := r.Phases[1].Status(); got != "failed" {
+		t.Errorf("links status = %s, want failed", got)
+	}
+
+	text := r.Text()
+	for _, want := range []string{
+		"install tool karabiner: 1 failure(s)",
+		"✓ packages   2 ok",
+		"✗ links      3 ok, 1 failed (/home/u/.config/karabiner)",
+		"⊘ scripts    skipped: --no-scripts",
+		"• Grant input monitoring (then: merlin todo done karabiner/input)",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Text() missing %q:\n%s", want, text)
+		}
+	}
+
+	md := r.Markdown()
+	for _, want := range []string{
+		"# merlin install tool karabiner",
+		"| scripts | skipped: --no-scripts | 0 | 0 | - |",
+		"- links: /home/u/.config/karabiner",
+		"- [ ] Grant input monitoring",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Markdown() missing %q:\n%s", want, md)
+		}
+	}
+
+	r.Started = time.Date(2026, 3, 1, 9, 30, 0, 0, time.Local)
+	path, err := r.Write()
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	dir, _ := Dir()
+	if path != filepath.Join(dir, "20260301-093000.md") {
+		t.Errorf("Write() path = %s", path)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != r.Markdown() {
+		t.Errorf("written report differs: %v", err)
+	}
+}
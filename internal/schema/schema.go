@@ -0,0 +1,129 @@
+// Package schema generates JSON Schema documents for merlin's TOML files from
+// the Go models, so editors can validate and complete them. Properties come
+// from toml struct tags; a `schema` tag adds constraints the types can't
+// express ("required", or "enum=a|b"), and types with custom decoding
+// describe themselves through Provider.
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/ildx/merlin/internal/models"
+)
+
+// Draft is the JSON Schema dialect of generated documents
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Provider is implemented by model types whose TOML form doesn't follow
+// their struct fields (e.g. models.ScriptItem, a string or a table)
+type Provider interface {
+	JSONSchema() map[string]any
+}
+
+// kinds maps each schema name to its file and model
+var kinds = map[string]struct {
+	title string
+	model any
+}{
+	"root": {"merlin.toml (repository root)", models.RootMerlinConfig{}},
+	"tool": {"merlin.toml (config/<tool>)", models.ToolMerlinConfig{}},
+	"brew": {"brew.toml", models.BrewConfig{}},
+	"mas":  {"mas.toml", models.MASConfig{}},
+}
+
+// Kinds returns the available schema names, sorted
+func Kinds() []string {
+	names := make([]string, 0, len(kinds))
+	for name := range kinds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Generate returns the JSON Schema for one of Kinds
+func Generate(kind string) (map[string]any, error) {
+	k, ok := kinds[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown schema %q (must be: %s)", kind, strings.Join(Kinds(), ", "))
+	}
+	doc := typeSchema(reflect.TypeOf(k.model))
+	doc["$schema"] = Draft
+	doc["title"] = k.title
+	return doc, nil
+}
+
+var providerType = reflect.TypeOf((*Provider)(nil)).Elem()
+
+// typeSchema describes how a value of type t is written in TOML
+func typeSchema(t reflect.Type) map[string]any {
+	if t.Implements(providerType) {
+		return reflect.Zero(t).Interface().(Provider).JSONSchema()
+	}
+	if reflect.PointerTo(t).Implements(providerType) {
+		return reflect.New(t).Interface().(Provider).JSONSchema()
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return typeSchema(t.Elem())
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Struct:
+		return structSchema(t)
+	}
+	return map[string]any{}
+}
+
+// structSchema describes a TOML table from the struct's toml-tagged fields.
+// Unknown keys are rejected: merlin ignores them, so they are usually typos.
+func structSchema(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	var required []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("toml"), ",")
+		if name == "" || name == "-" || !field.IsExported() {
+			continue
+		}
+		prop := typeSchema(field.Type)
+		for _, opt := range strings.Split(field.Tag.Get("schema"), ",") {
+			switch {
+			case opt == "required":
+				required = append(required, name)
+			case strings.HasPrefix(opt, "enum="):
+				// On a list the values constrain its items
+				target := prop
+				if items, ok := prop["items"].(map[string]any); ok {
+					target = items
+				}
+				target["enum"] = strings.Split(strings.TrimPrefix(opt, "enum="), "|")
+			}
+		}
+		properties[name] = prop
+	}
+
+	s := map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
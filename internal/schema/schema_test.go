@@ -0,0 +1,90 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// prop walks nested properties, descending into array items
+func prop(t *testing.T, s map[string]any, path ...string) map[string]any {
+	t.Helper()
+	for _, name := range path {
+		if items, ok := s["items"].(map[string]any); ok {
+			s = items
+		}
+		props, _ := s["properties"].(map[string]any)
+		next, ok := props[name].(map[string]any)
+		if !ok {
+			t.Fatalf("property %v not found", path)
+		}
+		s = next
+	}
+	return s
+}
+
+func TestGenerate(t *testing.T) {
+	for _, kind := range Kinds() {
+		doc, err := Generate(kind)
+		if err != nil {
+			t.Fatalf("Generate(%q) error = %v", kind, err)
+		}
+		if doc["$schema"] != Draft || doc["type"] != "object" {
+			t.Errorf("Generate(%q) is not an object schema: %v", kind, doc)
+		}
+		if _, err := json.Marshal(doc); err != nil {
+			t.Errorf("Generate(%q) doesn't encode: %v", kind, err)
+		}
+	}
+
+	if _, err := Generate("nope"); err == nil {
+		t.Error("Generate() should reject unknown kinds")
+	}
+}
+
+func TestGenerateTool(t *testing.T) {
+	doc, _ := Generate("tool")
+
+	if got := doc["required"]; !reflect.DeepEqual(got, []string{"tool"}) {
+		t.Errorf("required = %v, want [tool]", got)
+	}
+	if got := prop(t, doc, "link")["items"].(map[string]any)["required"]; !reflect.DeepEqual(got, []string{"target"}) {
+		t.Errorf("link required = %v, want [target]", got)
+	}
+	if got := prop(t, doc, "tool", "enabled")["type"]; got != "boolean" {
+		t.Errorf("tool.enabled type = %v, want boolean (pointer unwrapped)", got)
+	}
+	if got := prop(t, doc, "link", "rename")["additionalProperties"]; !reflect.DeepEqual(got, map[string]any{"type": "string"}) {
+		t.Errorf("link.rename values = %v, want strings", got)
+	}
+	if got := prop(t, doc, "scripts", "on_error")["enum"]; !reflect.DeepEqual(got, []string{"stop", "continue"}) {
+		t.Errorf("scripts.on_error enum = %v", got)
+	}
+	if got := prop(t, doc, "tool", "permissions")["items"].(map[string]any)["enum"]; len(got.([]string)) != 4 {
+		t.Errorf("tool.permissions items enum = %v, want the four permissions", got)
+	}
+
+	// Script entries come from ScriptItem.JSONSchema, not its (untagged) fields
+	items := prop(t, doc, "scripts", "scripts")["items"].(map[string]any)
+	if _, ok := items["oneOf"]; !ok {
+		t.Errorf("scripts.scripts items = %v, want string-or-table oneOf", items)
+	}
+}
+
+func TestGenerateRootAndPackages(t *testing.T) {
+	root, _ := Generate("root")
+	if got := prop(t, root, "settings", "conflict_strategy")["enum"]; !reflect.DeepEqual(got, []string{"backup", "skip", "overwrite", "interactive"}) {
+		t.Errorf("conflict_strategy enum = %v", got)
+	}
+	if got := prop(t, root, "profile", "tools")["type"]; got != "array" {
+		t.Errorf("profile.tools type = %v, want array", got)
+	}
+
+	mas, _ := Generate("mas")
+	if got := prop(t, mas, "app", "id")["type"]; got != "integer" {
+		t.Errorf("app.id type = %v, want integer", got)
+	}
+	brew, _ := Generate("brew")
+	prop(t, brew, "cask", "name")
+	prop(t, brew, "brew", "dependencies")
+}
@@ -0,0 +1,114 @@
+package scripts
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ildx/merlin/internal/models"
+)
+
+// ScriptVariableNames are the {placeholders} available in script env values
+var ScriptVariableNames = []string{"home_dir", "config_dir", "tool_root", "tool"}
+
+var (
+	placeholderPattern = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+	envKeyPattern      = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// scriptVariables maps placeholder names to values taken from the default environment
+func scriptVariables(base map[string]string) map[string]string {
+	vars := map[string]string{
+		"home_dir":   base["MERLIN_HOME"],
+		"config_dir": base["MERLIN_CONFIG_DIR"],
+		"tool_root":  base["MERLIN_TOOL_ROOT"],
+		"tool":       base["MERLIN_TOOL"],
+	}
+	for k, v := range vars {
+		if v == "" {
+			delete(vars, k)
+		}
+	}
+	return vars
+}
+
+// expandValue replaces {var} placeholders and a leading ~ in value. Placeholders
+// without a value are returned as undefined.
+func expandValue(value string, vars map[string]string) (string, []string) {
+	var undefined []string
+	expanded := placeholderPattern.ReplaceAllStringFunc(value, func(m string) string {
+		name := m[1 : len(m)-1]
+		if v, ok := vars[name]; ok {
+			return v
+		}
+		undefined = append(undefined, name)
+		return m
+	})
+
+	if home, ok := vars["home_dir"]; ok {
+		if strings.HasPrefix(expanded, "~/") {
+			expanded = filepath.Join(home, expanded[2:])
+		} else if expanded == "~" {
+			expanded = home
+		}
+	}
+	return expanded, undefined
+}
+
+// ResolveScriptEnv expands the [scripts.env] and per-script env layers against the
+// variables in base (see GetDefaultEnvironment). Later layers override earlier ones.
+func ResolveScriptEnv(base map[string]string, layers ...map[string]string) (map[string]string, error) {
+	vars := scriptVariables(base)
+	resolved := make(map[string]string)
+	for _, layer := range layers {
+		for _, key := range sortedKeys(layer) {
+			value, undefined := expandValue(layer[key], vars)
+			if len(undefined) > 0 {
+				return nil, fmt.Errorf("env %s references undefined variable {%s}", key, undefined[0])
+			}
+			resolved[key] = value
+		}
+	}
+	return resolved, nil
+}
+
+// ValidateScriptEnv reports invalid env keys and references to unknown variables
+// in a tool's [scripts.env] and per-script env tables.
+func ValidateScriptEnv(config *models.ToolMerlinConfig) []error {
+	known := make(map[string]bool, len(ScriptVariableNames))
+	for _, name := range ScriptVariableNames {
+		known[name] = true
+	}
+
+	var errs []error
+	check := func(scope string, env map[string]string) {
+		for _, key := range sortedKeys(env) {
+			if !envKeyPattern.MatchString(key) {
+				errs = append(errs, fmt.Errorf("%s: invalid environment variable name %q", scope, key))
+			}
+			for _, m := range placeholderPattern.FindAllStringSubmatch(env[key], -1) {
+				if !known[m[1]] {
+					errs = append(errs, fmt.Errorf("%s: env %s references undefined variable {%s} (available: %s)",
+						scope, key, m[1], strings.Join(ScriptVariableNames, ", ")))
+				}
+			}
+		}
+	}
+
+	check("[scripts.env]", config.Scripts.Env)
+	for _, item := range config.Scripts.Scripts {
+		check(fmt.Sprintf("script %s", item.File), item.Env)
+	}
+	return errs
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,56 @@
+package scripts
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ildx/merlin/internal/models"
+)
+
+func TestResolveScriptEnv(t *testing.T) {
+	base := GetDefaultEnvironment("/repo/config/cursor", "cursor", "/Users/me", "/Users/me/.config")
+	shared := map[string]string{
+		"USER_DIR": "{home_dir}/Library/Cursor",
+		"EXT_LIST": "{tool_root}/extensions.txt",
+	}
+	item := map[string]string{
+		"EXT_LIST": "~/extensions-{tool}.txt",
+	}
+
+	env, err := ResolveScriptEnv(base, shared, item)
+	if err != nil {
+		t.Fatalf("ResolveScriptEnv() error = %v", err)
+	}
+	if env["USER_DIR"] != "/Users/me/Library/Cursor" {
+		t.Errorf("USER_DIR = %q", env["USER_DIR"])
+	}
+	if env["EXT_LIST"] != "/Users/me/extensions-cursor.txt" {
+		t.Errorf("EXT_LIST = %q, per-script env should override shared env", env["EXT_LIST"])
+	}
+
+	if _, err := ResolveScriptEnv(base, map[string]string{"X": "{repo_dir}/x"}); err == nil {
+		t.Error("expected error for undefined variable")
+	}
+}
+
+func TestValidateScriptEnv(t *testing.T) {
+	config := &models.ToolMerlinConfig{
+		Scripts: models.ScriptsSection{
+			Env: map[string]string{"GOOD": "{config_dir}/x", "bad-key": "v"},
+			Scripts: []models.ScriptItem{
+				{File: "a.sh", Env: map[string]string{"PATHS": "{home_dir}:{nope}"}},
+			},
+		},
+	}
+
+	errs := ValidateScriptEnv(config)
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %d: %v", len(errs), errs)
+	}
+	if !strings.Contains(errs[0].Error(), "bad-key") {
+		t.Errorf("expected invalid key error first, got %v", errs[0])
+	}
+	if !strings.Contains(errs[1].Error(), "{nope}") || !strings.Contains(errs[1].Error(), "a.sh") {
+		t.Errorf("expected undefined variable error for a.sh, got %v", errs[1])
+	}
+}
@@ -0,0 +1,107 @@
+package scripts
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// logTimeFormat matches backup IDs so log names sort chronologically
+const logTimeFormat = "20060102_150405"
+
+// LogDir returns the base directory for persisted script output
+func LogDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("get home directory: %w", err)
+	}
+	return filepath.Join(home, ".merlin", "logs", "scripts"), nil
+}
+
+// newLogFile creates ~/.merlin/logs/scripts/<tool>/<script>-<timestamp>.log
+func newLogFile(tool, script string, started time.Time) (*os.File, error) {
+	base, err := LogDir()
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(base, tool)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("create log directory: %w", err)
+	}
+	name := fmt.Sprintf("%s-%s.log", script, started.Format(logTimeFormat))
+	return os.Create(filepath.Join(dir, name))
+}
+
+// ScriptLog is a persisted output file for one script run
+type ScriptLog struct {
+	Script string
+	Path   string
+	RunAt  time.Time
+}
+
+// ListLogs returns a tool's script logs, newest first. If script is non-empty only
+// that script's logs are returned.
+func ListLogs(tool, script string) ([]ScriptLog, error) {
+	base, err := LogDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(filepath.Join(base, tool))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read log directory: %w", err)
+	}
+
+	var logs []ScriptLog
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".log")
+		if entry.IsDir() || !ok {
+			continue
+		}
+		i := strings.LastIndex(name, "-")
+		if i <= 0 {
+			continue
+		}
+		runAt, err := time.ParseInLocation(logTimeFormat, name[i+1:], time.Local)
+		if err != nil {
+			continue
+		}
+		if script != "" && name[:i] != script {
+			continue
+		}
+		logs = append(logs, ScriptLog{
+			Script: name[:i],
+			Path:   filepath.Join(base, tool, entry.Name()),
+			RunAt:  runAt,
+		})
+	}
+
+	sort.SliceStable(logs, func(i, j int) bool {
+		return logs[i].RunAt.After(logs[j].RunAt)
+	})
+	return logs, nil
+}
+
+// LatestLogs returns the most recent log for each script of a tool (or only for
+// script when given), newest first.
+func LatestLogs(tool, script string) ([]ScriptLog, error) {
+	logs, err := ListLogs(tool, script)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var latest []ScriptLog
+	for _, l := range logs {
+		if seen[l.Script] {
+			continue
+		}
+		seen[l.Script] = true
+		latest = append(latest, l)
+	}
+	return latest, nil
+}
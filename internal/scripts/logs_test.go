@@ -0,0 +1,53 @@
+package scripts
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunScriptPersistsLog(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", originalHome)
+
+	toolRoot := filepath.Join(tmpDir, "config", "demo")
+	scriptDir := filepath.Join(toolRoot, "scripts")
+	os.MkdirAll(scriptDir, 0755)
+	script := filepath.Join(scriptDir, "fail.sh")
+	os.WriteFile(script, []byte("#!/bin/sh\necho to-stdout\necho to-stderr >&2\nexit 3\n"), 0755)
+
+	var out bytes.Buffer
+	runner := NewScriptRunner(toolRoot, nil, false, 0, &out)
+	result := runner.RunScript(script)
+	if result.Success || result.ExitCode != 3 {
+		t.Fatalf("expected exit code 3, got success=%v code=%d", result.Success, result.ExitCode)
+	}
+	if result.LogPath == "" {
+		t.Fatal("expected LogPath to be set")
+	}
+
+	data, err := os.ReadFile(result.LogPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"to-stdout", "to-stderr", "exit code 3"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("log missing %q:\n%s", want, data)
+		}
+	}
+
+	logs, err := LatestLogs("demo", "fail.sh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 1 || logs[0].Path != result.LogPath {
+		t.Errorf("LatestLogs() = %+v, want %s", logs, result.LogPath)
+	}
+	if !strings.Contains(FormatScriptResult(result, 0), result.LogPath) {
+		t.Error("failed result should reference its log file")
+	}
+}
@@ -0,0 +1,130 @@
+package scripts
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"maps"
+	"strings"
+
+	"github.com/ildx/merlin/internal/models"
+)
+
+// paramEnvPrefix names the environment variables params are exported as
+const paramEnvPrefix = "MERLIN_PARAM_"
+
+// ParamPrompter asks for the value of a script's param. An empty answer keeps
+// the param's default.
+type ParamPrompter func(script string, param models.ScriptParam) (string, error)
+
+// ParamEnvName returns the environment variable a param is exported as,
+// e.g. "region" becomes MERLIN_PARAM_REGION
+func ParamEnvName(name string) string {
+	return paramEnvPrefix + strings.ToUpper(name)
+}
+
+// ParseParamFlags parses --param name=value flags
+func ParseParamFlags(flags []string) (map[string]string, error) {
+	params := make(map[string]string, len(flags))
+	for _, flag := range flags {
+		name, value, ok := strings.Cut(flag, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid --param %q (expected name=value)", flag)
+		}
+		params[name] = value
+	}
+	return params, nil
+}
+
+// ResolveParams returns the value of each of item's params: the given value,
+// else the prompted one when prompt is set, else the default. A param left
+// without a value is an error.
+func ResolveParams(item models.ScriptItem, given map[string]string, prompt ParamPrompter) (map[string]string, error) {
+	values := make(map[string]string, len(item.Params))
+	for _, p := range item.Params {
+		value, ok := given[p.Name]
+		if !ok && prompt != nil {
+			answer, err := prompt(item.File, p)
+			if err != nil {
+				return nil, err
+			}
+			value = answer
+		}
+		if value == "" {
+			value = p.Default
+		}
+		if value == "" {
+			return nil, fmt.Errorf("script %s: param %s has no value (pass --param %s=<value>)", item.File, p.Name, p.Name)
+		}
+		values[p.Name] = value
+	}
+	return values, nil
+}
+
+// ExpandArgs expands {var} and {param} placeholders in item's args
+func ExpandArgs(base map[string]string, item models.ScriptItem, params map[string]string) ([]string, error) {
+	vars := scriptVariables(base)
+	maps.Copy(vars, params)
+	args := make([]string, 0, len(item.Args))
+	for _, arg := range item.Args {
+		expanded, undefined := expandValue(arg, vars)
+		if len(undefined) > 0 {
+			return nil, fmt.Errorf("script %s: argument %q references undefined variable {%s}", item.File, arg, undefined[0])
+		}
+		args = append(args, expanded)
+	}
+	return args, nil
+}
+
+// PromptParams returns a ParamPrompter reading answers from input, one line
+// per param
+func PromptParams(input io.Reader, output io.Writer) ParamPrompter {
+	reader := bufio.NewReader(input)
+	return func(script string, p models.ScriptParam) (string, error) {
+		label := p.Name
+		if p.Description != "" {
+			label = fmt.Sprintf("%s (%s)", p.Name, p.Description)
+		}
+		if p.Default != "" {
+			fmt.Fprintf(output, "%s: %s [%s]: ", script, label, p.Default)
+		} else {
+			fmt.Fprintf(output, "%s: %s: ", script, label)
+		}
+		line, err := reader.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return "", fmt.Errorf("failed to read input")
+		}
+		return strings.TrimSpace(line), nil
+	}
+}
+
+// ValidateScriptParams reports duplicate or invalid param names and args
+// referencing undefined variables
+func ValidateScriptParams(config *models.ToolMerlinConfig) []error {
+	var errs []error
+	for _, item := range config.Scripts.Scripts {
+		known := make(map[string]bool)
+		for _, name := range ScriptVariableNames {
+			known[name] = true
+		}
+		seen := make(map[string]bool)
+		for _, p := range item.Params {
+			if !envKeyPattern.MatchString(p.Name) {
+				errs = append(errs, fmt.Errorf("script %s: invalid param name %q", item.File, p.Name))
+			}
+			if seen[p.Name] {
+				errs = append(errs, fmt.Errorf("script %s: duplicate param %q", item.File, p.Name))
+			}
+			seen[p.Name] = true
+			known[p.Name] = true
+		}
+		for _, arg := range item.Args {
+			for _, m := range placeholderPattern.FindAllStringSubmatch(arg, -1) {
+				if !known[m[1]] {
+					errs = append(errs, fmt.Errorf("script %s: argument %q references undefined variable {%s}", item.File, arg, m[1]))
+				}
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,118 @@
+package scripts
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ildx/merlin/internal/models"
+)
+
+func TestResolveParams(t *testing.T) {
+	item := models.ScriptItem{
+		File:   "configure.sh",
+		Params: []models.ScriptParam{{Name: "region", Default: "eu"}, {Name: "account"}},
+	}
+
+	values, err := ResolveParams(item, map[string]string{"account": "dev"}, nil)
+	if err != nil {
+		t.Fatalf("ResolveParams() error = %v", err)
+	}
+	if values["region"] != "eu" || values["account"] != "dev" {
+		t.Errorf("values = %v, want the default region and the given account", values)
+	}
+
+	if _, err := ResolveParams(item, nil, nil); err == nil || !strings.Contains(err.Error(), "--param account=") {
+		t.Errorf("expected missing value error for account, got %v", err)
+	}
+
+	var out bytes.Buffer
+	prompt := PromptParams(strings.NewReader("\nprod\n"), &out)
+	values, err = ResolveParams(item, nil, prompt)
+	if err != nil {
+		t.Fatalf("ResolveParams() with prompt error = %v", err)
+	}
+	if values["region"] != "eu" || values["account"] != "prod" {
+		t.Errorf("prompted values = %v, an empty answer should keep the default", values)
+	}
+	if !strings.Contains(out.String(), "region [eu]") {
+		t.Errorf("prompt should show the default, got %q", out.String())
+	}
+}
+
+func TestParseParamFlags(t *testing.T) {
+	params, err := ParseParamFlags([]string{"region=us", "tag=a=b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params["region"] != "us" || params["tag"] != "a=b" {
+		t.Errorf("params = %v", params)
+	}
+	if _, err := ParseParamFlags([]string{"region"}); err == nil {
+		t.Error("expected error for a flag without '='")
+	}
+}
+
+func TestValidateScriptParams(t *testing.T) {
+	config := &models.ToolMerlinConfig{
+		Scripts: models.ScriptsSection{
+			Scripts: []models.ScriptItem{{
+				File:   "a.sh",
+				Args:   []string{"--region", "{region}", "--dir", "{home_dir}", "{zone}"},
+				Params: []models.ScriptParam{{Name: "region"}, {Name: "region"}, {Name: "bad-name"}},
+			}},
+		},
+	}
+
+	errs := ValidateScriptParams(config)
+	if len(errs) != 3 {
+		t.Fatalf("expected 3 errors, got %d: %v", len(errs), errs)
+	}
+	for i, want := range []string{"duplicate", "bad-name", "{zone}"} {
+		if !strings.Contains(errs[i].Error(), want) {
+			t.Errorf("error %d = %v, want it to mention %s", i, errs[i], want)
+		}
+	}
+}
+
+func TestRunScriptItemParams(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	toolRoot := filepath.Join(tmpDir, "config", "demo")
+	scriptDir := filepath.Join(toolRoot, "scripts")
+	os.MkdirAll(scriptDir, 0755)
+	os.WriteFile(filepath.Join(scriptDir, "echo.sh"), []byte("#!/bin/sh\necho \"args=$* region=$MERLIN_PARAM_REGION\"\n"), 0755)
+
+	item := models.ScriptItem{
+		File:   "echo.sh",
+		Args:   []string{"--region", "{region}", "--tool", "{tool}"},
+		Params: []models.ScriptParam{{Name: "region", Default: "eu"}},
+	}
+	env := GetDefaultEnvironment(toolRoot, "demo", tmpDir, filepath.Join(tmpDir, ".config"))
+	runner := NewScriptRunner(toolRoot, env, false, 0, &bytes.Buffer{})
+	runner.Params = map[string]string{"region": "us"}
+
+	result := runner.RunScriptItem(scriptDir, item, nil)
+	if !result.Success {
+		t.Fatalf("script failed: %v", result.Error)
+	}
+	if result.Output != "args=--region us --tool demo region=us" {
+		t.Errorf("output = %q", result.Output)
+	}
+	if result.Params["region"] != "us" {
+		t.Errorf("result params = %v", result.Params)
+	}
+
+	data, err := os.ReadFile(result.LogPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"# args: --region us --tool demo", "# param region=us"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("log missing %q:\n%s", want, data)
+		}
+	}
+}
@@ -8,10 +8,14 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/ildx/merlin/internal/cli"
 	"github.com/ildx/merlin/internal/logger"
+	"github.com/ildx/merlin/internal/metrics"
 	"github.com/ildx/merlin/internal/models"
+	"github.com/ildx/merlin/internal/progress"
 )
 
 // ScriptResult represents the outcome of a script execution
@@ -22,19 +26,30 @@ type ScriptResult struct {
 	Success  bool
 	Output   string
 	Error    error
+	LogPath  string            // Persisted stdout/stderr for this run (empty for dry runs)
+	Params   map[string]string // Param values the script ran with (also recorded in its log)
+	Skipped  bool              // Not executed; see SkipReason
+
+	SkipReason string // e.g. "dry run" or "earlier script failed"
 }
 
+// dryRunEnv is set to "1" for scripts that run in their own dry-run mode
+const dryRunEnv = "MERLIN_DRY_RUN"
+
 // ScriptRunner handles script execution
 type ScriptRunner struct {
 	ToolRoot    string
 	Environment map[string]string
 	DryRun      bool
-	Verbose     bool
+	Verbosity   cli.Verbosity     // Stream() echoes script output as it runs
+	KeepGoing   bool              // Run remaining scripts after a failure regardless of on_error
+	Params      map[string]string // --param values, by param name
+	Prompt      ParamPrompter     // Asks for params without a --param value; nil uses defaults
 	Output      io.Writer
 }
 
 // NewScriptRunner creates a new script runner
-func NewScriptRunner(toolRoot string, env map[string]string, dryRun, verbose bool, output io.Writer) *ScriptRunner {
+func NewScriptRunner(toolRoot string, env map[string]string, dryRun bool, verbosity cli.Verbosity, output io.Writer) *ScriptRunner {
 	if output == nil {
 		output = os.Stdout
 	}
@@ -43,7 +58,7 @@ func NewScriptRunner(toolRoot string, env map[string]string, dryRun, verbose boo
 		ToolRoot:    toolRoot,
 		Environment: env,
 		DryRun:      dryRun,
-		Verbose:     verbose,
+		Verbosity:   verbosity,
 		Output:      output,
 	}
 }
@@ -55,10 +70,7 @@ func (r *ScriptRunner) RunScripts(config *models.ToolMerlinConfig) ([]*ScriptRes
 	}
 
 	// Determine script directory
-	scriptDir := filepath.Join(r.ToolRoot, config.Scripts.Directory)
-	if config.Scripts.Directory == "" {
-		scriptDir = filepath.Join(r.ToolRoot, "scripts")
-	}
+	scriptDir := ScriptDirectory(r.ToolRoot, config)
 
 	// Check if script directory exists
 	if _, err := os.Stat(scriptDir); os.IsNotExist(err) {
@@ -66,28 +78,97 @@ func (r *ScriptRunner) RunScripts(config *models.ToolMerlinConfig) ([]*ScriptRes
 	}
 
 	var results []*ScriptResult
+	keepGoing := r.KeepGoing || config.Scripts.ContinueOnError()
+	var failed string
+
+	// Progress items are "<tool>/<script>", the tool being the last element of ToolRoot
+	phase := progress.Start("scripts", len(config.Scripts.Scripts))
+	defer phase.End()
+	item := func(script string) string { return filepath.Base(r.ToolRoot) + "/" + script }
 
 	for _, scriptItem := range config.Scripts.Scripts {
-		scriptPath := filepath.Join(scriptDir, scriptItem.File)
+		// With on_error = "stop", report the rest as skipped rather than dropping them
+		if failed != "" {
+			results = append(results, &ScriptResult{
+				Script:     scriptItem.File,
+				Skipped:    true,
+				SkipReason: fmt.Sprintf("%s failed", failed),
+			})
+			phase.Finish(item(scriptItem.File), progress.StatusSkipped, fmt.Sprintf("%s failed", failed))
+			continue
+		}
 
-		result := r.RunScript(scriptPath)
+		phase.Begin(item(scriptItem.File))
+		result := r.RunScriptItem(scriptDir, scriptItem, config.Scripts.Env)
 		results = append(results, result)
+		switch {
+		case result.Skipped:
+			phase.Finish(item(result.Script), progress.StatusSkipped, result.SkipReason)
+		case result.Success:
+			phase.Finish(item(result.Script), progress.StatusDone, "")
+		case result.Error != nil:
+			phase.Finish(item(result.Script), progress.StatusFailed, result.Error.Error())
+		default:
+			phase.Finish(item(result.Script), progress.StatusFailed, fmt.Sprintf("exit code %d", result.ExitCode))
+		}
 
-		// Stop on error unless we're being lenient
-		if !result.Success && result.Error != nil {
-			break
+		if !result.Success && result.Error != nil && !keepGoing {
+			failed = result.Script
 		}
 	}
 
 	return results, nil
 }
 
+// RunScriptItem executes a configured script with the tool's shared env
+// ([scripts.env]) and the item's own env layered over the default environment.
+// Its params are resolved (see ResolveParams) and exported as MERLIN_PARAM_*,
+// and its args are passed with placeholders expanded.
+func (r *ScriptRunner) RunScriptItem(scriptDir string, item models.ScriptItem, sharedEnv map[string]string) *ScriptResult {
+	scriptPath := filepath.Join(scriptDir, item.File)
+	env, err := ResolveScriptEnv(r.Environment, sharedEnv, item.Env)
+	if err != nil {
+		return &ScriptResult{Script: item.File, Error: err}
+	}
+
+	// Scripts that understand MERLIN_DRY_RUN run for real and preview themselves;
+	// the others are skipped without asking for their params
+	if r.DryRun && !item.DryRunSupported {
+		return r.runScript(scriptPath, env, nil, nil)
+	}
+	if r.DryRun {
+		env[dryRunEnv] = "1"
+	}
+
+	params, err := ResolveParams(item, r.Params, r.Prompt)
+	if err != nil {
+		return &ScriptResult{Script: item.File, Error: err}
+	}
+	args, err := ExpandArgs(r.Environment, item, params)
+	if err != nil {
+		return &ScriptResult{Script: item.File, Error: err}
+	}
+	for name, value := range params {
+		env[ParamEnvName(name)] = value
+	}
+	return r.runScript(scriptPath, env, args, params)
+}
+
 // RunScript executes a single script
 func (r *ScriptRunner) RunScript(scriptPath string) *ScriptResult {
+	return r.runScript(scriptPath, nil, nil, nil)
+}
+
+// runScript executes scriptPath with args, extraEnv applied after the runner's
+// environment. params are only recorded.
+func (r *ScriptRunner) runScript(scriptPath string, extraEnv map[string]string, args []string, params map[string]string) *ScriptResult {
 	result := &ScriptResult{
 		Script:  filepath.Base(scriptPath),
 		Success: false,
 	}
+	if len(params) > 0 {
+		result.Params = params
+	}
 
 	// Check if script exists
 	info, err := os.Stat(scriptPath)
@@ -102,19 +183,27 @@ func (r *ScriptRunner) RunScript(scriptPath string) *ScriptResult {
 		return result
 	}
 
-	// Dry run mode
-	if r.DryRun {
-		fmt.Fprintf(r.Output, "  [DRY RUN] Would execute: %s\n", result.Script)
+	// Dry run mode (unless the script previews itself via MERLIN_DRY_RUN)
+	if r.DryRun && extraEnv[dryRunEnv] != "1" {
+		fmt.Fprintf(r.Output, "  [DRY RUN] Skipping %s (set dry_run_supported = true to preview it)\n", result.Script)
 		logger.Info("Script dry-run", "script", result.Script, "path", scriptPath)
 		result.Success = true
+		result.Skipped = true
+		result.SkipReason = "dry run"
 		return result
 	}
 
 	// Execute script
 	logger.Info("Starting script execution", "script", result.Script, "path", scriptPath)
+	if r.Verbosity.Stream() {
+		fmt.Fprintf(r.Output, "  ▶ %s\n", result.Script)
+	}
+	if r.Verbosity.Debug() {
+		fmt.Fprintf(r.Output, "    %s\n", scriptPath)
+	}
 	startTime := time.Now()
 
-	cmd := exec.Command(scriptPath)
+	cmd := exec.Command(scriptPath, args...)
 	cmd.Dir = filepath.Dir(scriptPath)
 
 	// Set up environment
@@ -122,6 +211,9 @@ func (r *ScriptRunner) RunScript(scriptPath string) *ScriptResult {
 	for key, value := range r.Environment {
 		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", key, value))
 	}
+	for key, value := range extraEnv {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", key, value))
+	}
 
 	// Capture stdout and stderr
 	stdout, err := cmd.StdoutPipe()
@@ -142,35 +234,51 @@ func (r *ScriptRunner) RunScript(scriptPath string) *ScriptResult {
 		return result
 	}
 
-	// Stream output
-	var outputLines []string
-	done := make(chan bool)
-
-	go func() {
-		scanner := bufio.NewScanner(stdout)
-		for scanner.Scan() {
-			line := scanner.Text()
-			outputLines = append(outputLines, line)
-			if r.Verbose {
-				fmt.Fprintf(r.Output, "    %s\n", line)
-			}
+	// Persist output so failures can be inspected later with 'merlin scripts logs'
+	logFile, logErr := newLogFile(filepath.Base(r.ToolRoot), result.Script, startTime)
+	if logErr != nil {
+		logger.Warn("Script output will not be saved", "script", result.Script, "error", logErr)
+	} else {
+		defer logFile.Close()
+		result.LogPath = logFile.Name()
+		fmt.Fprintf(logFile, "# %s\n# started %s\n", scriptPath, startTime.Format(time.RFC3339))
+		if len(args) > 0 {
+			fmt.Fprintf(logFile, "# args: %s\n", strings.Join(args, " "))
+		}
+		for _, name := range sortedKeys(params) {
+			fmt.Fprintf(logFile, "# param %s=%s\n", name, params[name])
 		}
-	}()
+		fmt.Fprintln(logFile)
+	}
 
-	go func() {
-		scanner := bufio.NewScanner(stderr)
+	// Stream output
+	var (
+		outputLines []string
+		mu          sync.Mutex
+		wg          sync.WaitGroup
+	)
+	collect := func(rd io.Reader) {
+		defer wg.Done()
+		scanner := bufio.NewScanner(rd)
 		for scanner.Scan() {
 			line := scanner.Text()
+			mu.Lock()
 			outputLines = append(outputLines, line)
-			if r.Verbose {
+			if logFile != nil {
+				fmt.Fprintln(logFile, line)
+			}
+			if r.Verbosity.Stream() {
 				fmt.Fprintf(r.Output, "    %s\n", line)
 			}
+			mu.Unlock()
 		}
-		done <- true
-	}()
+	}
+	wg.Add(2)
+	go collect(stdout)
+	go collect(stderr)
 
-	// Wait for command to complete
-	<-done
+	// Wait for output to drain, then for the command to complete
+	wg.Wait()
 	err = cmd.Wait()
 
 	result.Duration = time.Since(startTime)
@@ -180,11 +288,18 @@ func (r *ScriptRunner) RunScript(scriptPath string) *ScriptResult {
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			result.ExitCode = exitErr.ExitCode()
 		}
+	}
+	if logFile != nil {
+		fmt.Fprintf(logFile, "\n# exit code %d after %.2fs\n", result.ExitCode, result.Duration.Seconds())
+	}
+
+	if err != nil {
 		result.Error = fmt.Errorf("script failed with exit code %d", result.ExitCode)
 		logger.Error("Script execution failed",
 			"script", result.Script,
 			"exitCode", result.ExitCode,
 			"duration", result.Duration.Seconds(),
+			"log", result.LogPath,
 			"error", err)
 		return result
 	}
@@ -193,7 +308,8 @@ func (r *ScriptRunner) RunScript(scriptPath string) *ScriptResult {
 	result.Success = true
 	logger.Info("Script execution completed",
 		"script", result.Script,
-		"duration", result.Duration.Seconds())
+		"duration", result.Duration.Seconds(),
+		"log", result.LogPath)
 	return result
 }
 
@@ -214,20 +330,37 @@ func GetDefaultEnvironment(toolRoot, toolName string, homeDir, configDir string)
 	}
 }
 
-// FormatScriptResult formats a script result for display
-func FormatScriptResult(result *ScriptResult, verbose bool) string {
+// ScriptTimings collects the durations of the scripts that ran
+func ScriptTimings(results []*ScriptResult) *metrics.Timings {
+	timings := &metrics.Timings{}
+	for _, result := range results {
+		if !result.Skipped {
+			timings.Add(result.Script, 1, result.Duration)
+		}
+	}
+	return timings
+}
+
+// FormatScriptResult formats a script result for display; durations are
+// included at debug verbosity
+func FormatScriptResult(result *ScriptResult, verbosity cli.Verbosity) string {
 	var sb strings.Builder
 
-	if result.Success {
+	if result.Skipped {
+		sb.WriteString(fmt.Sprintf("  ⊘ %s (skipped: %s)", result.Script, result.SkipReason))
+	} else if result.Success {
 		sb.WriteString(fmt.Sprintf("  ✓ %s", result.Script))
-		if verbose {
-			sb.WriteString(fmt.Sprintf(" (%.2fs)", result.Duration.Seconds()))
+		if verbosity.Debug() {
+			sb.WriteString(fmt.Sprintf(" (%s)", metrics.Format(result.Duration)))
 		}
 	} else {
 		sb.WriteString(fmt.Sprintf("  ✗ %s", result.Script))
 		if result.Error != nil {
 			sb.WriteString(fmt.Sprintf(" - %s", result.Error.Error()))
 		}
+		if result.LogPath != "" {
+			sb.WriteString(fmt.Sprintf("\n    log: %s", result.LogPath))
+		}
 	}
 
 	return sb.String()
@@ -239,13 +372,17 @@ func ValidateScripts(toolRoot string, config *models.ToolMerlinConfig) []error {
 		return nil
 	}
 
-	var errors []error
+	errors := ValidateScriptEnv(config)
+	errors = append(errors, ValidateScriptParams(config)...)
 
-	scriptDir := filepath.Join(toolRoot, config.Scripts.Directory)
-	if config.Scripts.Directory == "" {
-		scriptDir = filepath.Join(toolRoot, "scripts")
+	switch config.Scripts.OnError {
+	case "", models.OnErrorStop, models.OnErrorContinue:
+	default:
+		errors = append(errors, fmt.Errorf("invalid on_error %q (must be: stop or continue)", config.Scripts.OnError))
 	}
 
+	scriptDir := ScriptDirectory(toolRoot, config)
+
 	// Check if script directory exists
 	if _, err := os.Stat(scriptDir); os.IsNotExist(err) {
 		errors = append(errors, fmt.Errorf("script directory does not exist: %s", scriptDir))
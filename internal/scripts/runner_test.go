@@ -0,0 +1,107 @@
+package scripts
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ildx/merlin/internal/models"
+)
+
+func TestRunScriptsDryRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", originalHome)
+
+	toolRoot := filepath.Join(tmpDir, "config", "demo")
+	scriptDir := filepath.Join(toolRoot, "scripts")
+	os.MkdirAll(scriptDir, 0755)
+	os.WriteFile(filepath.Join(scriptDir, "preview.sh"), []byte("#!/bin/sh\necho \"dry=$MERLIN_DRY_RUN\"\n"), 0755)
+	os.WriteFile(filepath.Join(scriptDir, "install.sh"), []byte("#!/bin/sh\ntouch ran\n"), 0755)
+
+	config := &models.ToolMerlinConfig{}
+	config.Scripts.Scripts = []models.ScriptItem{
+		{File: "preview.sh", DryRunSupported: true},
+		{File: "install.sh"},
+	}
+
+	var out bytes.Buffer
+	runner := NewScriptRunner(toolRoot, nil, true, 0, &out)
+	results, err := runner.RunScripts(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+
+	if results[0].Skipped || !results[0].Success || results[0].Output != "dry=1" {
+		t.Errorf("preview.sh: expected to run with MERLIN_DRY_RUN=1, got %+v", results[0])
+	}
+	if !results[1].Skipped || !results[1].Success {
+		t.Errorf("install.sh: expected to be skipped, got %+v", results[1])
+	}
+	if _, err := os.Stat(filepath.Join(scriptDir, "ran")); !os.IsNotExist(err) {
+		t.Error("install.sh should not have executed in dry run")
+	}
+}
+
+func TestRunScriptsOnError(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", originalHome)
+
+	toolRoot := filepath.Join(tmpDir, "config", "demo")
+	scriptDir := filepath.Join(toolRoot, "scripts")
+	os.MkdirAll(scriptDir, 0755)
+	os.WriteFile(filepath.Join(scriptDir, "fail.sh"), []byte("#!/bin/sh\nexit 1\n"), 0755)
+	os.WriteFile(filepath.Join(scriptDir, "ok.sh"), []byte("#!/bin/sh\nexit 0\n"), 0755)
+
+	newConfig := func(onError string) *models.ToolMerlinConfig {
+		config := &models.ToolMerlinConfig{}
+		config.Scripts.OnError = onError
+		config.Scripts.Scripts = []models.ScriptItem{{File: "fail.sh"}, {File: "ok.sh"}}
+		return config
+	}
+
+	tests := []struct {
+		name        string
+		onError     string
+		keepGoing   bool
+		wantSkipped bool
+	}{
+		{"default stops", "", false, true},
+		{"stop", models.OnErrorStop, false, true},
+		{"continue", models.OnErrorContinue, false, false},
+		{"keep going overrides stop", models.OnErrorStop, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := NewScriptRunner(toolRoot, nil, false, 0, &bytes.Buffer{})
+			runner.KeepGoing = tt.keepGoing
+			results, err := runner.RunScripts(newConfig(tt.onError))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(results) != 2 {
+				t.Fatalf("expected 2 results, got %d", len(results))
+			}
+			if results[0].Success {
+				t.Error("fail.sh should fail")
+			}
+			second := results[1]
+			if second.Skipped != tt.wantSkipped {
+				t.Errorf("ok.sh skipped = %v, want %v", second.Skipped, tt.wantSkipped)
+			}
+			if tt.wantSkipped && second.SkipReason != "fail.sh failed" {
+				t.Errorf("unexpected skip reason %q", second.SkipReason)
+			}
+			if !tt.wantSkipped && !second.Success {
+				t.Errorf("ok.sh should have run and succeeded: %+v", second)
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package scripts
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ildx/merlin/internal/models"
+)
+
+// TrustState describes whether a script's current content has been approved
+type TrustState int
+
+const (
+	TrustNew      TrustState = iota // never approved
+	TrustChanged                    // approved before, but content changed since
+	TrustApproved                   // content matches the approved hash
+)
+
+func (s TrustState) String() string {
+	switch s {
+	case TrustNew:
+		return "new"
+	case TrustChanged:
+		return "changed"
+	case TrustApproved:
+		return "trusted"
+	default:
+		return "unknown"
+	}
+}
+
+// TrustEntry records the approved content hash of one script
+type TrustEntry struct {
+	Hash       string    `json:"hash"`
+	ApprovedAt time.Time `json:"approved_at"`
+}
+
+// TrustStore holds approved script hashes keyed by "<tool>/<script>"
+type TrustStore struct {
+	Scripts map[string]TrustEntry `json:"scripts"`
+	path    string
+}
+
+// UntrustedScript is a script that needs approval before it may run
+type UntrustedScript struct {
+	Script string
+	Path   string
+	Hash   string
+	State  TrustState
+}
+
+// TrustStorePath returns the location of the script trust database
+func TrustStorePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("get home directory: %w", err)
+	}
+	return filepath.Join(home, ".merlin", "trusted_scripts.json"), nil
+}
+
+// LoadTrustStore reads the trust database; a missing file yields an empty store
+func LoadTrustStore() (*TrustStore, error) {
+	path, err := TrustStorePath()
+	if err != nil {
+		return nil, err
+	}
+
+	store := &TrustStore{Scripts: make(map[string]TrustEntry), path: path}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return store, nil
+		}
+		return nil, fmt.Errorf("read trust store: %w", err)
+	}
+	if err := json.Unmarshal(data, store); err != nil {
+		return nil, fmt.Errorf("parse trust store: %w", err)
+	}
+	if store.Scripts == nil {
+		store.Scripts = make(map[string]TrustEntry)
+	}
+	return store, nil
+}
+
+// Save writes the trust database to disk
+func (s *TrustStore) Save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("create trust store directory: %w", err)
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0600)
+}
+
+// State reports whether the script content identified by hash is approved
+func (s *TrustStore) State(tool, script, hash string) TrustState {
+	entry, ok := s.Scripts[trustKey(tool, script)]
+	switch {
+	case !ok:
+		return TrustNew
+	case entry.Hash != hash:
+		return TrustChanged
+	default:
+		return TrustApproved
+	}
+}
+
+// Approve records hash as the trusted content of a script
+func (s *TrustStore) Approve(tool, script, hash string) {
+	s.Scripts[trustKey(tool, script)] = TrustEntry{Hash: hash, ApprovedAt: time.Now()}
+}
+
+// Untrusted returns the scripts in items whose current content is not approved.
+// Missing scripts are ignored here; ValidateScripts reports them.
+func (s *TrustStore) Untrusted(tool, scriptDir string, items []models.ScriptItem) ([]UntrustedScript, error) {
+	var untrusted []UntrustedScript
+	for _, item := range items {
+		path := filepath.Join(scriptDir, item.File)
+		hash, err := HashScript(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		if state := s.State(tool, item.File, hash); state != TrustApproved {
+			untrusted = append(untrusted, UntrustedScript{Script: item.File, Path: path, Hash: hash, State: state})
+		}
+	}
+	return untrusted, nil
+}
+
+// HashScript returns the SHA256 of a script's content
+func HashScript(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// ScriptDirectory returns the absolute script directory configured for a tool
+func ScriptDirectory(toolRoot string, config *models.ToolMerlinConfig) string {
+	if config.Scripts.Directory == "" {
+		return filepath.Join(toolRoot, "scripts")
+	}
+	return filepath.Join(toolRoot, config.Scripts.Directory)
+}
+
+// ConfirmUntrusted shows the body of every new or changed script and asks for
+// approval. Approved hashes are saved; declining any script returns an error so
+// the caller runs nothing.
+func ConfirmUntrusted(tool, scriptDir string, items []models.ScriptItem, input io.Reader, output io.Writer) error {
+	store, err := LoadTrustStore()
+	if err != nil {
+		return err
+	}
+	untrusted, err := store.Untrusted(tool, scriptDir, items)
+	if err != nil {
+		return err
+	}
+	if len(untrusted) == 0 {
+		return nil
+	}
+
+	scanner := bufio.NewScanner(input)
+	for _, u := range untrusted {
+		body, err := os.ReadFile(u.Path)
+		if err != nil {
+			return fmt.Errorf("read script %s: %w", u.Script, err)
+		}
+
+		fmt.Fprintf(output, "\n🔐 %s/%s is %s and has not been approved:\n", tool, u.Script, u.State)
+		fmt.Fprintln(output, strings.Repeat("─", 80))
+		for _, line := range strings.Split(strings.TrimRight(string(body), "\n"), "\n") {
+			fmt.Fprintf(output, "  │ %s\n", line)
+		}
+		fmt.Fprintln(output, strings.Repeat("─", 80))
+		fmt.Fprintf(output, "Trust and run this script? [y/N]: ")
+
+		if !scanner.Scan() {
+			return fmt.Errorf("failed to read input")
+		}
+		response := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if response != "y" && response != "yes" {
+			return fmt.Errorf("script %s/%s not trusted (approve with: merlin scripts trust %s)", tool, u.Script, tool)
+		}
+		store.Approve(tool, u.Script, u.Hash)
+	}
+
+	return store.Save()
+}
+
+// TrustAll approves the current content of every script in items without prompting
+func TrustAll(tool, scriptDir string, items []models.ScriptItem) ([]UntrustedScript, error) {
+	store, err := LoadTrustStore()
+	if err != nil {
+		return nil, err
+	}
+	untrusted, err := store.Untrusted(tool, scriptDir, items)
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range untrusted {
+		store.Approve(tool, u.Script, u.Hash)
+	}
+	if len(untrusted) == 0 {
+		return nil, nil
+	}
+	return untrusted, store.Save()
+}
+
+func trustKey(tool, script string) string {
+	return tool + "/" + script
+}
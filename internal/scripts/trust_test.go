@@ -0,0 +1,62 @@
+package scripts
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ildx/merlin/internal/models"
+)
+
+func TestConfirmUntrusted(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", originalHome)
+
+	scriptDir := filepath.Join(tmpDir, "scripts")
+	os.MkdirAll(scriptDir, 0755)
+	script := filepath.Join(scriptDir, "setup.sh")
+	os.WriteFile(script, []byte("#!/bin/sh\necho hello\n"), 0755)
+	items := []models.ScriptItem{{File: "setup.sh"}}
+
+	// Declining a new script refuses to run it
+	var out bytes.Buffer
+	if err := ConfirmUntrusted("demo", scriptDir, items, strings.NewReader("n\n"), &out); err == nil {
+		t.Fatal("expected error when script is declined")
+	}
+	if !strings.Contains(out.String(), "echo hello") {
+		t.Errorf("script body not shown:\n%s", out.String())
+	}
+
+	// Approving records the hash; a second run does not prompt
+	if err := ConfirmUntrusted("demo", scriptDir, items, strings.NewReader("y\n"), &out); err != nil {
+		t.Fatalf("ConfirmUntrusted() error = %v", err)
+	}
+	if err := ConfirmUntrusted("demo", scriptDir, items, strings.NewReader(""), &out); err != nil {
+		t.Fatalf("trusted script should not prompt: %v", err)
+	}
+
+	// Changing the script requires approval again
+	os.WriteFile(script, []byte("#!/bin/sh\nrm -rf /tmp/x\n"), 0755)
+	store, err := LoadTrustStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	untrusted, err := store.Untrusted("demo", scriptDir, items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(untrusted) != 1 || untrusted[0].State != TrustChanged {
+		t.Fatalf("expected one changed script, got %+v", untrusted)
+	}
+
+	if _, err := TrustAll("demo", scriptDir, items); err != nil {
+		t.Fatalf("TrustAll() error = %v", err)
+	}
+	if err := ConfirmUntrusted("demo", scriptDir, items, strings.NewReader(""), &out); err != nil {
+		t.Fatalf("script should be trusted after TrustAll: %v", err)
+	}
+}
@@ -0,0 +1,268 @@
+// Package secrets looks for credentials in files that are about to be
+// linked from (and usually committed to) the dotfiles repository.
+package secrets
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// AllowlistName is the file at the repository root listing false positives:
+// repository-relative path patterns (path.Match syntax) whose files are not
+// scanned, or finding fingerprints. Blank lines and # comments are ignored.
+const AllowlistName = ".merlin-secrets-allow"
+
+// maxFileSize skips files too large to be hand-written configuration
+const maxFileSize = 1 << 20
+
+// Rule names reported in Finding.Rule
+const (
+	RulePrivateKey  = "private key"
+	RuleToken       = "access token"
+	RuleAssignment  = "credential assignment"
+	RuleHighEntropy = "high-entropy string"
+)
+
+var (
+	privateKeyPattern = regexp.MustCompile(`-----BEGIN ([A-Z0-9]+ )*PRIVATE KEY( BLOCK)?-----`)
+
+	// Well-known token formats
+	tokenPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36}\b`),
+		regexp.MustCompile(`\bgithub_pat_[A-Za-z0-9_]{22,}\b`),
+		regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20,}\b`),
+		regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}\b`),
+		regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b`),
+		regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{20,}\b`),
+	}
+
+	// password = ..., imap_pass = ..., GITHUB_TOKEN=..., "api_key": "..."
+	assignmentPattern = regexp.MustCompile(`(?i)\b[a-z0-9_.-]*(?:pass(?:word|wd)?|secret|token|api[_-]?key)["']?\s*[:=]\s*["']?([^\s"'#,;]+)`)
+
+	// Candidates for the entropy check
+	wordPattern = regexp.MustCompile(`[A-Za-z0-9+/=_-]{32,}`)
+)
+
+// Finding is a line that looks like it contains a credential
+type Finding struct {
+	Path        string // repository-relative, slash-separated
+	Line        int
+	Rule        string // one of the Rule* constants
+	Fingerprint string // identifies the matched value; add it to AllowlistName to silence the finding
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s:%d: %s [%s]", f.Path, f.Line, f.Rule, f.Fingerprint)
+}
+
+// Allowlist holds the entries of AllowlistName
+type Allowlist struct {
+	patterns     []string
+	fingerprints map[string]bool
+}
+
+// LoadAllowlist reads AllowlistName from repoRoot. A missing file is an
+// empty allowlist.
+func LoadAllowlist(repoRoot string) (*Allowlist, error) {
+	allow := &Allowlist{fingerprints: make(map[string]bool)}
+	data, err := os.ReadFile(filepath.Join(repoRoot, AllowlistName))
+	if os.IsNotExist(err) {
+		return allow, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case isFingerprint(line):
+			allow.fingerprints[line] = true
+		default:
+			allow.patterns = append(allow.patterns, strings.TrimSuffix(line, "/"))
+		}
+	}
+	return allow, nil
+}
+
+// allowsPath reports whether rel, or a directory containing it, matches a pattern
+func (a *Allowlist) allowsPath(rel string) bool {
+	for _, pattern := range a.patterns {
+		for p := rel; p != "." && p != "/"; p = path.Dir(p) {
+			if ok, _ := path.Match(pattern, p); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Scan checks every regular file under sources (files or directories inside
+// repoRoot) and returns the findings not silenced by allow, sorted by path
+// and line. Binary and very large files are skipped, as are .git directories.
+func Scan(repoRoot string, sources []string, allow *Allowlist) ([]Finding, error) {
+	if allow == nil {
+		allow = &Allowlist{fingerprints: make(map[string]bool)}
+	}
+	seen := make(map[string]bool)
+	var findings []Finding
+
+	for _, source := range sources {
+		err := filepath.WalkDir(source, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil // missing sources are reported elsewhere
+				}
+				return err
+			}
+			if d.IsDir() && d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			if !d.Type().IsRegular() || seen[p] {
+				return nil
+			}
+			seen[p] = true
+
+			rel, err := filepath.Rel(repoRoot, p)
+			if err != nil {
+				rel = p
+			}
+			rel = filepath.ToSlash(rel)
+			if allow.allowsPath(rel) {
+				return nil
+			}
+			if info, err := d.Info(); err != nil || info.Size() > maxFileSize {
+				return nil
+			}
+			data, err := os.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			for _, f := range ScanContent(rel, data) {
+				if !allow.fingerprints[f.Fingerprint] {
+					findings = append(findings, f)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(findings, func(i, j int) bool {
+		if findings[i].Path != findings[j].Path {
+			return findings[i].Path < findings[j].Path
+		}
+		return findings[i].Line < findings[j].Line
+	})
+	return findings, nil
+}
+
+// ScanContent checks data line by line, reporting at most one finding per
+// line. Binary content yields nothing.
+func ScanContent(rel string, data []byte) []Finding {
+	if bytes.IndexByte(data[:min(len(data), 8000)], 0) >= 0 {
+		return nil
+	}
+	var findings []Finding
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), maxFileSize)
+	for n := 1; scanner.Scan(); n++ {
+		if rule, value := checkLine(scanner.Text()); rule != "" {
+			findings = append(findings, Finding{Path: rel, Line: n, Rule: rule, Fingerprint: fingerprint(value)})
+		}
+	}
+	return findings
+}
+
+// checkLine returns the first rule line trips and the value that tripped it
+func checkLine(line string) (string, string) {
+	if m := privateKeyPattern.FindString(line); m != "" {
+		return RulePrivateKey, m
+	}
+	for _, pattern := range tokenPatterns {
+		if m := pattern.FindString(line); m != "" {
+			return RuleToken, m
+		}
+	}
+	for _, m := range assignmentPattern.FindAllStringSubmatch(line, -1) {
+		if !isPlaceholder(m[1]) {
+			return RuleAssignment, m[1]
+		}
+	}
+	for _, word := range wordPattern.FindAllString(line, -1) {
+		if looksRandom(word) {
+			return RuleHighEntropy, word
+		}
+	}
+	return "", ""
+}
+
+// isPlaceholder reports whether an assigned value is a reference or dummy
+// rather than a credential: variables, command substitutions, template
+// syntax, password-manager references and booleans
+func isPlaceholder(value string) bool {
+	lower := strings.ToLower(value)
+	switch lower {
+	case "true", "false", "null", "nil", "none", "yes", "no", "changeme", "xxx", "redacted":
+		return true
+	}
+	return len(value) < 4 ||
+		strings.HasPrefix(value, "$") || strings.HasPrefix(value, "<") ||
+		strings.HasPrefix(value, "{{") || strings.HasPrefix(value, "%") ||
+		strings.HasPrefix(value, "`") || strings.HasPrefix(value, "(") ||
+		strings.Contains(lower, "://") || strings.Trim(value, "*x.") == ""
+}
+
+// looksRandom reports whether word mixes upper case, lower case and digits
+// with Shannon entropy close to that of a random string of its length, as
+// generated tokens do. Hex digests have no upper and lower case mix.
+func looksRandom(word string) bool {
+	var upper, lower, digit bool
+	counts := make(map[rune]int)
+	for _, r := range word {
+		counts[r]++
+		switch {
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= '0' && r <= '9':
+			digit = true
+		}
+	}
+	if !upper || !lower || !digit {
+		return false
+	}
+	entropy := 0.0
+	for _, c := range counts {
+		p := float64(c) / float64(len(word))
+		entropy -= p * math.Log2(p)
+	}
+	return entropy >= 0.8*math.Log2(float64(min(len(word), 62)))
+}
+
+func fingerprint(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:6])
+}
+
+func isFingerprint(s string) bool {
+	if len(s) != 12 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
@@ -0,0 +1,393 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/ildx/merlin/internal/backup"
+	"github.com/ildx/merlin/internal/diff"
+	"github.com/ildx/merlin/internal/installer"
+	"github.com/ildx/merlin/internal/logger"
+	"github.com/ildx/merlin/internal/machine"
+	"github.com/ildx/merlin/internal/models"
+	"github.com/ildx/merlin/internal/parser"
+	"github.com/ildx/merlin/internal/state"
+	"github.com/ildx/merlin/internal/symlink"
+	"github.com/ildx/merlin/internal/system"
+)
+
+// statusResponse is the answer of GET /v1/status
+type statusResponse struct {
+	Version string       `json:"version"`
+	Repo    string       `json:"repo"`
+	Host    hostInfo     `json:"host"`
+	Drift   *state.Drift `json:"drift"` // summary of the last diff; null before the first
+}
+
+// hostInfo is the machine package conditions are evaluated against
+type hostInfo struct {
+	OS       string `json:"os"`
+	Arch     string `json:"arch"`
+	Hostname string `json:"hostname"`
+	Profile  string `json:"profile,omitempty"`
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	drift, err := state.LoadDrift()
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		logger.Debug("drift summary not loaded", "error", err)
+	}
+	host := machine.CurrentHost(s.Repo)
+	writeJSON(w, http.StatusOK, statusResponse{
+		Version: s.Version,
+		Repo:    s.Repo.Root,
+		Host:    hostInfo{OS: host.OS, Arch: host.Arch, Hostname: host.Hostname, Profile: host.Profile},
+		Drift:   drift,
+	})
+}
+
+// handleDiff computes the drift like merlin diff; ?offline=true (or offline
+// mode) uses the cached package state instead of running brew and mas. It
+// rewrites the package cache and drift summary, so it runs exclusively.
+func (s *Server) handleDiff(w http.ResponseWriter, r *http.Request) {
+	var snap *state.SystemSnapshot
+	if r.URL.Query().Get("offline") == "true" || system.IsOffline() {
+		snap, _ = state.CollectOfflineSnapshot(s.Repo.Root)
+	} else {
+		snap = state.CollectSnapshot(s.Repo.Root)
+		if err := state.SavePackageCache(snap); err != nil {
+			logger.Debug("package cache not saved", "error", err)
+		}
+	}
+	result, err := diff.Compute(r.Context(), s.Repo, snap)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("compute diff: %w", err))
+		return
+	}
+	if err := state.SaveDrift(result.Drift(s.Repo.Root)); err != nil {
+		logger.Debug("drift summary not saved", "error", err)
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// linkRequest is the body of POST /v1/link
+type linkRequest struct {
+	Tools    []string `json:"tools"`    // empty links every enabled tool
+	Strategy string   `json:"strategy"` // skip (default), backup or overwrite
+	DryRun   bool     `json:"dry_run"`
+}
+
+// linkResult is one link of a POST /v1/link answer
+type linkResult struct {
+	Tool     string `json:"tool"`
+	Source   string `json:"source"`
+	Target   string `json:"target"`
+	Status   string `json:"status"` // success, already_linked, skipped, conflict or error
+	Message  string `json:"message,omitempty"`
+	BackupID string `json:"backup_id,omitempty"`
+}
+
+func (s *Server) handleLink(w http.ResponseWriter, r *http.Request) {
+	req := linkRequest{Strategy: "skip"}
+	if err := decode(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	strategy, err := symlink.ParseStrategy(req.Strategy)
+	if err == nil && strategy == symlink.StrategyInteractive {
+		err = errors.New("the interactive strategy needs a terminal")
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	tools, err := s.discoverTools()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if len(req.Tools) > 0 {
+		for _, name := range req.Tools {
+			if !slices.ContainsFunc(tools, func(t *symlink.ToolConfig) bool { return t.Name == name }) {
+				writeError(w, http.StatusBadRequest, fmt.Errorf("unknown or disabled tool %q", name))
+				return
+			}
+		}
+		tools = slices.DeleteFunc(tools, func(t *symlink.ToolConfig) bool { return !slices.Contains(req.Tools, t.Name) })
+	}
+
+	backup.StartOperation()
+	registry, err := state.LoadLinkRegistry()
+	if err != nil {
+		logger.Warn("link registry not loaded", "error", err)
+	}
+	linkStates := state.LoadLinkStateCache()
+	results := []linkResult{}
+	for _, tool := range tools {
+		linked, err := symlink.LinkToolWithStrategy(tool, strategy, req.DryRun)
+		if err != nil {
+			logger.Warn("linking tool", "tool", tool.Name, "error", err)
+		}
+		for _, l := range linked {
+			if l.Status == symlink.LinkStatusSuccess || l.Status == symlink.LinkStatusAlreadyLinked {
+				registry.Record(tool.Name, l.Source, l.Target)
+			}
+			results = append(results, linkResult{
+				Tool:     tool.Name,
+				Source:   l.Source,
+				Target:   l.Target,
+				Status:   l.Status.String(),
+				Message:  l.Message,
+				BackupID: l.BackupID,
+			})
+		}
+		if !req.DryRun {
+			total, linkedCount, conflicts := symlink.CountLinks(tool)
+			linkStates.Set(tool.ToolRoot, state.ToolLinkState{Links: total, Linked: linkedCount, Conflicts: conflicts, CheckedAt: time.Now()})
+		}
+	}
+	if !req.DryRun {
+		if err := registry.Save(); err != nil {
+			logger.Warn("link registry not saved", "error", err)
+		}
+		if err := linkStates.Save(); err != nil {
+			logger.Debug("link state not cached", "error", err)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"dry_run": req.DryRun, "results": results})
+}
+
+// discoverTools returns the enabled tools of the repository
+func (s *Server) discoverTools() ([]*symlink.ToolConfig, error) {
+	rootConfig, err := parser.ParseRootMerlinTOML(s.Repo.GetRootMerlinConfig())
+	if err != nil {
+		return nil, fmt.Errorf("parse root config: %w", err)
+	}
+	vars, err := symlink.GetVariablesFromRoot(rootConfig)
+	if err != nil {
+		return nil, fmt.Errorf("get variables: %w", err)
+	}
+	tools, err := symlink.DiscoverTools(s.Repo, vars)
+	if err != nil {
+		return nil, fmt.Errorf("discover tools: %w", err)
+	}
+	return tools, nil
+}
+
+// installRequest is the body of POST /v1/install
+type installRequest struct {
+	Kind     string   `json:"kind"`     // brew or mas
+	Packages []string `json:"packages"` // names (or App Store ids); empty installs everything declared for this machine
+	DryRun   bool     `json:"dry_run"`
+}
+
+// installResult is one package of a POST /v1/install answer
+type installResult struct {
+	Package          string  `json:"package"`
+	Success          bool    `json:"success"`
+	AlreadyInstalled bool    `json:"already_installed,omitempty"`
+	Error            string  `json:"error,omitempty"`
+	Attempts         int     `json:"attempts,omitempty"`
+	Seconds          float64 `json:"seconds,omitempty"`
+	PostInstall      string  `json:"post_install,omitempty"`
+}
+
+func (s *Server) handleInstall(w http.ResponseWriter, r *http.Request) {
+	var req installRequest
+	if err := decode(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if system.IsOffline() && !req.DryRun {
+		writeError(w, http.StatusServiceUnavailable, errors.New("offline mode: installing needs the network"))
+		return
+	}
+	retry := installer.RetryPolicy{}
+	if rootConfig, err := parser.ParseRootMerlinTOML(s.Repo.GetRootMerlinConfig()); err == nil {
+		retry.Retries = rootConfig.Settings.InstallRetries
+	}
+	host := machine.CurrentHost(s.Repo)
+
+	var output bytes.Buffer
+	var results []*installer.InstallResult
+	switch req.Kind {
+	case "brew":
+		brewConfig, err := parser.ParseBrewConfig(s.Repo.GetToolConfigDir("brew"))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		brewConfig = brewConfig.ForHost(host)
+		formulae, casks := brewConfig.Formulae, brewConfig.Casks
+		if len(req.Packages) > 0 {
+			if formulae, casks, err = selectBrewPackages(brewConfig, req.Packages); err != nil {
+				writeError(w, http.StatusBadRequest, err)
+				return
+			}
+		}
+		brewInstaller := installer.NewBrewInstaller(req.DryRun, 0)
+		brewInstaller.Retry = retry
+		results = append(brewInstaller.InstallFormulae(formulae, &output), brewInstaller.InstallCasks(casks, &output)...)
+	case "mas":
+		masConfig, err := parser.ParseMASTOML(s.Repo.GetToolConfigDir("mas") + "/mas.toml")
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		apps := masConfig.ForHost(host).Apps
+		if len(req.Packages) > 0 {
+			if apps, err = selectMASApps(apps, req.Packages); err != nil {
+				writeError(w, http.StatusBadRequest, err)
+				return
+			}
+		}
+		masInstaller := installer.NewMASInstaller(req.DryRun, 0)
+		masInstaller.Retry = retry
+		results = masInstaller.InstallApps(apps, &output)
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unknown kind %q (must be: brew or mas)", req.Kind))
+		return
+	}
+
+	out := make([]installResult, 0, len(results))
+	for _, res := range results {
+		item := installResult{
+			Package:          res.Package,
+			Success:          res.Success,
+			AlreadyInstalled: res.AlreadyExists,
+			Attempts:         res.Attempts,
+			Seconds:          res.Duration.Seconds(),
+			PostInstall:      res.PostInstall,
+		}
+		if res.Error != nil {
+			item.Error = res.Error.Error()
+		}
+		out = append(out, item)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"dry_run": req.DryRun, "results": out, "output": output.String()})
+}
+
+// selectBrewPackages picks the named formulae and casks
+func selectBrewPackages(brewConfig *models.BrewConfig, names []string) (formulae, casks []models.BrewPackage, err error) {
+	for _, name := range names {
+		if i := slices.IndexFunc(brewConfig.Formulae, func(p models.BrewPackage) bool { return p.Name == name }); i >= 0 {
+			formulae = append(formulae, brewConfig.Formulae[i])
+		} else if i := slices.IndexFunc(brewConfig.Casks, func(p models.BrewPackage) bool { return p.Name == name }); i >= 0 {
+			casks = append(casks, brewConfig.Casks[i])
+		} else {
+			return nil, nil, fmt.Errorf("%s is not declared for this machine in brew.toml", name)
+		}
+	}
+	return formulae, casks, nil
+}
+
+// selectMASApps picks the apps named or identified by names
+func selectMASApps(apps []models.MASApp, names []string) ([]models.MASApp, error) {
+	var selected []models.MASApp
+	for _, name := range names {
+		i := slices.IndexFunc(apps, func(a models.MASApp) bool { return a.Name == name || fmt.Sprint(a.ID) == name })
+		if i < 0 {
+			return nil, fmt.Errorf("%s is not declared for this machine in mas.toml", name)
+		}
+		selected = append(selected, apps[i])
+	}
+	return selected, nil
+}
+
+func (s *Server) handleBackups(w http.ResponseWriter, r *http.Request) {
+	backups, err := backup.ListBackups()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("list backups: %w", err))
+		return
+	}
+	if backups == nil {
+		backups = []*backup.BackupManifest{}
+	}
+	writeJSON(w, http.StatusOK, backups)
+}
+
+// backupRequest is the body of POST /v1/backups
+type backupRequest struct {
+	Files  []string `json:"files"`
+	Reason string   `json:"reason"`
+}
+
+func (s *Server) handleCreateBackup(w http.ResponseWriter, r *http.Request) {
+	req := backupRequest{Reason: "Backup via merlin serve"}
+	if err := decode(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if len(req.Files) == 0 {
+		writeError(w, http.StatusBadRequest, errors.New("no files to back up"))
+		return
+	}
+	backup.StartOperation()
+	manifest, err := backup.CreateBackup(req.Files, req.Reason)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("create backup: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusCreated, manifest)
+}
+
+// restoreRequest is the body of POST /v1/backups/{id}/restore
+type restoreRequest struct {
+	Files    []string `json:"files"` // empty restores every file
+	DryRun   bool     `json:"dry_run"`
+	NoSafety bool     `json:"no_safety"` // skip backing up the current files first
+}
+
+// restoreResult is one file of a restore answer
+type restoreResult struct {
+	Path    string `json:"path"`
+	Status  string `json:"status"` // restored, would restore, skipped or failed
+	Warning string `json:"warning,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+func (s *Server) handleRestoreBackup(w http.ResponseWriter, r *http.Request) {
+	var req restoreRequest
+	if err := decode(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	id := r.PathValue("id")
+	if _, err := backup.GetBackupInfo(id); err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	backup.StartOperation()
+	safetyID := ""
+	if !req.DryRun && !req.NoSafety {
+		safety, err := backup.CreateSafetyBackup(id, req.Files, nil)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, fmt.Errorf("create safety backup: %w", err))
+			return
+		}
+		if safety != nil {
+			safetyID = safety.ID
+		}
+	}
+	results, err := backup.RestoreBackupResults(id, req.Files, nil, req.DryRun)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("restore backup: %w", err))
+		return
+	}
+	out := make([]restoreResult, 0, len(results))
+	for _, res := range results {
+		item := restoreResult{Path: res.Path, Status: string(res.Status), Warning: res.Warning}
+		if res.Err != nil {
+			item.Error = res.Err.Error()
+		}
+		out = append(out, item)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"dry_run": req.DryRun, "safety_backup": safetyID, "results": out})
+}
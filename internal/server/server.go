@@ -0,0 +1,137 @@
+// Package server exposes merlin's core operations (status, diff, link,
+// install, backups) as a localhost HTTP/JSON API for 'merlin serve', so
+// fleet tooling or a GUI can drive merlin without scraping terminal output.
+// Every request needs the bearer token; operations that change the system
+// run one at a time.
+package server
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/ildx/merlin/internal/config"
+)
+
+// DefaultAddr is where merlin serve listens unless told otherwise
+const DefaultAddr = "127.0.0.1:7420"
+
+// TokenEnv overrides the token file
+const TokenEnv = "MERLIN_API_TOKEN"
+
+// maxBody bounds request bodies; requests only carry names and flags
+const maxBody = 1 << 20
+
+// Server answers API requests for one dotfiles repository
+type Server struct {
+	Repo    *config.DotfilesRepo
+	Token   string
+	Version string
+
+	mu sync.Mutex // held by operations that change the system or merlin's state
+}
+
+// Handler returns the API routes behind token authentication
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/status", s.handleStatus)
+	mux.HandleFunc("GET /v1/diff", s.exclusive(s.handleDiff))
+	mux.HandleFunc("POST /v1/link", s.exclusive(s.handleLink))
+	mux.HandleFunc("POST /v1/install", s.exclusive(s.handleInstall))
+	mux.HandleFunc("GET /v1/backups", s.handleBackups)
+	mux.HandleFunc("POST /v1/backups", s.exclusive(s.handleCreateBackup))
+	mux.HandleFunc("POST /v1/backups/{id}/restore", s.exclusive(s.handleRestoreBackup))
+	return s.authenticate(mux)
+}
+
+// authenticate rejects requests without "Authorization: Bearer <token>"
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || s.Token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.Token)) != 1 {
+			writeError(w, http.StatusUnauthorized, errors.New("missing or invalid bearer token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// exclusive runs handler while no other system-changing operation runs
+func (s *Server) exclusive(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		handler(w, r)
+	}
+}
+
+// decode reads a JSON request body into v; an empty body leaves v as is
+func decode(r *http.Request, v any) error {
+	dec := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+// writeError answers {"error": "..."}
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// TokenPath returns where the API token is kept
+func TokenPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("get home directory: %w", err)
+	}
+	return filepath.Join(home, ".merlin", "serve.token"), nil
+}
+
+// LoadOrCreateToken returns the token clients must send: MERLIN_API_TOKEN
+// when set, else the one in TokenPath, which is generated (readable only by
+// the user) on first use
+func LoadOrCreateToken() (string, error) {
+	if token := os.Getenv(TokenEnv); token != "" {
+		return token, nil
+	}
+	path, err := TokenPath()
+	if err != nil {
+		return "", err
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		if token := strings.TrimSpace(string(data)); token != "" {
+			return token, nil
+		}
+	}
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("generate token: %w", err)
+	}
+	token := hex.EncodeToString(raw)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("create state directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(token+"\n"), 0600); err != nil {
+		return "", fmt.Errorf("write token: %w", err)
+	}
+	return token, nil
+}
@@ -0,0 +1,154 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ildx/merlin/internal/backup"
+	"github.com/ildx/merlin/internal/config"
+)
+
+// newTestServer serves an empty repository with HOME in a temp directory
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(backup.EnvVarBackupDir, "")
+	backup.Configure("", nil, home)
+	t.Cleanup(func() { backup.Configure("", nil, "") })
+
+	root := t.TempDir()
+	rootCfg := "metadata = { name = \"test\" }\n[settings]\nconflict_strategy = \"skip\"\n"
+	if err := os.WriteFile(filepath.Join(root, "merlin.toml"), []byte(rootCfg), 0644); err != nil {
+		t.Fatalf("write merlin.toml: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "config"), 0755); err != nil {
+		t.Fatalf("mkdir config: %v", err)
+	}
+	repo := &config.DotfilesRepo{Root: root, ConfigDir: filepath.Join(root, "config")}
+
+	ts := httptest.NewServer((&Server{Repo: repo, Token: "secret", Version: "test"}).Handler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func request(t *testing.T, ts *httptest.Server, method, path, token, body string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, ts.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestAuthentication(t *testing.T) {
+	ts := newTestServer(t)
+
+	for _, token := range []string{"", "wrong"} {
+		if resp := request(t, ts, "GET", "/v1/status", token, ""); resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("token %q: status = %d, want 401", token, resp.StatusCode)
+		}
+	}
+
+	resp := request(t, ts, "GET", "/v1/status", "secret", "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	var status statusResponse
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if status.Version != "test" || status.Drift != nil {
+		t.Errorf("status = %+v", status)
+	}
+}
+
+func TestBadRequests(t *testing.T) {
+	ts := newTestServer(t)
+
+	tests := []struct {
+		name, method, path, body string
+		want                     int
+	}{
+		{"unknown tool", "POST", "/v1/link", `{"tools": ["nope"]}`, http.StatusBadRequest},
+		{"interactive strategy", "POST", "/v1/link", `{"strategy": "interactive"}`, http.StatusBadRequest},
+		{"unknown field", "POST", "/v1/link", `{"force": true}`, http.StatusBadRequest},
+		{"unknown install kind", "POST", "/v1/install", `{"kind": "apt", "dry_run": true}`, http.StatusBadRequest},
+		{"backup without files", "POST", "/v1/backups", `{}`, http.StatusBadRequest},
+		{"restore missing backup", "POST", "/v1/backups/nope/restore", `{"dry_run": true}`, http.StatusNotFound},
+		{"wrong method", "DELETE", "/v1/backups", ``, http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if resp := request(t, ts, tt.method, tt.path, "secret", tt.body); resp.StatusCode != tt.want {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackupRoundTrip(t *testing.T) {
+	ts := newTestServer(t)
+	file := filepath.Join(os.Getenv("HOME"), ".zshrc")
+	if err := os.WriteFile(file, []byte("export A=1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	resp := request(t, ts, "POST", "/v1/backups", "secret", `{"files": ["`+file+`"], "reason": "test"}`)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create status = %d", resp.StatusCode)
+	}
+	var created backup.BackupManifest
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	var listed []backup.BackupManifest
+	if err := json.NewDecoder(request(t, ts, "GET", "/v1/backups", "secret", "").Body).Decode(&listed); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(listed) != 1 || listed[0].ID != created.ID {
+		t.Fatalf("listed = %+v, want %s", listed, created.ID)
+	}
+
+	if err := os.WriteFile(file, []byte("changed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	resp = request(t, ts, "POST", "/v1/backups/"+created.ID+"/restore", "secret", `{"no_safety": true}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("restore status = %d", resp.StatusCode)
+	}
+	if data, _ := os.ReadFile(file); string(data) != "export A=1\n" {
+		t.Errorf("restored content = %q", data)
+	}
+}
+
+func TestLoadOrCreateToken(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(TokenEnv, "")
+
+	first, err := LoadOrCreateToken()
+	if err != nil || len(first) != 64 {
+		t.Fatalf("LoadOrCreateToken() = %q, %v", first, err)
+	}
+	if second, _ := LoadOrCreateToken(); second != first {
+		t.Errorf("token changed between runs: %q, %q", first, second)
+	}
+	t.Setenv(TokenEnv, "from-env")
+	if got, _ := LoadOrCreateToken(); got != "from-env" {
+		t.Errorf("env token = %q", got)
+	}
+}
@@ -0,0 +1,116 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// PackageCache is the last package state observed while online. Offline runs
+// read it instead of querying brew/mas.
+type PackageCache struct {
+	CollectedAt  time.Time       `json:"collected_at"`
+	BrewFormulae map[string]bool `json:"brew_formulae"`
+	BrewCasks    map[string]bool `json:"brew_casks"`
+	MASApps      map[string]bool `json:"mas_apps"`
+	MASStale     []string        `json:"mas_stale,omitempty"`
+	Extensions   map[string]bool `json:"extensions,omitempty"`
+}
+
+// CachePath returns the location of the package state cache
+func CachePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("get home directory: %w", err)
+	}
+	return filepath.Join(home, ".merlin", "cache", "packages.json"), nil
+}
+
+// SavePackageCache records the package portion of a snapshot for later offline
+// use. A snapshot whose listings failed, or that lists no packages at all while
+// the cache has some, is not saved, so one bad online run doesn't wipe the last
+// good state.
+func SavePackageCache(snap *SystemSnapshot) error {
+	if len(snap.ListErrors) > 0 {
+		return fmt.Errorf("package listing failed, cache kept: %s", strings.Join(snap.ListErrors, "; "))
+	}
+	if len(snap.BrewFormulae)+len(snap.BrewCasks)+len(snap.MASApps)+len(snap.Extensions) == 0 {
+		if cached, err := LoadPackageCache(); err == nil &&
+			len(cached.BrewFormulae)+len(cached.BrewCasks)+len(cached.MASApps)+len(cached.Extensions) > 0 {
+			return errors.New("no packages listed, cache kept")
+		}
+	}
+	path, err := CachePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create cache directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(PackageCache{
+		CollectedAt:  time.Now(),
+		BrewFormulae: snap.BrewFormulae,
+		BrewCasks:    snap.BrewCasks,
+		MASApps:      snap.MASApps,
+		MASStale:     snap.MASStale,
+		Extensions:   snap.Extensions,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// LoadPackageCache reads the cached package state. A missing cache returns os.ErrNotExist.
+func LoadPackageCache() (*PackageCache, error) {
+	path, err := CachePath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cache PackageCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, fmt.Errorf("parse package cache: %w", err)
+	}
+	return &cache, nil
+}
+
+// CollectOfflineSnapshot builds a snapshot from cached package state plus live
+// symlinks. When no cache exists the package sets are empty and cache is nil.
+func CollectOfflineSnapshot(rootDir string) (*SystemSnapshot, *PackageCache) {
+	snap := &SystemSnapshot{
+		BrewFormulae: make(map[string]bool),
+		BrewCasks:    make(map[string]bool),
+		MASApps:      make(map[string]bool),
+		Extensions:   make(map[string]bool),
+		Symlinks:     collectSymlinks(rootDir),
+	}
+
+	cache, err := LoadPackageCache()
+	if err != nil {
+		return snap, nil
+	}
+	if cache.BrewFormulae != nil {
+		snap.BrewFormulae = cache.BrewFormulae
+	}
+	if cache.BrewCasks != nil {
+		snap.BrewCasks = cache.BrewCasks
+	}
+	if cache.MASApps != nil {
+		snap.MASApps = cache.MASApps
+	}
+	snap.MASStale = cache.MASStale
+	if cache.Extensions != nil {
+		snap.Extensions = cache.Extensions
+	}
+	return snap, cache
+}
@@ -0,0 +1,51 @@
+package state
+
+import (
+	"os"
+	"testing"
+)
+
+func TestPackageCacheRoundTrip(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", originalHome)
+
+	// No cache yet: offline snapshot still has usable empty maps
+	snap, cache := CollectOfflineSnapshot("")
+	if cache != nil {
+		t.Fatal("expected nil cache before any save")
+	}
+	if snap.BrewFormulae == nil || snap.BrewCasks == nil || snap.MASApps == nil {
+		t.Fatal("expected non-nil maps in offline snapshot")
+	}
+
+	online := &SystemSnapshot{
+		BrewFormulae: map[string]bool{"bat": true},
+		BrewCasks:    map[string]bool{"cursor": true},
+		MASApps:      map[string]bool{"937984704": true},
+	}
+	if err := SavePackageCache(online); err != nil {
+		t.Fatalf("SavePackageCache() error = %v", err)
+	}
+
+	snap, cache = CollectOfflineSnapshot("")
+	if cache == nil || cache.CollectedAt.IsZero() {
+		t.Fatal("expected cache with timestamp after save")
+	}
+	if !snap.BrewFormulae["bat"] || !snap.BrewCasks["cursor"] || !snap.MASApps["937984704"] {
+		t.Errorf("offline snapshot did not use cached packages: %+v", snap)
+	}
+
+	// A failed or empty listing keeps the last good cache
+	failed := &SystemSnapshot{BrewFormulae: map[string]bool{}, ListErrors: []string{"brew list --formula: exit status 1"}}
+	if err := SavePackageCache(failed); err == nil {
+		t.Error("SavePackageCache() saved a snapshot whose listing failed")
+	}
+	if err := SavePackageCache(&SystemSnapshot{}); err == nil {
+		t.Error("SavePackageCache() replaced the cache with an empty snapshot")
+	}
+	if snap, _ = CollectOfflineSnapshot(""); !snap.BrewFormulae["bat"] {
+		t.Errorf("cached packages lost: %+v", snap)
+	}
+}
@@ -0,0 +1,118 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Drift is the summary of the last diff, persisted so prompt integrations can
+// show it without recomputing anything
+type Drift struct {
+	CollectedAt time.Time `json:"collected_at"`
+	Repo        string    `json:"repo"`
+	Missing     int       `json:"missing"` // declared but not installed/linked (⇡)
+	Broken      int       `json:"broken"`  // broken, divergent or orphaned links, stale apps, missing scripts (✘)
+	Extra       int       `json:"extra"`   // installed or present but undeclared (+)
+}
+
+// DefaultPromptFormat renders "✔" when in sync, otherwise e.g. "3⇡ 2✘"
+const DefaultPromptFormat = "{status}"
+
+// Total is the number of drifted items
+func (d *Drift) Total() int {
+	return d.Missing + d.Broken + d.Extra
+}
+
+// Status is the terse indicator: "✔", or the non-zero counts as "3⇡ 2✘ 1+"
+func (d *Drift) Status() string {
+	if d.Total() == 0 {
+		return "✔"
+	}
+	var parts []string
+	for _, p := range []struct {
+		n      int
+		symbol string
+	}{{d.Missing, "⇡"}, {d.Broken, "✘"}, {d.Extra, "+"}} {
+		if p.n > 0 {
+			parts = append(parts, fmt.Sprintf("%d%s", p.n, p.symbol))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// Format renders a prompt template. Placeholders: {status}, {missing},
+// {broken}, {extra}, {total} and {age} (time since the summary was taken,
+// e.g. "5m").
+func (d *Drift) Format(template string, now time.Time) string {
+	if template == "" {
+		template = DefaultPromptFormat
+	}
+	return strings.NewReplacer(
+		"{status}", d.Status(),
+		"{missing}", fmt.Sprint(d.Missing),
+		"{broken}", fmt.Sprint(d.Broken),
+		"{extra}", fmt.Sprint(d.Extra),
+		"{total}", fmt.Sprint(d.Total()),
+		"{age}", formatAge(now.Sub(d.CollectedAt)),
+	).Replace(template)
+}
+
+// formatAge renders a duration in its largest whole unit (s, m, h, d)
+func formatAge(age time.Duration) string {
+	switch {
+	case age < time.Minute:
+		return fmt.Sprintf("%ds", int(age.Seconds()))
+	case age < time.Hour:
+		return fmt.Sprintf("%dm", int(age.Minutes()))
+	case age < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(age.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(age.Hours()/24))
+	}
+}
+
+// DriftPath returns the location of the persisted drift summary
+func DriftPath() (string, error) {
+	cache, err := CachePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(cache), "drift.json"), nil
+}
+
+// SaveDrift persists a drift summary
+func SaveDrift(d *Drift) error {
+	path, err := DriftPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create cache directory: %w", err)
+	}
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// LoadDrift reads the persisted drift summary. A missing summary returns os.ErrNotExist.
+func LoadDrift() (*Drift, error) {
+	path, err := DriftPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var d Drift
+	if err := json.Unmarshal(data, &d); err != nil {
+		return nil, fmt.Errorf("parse drift summary: %w", err)
+	}
+	return &d, nil
+}
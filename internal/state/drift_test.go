@@ -0,0 +1,50 @@
+package state
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestDriftFormat(t *testing.T) {
+	now := time.Date(2025, 1, 8, 15, 0, 0, 0, time.UTC)
+	clean := &Drift{CollectedAt: now.Add(-30 * time.Second)}
+	drifted := &Drift{CollectedAt: now.Add(-90 * time.Minute), Missing: 3, Broken: 2}
+
+	cases := []struct {
+		drift    *Drift
+		template string
+		want     string
+	}{
+		{clean, "", "✔"},
+		{clean, "{status} {age}", "✔ 30s"},
+		{drifted, "", "3⇡ 2✘"},
+		{&Drift{Extra: 1}, "{status}", "1+"},
+		{drifted, "{missing}/{broken}/{extra} = {total} ({age})", "3/2/0 = 5 (1h)"},
+	}
+	for _, c := range cases {
+		if got := c.drift.Format(c.template, now); got != c.want {
+			t.Errorf("Format(%q) = %q, want %q", c.template, got, c.want)
+		}
+	}
+}
+
+func TestDriftRoundTrip(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	if _, err := LoadDrift(); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("LoadDrift() before save error = %v, want not-exist", err)
+	}
+	saved := &Drift{CollectedAt: time.Now().Round(time.Second), Repo: "/repo", Missing: 1, Broken: 2, Extra: 3}
+	if err := SaveDrift(saved); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadDrift()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.CollectedAt.Equal(saved.CollectedAt) || loaded.Status() != "1⇡ 2✘ 3+" || loaded.Repo != "/repo" {
+		t.Errorf("loaded %+v, want %+v", loaded, saved)
+	}
+}
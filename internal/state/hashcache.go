@@ -0,0 +1,126 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// hashEntry is a file's content hash and the stat it was computed from
+type hashEntry struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+	Sum     string    `json:"sum"`
+}
+
+// HashCache remembers file content hashes by path, size and modification
+// time, so the divergence check of merlin diff only re-hashes files that
+// changed since the last run. It is safe for concurrent use.
+type HashCache struct {
+	mu      sync.Mutex
+	Entries map[string]hashEntry `json:"entries"`
+	used    map[string]bool
+	dirty   bool
+	hits    int
+	misses  int
+}
+
+// HashCachePath returns the location of the file hash cache
+func HashCachePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("get home directory: %w", err)
+	}
+	return filepath.Join(home, ".merlin", "state", "hashes.json"), nil
+}
+
+// LoadHashCache reads the hash cache; a missing or unreadable cache is empty
+func LoadHashCache() *HashCache {
+	cache := &HashCache{Entries: make(map[string]hashEntry), used: make(map[string]bool)}
+	path, err := HashCachePath()
+	if err != nil {
+		return cache
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cache
+	}
+	if err := json.Unmarshal(data, cache); err != nil || cache.Entries == nil {
+		cache.Entries = make(map[string]hashEntry)
+	}
+	return cache
+}
+
+// Sum returns the hash of the file at path, calling hash only when the file
+// is new to the cache or its size or modification time changed
+func (c *HashCache) Sum(path string, hash func(path string) (string, error)) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	c.used[path] = true
+	entry, ok := c.Entries[path]
+	if ok && entry.Size == info.Size() && entry.ModTime.Equal(info.ModTime()) {
+		c.hits++
+		c.mu.Unlock()
+		return entry.Sum, nil
+	}
+	c.misses++
+	c.mu.Unlock()
+
+	sum, err := hash(path)
+	if err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	c.Entries[path] = hashEntry{Size: info.Size(), ModTime: info.ModTime(), Sum: sum}
+	c.dirty = true
+	c.mu.Unlock()
+	return sum, nil
+}
+
+// Counts returns how many lookups were answered from the cache and how many
+// hashed the file, since the cache was loaded
+func (c *HashCache) Counts() (hits, misses int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
+
+// Save writes the cache, keeping only the files looked up since it was
+// loaded so entries for files no longer linked don't pile up. Nothing is
+// written when no entry changed or was dropped.
+func (c *HashCache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for path := range c.Entries {
+		if !c.used[path] {
+			delete(c.Entries, path)
+			c.dirty = true
+		}
+	}
+	if !c.dirty {
+		return nil
+	}
+
+	path, err := HashCachePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create state directory: %w", err)
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return err
+	}
+	c.dirty = false
+	return nil
+}
@@ -0,0 +1,65 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHashCache(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", originalHome)
+
+	file := filepath.Join(tmpDir, "config")
+	if err := os.WriteFile(file, []byte("a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	calls := 0
+	hash := func(p string) (string, error) {
+		calls++
+		data, err := os.ReadFile(p)
+		return string(data), err
+	}
+
+	cache := LoadHashCache()
+	if sum, err := cache.Sum(file, hash); err != nil || sum != "a" {
+		t.Fatalf("Sum() = %q, %v", sum, err)
+	}
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// Unchanged file: answered from the reloaded cache
+	cache = LoadHashCache()
+	if sum, _ := cache.Sum(file, hash); sum != "a" || calls != 1 {
+		t.Errorf("unchanged file: sum %q after %d hash calls, want a after 1", sum, calls)
+	}
+	if hits, misses := cache.Counts(); hits != 1 || misses != 0 {
+		t.Errorf("Counts() = %d, %d, want 1, 0", hits, misses)
+	}
+
+	// Changed file: hashed again
+	if err := os.WriteFile(file, []byte("bb"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(file, later, later)
+	if sum, _ := cache.Sum(file, hash); sum != "bb" || calls != 2 {
+		t.Errorf("changed file: sum %q after %d hash calls, want bb after 2", sum, calls)
+	}
+
+	// Entries not looked up since loading are dropped on save
+	if err := cache.Save(); err != nil {
+		t.Fatal(err)
+	}
+	cache = LoadHashCache()
+	if err := cache.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if cache = LoadHashCache(); len(cache.Entries) != 0 {
+		t.Errorf("expected unused entries pruned, got %v", cache.Entries)
+	}
+}
@@ -0,0 +1,137 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// LinkOwner records which tool created a link and what it points to
+type LinkOwner struct {
+	Tool   string `json:"tool"`
+	Source string `json:"source"`
+}
+
+// LinkRegistry tracks the owner of every symlink merlin created, file by file,
+// so unlinking a tool removes exactly its links even when several tools merge
+// files into one directory or its config no longer declares them
+type LinkRegistry struct {
+	Links map[string]LinkOwner `json:"links"` // target → owner
+
+	corrupt string // unparsable registry file that couldn't be moved aside; Save refuses to overwrite it
+}
+
+// LinkRegistryPath returns the location of the link registry
+func LinkRegistryPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("get home directory: %w", err)
+	}
+	return filepath.Join(home, ".merlin", "links.json"), nil
+}
+
+// LoadLinkRegistry reads the link registry; a missing registry is empty. An
+// unparsable registry is moved aside to links.json.corrupt and an empty one
+// returned with the error, so saving it doesn't lose the records; if it can't
+// be moved, Save refuses to overwrite it.
+func LoadLinkRegistry() (*LinkRegistry, error) {
+	registry := &LinkRegistry{Links: make(map[string]LinkOwner)}
+	path, err := LinkRegistryPath()
+	if err != nil {
+		return registry, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return registry, nil
+	}
+	if err != nil {
+		return registry, err
+	}
+	if err := json.Unmarshal(data, registry); err != nil {
+		registry = &LinkRegistry{Links: make(map[string]LinkOwner)}
+		aside := path + ".corrupt"
+		if renameErr := os.Rename(path, aside); renameErr != nil {
+			registry.corrupt = path
+			return registry, fmt.Errorf("parse link registry: %w (not moved aside: %v)", err, renameErr)
+		}
+		return registry, fmt.Errorf("parse link registry: %w (moved to %s, starting a new registry)", err, aside)
+	}
+	if registry.Links == nil {
+		registry.Links = make(map[string]LinkOwner)
+	}
+	return registry, nil
+}
+
+// Save writes the registry atomically, so merlin serve and the CLI never
+// leave a torn file behind
+func (r *LinkRegistry) Save() error {
+	if r.corrupt != "" {
+		return fmt.Errorf("not overwriting unparsable %s; fix or remove it", r.corrupt)
+	}
+	path, err := LinkRegistryPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create state directory: %w", err)
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "links-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Record notes that tool linked target to source
+func (r *LinkRegistry) Record(tool, source, target string) {
+	r.Links[target] = LinkOwner{Tool: tool, Source: source}
+}
+
+// Forget drops target from the registry
+func (r *LinkRegistry) Forget(target string) {
+	delete(r.Links, target)
+}
+
+// Owner returns the recorded owner of target
+func (r *LinkRegistry) Owner(target string) (LinkOwner, bool) {
+	owner, ok := r.Links[target]
+	return owner, ok
+}
+
+// Targets returns the targets recorded for tool, sorted
+func (r *LinkRegistry) Targets(tool string) []string {
+	var targets []string
+	for target, owner := range r.Links {
+		if owner.Tool == tool {
+			targets = append(targets, target)
+		}
+	}
+	sort.Strings(targets)
+	return targets
+}
+
+// ToolsByTarget returns the tool owning each recorded target
+func (r *LinkRegistry) ToolsByTarget() map[string]string {
+	tools := make(map[string]string, len(r.Links))
+	for target, owner := range r.Links {
+		tools[target] = owner.Tool
+	}
+	return tools
+}
@@ -0,0 +1,48 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadLinkRegistryCorrupt(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path, err := LinkRegistryPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(`{"links": {`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	registry, err := LoadLinkRegistry()
+	if err == nil {
+		t.Fatal("LoadLinkRegistry() = nil error for an unparsable registry")
+	}
+	if data, err := os.ReadFile(path + ".corrupt"); err != nil || string(data) != `{"links": {` {
+		t.Errorf("corrupt registry not moved aside: %q, %v", data, err)
+	}
+
+	registry.Record("zsh", "/repo/config/zsh/.zshrc", "/home/.zshrc")
+	if err := registry.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	reloaded, err := LoadLinkRegistry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if owner, ok := reloaded.Owner("/home/.zshrc"); !ok || owner.Tool != "zsh" {
+		t.Errorf("Owner() = %+v, %v", owner, ok)
+	}
+}
+
+func TestLinkRegistrySaveRefusesUnmovedCorrupt(t *testing.T) {
+	registry := &LinkRegistry{Links: make(map[string]LinkOwner), corrupt: "/home/.merlin/links.json"}
+	if err := registry.Save(); err == nil {
+		t.Error("Save() = nil, want an error instead of overwriting the corrupt registry")
+	}
+}
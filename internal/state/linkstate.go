@@ -0,0 +1,95 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ToolLinkState is a tool's link status when it was last checked
+type ToolLinkState struct {
+	Links     int       `json:"links"` // file-level links the tool declares
+	Linked    int       `json:"linked"`
+	Conflicts int       `json:"conflicts"` // targets in the way of the tool's links
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Status summarizes the state: "linked" when every link is in place,
+// "conflict" when a target is in the way, "partial" when only some links are
+// in place and "unlinked" when none is
+func (s ToolLinkState) Status() string {
+	switch {
+	case s.Conflicts > 0:
+		return "conflict"
+	case s.Linked >= s.Links:
+		return "linked"
+	case s.Linked > 0:
+		return "partial"
+	default:
+		return "unlinked"
+	}
+}
+
+// LinkStateCache remembers each tool's link status so merlin list configs
+// can show it without checking every target. merlin link and unlink refresh
+// the tools they touch. Entries are keyed by tool root, so several
+// repositories can share the file.
+type LinkStateCache struct {
+	Tools map[string]ToolLinkState `json:"tools"`
+}
+
+// LinkStateCachePath returns the location of the link status cache
+func LinkStateCachePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("get home directory: %w", err)
+	}
+	return filepath.Join(home, ".merlin", "cache", "link-state.json"), nil
+}
+
+// LoadLinkStateCache reads the link status cache; a missing or unreadable
+// cache is empty
+func LoadLinkStateCache() *LinkStateCache {
+	cache := &LinkStateCache{Tools: make(map[string]ToolLinkState)}
+	path, err := LinkStateCachePath()
+	if err != nil {
+		return cache
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cache
+	}
+	if err := json.Unmarshal(data, cache); err != nil || cache.Tools == nil {
+		cache.Tools = make(map[string]ToolLinkState)
+	}
+	return cache
+}
+
+// Get returns the cached state of the tool at toolRoot
+func (c *LinkStateCache) Get(toolRoot string) (ToolLinkState, bool) {
+	s, ok := c.Tools[toolRoot]
+	return s, ok
+}
+
+// Set records the state of the tool at toolRoot
+func (c *LinkStateCache) Set(toolRoot string, s ToolLinkState) {
+	c.Tools[toolRoot] = s
+}
+
+// Save writes the cache
+func (c *LinkStateCache) Save() error {
+	path, err := LinkStateCachePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create cache directory: %w", err)
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,44 @@
+package state
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLinkStateCache(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", originalHome)
+
+	cache := LoadLinkStateCache()
+	if _, ok := cache.Get("/repo/config/zsh"); ok {
+		t.Fatal("empty cache should have no entries")
+	}
+	checked := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	cache.Set("/repo/config/zsh", ToolLinkState{Links: 3, Linked: 3, CheckedAt: checked})
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	s, ok := LoadLinkStateCache().Get("/repo/config/zsh")
+	if !ok || s.Linked != 3 || !s.CheckedAt.Equal(checked) {
+		t.Errorf("reloaded state = %+v, %v", s, ok)
+	}
+
+	tests := []struct {
+		state ToolLinkState
+		want  string
+	}{
+		{ToolLinkState{Links: 2, Linked: 2}, "linked"},
+		{ToolLinkState{Links: 2, Linked: 1, Conflicts: 1}, "conflict"},
+		{ToolLinkState{Links: 2, Linked: 1}, "partial"},
+		{ToolLinkState{Links: 2}, "unlinked"},
+	}
+	for _, tt := range tests {
+		if got := tt.state.Status(); got != tt.want {
+			t.Errorf("%+v.Status() = %q, want %q", tt.state, got, tt.want)
+		}
+	}
+}
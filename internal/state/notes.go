@@ -0,0 +1,133 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Note is a remark attached to a managed target, such as why it must not be
+// overwritten
+type Note struct {
+	Text  string    `json:"text"`
+	Added time.Time `json:"added"`
+}
+
+// TargetNotes holds the notes added with 'merlin note add', keyed by the
+// target's absolute path. They are shown wherever the target comes up in
+// conflict, diff and link output.
+type TargetNotes struct {
+	Notes map[string][]Note `json:"notes"`
+}
+
+// TargetNotesPath returns the location of the target notes
+func TargetNotesPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("get home directory: %w", err)
+	}
+	return filepath.Join(home, ".merlin", "state", "notes.json"), nil
+}
+
+// NoteTarget returns the key notes for path are stored under: the absolute,
+// cleaned path with a leading ~ expanded
+func NoteTarget(path string) (string, error) {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("get home directory: %w", err)
+		}
+		path = filepath.Join(home, strings.TrimPrefix(path[1:], "/"))
+	}
+	return filepath.Abs(path)
+}
+
+// LoadTargetNotes reads the target notes; a missing file has none
+func LoadTargetNotes() (*TargetNotes, error) {
+	notes := &TargetNotes{Notes: make(map[string][]Note)}
+	path, err := TargetNotesPath()
+	if err != nil {
+		return notes, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return notes, nil
+	}
+	if err != nil {
+		return notes, err
+	}
+	if err := json.Unmarshal(data, notes); err != nil {
+		return &TargetNotes{Notes: make(map[string][]Note)}, fmt.Errorf("parse target notes: %w", err)
+	}
+	if notes.Notes == nil {
+		notes.Notes = make(map[string][]Note)
+	}
+	return notes, nil
+}
+
+// Save writes the notes
+func (n *TargetNotes) Save() error {
+	path, err := TargetNotesPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create state directory: %w", err)
+	}
+	data, err := json.MarshalIndent(n, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Add attaches text to target
+func (n *TargetNotes) Add(target, text string, now time.Time) {
+	n.Notes[target] = append(n.Notes[target], Note{Text: text, Added: now})
+}
+
+// Remove drops the index-th (1-based) note of target, or all of them when
+// index is 0. It reports whether anything was removed.
+func (n *TargetNotes) Remove(target string, index int) bool {
+	notes := n.Notes[target]
+	switch {
+	case len(notes) == 0 || index < 0 || index > len(notes):
+		return false
+	case index == 0 || len(notes) == 1:
+		delete(n.Notes, target)
+	default:
+		n.Notes[target] = append(notes[:index-1], notes[index:]...)
+	}
+	return true
+}
+
+// For returns the notes of target
+func (n *TargetNotes) For(target string) []Note {
+	if n == nil {
+		return nil
+	}
+	return n.Notes[target]
+}
+
+// Texts returns the text of each note of target
+func (n *TargetNotes) Texts(target string) []string {
+	var texts []string
+	for _, note := range n.For(target) {
+		texts = append(texts, note.Text)
+	}
+	return texts
+}
+
+// Targets returns the annotated targets, sorted
+func (n *TargetNotes) Targets() []string {
+	targets := make([]string, 0, len(n.Notes))
+	for target := range n.Notes {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+	return targets
+}
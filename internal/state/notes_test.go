@@ -0,0 +1,53 @@
+package state
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTargetNotesRoundTrip(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	target, err := NoteTarget("~/.zshrc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if target != filepath.Join(tmpDir, ".zshrc") {
+		t.Fatalf("NoteTarget(~/.zshrc) = %s", target)
+	}
+
+	notes, err := LoadTargetNotes()
+	if err != nil {
+		t.Fatalf("LoadTargetNotes() error = %v", err)
+	}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	notes.Add(target, "uses work plugin; don't overwrite", now)
+	notes.Add(target, "second", now)
+	notes.Add("/etc/hosts", "managed by IT", now)
+	if err := notes.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := LoadTargetNotes()
+	if err != nil {
+		t.Fatalf("LoadTargetNotes() error = %v", err)
+	}
+	if texts := loaded.Texts(target); len(texts) != 2 || texts[0] != "uses work plugin; don't overwrite" {
+		t.Errorf("Texts() = %v", texts)
+	}
+	if targets := loaded.Targets(); len(targets) != 2 || targets[0] != "/etc/hosts" {
+		t.Errorf("Targets() = %v", targets)
+	}
+
+	if loaded.Remove(target, 3) {
+		t.Error("removing a note that doesn't exist should report false")
+	}
+	if !loaded.Remove(target, 1) || len(loaded.For(target)) != 1 || loaded.For(target)[0].Text != "second" {
+		t.Errorf("after removing note 1: %v", loaded.For(target))
+	}
+	if !loaded.Remove("/etc/hosts", 0) || loaded.For("/etc/hosts") != nil {
+		t.Error("index 0 should remove every note of the target")
+	}
+}
@@ -1,10 +1,16 @@
 package state
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
+
+	"github.com/ildx/merlin/internal/installer"
+	"github.com/ildx/merlin/internal/system"
 )
 
 // SystemSnapshot represents a point-in-time view of relevant system state
@@ -13,7 +19,10 @@ type SystemSnapshot struct {
 	BrewFormulae map[string]bool
 	BrewCasks    map[string]bool
 	MASApps      map[string]bool
+	MASStale     []string        // "Name (id)" of apps mas lists whose bundle is gone
+	Extensions   map[string]bool // editor:id (lowercased) from code/cursor --list-extensions
 	Symlinks     []SymlinkEntry
+	ListErrors   []string // package listings that failed, e.g. "brew list --cask: exit status 1"
 }
 
 // SymlinkEntry captures a discovered symlink and its resolution status.
@@ -24,34 +33,46 @@ type SymlinkEntry struct {
 }
 
 // CollectSnapshot gathers current system state. Individual collectors are
-// resilient: failures (e.g., brew not installed) result in empty sets.
+// resilient: a missing tool (e.g. brew not installed) results in an empty
+// set, and a listing that fails also records the error in ListErrors.
 func CollectSnapshot(rootDir string) *SystemSnapshot {
-	return &SystemSnapshot{
-		BrewFormulae: collectBrew("formula"),
-		BrewCasks:    collectBrew("cask"),
-		MASApps:      collectMAS(),
-		Symlinks:     collectSymlinks(rootDir),
+	snap := &SystemSnapshot{Symlinks: collectSymlinks(rootDir)}
+	record := func(err error) {
+		if err != nil {
+			snap.ListErrors = append(snap.ListErrors, err.Error())
+		}
 	}
+	var err error
+	snap.BrewFormulae, err = collectBrew("formula")
+	record(err)
+	snap.BrewCasks, err = collectBrew("cask")
+	record(err)
+	snap.MASApps, snap.MASStale, err = collectMAS()
+	record(err)
+	snap.Extensions, err = collectExtensions()
+	record(err)
+	return snap
 }
 
 // collectBrew collects installed brew items of a given type (formula|cask).
-func collectBrew(kind string) map[string]bool {
+func collectBrew(kind string) (map[string]bool, error) {
 	items := make(map[string]bool)
 	// Check if brew exists
-	if _, err := exec.LookPath("brew"); err != nil {
-		return items
+	brew := system.BrewPath()
+	if _, err := exec.LookPath(brew); err != nil {
+		return items, nil
 	}
 
 	var cmd *exec.Cmd
 	if kind == "formula" {
-		cmd = exec.Command("brew", "list", "--formula")
+		cmd = exec.Command(brew, "list", "--formula")
 	} else {
-		cmd = exec.Command("brew", "list", "--cask")
+		cmd = exec.Command(brew, "list", "--cask")
 	}
 
 	out, err := cmd.Output()
 	if err != nil {
-		return items
+		return items, fmt.Errorf("brew list --%s: %w", kind, err)
 	}
 
 	for _, line := range strings.Split(string(out), "\n") {
@@ -61,36 +82,56 @@ func collectBrew(kind string) map[string]bool {
 		}
 		items[line] = true
 	}
-	return items
+	return items, nil
 }
 
-// collectMAS collects installed MAS apps by id or name.
-// Uses `mas list` output lines like: "123456789 App Name".
-func collectMAS() map[string]bool {
+// collectMAS collects the App Store apps actually present on disk, keyed by
+// id, plus the apps `mas list` still reports after their bundle was deleted.
+// Apps found through their receipt but missing from `mas list` count as
+// installed.
+func collectMAS() (map[string]bool, []string, error) {
 	apps := make(map[string]bool)
 	if _, err := exec.LookPath("mas"); err != nil {
-		return apps
+		return apps, nil, nil
 	}
-
-	cmd := exec.Command("mas", "list")
-	out, err := cmd.Output()
+	scanned, err := installer.ScanMASApps()
 	if err != nil {
-		return apps
+		return apps, nil, err
 	}
-	for _, line := range strings.Split(string(out), "\n") {
-		line = strings.TrimSpace(line)
-		if line == "" {
+	var stale []string
+	for _, app := range scanned {
+		if app.Present {
+			apps[strconv.Itoa(app.ID)] = true
+		} else if app.Stale() {
+			stale = append(stale, app.Label())
+		}
+	}
+	return apps, stale, nil
+}
+
+// collectExtensions lists extensions of the editors whose CLI is installed,
+// keyed as editor:id. Ids are lowercased since the marketplace ignores case.
+func collectExtensions() (map[string]bool, error) {
+	exts := make(map[string]bool)
+	var errs []error
+	for _, editor := range []string{"code", "cursor"} {
+		if _, err := exec.LookPath(editor); err != nil {
 			continue
 		}
-		// Split first token (id) and remainder (name)
-		parts := strings.SplitN(line, " ", 2)
-		if len(parts) != 2 {
+		out, err := exec.Command(editor, "--list-extensions").Output()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s --list-extensions: %w", editor, err))
 			continue
 		}
-		id := parts[0]
-		apps[id] = true
+		for _, line := range strings.Split(string(out), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
+			exts[editor+":"+strings.ToLower(line)] = true
+		}
 	}
-	return apps
+	return exts, errors.Join(errs...)
 }
 
 // collectSymlinks walks the user's home directory and records symlinks whose
@@ -0,0 +1,82 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// TodoState records which [[manual_step]] entries are done on this machine.
+// Steps are keyed by their address: the id for root steps, tool/id for a
+// tool's steps. Anything not recorded is pending.
+type TodoState struct {
+	Done map[string]time.Time `json:"done"` // step → when it was checked off
+}
+
+// TodoStatePath returns the location of the manual step state
+func TodoStatePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("get home directory: %w", err)
+	}
+	return filepath.Join(home, ".merlin", "state", "todo.json"), nil
+}
+
+// LoadTodoState reads the manual step state; a missing file means every step is pending
+func LoadTodoState() (*TodoState, error) {
+	todo := &TodoState{Done: make(map[string]time.Time)}
+	path, err := TodoStatePath()
+	if err != nil {
+		return todo, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return todo, nil
+	}
+	if err != nil {
+		return todo, err
+	}
+	if err := json.Unmarshal(data, todo); err != nil {
+		return &TodoState{Done: make(map[string]time.Time)}, fmt.Errorf("parse todo state: %w", err)
+	}
+	if todo.Done == nil {
+		todo.Done = make(map[string]time.Time)
+	}
+	return todo, nil
+}
+
+// Save writes the state
+func (t *TodoState) Save() error {
+	path, err := TodoStatePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create state directory: %w", err)
+	}
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// IsDone reports whether step has been checked off
+func (t *TodoState) IsDone(step string) bool {
+	_, ok := t.Done[step]
+	return ok
+}
+
+// MarkDone checks step off, keeping the original time if it already was
+func (t *TodoState) MarkDone(step string) {
+	if !t.IsDone(step) {
+		t.Done[step] = time.Now()
+	}
+}
+
+// MarkPending puts step back on the list
+func (t *TodoState) MarkPending(step string) {
+	delete(t.Done, step)
+}
@@ -0,0 +1,41 @@
+package state
+
+import (
+	"os"
+	"testing"
+)
+
+func TestTodoStateRoundTrip(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", tmpDir)
+	defer os.Setenv("HOME", originalHome)
+
+	todo, err := LoadTodoState()
+	if err != nil {
+		t.Fatalf("LoadTodoState() error = %v", err)
+	}
+	if todo.IsDone("karabiner/input-monitoring") {
+		t.Fatal("expected every step pending before any save")
+	}
+
+	todo.MarkDone("karabiner/input-monitoring")
+	todo.MarkDone("app-store")
+	first := todo.Done["app-store"]
+	todo.MarkDone("app-store")
+	if !todo.Done["app-store"].Equal(first) {
+		t.Error("marking a done step again should keep its original time")
+	}
+	todo.MarkPending("app-store")
+	if err := todo.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := LoadTodoState()
+	if err != nil {
+		t.Fatalf("LoadTodoState() error = %v", err)
+	}
+	if !loaded.IsDone("karabiner/input-monitoring") || loaded.IsDone("app-store") {
+		t.Errorf("unexpected state after reload: %+v", loaded.Done)
+	}
+}
@@ -0,0 +1,109 @@
+package symlink
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/ildx/merlin/internal/models"
+)
+
+// binDir returns the directory [[bin]] entries link into
+func (v Variables) binDir() string {
+	if v.BinDir != "" {
+		return v.BinDir
+	}
+	return filepath.Join(v.HomeDir, "bin")
+}
+
+// resolveBin resolves a [[bin]] entry into executable links in the bin
+// directory. Glob matches keep their file names; name renames a single file.
+func resolveBin(bin models.Bin, toolRoot string, vars Variables) ([]ResolvedLink, error) {
+	if IsGlob(bin.Source) {
+		if bin.Name != "" {
+			return nil, fmt.Errorf("bin %s: name cannot be used with a glob source", bin.Source)
+		}
+		matches, err := ExpandGlob(toolRoot, bin.Source)
+		if err != nil {
+			return nil, fmt.Errorf("expand %s: %w", bin.Source, err)
+		}
+		links := make([]ResolvedLink, 0, len(matches))
+		for _, m := range matches {
+			links = append(links, ResolvedLink{Source: m.Path, Target: filepath.Join(vars.binDir(), filepath.Base(m.Path)), Executable: true})
+		}
+		return links, nil
+	}
+
+	source := filepath.Join(toolRoot, bin.Source)
+	info, err := fsys.Stat(source)
+	if err != nil {
+		return nil, fmt.Errorf("source does not exist: %s", source)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("bin %s is a directory; use a glob such as %s/*", bin.Source, bin.Source)
+	}
+	name := bin.Name
+	if name == "" {
+		name = filepath.Base(source)
+	}
+	return []ResolvedLink{{Source: source, Target: filepath.Join(vars.binDir(), name), Executable: true}}, nil
+}
+
+// BinDirs returns the directories the tool's [[bin]] entries link into
+func BinDirs(tool *ToolConfig) []string {
+	seen := make(map[string]bool)
+	var dirs []string
+	for _, link := range tool.Links {
+		if dir := filepath.Dir(link.Target); link.Executable && !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// MarkExecutables adds the execute bits to the sources of the tool's [[bin]]
+// links that are linked at their targets. It returns the sources changed (in
+// dry-run mode, the ones that would be); sources already executable are left
+// alone.
+func MarkExecutables(tool *ToolConfig, dryRun bool) ([]string, error) {
+	var changed []string
+	for _, link := range tool.Links {
+		if !link.Executable {
+			continue
+		}
+		if !dryRun && !IsSymlink(link.Target) {
+			continue // not linked (skipped or conflicting)
+		}
+		info, err := fsys.Stat(link.Source)
+		if err != nil {
+			return changed, err
+		}
+		// Executable wherever the file is readable, like chmod +x
+		mode := info.Mode().Perm()
+		executable := mode | (mode&0o444)>>2 | 0o100
+		if executable == mode {
+			continue
+		}
+		if !dryRun {
+			if err := fsys.Chmod(link.Source, executable); err != nil {
+				return changed, fmt.Errorf("chmod +x %s: %w", link.Source, err)
+			}
+		}
+		changed = append(changed, link.Source)
+	}
+	return changed, nil
+}
+
+// InPath reports whether dir is one of the directories in $PATH
+func InPath(dir string) bool {
+	dir = filepath.Clean(dir)
+	for _, entry := range filepath.SplitList(os.Getenv("PATH")) {
+		if entry != "" && filepath.Clean(entry) == dir {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,160 @@
+package symlink
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ildx/merlin/internal/protect"
+)
+
+// BrokenLink is a symlink at a managed target whose destination is gone
+type BrokenLink struct {
+	Target string // Path of the symlink
+	Dest   string // Where it points
+	Tool   string // Tool that declares or registered the link (StaleLinks only)
+}
+
+// BrokenLinks finds dangling symlinks at a tool's declared targets. Declared
+// file and directory targets are reported whenever they dangle. Contents
+// targets are directories shared with other files, so only the directories
+// the link populates are scanned and only symlinks into repoRoot (ones merlin
+// made) are reported; that catches links left behind by a renamed tool or
+// deleted source without flagging unrelated dangling links in the user's home.
+func BrokenLinks(tool *ToolConfig, repoRoot string) []BrokenLink {
+	seen := make(map[string]bool)
+	var broken []BrokenLink
+	check := func(target string, managedOnly bool) {
+		if seen[target] {
+			return
+		}
+		seen[target] = true
+		dest, ok := danglingDest(target)
+		if !ok || (managedOnly && !within(dest, repoRoot)) {
+			return
+		}
+		broken = append(broken, BrokenLink{Target: target, Dest: dest})
+	}
+
+	for _, link := range tool.Links {
+		if !link.Contents {
+			check(link.Target, false)
+			continue
+		}
+
+		// The target itself, plus every directory the contents walk creates
+		dirs := map[string]bool{link.Target: true}
+		if links, err := ContentLinks(link); err == nil {
+			for _, l := range links {
+				for dir := filepath.Dir(l.Target); within(dir, link.Target); dir = filepath.Dir(dir) {
+					dirs[dir] = true
+				}
+			}
+		}
+		for dir := range dirs {
+			entries, err := fsys.ReadDir(dir)
+			if err != nil {
+				continue
+			}
+			for _, entry := range entries {
+				if entry.Type()&os.ModeSymlink != 0 {
+					check(filepath.Join(dir, entry.Name()), true)
+				}
+			}
+		}
+	}
+
+	sort.Slice(broken, func(i, j int) bool { return broken[i].Target < broken[j].Target })
+	return broken
+}
+
+// StaleLinks finds symlinks into repoRoot left dangling once their source
+// was deleted: those BrokenLinks reports for tools, plus those in the
+// directories of registered targets (target → tool, from the link
+// registry), which catches the links of tools no longer declared. Dangling
+// links pointing outside repoRoot aren't merlin's and are never reported.
+func StaleLinks(tools []*ToolConfig, registered map[string]string, repoRoot string) []BrokenLink {
+	found := make(map[string]BrokenLink)
+	for _, tool := range tools {
+		for _, link := range BrokenLinks(tool, repoRoot) {
+			if within(link.Dest, repoRoot) {
+				link.Tool = tool.Name
+				found[link.Target] = link
+			}
+		}
+	}
+
+	scanned := make(map[string]bool)
+	add := func(target, tool string) {
+		if _, ok := found[target]; ok {
+			return
+		}
+		if dest, ok := danglingDest(target); ok && within(dest, repoRoot) {
+			found[target] = BrokenLink{Target: target, Dest: dest, Tool: tool}
+		}
+	}
+	for target, tool := range registered {
+		add(target, tool)
+		dir := filepath.Dir(target)
+		if scanned[dir] {
+			continue
+		}
+		scanned[dir] = true
+		entries, err := fsys.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.Type()&os.ModeSymlink != 0 {
+				add(filepath.Join(dir, entry.Name()), registered[filepath.Join(dir, entry.Name())])
+			}
+		}
+	}
+
+	stale := make([]BrokenLink, 0, len(found))
+	for _, link := range found {
+		stale = append(stale, link)
+	}
+	sort.Slice(stale, func(i, j int) bool { return stale[i].Target < stale[j].Target })
+	return stale
+}
+
+// RemoveStaleLink deletes the symlink at link.Target after checking it still
+// dangles into the same place and isn't protected
+func RemoveStaleLink(link BrokenLink) error {
+	dest, ok := danglingDest(link.Target)
+	if !ok || dest != link.Dest {
+		return fmt.Errorf("%s is no longer a dangling symlink to %s", link.Target, link.Dest)
+	}
+	if err := protect.Check(link.Target); err != nil {
+		return err
+	}
+	return fsys.Remove(link.Target)
+}
+
+// danglingDest returns the destination of path if it is a symlink whose
+// destination doesn't exist
+func danglingDest(path string) (string, bool) {
+	info, err := fsys.Lstat(path)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return "", false
+	}
+	if _, err := fsys.Stat(path); !os.IsNotExist(err) {
+		return "", false
+	}
+	dest, err := fsys.Readlink(path)
+	if err != nil {
+		return "", false
+	}
+	if !filepath.IsAbs(dest) {
+		dest = filepath.Join(filepath.Dir(path), dest)
+	}
+	return dest, true
+}
+
+// within reports whether path is dir or inside it
+func within(path, dir string) bool {
+	return path == dir || strings.HasPrefix(path, strings.TrimSuffix(dir, string(filepath.Separator))+string(filepath.Separator))
+}
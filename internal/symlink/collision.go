@@ -0,0 +1,114 @@
+package symlink
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// LinkClaim is one link's claim on a target
+type LinkClaim struct {
+	Tool   string `json:"tool"`
+	Source string `json:"source"`
+}
+
+// Collision is a target claimed by more than one link: two sources for the
+// same file, or a whole-directory link over a directory that other links
+// merge files into. Contents-mode links sharing a directory only collide
+// where they provide the same file.
+type Collision struct {
+	Target string      `json:"target"`
+	Claims []LinkClaim `json:"claims"`
+}
+
+// String describes the collision, e.g. "~/.zshrc (zsh: a/.zshrc, work: b/.zshrc)"
+func (c Collision) String() string {
+	claims := make([]string, len(c.Claims))
+	for i, claim := range c.Claims {
+		claims[i] = fmt.Sprintf("%s: %s", claim.Tool, claim.Source)
+	}
+	return fmt.Sprintf("%s (%s)", c.Target, strings.Join(claims, ", "))
+}
+
+// Involves reports whether one of the claims belongs to a tool in names
+func (c Collision) Involves(names map[string]bool) bool {
+	for _, claim := range c.Claims {
+		if names[claim.Tool] {
+			return true
+		}
+	}
+	return false
+}
+
+// FindCollisions reports the targets that the tools' links claim more than
+// once, at file level. Results are sorted by target.
+func FindCollisions(tools []*ToolConfig) []Collision {
+	claims := make(map[string][]LinkClaim)
+	var dirTargets []string
+	for _, tool := range tools {
+		for _, link := range ExpandLinks(tool.Links) {
+			claim := LinkClaim{Tool: tool.Name, Source: link.Source}
+			if !containsClaim(claims[link.Target], claim) {
+				claims[link.Target] = append(claims[link.Target], claim)
+			}
+			if link.IsDir && !link.Contents {
+				dirTargets = append(dirTargets, link.Target)
+			}
+		}
+	}
+
+	collided := make(map[string][]LinkClaim)
+	for target, list := range claims {
+		if len(list) > 1 {
+			collided[target] = list
+		}
+	}
+	// A directory symlink replaces the whole directory, so anything another
+	// link puts beneath it collides with it
+	for _, dir := range dirTargets {
+		prefix := dir + string(filepath.Separator)
+		for target, list := range claims {
+			if !strings.HasPrefix(target, prefix) {
+				continue
+			}
+			if len(collided[dir]) == 0 {
+				collided[dir] = append([]LinkClaim(nil), claims[dir]...)
+			}
+			collided[dir] = append(collided[dir], list...)
+		}
+	}
+
+	collisions := make([]Collision, 0, len(collided))
+	for target, list := range collided {
+		collisions = append(collisions, Collision{Target: target, Claims: dedupeClaims(list)})
+	}
+	sort.Slice(collisions, func(i, j int) bool { return collisions[i].Target < collisions[j].Target })
+	return collisions
+}
+
+func containsClaim(list []LinkClaim, claim LinkClaim) bool {
+	for _, c := range list {
+		if c == claim {
+			return true
+		}
+	}
+	return false
+}
+
+// dedupeClaims drops repeated claims and orders the rest by tool and source
+func dedupeClaims(list []LinkClaim) []LinkClaim {
+	var out []LinkClaim
+	for _, claim := range list {
+		if !containsClaim(out, claim) {
+			out = append(out, claim)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Tool != out[j].Tool {
+			return out[i].Tool < out[j].Tool
+		}
+		return out[i].Source < out[j].Source
+	})
+	return out
+}
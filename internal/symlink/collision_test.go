@@ -0,0 +1,52 @@
+package symlink
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindCollisions(t *testing.T) {
+	root := t.TempDir()
+	write := func(rel string) string {
+		path := filepath.Join(root, rel)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(rel), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	write("zsh/conf.d/aliases.zsh")
+	write("zsh/conf.d/path.zsh")
+	write("work/conf.d/work.zsh")
+	write("work/conf.d/path.zsh")
+	write("extra/conf.d/x.zsh")
+
+	confd := filepath.Join(root, "home", ".config", "zsh", "conf.d")
+	contents := func(tool string) *ToolConfig {
+		return &ToolConfig{Name: tool, Links: []ResolvedLink{{
+			Source: filepath.Join(root, tool, "conf.d"), Target: confd, IsDir: true, Contents: true,
+		}}}
+	}
+
+	// Merging different files into one directory is fine
+	if got := FindCollisions([]*ToolConfig{contents("zsh"), contents("extra")}); len(got) != 0 {
+		t.Errorf("FindCollisions() = %v, want none", got)
+	}
+
+	// Two tools providing the same file collide on that file only
+	got := FindCollisions([]*ToolConfig{contents("zsh"), contents("work"), contents("extra")})
+	if len(got) != 1 || got[0].Target != filepath.Join(confd, "path.zsh") || len(got[0].Claims) != 2 {
+		t.Fatalf("FindCollisions() = %v, want path.zsh claimed by zsh and work", got)
+	}
+	if got[0].Claims[0].Tool != "work" || got[0].Claims[1].Tool != "zsh" {
+		t.Errorf("claims = %+v, want work and zsh in order", got[0].Claims)
+	}
+
+	// A whole-directory link collides with files merged beneath it
+	whole := &ToolConfig{Name: "whole", Links: []ResolvedLink{{Source: filepath.Join(root, "extra", "conf.d"), Target: confd, IsDir: true}}}
+	got = FindCollisions([]*ToolConfig{contents("zsh"), whole})
+	if len(got) != 1 || got[0].Target != confd || !got[0].Involves(map[string]bool{"zsh": true}) {
+		t.Errorf("FindCollisions() = %v, want the directory target claimed by whole and zsh", got)
+	}
+}
@@ -1,12 +1,15 @@
 package symlink
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
 	"time"
 
 	"github.com/ildx/merlin/internal/backup"
+	"github.com/ildx/merlin/internal/preview"
+	"github.com/ildx/merlin/internal/protect"
 )
 
 // ConflictStrategy defines how to handle conflicts
@@ -56,13 +59,18 @@ func ParseStrategy(s string) (ConflictStrategy, error) {
 
 // ResolveConflict handles a conflict based on the strategy
 func ResolveConflict(source, target string, strategy ConflictStrategy, dryRun bool) (*LinkResult, error) {
+	return resolveConflict("", source, target, strategy, dryRun)
+}
+
+// resolveConflict is ResolveConflict with backups attributed to tool
+func resolveConflict(tool, source, target string, strategy ConflictStrategy, dryRun bool) (*LinkResult, error) {
 	result := &LinkResult{
 		Source: source,
 		Target: target,
 	}
 
 	// Check if source exists
-	sourceInfo, err := os.Lstat(source)
+	sourceInfo, err := fsys.Lstat(source)
 	if err != nil {
 		result.Status = LinkStatusError
 		result.Message = fmt.Sprintf("source does not exist: %v", err)
@@ -71,7 +79,7 @@ func ResolveConflict(source, target string, strategy ConflictStrategy, dryRun bo
 	result.IsDir = sourceInfo.IsDir()
 
 	// Check if target exists
-	targetInfo, err := os.Lstat(target)
+	targetInfo, err := fsys.Lstat(target)
 	if err != nil {
 		if os.IsNotExist(err) {
 			// No conflict - create symlink normally
@@ -84,7 +92,7 @@ func ResolveConflict(source, target string, strategy ConflictStrategy, dryRun bo
 
 	// Check if target is already correctly linked
 	if targetInfo.Mode()&os.ModeSymlink != 0 {
-		linkDest, err := os.Readlink(target)
+		linkDest, err := fsys.Readlink(target)
 		if err == nil {
 			absLinkDest := linkDest
 			if !filepath.IsAbs(linkDest) {
@@ -101,6 +109,11 @@ func ResolveConflict(source, target string, strategy ConflictStrategy, dryRun bo
 		}
 	}
 
+	// Protected paths are refused whatever the strategy
+	if err := refuseProtected(result, target); err != nil {
+		return result, err
+	}
+
 	// Handle conflict based on strategy
 	switch strategy {
 	case StrategySkip:
@@ -112,11 +125,12 @@ func ResolveConflict(source, target string, strategy ConflictStrategy, dryRun bo
 		if dryRun {
 			result.Status = LinkStatusSuccess
 			result.Message = "would backup and link (dry-run)"
+			result.Preview = replacePreview(source, target, result.IsDir)
 			return result, nil
 		}
 
 		// Create backup using backup system
-		manifest, err := backup.CreateBackup([]string{target}, fmt.Sprintf("Before linking %s", source))
+		manifest, err := backup.CreateToolBackup([]string{target}, fmt.Sprintf("Before linking %s", source), tool)
 		if err != nil {
 			result.Status = LinkStatusError
 			result.Message = fmt.Sprintf("failed to backup: %v", err)
@@ -124,43 +138,47 @@ func ResolveConflict(source, target string, strategy ConflictStrategy, dryRun bo
 		}
 
 		// Remove existing file/directory now that it's backed up
-		if err := os.RemoveAll(target); err != nil {
+		if err := fsys.RemoveAll(target); err != nil {
 			result.Status = LinkStatusError
 			result.Message = fmt.Sprintf("failed to remove after backup: %v", err)
 			return result, fmt.Errorf("failed to remove: %w", err)
 		}
 
 		// Create symlink
-		if err := os.Symlink(source, target); err != nil {
+		if err := fsys.Symlink(source, target); err != nil {
 			// Try to restore from backup
 			backup.RestoreBackup(manifest.ID, []string{target})
 			result.Status = LinkStatusError
 			result.Message = fmt.Sprintf("failed to create symlink: %v", err)
+			markPermissionDenied(result, err)
 			return result, fmt.Errorf("failed to create symlink: %w", err)
 		}
 
 		result.Status = LinkStatusSuccess
 		result.Message = fmt.Sprintf("backed up (ID: %s) and linked", manifest.ID)
+		result.BackupID = manifest.ID
 		return result, nil
 
 	case StrategyOverwrite:
 		if dryRun {
 			result.Status = LinkStatusSuccess
 			result.Message = "would overwrite and link (dry-run)"
+			result.Preview = replacePreview(source, target, result.IsDir)
 			return result, nil
 		}
 
 		// Remove existing file/directory
-		if err := os.RemoveAll(target); err != nil {
+		if err := fsys.RemoveAll(target); err != nil {
 			result.Status = LinkStatusError
 			result.Message = fmt.Sprintf("failed to remove: %v", err)
 			return result, fmt.Errorf("failed to remove: %w", err)
 		}
 
 		// Create symlink
-		if err := os.Symlink(source, target); err != nil {
+		if err := fsys.Symlink(source, target); err != nil {
 			result.Status = LinkStatusError
 			result.Message = fmt.Sprintf("failed to create symlink: %v", err)
+			markPermissionDenied(result, err)
 			return result, fmt.Errorf("failed to create symlink: %w", err)
 		}
 
@@ -181,6 +199,19 @@ func ResolveConflict(source, target string, strategy ConflictStrategy, dryRun bo
 	}
 }
 
+// replacePreview shows how the content at target changes once it becomes a
+// link to source. Directory sources aren't diffed file by file.
+func replacePreview(source, target string, isDir bool) string {
+	if isDir {
+		return fmt.Sprintf("%s would be replaced by a link to directory %s\n", target, source)
+	}
+	diff, err := preview.File(target, source)
+	if err != nil {
+		return ""
+	}
+	return diff
+}
+
 // generateBackupPath generates a backup filename with timestamp
 func generateBackupPath(path string) string {
 	timestamp := time.Now().Format("20060102_150405")
@@ -189,19 +220,94 @@ func generateBackupPath(path string) string {
 	return filepath.Join(dir, fmt.Sprintf("%s.backup_%s", base, timestamp))
 }
 
-// LinkToolWithStrategy links all configured links for a tool with conflict resolution
+// LinkToolWithStrategy links all configured links for a tool with conflict resolution.
+// Links are resolved concurrently except with StrategyBackup, whose backups are
+// keyed by timestamp and must not be created in parallel. Results keep link order.
 func LinkToolWithStrategy(tool *ToolConfig, strategy ConflictStrategy, dryRun bool) ([]*LinkResult, error) {
-	var allResults []*LinkResult
+	links := ExpandLinks(tool.Links)
+
+	workers := linkWorkers
+	if strategy == StrategyBackup && !dryRun {
+		workers = 1
+	}
 
-	for _, link := range tool.Links {
-		result, err := ResolveConflict(link.Source, link.Target, strategy, dryRun)
-		allResults = append(allResults, result)
+	// Errors are carried in each result; one failed link doesn't stop the others
+	return runOrderedLinks(links, workers, func(link ResolvedLink) *LinkResult {
+		result, _ := resolveConflict(tool.Name, link.Source, link.Target, strategy, dryRun)
+		return result
+	}), nil
+}
 
-		// Continue with other links even if one fails
-		if err != nil && result.Status == LinkStatusError {
-			continue
+// Conflict is a link target occupied by something other than the link
+// merlin wants there
+type Conflict struct {
+	Tool      string `json:"tool"`
+	Source    string `json:"source"` // What merlin would link
+	Target    string `json:"target"`
+	Existing  string `json:"existing"`            // "file", "directory", "symlink", "broken symlink" or "other"
+	PointsTo  string `json:"points_to,omitempty"` // Destination of an existing symlink
+	Files     int    `json:"files,omitempty"`     // Regular files under an existing directory
+	Identical bool   `json:"identical,omitempty"` // Existing file has the source's content
+	Protected bool   `json:"protected,omitempty"` // Listed in protected_paths; linking will refuse it
+}
+
+// CountLinks returns how many of a tool's expanded links exist already and
+// how many targets are occupied by something else, as shown by the batch
+// review of link --all and unlink --all
+func CountLinks(tool *ToolConfig) (total, linked, conflicts int) {
+	links := ExpandLinks(tool.Links)
+	for _, link := range links {
+		if ok, _ := IsLinked(link.Source, link.Target); ok {
+			linked++
+		}
+	}
+	return len(links), linked, len(FindConflicts([]*ToolConfig{tool}))
+}
+
+// FindConflicts reports the targets of the tools' links that are occupied:
+// anything at a target other than a symlink to its source. Nothing is changed.
+func FindConflicts(tools []*ToolConfig) []Conflict {
+	var conflicts []Conflict
+	for _, tool := range tools {
+		for _, link := range ExpandLinks(tool.Links) {
+			info, err := fsys.Lstat(link.Target)
+			if err != nil {
+				continue
+			}
+			if linked, _ := IsLinked(link.Source, link.Target); linked {
+				continue
+			}
+
+			c := Conflict{Tool: tool.Name, Source: link.Source, Target: link.Target, Protected: protect.Check(link.Target) != nil}
+			switch {
+			case info.Mode()&os.ModeSymlink != 0:
+				c.Existing = "symlink"
+				c.PointsTo, _ = fsys.Readlink(link.Target)
+				if _, err := fsys.Stat(link.Target); err != nil {
+					c.Existing = "broken symlink"
+				}
+			case info.IsDir():
+				c.Existing = "directory"
+				files, _ := filesAt(link.Target)
+				c.Files = len(files)
+			case info.Mode().IsRegular():
+				c.Existing = "file"
+				c.Identical = sameContent(link.Source, link.Target)
+			default:
+				c.Existing = "other"
+			}
+			conflicts = append(conflicts, c)
 		}
 	}
+	return conflicts
+}
 
-	return allResults, nil
+// sameContent reports whether two regular files hold the same bytes
+func sameContent(a, b string) bool {
+	dataA, err := fsys.ReadFile(a)
+	if err != nil {
+		return false
+	}
+	dataB, err := fsys.ReadFile(b)
+	return err == nil && bytes.Equal(dataA, dataB)
 }
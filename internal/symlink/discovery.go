@@ -13,49 +13,118 @@ import (
 
 // ToolConfig represents a tool's symlink configuration
 type ToolConfig struct {
-	Name         string
-	Description  string
-	ToolRoot     string // Absolute path to config/TOOL/
-	ConfigDir    string // Absolute path to config/TOOL/config/
-	Links        []ResolvedLink
-	Dependencies []string
-	HasMerlinTOML bool
+	Name          string              `json:"name"`
+	Description   string              `json:"description,omitempty"`
+	ToolRoot      string              `json:"tool_root"`  // Absolute path to config/TOOL/
+	ConfigDir     string              `json:"config_dir"` // Absolute path to config/TOOL/config/
+	Links         []ResolvedLink      `json:"links"`
+	Dependencies  []string            `json:"dependencies,omitempty"`
+	HasMerlinTOML bool                `json:"has_merlin_toml"`
+	Disabled      bool                `json:"disabled,omitempty"` // enabled = false in merlin.toml
+	Notes         string              `json:"notes,omitempty"`    // [tool] notes
+	Readme        string              `json:"readme,omitempty"`   // Absolute path to the tool's README.md, if any
+	ManualSteps   []models.ManualStep `json:"manual_steps,omitempty"`
+	Permissions   []string            `json:"permissions,omitempty"` // [tool] permissions
+	Command       string              `json:"command,omitempty"`     // [tool] command
+}
+
+// ToolReadmeName is the optional file in a tool's directory describing it;
+// see ToolConfig.Readme
+const ToolReadmeName = "README.md"
+
+func isRegularFile(path string) bool {
+	info, err := fsys.Stat(path)
+	return err == nil && info.Mode().IsRegular()
 }
 
 // ResolvedLink represents a fully resolved symlink with expanded variables
 type ResolvedLink struct {
-	Source string // Absolute source path
-	Target string // Absolute target path
-	IsDir  bool   // True if source is a directory
+	Source        string            `json:"source"`                   // Absolute source path
+	Target        string            `json:"target"`                   // Absolute target path
+	IsDir         bool              `json:"is_dir"`                   // True if source is a directory
+	Contents      bool              `json:"contents,omitempty"`       // Link directory contents file-by-file instead of the directory
+	IncludeHidden bool              `json:"include_hidden,omitempty"` // Contents mode: include dotfiles
+	Rename        map[string]string `json:"rename,omitempty"`         // Contents mode: target renames (see WalkOptions)
+	DotPrefix     bool              `json:"dot_prefix,omitempty"`     // Contents mode: dot top-level entries
+	Launchd       bool              `json:"launchd,omitempty"`        // Load/unload .plist targets with launchctl
+	Executable    bool              `json:"executable,omitempty"`     // [[bin]] entry: source is made executable when linked
+	Order         int               `json:"order,omitempty"`          // [[link]] order; links are sorted by it (see sortLinks)
+}
+
+// walkOptions returns the contents-mode options of the link
+func (l ResolvedLink) walkOptions() WalkOptions {
+	return WalkOptions{IncludeHidden: l.IncludeHidden, Rename: l.Rename, DotPrefix: l.DotPrefix}
 }
 
 // Variables holds the variable values for expansion
 type Variables struct {
 	HomeDir   string
 	ConfigDir string
+	BinDir    string // empty means {home_dir}/bin
+
+	// RequireMerlinTOML (settings.require_merlin_toml = true) drops the
+	// implicit config/ → {config_dir}/TOOL link of tools without a merlin.toml
+	RequireMerlinTOML bool
 }
 
-// DiscoverTools discovers all tools in the dotfiles repository
+// DiscoverTools discovers all enabled tools in the dotfiles repository
 func DiscoverTools(repo *config.DotfilesRepo, vars Variables) ([]*ToolConfig, error) {
 	tools, err := repo.ListTools()
 	if err != nil {
 		return nil, fmt.Errorf("failed to list tools: %w", err)
 	}
 
+	// Unchanged tools come from the persisted index; the rest are discovered
+	// and re-indexed. Index errors never fail discovery.
+	index := loadToolIndex()
+	changed := index.prune(repo.ConfigDir, tools)
+
 	toolConfigs := make([]*ToolConfig, 0, len(tools))
 	
 	for _, toolName := range tools {
-		toolConfig, err := DiscoverToolConfig(repo, toolName, vars)
-		if err != nil {
-			// Skip tools that can't be discovered
+		toolRoot := repo.GetToolRoot(toolName)
+		toolConfig, ok := index.lookup(toolRoot, vars)
+		if !ok {
+			stamps := toolStamps(repo, toolName)
+			toolConfig, err = DiscoverToolConfig(repo, toolName, vars)
+			if err != nil {
+				// Skip tools that can't be discovered
+				delete(index.Tools, toolRoot)
+				continue
+			}
+			index.Tools[toolRoot] = indexedTool{HomeDir: vars.HomeDir, ConfigDir: vars.ConfigDir, BinDir: vars.BinDir, RequireMerlinTOML: vars.RequireMerlinTOML, XDG: XDGDirs(vars.HomeDir), Stamps: stamps, Config: toolConfig}
+			changed = true
+		}
+		if toolConfig.Disabled {
 			continue
 		}
 		toolConfigs = append(toolConfigs, toolConfig)
 	}
 
+	if changed {
+		index.save()
+	}
+
 	return toolConfigs, nil
 }
 
+// DisabledTools returns the names of tools whose merlin.toml sets enabled = false
+func DisabledTools(repo *config.DotfilesRepo) []string {
+	tools, err := repo.ListTools()
+	if err != nil {
+		return nil
+	}
+
+	var disabled []string
+	for _, toolName := range tools {
+		merlinConfig, err := parser.ParseToolMerlinTOML(repo.GetToolMerlinConfig(toolName))
+		if err == nil && !merlinConfig.IsEnabled() {
+			disabled = append(disabled, toolName)
+		}
+	}
+	return disabled
+}
+
 // DiscoverToolConfig discovers configuration for a single tool
 func DiscoverToolConfig(repo *config.DotfilesRepo, toolName string, vars Variables) (*ToolConfig, error) {
 	toolRoot := repo.GetToolRoot(toolName)
@@ -81,6 +150,11 @@ func DiscoverToolConfig(repo *config.DotfilesRepo, toolName string, vars Variabl
 
 		toolConfig.Description = merlinConfig.Tool.Description
 		toolConfig.Dependencies = merlinConfig.Tool.Dependencies
+		toolConfig.Disabled = !merlinConfig.IsEnabled()
+		toolConfig.Notes = merlinConfig.Tool.Notes
+		toolConfig.ManualSteps = merlinConfig.ManualSteps
+		toolConfig.Permissions = merlinConfig.Tool.Permissions
+		toolConfig.Command = merlinConfig.Tool.Command
 
 		// Process links
 		for _, link := range merlinConfig.Links {
@@ -88,14 +162,25 @@ func DiscoverToolConfig(repo *config.DotfilesRepo, toolName string, vars Variabl
 			if err != nil {
 				return nil, fmt.Errorf("failed to resolve link for %s: %w", toolName, err)
 			}
+			for i := range resolvedLinks {
+				resolvedLinks[i].Order = link.Order
+			}
 			toolConfig.Links = append(toolConfig.Links, resolvedLinks...)
 		}
-	} else {
+		for _, bin := range merlinConfig.Bins {
+			resolvedLinks, err := resolveBin(bin, toolRoot, vars)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve bin for %s: %w", toolName, err)
+			}
+			toolConfig.Links = append(toolConfig.Links, resolvedLinks...)
+		}
+		sortLinks(toolConfig.Links)
+	} else if !vars.RequireMerlinTOML {
 		// Use default: config/ → ~/.config/TOOL/
 		defaultTarget := filepath.Join(vars.ConfigDir, toolName)
 		
 		// Check if config directory exists
-		if info, err := os.Stat(configDir); err == nil && info.IsDir() {
+		if info, err := fsys.Stat(configDir); err == nil && info.IsDir() {
 			toolConfig.Links = []ResolvedLink{
 				{
 					Source: configDir,
@@ -106,6 +191,10 @@ func DiscoverToolConfig(repo *config.DotfilesRepo, toolName string, vars Variabl
 		}
 	}
 
+	if readme := filepath.Join(toolRoot, ToolReadmeName); isRegularFile(readme) {
+		toolConfig.Readme = readme
+	}
+
 	return toolConfig, nil
 }
 
@@ -119,26 +208,49 @@ func resolveLink(link models.Link, toolRoot, configDir string, vars Variables) (
 	// If there are specific files, handle them
 	if len(link.Files) > 0 {
 		for _, file := range link.Files {
+			if IsGlob(file.Source) {
+				// Glob: every match goes under the entry's target directory
+				matched, err := globLinks(toolRoot, file.Source, filepath.Join(target, file.Target), link.Launchd)
+				if err != nil {
+					return nil, err
+				}
+				results = append(results, matched...)
+				continue
+			}
+
 			// Source is relative to tool root
 			source := filepath.Join(toolRoot, file.Source)
 			// Target is relative to the link target
 			fileTarget := filepath.Join(target, file.Target)
 
-			// Check if source exists
-			info, err := os.Stat(source)
+			// A missing source fails the link unless it is optional
+			info, err := fsys.Stat(source)
 			if err != nil {
-				continue // Skip non-existent sources
+				if link.Optional.Skippable() {
+					continue
+				}
+				return nil, fmt.Errorf("source does not exist: %s (set optional = true if it may be absent)", source)
 			}
 
 			results = append(results, ResolvedLink{
-				Source: source,
-				Target: fileTarget,
-				IsDir:  info.IsDir(),
+				Source:        source,
+				Target:        fileTarget,
+				IsDir:         info.IsDir(),
+				Contents:      link.Contents && info.IsDir(),
+				IncludeHidden: link.IncludeHidden,
+				Rename:        link.Rename,
+				DotPrefix:     link.DotPrefix,
+				Launchd:       link.Launchd,
 			})
 		}
 		return results, nil
 	}
 
+	// A glob source links each matching file under the target directory
+	if IsGlob(link.Source) {
+		return globLinks(toolRoot, link.Source, target, link.Launchd)
+	}
+
 	// Determine source
 	var source string
 	if link.Source != "" {
@@ -149,25 +261,52 @@ func resolveLink(link models.Link, toolRoot, configDir string, vars Variables) (
 		source = configDir
 	}
 
-	// Check if source exists
-	info, err := os.Stat(source)
+	// A missing source fails the link unless it is optional
+	info, err := fsys.Stat(source)
 	if err != nil {
-		return nil, fmt.Errorf("source does not exist: %s", source)
+		if link.Optional.Skippable() {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("source does not exist: %s (set optional = true if it may be absent)", source)
 	}
 
 	results = append(results, ResolvedLink{
-		Source: source,
-		Target: target,
-		IsDir:  info.IsDir(),
+		Source:        source,
+		Target:        target,
+		IsDir:         info.IsDir(),
+		Contents:      link.Contents && info.IsDir(),
+		IncludeHidden: link.IncludeHidden,
+		Rename:        link.Rename,
+		DotPrefix:     link.DotPrefix,
+		Launchd:       link.Launchd,
 	})
 
 	return results, nil
 }
 
+// globLinks resolves a glob source into one file link per match under targetDir
+func globLinks(toolRoot, pattern, targetDir string, launchd bool) ([]ResolvedLink, error) {
+	matches, err := ExpandGlob(toolRoot, pattern)
+	if err != nil {
+		return nil, fmt.Errorf("expand %s: %w", pattern, err)
+	}
+	links := make([]ResolvedLink, 0, len(matches))
+	for _, m := range matches {
+		links = append(links, ResolvedLink{Source: m.Path, Target: filepath.Join(targetDir, m.Rel), Launchd: launchd})
+	}
+	return links, nil
+}
+
 // expandVariables expands {var} patterns in a string
 func expandVariables(s string, vars Variables) string {
 	s = strings.ReplaceAll(s, "{home_dir}", vars.HomeDir)
 	s = strings.ReplaceAll(s, "{config_dir}", vars.ConfigDir)
+	s = strings.ReplaceAll(s, "{bin_dir}", vars.binDir())
+	for variable, dir := range XDGDirs(vars.HomeDir) {
+		s = strings.ReplaceAll(s, variable, dir)
+	}
+	s = strings.ReplaceAll(s, "{app_support}", filepath.Join(vars.HomeDir, "Library", "Application Support"))
+	s = strings.ReplaceAll(s, "{launch_agents}", filepath.Join(vars.HomeDir, "Library", "LaunchAgents"))
 	
 	// Handle ~ expansion
 	if strings.HasPrefix(s, "~/") {
@@ -208,6 +347,10 @@ func GetVariablesFromRoot(rootConfig *models.RootMerlinConfig) (Variables, error
 	if rootConfig.Settings.ConfigDir != "" {
 		vars.ConfigDir = expandVariables(rootConfig.Settings.ConfigDir, vars)
 	}
+	if rootConfig.Settings.BinDir != "" {
+		vars.BinDir = expandVariables(rootConfig.Settings.BinDir, vars)
+	}
+	vars.RequireMerlinTOML = rootConfig.Settings.RequireMerlinTOML.Enforced()
 
 	return vars, nil
 }
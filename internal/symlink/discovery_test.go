@@ -1,8 +1,11 @@
 package symlink
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
 
 	"github.com/ildx/merlin/internal/config"
@@ -10,6 +13,10 @@ import (
 )
 
 func TestExpandVariables(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("XDG_DATA_HOME", "/data")
+	t.Setenv("XDG_CACHE_HOME", "relative/cache") // ignored: must be absolute
+	t.Setenv("XDG_STATE_HOME", "")
 	vars := Variables{
 		HomeDir:   "/Users/test",
 		ConfigDir: "/Users/test/.config",
@@ -30,6 +37,36 @@ func TestExpandVariables(t *testing.T) {
 			input: "{config_dir}/git",
 			want:  "/Users/test/.config/git",
 		},
+		{
+			name:  "app_support variable",
+			input: "{app_support}/Code/User",
+			want:  "/Users/test/Library/Application Support/Code/User",
+		},
+		{
+			name:  "launch_agents variable",
+			input: "{launch_agents}/com.example.agent.plist",
+			want:  "/Users/test/Library/LaunchAgents/com.example.agent.plist",
+		},
+		{
+			name:  "xdg_config default",
+			input: "{xdg_config}/nvim",
+			want:  "/Users/test/.config/nvim",
+		},
+		{
+			name:  "xdg_data override",
+			input: "{xdg_data}/fonts",
+			want:  "/data/fonts",
+		},
+		{
+			name:  "xdg_cache ignores relative override",
+			input: "{xdg_cache}/zsh",
+			want:  "/Users/test/.cache/zsh",
+		},
+		{
+			name:  "xdg_state default",
+			input: "{xdg_state}/lazygit",
+			want:  "/Users/test/.local/state/lazygit",
+		},
 		{
 			name:  "tilde expansion",
 			input: "~/.bashrc",
@@ -199,6 +236,87 @@ func TestResolveLink(t *testing.T) {
 			t.Errorf("Target = %v, want %v", results[0].Target, expectedTarget)
 		}
 	})
+
+	t.Run("missing source", func(t *testing.T) {
+		missing := models.Link{Source: "config/work.conf", Target: "{home_dir}/work.conf"}
+		if _, err := resolveLink(missing, toolRoot, configDir, vars); err == nil {
+			t.Error("expected an error for a missing required source")
+		}
+
+		missing.Optional = models.OptionalYes
+		if results, err := resolveLink(missing, toolRoot, configDir, vars); err != nil || len(results) != 0 {
+			t.Errorf("optional missing source: got %v, %v; want no links", results, err)
+		}
+
+		files := models.Link{Target: "{home_dir}", Files: []models.FileLink{{Source: "config/test.conf", Target: "a"}, {Source: "config/work.conf", Target: "b"}}}
+		if _, err := resolveLink(files, toolRoot, configDir, vars); err == nil {
+			t.Error("expected an error for a missing required files entry")
+		}
+		files.Optional = models.OptionalWarn
+		if results, err := resolveLink(files, toolRoot, configDir, vars); err != nil || len(results) != 1 {
+			t.Errorf("optional files entry: got %v, %v; want only test.conf", results, err)
+		}
+	})
+}
+
+func TestExpandGlob(t *testing.T) {
+	root := t.TempDir()
+	for _, rel := range []string{
+		"config/aliases.zsh", "config/env.zsh", "config/zshrc", "config/.hidden.zsh",
+		"config/lua/init.lua", "config/lua/plugins/telescope.lua", "config/lua/plugins/readme.md",
+		"config/.git/hooks.lua", "config/.cache/x.lua",
+	} {
+		path := filepath.Join(root, rel)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, nil, 0644)
+	}
+
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{"config/*.zsh", []string{"aliases.zsh", "env.zsh"}},
+		{"config/.*.zsh", []string{".hidden.zsh"}},
+		{"config/**/*.lua", []string{"lua/init.lua", "lua/plugins/telescope.lua"}},
+		{"config/lua/*/*", []string{"plugins/readme.md", "plugins/telescope.lua"}},
+		{"config/lua/**", []string{"init.lua", "plugins/readme.md", "plugins/telescope.lua"}},
+		{"config/env.z?h", []string{"env.zsh"}},
+		{"missing/*.zsh", nil},
+	}
+	for _, tt := range tests {
+		matches, err := ExpandGlob(root, tt.pattern)
+		if err != nil {
+			t.Fatalf("ExpandGlob(%q) error = %v", tt.pattern, err)
+		}
+		var got []string
+		for _, m := range matches {
+			got = append(got, filepath.ToSlash(m.Rel))
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("ExpandGlob(%q) = %v, want %v", tt.pattern, got, tt.want)
+		}
+	}
+
+	t.Run("resolveLink", func(t *testing.T) {
+		vars := Variables{HomeDir: "/Users/test", ConfigDir: "/Users/test/.config"}
+		link := models.Link{Source: "config/**/*.lua", Target: "{config_dir}/nvim"}
+		results, err := resolveLink(link, root, filepath.Join(root, "config"), vars)
+		if err != nil {
+			t.Fatalf("resolveLink() error = %v", err)
+		}
+		if len(results) != 2 || results[1].Target != "/Users/test/.config/nvim/lua/plugins/telescope.lua" {
+			t.Errorf("unexpected glob links: %+v", results)
+		}
+
+		link = models.Link{Target: "{home_dir}", Files: []models.FileLink{{Source: "config/*.zsh", Target: ".zsh"}}}
+		results, err = resolveLink(link, root, filepath.Join(root, "config"), vars)
+		if err != nil {
+			t.Fatalf("resolveLink() error = %v", err)
+		}
+		if len(results) != 2 || results[0].Target != "/Users/test/.zsh/aliases.zsh" {
+			t.Errorf("unexpected files glob links: %+v", results)
+		}
+	})
 }
 
 // Test with real Covenant repository if available
@@ -322,3 +440,270 @@ func TestDiscoverToolConfig(t *testing.T) {
 	})
 }
 
+
+func TestDiscoverToolsSkipsDisabled(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	root := t.TempDir()
+	for _, tool := range []string{"git", "zsh"} {
+		if err := os.MkdirAll(filepath.Join(root, "config", tool, "config"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	toml := "[tool]\nname = \"zsh\"\nenabled = false\n"
+	if err := os.WriteFile(filepath.Join(root, "config", "zsh", "merlin.toml"), []byte(toml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	repo := &config.DotfilesRepo{Root: root, ConfigDir: filepath.Join(root, "config")}
+	vars := Variables{HomeDir: t.TempDir(), ConfigDir: t.TempDir()}
+
+	tools, err := DiscoverTools(repo, vars)
+	if err != nil {
+		t.Fatalf("DiscoverTools() error = %v", err)
+	}
+	if len(tools) != 1 || tools[0].Name != "git" {
+		t.Errorf("expected only git, got %v", tools)
+	}
+
+	if disabled := DisabledTools(repo); len(disabled) != 1 || disabled[0] != "zsh" {
+		t.Errorf("DisabledTools() = %v, want [zsh]", disabled)
+	}
+
+	// A single disabled tool is still discoverable (for unlink) but flagged
+	tool, err := DiscoverToolConfig(repo, "zsh", vars)
+	if err != nil {
+		t.Fatalf("DiscoverToolConfig() error = %v", err)
+	}
+	if !tool.Disabled {
+		t.Error("expected zsh to be flagged as disabled")
+	}
+}
+
+func TestDiscoverToolsRequireMerlinTOML(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "config", "zsh", "config"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	repo := &config.DotfilesRepo{Root: root, ConfigDir: filepath.Join(root, "config")}
+	vars := Variables{HomeDir: "/home/u", ConfigDir: "/home/u/.config"}
+
+	tools, err := DiscoverTools(repo, vars)
+	if err != nil || len(tools) != 1 || len(tools[0].Links) != 1 {
+		t.Fatalf("expected the implicit link, got %v, %v", tools, err)
+	}
+
+	// The index must not hand back the config resolved without the setting
+	vars.RequireMerlinTOML = true
+	tools, err = DiscoverTools(repo, vars)
+	if err != nil {
+		t.Fatalf("DiscoverTools() error = %v", err)
+	}
+	if len(tools) != 1 || tools[0].Name != "zsh" || len(tools[0].Links) != 0 {
+		t.Errorf("expected zsh listed without links, got %+v", tools)
+	}
+}
+
+func TestDiscoverToolNotesAndReadme(t *testing.T) {
+	root := t.TempDir()
+	for _, tool := range []string{"git", "zsh"} {
+		if err := os.MkdirAll(filepath.Join(root, "config", tool, "config"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	toml := "[tool]\nname = \"zsh\"\nnotes = \"Run compinit once after linking\"\n"
+	if err := os.WriteFile(filepath.Join(root, "config", "zsh", "merlin.toml"), []byte(toml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	readme := filepath.Join(root, "config", "zsh", ToolReadmeName)
+	if err := os.WriteFile(readme, []byte("# zsh\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	repo := &config.DotfilesRepo{Root: root, ConfigDir: filepath.Join(root, "config")}
+	vars := Variables{HomeDir: t.TempDir(), ConfigDir: t.TempDir()}
+
+	tool, err := DiscoverToolConfig(repo, "zsh", vars)
+	if err != nil {
+		t.Fatalf("DiscoverToolConfig() error = %v", err)
+	}
+	if tool.Notes != "Run compinit once after linking" || tool.Readme != readme {
+		t.Errorf("Notes = %q, Readme = %q", tool.Notes, tool.Readme)
+	}
+
+	tool, err = DiscoverToolConfig(repo, "git", vars)
+	if err != nil {
+		t.Fatalf("DiscoverToolConfig() error = %v", err)
+	}
+	if tool.Notes != "" || tool.Readme != "" {
+		t.Errorf("git: Notes = %q, Readme = %q, want none", tool.Notes, tool.Readme)
+	}
+}
+
+func TestDiscoverToolsIndex(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	root := t.TempDir()
+	writeFile := func(rel, content string) {
+		t.Helper()
+		path := filepath.Join(root, "config", rel)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("git/gitconfig", "[user]")
+	writeFile("git/merlin.toml", "[tool]\nname = \"git\"\n\n[[link]]\ntarget = \"{home_dir}\"\noptional = true\nfiles = [{ source = \"gitconfig\", target = \".gitconfig\" }, { source = \"gitignore\", target = \".gitignore\" }]\n")
+	writeFile("zsh/config/.zshrc", "")
+
+	repo := &config.DotfilesRepo{Root: root, ConfigDir: filepath.Join(root, "config")}
+	vars := Variables{HomeDir: "/home/u", ConfigDir: "/home/u/.config"}
+	gitRoot := repo.GetToolRoot("git")
+
+	discover := func() map[string]*ToolConfig {
+		t.Helper()
+		tools, err := DiscoverTools(repo, vars)
+		if err != nil {
+			t.Fatalf("DiscoverTools() error = %v", err)
+		}
+		byName := map[string]*ToolConfig{}
+		for _, tool := range tools {
+			byName[tool.Name] = tool
+		}
+		return byName
+	}
+
+	first := discover()
+	if len(first) != 2 || len(first["git"].Links) != 1 {
+		t.Fatalf("unexpected discovery: %+v", first)
+	}
+	index := loadToolIndex()
+	if _, ok := index.lookup(gitRoot, vars); !ok {
+		t.Fatal("expected git to be indexed")
+	}
+	if _, ok := index.lookup(gitRoot, Variables{HomeDir: "/other"}); ok {
+		t.Error("index entry must not match different variables")
+	}
+
+	// A source that appears later invalidates only that tool
+	zshEntry := index.Tools[repo.GetToolRoot("zsh")]
+	writeFile("git/gitignore", "*.swp")
+	if _, ok := loadToolIndex().lookup(gitRoot, vars); ok {
+		t.Error("expected git entry to be stale after adding a declared source")
+	}
+	if second := discover(); len(second["git"].Links) != 2 {
+		t.Errorf("expected gitignore to be linked after re-discovery, got %+v", second["git"].Links)
+	}
+	if after := loadToolIndex().Tools[repo.GetToolRoot("zsh")]; after.Stamps[repo.GetToolRoot("zsh")] != zshEntry.Stamps[repo.GetToolRoot("zsh")] {
+		t.Error("unchanged tool should keep its entry")
+	}
+
+	// Removed tools are pruned
+	os.RemoveAll(repo.GetToolRoot("zsh"))
+	if third := discover(); len(third) != 1 {
+		t.Errorf("expected only git, got %+v", third)
+	}
+	if _, ok := loadToolIndex().Tools[repo.GetToolRoot("zsh")]; ok {
+		t.Error("expected zsh entry to be pruned")
+	}
+}
+
+func TestSuggestXDG(t *testing.T) {
+	tests := map[string]string{
+		"~/.config/nvim":             "{xdg_config}/nvim",
+		"{home_dir}/.config":         "{xdg_config}",
+		"$HOME/.local/share/fonts":   "{xdg_data}/fonts",
+		"~/.cache/zsh":               "{xdg_cache}/zsh",
+		"~/.local/state/lazygit":     "{xdg_state}/lazygit",
+		"~/.configs/nvim":            "",
+		"{config_dir}/nvim":          "",
+		"/etc/.config/nvim":          "",
+		"{home_dir}/.local/bin/tool": "",
+	}
+	for target, want := range tests {
+		got, ok := SuggestXDG(target)
+		if ok != (want != "") || got != want {
+			t.Errorf("SuggestXDG(%q) = %q, %v; want %q", target, got, ok, want)
+		}
+	}
+}
+
+func TestSortByDependencies(t *testing.T) {
+	tool := func(name string, deps ...string) *ToolConfig {
+		return &ToolConfig{Name: name, Dependencies: deps}
+	}
+	names := func(tools []*ToolConfig) string {
+		var s []string
+		for _, tool := range tools {
+			s = append(s, tool.Name)
+		}
+		return strings.Join(s, ",")
+	}
+
+	sorted, err := SortByDependencies([]*ToolConfig{
+		tool("alacritty", "fonts"),
+		tool("fonts", "brew"),
+		tool("git"),
+		tool("zsh", "git", "starship"),
+		tool("starship", "fonts"),
+	})
+	if err != nil {
+		t.Fatalf("SortByDependencies() error = %v", err)
+	}
+	if got := names(sorted); got != "fonts,alacritty,git,starship,zsh" {
+		t.Errorf("SortByDependencies() = %s", got)
+	}
+
+	_, err = SortByDependencies([]*ToolConfig{tool("a", "b"), tool("b", "c"), tool("c", "b")})
+	if err == nil || !strings.Contains(err.Error(), "b → c → b") {
+		t.Errorf("expected a cycle error, got %v", err)
+	}
+}
+
+func TestToolGraph(t *testing.T) {
+	tool := func(name string, deps ...string) *ToolConfig {
+		return &ToolConfig{Name: name, Dependencies: deps}
+	}
+	tools := []*ToolConfig{
+		tool("zsh", "git", "starship"),
+		tool("git"),
+		tool("starship", "fonts", "brew"),
+		tool("fonts", "starship"),
+		tool("loop", "loop"),
+		tool("scratch"),
+	}
+	profiles := []models.Profile{{Name: "work", Tools: []string{"zsh", "git", "gone"}}}
+
+	if got := fmt.Sprint(DependencyCycles(tools)); got != "[[fonts starship] [loop]]" {
+		t.Errorf("DependencyCycles() = %s", got)
+	}
+	if got := strings.Join(OrphanTools(tools, profiles), ","); got != "fonts,loop,scratch,starship" {
+		t.Errorf("OrphanTools() = %s", got)
+	}
+
+	g := ToolGraph(tools, profiles)
+	classes := make(map[string]string)
+	for _, n := range g.Nodes {
+		classes[n.ID] = n.Class
+	}
+	if classes["profile:work"] != NodeProfile || classes["zsh"] != NodeTool ||
+		classes["scratch"] != NodeOrphan || classes["gone"] != NodeUnknown {
+		t.Errorf("node classes = %v", classes)
+	}
+	var flagged, dashed []string
+	for _, e := range g.Edges {
+		if e.Flagged {
+			flagged = append(flagged, e.From+"->"+e.To)
+		}
+		if e.Dashed {
+			dashed = append(dashed, e.From+"->"+e.To)
+		}
+	}
+	sort.Strings(flagged)
+	if got := strings.Join(flagged, ","); got != "fonts->starship,loop->loop,starship->fonts" {
+		t.Errorf("flagged edges = %s, want the cycles only", got)
+	}
+	if len(dashed) != 3 {
+		t.Errorf("membership edges = %v, want work to zsh, git and gone", dashed)
+	}
+	if len(g.Edges) != 8 {
+		t.Errorf("edges = %+v, want 3 membership and 5 tool dependencies (brew isn't a tool)", g.Edges)
+	}
+}
@@ -0,0 +1,147 @@
+package symlink
+
+import (
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// GlobMatch is a file matched by a glob link source
+type GlobMatch struct {
+	Path string // Absolute path of the matched file
+	Rel  string // Path relative to the pattern's static prefix, used under the link target
+}
+
+// IsGlob reports whether a link source is a glob pattern
+func IsGlob(source string) bool {
+	return strings.ContainsAny(source, "*?[")
+}
+
+// ExpandGlob resolves a glob source relative to root into the regular files it
+// matches, sorted by path. Segments follow path.Match, and a "**" segment
+// matches any number of directories. As in the shell, wildcards don't match
+// names starting with "." unless the pattern segment does, and VCS metadata
+// is never matched. Rel keeps the directories below the pattern's static
+// prefix, so "config/**/*.lua" maps config/lua/x.lua to lua/x.lua.
+func ExpandGlob(root, pattern string) ([]GlobMatch, error) {
+	base, segments := splitGlob(pattern)
+	baseDir := filepath.Join(root, base)
+
+	var matches []GlobMatch
+	err := walkDir(baseDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == baseDir {
+				return fs.SkipAll // no static prefix directory, no matches
+			}
+			return nil
+		}
+		if p == baseDir {
+			return nil
+		}
+		if alwaysSkip[d.Name()] {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, _ := filepath.Rel(baseDir, p)
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+		if d.IsDir() {
+			if !globPrefixMatches(segments, parts) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Type().IsRegular() && globMatches(segments, parts) {
+			matches = append(matches, GlobMatch{Path: p, Rel: rel})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Path < matches[j].Path })
+	return matches, nil
+}
+
+// GlobDirs returns the directories whose listings determine what pattern
+// matches: the static prefix, plus every directory below it for "**"
+func GlobDirs(root, pattern string) []string {
+	base, segments := splitGlob(pattern)
+	baseDir := filepath.Join(root, base)
+	dirs := []string{baseDir}
+	walkDir(baseDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() || p == baseDir {
+			return nil
+		}
+		rel, _ := filepath.Rel(baseDir, p)
+		if alwaysSkip[d.Name()] || !globPrefixMatches(segments, strings.Split(filepath.ToSlash(rel), "/")) {
+			return filepath.SkipDir
+		}
+		dirs = append(dirs, p)
+		return nil
+	})
+	return dirs
+}
+
+// splitGlob separates the leading segments without wildcards from the rest
+func splitGlob(pattern string) (string, []string) {
+	segments := strings.Split(path.Clean(filepath.ToSlash(pattern)), "/")
+	i := 0
+	for i < len(segments)-1 && !IsGlob(segments[i]) {
+		i++
+	}
+	return filepath.FromSlash(strings.Join(segments[:i], "/")), segments[i:]
+}
+
+// globMatches reports whether path segments match pattern segments exactly
+func globMatches(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+	if pattern[0] == "**" {
+		// A trailing "**" matches every non-hidden file below
+		if len(pattern) == 1 {
+			for _, part := range parts {
+				if strings.HasPrefix(part, ".") {
+					return false
+				}
+			}
+			return len(parts) > 0
+		}
+		// Zero directories, or consume one (non-hidden) directory and retry
+		if globMatches(pattern[1:], parts) {
+			return true
+		}
+		return len(parts) > 1 && !strings.HasPrefix(parts[0], ".") && globMatches(pattern, parts[1:])
+	}
+	return len(parts) > 0 && segmentMatches(pattern[0], parts[0]) && globMatches(pattern[1:], parts[1:])
+}
+
+// globPrefixMatches reports whether a directory's segments could lead to a match
+func globPrefixMatches(pattern, parts []string) bool {
+	for i, part := range parts {
+		if i >= len(pattern) {
+			return false
+		}
+		if pattern[i] == "**" {
+			return !strings.HasPrefix(part, ".") || globPrefixMatches(pattern[i+1:], parts[i:])
+		}
+		// The last pattern segment names files, never directories
+		if i == len(pattern)-1 || !segmentMatches(pattern[i], part) {
+			return false
+		}
+	}
+	return true
+}
+
+func segmentMatches(pattern, name string) bool {
+	if strings.HasPrefix(name, ".") && !strings.HasPrefix(pattern, ".") {
+		return false
+	}
+	ok, _ := path.Match(pattern, name)
+	return ok
+}
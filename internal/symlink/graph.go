@@ -0,0 +1,152 @@
+package symlink
+
+import (
+	"sort"
+
+	"github.com/ildx/merlin/internal/graph"
+	"github.com/ildx/merlin/internal/models"
+)
+
+// Node classes of ToolGraph
+const (
+	NodeProfile = "profile"
+	NodeTool    = "tool"    // in at least one profile
+	NodeOrphan  = "orphan"  // in no profile, so only `merlin link <tool>` or --all links it
+	NodeUnknown = "unknown" // listed in a profile but not an enabled tool
+)
+
+// ToolGraph returns the graph of tools and the tools they list in [tool]
+// dependencies (other dependencies are packages and left out), plus a node
+// per profile pointing at its tools. Dependency edges in a cycle are flagged.
+func ToolGraph(tools []*ToolConfig, profiles []models.Profile) *graph.Graph {
+	g := &graph.Graph{
+		Name:    "tools",
+		Classes: []string{NodeProfile, NodeTool, NodeOrphan, NodeUnknown},
+		Styles: map[string]graph.Style{
+			NodeProfile: {Fill: "#bee3f8", Label: "profiles"},
+			NodeTool:    {Label: "tools"},
+			NodeOrphan:  {Fill: "#fed7d7", Label: "tools in no profile"},
+			NodeUnknown: {Dashed: true, Label: "in a profile, but not an enabled tool"},
+		},
+	}
+
+	isTool := make(map[string]bool, len(tools))
+	for _, tool := range tools {
+		isTool[tool.Name] = true
+	}
+	inProfile := make(map[string]bool)
+	for _, p := range profiles {
+		id := "profile:" + p.Name
+		g.AddLabeledNode(id, p.Name, NodeProfile)
+		for _, name := range p.Tools {
+			inProfile[name] = true
+			if !isTool[name] {
+				g.AddNode(name, NodeUnknown)
+			}
+			g.Edges = append(g.Edges, graph.Edge{From: id, To: name, Dashed: true})
+		}
+	}
+
+	inCycle := make(map[string]int)
+	for i, cycle := range DependencyCycles(tools) {
+		for _, name := range cycle {
+			inCycle[name] = i + 1
+		}
+	}
+	for _, tool := range tools {
+		if inProfile[tool.Name] {
+			g.AddNode(tool.Name, NodeTool)
+		} else {
+			g.AddNode(tool.Name, NodeOrphan)
+		}
+		for _, dep := range tool.Dependencies {
+			if isTool[dep] {
+				cyclic := inCycle[tool.Name] != 0 && inCycle[tool.Name] == inCycle[dep]
+				g.Edges = append(g.Edges, graph.Edge{From: tool.Name, To: dep, Flagged: cyclic})
+			}
+		}
+	}
+	return g
+}
+
+// OrphanTools returns the names of tools in no profile, sorted
+func OrphanTools(tools []*ToolConfig, profiles []models.Profile) []string {
+	inProfile := make(map[string]bool)
+	for _, p := range profiles {
+		for _, name := range p.Tools {
+			inProfile[name] = true
+		}
+	}
+	var orphans []string
+	for _, tool := range tools {
+		if !inProfile[tool.Name] {
+			orphans = append(orphans, tool.Name)
+		}
+	}
+	sort.Strings(orphans)
+	return orphans
+}
+
+// DependencyCycles returns each group of tools that depend on one another
+// through [tool] dependencies, including a tool listing itself, with names
+// sorted within a group. SortByDependencies fails on the first of them.
+func DependencyCycles(tools []*ToolConfig) [][]string {
+	byName := make(map[string]*ToolConfig, len(tools))
+	for _, tool := range tools {
+		byName[tool.Name] = tool
+	}
+
+	// Tarjan's strongly connected components
+	index := make(map[string]int, len(tools))
+	low := make(map[string]int, len(tools))
+	onStack := make(map[string]bool)
+	var stack []string
+	var cycles [][]string
+
+	var visit func(tool *ToolConfig)
+	visit = func(tool *ToolConfig) {
+		index[tool.Name] = len(index) + 1
+		low[tool.Name] = index[tool.Name]
+		stack = append(stack, tool.Name)
+		onStack[tool.Name] = true
+
+		selfLoop := false
+		for _, dep := range tool.Dependencies {
+			depTool, ok := byName[dep]
+			switch {
+			case !ok:
+				continue
+			case dep == tool.Name:
+				selfLoop = true
+			case index[dep] == 0:
+				visit(depTool)
+				low[tool.Name] = min(low[tool.Name], low[dep])
+			case onStack[dep]:
+				low[tool.Name] = min(low[tool.Name], index[dep])
+			}
+		}
+		if low[tool.Name] != index[tool.Name] {
+			return
+		}
+		var group []string
+		for {
+			name := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[name] = false
+			group = append(group, name)
+			if name == tool.Name {
+				break
+			}
+		}
+		if len(group) > 1 || selfLoop {
+			sort.Strings(group)
+			cycles = append(cycles, group)
+		}
+	}
+	for _, tool := range tools {
+		if index[tool.Name] == 0 {
+			visit(tool)
+		}
+	}
+	return cycles
+}
@@ -0,0 +1,175 @@
+package symlink
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ildx/merlin/internal/config"
+	"github.com/ildx/merlin/internal/parser"
+)
+
+// toolIndexVersion is bumped whenever stamping or the cached config changes
+// (2: glob sources, 3: launchd links, 4: bin links, 5: XDG variables,
+// 6: link order, notes and README, 7: manual steps, 8: permissions)
+const toolIndexVersion = 9
+
+// toolIndex persists discovery results between runs. Entries are keyed by tool
+// root, so several repositories can share the file, and each is invalidated
+// on its own when any stamped path changes.
+type toolIndex struct {
+	Version int                    `json:"version"`
+	Tools   map[string]indexedTool `json:"tools"`
+}
+
+type indexedTool struct {
+	HomeDir           string               `json:"home_dir"`
+	ConfigDir         string               `json:"config_dir"`
+	BinDir            string               `json:"bin_dir,omitempty"`
+	RequireMerlinTOML bool                 `json:"require_merlin_toml,omitempty"` // settings.require_merlin_toml the config was resolved with
+	XDG               map[string]string    `json:"xdg,omitempty"`                 // {xdg_*} values the config was resolved with
+	Stamps            map[string]fileStamp `json:"stamps"`
+	Config            *ToolConfig          `json:"config"`
+}
+
+// fileStamp identifies a file version; ModTime is -1 for a missing path
+type fileStamp struct {
+	ModTime int64 `json:"mtime"`
+	Size    int64 `json:"size"`
+}
+
+// ToolIndexPath returns the location of the persisted discovery index
+func ToolIndexPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("get home directory: %w", err)
+	}
+	return filepath.Join(home, ".merlin", "state", "tools.json"), nil
+}
+
+// loadToolIndex reads the index; a missing, unreadable or outdated index is empty
+func loadToolIndex() *toolIndex {
+	index := &toolIndex{Version: toolIndexVersion, Tools: make(map[string]indexedTool)}
+	path, err := ToolIndexPath()
+	if err != nil {
+		return index
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return index
+	}
+	var loaded toolIndex
+	if json.Unmarshal(data, &loaded) != nil || loaded.Version != toolIndexVersion || loaded.Tools == nil {
+		return index
+	}
+	return &loaded
+}
+
+// save writes the index atomically so concurrent runs never see a torn file
+func (idx *toolIndex) save() error {
+	path, err := ToolIndexPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create state directory: %w", err)
+	}
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "tools-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// lookup returns the cached config for a tool if nothing it depends on changed
+func (idx *toolIndex) lookup(toolRoot string, vars Variables) (*ToolConfig, bool) {
+	entry, ok := idx.Tools[toolRoot]
+	if !ok || entry.Config == nil || entry.HomeDir != vars.HomeDir || entry.ConfigDir != vars.ConfigDir || entry.BinDir != vars.BinDir ||
+		entry.RequireMerlinTOML != vars.RequireMerlinTOML ||
+		!maps.Equal(entry.XDG, XDGDirs(vars.HomeDir)) {
+		return nil, false
+	}
+	for path, stamp := range entry.Stamps {
+		if stampOf(path) != stamp {
+			return nil, false
+		}
+	}
+	return entry.Config, true
+}
+
+// prune drops entries for tools under configDir that no longer exist
+func (idx *toolIndex) prune(configDir string, tools []string) bool {
+	present := make(map[string]bool, len(tools))
+	for _, tool := range tools {
+		present[filepath.Join(configDir, tool)] = true
+	}
+	changed := false
+	for root := range idx.Tools {
+		if filepath.Dir(root) == configDir && !present[root] {
+			delete(idx.Tools, root)
+			changed = true
+		}
+	}
+	return changed
+}
+
+// toolStamps stamps every path whose change could alter the tool's discovery
+// result: its directory, merlin.toml, config/, each declared link source (or
+// the directories a glob source searches), plus their parents so created or
+// deleted sources are noticed.
+func toolStamps(repo *config.DotfilesRepo, toolName string) map[string]fileStamp {
+	toolRoot := repo.GetToolRoot(toolName)
+	merlinPath := repo.GetToolMerlinConfig(toolName)
+	paths := []string{toolRoot, merlinPath, repo.GetToolConfigDir(toolName)}
+
+	// Glob sources depend on directory listings rather than single paths
+	addSource := func(source string) {
+		if IsGlob(source) {
+			paths = append(paths, GlobDirs(toolRoot, source)...)
+			return
+		}
+		paths = append(paths, filepath.Join(toolRoot, source))
+	}
+	if merlinConfig, err := parser.ParseToolMerlinTOML(merlinPath); err == nil {
+		for _, link := range merlinConfig.Links {
+			if link.Source != "" {
+				addSource(link.Source)
+			}
+			for _, file := range link.Files {
+				addSource(file.Source)
+			}
+		}
+	}
+
+	stamps := make(map[string]fileStamp, len(paths)*2)
+	for _, path := range paths {
+		stamps[path] = stampOf(path)
+		if parent := filepath.Dir(path); strings.HasPrefix(parent, toolRoot) {
+			stamps[parent] = stampOf(parent)
+		}
+	}
+	return stamps
+}
+
+func stampOf(path string) fileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{ModTime: -1}
+	}
+	return fileStamp{ModTime: info.ModTime().UnixNano(), Size: info.Size()}
+}
@@ -0,0 +1,98 @@
+package symlink
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// launchctl runs launchctl with args; replaced in tests
+var launchctl = func(args ...string) error {
+	out, err := exec.Command("launchctl", args...).CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("launchctl %s: %s", args[0], msg)
+		}
+		return fmt.Errorf("launchctl %s: %w", args[0], err)
+	}
+	return nil
+}
+
+// LaunchAgents returns the .plist targets of the tool's links declared with
+// launchd = true: plist file targets, plists directly inside a linked
+// directory, and plists placed by a contents link.
+func LaunchAgents(tool *ToolConfig) []string {
+	var agents []string
+	for _, link := range tool.Links {
+		if !link.Launchd {
+			continue
+		}
+		switch {
+		case link.Contents:
+			links, _ := ContentLinks(link)
+			for _, l := range links {
+				if isPlist(l.Target) {
+					agents = append(agents, l.Target)
+				}
+			}
+		case link.IsDir:
+			entries, _ := fsys.ReadDir(link.Source)
+			for _, entry := range entries {
+				if !entry.IsDir() && isPlist(entry.Name()) {
+					agents = append(agents, filepath.Join(link.Target, entry.Name()))
+				}
+			}
+		case isPlist(link.Target):
+			agents = append(agents, link.Target)
+		}
+	}
+	return agents
+}
+
+// LoadLaunchAgents loads the tool's launch agents that are linked at their
+// targets, unloading each first so an edited plist takes effect. It returns
+// the agents handled; in dry-run mode nothing is run.
+func LoadLaunchAgents(tool *ToolConfig, dryRun bool) ([]string, error) {
+	var loaded []string
+	var errs []error
+	for _, agent := range LaunchAgents(tool) {
+		if !dryRun && !IsSymlink(agent) {
+			continue // not linked (skipped or conflicting)
+		}
+		if !dryRun {
+			launchctl("unload", agent) // not loaded yet is fine
+			if err := launchctl("load", "-w", agent); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", filepath.Base(agent), err))
+				continue
+			}
+		}
+		loaded = append(loaded, agent)
+	}
+	return loaded, errors.Join(errs...)
+}
+
+// UnloadLaunchAgents unloads the tool's linked launch agents. Call it before
+// UnlinkTool: launchctl needs the plist to find the job.
+func UnloadLaunchAgents(tool *ToolConfig, dryRun bool) ([]string, error) {
+	var unloaded []string
+	var errs []error
+	for _, agent := range LaunchAgents(tool) {
+		if !IsSymlink(agent) {
+			continue // never touch a plist merlin didn't link
+		}
+		if !dryRun {
+			if err := launchctl("unload", "-w", agent); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", filepath.Base(agent), err))
+				continue
+			}
+		}
+		unloaded = append(unloaded, agent)
+	}
+	return unloaded, errors.Join(errs...)
+}
+
+func isPlist(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".plist")
+}
@@ -0,0 +1,67 @@
+package symlink
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Leftovers returns the link targets of tool that are still there once its
+// links are removed: directories contents links filled, and files or
+// directories that were never merlin's links. Targets shared with a tool in
+// others (the same path, inside one of its targets or holding one) are
+// skipped, and so are {home_dir}, {config_dir}, {bin_dir} and their parents.
+func Leftovers(tool *ToolConfig, others []*ToolConfig, vars Variables) []string {
+	roots := vars.roots()
+	seen := make(map[string]bool)
+	var leftovers []string
+	for _, link := range tool.Links {
+		target := filepath.Clean(link.Target)
+		if seen[target] || isRootOrParent(target, roots) || sharedTarget(target, tool, others) {
+			continue
+		}
+		seen[target] = true
+		info, err := fsys.Lstat(target)
+		if err != nil || info.Mode()&os.ModeSymlink != 0 {
+			continue
+		}
+		leftovers = append(leftovers, target)
+	}
+	sort.Strings(leftovers)
+	return leftovers
+}
+
+// roots returns {home_dir}, {config_dir} and {bin_dir}
+func (v Variables) roots() []string {
+	binDir := v.BinDir
+	if binDir == "" {
+		binDir = filepath.Join(v.HomeDir, "bin")
+	}
+	return []string{filepath.Clean(v.HomeDir), filepath.Clean(v.ConfigDir), filepath.Clean(binDir)}
+}
+
+// isRootOrParent reports whether path is one of roots or holds one of them
+func isRootOrParent(path string, roots []string) bool {
+	for _, root := range roots {
+		if root != "" && within(filepath.Clean(root), path) {
+			return true
+		}
+	}
+	return false
+}
+
+// sharedTarget reports whether a tool in others links to, into or above target
+func sharedTarget(target string, tool *ToolConfig, others []*ToolConfig) bool {
+	for _, other := range others {
+		if other.Name == tool.Name {
+			continue
+		}
+		for _, link := range other.Links {
+			t := filepath.Clean(link.Target)
+			if within(t, target) || within(target, t) {
+				return true
+			}
+		}
+	}
+	return false
+}
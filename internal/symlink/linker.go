@@ -6,15 +6,20 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/ildx/merlin/internal/protect"
 )
 
 // LinkResult represents the outcome of a symlink operation
 type LinkResult struct {
-	Source  string
-	Target  string
-	Status  LinkStatus
-	Message string
-	IsDir   bool
+	Source           string
+	Target           string
+	Status           LinkStatus
+	Message          string
+	IsDir            bool
+	PermissionDenied bool   // true when the failure was EPERM/EACCES (eligible for sudo escalation)
+	Preview          string // dry-run only: unified diff of the target's content being replaced
+	BackupID         string // backup holding the replaced target (StrategyBackup)
 }
 
 // LinkStatus represents the status of a link operation
@@ -53,7 +58,7 @@ func CreateSymlink(source, target string, dryRun bool) (*LinkResult, error) {
 	}
 
 	// Check if source exists
-	sourceInfo, err := os.Lstat(source)
+	sourceInfo, err := fsys.Lstat(source)
 	if err != nil {
 		result.Status = LinkStatusError
 		result.Message = fmt.Sprintf("source does not exist: %v", err)
@@ -62,12 +67,12 @@ func CreateSymlink(source, target string, dryRun bool) (*LinkResult, error) {
 	result.IsDir = sourceInfo.IsDir()
 
 	// Check if target already exists
-	targetInfo, err := os.Lstat(target)
+	targetInfo, err := fsys.Lstat(target)
 	if err == nil {
 		// Target exists - check if it's already our symlink
 		if targetInfo.Mode()&os.ModeSymlink != 0 {
 			// It's a symlink - check where it points
-			linkDest, err := os.Readlink(target)
+			linkDest, err := fsys.Readlink(target)
 			if err != nil {
 				result.Status = LinkStatusError
 				result.Message = fmt.Sprintf("failed to read existing symlink: %v", err)
@@ -106,7 +111,11 @@ func CreateSymlink(source, target string, dryRun bool) (*LinkResult, error) {
 		return result, nil
 	}
 
-	// Target doesn't exist - we can create the symlink
+	// Target doesn't exist - we can create the symlink unless it is protected
+	if err := refuseProtected(result, target); err != nil {
+		return result, err
+	}
+
 	if dryRun {
 		result.Status = LinkStatusSuccess
 		result.Message = "would create symlink (dry-run)"
@@ -115,16 +124,18 @@ func CreateSymlink(source, target string, dryRun bool) (*LinkResult, error) {
 
 	// Ensure parent directory exists
 	targetDir := filepath.Dir(target)
-	if err := os.MkdirAll(targetDir, 0755); err != nil {
+	if err := fsys.MkdirAll(targetDir, 0755); err != nil {
 		result.Status = LinkStatusError
 		result.Message = fmt.Sprintf("failed to create parent directory: %v", err)
+		markPermissionDenied(result, err)
 		return result, fmt.Errorf("failed to create directory %s: %w", targetDir, err)
 	}
 
 	// Create the symlink
-	if err := os.Symlink(source, target); err != nil {
+	if err := fsys.Symlink(source, target); err != nil {
 		result.Status = LinkStatusError
 		result.Message = fmt.Sprintf("failed to create symlink: %v", err)
+		markPermissionDenied(result, err)
 		return result, fmt.Errorf("failed to create symlink: %w", err)
 	}
 
@@ -133,13 +144,73 @@ func CreateSymlink(source, target string, dryRun bool) (*LinkResult, error) {
 	return result, nil
 }
 
+// refuseProtected fails result when target is covered by protected_paths
+func refuseProtected(result *LinkResult, target string) error {
+	if err := protect.Check(target); err != nil {
+		result.Status = LinkStatusError
+		result.Message = err.Error()
+		return err
+	}
+	return nil
+}
+
+// WalkOptions controls which entries are visited when linking directory contents
+type WalkOptions struct {
+	IncludeHidden bool              // Link dotfiles such as .prettierrc (VCS metadata is always skipped)
+	Rename        map[string]string // Source-relative path → target-relative path (slash-separated)
+	DotPrefix     bool              // Prepend "." to top-level entries that don't have one
+}
+
+// targetRel maps a path relative to the source directory to its path under
+// the target. The longest matching rename rule wins, so renaming a directory
+// moves everything inside it; otherwise DotPrefix dots the top-level entry.
+func (o WalkOptions) targetRel(rel string) string {
+	slashed := filepath.ToSlash(rel)
+	for prefix := slashed; ; {
+		if to, ok := o.Rename[prefix]; ok {
+			return filepath.FromSlash(to + strings.TrimPrefix(slashed, prefix))
+		}
+		i := strings.LastIndex(prefix, "/")
+		if i < 0 {
+			break
+		}
+		prefix = prefix[:i]
+	}
+	if o.DotPrefix && !strings.HasPrefix(slashed, ".") {
+		return "." + rel
+	}
+	return rel
+}
+
+// alwaysSkip lists entries never linked from a contents walk, even with IncludeHidden
+var alwaysSkip = map[string]bool{
+	".git":      true,
+	".hg":       true,
+	".svn":      true,
+	".DS_Store": true,
+}
+
+// shouldSkip reports whether a walked entry is excluded by the options
+func shouldSkip(name string, opts WalkOptions) bool {
+	if alwaysSkip[name] {
+		return true
+	}
+	return strings.HasPrefix(name, ".") && !opts.IncludeHidden
+}
+
 // WalkAndLink recursively walks a source directory and creates symlinks
-// for all files and subdirectories in the target directory
+// for all files and subdirectories in the target directory.
+// Hidden entries are skipped; use WalkAndLinkWithOptions to include them.
 func WalkAndLink(source, target string, dryRun bool) ([]*LinkResult, error) {
-	var results []*LinkResult
+	return WalkAndLinkWithOptions(source, target, WalkOptions{}, dryRun)
+}
 
+// WalkAndLinkWithOptions is WalkAndLink with control over hidden file handling.
+// The walk and directory creation run in order; file symlinks are then created
+// on a bounded worker pool. Results keep walk order.
+func WalkAndLinkWithOptions(source, target string, opts WalkOptions, dryRun bool) ([]*LinkResult, error) {
 	// Check if source is a directory
-	sourceInfo, err := os.Stat(source)
+	sourceInfo, err := fsys.Stat(source)
 	if err != nil {
 		return nil, fmt.Errorf("failed to stat source %s: %w", source, err)
 	}
@@ -153,12 +224,16 @@ func WalkAndLink(source, target string, dryRun bool) ([]*LinkResult, error) {
 		return []*LinkResult{result}, nil
 	}
 
-	// Source is a directory - we need to decide whether to link the whole
-	// directory or its contents
-	// For now, we'll link individual files to preserve the ability to have
-	// some files from dotfiles and some from elsewhere
+	// Source is a directory - link individual files to preserve the ability
+	// to have some files from dotfiles and some from elsewhere. Each entry is
+	// either a file to link or an already-failed directory.
+	type walkEntry struct {
+		source, target string
+		failed         *LinkResult
+	}
+	var entries []walkEntry
 
-	err = filepath.WalkDir(source, func(path string, d fs.DirEntry, err error) error {
+	err = walkDir(source, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
@@ -175,10 +250,10 @@ func WalkAndLink(source, target string, dryRun bool) ([]*LinkResult, error) {
 		}
 
 		// Calculate target path
-		targetPath := filepath.Join(target, relPath)
+		targetPath := filepath.Join(target, opts.targetRel(relPath))
 
-		// Skip hidden files and directories (starting with .)
-		if strings.HasPrefix(d.Name(), ".") && path != source {
+		// Skip hidden files and directories unless requested; VCS metadata always
+		if shouldSkip(d.Name(), opts) {
 			if d.IsDir() {
 				return filepath.SkipDir
 			}
@@ -188,15 +263,14 @@ func WalkAndLink(source, target string, dryRun bool) ([]*LinkResult, error) {
 		// If it's a directory, just ensure it exists at target
 		if d.IsDir() {
 			if !dryRun {
-				if err := os.MkdirAll(targetPath, 0755); err != nil {
-					result := &LinkResult{
+				if err := fsys.MkdirAll(targetPath, 0755); err != nil {
+					entries = append(entries, walkEntry{failed: &LinkResult{
 						Source:  path,
 						Target:  targetPath,
 						Status:  LinkStatusError,
 						Message: fmt.Sprintf("failed to create directory: %v", err),
 						IsDir:   true,
-					}
-					results = append(results, result)
+					}})
 					return nil // Continue walking
 				}
 			}
@@ -204,14 +278,20 @@ func WalkAndLink(source, target string, dryRun bool) ([]*LinkResult, error) {
 			return nil
 		}
 
-		// It's a file - create symlink
-		result, err := CreateSymlink(path, targetPath, dryRun)
-		results = append(results, result)
-		
-		// Continue even if there was an error linking this file
+		entries = append(entries, walkEntry{source: path, target: targetPath})
 		return nil
 	})
 
+	// Link what was walked even if the walk stopped early; errors linking
+	// individual files are reported in their results
+	results := runLinks(len(entries), linkWorkers, func(i int) *LinkResult {
+		if entries[i].failed != nil {
+			return entries[i].failed
+		}
+		result, _ := CreateSymlink(entries[i].source, entries[i].target, dryRun)
+		return result
+	})
+
 	if err != nil {
 		return results, fmt.Errorf("failed to walk directory: %w", err)
 	}
@@ -219,6 +299,148 @@ func WalkAndLink(source, target string, dryRun bool) ([]*LinkResult, error) {
 	return results, nil
 }
 
+// ContentLinks expands a contents-mode link into one file-level link per entry
+// under its source directory, honoring the same skip rules as WalkAndLink.
+func ContentLinks(link ResolvedLink) ([]ResolvedLink, error) {
+	opts := link.walkOptions()
+	var links []ResolvedLink
+	err := walkDir(link.Source, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == link.Source {
+			return nil
+		}
+		if shouldSkip(d.Name(), opts) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		relPath, err := filepath.Rel(link.Source, path)
+		if err != nil {
+			return fmt.Errorf("failed to get relative path: %w", err)
+		}
+		links = append(links, ResolvedLink{
+			Source: path,
+			Target: filepath.Join(link.Target, opts.targetRel(relPath)),
+			Order:  link.Order,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk directory: %w", err)
+	}
+	return links, nil
+}
+
+// ExpandLinks replaces contents-mode links with their file-level links
+func ExpandLinks(links []ResolvedLink) []ResolvedLink {
+	var out []ResolvedLink
+	for _, link := range links {
+		if !link.Contents {
+			out = append(out, link)
+			continue
+		}
+		expanded, err := ContentLinks(link)
+		if err != nil {
+			// Keep the directory link so callers surface the error per result
+			out = append(out, link)
+			continue
+		}
+		out = append(out, expanded...)
+	}
+	return out
+}
+
+// TargetFiles returns the regular files that currently exist at the tool's link targets.
+// Directory targets are walked (following a top-level symlink) and reported by their
+// path under the target, so restoring a backup writes back to the live location.
+func TargetFiles(tool *ToolConfig) ([]string, error) {
+	var files []string
+	for _, link := range ExpandLinks(tool.Links) {
+		found, err := filesAt(link.Target)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, found...)
+	}
+	return files, nil
+}
+
+// ConflictTargets returns the regular files that linking the tools would replace:
+// targets that exist but are not already symlinks to their source.
+func ConflictTargets(tools []*ToolConfig) ([]string, error) {
+	var files []string
+	for _, tool := range tools {
+		for _, link := range ExpandLinks(tool.Links) {
+			if _, err := fsys.Lstat(link.Target); err != nil {
+				continue
+			}
+			if linked, _ := IsLinked(link.Source, link.Target); linked {
+				continue
+			}
+			found, err := filesAt(link.Target)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", tool.Name, err)
+			}
+			files = append(files, found...)
+		}
+	}
+	return files, nil
+}
+
+// filesAt lists regular files at target: the target itself, or everything beneath it.
+func filesAt(target string) ([]string, error) {
+	info, err := fsys.Stat(target)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("stat %s: %w", target, err)
+	}
+
+	if info.Mode().IsRegular() {
+		return []string{target}, nil
+	}
+	if !info.IsDir() {
+		return nil, nil
+	}
+
+	root, err := resolveLinks(target)
+	if err != nil {
+		return nil, fmt.Errorf("resolve %s: %w", target, err)
+	}
+	var files []string
+	err = walkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if alwaysSkip[d.Name()] {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.Join(target, rel))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walk %s: %w", target, err)
+	}
+	return files, nil
+}
+
 // LinkTool links all configured links for a tool
 func LinkTool(tool *ToolConfig, dryRun bool) ([]*LinkResult, error) {
 	var allResults []*LinkResult
@@ -226,7 +448,20 @@ func LinkTool(tool *ToolConfig, dryRun bool) ([]*LinkResult, error) {
 	for _, link := range tool.Links {
 		var results []*LinkResult
 
-		if link.IsDir {
+		if link.Contents {
+			var err error
+			results, err = WalkAndLinkWithOptions(link.Source, link.Target, link.walkOptions(), dryRun)
+			if err != nil {
+				// A missing or unreadable source must not look like an empty success
+				results = append(results, &LinkResult{
+					Source:  link.Source,
+					Target:  link.Target,
+					Status:  LinkStatusError,
+					Message: err.Error(),
+					IsDir:   true,
+				})
+			}
+		} else if link.IsDir {
 			// If we want to link the whole directory as one symlink
 			// (not its contents), use CreateSymlink
 			// Otherwise use WalkAndLink to link contents
@@ -260,7 +495,7 @@ func LinkTool(tool *ToolConfig, dryRun bool) ([]*LinkResult, error) {
 // IsLinked checks if a target path is already correctly symlinked to source
 func IsLinked(source, target string) (bool, error) {
 	// Check if target exists
-	targetInfo, err := os.Lstat(target)
+	targetInfo, err := fsys.Lstat(target)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return false, nil
@@ -274,7 +509,7 @@ func IsLinked(source, target string) (bool, error) {
 	}
 
 	// Read where the symlink points
-	linkDest, err := os.Readlink(target)
+	linkDest, err := fsys.Readlink(target)
 	if err != nil {
 		return false, fmt.Errorf("failed to read symlink: %w", err)
 	}
@@ -307,7 +542,7 @@ func GetLinkStatus(tool *ToolConfig) map[string]LinkStatus {
 			status[link.Target] = LinkStatusAlreadyLinked
 		} else {
 			// Check if target exists (conflict)
-			if _, err := os.Stat(link.Target); err == nil {
+			if _, err := fsys.Stat(link.Target); err == nil {
 				status[link.Target] = LinkStatusConflict
 			} else {
 				status[link.Target] = LinkStatusSkipped
@@ -332,7 +567,7 @@ func RemoveSymlink(source, target string, dryRun bool) (*UnlinkResult, error) {
 	}
 
 	// Check if target exists
-	targetInfo, err := os.Lstat(target)
+	targetInfo, err := fsys.Lstat(target)
 	if err != nil {
 		if os.IsNotExist(err) {
 			result.Status = LinkStatusSkipped
@@ -352,7 +587,7 @@ func RemoveSymlink(source, target string, dryRun bool) (*UnlinkResult, error) {
 	}
 
 	// Read where the symlink points
-	linkDest, err := os.Readlink(target)
+	linkDest, err := fsys.Readlink(target)
 	if err != nil {
 		result.Status = LinkStatusError
 		result.Message = fmt.Sprintf("failed to read symlink: %v", err)
@@ -374,6 +609,12 @@ func RemoveSymlink(source, target string, dryRun bool) (*UnlinkResult, error) {
 		return result, nil
 	}
 
+	if err := protect.Check(target); err != nil {
+		result.Status = LinkStatusError
+		result.Message = err.Error()
+		return result, err
+	}
+
 	// Remove the symlink
 	if dryRun {
 		result.Status = LinkStatusSuccess
@@ -381,7 +622,7 @@ func RemoveSymlink(source, target string, dryRun bool) (*UnlinkResult, error) {
 		return result, nil
 	}
 
-	if err := os.Remove(target); err != nil {
+	if err := fsys.Remove(target); err != nil {
 		result.Status = LinkStatusError
 		result.Message = fmt.Sprintf("failed to remove: %v", err)
 		return result, fmt.Errorf("failed to remove: %w", err)
@@ -396,7 +637,7 @@ func RemoveSymlink(source, target string, dryRun bool) (*UnlinkResult, error) {
 func UnlinkTool(tool *ToolConfig, dryRun bool) ([]*UnlinkResult, error) {
 	var results []*UnlinkResult
 
-	for _, link := range tool.Links {
+	for _, link := range ExpandLinks(tool.Links) {
 		result, err := RemoveSymlink(link.Source, link.Target, dryRun)
 		results = append(results, result)
 		
@@ -1,9 +1,21 @@
 package symlink
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
 	"testing"
+
+	"github.com/ildx/merlin/internal/backup"
+	"github.com/ildx/merlin/internal/config"
+	"github.com/ildx/merlin/internal/models"
+	"github.com/ildx/merlin/internal/protect"
 )
 
 func TestCreateSymlink(t *testing.T) {
@@ -388,6 +400,182 @@ func TestWalkAndLink(t *testing.T) {
 	})
 }
 
+func TestWalkAndLinkIncludeHidden(t *testing.T) {
+	tmpDir := t.TempDir()
+	sourceDir := filepath.Join(tmpDir, "source")
+	targetDir := filepath.Join(tmpDir, "target")
+
+	os.MkdirAll(filepath.Join(sourceDir, ".git"), 0755)
+	os.WriteFile(filepath.Join(sourceDir, ".git", "HEAD"), []byte("ref"), 0644)
+	os.WriteFile(filepath.Join(sourceDir, ".prettierrc"), []byte("{}"), 0644)
+	os.WriteFile(filepath.Join(sourceDir, "init.lua"), []byte("--"), 0644)
+
+	results, err := WalkAndLinkWithOptions(sourceDir, targetDir, WalkOptions{IncludeHidden: true}, false)
+	if err != nil {
+		t.Fatalf("WalkAndLinkWithOptions() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results (.prettierrc, init.lua), got %d", len(results))
+	}
+
+	if linked, _ := IsLinked(filepath.Join(sourceDir, ".prettierrc"), filepath.Join(targetDir, ".prettierrc")); !linked {
+		t.Error("hidden file should be linked when IncludeHidden is set")
+	}
+	if _, err := os.Lstat(filepath.Join(targetDir, ".git")); !os.IsNotExist(err) {
+		t.Error(".git should never be linked")
+	}
+}
+
+func TestLinkToolWithStrategyContents(t *testing.T) {
+	tmpDir := t.TempDir()
+	sourceDir := filepath.Join(tmpDir, "source")
+	targetDir := filepath.Join(tmpDir, "target")
+
+	os.MkdirAll(sourceDir, 0755)
+	os.WriteFile(filepath.Join(sourceDir, ".stylua.toml"), []byte("x"), 0644)
+	os.WriteFile(filepath.Join(sourceDir, "init.lua"), []byte("--"), 0644)
+
+	tool := &ToolConfig{
+		Name: "nvim",
+		Links: []ResolvedLink{
+			{Source: sourceDir, Target: targetDir, IsDir: true, Contents: true},
+		},
+	}
+
+	results, err := LinkToolWithStrategy(tool, StrategySkip, false)
+	if err != nil {
+		t.Fatalf("LinkToolWithStrategy() error = %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected only init.lua to be linked without include_hidden, got %d results", len(results))
+	}
+
+	tool.Links[0].IncludeHidden = true
+	unlinked, _ := UnlinkTool(tool, false)
+	if len(unlinked) != 2 {
+		t.Fatalf("expected unlink to visit 2 entries, got %d", len(unlinked))
+	}
+	if _, err := os.Lstat(filepath.Join(targetDir, "init.lua")); !os.IsNotExist(err) {
+		t.Error("init.lua symlink should be removed")
+	}
+}
+
+func TestProtectedPaths(t *testing.T) {
+	tmpDir := t.TempDir()
+	source := filepath.Join(tmpDir, "source")
+	keys := filepath.Join(tmpDir, "home", ".ssh", "authorized_keys")
+	os.WriteFile(source, []byte("bad"), 0644)
+	os.MkdirAll(filepath.Dir(keys), 0700)
+	os.WriteFile(keys, []byte("ssh-ed25519 AAAA"), 0600)
+
+	protect.Set([]string{keys}, "")
+	defer protect.Set(nil, "")
+
+	for _, strategy := range []ConflictStrategy{StrategySkip, StrategyBackup, StrategyOverwrite} {
+		result, err := ResolveConflict(source, keys, strategy, false)
+		if !errors.Is(err, protect.ErrProtected) || result.Status != LinkStatusError {
+			t.Errorf("%s: expected protected error, got status %s, err %v", strategy, result.Status, err)
+		}
+	}
+
+	// Replacing the parent directory would replace the protected file too
+	result, err := ResolveConflict(source, filepath.Dir(keys), StrategyOverwrite, false)
+	if !errors.Is(err, protect.ErrProtected) {
+		t.Errorf("expected parent directory to be refused, got status %s, err %v", result.Status, err)
+	}
+
+	if data, _ := os.ReadFile(keys); string(data) != "ssh-ed25519 AAAA" {
+		t.Errorf("protected file was modified: %q", data)
+	}
+
+	// Missing protected paths are not created either
+	os.Remove(keys)
+	if result, err := CreateSymlink(source, keys, true); !errors.Is(err, protect.ErrProtected) {
+		t.Errorf("expected dry-run create to be refused, got status %s, err %v", result.Status, err)
+	}
+}
+
+func TestTargetFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	sourceDir := filepath.Join(tmpDir, "source")
+	os.MkdirAll(filepath.Join(sourceDir, "nested", ".git"), 0755)
+	os.WriteFile(filepath.Join(sourceDir, "init.lua"), []byte("--"), 0644)
+	os.WriteFile(filepath.Join(sourceDir, "nested", "opts.lua"), []byte("--"), 0644)
+	os.WriteFile(filepath.Join(sourceDir, "nested", ".git", "HEAD"), []byte("x"), 0644)
+
+	// Directory target is a symlink into the repo; file target is a real file
+	dirTarget := filepath.Join(tmpDir, "home", ".config", "nvim")
+	os.MkdirAll(filepath.Dir(dirTarget), 0755)
+	os.Symlink(sourceDir, dirTarget)
+	fileTarget := filepath.Join(tmpDir, "home", ".zshrc")
+	os.WriteFile(fileTarget, []byte("export"), 0644)
+
+	tool := &ToolConfig{
+		Name: "mixed",
+		Links: []ResolvedLink{
+			{Source: sourceDir, Target: dirTarget, IsDir: true},
+			{Source: filepath.Join(sourceDir, "zshrc"), Target: fileTarget},
+			{Source: filepath.Join(sourceDir, "missing"), Target: filepath.Join(tmpDir, "home", "missing")},
+		},
+	}
+
+	files, err := TargetFiles(tool)
+	if err != nil {
+		t.Fatalf("TargetFiles() error = %v", err)
+	}
+
+	want := map[string]bool{
+		filepath.Join(dirTarget, "init.lua"):           true,
+		filepath.Join(dirTarget, "nested", "opts.lua"): true,
+		fileTarget: true,
+	}
+	if len(files) != len(want) {
+		t.Fatalf("TargetFiles() = %v, want %d files", files, len(want))
+	}
+	for _, f := range files {
+		if !want[f] {
+			t.Errorf("unexpected file %s", f)
+		}
+	}
+}
+
+func TestConflictTargets(t *testing.T) {
+	tmpDir := t.TempDir()
+	sourceDir := filepath.Join(tmpDir, "source")
+	targetDir := filepath.Join(tmpDir, "target")
+	os.MkdirAll(sourceDir, 0755)
+	os.MkdirAll(targetDir, 0755)
+
+	linkedSrc := filepath.Join(sourceDir, "linked")
+	conflictSrc := filepath.Join(sourceDir, "conflict")
+	freshSrc := filepath.Join(sourceDir, "fresh")
+	for _, src := range []string{linkedSrc, conflictSrc, freshSrc} {
+		os.WriteFile(src, []byte("repo"), 0644)
+	}
+
+	linkedTarget := filepath.Join(targetDir, "linked")
+	conflictTarget := filepath.Join(targetDir, "conflict")
+	os.Symlink(linkedSrc, linkedTarget)
+	os.WriteFile(conflictTarget, []byte("local"), 0644)
+
+	tools := []*ToolConfig{{
+		Name: "demo",
+		Links: []ResolvedLink{
+			{Source: linkedSrc, Target: linkedTarget},
+			{Source: conflictSrc, Target: conflictTarget},
+			{Source: freshSrc, Target: filepath.Join(targetDir, "fresh")},
+		},
+	}}
+
+	conflicts, err := ConflictTargets(tools)
+	if err != nil {
+		t.Fatalf("ConflictTargets() error = %v", err)
+	}
+	if len(conflicts) != 1 || conflicts[0] != conflictTarget {
+		t.Errorf("ConflictTargets() = %v, want [%s]", conflicts, conflictTarget)
+	}
+}
+
 func TestLinkTool(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -481,6 +669,26 @@ func TestLinkTool(t *testing.T) {
 			t.Error("directory should be linked")
 		}
 	})
+
+	t.Run("contents link with missing source", func(t *testing.T) {
+		tool := &ToolConfig{
+			Name: "testtool3",
+			Links: []ResolvedLink{{
+				Source:   filepath.Join(tmpDir, "tool3", "gone"),
+				Target:   filepath.Join(tmpDir, "target3"),
+				IsDir:    true,
+				Contents: true,
+			}},
+		}
+
+		results, err := LinkTool(tool, false)
+		if err != nil {
+			t.Fatalf("LinkTool() error = %v", err)
+		}
+		if len(results) != 1 || results[0].Status != LinkStatusError {
+			t.Fatalf("expected one error result, got %+v", results)
+		}
+	})
 }
 
 func TestGetLinkStatus(t *testing.T) {
@@ -527,3 +735,643 @@ func TestGetLinkStatus(t *testing.T) {
 	}
 }
 
+func TestMarkPermissionDenied(t *testing.T) {
+	result := &LinkResult{Source: "/repo/hosts", Target: "/etc/hosts", Status: LinkStatusError}
+	markPermissionDenied(result, &os.PathError{Op: "symlink", Path: "/etc/hosts", Err: syscall.EACCES})
+	if !result.PermissionDenied {
+		t.Fatal("expected EACCES to be flagged as permission denied")
+	}
+	if !strings.Contains(result.Message, "--sudo") {
+		t.Errorf("expected remediation hint in message, got %q", result.Message)
+	}
+
+	other := &LinkResult{Message: "failed"}
+	markPermissionDenied(other, &os.PathError{Op: "symlink", Path: "/x", Err: syscall.ENOENT})
+	if other.PermissionDenied || other.Message != "failed" {
+		t.Error("non-permission errors should be left untouched")
+	}
+}
+
+func TestRunLinksOrder(t *testing.T) {
+	for _, n := range []int{0, 1, linkBatchSize - 1, linkBatchSize*10 + 3} {
+		results := runLinks(n, 8, func(i int) *LinkResult {
+			return &LinkResult{Target: strconv.Itoa(i)}
+		})
+		if len(results) != n {
+			t.Fatalf("n=%d: got %d results", n, len(results))
+		}
+		for i, r := range results {
+			if r.Target != strconv.Itoa(i) {
+				t.Fatalf("n=%d: result %d out of order (%s)", n, i, r.Target)
+			}
+		}
+	}
+}
+
+// makeTree creates files spread over nested directories for walk tests
+func makeTree(t testing.TB, root string, files int) {
+	t.Helper()
+	for i := 0; i < files; i++ {
+		dir := filepath.Join(root, fmt.Sprintf("pack%02d", i%20), "plugin", fmt.Sprintf("d%d", i%7))
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("f%04d.lua", i)), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestWalkAndLinkParallelOrder(t *testing.T) {
+	tmpDir := t.TempDir()
+	source := filepath.Join(tmpDir, "source")
+	makeTree(t, source, 1000)
+
+	defer func(w int) { linkWorkers = w }(linkWorkers)
+
+	relTargets := func(target string, results []*LinkResult) []string {
+		var rel []string
+		for _, r := range results {
+			if r.Status != LinkStatusSuccess {
+				t.Fatalf("%s: %s", r.Target, r.Message)
+			}
+			p, _ := filepath.Rel(target, r.Target)
+			rel = append(rel, p)
+		}
+		return rel
+	}
+
+	linkWorkers = 1
+	serialTarget := filepath.Join(tmpDir, "serial")
+	serial, err := WalkAndLink(source, serialTarget, false)
+	if err != nil {
+		t.Fatalf("serial WalkAndLink() error = %v", err)
+	}
+
+	linkWorkers = 16
+	parallelTarget := filepath.Join(tmpDir, "parallel")
+	parallel, err := WalkAndLink(source, parallelTarget, false)
+	if err != nil {
+		t.Fatalf("parallel WalkAndLink() error = %v", err)
+	}
+
+	want, got := relTargets(serialTarget, serial), relTargets(parallelTarget, parallel)
+	if len(got) != 1000 || strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("parallel results differ from walk order (%d vs %d results)", len(got), len(want))
+	}
+	for _, rel := range got {
+		if linked, _ := IsLinked(filepath.Join(source, rel), filepath.Join(parallelTarget, rel)); !linked {
+			t.Fatalf("%s not linked", rel)
+		}
+	}
+}
+
+func BenchmarkWalkAndLink(b *testing.B) {
+	source := filepath.Join(b.TempDir(), "source")
+	makeTree(b, source, 5000)
+
+	defer func(w int) { linkWorkers = w }(linkWorkers)
+	for _, workers := range []int{1, linkWorkers} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			linkWorkers = workers
+			target := filepath.Join(b.TempDir(), "target")
+			for i := 0; i < b.N; i++ {
+				if _, err := WalkAndLink(source, target, false); err != nil {
+					b.Fatal(err)
+				}
+				b.StopTimer()
+				os.RemoveAll(target)
+				b.StartTimer()
+			}
+		})
+	}
+}
+
+func TestWalkOptionsTargetRel(t *testing.T) {
+	opts := WalkOptions{
+		DotPrefix: true,
+		Rename: map[string]string{
+			"gitconfig":      ".gitconfig-personal",
+			"nvim":           ".config/nvim",
+			"nvim/lua/x.lua": ".config/nvim/lua/y.lua",
+		},
+	}
+	tests := []struct {
+		rel  string
+		want string
+	}{
+		{"gitconfig", ".gitconfig-personal"},
+		{"zshrc", ".zshrc"},
+		{".vimrc", ".vimrc"},
+		{"nvim/init.lua", ".config/nvim/init.lua"},
+		{"nvim/lua/x.lua", ".config/nvim/lua/y.lua"},
+		{"local/bin/tool", ".local/bin/tool"},
+	}
+	for _, tt := range tests {
+		if got := filepath.ToSlash(opts.targetRel(filepath.FromSlash(tt.rel))); got != tt.want {
+			t.Errorf("targetRel(%q) = %q, want %q", tt.rel, got, tt.want)
+		}
+	}
+
+	if got := (WalkOptions{}).targetRel("zshrc"); got != "zshrc" {
+		t.Errorf("targetRel without rules = %q, want zshrc", got)
+	}
+}
+
+func TestLinkToolWithStrategyRename(t *testing.T) {
+	tmpDir := t.TempDir()
+	sourceDir := filepath.Join(tmpDir, "source")
+	targetDir := filepath.Join(tmpDir, "target")
+
+	os.MkdirAll(filepath.Join(sourceDir, "config", "git"), 0755)
+	os.WriteFile(filepath.Join(sourceDir, "gitconfig"), []byte("x"), 0644)
+	os.WriteFile(filepath.Join(sourceDir, "zshrc"), []byte("x"), 0644)
+	os.WriteFile(filepath.Join(sourceDir, "config", "git", "ignore"), []byte("x"), 0644)
+
+	tool := &ToolConfig{
+		Name: "home",
+		Links: []ResolvedLink{{
+			Source:    sourceDir,
+			Target:    targetDir,
+			IsDir:     true,
+			Contents:  true,
+			DotPrefix: true,
+			Rename:    map[string]string{"gitconfig": ".gitconfig-personal"},
+		}},
+	}
+
+	if _, err := LinkToolWithStrategy(tool, StrategySkip, false); err != nil {
+		t.Fatalf("LinkToolWithStrategy() error = %v", err)
+	}
+	for source, target := range map[string]string{
+		"gitconfig":         ".gitconfig-personal",
+		"zshrc":             ".zshrc",
+		"config/git/ignore": ".config/git/ignore",
+	} {
+		if linked, _ := IsLinked(filepath.Join(sourceDir, source), filepath.Join(targetDir, target)); !linked {
+			t.Errorf("%s should be linked at %s", source, target)
+		}
+	}
+
+	links, err := ContentLinks(tool.Links[0])
+	if err != nil {
+		t.Fatalf("ContentLinks() error = %v", err)
+	}
+	for _, link := range links {
+		if strings.HasPrefix(filepath.Base(link.Target), "gitconfig") || filepath.Base(link.Target) == "zshrc" {
+			t.Errorf("ContentLinks() returned unmapped target %s", link.Target)
+		}
+	}
+
+	unlinked, _ := UnlinkTool(tool, false)
+	if len(unlinked) != 3 {
+		t.Fatalf("expected unlink to visit 3 entries, got %d", len(unlinked))
+	}
+	if _, err := os.Lstat(filepath.Join(targetDir, ".zshrc")); !os.IsNotExist(err) {
+		t.Error(".zshrc symlink should be removed")
+	}
+}
+
+func TestBrokenLinks(t *testing.T) {
+	tmpDir := t.TempDir()
+	repoRoot := filepath.Join(tmpDir, "repo")
+	sourceDir := filepath.Join(repoRoot, "config", "zsh", "config")
+	targetDir := filepath.Join(tmpDir, "target")
+
+	os.MkdirAll(filepath.Join(sourceDir, "functions"), 0755)
+	os.WriteFile(filepath.Join(sourceDir, "functions", "f.zsh"), []byte("x"), 0644)
+	os.MkdirAll(filepath.Join(targetDir, "functions"), 0755)
+
+	// Managed links into the repo whose sources are gone
+	os.Symlink(filepath.Join(sourceDir, "old.zsh"), filepath.Join(targetDir, "old.zsh"))
+	os.Symlink(filepath.Join(sourceDir, "functions", "g.zsh"), filepath.Join(targetDir, "functions", "g.zsh"))
+	// A dangling link merlin didn't make, and a healthy one
+	os.Symlink(filepath.Join(tmpDir, "elsewhere"), filepath.Join(targetDir, "unrelated"))
+	os.Symlink(filepath.Join(sourceDir, "functions", "f.zsh"), filepath.Join(targetDir, "functions", "f.zsh"))
+	// A declared file target left dangling by a renamed tool
+	fileTarget := filepath.Join(tmpDir, "zshrc")
+	os.Symlink(filepath.Join(repoRoot, "config", "oldzsh", "zshrc"), fileTarget)
+
+	tool := &ToolConfig{
+		Name: "zsh",
+		Links: []ResolvedLink{
+			{Source: sourceDir, Target: targetDir, IsDir: true, Contents: true},
+			{Source: filepath.Join(repoRoot, "config", "zsh", "zshrc"), Target: fileTarget},
+		},
+	}
+
+	broken := BrokenLinks(tool, repoRoot)
+	var got []string
+	for _, link := range broken {
+		got = append(got, link.Target)
+	}
+	want := []string{
+		filepath.Join(targetDir, "functions", "g.zsh"),
+		filepath.Join(targetDir, "old.zsh"),
+		fileTarget,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("BrokenLinks() = %v, want %v", got, want)
+	}
+	if broken[len(broken)-1].Dest != filepath.Join(repoRoot, "config", "oldzsh", "zshrc") {
+		t.Errorf("Dest = %s, want old tool path", broken[len(broken)-1].Dest)
+	}
+}
+
+func TestStaleLinks(t *testing.T) {
+	tmpDir := t.TempDir()
+	repoRoot := filepath.Join(tmpDir, "repo")
+	sourceDir := filepath.Join(repoRoot, "config", "zsh", "config")
+	targetDir := filepath.Join(tmpDir, "zsh")
+	gone := filepath.Join(tmpDir, "gone")
+	os.MkdirAll(sourceDir, 0755)
+	os.MkdirAll(targetDir, 0755)
+	os.MkdirAll(gone, 0755)
+
+	// A contents link whose source was deleted
+	os.Symlink(filepath.Join(sourceDir, "old.zsh"), filepath.Join(targetDir, "old.zsh"))
+	// Links of a tool no longer declared, one of them registered
+	os.Symlink(filepath.Join(repoRoot, "config", "gone", "a"), filepath.Join(gone, "a"))
+	os.Symlink(filepath.Join(repoRoot, "config", "gone", "b"), filepath.Join(gone, "b"))
+	// Dangling, but not into the repository
+	os.Symlink(filepath.Join(tmpDir, "elsewhere"), filepath.Join(gone, "unrelated"))
+
+	tool := &ToolConfig{Name: "zsh", Links: []ResolvedLink{{Source: sourceDir, Target: targetDir, IsDir: true, Contents: true}}}
+	stale := StaleLinks([]*ToolConfig{tool}, map[string]string{filepath.Join(gone, "a"): "gone"}, repoRoot)
+
+	var got []string
+	for _, link := range stale {
+		got = append(got, link.Tool+" "+link.Target)
+	}
+	want := []string{
+		"gone " + filepath.Join(gone, "a"),
+		" " + filepath.Join(gone, "b"),
+		"zsh " + filepath.Join(targetDir, "old.zsh"),
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("StaleLinks() = %q, want %q", got, want)
+	}
+
+	if err := RemoveStaleLink(stale[0]); err != nil {
+		t.Fatalf("RemoveStaleLink: %v", err)
+	}
+	if _, err := os.Lstat(stale[0].Target); !os.IsNotExist(err) {
+		t.Error("expected the stale link to be removed")
+	}
+	if err := RemoveStaleLink(stale[0]); err == nil {
+		t.Error("expected an error removing a link that is gone")
+	}
+}
+
+func TestLaunchAgents(t *testing.T) {
+	tmpDir := t.TempDir()
+	sourceDir := filepath.Join(tmpDir, "agents")
+	targetDir := filepath.Join(tmpDir, "LaunchAgents")
+	os.MkdirAll(sourceDir, 0755)
+	os.WriteFile(filepath.Join(sourceDir, "com.example.sync.plist"), []byte("<plist/>"), 0644)
+	os.WriteFile(filepath.Join(sourceDir, "README.md"), []byte("x"), 0644)
+	// A plist the user manages themselves
+	os.MkdirAll(targetDir, 0755)
+	os.WriteFile(filepath.Join(targetDir, "com.example.own.plist"), []byte("<plist/>"), 0644)
+
+	tool := &ToolConfig{
+		Name: "agents",
+		Links: []ResolvedLink{
+			{Source: sourceDir, Target: targetDir, IsDir: true, Contents: true, Launchd: true},
+			{Source: filepath.Join(tmpDir, "com.example.own.plist"), Target: filepath.Join(targetDir, "com.example.own.plist"), Launchd: true},
+			{Source: filepath.Join(sourceDir, "README.md"), Target: filepath.Join(tmpDir, "README.md")},
+		},
+	}
+
+	var calls []string
+	orig := launchctl
+	launchctl = func(args ...string) error {
+		calls = append(calls, strings.Join(args, " "))
+		return nil
+	}
+	defer func() { launchctl = orig }()
+
+	agent := filepath.Join(targetDir, "com.example.sync.plist")
+	if got := LaunchAgents(tool); len(got) != 2 || got[0] != agent {
+		t.Fatalf("LaunchAgents() = %v, want the linked plist and the declared file target", got)
+	}
+
+	// Dry run reports without calling launchctl
+	if loaded, err := LoadLaunchAgents(tool, true); err != nil || len(loaded) != 2 || len(calls) != 0 {
+		t.Fatalf("LoadLaunchAgents(dry-run) = %v, %v with calls %v", loaded, err, calls)
+	}
+
+	if _, err := LinkToolWithStrategy(tool, StrategySkip, false); err != nil {
+		t.Fatalf("LinkToolWithStrategy() error = %v", err)
+	}
+	loaded, err := LoadLaunchAgents(tool, false)
+	if err != nil {
+		t.Fatalf("LoadLaunchAgents() error = %v", err)
+	}
+	// The user's own (conflicting, unlinked) plist is left alone
+	if len(loaded) != 1 || loaded[0] != agent {
+		t.Errorf("LoadLaunchAgents() = %v, want only %s", loaded, agent)
+	}
+	if want := []string{"unload " + agent, "load -w " + agent}; strings.Join(calls, "|") != strings.Join(want, "|") {
+		t.Errorf("launchctl calls = %v, want %v", calls, want)
+	}
+
+	calls = nil
+	launchctl = func(args ...string) error {
+		calls = append(calls, strings.Join(args, " "))
+		return errors.New("Could not find specified service")
+	}
+	unloaded, err := UnloadLaunchAgents(tool, false)
+	if err == nil || len(unloaded) != 0 || len(calls) != 1 || calls[0] != "unload -w "+agent {
+		t.Errorf("UnloadLaunchAgents() = %v, %v with calls %v", unloaded, err, calls)
+	}
+}
+
+func TestResolveConflictDryRunPreview(t *testing.T) {
+	tmpDir := t.TempDir()
+	source := filepath.Join(tmpDir, "gitconfig")
+	target := filepath.Join(tmpDir, ".gitconfig")
+	os.WriteFile(source, []byte("[user]\n  name = New\n"), 0644)
+	os.WriteFile(target, []byte("[user]\n  name = Old\n"), 0644)
+
+	for _, strategy := range []ConflictStrategy{StrategyBackup, StrategyOverwrite} {
+		result, err := ResolveConflict(source, target, strategy, true)
+		if err != nil {
+			t.Fatalf("%s: ResolveConflict() error = %v", strategy, err)
+		}
+		if !strings.Contains(result.Preview, "-  name = Old\n+  name = New\n") {
+			t.Errorf("%s: Preview =\n%s", strategy, result.Preview)
+		}
+	}
+
+	// Skipping changes nothing, so there is nothing to preview
+	if result, _ := ResolveConflict(source, target, StrategySkip, true); result.Preview != "" {
+		t.Errorf("skip: Preview = %q, want empty", result.Preview)
+	}
+	if data, _ := os.ReadFile(target); string(data) != "[user]\n  name = Old\n" {
+		t.Errorf("dry run modified the target: %q", data)
+	}
+}
+
+func TestResolveConflictBackupID(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	source := filepath.Join(tmpDir, "gitconfig")
+	target := filepath.Join(tmpDir, ".gitconfig")
+	os.WriteFile(source, []byte("new"), 0644)
+	os.WriteFile(target, []byte("old"), 0644)
+
+	op := backup.StartOperation()
+	result, err := ResolveConflict(source, target, StrategyBackup, false)
+	if err != nil {
+		t.Fatalf("ResolveConflict() error = %v", err)
+	}
+	if result.BackupID == "" {
+		t.Fatal("BackupID not set for StrategyBackup")
+	}
+	manifest, err := backup.GetBackupInfo(result.BackupID)
+	if err != nil {
+		t.Fatalf("GetBackupInfo() error = %v", err)
+	}
+	if manifest.Operation != op {
+		t.Errorf("Operation = %q, want %q", manifest.Operation, op)
+	}
+}
+
+func TestBinLinks(t *testing.T) {
+	toolRoot := t.TempDir()
+	binDir := filepath.Join(t.TempDir(), "bin")
+	os.MkdirAll(filepath.Join(toolRoot, "bin"), 0755)
+	os.WriteFile(filepath.Join(toolRoot, "bin", "deploy.sh"), []byte("#!/bin/sh\n"), 0644)
+	os.WriteFile(filepath.Join(toolRoot, "bin", "sync"), []byte("#!/bin/sh\n"), 0700)
+	vars := Variables{HomeDir: t.TempDir(), BinDir: binDir}
+
+	links, err := resolveBin(models.Bin{Source: "bin/deploy.sh", Name: "deploy"}, toolRoot, vars)
+	if err != nil || len(links) != 1 || links[0].Target != filepath.Join(binDir, "deploy") || !links[0].Executable {
+		t.Fatalf("resolveBin(named) = %+v, %v", links, err)
+	}
+	globbed, err := resolveBin(models.Bin{Source: "bin/*"}, toolRoot, vars)
+	if err != nil || len(globbed) != 2 || globbed[1].Target != filepath.Join(binDir, "sync") {
+		t.Fatalf("resolveBin(glob) = %+v, %v", globbed, err)
+	}
+	if _, err := resolveBin(models.Bin{Source: "bin/*", Name: "x"}, toolRoot, vars); err == nil {
+		t.Error("name with a glob source should be rejected")
+	}
+	if got := (Variables{HomeDir: "/home/u"}).binDir(); got != "/home/u/bin" {
+		t.Errorf("default binDir() = %s", got)
+	}
+
+	tool := &ToolConfig{Name: "scripts", Links: globbed}
+	if dirs := BinDirs(tool); len(dirs) != 1 || dirs[0] != binDir {
+		t.Errorf("BinDirs() = %v, want [%s]", dirs, binDir)
+	}
+
+	// Dry run reports without touching the file; 0700 is already executable
+	deploy := filepath.Join(toolRoot, "bin", "deploy.sh")
+	if changed, err := MarkExecutables(tool, true); err != nil || len(changed) != 1 || changed[0] != deploy {
+		t.Fatalf("MarkExecutables(dry-run) = %v, %v", changed, err)
+	}
+	// Unlinked targets are left alone
+	if changed, _ := MarkExecutables(tool, false); len(changed) != 0 {
+		t.Errorf("MarkExecutables() before linking = %v", changed)
+	}
+
+	if _, err := LinkToolWithStrategy(tool, StrategySkip, false); err != nil {
+		t.Fatalf("LinkToolWithStrategy() error = %v", err)
+	}
+	if changed, err := MarkExecutables(tool, false); err != nil || len(changed) != 1 {
+		t.Fatalf("MarkExecutables() = %v, %v", changed, err)
+	}
+	if info, _ := os.Stat(deploy); info.Mode().Perm() != 0755 {
+		t.Errorf("deploy.sh mode = %v, want 0755", info.Mode().Perm())
+	}
+
+	t.Setenv("PATH", "/usr/bin"+string(os.PathListSeparator)+binDir+"/")
+	if !InPath(binDir) || InPath(toolRoot) {
+		t.Error("InPath() should match cleaned PATH entries only")
+	}
+}
+
+func TestFindConflicts(t *testing.T) {
+	tmpDir := t.TempDir()
+	src := func(name, content string) string {
+		path := filepath.Join(tmpDir, "repo", name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(content), 0644)
+		return path
+	}
+	home := filepath.Join(tmpDir, "home")
+	os.MkdirAll(filepath.Join(home, "nvim"), 0755)
+	os.WriteFile(filepath.Join(home, "nvim", "init.lua"), []byte("old"), 0644)
+	os.WriteFile(filepath.Join(home, ".zshrc"), []byte("same"), 0644)
+	os.Symlink(filepath.Join(tmpDir, "gone"), filepath.Join(home, ".gitconfig"))
+
+	linked := src("tmux.conf", "x")
+	os.Symlink(linked, filepath.Join(home, ".tmux.conf"))
+
+	tool := &ToolConfig{Name: "dots", Links: []ResolvedLink{
+		{Source: src("nvim/init.lua", "new"), Target: filepath.Join(home, "nvim"), IsDir: true},
+		{Source: src("zshrc", "same"), Target: filepath.Join(home, ".zshrc")},
+		{Source: src("gitconfig", "g"), Target: filepath.Join(home, ".gitconfig")},
+		{Source: linked, Target: filepath.Join(home, ".tmux.conf")},
+		{Source: src("vimrc", "v"), Target: filepath.Join(home, ".vimrc")},
+	}}
+	tool.Links[0].Source = filepath.Dir(tool.Links[0].Source)
+
+	conflicts := FindConflicts([]*ToolConfig{tool})
+	if len(conflicts) != 3 {
+		t.Fatalf("FindConflicts() = %+v, want 3 (linked and free targets are not conflicts)", conflicts)
+	}
+	if c := conflicts[0]; c.Existing != "directory" || c.Files != 1 || c.Tool != "dots" {
+		t.Errorf("directory conflict = %+v", c)
+	}
+	if c := conflicts[1]; c.Existing != "file" || !c.Identical {
+		t.Errorf("identical file conflict = %+v", c)
+	}
+	if c := conflicts[2]; c.Existing != "broken symlink" || c.PointsTo != filepath.Join(tmpDir, "gone") {
+		t.Errorf("broken symlink conflict = %+v", c)
+	}
+
+	if total, linked, conflicts := CountLinks(tool); total != 5 || linked != 1 || conflicts != 3 {
+		t.Errorf("CountLinks() = %d, %d, %d, want 5, 1, 3", total, linked, conflicts)
+	}
+}
+
+func TestLinkOrder(t *testing.T) {
+	root := t.TempDir()
+	toolRoot := filepath.Join(root, "config", "nvim")
+	for _, rel := range []string{"config/init.lua", "config/lua/plugins.lua", "config/after/ftplugin.lua"} {
+		os.MkdirAll(filepath.Dir(filepath.Join(toolRoot, rel)), 0755)
+		if err := os.WriteFile(filepath.Join(toolRoot, rel), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	toml := `[tool]
+name = "nvim"
+
+[[link]]
+source = "config/init.lua"
+target = "{config_dir}/nvim/init.lua"
+order = 2
+
+[[link]]
+source = "config/lua"
+target = "{config_dir}/nvim/lua"
+contents = true
+order = 1
+
+[[link]]
+source = "config/after"
+target = "{config_dir}/nvim/after"
+`
+	if err := os.WriteFile(filepath.Join(toolRoot, "merlin.toml"), []byte(toml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	repo := &config.DotfilesRepo{Root: root, ConfigDir: filepath.Join(root, "config")}
+	vars := Variables{HomeDir: t.TempDir(), ConfigDir: t.TempDir()}
+
+	tool, err := DiscoverToolConfig(repo, "nvim", vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, _ := LinkToolWithStrategy(tool, StrategySkip, false)
+	var got []string
+	for _, r := range results {
+		got = append(got, filepath.Base(r.Target))
+	}
+	if want := "after,plugins.lua,init.lua"; strings.Join(got, ",") != want {
+		t.Errorf("link order = %v, want %s", got, want)
+	}
+
+	// Each order value finishes before the next starts, even when linked concurrently
+	links := make([]ResolvedLink, 3*linkBatchSize)
+	for i := range links {
+		links[i].Order = i / linkBatchSize
+	}
+	var mu sync.Mutex
+	var seen []int
+	runOrderedLinks(links, 8, func(l ResolvedLink) *LinkResult {
+		mu.Lock()
+		defer mu.Unlock()
+		seen = append(seen, l.Order)
+		return &LinkResult{}
+	})
+	for i := 1; i < len(seen); i++ {
+		if seen[i] < seen[i-1] {
+			t.Fatalf("order %d linked after order %d", seen[i-1], seen[i])
+		}
+	}
+}
+
+func TestLeftovers(t *testing.T) {
+	tmpDir := t.TempDir()
+	home := filepath.Join(tmpDir, "home")
+	configDir := filepath.Join(home, ".config")
+	nvim := filepath.Join(configDir, "nvim")
+	shared := filepath.Join(configDir, "zsh", "conf.d")
+	rc := filepath.Join(home, ".nvimrc")
+	linked := filepath.Join(home, ".vimrc")
+	os.MkdirAll(nvim, 0755)
+	os.MkdirAll(shared, 0755)
+	os.WriteFile(rc, []byte("set nu"), 0644)
+	os.Symlink(filepath.Join(tmpDir, "elsewhere"), linked)
+
+	tool := &ToolConfig{Name: "nvim", Links: []ResolvedLink{
+		{Target: nvim, Contents: true},
+		{Target: shared, Contents: true},
+		{Target: rc},
+		{Target: linked},
+		{Target: filepath.Join(home, ".missing")},
+		{Target: home, Contents: true},
+	}}
+	zsh := &ToolConfig{Name: "zsh", Links: []ResolvedLink{{Target: shared, Contents: true}}}
+	vars := Variables{HomeDir: home, ConfigDir: configDir}
+
+	got := Leftovers(tool, []*ToolConfig{tool, zsh}, vars)
+	want := []string{nvim, rc}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Leftovers = %v, want %v", got, want)
+	}
+}
+
+func TestIndependentGroups(t *testing.T) {
+	home := "/home/u"
+	vars := Variables{HomeDir: home, ConfigDir: home + "/.config"}
+	tool := func(name string, targets ...string) *ToolConfig {
+		tc := &ToolConfig{Name: name}
+		for _, target := range targets {
+			tc.Links = append(tc.Links, ResolvedLink{Target: target})
+		}
+		return tc
+	}
+	tools := []*ToolConfig{
+		tool("nvim", home+"/.config/nvim"),
+		tool("zsh", home+"/.zshrc", home+"/.config/zsh"),
+		tool("git", home+"/.config/git"),
+		tool("zsh-plugins", home+"/.config/zsh/conf.d"),           // inside zsh's target
+		tool("cursor", home+"/Library/Cursor/User/settings.json"), // same parent as vscode-keys
+		tool("vscode-keys", home+"/Library/Cursor/User/keybindings.json"),
+		tool("bins", home+"/bin/a"),
+	}
+
+	got := fmt.Sprint(IndependentGroups(tools, vars))
+	if want := "[[0] [1 3] [2] [4 5] [6]]"; got != want {
+		t.Errorf("IndependentGroups = %s, want %s", got, want)
+	}
+
+	for _, workers := range []int{1, 4} {
+		var mu sync.Mutex
+		var order []int
+		RunToolGroups(IndependentGroups(tools, vars), workers, func(i int) {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, i)
+		})
+		if len(order) != len(tools) {
+			t.Fatalf("workers=%d: ran %v", workers, order)
+		}
+		if slices.Index(order, 1) > slices.Index(order, 3) || slices.Index(order, 4) > slices.Index(order, 5) {
+			t.Errorf("workers=%d: tools of a group ran out of order: %v", workers, order)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package symlink
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SortByDependencies orders tools so each comes after the tools it lists in
+// [tool] dependencies. Dependencies that name no tool in the list (usually
+// packages) are ignored, and unrelated tools keep their relative order. A
+// dependency cycle is an error.
+func SortByDependencies(tools []*ToolConfig) ([]*ToolConfig, error) {
+	byName := make(map[string]*ToolConfig, len(tools))
+	for _, tool := range tools {
+		byName[tool.Name] = tool
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int, len(tools))
+	sorted := make([]*ToolConfig, 0, len(tools))
+	var path []string
+
+	var visit func(tool *ToolConfig) error
+	visit = func(tool *ToolConfig) error {
+		switch state[tool.Name] {
+		case visited:
+			return nil
+		case visiting:
+			cycle := append(path[indexOf(path, tool.Name):], tool.Name)
+			return fmt.Errorf("tool dependency cycle: %s", strings.Join(cycle, " → "))
+		}
+		state[tool.Name] = visiting
+		path = append(path, tool.Name)
+		for _, dep := range tool.Dependencies {
+			if depTool, ok := byName[dep]; ok {
+				if err := visit(depTool); err != nil {
+					return err
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[tool.Name] = visited
+		sorted = append(sorted, tool)
+		return nil
+	}
+
+	for _, tool := range tools {
+		if err := visit(tool); err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
+
+func indexOf(names []string, name string) int {
+	for i, n := range names {
+		if n == name {
+			return i
+		}
+	}
+	return 0
+}
@@ -0,0 +1,179 @@
+package symlink
+
+import (
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// linkWorkers bounds concurrent symlink creation. Each link is a couple of
+// metadata syscalls, so a few workers per CPU keep the filesystem busy
+// without piling up goroutines for trees with thousands of files.
+var linkWorkers = min(runtime.NumCPU()*4, 32)
+
+// ToolWorkers bounds how many tools link --all links at the same time; each
+// tool may in turn use linkWorkers for its own links
+var ToolWorkers = min(runtime.NumCPU(), 8)
+
+// linkBatchSize is how many links a worker claims at a time; below one batch
+// the pool is skipped entirely since goroutine overhead would dominate.
+const linkBatchSize = 64
+
+// runLinks calls link(i) for i in [0, n) on up to workers goroutines. Workers
+// claim batches of indexes and write each result into its own slot, so the
+// returned slice is in index order no matter which worker finishes first.
+func runLinks(n, workers int, link func(i int) *LinkResult) []*LinkResult {
+	results := make([]*LinkResult, n)
+	if workers > (n+linkBatchSize-1)/linkBatchSize {
+		workers = (n + linkBatchSize - 1) / linkBatchSize
+	}
+	if workers <= 1 {
+		for i := range results {
+			results[i] = link(i)
+		}
+		return results
+	}
+
+	batches := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for start := range batches {
+				for i := start; i < min(start+linkBatchSize, n); i++ {
+					results[i] = link(i)
+				}
+			}
+		}()
+	}
+	for start := 0; start < n; start += linkBatchSize {
+		batches <- start
+	}
+	close(batches)
+	wg.Wait()
+	return results
+}
+
+// runOrderedLinks runs links, already sorted by Order, one order value at a
+// time: links sharing a value go through runLinks concurrently, and the next
+// value starts only once they are done. Results keep the order of links.
+func runOrderedLinks(links []ResolvedLink, workers int, link func(l ResolvedLink) *LinkResult) []*LinkResult {
+	results := make([]*LinkResult, 0, len(links))
+	for start := 0; start < len(links); {
+		end := start + 1
+		for end < len(links) && links[end].Order == links[start].Order {
+			end++
+		}
+		group := links[start:end]
+		results = append(results, runLinks(len(group), workers, func(i int) *LinkResult {
+			return link(group[i])
+		})...)
+		start = end
+	}
+	return results
+}
+
+// sortLinks orders a tool's links by their [[link]] order, keeping file
+// order between equal values
+func sortLinks(links []ResolvedLink) {
+	sort.SliceStable(links, func(i, j int) bool { return links[i].Order < links[j].Order })
+}
+
+// IndependentGroups splits tools into groups of indexes that can be linked
+// concurrently with every other group. Tools whose targets collide (the same
+// path, or one inside another, e.g. contents links merging into one
+// directory) or share a parent directory land in the same group, in their
+// original order. Sharing {home_dir}, {config_dir} or {bin_dir} doesn't count:
+// links there only ever create the directory, which is safe to race.
+func IndependentGroups(tools []*ToolConfig, vars Variables) [][]int {
+	roots := vars.roots()
+	parent := make([]int, len(tools))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	for i := range tools {
+		for j := i + 1; j < len(tools); j++ {
+			if find(i) != find(j) && targetsOverlap(tools[i], tools[j], roots) {
+				parent[find(j)] = find(i)
+			}
+		}
+	}
+
+	var groups [][]int
+	index := make(map[int]int)
+	for i := range tools {
+		root := find(i)
+		g, ok := index[root]
+		if !ok {
+			g = len(groups)
+			index[root] = g
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], i)
+	}
+	return groups
+}
+
+// targetsOverlap reports whether linking a and b at the same time could race
+func targetsOverlap(a, b *ToolConfig, roots []string) bool {
+	for _, la := range a.Links {
+		ta := filepath.Clean(la.Target)
+		for _, lb := range b.Links {
+			tb := filepath.Clean(lb.Target)
+			if within(ta, tb) || within(tb, ta) {
+				return true
+			}
+			if dir := filepath.Dir(ta); dir == filepath.Dir(tb) && !slices.Contains(roots, dir) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RunToolGroups calls link(i) for every index in groups on up to workers
+// goroutines. The tools of one group run one after another in group order;
+// groups run concurrently. link must only touch state of its own tool.
+func RunToolGroups(groups [][]int, workers int, link func(i int)) {
+	if workers > len(groups) {
+		workers = len(groups)
+	}
+	if workers <= 1 {
+		for _, group := range groups {
+			for _, i := range group {
+				link(i)
+			}
+		}
+		return
+	}
+
+	queue := make(chan []int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for group := range queue {
+				for _, i := range group {
+					link(i)
+				}
+			}
+		}()
+	}
+	for _, group := range groups {
+		queue <- group
+	}
+	close(queue)
+	wg.Wait()
+}
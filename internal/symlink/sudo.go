@@ -0,0 +1,74 @@
+package symlink
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/ildx/merlin/internal/protect"
+)
+
+// IsPermissionError reports whether err is EPERM/EACCES, typically from
+// linking into system locations such as /etc or /usr/local.
+func IsPermissionError(err error) bool {
+	return errors.Is(err, fs.ErrPermission)
+}
+
+// markPermissionDenied flags a failed result and appends remediation guidance
+func markPermissionDenied(result *LinkResult, err error) {
+	if !IsPermissionError(err) {
+		return
+	}
+	result.PermissionDenied = true
+	result.Message = fmt.Sprintf("permission denied; re-run with --sudo or run: sudo ln -sn -- %q %q", result.Source, result.Target)
+}
+
+// CreateSymlinkSudo creates a symlink via `sudo ln -sn`, creating the parent
+// directory with `sudo mkdir -p` first. Anything at the target, including a
+// symlink to a directory, makes ln fail instead of being replaced as root,
+// so conflicts stay with the conflict strategy; -- keeps paths starting with
+// "-" from being read as options. The sudo password prompt is attached to the
+// current terminal. Callers must obtain explicit user confirmation.
+func CreateSymlinkSudo(source, target string) error {
+	if err := protect.Check(target); err != nil {
+		return err
+	}
+	if _, err := exec.LookPath("sudo"); err != nil {
+		return fmt.Errorf("sudo not available: %w", err)
+	}
+	if err := runSudo("mkdir", "-p", "--", filepath.Dir(target)); err != nil {
+		return fmt.Errorf("sudo mkdir failed: %w", err)
+	}
+	if err := runSudo("ln", "-sn", "--", source, target); err != nil {
+		return fmt.Errorf("sudo ln failed: %w", err)
+	}
+	return nil
+}
+
+func runSudo(args ...string) error {
+	cmd := exec.Command("sudo", args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// EscalateWithSudo retries permission-denied results through CreateSymlinkSudo,
+// updating each result in place. Other results are left untouched.
+func EscalateWithSudo(results []*LinkResult) {
+	for _, r := range results {
+		if r == nil || !r.PermissionDenied {
+			continue
+		}
+		if err := CreateSymlinkSudo(r.Source, r.Target); err != nil {
+			r.Message = err.Error()
+			continue
+		}
+		r.Status = LinkStatusSuccess
+		r.PermissionDenied = false
+		r.Message = "symlink created with sudo"
+	}
+}
@@ -0,0 +1,49 @@
+package symlink
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// xdgDirs are the XDG base directory variables: the environment variable that
+// overrides each and its default below the home directory. macOS has no
+// native equivalent, so the same defaults apply there as on Linux.
+var xdgDirs = []struct{ variable, env, fallback string }{
+	{"{xdg_config}", "XDG_CONFIG_HOME", ".config"},
+	{"{xdg_data}", "XDG_DATA_HOME", ".local/share"},
+	{"{xdg_cache}", "XDG_CACHE_HOME", ".cache"},
+	{"{xdg_state}", "XDG_STATE_HOME", ".local/state"},
+}
+
+// XDGDirs returns the {xdg_*} variables resolved for homeDir. An override
+// must be absolute; relative values are ignored, as the spec requires.
+func XDGDirs(homeDir string) map[string]string {
+	dirs := make(map[string]string, len(xdgDirs))
+	for _, d := range xdgDirs {
+		if dir := os.Getenv(d.env); filepath.IsAbs(dir) {
+			dirs[d.variable] = filepath.Clean(dir)
+		} else {
+			dirs[d.variable] = filepath.Join(homeDir, d.fallback)
+		}
+	}
+	return dirs
+}
+
+// SuggestXDG rewrites a target that hard-codes an XDG default location
+// (e.g. "~/.config/nvim") to use the matching variable ("{xdg_config}/nvim").
+// It reports false for targets that don't.
+func SuggestXDG(target string) (string, bool) {
+	for _, home := range []string{"~", "$HOME", "${HOME}", "{home_dir}"} {
+		rest, ok := strings.CutPrefix(target, home+"/")
+		if !ok {
+			continue
+		}
+		for _, d := range xdgDirs {
+			if sub, ok := strings.CutPrefix(rest, d.fallback); ok && (sub == "" || strings.HasPrefix(sub, "/")) {
+				return d.variable + sub, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,135 @@
+package system
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// brewPrefixes are Homebrew's default prefixes per architecture. On Apple
+// Silicon an x86_64 brew under /usr/local runs through Rosetta next to the
+// native one.
+var brewPrefixes = map[string]string{
+	"arm64":  "/opt/homebrew",
+	"x86_64": "/usr/local",
+}
+
+// BrewArchs returns the architectures brew.toml arch hints may name
+func BrewArchs() []string {
+	return []string{"arm64", "x86_64"}
+}
+
+// BrewInstall is one Homebrew installation found on the machine
+type BrewInstall struct {
+	Path   string `json:"path"`   // brew binary
+	Prefix string `json:"prefix"` // e.g. /opt/homebrew
+	Arch   string `json:"arch"`   // arm64 or x86_64
+}
+
+var brewPath struct {
+	sync.Mutex
+	configured string
+	resolved   string
+}
+
+// SetBrewPath makes path (settings.brew.brew_path, "~" expanded) the brew
+// every merlin command runs; empty restores detection
+func SetBrewPath(path string) {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[2:])
+		}
+	}
+	brewPath.Lock()
+	defer brewPath.Unlock()
+	brewPath.configured, brewPath.resolved = path, ""
+}
+
+// BrewPath returns the brew binary merlin runs: the configured brew_path,
+// else the native brew for this machine's architecture when installed (so an
+// x86_64 brew earlier on PATH isn't picked on Apple Silicon), else "brew"
+// from PATH
+func BrewPath() string {
+	brewPath.Lock()
+	defer brewPath.Unlock()
+	if brewPath.configured != "" {
+		return brewPath.configured
+	}
+	if brewPath.resolved == "" {
+		brewPath.resolved = "brew"
+		if prefix, ok := brewPrefixes[nativeArch()]; ok && isExecutable(filepath.Join(prefix, "bin", "brew")) {
+			brewPath.resolved = filepath.Join(prefix, "bin", "brew")
+		}
+	}
+	return brewPath.resolved
+}
+
+// BrewPathFor returns the brew binary of the installation for arch, or ""
+// when arch has no known prefix
+func BrewPathFor(arch string) string {
+	prefix, ok := brewPrefixes[arch]
+	if !ok {
+		return ""
+	}
+	return filepath.Join(prefix, "bin", "brew")
+}
+
+// BrewPrefix returns the prefix of a brew binary (the directory above bin)
+func BrewPrefix(path string) string {
+	if !filepath.IsAbs(path) {
+		found, err := exec.LookPath(path)
+		if err != nil {
+			return ""
+		}
+		path = found
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil && filepath.Base(filepath.Dir(resolved)) == "bin" {
+		path = resolved
+	}
+	return filepath.Dir(filepath.Dir(path))
+}
+
+// DetectBrews lists the Homebrew installations at the default prefixes,
+// native architecture first
+func DetectBrews() []BrewInstall {
+	var installs []BrewInstall
+	for _, arch := range BrewArchs() {
+		path := BrewPathFor(arch)
+		if !isExecutable(path) {
+			continue
+		}
+		install := BrewInstall{Path: path, Prefix: brewPrefixes[arch], Arch: arch}
+		if arch == nativeArch() {
+			installs = append([]BrewInstall{install}, installs...)
+		} else {
+			installs = append(installs, install)
+		}
+	}
+	return installs
+}
+
+// BrewArchCommand returns the command running brew with args for arch:
+// plain brew when arch is empty or native, otherwise brew at arch's prefix
+// under arch(1), so x86_64 packages install through Rosetta
+func BrewArchCommand(arch string, args ...string) (string, []string) {
+	if arch == "" || arch == nativeArch() {
+		return "brew", args
+	}
+	return "arch", append([]string{"-" + arch, BrewPathFor(arch)}, args...)
+}
+
+// nativeArch returns the machine's architecture in Homebrew's terms
+func nativeArch() string {
+	if runtime.GOARCH == "amd64" {
+		return "x86_64"
+	}
+	return runtime.GOARCH
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir() && info.Mode()&0111 != 0
+}
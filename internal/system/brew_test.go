@@ -0,0 +1,55 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestBrewPath(t *testing.T) {
+	defer SetBrewPath("")
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	SetBrewPath("~/homebrew/bin/brew")
+	if got, want := BrewPath(), filepath.Join(home, "homebrew", "bin", "brew"); got != want {
+		t.Errorf("BrewPath() = %q, want configured %q", got, want)
+	}
+
+	SetBrewPath("")
+	if got := BrewPath(); got != "brew" && got != BrewPathFor(nativeArch()) {
+		t.Errorf("BrewPath() = %q, want the native brew or brew from PATH", got)
+	}
+}
+
+func TestBrewArchCommand(t *testing.T) {
+	if name, args := BrewArchCommand("", "install", "jq"); name != "brew" || !slices.Equal(args, []string{"install", "jq"}) {
+		t.Errorf("no hint: got %s %v", name, args)
+	}
+	if name, _ := BrewArchCommand(nativeArch(), "install", "jq"); name != "brew" {
+		t.Errorf("native hint: got %s, want brew", name)
+	}
+
+	other := "x86_64"
+	if nativeArch() == other {
+		other = "arm64"
+	}
+	name, args := BrewArchCommand(other, "install", "jq")
+	want := []string{"-" + other, BrewPathFor(other), "install", "jq"}
+	if name != "arch" || !slices.Equal(args, want) {
+		t.Errorf("foreign hint: got %s %v, want arch %v", name, args, want)
+	}
+}
+
+func TestBrewPrefix(t *testing.T) {
+	dir := t.TempDir()
+	brew := filepath.Join(dir, "bin", "brew")
+	os.MkdirAll(filepath.Dir(brew), 0755)
+	os.WriteFile(brew, []byte("#!/bin/sh\n"), 0755)
+
+	want, _ := filepath.EvalSymlinks(dir)
+	if got := BrewPrefix(brew); got != want {
+		t.Errorf("BrewPrefix(%q) = %q, want %q", brew, got, want)
+	}
+}
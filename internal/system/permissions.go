@@ -0,0 +1,84 @@
+package system
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/ildx/merlin/internal/models"
+)
+
+// PermissionStatus is what a preflight check could tell about a permission
+type PermissionStatus string
+
+const (
+	PermissionGranted PermissionStatus = "granted"
+	PermissionMissing PermissionStatus = "missing"
+	PermissionUnknown PermissionStatus = "unknown" // can't be probed from a CLI; check System Settings
+)
+
+// PermissionCheck is the preflight result for one macOS privacy permission,
+// as held by the terminal merlin runs in
+type PermissionCheck struct {
+	Permission string           `json:"permission"`
+	Label      string           `json:"label"` // Name of the System Settings list, e.g. "Full Disk Access"
+	Status     PermissionStatus `json:"status"`
+	Pane       string           `json:"pane"` // URL opening the Privacy & Security pane
+}
+
+// permissionPanes maps each permission to its System Settings list and the
+// Privacy & Security anchor that opens it
+var permissionPanes = map[string]struct{ label, anchor string }{
+	models.PermissionFullDiskAccess:  {"Full Disk Access", "Privacy_AllFiles"},
+	models.PermissionScreenRecording: {"Screen Recording", "Privacy_ScreenCapture"},
+	models.PermissionAccessibility:   {"Accessibility", "Privacy_Accessibility"},
+	models.PermissionInputMonitoring: {"Input Monitoring", "Privacy_ListenEvent"},
+}
+
+// IsKnownPermission reports whether name is one of the models.Permission* values
+func IsKnownPermission(name string) bool {
+	_, ok := permissionPanes[name]
+	return ok
+}
+
+// fullDiskAccessProbe returns a file only readable with Full Disk Access: the
+// user's TCC database
+var fullDiskAccessProbe = func() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, "Library", "Application Support", "com.apple.TCC", "TCC.db")
+}
+
+// CheckPermission reports whether the terminal holds permission. Only Full
+// Disk Access can be probed (by reading a protected file); the others need an
+// app's own entitlement check and are reported as unknown.
+func CheckPermission(permission string) *PermissionCheck {
+	pane := permissionPanes[permission]
+	check := &PermissionCheck{
+		Permission: permission,
+		Label:      pane.label,
+		Status:     PermissionUnknown,
+		Pane:       "x-apple.systempreferences:com.apple.preference.security?" + pane.anchor,
+	}
+	if check.Label == "" {
+		check.Label = permission
+	}
+	if permission == models.PermissionFullDiskAccess && IsMacOS() {
+		check.Status = probeReadable(fullDiskAccessProbe())
+	}
+	return check
+}
+
+// probeReadable maps reading a TCC-protected file to a permission status: a
+// denied read means the permission is missing, a missing file says nothing
+func probeReadable(path string) PermissionStatus {
+	f, err := os.Open(path)
+	if err == nil {
+		f.Close()
+		return PermissionGranted
+	}
+	if errors.Is(err, fs.ErrPermission) {
+		return PermissionMissing
+	}
+	return PermissionUnknown
+}
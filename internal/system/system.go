@@ -2,19 +2,22 @@ package system
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
 	"strings"
+	"syscall"
 )
 
 // CommandCheck represents the result of checking if a command exists
 type CommandCheck struct {
-	Name      string
-	Exists    bool
-	Path      string
-	Version   string
-	Error     error
+	Name    string
+	Exists  bool
+	Path    string
+	Version string
+	Error   error
 }
 
 // CheckCommand checks if a command exists in the system PATH
@@ -41,9 +44,11 @@ func CheckCommand(name string) *CommandCheck {
 }
 
 // CheckHomebrew checks if Homebrew is installed and returns detailed info
+// about the brew merlin runs (see BrewPath)
 func CheckHomebrew() *CommandCheck {
-	check := CheckCommand("brew")
-	
+	check := CheckCommand(BrewPath())
+	check.Name = "brew"
+
 	if !check.Exists {
 		check.Error = fmt.Errorf("Homebrew is not installed. Install it from https://brew.sh")
 		return check
@@ -51,7 +56,7 @@ func CheckHomebrew() *CommandCheck {
 
 	// Get brew version
 	if check.Version == "" {
-		cmd := exec.Command("brew", "--version")
+		cmd := exec.Command(BrewPath(), "--version")
 		if output, err := cmd.Output(); err == nil {
 			// Parse "Homebrew X.Y.Z" from output
 			lines := strings.Split(string(output), "\n")
@@ -67,7 +72,7 @@ func CheckHomebrew() *CommandCheck {
 // CheckMAS checks if mas-cli is installed
 func CheckMAS() *CommandCheck {
 	check := CheckCommand("mas")
-	
+
 	if !check.Exists {
 		check.Error = fmt.Errorf("mas-cli is not installed. Install it with: brew install mas")
 		return check
@@ -80,7 +85,7 @@ func CheckMAS() *CommandCheck {
 func getCommandVersion(name string) string {
 	// Try common version flags
 	versionFlags := []string{"--version", "-v", "version"}
-	
+
 	for _, flag := range versionFlags {
 		cmd := exec.Command(name, flag)
 		output, err := cmd.Output()
@@ -92,7 +97,7 @@ func getCommandVersion(name string) string {
 			}
 		}
 	}
-	
+
 	return ""
 }
 
@@ -176,7 +181,49 @@ func FormatCommandCheck(check *CommandCheck) string {
 		}
 		return info
 	}
-	
+
 	return fmt.Sprintf("✗ %s (not found)", check.Name)
 }
 
+// IsOffline reports whether MERLIN_OFFLINE requests skipping network operations
+func IsOffline() bool {
+	switch strings.ToLower(os.Getenv("MERLIN_OFFLINE")) {
+	case "1", "true", "yes":
+		return true
+	}
+	return false
+}
+
+// FreeDiskSpace returns the bytes available to the current user on the volume
+// containing path
+func FreeDiskSpace(path string) (int64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, fmt.Errorf("statfs %s: %w", path, err)
+	}
+	return int64(st.Bavail) * int64(st.Bsize), nil
+}
+
+// DirSize returns the total size of regular files under path. Symlinks are not
+// followed; a missing path has size 0.
+func DirSize(path string) (int64, error) {
+	var total int64
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil // Removed while walking
+		}
+		total += info.Size()
+		return nil
+	})
+	return total, err
+}
@@ -1,6 +1,8 @@
 package system
 
 import (
+	"os"
+	"path/filepath"
 	"runtime"
 	"testing"
 )
@@ -240,6 +242,26 @@ func TestFormatCommandCheck(t *testing.T) {
 	})
 }
 
+func TestDirSize(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a"), make([]byte, 100), 0644)
+	os.MkdirAll(filepath.Join(dir, "sub"), 0755)
+	os.WriteFile(filepath.Join(dir, "sub", "b"), make([]byte, 50), 0644)
+	os.Symlink("/usr", filepath.Join(dir, "link"))
+
+	size, err := DirSize(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != 150 {
+		t.Errorf("expected 150 bytes, got %d", size)
+	}
+
+	if size, err := DirSize(filepath.Join(dir, "missing")); err != nil || size != 0 {
+		t.Errorf("missing path: got %d, %v", size, err)
+	}
+}
+
 // Helper function
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > len(substr) && 
@@ -256,3 +278,28 @@ func someContains(s, substr string) bool {
 	return false
 }
 
+
+func TestCheckPermission(t *testing.T) {
+	tmp := t.TempDir()
+	readable := filepath.Join(tmp, "TCC.db")
+	if err := os.WriteFile(readable, []byte("db"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := probeReadable(readable); got != PermissionGranted {
+		t.Errorf("readable file: got %s, want granted", got)
+	}
+	if got := probeReadable(filepath.Join(tmp, "missing.db")); got != PermissionUnknown {
+		t.Errorf("missing file: got %s, want unknown", got)
+	}
+
+	check := CheckPermission("screen_recording")
+	if check.Label != "Screen Recording" || check.Status != PermissionUnknown {
+		t.Errorf("screen_recording: got %+v", check)
+	}
+	if check.Pane != "x-apple.systempreferences:com.apple.preference.security?Privacy_ScreenCapture" {
+		t.Errorf("screen_recording pane = %s", check.Pane)
+	}
+	if IsKnownPermission("camera") {
+		t.Error("camera should not be a known permission")
+	}
+}
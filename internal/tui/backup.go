@@ -278,13 +278,18 @@ func (m BackupRestoreModel) Init() tea.Cmd {
 
 func (m BackupRestoreModel) restore() tea.Cmd {
 	return func() tea.Msg {
-		err := backup.RestoreBackup(m.manifest.ID, m.selectedFiles)
-		return backupRestoreDoneMsg{err: err}
+		safety, err := backup.CreateSafetyBackup(m.manifest.ID, m.selectedFiles, nil)
+		if err != nil {
+			return backupRestoreDoneMsg{err: fmt.Errorf("create safety backup: %w", err)}
+		}
+		err = backup.RestoreBackup(m.manifest.ID, m.selectedFiles)
+		return backupRestoreDoneMsg{safety: safety, err: err}
 	}
 }
 
 type backupRestoreDoneMsg struct {
-	err error
+	safety *backup.BackupManifest
+	err    error
 }
 
 func (m BackupRestoreModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -297,6 +302,9 @@ func (m BackupRestoreModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		} else {
 			m.status = fmt.Sprintf("❌ Restore failed: %v", msg.err)
 		}
+		if msg.safety != nil {
+			m.status += fmt.Sprintf("\n   Previous files saved to backup %s", msg.safety.ID)
+		}
 		return m, tea.Quit
 
 	case tea.KeyMsg:
@@ -12,6 +12,7 @@ import (
 type ConfigItem struct {
 	Name        string
 	Description string
+	Details     string // notes and README shown in the detail pane
 	IsLinked    bool
 	HasConflict bool
 	Selected    bool
@@ -29,6 +30,7 @@ type ConfigSelectorModel struct {
 	width      int
 	height     int
 	viewOffset int
+	showInfo   bool // detail pane for the cursor item
 }
 
 // NewConfigSelectorModel creates a new config selector
@@ -94,6 +96,10 @@ func (m ConfigSelectorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.items[i].Selected = false
 			}
 
+		case "i":
+			m.showInfo = !m.showInfo
+			return m, nil
+
 		case "enter":
 			m.confirmed = true
 			return m, tea.Quit
@@ -162,6 +168,19 @@ func (m ConfigSelectorModel) View() string {
 				Render(item.Description)
 			s.WriteString(desc + "\n")
 		}
+		if i == m.cursor && m.showInfo {
+			details := item.Details
+			if details == "" {
+				details = "No notes or README for this tool."
+			}
+			pane := lipgloss.NewStyle().
+				Foreground(mutedColor).
+				Border(lipgloss.NormalBorder(), false, false, false, true).
+				MarginLeft(8).
+				PaddingLeft(1).
+				Render(details)
+			s.WriteString(pane + "\n")
+		}
 	}
 
 	// Scroll indicator
@@ -183,7 +202,7 @@ func (m ConfigSelectorModel) View() string {
 
 	// Help
 	actionText := m.action
-	help := helpStyle.Render(fmt.Sprintf("\n↑/↓: navigate • space: toggle • a: all • n: none • enter: %s • esc: cancel", actionText))
+	help := helpStyle.Render(fmt.Sprintf("\n↑/↓: navigate • space: toggle • a: all • n: none • i: info • enter: %s • esc: cancel", actionText))
 	s.WriteString(help)
 
 	return boxStyle.Render(s.String())
@@ -2,36 +2,40 @@ package tui
 
 import (
 	"fmt"
+	"maps"
 	"os"
-	"path/filepath"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ildx/merlin/internal/cli"
 	"github.com/ildx/merlin/internal/config"
 	"github.com/ildx/merlin/internal/installer"
+	"github.com/ildx/merlin/internal/machine"
 	"github.com/ildx/merlin/internal/models"
 	"github.com/ildx/merlin/internal/parser"
 	"github.com/ildx/merlin/internal/scripts"
 	"github.com/ildx/merlin/internal/symlink"
+	"github.com/ildx/merlin/internal/system"
 )
 
 // LaunchPackageInstaller shows package selection and installation
 func LaunchPackageInstaller() error {
+	if system.IsOffline() {
+		return fmt.Errorf("offline mode: package installation requires network access")
+	}
+
 	// Find dotfiles repo
 	repo, err := config.FindDotfilesRepo()
 	if err != nil {
 		return fmt.Errorf("dotfiles repository not found: %w", err)
 	}
 
-	// Parse brew.toml
-	brewPath := filepath.Join(repo.GetToolConfigDir("brew"), "brew.toml")
-	if _, err := os.Stat(brewPath); os.IsNotExist(err) {
-		return fmt.Errorf("brew.toml not found at %s", brewPath)
-	}
-
-	brewConfig, err := parser.ParseBrewTOML(brewPath)
+	// Parse brew.toml and brew.d/*.toml
+	brewConfig, err := parser.ParseBrewConfig(repo.GetToolConfigDir("brew"))
 	if err != nil {
-		return fmt.Errorf("failed to parse brew.toml: %w", err)
+		return err
 	}
+	brewConfig = brewConfig.ForHost(machine.CurrentHost(repo))
 
 	// Show package type selection menu
 	typeMenu := NewPackageTypeMenu()
@@ -108,9 +112,28 @@ func LaunchPackageInstaller() error {
 		}
 	}
 
+	// Remember the pick so it can be replayed with install brew --selection
+	if path, err := installer.LastSelectionPath(); err == nil {
+		selection := &installer.Selection{}
+		if selectedType != "casks" {
+			selection.Formulae = installer.PackageNames(formulae)
+		}
+		if selectedType != "formulae" {
+			selection.Casks = installer.PackageNames(casks)
+		}
+		if err := installer.SaveSelection(path, selection); err != nil {
+			cli.Warning("Could not save selection: %v", err)
+		} else {
+			fmt.Println(cli.Dim(fmt.Sprintf("Selection saved; replay with: merlin install brew --selection %s", path)))
+		}
+	}
+
 	// Install packages
 	fmt.Println("\n📦 Installing selected packages...")
-	brewInstaller := installer.NewBrewInstaller(false, true)
+	brewInstaller := installer.NewBrewInstaller(false, cli.VerbosityStream)
+	if rootConfig, err := parser.ParseRootMerlinTOML(repo.GetRootMerlinConfig()); err == nil {
+		brewInstaller.Retry = installer.RetryPolicy{Retries: rootConfig.Settings.InstallRetries}
+	}
 
 	var formulaeResults, caskResults []*installer.InstallResult
 
@@ -235,6 +258,7 @@ func LaunchConfigManager() error {
 		configItems[i] = ConfigItem{
 			Name:        tool.Name,
 			Description: tool.Description,
+			Details:     toolDetails(tool),
 			IsLinked:    false, // TODO: Check actual link status
 			HasConflict: false,
 			Selected:    false,
@@ -350,6 +374,22 @@ func printUnlinkResults(results []*symlink.UnlinkResult) {
 	}
 }
 
+// toolDetails joins a tool's notes and README for the selector's detail pane
+func toolDetails(tool *symlink.ToolConfig) string {
+	var parts []string
+	if notes := strings.TrimSpace(tool.Notes); notes != "" {
+		parts = append(parts, notes)
+	}
+	if tool.Readme != "" {
+		if data, err := os.ReadFile(tool.Readme); err == nil {
+			if text := strings.TrimSpace(string(data)); text != "" {
+				parts = append(parts, text)
+			}
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
 // LaunchScriptRunner shows tool and script selection, then runs scripts
 func LaunchScriptRunner() error {
 	// Find dotfiles repo
@@ -442,9 +482,11 @@ func LaunchScriptRunner() error {
 	}
 
 	toolRoot := repo.GetToolRoot(selectedTool.ToolName)
-	scriptDir := filepath.Join(toolRoot, toolConfig.Scripts.Directory)
-	if toolConfig.Scripts.Directory == "" {
-		scriptDir = filepath.Join(toolRoot, "scripts")
+	scriptDir := scripts.ScriptDirectory(toolRoot, toolConfig)
+
+	// New or changed scripts must be approved before they run
+	if err := scripts.ConfirmUntrusted(selectedTool.ToolName, scriptDir, selectedScripts, os.Stdin, os.Stdout); err != nil {
+		return err
 	}
 
 	// Create script runner
@@ -452,7 +494,23 @@ func LaunchScriptRunner() error {
 		"MERLIN_TOOL":      selectedTool.ToolName,
 		"MERLIN_TOOL_ROOT": toolRoot,
 	}
-	runner := scripts.NewScriptRunner(toolRoot, env, false, false, os.Stdout)
+	if rootConfig, err := parser.ParseRootMerlinTOML(repo.GetRootMerlinConfig()); err == nil {
+		if vars, err := symlink.GetVariablesFromRoot(rootConfig); err == nil {
+			env = scripts.GetDefaultEnvironment(toolRoot, selectedTool.ToolName, vars.HomeDir, vars.ConfigDir)
+		}
+	}
+	runner := scripts.NewScriptRunner(toolRoot, env, false, cli.VerbosityNormal, os.Stdout)
+
+	// Params are asked for before the progress UI takes over the terminal
+	prompt := scripts.PromptParams(os.Stdin, os.Stdout)
+	runner.Params = make(map[string]string)
+	for _, item := range selectedScripts {
+		values, err := scripts.ResolveParams(item, runner.Params, prompt)
+		if err != nil {
+			return err
+		}
+		maps.Copy(runner.Params, values)
+	}
 
 	// Run scripts with progress UI
 	runnerModel := NewScriptRunnerModel(
@@ -460,27 +518,38 @@ func LaunchScriptRunner() error {
 		toolRoot,
 		scriptDir,
 		selectedScripts,
+		toolConfig.Scripts.Env,
 		runner,
 	)
-	p = tea.NewProgram(runnerModel, tea.WithAltScreen())
-	finalModel, err = p.Run()
+	_, err = RunScripts(runnerModel)
+	return err
+}
+
+// RunScripts runs model's scripts with the progress UI. When scripts fail,
+// their logs are shown and a summary is printed once the viewer closes. It
+// returns the failed executions.
+func RunScripts(model ScriptRunnerModel) ([]ScriptExecution, error) {
+	finalModel, err := tea.NewProgram(model, tea.WithAltScreen()).Run()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	runnerFinal, ok := finalModel.(ScriptRunnerModel)
-	if !ok {
-		return nil
+	if !ok || !runnerFinal.HasFailures() {
+		return nil, nil
 	}
 
-	// Check for failures and optionally offer retry
-	if runnerFinal.HasFailures() {
-		failed := runnerFinal.GetFailedScripts()
-		fmt.Printf("\n⚠ %d script(s) failed:\n", len(failed))
-		for _, exec := range failed {
-			fmt.Printf("  • %s: %v\n", exec.Script.File, exec.Error)
+	// Page through the failed scripts' output, then leave a summary behind
+	failed := runnerFinal.GetFailedScripts()
+	if _, err := tea.NewProgram(NewScriptLogModel(failed), tea.WithAltScreen()).Run(); err != nil {
+		return failed, err
+	}
+	fmt.Printf("\n⚠ %d script(s) failed:\n", len(failed))
+	for _, exec := range failed {
+		fmt.Printf("  • %s/%s: %v\n", exec.Tool, exec.Script.File, exec.Error)
+		if exec.LogPath != "" {
+			fmt.Printf("    log: %s\n", exec.LogPath)
 		}
 	}
-
-	return nil
+	return failed, nil
 }
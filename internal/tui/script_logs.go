@@ -0,0 +1,104 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ScriptLogModel pages through the output of failed scripts after a run
+type ScriptLogModel struct {
+	failed   []ScriptExecution
+	current  int
+	viewport viewport.Model
+	ready    bool
+}
+
+// NewScriptLogModel creates a log viewer for the given failed executions
+func NewScriptLogModel(failed []ScriptExecution) ScriptLogModel {
+	return ScriptLogModel{failed: failed}
+}
+
+// scriptLogContent returns the saved log of exec, falling back to the output
+// kept in memory when the log can't be read
+func scriptLogContent(exec ScriptExecution) string {
+	if exec.LogPath != "" {
+		if data, err := os.ReadFile(exec.LogPath); err == nil {
+			return string(data)
+		}
+	}
+	if strings.TrimSpace(exec.Output) != "" {
+		return exec.Output
+	}
+	return dimStyle.Render("(no output captured)")
+}
+
+func (m ScriptLogModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m ScriptLogModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		// Title, error and help lines take the rest of the screen
+		height := max(msg.Height-8, 3)
+		if !m.ready {
+			m.viewport = viewport.New(msg.Width-4, height)
+			m.viewport.SetContent(scriptLogContent(m.failed[m.current]))
+			m.ready = true
+		} else {
+			m.viewport.Width = msg.Width - 4
+			m.viewport.Height = height
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q", "esc":
+			return m, tea.Quit
+		case "tab", "n":
+			m.show((m.current + 1) % len(m.failed))
+			return m, nil
+		case "shift+tab", "p":
+			m.show((m.current - 1 + len(m.failed)) % len(m.failed))
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+// show switches to the index-th failed script
+func (m *ScriptLogModel) show(index int) {
+	m.current = index
+	if m.ready {
+		m.viewport.SetContent(scriptLogContent(m.failed[index]))
+		m.viewport.GotoTop()
+	}
+}
+
+func (m ScriptLogModel) View() string {
+	if !m.ready {
+		return "Loading..."
+	}
+	exec := m.failed[m.current]
+
+	var s strings.Builder
+	title := fmt.Sprintf("📜 Failed: %s/%s (%d/%d)", exec.Tool, exec.Script.File, m.current+1, len(m.failed))
+	s.WriteString(titleStyle.Render(title) + "\n")
+	if exec.Error != nil {
+		s.WriteString(errorStyle.Render(fmt.Sprintf("✗ %v", exec.Error)) + "\n")
+	}
+	if exec.LogPath != "" {
+		s.WriteString(dimStyle.Render(exec.LogPath) + "\n")
+	}
+	s.WriteString("\n" + m.viewport.View() + "\n")
+	s.WriteString(helpStyle.Render(fmt.Sprintf("↑/↓: scroll • tab/n: next • shift+tab/p: previous • q: quit • %3.f%%", m.viewport.ScrollPercent()*100)))
+
+	return docStyle.Render(s.String())
+}
@@ -23,22 +23,31 @@ const (
 
 // ScriptExecution tracks a single script's execution
 type ScriptExecution struct {
+	Tool     string
 	Script   models.ScriptItem
 	Status   ScriptStatus
 	Duration time.Duration
 	Error    error
 	Output   string
+	LogPath  string // saved stdout/stderr of the run
+}
+
+// ScriptJob is one script to run, with the tool it belongs to
+type ScriptJob struct {
+	Tool      string
+	ScriptDir string
+	SharedEnv map[string]string // the tool's [scripts.env]
+	Runner    *scripts.ScriptRunner
+	Script    models.ScriptItem
 }
 
 // ScriptRunnerModel handles batch script execution with progress display
 type ScriptRunnerModel struct {
-	toolName   string
-	toolRoot   string
-	scriptDir  string
-	scripts    []models.ScriptItem
+	title      string
+	jobs       []ScriptJob
+	batch      bool // jobs span several tools; group the progress by tool
 	executions []ScriptExecution
 	current    int
-	runner     *scripts.ScriptRunner
 	done       bool
 	err        error
 	width      int
@@ -56,22 +65,33 @@ type scriptFinishedMsg struct {
 type allScriptsFinishedMsg struct{}
 
 // NewScriptRunnerModel creates a new script runner model
-func NewScriptRunnerModel(toolName, toolRoot, scriptDir string, scriptsToRun []models.ScriptItem, runner *scripts.ScriptRunner) ScriptRunnerModel {
-	executions := make([]ScriptExecution, len(scriptsToRun))
+func NewScriptRunnerModel(toolName, toolRoot, scriptDir string, scriptsToRun []models.ScriptItem, sharedEnv map[string]string, runner *scripts.ScriptRunner) ScriptRunnerModel {
+	jobs := make([]ScriptJob, len(scriptsToRun))
 	for i, script := range scriptsToRun {
+		jobs[i] = ScriptJob{Tool: toolName, ScriptDir: scriptDir, SharedEnv: sharedEnv, Runner: runner, Script: script}
+	}
+	m := NewBatchScriptRunnerModel(fmt.Sprintf("📜 Running Scripts: %s", toolName), jobs)
+	m.batch = false
+	return m
+}
+
+// NewBatchScriptRunnerModel runs jobs of several tools in the given order,
+// showing the progress grouped by tool
+func NewBatchScriptRunnerModel(title string, jobs []ScriptJob) ScriptRunnerModel {
+	executions := make([]ScriptExecution, len(jobs))
+	for i, job := range jobs {
 		executions[i] = ScriptExecution{
-			Script: script,
+			Tool:   job.Tool,
+			Script: job.Script,
 			Status: StatusPending,
 		}
 	}
 
 	return ScriptRunnerModel{
-		toolName:   toolName,
-		toolRoot:   toolRoot,
-		scriptDir:  scriptDir,
-		scripts:    scriptsToRun,
+		title:      title,
+		jobs:       jobs,
+		batch:      true,
 		executions: executions,
-		runner:     runner,
 	}
 }
 
@@ -102,6 +122,7 @@ func (m ScriptRunnerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if msg.index < len(m.executions) {
 			exec := &m.executions[msg.index]
 			exec.Duration = msg.duration
+			exec.LogPath = msg.result.LogPath
 			if msg.result.Success {
 				exec.Status = StatusSuccess
 			} else {
@@ -113,7 +134,7 @@ func (m ScriptRunnerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		// Move to next script
 		m.current++
-		if m.current < len(m.scripts) {
+		if m.current < len(m.jobs) {
 			return m, m.runNextScript()
 		}
 
@@ -134,10 +155,14 @@ func (m ScriptRunnerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (m ScriptRunnerModel) View() string {
 	var s strings.Builder
 
-	s.WriteString(titleStyle.Render(fmt.Sprintf("📜 Running Scripts: %s", m.toolName)) + "\n\n")
+	s.WriteString(titleStyle.Render(m.title) + "\n\n")
 
 	// Show execution progress for each script
-	for _, exec := range m.executions {
+	for i, exec := range m.executions {
+		if m.batch && (i == 0 || m.executions[i-1].Tool != exec.Tool) {
+			s.WriteString(subtitleStyle.Render(exec.Tool) + "\n")
+		}
+
 		var icon, status string
 		var style lipgloss.Style
 
@@ -162,6 +187,9 @@ func (m ScriptRunnerModel) View() string {
 		}
 
 		line := fmt.Sprintf("%s %s", icon, exec.Script.File)
+		if m.batch {
+			line = "  " + line
+		}
 		s.WriteString(style.Render(line))
 
 		if exec.Status != StatusPending {
@@ -200,7 +228,7 @@ func (m ScriptRunnerModel) View() string {
 
 		s.WriteString(helpStyle.Render("\nPress any key to continue..."))
 	} else {
-		progress := fmt.Sprintf("Progress: %d/%d", m.current, len(m.scripts))
+		progress := fmt.Sprintf("Progress: %d/%d", m.current, len(m.jobs))
 		s.WriteString("\n" + dimStyle.Render(progress))
 	}
 
@@ -209,7 +237,7 @@ func (m ScriptRunnerModel) View() string {
 
 // runNextScript executes the next script in the queue
 func (m *ScriptRunnerModel) runNextScript() tea.Cmd {
-	if m.current >= len(m.scripts) {
+	if m.current >= len(m.jobs) {
 		return nil
 	}
 
@@ -217,12 +245,11 @@ func (m *ScriptRunnerModel) runNextScript() tea.Cmd {
 	m.executions[m.current].Status = StatusRunning
 
 	index := m.current
-	script := m.scripts[index]
+	job := m.jobs[index]
 
 	return func() tea.Msg {
 		start := time.Now()
-		scriptPath := fmt.Sprintf("%s/%s", m.scriptDir, script.File)
-		result := m.runner.RunScript(scriptPath)
+		result := job.Runner.RunScriptItem(job.ScriptDir, job.Script, job.SharedEnv)
 		duration := time.Since(start)
 
 		return scriptFinishedMsg{